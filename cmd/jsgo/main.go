@@ -1,78 +1,144 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/example/jsgo/builtins"
-	"github.com/example/jsgo/interpreter"
-	"github.com/example/jsgo/parser"
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/builtins"
+	"github.com/example/jsgo/internal/interpreter"
+	"github.com/example/jsgo/internal/lexer"
+	"github.com/example/jsgo/internal/parser"
+	"github.com/example/jsgo/internal/runtime"
+	"github.com/example/jsgo/internal/token"
 )
 
-// consoleShim creates a console object using the registered _print/_printErr natives.
-const consoleShim = `var console = {
-	log: function() { var i = 0; var s = ""; while (i < arguments.length) { if (i > 0) s = s + " "; s = s + arguments[i]; i++; } _print(s); },
-	warn: function() { var i = 0; var s = ""; while (i < arguments.length) { if (i > 0) s = s + " "; s = s + arguments[i]; i++; } _printErr(s); },
-	error: function() { var i = 0; var s = ""; while (i < arguments.length) { if (i > 0) s = s + " "; s = s + arguments[i]; i++; } _printErr(s); },
-	info: function() { var i = 0; var s = ""; while (i < arguments.length) { if (i > 0) s = s + " "; s = s + arguments[i]; i++; } _print(s); }
-};
-`
-
 func main() {
 	evalCode := flag.String("e", "", "evaluate inline JavaScript code")
 	dumpAST := flag.Bool("ast", false, "dump the AST as JSON")
+	dumpESTree := flag.Bool("estree", false, "dump the AST as ESTree JSON, as JavaScript parsers such as acorn produce")
+	dumpTokens := flag.Bool("tokens", false, "print the token stream, one token per line")
+	moduleMode := flag.Bool("module", false, "run the input as an ES module; imports are resolved relative to the importing file")
+	commonJS := flag.Bool("commonjs", false, "run the file as a CommonJS module with require, module and exports")
+	temporal := flag.Bool("temporal", false, "add the Temporal namespace (PlainDate, PlainDateTime, Duration, Now)")
+	interactive := flag.Bool("i", false, "start the interactive REPL, the default with no file when stdin is a terminal")
+	compile := flag.Bool("compile", false, "compile function bodies to bytecode and run them on the VM; unsupported functions are still walked")
 	flag.Parse()
 
+	// Options may also follow the file name: jsgo file.js -ast
+	var files []string
+	for args := flag.Args(); len(args) > 0; args = flag.Args() {
+		files = append(files, args[0])
+		flag.CommandLine.Parse(args[1:])
+	}
+	evalSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "e" {
+			evalSet = true
+		}
+	})
+
 	var source string
+	entry := "<eval>"
 
-	if *evalCode != "" {
+	if *interactive || !evalSet && len(files) == 0 && !stdinPiped() {
+		interp := newInterpreter(*temporal, *compile)
+		runREPL(interp)
+		return
+	}
+
+	if evalSet {
 		source = *evalCode
-	} else if flag.NArg() > 0 {
-		filename := flag.Arg(0)
+	} else if len(files) > 0 && files[0] != "-" {
+		filename := files[0]
 		data, err := os.ReadFile(filename)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
 			os.Exit(1)
 		}
 		source = string(data)
+		entry = filename
 	} else {
-		fmt.Fprintf(os.Stderr, "Usage: jsgo [options] <file.js>\n")
-		fmt.Fprintf(os.Stderr, "       jsgo -e \"code\"\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
-		os.Exit(1)
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+		source = string(data)
+	}
+
+	// .mjs files are always ES modules, as in Node.
+	if strings.HasSuffix(entry, ".mjs") {
+		*moduleMode = true
+	}
+
+	// Token dump mode: print the lexer output
+	if *dumpTokens {
+		if !printTokens(source) {
+			os.Exit(1)
+		}
+		return
 	}
 
 	// AST dump mode: parse and print JSON
-	if *dumpAST {
+	if *dumpAST || *dumpESTree {
 		p := parser.New(source)
-		program, errs := p.ParseProgram()
+		parse := p.ParseProgram
+		if *moduleMode {
+			parse = p.ParseModule
+		}
+		program, errs := parse()
 		if len(errs) > 0 {
 			for _, err := range errs {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 			}
 			os.Exit(1)
 		}
+		var out interface{} = program
+		if *dumpESTree {
+			out = ast.ESTree(program, source)
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		if err := enc.Encode(program); err != nil {
+		if err := enc.Encode(out); err != nil {
 			fmt.Fprintf(os.Stderr, "Error encoding AST: %v\n", err)
 			os.Exit(1)
 		}
 		return
 	}
 
-	// Create interpreter, register builtins and native print functions
-	interp := interpreter.New()
-	builtins.RegisterAll(interp.GlobalEnv(), nil)
-	registerNatives(interp)
+	interp := newInterpreter(*temporal, *compile)
 
-	// Prepend console shim + user source and evaluate
-	fullSource := consoleShim + source
-	result, err := interp.Eval(fullSource)
+	if *commonJS {
+		if entry == "<eval>" {
+			fmt.Fprintf(os.Stderr, "Error: -commonjs needs a file\n")
+			os.Exit(1)
+		}
+		if _, err := interp.EvalCommonJS(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runLoop(interp)
+		return
+	}
+
+	if *moduleMode {
+		interp.SetModuleResolver(fileResolver(entry, source))
+		if _, err := interp.EvalModule(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runLoop(interp)
+		return
+	}
+
+	result, err := interp.EvalFile(entry, source)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -81,24 +147,113 @@ func main() {
 	if result != nil && result.Type != runtime.TypeUndefined {
 		fmt.Println(result.ToString())
 	}
+	runLoop(interp)
 }
 
-func registerNatives(interp *interpreter.Interpreter) {
-	interp.RegisterNative("_print", func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
-		if len(args) > 0 {
-			fmt.Println(args[0].ToString())
-		} else {
-			fmt.Println()
+// runLoop runs the timers the script set, exiting as Node does when one
+// of them throws.
+func runLoop(interp *interpreter.Interpreter) {
+	if err := interp.RunLoop(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newInterpreter creates an interpreter with the builtins and the native
+// print functions, in Compiled mode if compile is set.
+func newInterpreter(temporal, compile bool) *interpreter.Interpreter {
+	interp := interpreter.New()
+	if compile {
+		interp.SetMode(interpreter.Compiled)
+	}
+	realm := builtins.RegisterAll(interp.Realm(), interp.GlobalEnv(), nil)
+	if temporal {
+		realm.RegisterTemporal(interp.GlobalEnv())
+	}
+	registerNatives(interp)
+	return interp
+}
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal.
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// printTokens prints the tokens of source, one per line, as position, type
+// and quoted literal. Regular expressions are told apart from division the
+// same way the parser does it, from the previous token. It reports false if
+// the lexer produced an Illegal token.
+func printTokens(source string) bool {
+	l := lexer.New(source)
+	prev := token.EOF
+	ok := true
+	for {
+		tok := l.NextTokenWithRegex(prev)
+		fmt.Printf("%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Literal)
+		if tok.Type == token.Illegal {
+			ok = false
 		}
-		return runtime.Undefined, nil
-	})
-	interp.RegisterNative("_printErr", func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
-		if len(args) > 0 {
-			fmt.Fprintln(os.Stderr, args[0].ToString())
-		} else {
-			fmt.Fprintln(os.Stderr)
+		if tok.Type == token.EOF {
+			return ok
 		}
-		return runtime.Undefined, nil
-	})
+		prev = tok.Type
+	}
 }
 
+// fileResolver resolves module specifiers to files. Relative specifiers
+// ("./x.js", "../x.js") are resolved against the importing module's
+// directory, or the working directory for inline code. The entry module's
+// source has already been read.
+func fileResolver(entry, entrySource string) interpreter.ModuleResolver {
+	return func(specifier, referrer string) (string, string, error) {
+		if referrer == "" && specifier == entry {
+			if entry == "<eval>" {
+				return entry, entrySource, nil
+			}
+			path, err := filepath.Abs(entry)
+			return path, entrySource, err
+		}
+		if !strings.HasPrefix(specifier, "./") && !strings.HasPrefix(specifier, "../") && !filepath.IsAbs(specifier) {
+			return "", "", fmt.Errorf("cannot find module '%s': only relative and absolute paths are supported", specifier)
+		}
+		path := specifier
+		if !filepath.IsAbs(path) {
+			dir := "."
+			if referrer != "<eval>" {
+				dir = filepath.Dir(referrer)
+			}
+			path = filepath.Join(dir, specifier)
+		}
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return "", "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot find module '%s' imported from %s", specifier, referrer)
+		}
+		return path, string(data), nil
+	}
+}
+
+func registerNatives(interp *interpreter.Interpreter) {
+	interp.RegisterNativeObject("host", map[string]runtime.CallableFunc{
+		"print": func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			if len(args) > 0 {
+				fmt.Fprintln(interp.Stdout(), runtime.ToWellFormed(args[0].ToString()))
+			} else {
+				fmt.Fprintln(interp.Stdout())
+			}
+			return runtime.Undefined, nil
+		},
+		"printErr": func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			if len(args) > 0 {
+				fmt.Fprintln(interp.Stderr(), runtime.ToWellFormed(args[0].ToString()))
+			} else {
+				fmt.Fprintln(interp.Stderr())
+			}
+			return runtime.Undefined, nil
+		},
+	}, interpreter.NativeMethodOption{Method: "print", Length: 1}, interpreter.NativeMethodOption{Method: "printErr", Length: 1})
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/example/jsgo/internal/builtins"
+	"github.com/example/jsgo/internal/interpreter"
+	"github.com/example/jsgo/internal/parser"
+)
+
+// replTag tags the evaluations of the REPL, so Ctrl+C can interrupt them.
+const replTag = "repl"
+
+const replHelp = `.break    Discard the current multi-line input
+.exit     Exit the REPL
+.help     Print this help message
+.load     Evaluate a file: .load file.js
+
+Press Ctrl+C to abort the current input or evaluation, Ctrl+D to exit.
+`
+
+// errInterrupt is returned by readLine when Ctrl+C is pressed.
+var errInterrupt = errors.New("interrupted")
+
+// repl is the interactive mode of the CLI: it reads code a line at a time,
+// keeps reading while the input is incomplete, and prints the value of each
+// evaluation. Every evaluation runs in the same global environment.
+type repl struct {
+	interp  *interpreter.Interpreter
+	editor  *lineEditor
+	out     io.Writer
+	pending string // lines read so far of an incomplete input
+}
+
+func runREPL(interp *interpreter.Interpreter) {
+	r := &repl{interp: interp, editor: newLineEditor(os.Stdin, os.Stdout), out: os.Stdout}
+	fmt.Fprintf(r.out, "Welcome to jsgo.\nType \".help\" for more information.\n")
+	for {
+		prompt := "> "
+		if r.pending != "" {
+			prompt = "... "
+		}
+		line, err := r.editor.readLine(prompt)
+		if err == errInterrupt {
+			if r.pending == "" && line == "" {
+				fmt.Fprintln(r.out, "(To exit, press Ctrl+D or type .exit)")
+			}
+			r.pending = ""
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), ".") {
+			if done, ok := r.command(strings.TrimSpace(line)); ok {
+				if done {
+					return
+				}
+				continue
+			}
+		}
+		src := r.pending + line
+		if strings.TrimSpace(src) == "" {
+			continue
+		}
+		if incomplete(src) {
+			r.pending = src + "\n"
+			continue
+		}
+		r.pending = ""
+		r.eval(src)
+	}
+}
+
+// command runs a REPL command such as .help. It reports whether the line
+// was a command, and whether the REPL should exit.
+func (r *repl) command(line string) (exit, ok bool) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ".exit":
+		return true, true
+	case ".help":
+		fmt.Fprint(r.out, replHelp)
+	case ".break":
+		r.pending = ""
+	case ".load":
+		if arg == "" {
+			fmt.Fprintln(r.out, "Usage: .load file.js")
+			break
+		}
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintf(r.out, "Failed to load: %v\n", err)
+			break
+		}
+		r.pending = ""
+		r.eval(string(data))
+	default:
+		// Not a command, but code such as .5 + 1.
+		return false, false
+	}
+	return false, true
+}
+
+// eval runs src and prints its value, or the error it threw, then runs
+// the event loop until the timers and microtasks the input queued are
+// done. Ctrl+C stops a long evaluation or the wait for a timer rather than
+// the process; the timers left then run after the next input.
+func (r *repl) eval(src string) {
+	program, errs := parser.New(src).ParseProgram()
+	if len(errs) > 0 {
+		fmt.Fprintf(r.out, "Uncaught SyntaxError: %v\n", errs[0])
+		return
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-sigs:
+			r.interp.Interrupt(replTag)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	defer signal.Stop(sigs)
+	defer cancel()
+
+	result, err := r.interp.RunTagged(replTag, program)
+	var interrupted *interpreter.InterruptedError
+	switch {
+	case errors.As(err, &interrupted):
+		fmt.Fprintln(r.out, "Script execution was interrupted by Ctrl+C.")
+		return
+	case err != nil:
+		fmt.Fprintf(r.out, "Uncaught %v\n", err)
+	default:
+		fmt.Fprintln(r.out, builtins.Inspect(result))
+	}
+
+	switch err := r.interp.RunLoop(ctx); {
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(r.out, "Script execution was interrupted by Ctrl+C.")
+	case err != nil:
+		fmt.Fprintf(r.out, "Uncaught %v\n", err)
+	}
+}
+
+// incomplete reports whether src fails to parse only because it ends too
+// early, such as an unclosed block or template literal, so the REPL should
+// read another line before evaluating it.
+func incomplete(src string) bool {
+	_, errs := parser.New(src).ParseProgram()
+	for _, err := range errs {
+		// The end of the input may not be the first error: a template
+		// left open after a substitution is first reported as a missing
+		// template tail.
+		if errors.Is(err, parser.ErrUnexpectedEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineEditor reads lines from a terminal with basic editing: cursor
+// movement, deletion and history. When the input is not a terminal it
+// reads plain lines.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	tty     *os.File
+	term    bool
+	history []string
+}
+
+func newLineEditor(in *os.File, out io.Writer) *lineEditor {
+	return &lineEditor{
+		in:   bufio.NewReader(in),
+		out:  out,
+		tty:  in,
+		term: term.IsTerminal(int(in.Fd())),
+	}
+}
+
+// readLine prints prompt and reads one line. It returns io.EOF at the end
+// of the input or on Ctrl+D at an empty line, and errInterrupt, with the
+// line typed so far, on Ctrl+C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if e.term {
+		// Raw mode passes keys through one at a time, unechoed; it also
+		// turns off output processing, so edit ends lines with "\r\n".
+		fd := int(e.tty.Fd())
+		if state, err := term.MakeRaw(fd); err == nil {
+			defer term.Restore(fd, state)
+			return e.edit(prompt)
+		}
+	}
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// edit reads a line in raw mode, redrawing it after every key.
+func (e *lineEditor) edit(prompt string) (string, error) {
+	var line []rune
+	pos := 0
+	hist := len(e.history)
+	saved := ""
+
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if back := len(line) - pos; back > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", back)
+		}
+	}
+	recall := func(i int) {
+		if i < 0 || i > len(e.history) {
+			return
+		}
+		if hist == len(e.history) {
+			saved = string(line)
+		}
+		hist = i
+		if i == len(e.history) {
+			line = []rune(saved)
+		} else {
+			line = []rune(e.history[i])
+		}
+		pos = len(line)
+	}
+
+	fmt.Fprint(e.out, prompt)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			s := string(line)
+			if strings.TrimSpace(s) != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != s) {
+				e.history = append(e.history, s)
+			}
+			return s, nil
+		case 3: // Ctrl+C
+			fmt.Fprint(e.out, "^C\r\n")
+			return string(line), errInterrupt
+		case 4: // Ctrl+D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case 127, 8: // Backspace
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case 1: // Ctrl+A
+			pos = 0
+		case 5: // Ctrl+E
+			pos = len(line)
+		case 2: // Ctrl+B
+			if pos > 0 {
+				pos--
+			}
+		case 6: // Ctrl+F
+			if pos < len(line) {
+				pos++
+			}
+		case 11: // Ctrl+K
+			line = line[:pos]
+		case 21: // Ctrl+U
+			line = line[pos:]
+			pos = 0
+		case 16: // Ctrl+P
+			recall(hist - 1)
+		case 14: // Ctrl+N
+			recall(hist + 1)
+		case 27: // Escape sequences: arrows, Home, End, Delete
+			switch e.escape() {
+			case "A":
+				recall(hist - 1)
+			case "B":
+				recall(hist + 1)
+			case "C":
+				if pos < len(line) {
+					pos++
+				}
+			case "D":
+				if pos > 0 {
+					pos--
+				}
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(line)
+			case "3~":
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		case '\t':
+			line = append(line[:pos], append([]rune("  "), line[pos:]...)...)
+			pos += 2
+		default:
+			if r < 32 {
+				continue
+			}
+			line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+		}
+		redraw()
+	}
+}
+
+// escape reads the rest of an escape sequence after ESC, such as "[A" for
+// the up arrow, and returns its final part ("A", "3~").
+func (e *lineEditor) escape() string {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != '[' && r != 'O' {
+		return ""
+	}
+	var seq []rune
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, r)
+		if r < '0' || r > '9' {
+			return string(seq)
+		}
+	}
+}
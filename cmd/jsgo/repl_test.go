@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"1 + 2", false},
+		{"var x = 1;", false},
+		{"", false},
+		{"function f() {", true},
+		{"if (x) {\n  y();", true},
+		{"[1, 2", true},
+		{"({ a: 1", true},
+		{"f(1,", true},
+		{"var x =", true},
+		{"`abc", true},
+		{"`a ${b}\nc", true},
+		{"}", false},
+		{"var 1 = 2;", false},
+		{"1 +* 2", false},
+		{"'abc", false},
+	}
+	for _, tt := range tests {
+		if got := incomplete(tt.src); got != tt.want {
+			t.Errorf("incomplete(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
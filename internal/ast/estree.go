@@ -0,0 +1,765 @@
+package ast
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ESTree converts the tree rooted at node to the ESTree format used by
+// JavaScript tooling such as acorn and ESLint, as nested maps ready for
+// encoding/json. Every node has a type, start and end offsets, a range and
+// a loc with 1-based lines and 0-based columns. Offsets count bytes and
+// columns count code points, where JavaScript parsers count UTF-16 units.
+//
+// source is the text node was parsed from. It supplies the raw text of
+// literals and template elements and the positions of names the tree keeps
+// only as strings, such as import and export names; it may be empty.
+func ESTree(node Node, source string) map[string]interface{} {
+	m, _ := (&esConverter{source: source}).node(node).(map[string]interface{})
+	return m
+}
+
+type esConverter struct {
+	source string
+}
+
+// make returns an ESTree node of the given type covering span.
+func (c *esConverter) make(typ string, span SourceSpan) map[string]interface{} {
+	m := map[string]interface{}{"type": typ}
+	if span.Start.IsValid() {
+		m["start"] = span.Start.Offset
+		m["end"] = span.End.Offset
+		m["range"] = []int{span.Start.Offset, span.End.Offset}
+		m["loc"] = map[string]interface{}{
+			"start": map[string]int{"line": span.Start.Line, "column": span.Start.Column - 1},
+			"end":   map[string]int{"line": span.End.Line, "column": span.End.Column - 1},
+		}
+	}
+	return m
+}
+
+// text returns the source covered by span, or "" without source.
+func (c *esConverter) text(span SourceSpan) string {
+	return span.Text(c.source)
+}
+
+// name returns an Identifier for a name the tree keeps as a string. The
+// name is written at the start of span, or at its end when atEnd is set;
+// if the source shows otherwise the identifier covers all of span.
+func (c *esConverter) name(name string, span SourceSpan, atEnd bool) map[string]interface{} {
+	id := span
+	if atEnd {
+		id.Start = Position{Offset: span.End.Offset - len(name), Line: span.End.Line, Column: span.End.Column - utf8.RuneCountInString(name)}
+	} else {
+		id.End = advance(span.Start, name)
+	}
+	if c.source != "" && id.Text(c.source) != name {
+		id = span
+	}
+	m := c.make("Identifier", id)
+	m["name"] = name
+	return m
+}
+
+// isNil reports whether n is nil or a nil pointer in an interface.
+func isNil(n Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+func (c *esConverter) nodes(list []Node) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, n := range list {
+		out[i] = c.node(n)
+	}
+	return out
+}
+
+func (c *esConverter) exprs(list []Expression) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, e := range list {
+		out[i] = c.node(e)
+	}
+	return out
+}
+
+func (c *esConverter) stmts(list []Statement) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, s := range list {
+		out[i] = c.node(s)
+	}
+	return out
+}
+
+// body converts a statement body. The parser wraps the single statement
+// of if and else branches in a block covering the same source; ESTree
+// keeps the statement as written.
+func (c *esConverter) body(s Statement) interface{} {
+	if b, ok := s.(*BlockStatement); ok && b != nil && len(b.Statements) == 1 && b.Statements[0].Span() == b.Span() {
+		return c.node(b.Statements[0])
+	}
+	return c.node(s)
+}
+
+// pattern converts the target of a binding or assignment. Arrow function
+// parameters are parsed as expressions, so array and object literals,
+// assignments and spreads there stand for the corresponding patterns.
+func (c *esConverter) pattern(e Expression) interface{} {
+	switch e := e.(type) {
+	case *ArrayLiteral:
+		if e == nil {
+			return nil
+		}
+		m := c.make("ArrayPattern", e.SourceSpan)
+		elems := make([]interface{}, len(e.Elements))
+		for i, el := range e.Elements {
+			elems[i] = c.pattern(el)
+		}
+		m["elements"] = elems
+		return m
+	case *ArrayPattern:
+		if e == nil {
+			return nil
+		}
+		m := c.make("ArrayPattern", e.SourceSpan)
+		elems := make([]interface{}, len(e.Elements))
+		for i, el := range e.Elements {
+			elems[i] = c.pattern(el)
+		}
+		m["elements"] = elems
+		return m
+	case *ObjectLiteral:
+		if e == nil {
+			return nil
+		}
+		return c.objectPattern(e.SourceSpan, e.Properties)
+	case *ObjectPattern:
+		if e == nil {
+			return nil
+		}
+		return c.objectPattern(e.SourceSpan, e.Properties)
+	case *AssignmentExpression:
+		if e == nil || e.Operator != "=" {
+			break
+		}
+		m := c.make("AssignmentPattern", e.SourceSpan)
+		m["left"] = c.pattern(e.Left)
+		m["right"] = c.node(e.Right)
+		return m
+	case *AssignmentPattern:
+		if e == nil {
+			return nil
+		}
+		m := c.make("AssignmentPattern", e.SourceSpan)
+		m["left"] = c.pattern(e.Left)
+		m["right"] = c.node(e.Right)
+		return m
+	case *SpreadElement:
+		if e == nil {
+			return nil
+		}
+		m := c.make("RestElement", e.SourceSpan)
+		m["argument"] = c.pattern(e.Argument)
+		return m
+	case *RestElement:
+		if e == nil {
+			return nil
+		}
+		m := c.make("RestElement", e.SourceSpan)
+		m["argument"] = c.pattern(e.Argument)
+		return m
+	}
+	return c.node(e)
+}
+
+func (c *esConverter) objectPattern(span SourceSpan, props []*Property) map[string]interface{} {
+	m := c.make("ObjectPattern", span)
+	out := make([]interface{}, len(props))
+	for i, p := range props {
+		switch rest := p.Value.(type) {
+		case *RestElement, *SpreadElement:
+			out[i] = c.pattern(rest)
+			continue
+		}
+		prop := c.make("Property", p.SourceSpan)
+		prop["key"] = c.node(p.Key)
+		prop["value"] = c.pattern(p.Value)
+		prop["kind"] = "init"
+		prop["method"] = false
+		prop["shorthand"] = p.Shorthand
+		prop["computed"] = p.Computed
+		out[i] = prop
+	}
+	m["properties"] = out
+	return m
+}
+
+// params returns the parameter list of a function, with default values
+// folded into AssignmentPatterns and the rest parameter last.
+func (c *esConverter) params(params, defaults []Expression, rest Expression) []interface{} {
+	out := make([]interface{}, 0, len(params)+1)
+	for i, p := range params {
+		if i < len(defaults) && !isNil(defaults[i]) {
+			span := SourceSpan{Start: p.Span().Start, End: defaults[i].Span().End}
+			m := c.make("AssignmentPattern", span)
+			m["left"] = c.pattern(p)
+			m["right"] = c.node(defaults[i])
+			out = append(out, m)
+			continue
+		}
+		out = append(out, c.pattern(p))
+	}
+	if !isNil(rest) {
+		out = append(out, c.pattern(rest))
+	}
+	return out
+}
+
+func (c *esConverter) function(typ string, span SourceSpan, name *Identifier, params, defaults []Expression, rest Expression, body *BlockStatement, generator, async bool) map[string]interface{} {
+	m := c.make(typ, span)
+	m["id"] = c.node(name)
+	m["params"] = c.params(params, defaults, rest)
+	m["body"] = c.node(body)
+	m["generator"] = generator
+	m["async"] = async
+	m["expression"] = false
+	return m
+}
+
+// method converts the function of a method, which has no name of its own.
+func (c *esConverter) method(fn *FunctionExpression) interface{} {
+	if fn == nil {
+		return nil
+	}
+	return c.function("FunctionExpression", fn.SourceSpan, nil, fn.Params, fn.Defaults, fn.Rest, fn.Body, fn.Generator, fn.Async)
+}
+
+func (c *esConverter) class(typ string, span SourceSpan, name *Identifier, super Expression, body *ClassBody) map[string]interface{} {
+	m := c.make(typ, span)
+	m["id"] = c.node(name)
+	m["superClass"] = c.node(super)
+	m["body"] = c.node(body)
+	return m
+}
+
+func (c *esConverter) literal(span SourceSpan, value interface{}, raw string) map[string]interface{} {
+	m := c.make("Literal", span)
+	m["value"] = value
+	m["raw"] = raw
+	return m
+}
+
+func (c *esConverter) node(n Node) interface{} {
+	if isNil(n) {
+		return nil
+	}
+	switch n := n.(type) {
+	case *Program:
+		m := c.make("Program", n.SourceSpan)
+		m["body"] = c.stmts(n.Statements)
+		sourceType := "script"
+		for _, s := range n.Statements {
+			switch s.(type) {
+			case *ImportDeclaration, *ExportNamedDeclaration, *ExportDefaultDeclaration, *ExportAllDeclaration:
+				sourceType = "module"
+			}
+		}
+		m["sourceType"] = sourceType
+		return m
+
+	// Statements
+	case *VariableDeclaration:
+		m := c.make("VariableDeclaration", n.SourceSpan)
+		decls := make([]interface{}, len(n.Declarations))
+		for i, d := range n.Declarations {
+			decls[i] = c.node(d)
+		}
+		m["declarations"] = decls
+		m["kind"] = n.Kind
+		return m
+	case *VariableDeclarator:
+		m := c.make("VariableDeclarator", n.SourceSpan)
+		m["id"] = c.pattern(n.Name)
+		m["init"] = c.node(n.Value)
+		return m
+	case *ExpressionStatement:
+		m := c.make("ExpressionStatement", n.SourceSpan)
+		m["expression"] = c.node(n.Expression)
+		return m
+	case *BlockStatement:
+		m := c.make("BlockStatement", n.SourceSpan)
+		m["body"] = c.stmts(n.Statements)
+		return m
+	case *ReturnStatement:
+		m := c.make("ReturnStatement", n.SourceSpan)
+		m["argument"] = c.node(n.Value)
+		return m
+	case *IfStatement:
+		m := c.make("IfStatement", n.SourceSpan)
+		m["test"] = c.node(n.Condition)
+		m["consequent"] = c.body(n.Consequence)
+		m["alternate"] = c.body(n.Alternative)
+		return m
+	case *WhileStatement:
+		m := c.make("WhileStatement", n.SourceSpan)
+		m["test"] = c.node(n.Condition)
+		m["body"] = c.node(n.Body)
+		return m
+	case *DoWhileStatement:
+		m := c.make("DoWhileStatement", n.SourceSpan)
+		m["body"] = c.node(n.Body)
+		m["test"] = c.node(n.Condition)
+		return m
+	case *ForStatement:
+		m := c.make("ForStatement", n.SourceSpan)
+		// An expression in the head is kept in an ExpressionStatement.
+		if init, ok := n.Init.(*ExpressionStatement); ok {
+			m["init"] = c.node(init.Expression)
+		} else {
+			m["init"] = c.node(n.Init)
+		}
+		m["test"] = c.node(n.Test)
+		m["update"] = c.node(n.Update)
+		m["body"] = c.node(n.Body)
+		return m
+	case *ForInStatement:
+		m := c.make("ForInStatement", n.SourceSpan)
+		m["left"] = c.forLeft(n.Left)
+		m["right"] = c.node(n.Right)
+		m["body"] = c.node(n.Body)
+		return m
+	case *ForOfStatement:
+		m := c.make("ForOfStatement", n.SourceSpan)
+		m["await"] = n.Await
+		m["left"] = c.forLeft(n.Left)
+		m["right"] = c.node(n.Right)
+		m["body"] = c.node(n.Body)
+		return m
+	case *BreakStatement:
+		m := c.make("BreakStatement", n.SourceSpan)
+		m["label"] = c.node(n.Label)
+		return m
+	case *ContinueStatement:
+		m := c.make("ContinueStatement", n.SourceSpan)
+		m["label"] = c.node(n.Label)
+		return m
+	case *SwitchStatement:
+		m := c.make("SwitchStatement", n.SourceSpan)
+		m["discriminant"] = c.node(n.Discriminant)
+		cases := make([]interface{}, len(n.Cases))
+		for i, sc := range n.Cases {
+			cases[i] = c.node(sc)
+		}
+		m["cases"] = cases
+		return m
+	case *SwitchCase:
+		m := c.make("SwitchCase", n.SourceSpan)
+		m["test"] = c.node(n.Test)
+		m["consequent"] = c.stmts(n.Consequent)
+		return m
+	case *ThrowStatement:
+		m := c.make("ThrowStatement", n.SourceSpan)
+		m["argument"] = c.node(n.Argument)
+		return m
+	case *TryStatement:
+		m := c.make("TryStatement", n.SourceSpan)
+		m["block"] = c.node(n.Block)
+		m["handler"] = c.node(n.Handler)
+		m["finalizer"] = c.node(n.Finalizer)
+		return m
+	case *CatchClause:
+		m := c.make("CatchClause", n.SourceSpan)
+		if isNil(n.Param) {
+			m["param"] = nil
+		} else {
+			m["param"] = c.pattern(n.Param)
+		}
+		m["body"] = c.node(n.Body)
+		return m
+	case *FunctionDeclaration:
+		return c.function("FunctionDeclaration", n.SourceSpan, n.Name, n.Params, n.Defaults, n.Rest, n.Body, n.Generator, n.Async)
+	case *ClassDeclaration:
+		return c.class("ClassDeclaration", n.SourceSpan, n.Name, n.SuperClass, n.Body)
+	case *ClassBody:
+		m := c.make("ClassBody", n.SourceSpan)
+		// Methods and fields are kept apart; ESTree lists them in source
+		// order.
+		members := make([]Node, 0, len(n.Methods)+len(n.Fields))
+		for _, md := range n.Methods {
+			members = append(members, md)
+		}
+		for _, f := range n.Fields {
+			members = append(members, f)
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return members[i].Span().Start.Offset < members[j].Span().Start.Offset
+		})
+		m["body"] = c.nodes(members)
+		return m
+	case *MethodDefinition:
+		m := c.make("MethodDefinition", n.SourceSpan)
+		m["key"] = c.node(n.Key)
+		m["value"] = c.method(n.Value)
+		m["kind"] = n.Kind
+		m["computed"] = n.Computed
+		m["static"] = n.Static
+		return m
+	case *FieldDefinition:
+		if n.Block != nil {
+			m := c.make("StaticBlock", n.SourceSpan)
+			m["body"] = c.stmts(n.Block.Statements)
+			return m
+		}
+		m := c.make("PropertyDefinition", n.SourceSpan)
+		m["key"] = c.node(n.Key)
+		m["value"] = c.node(n.Value)
+		m["computed"] = n.Computed
+		m["static"] = n.Static
+		return m
+	case *LabeledStatement:
+		m := c.make("LabeledStatement", n.SourceSpan)
+		m["label"] = c.node(n.Label)
+		m["body"] = c.node(n.Body)
+		return m
+	case *DebuggerStatement:
+		return c.make("DebuggerStatement", n.SourceSpan)
+	case *EmptyStatement:
+		return c.make("EmptyStatement", n.SourceSpan)
+	case *WithStatement:
+		m := c.make("WithStatement", n.SourceSpan)
+		m["object"] = c.node(n.Object)
+		m["body"] = c.node(n.Body)
+		return m
+
+	// Modules
+	case *ImportDeclaration:
+		m := c.make("ImportDeclaration", n.SourceSpan)
+		specs := make([]interface{}, len(n.Specifiers))
+		for i, s := range n.Specifiers {
+			specs[i] = c.node(s)
+		}
+		m["specifiers"] = specs
+		m["source"] = c.node(n.Source)
+		return m
+	case *ImportSpecifier:
+		var m map[string]interface{}
+		switch n.Imported {
+		case "default":
+			m = c.make("ImportDefaultSpecifier", n.SourceSpan)
+			// import { default as x } names the export.
+			if c.source != "" && strings.HasPrefix(c.text(n.SourceSpan), "default") {
+				m = c.make("ImportSpecifier", n.SourceSpan)
+				m["imported"] = c.name(n.Imported, n.SourceSpan, false)
+			}
+		case "*":
+			m = c.make("ImportNamespaceSpecifier", n.SourceSpan)
+		default:
+			m = c.make("ImportSpecifier", n.SourceSpan)
+			m["imported"] = c.name(n.Imported, n.SourceSpan, false)
+		}
+		m["local"] = c.node(n.Local)
+		return m
+	case *ExportNamedDeclaration:
+		m := c.make("ExportNamedDeclaration", n.SourceSpan)
+		m["declaration"] = c.node(n.Declaration)
+		specs := make([]interface{}, len(n.Specifiers))
+		for i, s := range n.Specifiers {
+			specs[i] = c.node(s)
+		}
+		m["specifiers"] = specs
+		m["source"] = c.node(n.Source)
+		return m
+	case *ExportSpecifier:
+		m := c.make("ExportSpecifier", n.SourceSpan)
+		m["local"] = c.name(n.Local, n.SourceSpan, false)
+		m["exported"] = c.name(n.Exported, n.SourceSpan, true)
+		return m
+	case *ExportDefaultDeclaration:
+		m := c.make("ExportDefaultDeclaration", n.SourceSpan)
+		m["declaration"] = c.node(n.Declaration)
+		// export default function () {} declares an anonymous function,
+		// which the parser keeps as an expression.
+		if decl, ok := m["declaration"].(map[string]interface{}); ok && c.source != "" {
+			text := c.text(n.Declaration.Span())
+			switch {
+			case decl["type"] == "FunctionExpression" && (strings.HasPrefix(text, "function") || strings.HasPrefix(text, "async")):
+				decl["type"] = "FunctionDeclaration"
+			case decl["type"] == "ClassExpression" && strings.HasPrefix(text, "class"):
+				decl["type"] = "ClassDeclaration"
+			}
+		}
+		return m
+	case *ExportAllDeclaration:
+		m := c.make("ExportAllDeclaration", n.SourceSpan)
+		m["exported"] = nil
+		if n.Exported != "" {
+			m["exported"] = c.exportAllName(n)
+		}
+		m["source"] = c.node(n.Source)
+		return m
+
+	// Expressions
+	case *Identifier:
+		m := c.make("Identifier", n.SourceSpan)
+		m["name"] = n.Value
+		return m
+	case *PrivateIdentifier:
+		m := c.make("PrivateIdentifier", n.SourceSpan)
+		m["name"] = strings.TrimPrefix(n.Name, "#")
+		return m
+	case *NumberLiteral:
+		raw := n.Token.Literal
+		if strings.HasSuffix(raw, "n") {
+			m := c.literal(n.SourceSpan, nil, raw)
+			m["bigint"] = strings.ReplaceAll(strings.TrimSuffix(raw, "n"), "_", "")
+			return m
+		}
+		var value interface{} = n.Value
+		if math.IsInf(n.Value, 0) {
+			// JSON has no infinity; 1e400 is written as null.
+			value = nil
+		}
+		return c.literal(n.SourceSpan, value, raw)
+	case *StringLiteral:
+		raw := c.text(n.SourceSpan)
+		if raw == "" {
+			raw = strconv.Quote(n.Value)
+		}
+		return c.literal(n.SourceSpan, n.Value, raw)
+	case *BooleanLiteral:
+		raw := "false"
+		if n.Value {
+			raw = "true"
+		}
+		return c.literal(n.SourceSpan, n.Value, raw)
+	case *NullLiteral:
+		return c.literal(n.SourceSpan, nil, "null")
+	case *UndefinedLiteral:
+		m := c.make("Identifier", n.SourceSpan)
+		m["name"] = "undefined"
+		return m
+	case *RegExpLiteral:
+		m := c.literal(n.SourceSpan, nil, "/"+n.Pattern+"/"+n.Flags)
+		m["regex"] = map[string]interface{}{"pattern": n.Pattern, "flags": n.Flags}
+		return m
+	case *ArrayLiteral:
+		m := c.make("ArrayExpression", n.SourceSpan)
+		m["elements"] = c.exprs(n.Elements)
+		return m
+	case *ObjectLiteral:
+		m := c.make("ObjectExpression", n.SourceSpan)
+		props := make([]interface{}, len(n.Properties))
+		for i, p := range n.Properties {
+			props[i] = c.node(p)
+		}
+		m["properties"] = props
+		return m
+	case *Property:
+		if spread, ok := n.Value.(*SpreadElement); ok {
+			return c.node(spread)
+		}
+		m := c.make("Property", n.SourceSpan)
+		m["key"] = c.node(n.Key)
+		if fn, ok := n.Value.(*FunctionExpression); ok && n.Method {
+			m["value"] = c.method(fn)
+		} else {
+			m["value"] = c.node(n.Value)
+		}
+		m["kind"] = n.Kind
+		m["method"] = n.Method && n.Kind == "init"
+		m["shorthand"] = n.Shorthand
+		m["computed"] = n.Computed
+		return m
+	case *FunctionExpression:
+		return c.function("FunctionExpression", n.SourceSpan, n.Name, n.Params, n.Defaults, n.Rest, n.Body, n.Generator, n.Async)
+	case *ArrowFunctionExpression:
+		m := c.make("ArrowFunctionExpression", n.SourceSpan)
+		m["id"] = nil
+		m["params"] = c.params(n.Params, n.Defaults, n.Rest)
+		m["body"] = c.node(n.Body)
+		m["generator"] = false
+		m["async"] = n.Async
+		_, block := n.Body.(*BlockStatement)
+		m["expression"] = !block
+		return m
+	case *UnaryExpression:
+		m := c.make("UnaryExpression", n.SourceSpan)
+		m["operator"] = n.Operator
+		m["prefix"] = true
+		m["argument"] = c.node(n.Operand)
+		return m
+	case *UpdateExpression:
+		m := c.make("UpdateExpression", n.SourceSpan)
+		m["operator"] = n.Operator
+		m["prefix"] = n.Prefix
+		m["argument"] = c.node(n.Operand)
+		return m
+	case *BinaryExpression:
+		typ := "BinaryExpression"
+		switch n.Operator {
+		case "&&", "||", "??":
+			typ = "LogicalExpression"
+		}
+		m := c.make(typ, n.SourceSpan)
+		m["operator"] = n.Operator
+		m["left"] = c.node(n.Left)
+		m["right"] = c.node(n.Right)
+		return m
+	case *LogicalExpression:
+		m := c.make("LogicalExpression", n.SourceSpan)
+		m["operator"] = n.Operator
+		m["left"] = c.node(n.Left)
+		m["right"] = c.node(n.Right)
+		return m
+	case *AssignmentExpression:
+		m := c.make("AssignmentExpression", n.SourceSpan)
+		m["operator"] = n.Operator
+		m["left"] = c.pattern(n.Left)
+		m["right"] = c.node(n.Right)
+		return m
+	case *ConditionalExpression:
+		m := c.make("ConditionalExpression", n.SourceSpan)
+		m["test"] = c.node(n.Test)
+		m["consequent"] = c.node(n.Consequent)
+		m["alternate"] = c.node(n.Alternate)
+		return m
+	case *CallExpression:
+		m := c.make("CallExpression", n.SourceSpan)
+		m["callee"] = c.node(n.Callee)
+		m["arguments"] = c.exprs(n.Arguments)
+		m["optional"] = n.Optional
+		return m
+	case *MemberExpression:
+		m := c.make("MemberExpression", n.SourceSpan)
+		m["object"] = c.node(n.Object)
+		m["property"] = c.node(n.Property)
+		m["computed"] = n.Computed
+		m["optional"] = n.Optional
+		return m
+	case *ChainExpression:
+		m := c.make("ChainExpression", n.SourceSpan)
+		m["expression"] = c.node(n.Expression)
+		return m
+	case *NewExpression:
+		m := c.make("NewExpression", n.SourceSpan)
+		m["callee"] = c.node(n.Callee)
+		m["arguments"] = c.exprs(n.Arguments)
+		return m
+	case *SequenceExpression:
+		m := c.make("SequenceExpression", n.SourceSpan)
+		m["expressions"] = c.exprs(n.Expressions)
+		return m
+	case *TemplateLiteralExpr:
+		m := c.make("TemplateLiteral", n.SourceSpan)
+		quasis := make([]interface{}, len(n.Quasis))
+		for i, q := range n.Quasis {
+			quasis[i] = c.node(q)
+		}
+		m["quasis"] = quasis
+		m["expressions"] = c.exprs(n.Expressions)
+		return m
+	case *TemplateElement:
+		return c.templateElement(n)
+	case *TaggedTemplateExpression:
+		m := c.make("TaggedTemplateExpression", n.SourceSpan)
+		m["tag"] = c.node(n.Tag)
+		m["quasi"] = c.node(n.Quasi)
+		return m
+	case *SpreadElement:
+		m := c.make("SpreadElement", n.SourceSpan)
+		m["argument"] = c.node(n.Argument)
+		return m
+	case *YieldExpression:
+		m := c.make("YieldExpression", n.SourceSpan)
+		m["argument"] = c.node(n.Argument)
+		m["delegate"] = n.Delegate
+		return m
+	case *AwaitExpression:
+		m := c.make("AwaitExpression", n.SourceSpan)
+		m["argument"] = c.node(n.Argument)
+		return m
+	case *ClassExpression:
+		return c.class("ClassExpression", n.SourceSpan, n.Name, n.SuperClass, n.Body)
+	case *ThisExpression:
+		return c.make("ThisExpression", n.SourceSpan)
+	case *SuperExpression:
+		return c.make("Super", n.SourceSpan)
+	case *MetaProperty:
+		m := c.make("MetaProperty", n.SourceSpan)
+		m["meta"] = c.name(n.Meta, n.SourceSpan, false)
+		m["property"] = c.name(n.Property, n.SourceSpan, true)
+		return m
+	case *ObjectPattern, *ArrayPattern, *AssignmentPattern, *RestElement:
+		return c.pattern(n.(Expression))
+	case *ComputedPropertyName:
+		return c.node(n.Expression)
+	}
+	return nil
+}
+
+// forLeft converts the head of a for-in or for-of loop.
+func (c *esConverter) forLeft(left Node) interface{} {
+	if e, ok := left.(Expression); ok {
+		if _, decl := left.(*VariableDeclarator); !decl {
+			return c.pattern(e)
+		}
+	}
+	return c.node(left)
+}
+
+// templateElement converts a quasi of a template literal. The token spans
+// the delimiters around the text, which ESTree leaves out.
+func (c *esConverter) templateElement(n *TemplateElement) map[string]interface{} {
+	span := n.SourceSpan
+	raw := n.Value
+	if text := c.text(span); text != "" {
+		// ` or } before the text, and ` or ${ after it.
+		open := 1
+		close := 1
+		if strings.HasSuffix(text, "${") {
+			close = 2
+		}
+		if len(text) >= open+close {
+			raw = text[open : len(text)-close]
+			span.Start = advance(span.Start, text[:open])
+			span.End = advance(span.Start, raw)
+		}
+	}
+	m := c.make("TemplateElement", span)
+	m["value"] = map[string]interface{}{"raw": raw, "cooked": n.Value}
+	m["tail"] = n.Tail
+	return m
+}
+
+// exportAllName returns the Identifier for the name in export * as name.
+func (c *esConverter) exportAllName(n *ExportAllDeclaration) map[string]interface{} {
+	text := c.text(n.SourceSpan)
+	if i := strings.Index(text, " as "); i >= 0 {
+		rest := text[i+len(" as "):]
+		j := strings.Index(rest, n.Exported)
+		if j >= 0 {
+			start := advance(n.Span().Start, text[:i+len(" as ")+j])
+			return c.name(n.Exported, SourceSpan{Start: start, End: advance(start, n.Exported)}, false)
+		}
+	}
+	return c.name(n.Exported, n.SourceSpan, false)
+}
+
+// advance returns the position after text, which starts at pos.
+func advance(pos Position, text string) Position {
+	pos.Offset += len(text)
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		pos.Line += strings.Count(text, "\n")
+		pos.Column = utf8.RuneCountInString(text[i:])
+	} else {
+		pos.Column += utf8.RuneCountInString(text)
+	}
+	return pos
+}
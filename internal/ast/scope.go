@@ -0,0 +1,83 @@
+package ast
+
+import "sort"
+
+// Scope is the result of scope analysis for a function or program. It lets
+// the interpreter create closures that keep only the enclosing bindings they
+// can refer to instead of the whole scope chain.
+type Scope struct {
+	// Free lists, sorted, every name the function might resolve in an
+	// enclosing scope: each identifier used in its parameters and body or in
+	// a nested function, plus "this", "super", "new.target" and private
+	// names such as "#x" where they appear. Names the function declares
+	// itself may be included too.
+	Free []string
+	// DirectEval is set when the function's own body calls eval directly
+	// (or uses with), so bindings can be added to its scope at run time.
+	DirectEval bool
+	// Dynamic is set when the function or any function nested in it has
+	// DirectEval. Eval code can name any binding in scope, so such a
+	// function has to keep its whole scope chain.
+	Dynamic bool
+}
+
+// AnalyzeScopes computes the Scope of program and of every function in it.
+// The parser runs it on each program it returns.
+func AnalyzeScopes(program *Program) {
+	program.Scope = analyzeScope(program)
+}
+
+func analyzeScope(root Node) *Scope {
+	scope := &Scope{}
+	names := make(map[string]bool)
+	Inspect(root, func(n Node) bool {
+		if n == root {
+			return true
+		}
+		var inner *Scope
+		switch n := n.(type) {
+		case *Identifier:
+			names[n.Value] = true
+		case *ThisExpression:
+			names["this"] = true
+		case *SuperExpression:
+			// super.name and super() both use the this binding.
+			names["super"] = true
+			names["this"] = true
+		case *MetaProperty:
+			names[n.Meta+"."+n.Property] = true
+		case *PrivateIdentifier:
+			names[n.Name] = true
+		case *CallExpression:
+			if callee, ok := n.Callee.(*Identifier); ok && callee.Value == "eval" {
+				scope.DirectEval = true
+			}
+		case *WithStatement:
+			scope.DirectEval = true
+		case *FunctionDeclaration:
+			n.Scope = analyzeScope(n)
+			inner = n.Scope
+		case *FunctionExpression:
+			n.Scope = analyzeScope(n)
+			inner = n.Scope
+		case *ArrowFunctionExpression:
+			n.Scope = analyzeScope(n)
+			inner = n.Scope
+		}
+		if inner == nil {
+			return true
+		}
+		for _, name := range inner.Free {
+			names[name] = true
+		}
+		scope.Dynamic = scope.Dynamic || inner.Dynamic
+		return false
+	})
+	scope.Dynamic = scope.Dynamic || scope.DirectEval
+	scope.Free = make([]string, 0, len(names))
+	for name := range names {
+		scope.Free = append(scope.Free, name)
+	}
+	sort.Strings(scope.Free)
+	return scope
+}
@@ -0,0 +1,211 @@
+package ast
+
+// Inspect traverses the tree rooted at node in depth-first order. It calls
+// f(node) first; if f returns true, Inspect visits each non-nil child of node
+// in source order.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child, f)
+	}
+}
+
+// children returns the direct, non-nil child nodes of n.
+func children(n Node) []Node {
+	var out []Node
+	add := func(nodes ...Node) {
+		for _, c := range nodes {
+			if c != nil {
+				out = append(out, c)
+			}
+		}
+	}
+	addExprs := func(exprs []Expression) {
+		for _, e := range exprs {
+			add(e)
+		}
+	}
+	addStmts := func(stmts []Statement) {
+		for _, s := range stmts {
+			add(s)
+		}
+	}
+	addFunc := func(name *Identifier, params, defaults []Expression, rest Expression, body Node) {
+		if name != nil {
+			add(name)
+		}
+		addExprs(params)
+		addExprs(defaults)
+		add(rest, body)
+	}
+
+	switch n := n.(type) {
+	case *Program:
+		addStmts(n.Statements)
+	case *VariableDeclaration:
+		for _, d := range n.Declarations {
+			add(d)
+		}
+	case *VariableDeclarator:
+		add(n.Name, n.Value)
+	case *ExpressionStatement:
+		add(n.Expression)
+	case *BlockStatement:
+		addStmts(n.Statements)
+	case *ReturnStatement:
+		add(n.Value)
+	case *IfStatement:
+		add(n.Condition, block(n.Consequence), n.Alternative)
+	case *WhileStatement:
+		add(n.Condition, n.Body)
+	case *DoWhileStatement:
+		add(n.Body, n.Condition)
+	case *ForStatement:
+		add(n.Init, n.Test, n.Update, n.Body)
+	case *ForInStatement:
+		add(n.Left, n.Right, n.Body)
+	case *ForOfStatement:
+		add(n.Left, n.Right, n.Body)
+	case *SwitchStatement:
+		add(n.Discriminant)
+		for _, c := range n.Cases {
+			add(c)
+		}
+	case *SwitchCase:
+		add(n.Test)
+		addStmts(n.Consequent)
+	case *ThrowStatement:
+		add(n.Argument)
+	case *TryStatement:
+		add(block(n.Block))
+		if n.Handler != nil {
+			add(n.Handler)
+		}
+		add(block(n.Finalizer))
+	case *CatchClause:
+		add(n.Param, block(n.Body))
+	case *FunctionDeclaration:
+		addFunc(n.Name, n.Params, n.Defaults, n.Rest, block(n.Body))
+	case *ClassDeclaration:
+		if n.Name != nil {
+			add(n.Name)
+		}
+		add(n.SuperClass)
+		if n.Body != nil {
+			add(n.Body)
+		}
+	case *ClassBody:
+		for _, m := range n.Methods {
+			add(m)
+		}
+		for _, f := range n.Fields {
+			add(f)
+		}
+	case *MethodDefinition:
+		add(n.Key)
+		if n.Value != nil {
+			add(n.Value)
+		}
+	case *FieldDefinition:
+		add(n.Key, n.Value)
+		if n.Block != nil {
+			add(n.Block)
+		}
+	case *LabeledStatement:
+		add(n.Body)
+	case *WithStatement:
+		add(n.Object, n.Body)
+	case *ImportDeclaration:
+		for _, s := range n.Specifiers {
+			add(s)
+		}
+	case *ImportSpecifier:
+		if n.Local != nil {
+			add(n.Local)
+		}
+	case *ExportNamedDeclaration:
+		add(n.Declaration)
+	case *ExportDefaultDeclaration:
+		add(n.Declaration)
+	case *ArrayLiteral:
+		addExprs(n.Elements)
+	case *ObjectLiteral:
+		for _, p := range n.Properties {
+			add(p)
+		}
+	case *Property:
+		add(n.Key, n.Value)
+	case *FunctionExpression:
+		addFunc(n.Name, n.Params, n.Defaults, n.Rest, block(n.Body))
+	case *ArrowFunctionExpression:
+		addFunc(nil, n.Params, n.Defaults, n.Rest, n.Body)
+	case *UnaryExpression:
+		add(n.Operand)
+	case *UpdateExpression:
+		add(n.Operand)
+	case *BinaryExpression:
+		add(n.Left, n.Right)
+	case *LogicalExpression:
+		add(n.Left, n.Right)
+	case *AssignmentExpression:
+		add(n.Left, n.Right)
+	case *ConditionalExpression:
+		add(n.Test, n.Consequent, n.Alternate)
+	case *CallExpression:
+		add(n.Callee)
+		addExprs(n.Arguments)
+	case *MemberExpression:
+		add(n.Object, n.Property)
+	case *ChainExpression:
+		add(n.Expression)
+	case *NewExpression:
+		add(n.Callee)
+		addExprs(n.Arguments)
+	case *SequenceExpression:
+		addExprs(n.Expressions)
+	case *TemplateLiteralExpr:
+		addExprs(n.Expressions)
+	case *TaggedTemplateExpression:
+		add(n.Tag)
+		if n.Quasi != nil {
+			add(n.Quasi)
+		}
+	case *SpreadElement:
+		add(n.Argument)
+	case *YieldExpression:
+		add(n.Argument)
+	case *AwaitExpression:
+		add(n.Argument)
+	case *ClassExpression:
+		if n.Name != nil {
+			add(n.Name)
+		}
+		add(n.SuperClass)
+		if n.Body != nil {
+			add(n.Body)
+		}
+	case *ObjectPattern:
+		for _, p := range n.Properties {
+			add(p)
+		}
+	case *ArrayPattern:
+		addExprs(n.Elements)
+	case *AssignmentPattern:
+		add(n.Left, n.Right)
+	case *RestElement:
+		add(n.Argument)
+	case *ComputedPropertyName:
+		add(n.Expression)
+	}
+	return out
+}
+
+// block converts b to a Node, mapping a nil block to a nil interface.
+func block(b *BlockStatement) Node {
+	if b == nil {
+		return nil
+	}
+	return b
+}
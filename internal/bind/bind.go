@@ -0,0 +1,168 @@
+// Package bind exposes Go structs to scripts as host objects. Where
+// runtime.GoConverter copies a struct into a new object, a bound struct
+// stays live: its exported fields are accessor properties that read and
+// write the Go value, and its exported methods are functions that call the
+// Go methods, so that a host can hand a script a database handle or a
+// service rather than a snapshot of one.
+package bind
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// Binder binds structs using Conv, which converts the values that go in
+// and out of fields and methods; a nil Conv uses the default rules. The
+// host objects belong to Conv's realm. Conv is read when the Binder is
+// first used. The zero Binder is ready to use.
+type Binder struct {
+	Conv *runtime.GoConverter
+
+	conv *runtime.GoConverter // Conv, also binding the structs it converts
+}
+
+// ToValue converts x like the converter does, except that a struct or a
+// pointer to one is bound, see Object. Structs reached through a bound
+// object, in its fields and in the results of its methods, are bound too.
+func (b *Binder) ToValue(x interface{}) (*runtime.Value, error) {
+	return b.converter().ToValue(x)
+}
+
+// Object binds x, a struct or a non-nil pointer to one, as a host object.
+// The object has an enumerable accessor property for each exported field,
+// named as runtime.FieldName names it: reading it converts the field's
+// current value and assigning to it stores the value into the field, or
+// throws a TypeError when it does not convert to the field's type. It has
+// a method for each exported method of the pointer type, which converts
+// its arguments as runtime.GoConverter.WrapFunc does, variadic ones
+// included, and throws the error the method returns. A struct value is
+// copied first, so that all of its methods can be called on the copy.
+func (b *Binder) Object(x interface{}) (*runtime.Value, error) {
+	ptr, ok := structPointer(reflect.ValueOf(x))
+	if !ok {
+		return nil, fmt.Errorf("cannot bind %T: not a struct or a pointer to one", x)
+	}
+	return b.bind(ptr), nil
+}
+
+// converter returns Conv, with structs bound instead of copied.
+func (b *Binder) converter() *runtime.GoConverter {
+	if b.conv != nil {
+		return b.conv
+	}
+	conv := &runtime.GoConverter{}
+	if b.Conv != nil {
+		*conv = *b.Conv
+	}
+	fromGo := conv.FromGo
+	conv.FromGo = func(rv reflect.Value) (*runtime.Value, bool, error) {
+		if fromGo != nil {
+			if v, ok, err := fromGo(rv); ok || err != nil {
+				return v, ok, err
+			}
+		}
+		if ptr, ok := structPointer(rv); ok {
+			return b.bind(ptr), true, nil
+		}
+		return nil, false, nil
+	}
+	b.conv = conv
+	return conv
+}
+
+// structPointer returns a pointer to the struct rv holds: rv itself for a
+// non-nil pointer to a struct, and a pointer to a copy for a struct.
+func structPointer(rv reflect.Value) (reflect.Value, bool) {
+	switch {
+	case rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct:
+		return rv, true
+	case rv.Kind() == reflect.Struct:
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		return ptr, true
+	}
+	return reflect.Value{}, false
+}
+
+// bind makes the host object of the struct ptr points to.
+func (b *Binder) bind(ptr reflect.Value) *runtime.Value {
+	conv := b.converter()
+	realm := conv.Realm
+	if realm == nil {
+		realm = &runtime.Realm{}
+	}
+	obj := runtime.NewOrdinaryObject(realm.ObjectPrototype)
+	t := ptr.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := runtime.FieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		obj.DefineProperty(name, &runtime.Property{
+			Getter:       newFunction(realm, "get "+name, 0, b.getter(ptr.Elem().Field(i))),
+			Setter:       newFunction(realm, "set "+name, 1, setter(conv, name, ptr.Elem().Field(i))),
+			IsAccessor:   true,
+			Enumerable:   true,
+			Configurable: true,
+		})
+	}
+	pt := ptr.Type()
+	for i := 0; i < pt.NumMethod(); i++ {
+		method := pt.Method(i)
+		fn, err := conv.WrapFunc(ptr.Method(i).Interface())
+		if err != nil {
+			continue
+		}
+		length := method.Type.NumIn() - 1 // without the receiver
+		if method.Type.IsVariadic() {
+			length--
+		}
+		obj.DefineProperty(method.Name, &runtime.Property{
+			Value:        newFunction(realm, method.Name, length, fn),
+			Writable:     true,
+			Configurable: true,
+			HasValue:     true,
+		})
+	}
+	return runtime.NewObject(obj)
+}
+
+// getter reads field. A struct field is bound once, to the field itself,
+// so that changes made through it are seen by the Go code.
+func (b *Binder) getter(field reflect.Value) runtime.CallableFunc {
+	var bound *runtime.Value
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if field.Kind() == reflect.Struct {
+			if bound == nil {
+				bound = b.bind(field.Addr())
+			}
+			return bound, nil
+		}
+		return b.converter().ToValue(field.Interface())
+	}
+}
+
+// setter stores its argument into field.
+func setter(conv *runtime.GoConverter, name string, field reflect.Value) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		val := runtime.Undefined
+		if len(args) > 0 {
+			val = args[0]
+		}
+		if err := conv.ExportTo(val, field); err != nil {
+			return nil, fmt.Errorf("TypeError: %s: %v", name, err)
+		}
+		return runtime.Undefined, nil
+	}
+}
+
+// newFunction makes a function value of realm with the name and length
+// properties of a built-in function.
+func newFunction(realm *runtime.Realm, name string, length int, fn runtime.CallableFunc) *runtime.Value {
+	obj := runtime.NewFunctionObject(realm.FunctionPrototype, fn)
+	obj.DefineProperty("name", &runtime.Property{Value: runtime.NewString(name), Configurable: true, HasValue: true})
+	obj.DefineProperty("length", &runtime.Property{Value: runtime.NewNumber(float64(length)), Configurable: true, HasValue: true})
+	return runtime.NewObject(obj)
+}
@@ -0,0 +1,102 @@
+package bind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+type point struct {
+	X, Y int
+}
+
+type counter struct {
+	Name   string `js:"name"`
+	Origin point  `js:"origin"`
+	Hidden int    `js:"-"`
+	count  int
+}
+
+func (c *counter) Add(n ...int) int {
+	for _, d := range n {
+		c.count += d
+	}
+	return c.count
+}
+
+func (c counter) Fail() error { return errors.New("RangeError: failed") }
+
+func (c *counter) Next() *point { return &point{c.count, c.count} }
+
+func call(t *testing.T, obj *runtime.Object, name string, args ...*runtime.Value) (*runtime.Value, error) {
+	t.Helper()
+	fn := obj.Get(name)
+	if fn.Type != runtime.TypeObject || fn.Object.Callable == nil {
+		t.Fatalf("%s is not a method", name)
+	}
+	return fn.Object.Callable(runtime.NewObject(obj), args)
+}
+
+func TestBinder(t *testing.T) {
+	var b Binder
+	c := &counter{Name: "c", Origin: point{1, 2}}
+	v, err := b.Object(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := v.Object
+
+	// Fields are live in both directions.
+	if got := obj.Get("name").Str; got != "c" {
+		t.Errorf("name = %q", got)
+	}
+	c.Name = "renamed"
+	if got := obj.Get("name").Str; got != "renamed" {
+		t.Errorf("name after a change in Go = %q", got)
+	}
+	obj.Set("name", runtime.NewString("set"))
+	if c.Name != "set" {
+		t.Errorf("Go field after an assignment = %q", c.Name)
+	}
+	if obj.HasProperty("Hidden") || obj.HasProperty("count") {
+		t.Error("hidden and unexported fields should not be bound")
+	}
+	if err := obj.SetErr("name", runtime.NewNumber(1)); err == nil {
+		t.Error("assigning a number to a string field should throw")
+	}
+
+	// A struct field is bound to the field itself, once.
+	origin := obj.Get("origin")
+	origin.Object.Set("X", runtime.NewNumber(5))
+	if c.Origin.X != 5 || obj.Get("origin") != origin {
+		t.Errorf("origin = %+v", c.Origin)
+	}
+
+	// Methods, variadic ones included, and their errors.
+	if r, err := call(t, obj, "Add", runtime.NewNumber(2), runtime.NewNumber(3)); err != nil || r.Number != 5 {
+		t.Errorf("Add(2, 3) = %v, %v", r, err)
+	}
+	if r, err := call(t, obj, "Add"); err != nil || r.Number != 5 || c.count != 5 {
+		t.Errorf("Add() = %v, %v", r, err)
+	}
+	if _, err := call(t, obj, "Fail"); err == nil || err.Error() != "RangeError: failed" {
+		t.Errorf("Fail() error = %v", err)
+	}
+	if length := obj.Get("Add").Object.Get("length").Number; length != 0 {
+		t.Errorf("Add.length = %v", length)
+	}
+
+	// Results that are structs are bound too.
+	next, err := call(t, obj, "Next")
+	if err != nil || next.Object.Get("X").Number != 5 {
+		t.Fatalf("Next() = %v, %v", next, err)
+	}
+	if prop := next.Object.Properties["X"]; prop == nil || !prop.IsAccessor {
+		t.Error("the result of Next should be bound")
+	}
+
+	if _, err := b.Object(42); err == nil {
+		t.Error("binding a number should fail")
+	}
+}
@@ -0,0 +1,1287 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createArrayConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	proto.OType = runtime.ObjTypeArray
+	proto.ArrayData = []*runtime.Value{}
+	rl.ArrayPrototype = proto
+
+	method := func(name string, length int, fn runtime.CallableFunc) {
+		rl.setMethod(proto, name, length, rl.objectThis(name, fn))
+	}
+	method("push", 1, rl.arrayPush)
+	method("pop", 0, arrayPop)
+	method("shift", 0, rl.arrayShift)
+	method("unshift", 1, rl.arrayUnshift)
+	method("splice", 2, rl.arraySplice)
+	method("slice", 2, rl.arraySlice)
+	method("concat", 1, rl.arrayConcat)
+	method("indexOf", 1, arrayIndexOf)
+	method("lastIndexOf", 1, arrayLastIndexOf)
+	method("includes", 1, arrayIncludes)
+	method("find", 1, arrayFind)
+	method("findIndex", 1, arrayFindIndex)
+	method("forEach", 1, arrayForEach)
+	method("map", 1, rl.arrayMap)
+	method("filter", 1, rl.arrayFilter)
+	method("reduce", 1, arrayReduce)
+	method("reduceRight", 1, arrayReduceRight)
+	method("every", 1, arrayEvery)
+	method("some", 1, arraySome)
+	method("sort", 1, rl.arraySort)
+	method("reverse", 0, rl.arrayReverse)
+	method("fill", 1, rl.arrayFill)
+	method("copyWithin", 2, rl.arrayCopyWithin)
+	method("join", 1, rl.arrayJoin)
+	method("toString", 0, rl.arrayToString)
+	method("keys", 0, rl.arrayKeys)
+	method("values", 0, rl.arrayValues)
+	method("entries", 0, rl.arrayEntries)
+	method("flat", 0, rl.arrayFlat)
+	method("flatMap", 1, rl.arrayFlatMap)
+
+	rl.ArrayIteratorPrototype = runtime.NewOrdinaryObject(objProto)
+	rl.setMethod(rl.ArrayIteratorPrototype, "next", 0, rl.arrayIteratorNext)
+	setDataProp(rl.ArrayIteratorPrototype, "@@toStringTag", runtime.NewString("Array Iterator"), false, false, true)
+
+	ctor := rl.newFuncObject("Array", 1, rl.arrayConstructorCall)
+	ctor.Constructor = rl.arrayConstructorCall
+
+	rl.setMethod(ctor, "isArray", 1, arrayIsArray)
+	rl.setMethod(ctor, "from", 1, rl.arrayFrom)
+	rl.setMethod(ctor, "of", 0, rl.arrayOf)
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+// objectThis makes an Array.prototype method convert its this value to an
+// object as ToObject does: a primitive is wrapped, so that join, map and
+// the other generic methods act on the characters of a string, and
+// undefined and null throw.
+func (rl *Realm) objectThis(name string, fn runtime.CallableFunc) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+			return nil, fmt.Errorf("TypeError: Array.prototype.%s called on null or undefined", name)
+		}
+		if this.Type != runtime.TypeObject {
+			obj, err := rl.ToObject(this)
+			if err != nil {
+				return nil, err
+			}
+			this = runtime.NewObject(obj)
+		}
+		return fn(this, args)
+	}
+}
+
+func (rl *Realm) newArray(data []*runtime.Value) *runtime.Object {
+	arr := &runtime.Object{
+		OType:      runtime.ObjTypeArray,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  rl.ArrayPrototype,
+		ArrayData:  data,
+	}
+	arr.Set("length", runtime.NewNumber(float64(len(data))))
+	return arr
+}
+
+// lengthOf returns the length of obj: the length of an array, or for any
+// other object, such as arguments, its length property.
+func lengthOf(obj *runtime.Object) int {
+	if obj.OType == runtime.ObjTypeArray {
+		return obj.ArrayLength()
+	}
+	n := toInteger(obj.Get("length"))
+	if n <= 0 {
+		return 0
+	}
+	return int(math.Min(n, 1<<53-1))
+}
+
+// hasElement reports whether obj has an element at index i, which for an
+// array means that i is not a hole.
+func hasElement(obj *runtime.Object, i int) bool {
+	if obj.OType == runtime.ObjTypeArray && i < len(obj.ArrayData) && obj.ArrayData[i] != nil {
+		return true
+	}
+	return obj.HasProperty(strconv.Itoa(i))
+}
+
+// elementIndices returns the indices below length at which obj has an
+// element, in ascending order. The holes of an array are skipped without
+// visiting them, so a sparse array costs no more than its elements.
+func elementIndices(obj *runtime.Object, length int) []int {
+	if obj.OType == runtime.ObjTypeArray {
+		indices := obj.ElementIndices()
+		return indices[:sort.SearchInts(indices, length)]
+	}
+	var indices []int
+	for i := 0; i < length; i++ {
+		if hasElement(obj, i) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// eachElement calls fn with the elements of obj below its length in
+// ascending order, as forEach, map and the other methods taking a callback
+// visit them: holes are skipped, and so are elements deleted by an earlier
+// call. It stops early when fn returns false or an error.
+func eachElement(obj *runtime.Object, fn func(i int, v *runtime.Value) (bool, error)) error {
+	for _, i := range elementIndices(obj, lengthOf(obj)) {
+		if !hasElement(obj, i) {
+			continue
+		}
+		more, err := fn(i, elementAt(obj, i))
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// newSparseArray returns an empty array of the given length, to be filled
+// with SetArrayElement, which stays sparse if the source of its elements
+// was.
+func (rl *Realm) newSparseArray(length int) *runtime.Object {
+	arr := rl.newArray(nil)
+	arr.SetArrayLength(length)
+	return arr
+}
+
+// relativeIndex converts the start or end argument of slice, fill and the
+// like to an index from 0 to length: a negative one counts from the end,
+// and a missing one is def.
+func relativeIndex(arg *runtime.Value, length, def int) int {
+	if arg == nil || arg.Type == runtime.TypeUndefined {
+		return def
+	}
+	n := toInteger(arg)
+	if n < 0 {
+		n = math.Max(float64(length)+n, 0)
+	}
+	return int(math.Min(n, float64(length)))
+}
+
+func getArrayData(v *runtime.Value) []*runtime.Value {
+	obj := toObject(v)
+	if obj == nil {
+		return nil
+	}
+	return obj.ArrayData
+}
+
+func (rl *Realm) arrayConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 1 && args[0].Type == runtime.TypeNumber {
+		n, err := runtime.ToArrayLength(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := rl.Agent.ChargeSlots(min(n, runtime.MaxArrayGap)); err != nil {
+			return nil, err
+		}
+		return runtime.NewObject(rl.newSparseArray(n)), nil
+	}
+	data := make([]*runtime.Value, len(args))
+	copy(data, args)
+	return runtime.NewObject(rl.newArray(data)), nil
+}
+
+func arrayIsArray(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	isArray, err := runtime.IsArray(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(isArray), nil
+}
+
+// arrayFrom implements Array.from. Iterables go through the iteration
+// protocol; other objects are read as array-likes through their length.
+func (rl *Realm) arrayFrom(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	src := argAt(args, 0)
+	var mapFn runtime.CallableFunc
+	if mapArg := argAt(args, 1); mapArg.Type != runtime.TypeUndefined {
+		if mapFn = getCallable(mapArg); mapFn == nil {
+			return nil, fmt.Errorf("TypeError: %s is not a function", mapArg.ToString())
+		}
+	}
+	thisArg := argAt(args, 2)
+	if src.Type == runtime.TypeUndefined || src.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: %s is not iterable", src.ToString())
+	}
+
+	data := []*runtime.Value{}
+	add := func(val *runtime.Value) error {
+		if err := rl.Agent.ChargeSlots(1); err != nil {
+			return err
+		}
+		if mapFn != nil {
+			mapped, err := mapFn(thisArg, []*runtime.Value{val, runtime.NewNumber(float64(len(data)))})
+			if err != nil {
+				return err
+			}
+			val = mapped
+		}
+		data = append(data, val)
+		return nil
+	}
+	if rl.isIterable(src) {
+		if err := rl.iterate(src, add); err != nil {
+			return nil, err
+		}
+		return rl.fillArrayFrom(this, nil, data)
+	}
+	if obj := toObject(src); obj != nil {
+		length := int(toInteger(obj.Get("length")))
+		for i := 0; i < length; i++ {
+			if err := add(elementAt(obj, i)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rl.fillArrayFrom(this, []*runtime.Value{runtime.NewNumber(float64(len(data)))}, data)
+}
+
+func (rl *Realm) arrayOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if err := rl.Agent.ChargeSlots(len(args)); err != nil {
+		return nil, err
+	}
+	data := make([]*runtime.Value, len(args))
+	copy(data, args)
+	return rl.fillArrayFrom(this, []*runtime.Value{runtime.NewNumber(float64(len(data)))}, data)
+}
+
+// fillArrayFrom returns the result of Array.from or Array.of called on c
+// with the elements data. When c is a constructor other than Array, as for
+// a subclass, the result is new c(ctorArgs...) with the elements and a
+// length defined on it; otherwise it is a new array.
+func (rl *Realm) fillArrayFrom(c *runtime.Value, ctorArgs, data []*runtime.Value) (*runtime.Value, error) {
+	if c == nil || c.Type != runtime.TypeObject || c.Object == nil || c.Object.Constructor == nil {
+		return runtime.NewObject(rl.newArray(data)), nil
+	}
+	if proto := c.Object.Get("prototype"); proto.Type == runtime.TypeObject && proto.Object == rl.ArrayPrototype {
+		return runtime.NewObject(rl.newArray(data)), nil
+	}
+	result, err := rl.Construct(c.Object, ctorArgs, c.Object)
+	if err != nil {
+		return nil, err
+	}
+	obj := result.Object
+	for i, v := range data {
+		if obj.OType == runtime.ObjTypeArray && obj.Properties[strconv.Itoa(i)] == nil {
+			if !obj.CanSetElement(i) {
+				return nil, fmt.Errorf("TypeError: Cannot add property %d, object is not extensible", i)
+			}
+			obj.SetArrayElement(i, v)
+			continue
+		}
+		mergeAndDefineProperty(obj, strconv.Itoa(i), &runtime.Property{Value: v, Writable: true, Enumerable: true, Configurable: true, HasValue: true})
+	}
+	if err := obj.SetErr("length", runtime.NewNumber(float64(len(data)))); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (rl *Realm) arrayPush(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.Undefined, nil
+	}
+	length := lengthOf(obj)
+	if length+len(args) > 1<<53-1 {
+		return nil, fmt.Errorf("TypeError: Pushing %d elements on an array-like of length %d is disallowed, as the total surpasses 2**53-1", len(args), length)
+	}
+	if err := rl.Agent.ChargeSlots(len(args)); err != nil {
+		return nil, err
+	}
+	if obj.OType != runtime.ObjTypeArray {
+		for i, arg := range args {
+			if err := obj.SetErr(strconv.Itoa(length+i), arg); err != nil {
+				return nil, err
+			}
+		}
+		length += len(args)
+		if err := obj.SetErr("length", runtime.NewNumber(float64(length))); err != nil {
+			return nil, err
+		}
+		return runtime.NewNumber(float64(length)), nil
+	}
+	if length+len(args) > runtime.MaxArrayLength {
+		return nil, fmt.Errorf("RangeError: Invalid array length")
+	}
+	for _, arg := range args {
+		if !obj.CanSetElement(length) {
+			return nil, fmt.Errorf("TypeError: Cannot add property %d, object is not extensible", length)
+		}
+		obj.SetArrayElement(length, arg)
+		length++
+	}
+	return runtime.NewNumber(float64(length)), nil
+}
+
+func arrayPop(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.Undefined, nil
+	}
+	length := obj.ArrayLength()
+	if length == 0 {
+		return runtime.Undefined, nil
+	}
+	last := elementAt(obj, length-1)
+	obj.SetArrayLength(length - 1)
+	return last, nil
+}
+
+func (rl *Realm) arrayShift(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil || obj.ArrayLength() == 0 {
+		return runtime.Undefined, nil
+	}
+	rl.densify(obj)
+	first := obj.ArrayData[0]
+	if first == nil {
+		first = elementAt(obj, 0)
+	}
+	obj.ArrayData = obj.ArrayData[1:]
+	obj.Set("length", runtime.NewNumber(float64(len(obj.ArrayData))))
+	return first, nil
+}
+
+func (rl *Realm) arrayUnshift(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.Undefined, nil
+	}
+	if err := rl.Agent.ChargeSlots(len(args)); err != nil {
+		return nil, err
+	}
+	rl.densify(obj)
+	obj.ArrayData = append(args, obj.ArrayData...)
+	length := float64(len(obj.ArrayData))
+	obj.Set("length", runtime.NewNumber(length))
+	return runtime.NewNumber(length), nil
+}
+
+func (rl *Realm) arraySplice(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	rl.densify(obj)
+	length := len(obj.ArrayData)
+	start := 0
+	if len(args) > 0 {
+		start = relativeIndex(args[0], length, 0)
+	}
+	deleteCount := length - start
+	if len(args) > 1 {
+		deleteCount = int(math.Max(0, math.Min(toInteger(args[1]), float64(length-start))))
+	}
+	items := args[min(len(args), 2):]
+	if err := rl.Agent.ChargeSlots(deleteCount + len(items)); err != nil {
+		return nil, err
+	}
+	removed := make([]*runtime.Value, deleteCount)
+	copy(removed, obj.ArrayData[start:start+deleteCount])
+	newData := make([]*runtime.Value, 0, length-deleteCount+len(items))
+	newData = append(newData, obj.ArrayData[:start]...)
+	newData = append(newData, items...)
+	newData = append(newData, obj.ArrayData[start+deleteCount:]...)
+	obj.ArrayData = newData
+	obj.Set("length", runtime.NewNumber(float64(len(newData))))
+	return runtime.NewObject(rl.newArray(removed)), nil
+}
+
+func (rl *Realm) arraySlice(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	length := lengthOf(obj)
+	start := relativeIndex(argAt(args, 0), length, 0)
+	end := relativeIndex(argAt(args, 1), length, length)
+	if start >= end {
+		return runtime.NewObject(rl.newArray([]*runtime.Value{})), nil
+	}
+	indices := elementIndices(obj, end)
+	indices = indices[sort.SearchInts(indices, start):]
+	if err := rl.Agent.ChargeSlots(len(indices)); err != nil {
+		return nil, err
+	}
+	result := rl.newSparseArray(end - start)
+	for _, i := range indices {
+		result.SetArrayElement(i-start, elementAt(obj, i))
+	}
+	return runtime.NewObject(result), nil
+}
+
+func (rl *Realm) arrayConcat(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	result := rl.newArray(nil)
+	appendArray := func(arr *runtime.Object) error {
+		indices := arr.ElementIndices()
+		if err := rl.Agent.ChargeSlots(len(indices)); err != nil {
+			return err
+		}
+		base := result.ArrayLength()
+		for _, i := range indices {
+			result.SetArrayElement(base+i, elementAt(arr, i))
+		}
+		result.SetArrayLength(base + arr.ArrayLength())
+		return nil
+	}
+	if obj != nil && obj.OType != runtime.ObjTypeArray {
+		// An object that is not an array is an element of its own.
+		args = append([]*runtime.Value{this}, args...)
+	} else if obj != nil {
+		if err := appendArray(obj); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range args {
+		if a.Type == runtime.TypeObject && a.Object != nil && a.Object.OType == runtime.ObjTypeArray {
+			if err := appendArray(a.Object); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := rl.Agent.ChargeSlots(1); err != nil {
+			return nil, err
+		}
+		result.SetArrayElement(result.ArrayLength(), a)
+	}
+	return runtime.NewObject(result), nil
+}
+
+func arrayIndexOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewNumber(-1), nil
+	}
+	length := lengthOf(obj)
+	if length == 0 {
+		return runtime.NewNumber(-1), nil
+	}
+	from := 0.0
+	if len(args) > 1 {
+		n, err := toIntegerErr(args[1])
+		if err != nil {
+			return nil, err
+		}
+		from = n
+	}
+	if from < 0 {
+		from = math.Max(float64(length)+from, 0)
+	}
+	search := argAt(args, 0)
+	for _, i := range elementIndices(obj, length) {
+		if float64(i) >= from && strictEquals(elementAt(obj, i), search) {
+			return runtime.NewNumber(float64(i)), nil
+		}
+	}
+	return runtime.NewNumber(-1), nil
+}
+
+func arrayLastIndexOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewNumber(-1), nil
+	}
+	length := lengthOf(obj)
+	if length == 0 {
+		return runtime.NewNumber(-1), nil
+	}
+	from := float64(length - 1)
+	if len(args) > 1 {
+		n, err := toIntegerErr(args[1])
+		if err != nil {
+			return nil, err
+		}
+		from = n
+	}
+	if from < 0 {
+		from = float64(length) + from
+	}
+	search := argAt(args, 0)
+	indices := elementIndices(obj, length)
+	for j := len(indices) - 1; j >= 0; j-- {
+		if i := indices[j]; float64(i) <= from && strictEquals(elementAt(obj, i), search) {
+			return runtime.NewNumber(float64(i)), nil
+		}
+	}
+	return runtime.NewNumber(-1), nil
+}
+
+func arrayIncludes(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.False, nil
+	}
+	length := lengthOf(obj)
+	search := argAt(args, 0)
+	from := relativeIndex(argAt(args, 1), length, 0)
+	// Holes read as undefined, so only a search for undefined needs them.
+	if search.Type != runtime.TypeUndefined {
+		for _, i := range elementIndices(obj, length) {
+			if i >= from && sameValueZero(elementAt(obj, i), search) {
+				return runtime.True, nil
+			}
+		}
+		return runtime.False, nil
+	}
+	for i := from; i < length; i++ {
+		if sameValueZero(elementAt(obj, i), search) {
+			return runtime.True, nil
+		}
+	}
+	return runtime.False, nil
+}
+
+func arrayFind(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.Undefined, nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	for i, length := 0, lengthOf(obj); i < length; i++ {
+		v := elementAt(obj, i)
+		result, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		if err != nil {
+			return nil, err
+		}
+		if result.ToBoolean() {
+			return v, nil
+		}
+	}
+	return runtime.Undefined, nil
+}
+
+func arrayFindIndex(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewNumber(-1), nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	for i, length := 0, lengthOf(obj); i < length; i++ {
+		result, err := cb(this, []*runtime.Value{elementAt(obj, i), runtime.NewNumber(float64(i)), this})
+		if err != nil {
+			return nil, err
+		}
+		if result.ToBoolean() {
+			return runtime.NewNumber(float64(i)), nil
+		}
+	}
+	return runtime.NewNumber(-1), nil
+}
+
+func arrayForEach(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.Undefined, nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+		_, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.Undefined, nil
+}
+
+func (rl *Realm) arrayMap(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	// The result has the holes of the source.
+	result := rl.newSparseArray(lengthOf(obj))
+	err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+		r, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		if err == nil {
+			err = rl.Agent.ChargeSlots(1)
+		}
+		if err == nil {
+			result.SetArrayElement(i, r)
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(result), nil
+}
+
+func (rl *Realm) arrayFilter(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	var result []*runtime.Value
+	err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+		r, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		if err == nil && r.ToBoolean() {
+			if err = rl.Agent.ChargeSlots(1); err == nil {
+				result = append(result, v)
+			}
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(rl.newArray(result)), nil
+}
+
+func arrayReduce(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	obj := toObject(this)
+	var acc *runtime.Value
+	if len(args) > 1 {
+		acc = args[1]
+	}
+	if obj != nil {
+		err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+			if acc == nil {
+				acc = v
+				return true, nil
+			}
+			r, err := cb(runtime.Undefined, []*runtime.Value{acc, v, runtime.NewNumber(float64(i)), this})
+			acc = r
+			return true, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("TypeError: Reduce of empty array with no initial value")
+	}
+	return acc, nil
+}
+
+func arrayReduceRight(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	obj := toObject(this)
+	var acc *runtime.Value
+	if len(args) > 1 {
+		acc = args[1]
+	}
+	if obj != nil {
+		indices := elementIndices(obj, lengthOf(obj))
+		for j := len(indices) - 1; j >= 0; j-- {
+			// The callback may have deleted elements or shortened the
+			// array; those are skipped.
+			i := indices[j]
+			if !hasElement(obj, i) {
+				continue
+			}
+			v := elementAt(obj, i)
+			if acc == nil {
+				acc = v
+				continue
+			}
+			r, err := cb(runtime.Undefined, []*runtime.Value{acc, v, runtime.NewNumber(float64(i)), this})
+			if err != nil {
+				return nil, err
+			}
+			acc = r
+		}
+	}
+	if acc == nil {
+		return nil, fmt.Errorf("TypeError: Reduce of empty array with no initial value")
+	}
+	return acc, nil
+}
+
+func arrayEvery(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.True, nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	every := true
+	err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+		r, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		if err == nil && !r.ToBoolean() {
+			every = false
+		}
+		return every, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(every), nil
+}
+
+func arraySome(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.False, nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	some := false
+	err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+		r, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		if err == nil && r.ToBoolean() {
+			some = true
+		}
+		return !some, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(some), nil
+}
+
+func (rl *Realm) arraySort(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return this, nil
+	}
+	if err := rl.densify(obj); err != nil {
+		return nil, err
+	}
+	compareFn := getCallable(argAt(args, 0))
+	var cmpErr error // the first exception of compareFn, which ends the sort
+	sort.SliceStable(obj.ArrayData, func(i, j int) bool {
+		a := obj.ArrayData[i]
+		b := obj.ArrayData[j]
+		// Holes sort last, after undefined.
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		if a.Type == runtime.TypeUndefined && b.Type == runtime.TypeUndefined {
+			return false
+		}
+		if a.Type == runtime.TypeUndefined {
+			return false
+		}
+		if b.Type == runtime.TypeUndefined {
+			return true
+		}
+		if compareFn != nil {
+			if cmpErr != nil {
+				return false
+			}
+			r, err := compareFn(runtime.Undefined, []*runtime.Value{a, b})
+			if err != nil {
+				cmpErr = err
+				return false
+			}
+			return r.ToNumber() < 0
+		}
+		return a.ToString() < b.ToString()
+	})
+	if cmpErr != nil {
+		return nil, cmpErr
+	}
+	return this, nil
+}
+
+func (rl *Realm) arrayReverse(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return this, nil
+	}
+	if err := rl.densify(obj); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(obj.ArrayData)-1; i < j; i, j = i+1, j-1 {
+		obj.ArrayData[i], obj.ArrayData[j] = obj.ArrayData[j], obj.ArrayData[i]
+	}
+	return this, nil
+}
+
+func (rl *Realm) arrayFill(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return this, nil
+	}
+	val := argAt(args, 0)
+	length := lengthOf(obj)
+	start := relativeIndex(argAt(args, 1), length, 0)
+	end := relativeIndex(argAt(args, 2), length, length)
+	if err := rl.Agent.ChargeSlots(end - start); err != nil {
+		return nil, err
+	}
+	for i := start; i < end; i++ {
+		if obj.OType == runtime.ObjTypeArray && obj.Properties[strconv.Itoa(i)] == nil {
+			if !obj.CanSetElement(i) {
+				return nil, fmt.Errorf("TypeError: Cannot add property %d, object is not extensible", i)
+			}
+			obj.SetArrayElement(i, val)
+			continue
+		}
+		if err := obj.SetErr(strconv.Itoa(i), val); err != nil {
+			return nil, err
+		}
+	}
+	return this, nil
+}
+
+func (rl *Realm) arrayCopyWithin(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return this, nil
+	}
+	if err := rl.densify(obj); err != nil {
+		return nil, err
+	}
+	length := len(obj.ArrayData)
+	target := relativeIndex(argAt(args, 0), length, 0)
+	start := relativeIndex(argAt(args, 1), length, 0)
+	end := relativeIndex(argAt(args, 2), length, length)
+	count := end - start
+	if count <= 0 {
+		return this, nil
+	}
+	temp := make([]*runtime.Value, count)
+	copy(temp, obj.ArrayData[start:start+count])
+	for i := 0; i < count && target+i < length; i++ {
+		obj.ArrayData[target+i] = temp[i]
+	}
+	return this, nil
+}
+
+func (rl *Realm) arrayJoin(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewString(""), nil
+	}
+	sep := ","
+	if len(args) > 0 && args[0].Type != runtime.TypeUndefined {
+		sep = args[0].ToString()
+	}
+	if rl.joining[obj] {
+		return runtime.NewString(""), nil
+	}
+	if rl.joining == nil {
+		rl.joining = make(map[*runtime.Object]bool)
+	}
+	rl.joining[obj] = true
+	defer delete(rl.joining, obj)
+	length := lengthOf(obj)
+	size := int64(len(sep)) * int64(length)
+	if err := rl.Agent.Charge(size); err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		v := elementAt(obj, i)
+		if v.Type == runtime.TypeUndefined || v.Type == runtime.TypeNull {
+			continue
+		}
+		s, err := rl.Agent.ToString(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := rl.Agent.Charge(int64(len(s))); err != nil {
+			return nil, err
+		}
+		sb.WriteString(s)
+	}
+	return runtime.NewString(sb.String()), nil
+}
+
+// arrayToString calls the join method of this, which an object that is
+// not an array may lack; it then falls back to Object.prototype.toString.
+func (rl *Realm) arrayToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if obj := toObject(this); obj != nil && obj.OType != runtime.ObjTypeArray {
+		join := getCallable(obj.Get("join"))
+		if join == nil {
+			return objectProtoToString(this, nil)
+		}
+		return join(this, nil)
+	}
+	return rl.arrayJoin(this, nil)
+}
+
+func (rl *Realm) arrayKeys(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.newArrayIterator(this, "keys")
+}
+
+func (rl *Realm) arrayValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.newArrayIterator(this, "values")
+}
+
+func (rl *Realm) arrayEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.newArrayIterator(this, "entries")
+}
+
+// newArrayIterator creates an array iterator over this yielding its keys,
+// values or [key, value] entries. The array is read live, and once the
+// iterator has reported done it stays done even if the array grows. Other
+// array-like objects, such as arguments, are read through length and their
+// index properties.
+func (rl *Realm) newArrayIterator(this *runtime.Value, kind string) (*runtime.Value, error) {
+	if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: Array.prototype.%s called on null or undefined", kind)
+	}
+	obj := toObject(this)
+	idx := 0
+	iter := runtime.NewOrdinaryObject(rl.ArrayIteratorPrototype)
+	iter.OType = runtime.ObjTypeIterator
+	iter.IteratorNext = func() (*runtime.Value, bool) {
+		if obj == nil {
+			return runtime.Undefined, true
+		}
+		if idx >= lengthOf(obj) {
+			obj = nil
+			return runtime.Undefined, true
+		}
+		val := elementAt(obj, idx)
+		key := runtime.NewNumber(float64(idx))
+		idx++
+		switch kind {
+		case "keys":
+			return key, false
+		case "entries":
+			return rl.createValueArray([]*runtime.Value{key, val}), false
+		}
+		return val, false
+	}
+	return runtime.NewObject(iter), nil
+}
+
+func (rl *Realm) arrayIteratorNext(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil || this.Object.IteratorNext == nil {
+		return nil, fmt.Errorf("TypeError: next method called on incompatible receiver")
+	}
+	return rl.makeIteratorNext(this.Object)(this, args)
+}
+
+func (rl *Realm) arrayFlat(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	depth := 1
+	if len(args) > 0 && args[0].Type == runtime.TypeNumber {
+		depth = int(args[0].Number)
+	}
+	result := flattenArray(obj, depth)
+	if err := rl.Agent.ChargeSlots(len(result)); err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(rl.newArray(result)), nil
+}
+
+func (rl *Realm) arrayFlatMap(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	cb := getCallable(argAt(args, 0))
+	if cb == nil {
+		return nil, fmt.Errorf("TypeError: callback is not a function")
+	}
+	var result []*runtime.Value
+	err := eachElement(obj, func(i int, v *runtime.Value) (bool, error) {
+		r, err := cb(this, []*runtime.Value{v, runtime.NewNumber(float64(i)), this})
+		if err != nil {
+			return false, err
+		}
+		items := []*runtime.Value{r}
+		if r.Type == runtime.TypeObject && r.Object != nil && r.Object.OType == runtime.ObjTypeArray {
+			items = flattenArray(r.Object, 0)
+		}
+		if err := rl.Agent.ChargeSlots(len(items)); err != nil {
+			return false, err
+		}
+		result = append(result, items...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(rl.newArray(result)), nil
+}
+
+// helpers
+
+// setIteratorMethods gives a native iterator its next method and a
+// Symbol.iterator method returning the iterator itself, so the iterator is
+// iterable too.
+func (rl *Realm) setIteratorMethods(iter *runtime.Object) {
+	rl.setMethod(iter, "next", 0, rl.makeIteratorNext(iter))
+	if SymIterator != nil {
+		self := rl.newFuncObject("[Symbol.iterator]", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return this, nil
+		})
+		setDataProp(iter, SymIterator.Key(), runtime.NewObject(self), true, false, true)
+	}
+}
+
+// installIteratorMethods adds Symbol.iterator to Array.prototype, as the
+// same function as values, and to String.prototype. Both constructors are
+// created before Symbol, so RegisterAll calls this once the symbol exists.
+func (rl *Realm) installIteratorMethods(arrayProto, stringProto *runtime.Object) {
+	values := arrayProto.Get("values")
+	setDataProp(arrayProto, SymIterator.Key(), values, true, false, true)
+	rl.ArrayIteratorMethod = values.Object
+	self := rl.newFuncObject("[Symbol.iterator]", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return this, nil
+	})
+	setDataProp(rl.ArrayIteratorPrototype, SymIterator.Key(), runtime.NewObject(self), true, false, true)
+
+	strIter := rl.newFuncObject("[Symbol.iterator]", 0, rl.stringIterator)
+	setDataProp(stringProto, SymIterator.Key(), runtime.NewObject(strIter), true, false, true)
+	rl.StringIteratorMethod = strIter
+}
+
+// installArrayUnscopables adds Array.prototype[Symbol.unscopables], which
+// keeps the newer array methods from shadowing outer names inside with.
+func installArrayUnscopables(arrayProto *runtime.Object) {
+	names := runtime.NewOrdinaryObject(nil)
+	for _, name := range []string{"at", "copyWithin", "entries", "fill", "find", "findIndex",
+		"findLast", "findLastIndex", "flat", "flatMap", "includes", "keys",
+		"toReversed", "toSorted", "toSpliced", "values"} {
+		names.Set(name, runtime.True)
+	}
+	setDataProp(arrayProto, SymUnscopables.Key(), runtime.NewObject(names), false, false, true)
+}
+
+// iteratorMethod returns v's Symbol.iterator method, or nil if v is not
+// iterable. Strings find theirs on String.prototype.
+func (rl *Realm) iteratorMethod(v *runtime.Value) runtime.CallableFunc {
+	if SymIterator == nil || v == nil {
+		return nil
+	}
+	switch {
+	case v.Type == runtime.TypeString && rl.StringPrototype != nil:
+		return getCallable(rl.StringPrototype.GetSymbol(SymIterator))
+	case v.Type == runtime.TypeObject && v.Object != nil:
+		return getCallable(v.Object.GetSymbol(SymIterator))
+	}
+	return nil
+}
+
+func (rl *Realm) isIterable(v *runtime.Value) bool {
+	return rl.HasDefaultIterator(v) || rl.iteratorMethod(v) != nil
+}
+
+// iterate calls fn with each value of the iterable v. Arrays and strings
+// that still use the built-in iterator are read directly; anything else
+// goes through v[Symbol.iterator]() and next(). If fn fails, the iterator's
+// return method is called before the error is passed on.
+func (rl *Realm) iterate(v *runtime.Value, fn func(val *runtime.Value) error) error {
+	if rl.HasDefaultIterator(v) {
+		if v.Type == runtime.TypeString {
+			for _, cp := range runtime.CodePoints(v.Str) {
+				if err := fn(runtime.NewString(cp)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		// Read live, so values appended by fn are visited, as with values().
+		obj := v.Object
+		for i := 0; i < obj.ArrayLength(); i++ {
+			if err := fn(elementAt(obj, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	method := rl.iteratorMethod(v)
+	if method == nil {
+		if v != nil && v.Type == runtime.TypeObject {
+			return fmt.Errorf("TypeError: object is not iterable")
+		}
+		return fmt.Errorf("TypeError: %s is not iterable", v.ToString())
+	}
+	iter, err := method(v, nil)
+	if err != nil {
+		return err
+	}
+	if iter == nil || iter.Type != runtime.TypeObject || iter.Object == nil {
+		return fmt.Errorf("TypeError: Result of the Symbol.iterator method is not an object")
+	}
+	next := getCallable(iter.Object.Get("next"))
+	if next == nil {
+		return fmt.Errorf("TypeError: iterator.next is not a function")
+	}
+	for {
+		result, err := next(iter, nil)
+		if err != nil {
+			return err
+		}
+		if result == nil || result.Type != runtime.TypeObject || result.Object == nil {
+			return fmt.Errorf("TypeError: Iterator result is not an object")
+		}
+		if result.Object.Get("done").ToBoolean() {
+			return nil
+		}
+		if err := fn(result.Object.Get("value")); err != nil {
+			// The error from fn wins over anything return() does.
+			if ret := getCallable(iter.Object.Get("return")); ret != nil {
+				_, _ = ret(iter, nil)
+			}
+			return err
+		}
+	}
+}
+
+// iterableToList collects the values of the iterable v.
+func (rl *Realm) iterableToList(v *runtime.Value) ([]*runtime.Value, error) {
+	var values []*runtime.Value
+	err := rl.iterate(v, func(val *runtime.Value) error {
+		values = append(values, val)
+		return nil
+	})
+	return values, err
+}
+
+func (rl *Realm) makeIteratorNext(iter *runtime.Object) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		val, done := iter.IteratorNext()
+		result := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+		result.Set("value", val)
+		result.Set("done", runtime.NewBool(done))
+		return runtime.NewObject(result), nil
+	}
+}
+
+// flattenArray returns the elements of the array arr, holes left out, with
+// the arrays among them flattened in turn down to depth levels.
+func flattenArray(arr *runtime.Object, depth int) []*runtime.Value {
+	var result []*runtime.Value
+	for _, i := range arr.ElementIndices() {
+		v := elementAt(arr, i)
+		if depth > 0 && v.Type == runtime.TypeObject && v.Object != nil && v.Object.OType == runtime.ObjTypeArray {
+			result = append(result, flattenArray(v.Object, depth-1)...)
+		} else {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func strictEquals(a, b *runtime.Value) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case runtime.TypeUndefined, runtime.TypeNull:
+		return true
+	case runtime.TypeNumber:
+		return a.Number == b.Number
+	case runtime.TypeString:
+		return a.Str == b.Str
+	case runtime.TypeBoolean:
+		return a.Bool == b.Bool
+	case runtime.TypeObject:
+		return a.Object == b.Object
+	case runtime.TypeSymbol:
+		return a.Symbol == b.Symbol
+	}
+	return false
+}
+
+func sameValueZero(a, b *runtime.Value) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case runtime.TypeUndefined, runtime.TypeNull:
+		return true
+	case runtime.TypeNumber:
+		if isNaN(a.Number) && isNaN(b.Number) {
+			return true
+		}
+		return a.Number == b.Number
+	case runtime.TypeString:
+		return a.Str == b.Str
+	case runtime.TypeBoolean:
+		return a.Bool == b.Bool
+	case runtime.TypeObject:
+		return a.Object == b.Object
+	case runtime.TypeSymbol:
+		return a.Symbol == b.Symbol
+	}
+	return false
+}
+
+// elementAt reads index i of an array or array-like object. A hole in an
+// array reads as whatever its prototype chain has at i, which is normally
+// undefined.
+func elementAt(obj *runtime.Object, i int) *runtime.Value {
+	if obj.OType == runtime.ObjTypeArray && i < len(obj.ArrayData) && obj.ArrayData[i] != nil {
+		return obj.ArrayData[i]
+	}
+	if val := obj.Get(strconv.Itoa(i)); val != nil {
+		return val
+	}
+	return runtime.Undefined
+}
+
+// densify stores a sparse array entirely in ArrayData, charging for the
+// holes that adds, for the methods that move elements around in it.
+func (rl *Realm) densify(obj *runtime.Object) error {
+	if obj.OType != runtime.ObjTypeArray || !obj.IsSparse() {
+		return nil
+	}
+	if err := rl.Agent.ChargeSlots(obj.ArrayLength() - len(obj.ArrayData)); err != nil {
+		return err
+	}
+	obj.Densify()
+	return nil
+}
+
+func getCallable(v *runtime.Value) runtime.CallableFunc {
+	if v != nil && v.Type == runtime.TypeObject && v.Object != nil && v.Object.Callable != nil {
+		return v.Object.Callable
+	}
+	return nil
+}
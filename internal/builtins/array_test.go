@@ -0,0 +1,645 @@
+package builtins
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupArray() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createArrayConstructor(rl.ObjectPrototype)
+	return rl
+}
+
+func (rl *Realm) makeTestArray(vals ...float64) *runtime.Value {
+	data := make([]*runtime.Value, len(vals))
+	for i, v := range vals {
+		data[i] = runtime.NewNumber(v)
+	}
+	return runtime.NewObject(rl.newArray(data))
+}
+
+func TestArrayPushPop(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	length, _ := rl.arrayPush(arr, []*runtime.Value{runtime.NewNumber(4)})
+	if length.Number != 4 {
+		t.Errorf("push: expected length 4, got %v", length.Number)
+	}
+
+	popped, _ := arrayPop(arr, nil)
+	if popped.Number != 4 {
+		t.Errorf("pop: expected 4, got %v", popped.Number)
+	}
+}
+
+func TestArrayShiftUnshift(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	shifted, _ := rl.arrayShift(arr, nil)
+	if shifted.Number != 1 {
+		t.Errorf("shift: expected 1, got %v", shifted.Number)
+	}
+
+	length, _ := rl.arrayUnshift(arr, []*runtime.Value{runtime.NewNumber(0)})
+	if length.Number != 3 {
+		t.Errorf("unshift: expected length 3, got %v", length.Number)
+	}
+}
+
+func TestArraySlice(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 4, 5)
+
+	result, _ := rl.arraySlice(arr, []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(3)})
+	data := getArrayData(result)
+	if len(data) != 2 || data[0].Number != 2 || data[1].Number != 3 {
+		t.Errorf("slice(1,3): expected [2,3], got %v", data)
+	}
+}
+
+func TestArraySplice(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 4, 5)
+
+	removed, _ := rl.arraySplice(arr, []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(2), runtime.NewNumber(10), runtime.NewNumber(20)})
+	removedData := getArrayData(removed)
+	if len(removedData) != 2 || removedData[0].Number != 2 || removedData[1].Number != 3 {
+		t.Errorf("splice removed: expected [2,3], got %v", removedData)
+	}
+	arrData := getArrayData(arr)
+	if len(arrData) != 5 || arrData[1].Number != 10 || arrData[2].Number != 20 {
+		t.Error("splice: array not modified correctly")
+	}
+}
+
+func TestArrayIndexOf(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 2, 1)
+
+	result, _ := arrayIndexOf(arr, []*runtime.Value{runtime.NewNumber(2)})
+	if result.Number != 1 {
+		t.Errorf("indexOf(2): expected 1, got %v", result.Number)
+	}
+
+	result, _ = arrayLastIndexOf(arr, []*runtime.Value{runtime.NewNumber(2)})
+	if result.Number != 3 {
+		t.Errorf("lastIndexOf(2): expected 3, got %v", result.Number)
+	}
+
+	result, _ = arrayIndexOf(arr, []*runtime.Value{runtime.NewNumber(99)})
+	if result.Number != -1 {
+		t.Errorf("indexOf(99): expected -1, got %v", result.Number)
+	}
+}
+
+func TestArrayIndexOfFromIndex(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 2, 1)
+	inf := runtime.NewNumber(math.Inf(1))
+	negInf := runtime.NewNumber(math.Inf(-1))
+
+	// Derived from test262 built-ins/Array/prototype/indexOf and lastIndexOf.
+	tests := []struct {
+		name     string
+		fn       func(*runtime.Value, []*runtime.Value) (*runtime.Value, error)
+		from     *runtime.Value
+		expected float64
+	}{
+		{"indexOf from 2", arrayIndexOf, runtime.NewNumber(2), 3},
+		{"indexOf from -2", arrayIndexOf, runtime.NewNumber(-2), 3},
+		{"indexOf from -1", arrayIndexOf, runtime.NewNumber(-1), -1},
+		{"indexOf from -100", arrayIndexOf, runtime.NewNumber(-100), 1},
+		{"indexOf from length", arrayIndexOf, runtime.NewNumber(5), -1},
+		{"indexOf from Infinity", arrayIndexOf, inf, -1},
+		{"indexOf from -Infinity", arrayIndexOf, negInf, 1},
+		{"indexOf from 1.9", arrayIndexOf, runtime.NewNumber(1.9), 1},
+		{"indexOf from NaN", arrayIndexOf, runtime.NewNumber(math.NaN()), 1},
+		{"indexOf from string", arrayIndexOf, runtime.NewString("2"), 3},
+		{"lastIndexOf from 2", arrayLastIndexOf, runtime.NewNumber(2), 1},
+		{"lastIndexOf from -2", arrayLastIndexOf, runtime.NewNumber(-2), 3},
+		{"lastIndexOf from -3", arrayLastIndexOf, runtime.NewNumber(-3), 1},
+		{"lastIndexOf from -100", arrayLastIndexOf, runtime.NewNumber(-100), -1},
+		{"lastIndexOf from 100", arrayLastIndexOf, runtime.NewNumber(100), 3},
+		{"lastIndexOf from Infinity", arrayLastIndexOf, inf, 3},
+		{"lastIndexOf from -Infinity", arrayLastIndexOf, negInf, -1},
+		{"lastIndexOf from NaN", arrayLastIndexOf, runtime.NewNumber(math.NaN()), -1},
+	}
+	for _, tt := range tests {
+		result, err := tt.fn(arr, []*runtime.Value{runtime.NewNumber(2), tt.from})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if result.Number != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, result.Number)
+		}
+	}
+
+	nan := rl.makeTestArray(math.NaN())
+	result, _ := arrayIndexOf(nan, []*runtime.Value{runtime.NewNumber(math.NaN())})
+	if result.Number != -1 {
+		t.Errorf("indexOf(NaN): expected -1, got %v", result.Number)
+	}
+}
+
+func TestArrayIncludes(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	result, _ := arrayIncludes(arr, []*runtime.Value{runtime.NewNumber(2)})
+	if !result.Bool {
+		t.Error("includes(2) should be true")
+	}
+	result, _ = arrayIncludes(arr, []*runtime.Value{runtime.NewNumber(5)})
+	if result.Bool {
+		t.Error("includes(5) should be false")
+	}
+}
+
+func TestArrayMap(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	double := rl.newFuncObject("double", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number * 2), nil
+	})
+
+	result, err := rl.arrayMap(arr, []*runtime.Value{runtime.NewObject(double)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := getArrayData(result)
+	if len(data) != 3 || data[0].Number != 2 || data[1].Number != 4 || data[2].Number != 6 {
+		t.Error("map: expected [2,4,6]")
+	}
+}
+
+func TestArrayFilter(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 4, 5)
+
+	isEven := rl.newFuncObject("isEven", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewBool(int(args[0].Number)%2 == 0), nil
+	})
+
+	result, err := rl.arrayFilter(arr, []*runtime.Value{runtime.NewObject(isEven)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := getArrayData(result)
+	if len(data) != 2 || data[0].Number != 2 || data[1].Number != 4 {
+		t.Error("filter: expected [2,4]")
+	}
+}
+
+func TestArrayReduce(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 4)
+
+	sum := rl.newFuncObject("sum", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number + args[1].Number), nil
+	})
+
+	result, err := arrayReduce(arr, []*runtime.Value{runtime.NewObject(sum), runtime.NewNumber(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Number != 10 {
+		t.Errorf("reduce: expected 10, got %v", result.Number)
+	}
+}
+
+func TestArrayReduceRight(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	var order []float64
+	concat := rl.newFuncObject("concat", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		order = append(order, args[2].Number)
+		return runtime.NewString(args[0].ToString() + args[1].ToString()), nil
+	})
+
+	result, err := arrayReduceRight(arr, []*runtime.Value{runtime.NewObject(concat)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != "321" {
+		t.Errorf("reduceRight: expected \"321\", got %v", result)
+	}
+	if len(order) != 2 || order[0] != 1 || order[1] != 0 {
+		t.Errorf("reduceRight: expected indices [1 0], got %v", order)
+	}
+
+	if _, err := arrayReduceRight(rl.makeTestArray(), []*runtime.Value{runtime.NewObject(concat)}); err == nil {
+		t.Error("reduceRight of empty array with no initial value should throw")
+	}
+	if _, err := arrayReduceRight(rl.makeTestArray(), []*runtime.Value{runtime.Undefined, runtime.NewNumber(0)}); err == nil {
+		t.Error("reduceRight with a non-callable callback should throw")
+	}
+}
+
+func TestArrayEvery(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(2, 4, 6)
+
+	isEven := rl.newFuncObject("isEven", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewBool(int(args[0].Number)%2 == 0), nil
+	})
+
+	result, _ := arrayEvery(arr, []*runtime.Value{runtime.NewObject(isEven)})
+	if !result.Bool {
+		t.Error("every(isEven) on [2,4,6] should be true")
+	}
+}
+
+func TestArraySome(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 3, 4)
+
+	isEven := rl.newFuncObject("isEven", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewBool(int(args[0].Number)%2 == 0), nil
+	})
+
+	result, _ := arraySome(arr, []*runtime.Value{runtime.NewObject(isEven)})
+	if !result.Bool {
+		t.Error("some(isEven) on [1,3,4] should be true")
+	}
+}
+
+func TestArraySort(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(3, 1, 2)
+
+	numSort := rl.newFuncObject("cmp", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number - args[1].Number), nil
+	})
+
+	rl.arraySort(arr, []*runtime.Value{runtime.NewObject(numSort)})
+	data := getArrayData(arr)
+	if data[0].Number != 1 || data[1].Number != 2 || data[2].Number != 3 {
+		t.Error("sort: expected [1,2,3]")
+	}
+}
+
+func TestArrayReverse(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	rl.arrayReverse(arr, nil)
+	data := getArrayData(arr)
+	if data[0].Number != 3 || data[1].Number != 2 || data[2].Number != 1 {
+		t.Error("reverse: expected [3,2,1]")
+	}
+}
+
+func TestArrayJoin(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+
+	result, _ := rl.arrayJoin(arr, []*runtime.Value{runtime.NewString("-")})
+	if result.Str != "1-2-3" {
+		t.Errorf("join('-'): expected '1-2-3', got %q", result.Str)
+	}
+}
+
+func TestArrayConcat(t *testing.T) {
+	rl := setupArray()
+	arr1 := rl.makeTestArray(1, 2)
+	arr2 := rl.makeTestArray(3, 4)
+
+	result, _ := rl.arrayConcat(arr1, []*runtime.Value{arr2})
+	data := getArrayData(result)
+	if len(data) != 4 {
+		t.Errorf("concat: expected 4 elements, got %d", len(data))
+	}
+}
+
+func TestArrayFind(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 4)
+
+	findThree := rl.newFuncObject("findThree", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewBool(args[0].Number == 3), nil
+	})
+
+	result, _ := arrayFind(arr, []*runtime.Value{runtime.NewObject(findThree)})
+	if result.Number != 3 {
+		t.Errorf("find: expected 3, got %v", result.Number)
+	}
+}
+
+func TestArrayFlat(t *testing.T) {
+	rl := setupArray()
+	inner := rl.makeTestArray(3, 4)
+	arr := runtime.NewObject(rl.newArray([]*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(2), inner}))
+
+	result, _ := rl.arrayFlat(arr, nil)
+	data := getArrayData(result)
+	if len(data) != 4 {
+		t.Errorf("flat: expected 4 elements, got %d", len(data))
+	}
+}
+
+func TestArrayIsArray(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3)
+	obj := runtime.NewObject(runtime.NewOrdinaryObject(nil))
+
+	result, _ := arrayIsArray(runtime.Undefined, []*runtime.Value{arr})
+	if !result.Bool {
+		t.Error("Array.isArray(arr) should be true")
+	}
+	result, _ = arrayIsArray(runtime.Undefined, []*runtime.Value{obj})
+	if result.Bool {
+		t.Error("Array.isArray(obj) should be false")
+	}
+}
+
+func TestArrayFill(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(1, 2, 3, 4)
+	rl.arrayFill(arr, []*runtime.Value{runtime.NewNumber(0), runtime.NewNumber(1), runtime.NewNumber(3)})
+	data := getArrayData(arr)
+	if data[0].Number != 1 || data[1].Number != 0 || data[2].Number != 0 || data[3].Number != 4 {
+		t.Error("fill: expected [1,0,0,4]")
+	}
+}
+
+func TestArrayIterators(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(10, 20, 30)
+
+	// keys
+	iter, _ := rl.arrayKeys(arr, nil)
+	iterObj := toObject(iter)
+	nextFn := getCallable(iterObj.Get("next"))
+
+	r1, _ := nextFn(iter, nil)
+	if toObject(r1).Get("value").Number != 0 || toObject(r1).Get("done").Bool != false {
+		t.Error("keys iterator: expected {value: 0, done: false}")
+	}
+}
+
+func TestArrayIteratorArrayLike(t *testing.T) {
+	rl := setupArray()
+	args := rl.NewArgumentsObject([]*runtime.Value{runtime.NewString("a"), runtime.NewString("b")}, runtime.Undefined)
+
+	iter, err := rl.arrayValues(runtime.NewObject(args), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := getCallable(iter.Object.Get("next"))
+	var got []string
+	for i := 0; i < 3; i++ {
+		r, _ := next(iter, nil)
+		if toObject(r).Get("done").Bool {
+			break
+		}
+		got = append(got, toObject(r).Get("value").ToString())
+	}
+	if strings.Join(got, ",") != "a,b" {
+		t.Errorf("values over arguments: expected a,b, got %v", got)
+	}
+	if res, _ := arrayIsArray(runtime.Undefined, []*runtime.Value{runtime.NewObject(args)}); res.Bool {
+		t.Error("an arguments object is not an array")
+	}
+	if tag, _ := objectProtoToString(runtime.NewObject(args), nil); tag.Str != "[object Arguments]" {
+		t.Errorf("expected [object Arguments], got %s", tag.Str)
+	}
+}
+
+func TestArrayMethodsOnArrayLikes(t *testing.T) {
+	rl := setupArray()
+	args := runtime.NewObject(rl.NewArgumentsObject([]*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(2), runtime.NewNumber(3)}, runtime.Undefined))
+
+	sliced, _ := rl.arraySlice(args, []*runtime.Value{runtime.NewNumber(1)})
+	if data := getArrayData(sliced); len(data) != 2 || data[0].Number != 2 || data[1].Number != 3 {
+		t.Errorf("slice of arguments: expected [2 3], got %v", data)
+	}
+	double := runtime.NewObject(rl.newFuncObject("double", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number * 2), nil
+	}))
+	mapped, _ := rl.arrayMap(args, []*runtime.Value{double})
+	if joined, _ := rl.arrayJoin(mapped, nil); joined.Str != "2,4,6" {
+		t.Errorf("map over arguments: expected 2,4,6, got %s", joined.Str)
+	}
+	if joined, _ := rl.arrayJoin(args, []*runtime.Value{runtime.NewString("-")}); joined.Str != "1-2-3" {
+		t.Errorf("join of arguments: expected 1-2-3, got %s", joined.Str)
+	}
+	if idx, _ := arrayIndexOf(args, []*runtime.Value{runtime.NewNumber(3)}); idx.Number != 2 {
+		t.Errorf("indexOf in arguments: expected 2, got %v", idx.Number)
+	}
+
+	// Any object with a length is read the same way.
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("length", runtime.NewNumber(2))
+	obj.Set("1", runtime.NewString("x"))
+	if found, _ := arrayIncludes(runtime.NewObject(obj), []*runtime.Value{runtime.NewString("x")}); !found.Bool {
+		t.Error("includes on an array-like: expected true")
+	}
+}
+
+func TestArrayIteratorPrototype(t *testing.T) {
+	rl := setupArray()
+	arr := rl.makeTestArray(10, 20)
+
+	entries, err := rl.arrayEntries(arr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries.Object.Prototype != rl.ArrayIteratorPrototype {
+		t.Error("entries: iterator should inherit from ArrayIteratorPrototype")
+	}
+	if _, ok := entries.Object.Properties["next"]; ok {
+		t.Error("entries: next should be inherited, not an own property")
+	}
+	next := getCallable(entries.Object.Get("next"))
+	r, _ := next(entries, nil)
+	if toObject(r).Prototype != rl.ObjectPrototype {
+		t.Error("next: results should inherit from Object.prototype")
+	}
+	pair := toObject(toObject(r).Get("value"))
+	if pair == nil || len(pair.ArrayData) != 2 || pair.ArrayData[0].Number != 0 || pair.ArrayData[1].Number != 10 {
+		t.Errorf("entries: expected [0, 10], got %v", toObject(r).Get("value"))
+	}
+
+	// Once done, an iterator stays done even if the array grows.
+	values, _ := rl.arrayValues(arr, nil)
+	for i := 0; i < 3; i++ {
+		next(values, nil)
+	}
+	rl.arrayPush(arr, []*runtime.Value{runtime.NewNumber(30)})
+	r, _ = next(values, nil)
+	if !toObject(r).Get("done").Bool {
+		t.Error("values: exhausted iterator should stay done")
+	}
+
+	if _, err := next(runtime.NewObject(runtime.NewOrdinaryObject(nil)), nil); err == nil {
+		t.Error("next on a non-iterator should throw")
+	}
+	if _, err := rl.arrayKeys(runtime.Undefined, nil); err == nil {
+		t.Error("keys on undefined should throw")
+	}
+}
+
+func TestArrayHoles(t *testing.T) {
+	rl := setupArray()
+	arr := rl.newArray([]*runtime.Value{runtime.NewNumber(3), nil, runtime.NewNumber(1), runtime.Undefined})
+	this := runtime.NewObject(arr)
+
+	sliced, _ := rl.arraySlice(this, []*runtime.Value{runtime.NewNumber(1)})
+	if got := sliced.Object.ArrayData; len(got) != 3 || got[0] != nil {
+		t.Errorf("slice keeps holes, got %v", got)
+	}
+
+	calls := 0
+	counter := runtime.NewObject(rl.newFuncObject("f", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		calls++
+		return runtime.True, nil
+	}))
+	mapped, _ := rl.arrayMap(this, []*runtime.Value{counter})
+	if calls != 3 || mapped.Object.ArrayLength() != 4 || mapped.Object.ArrayData[1] != nil {
+		t.Errorf("map skips holes and keeps them, called %d times", calls)
+	}
+
+	rl.arraySort(this, nil)
+	if d := arr.ArrayData; d[0].Number != 1 || d[1].Number != 3 || d[2].Type != runtime.TypeUndefined || d[3] != nil {
+		t.Errorf("sort puts undefined then holes last, got %v", d)
+	}
+
+	sparse, _ := rl.arrayConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1e9)})
+	if sparse.Object.ArrayLength() != 1e9 || len(sparse.Object.ArrayData) > runtime.MaxArrayGap {
+		t.Error("Array(n) does not allocate its holes")
+	}
+	if _, err := rl.arrayConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1.5)}); err == nil || !strings.HasPrefix(err.Error(), "RangeError") {
+		t.Errorf("Array(1.5) should throw a RangeError, got %v", err)
+	}
+}
+
+func TestArrayOfConstructor(t *testing.T) {
+	rl := registerTestRealm()
+	ctor := rl.newFuncObject("F", 1, nil)
+	ctor.Constructor = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		this.Object.Set("n", argAt(args, 0))
+		return runtime.Undefined, nil
+	}
+	proto := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	ctor.Set("prototype", runtime.NewObject(proto))
+	F := runtime.NewObject(ctor)
+
+	of, err := rl.arrayOf(F, []*runtime.Value{runtime.NewString("a"), runtime.NewString("b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := of.Object
+	if obj.Prototype != proto || obj.Get("n").Number != 2 || obj.Get("1").Str != "b" || obj.Get("length").Number != 2 {
+		t.Errorf("Array.of.call(F, 'a', 'b') should construct F(2) and fill it")
+	}
+
+	from, err := rl.arrayFrom(F, []*runtime.Value{rl.makeTestArray(7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj := from.Object; obj.Prototype != proto || obj.Get("n").Type != runtime.TypeUndefined || obj.Get("0").Number != 7 {
+		t.Errorf("Array.from.call(F, iterable) should construct F() and fill it")
+	}
+
+	// Array itself and non-constructors give plain arrays.
+	for _, this := range []*runtime.Value{runtime.NewObject(rl.ArrayPrototype.Get("constructor").Object), runtime.Undefined} {
+		arr, _ := rl.arrayOf(this, []*runtime.Value{runtime.NewNumber(5)})
+		if arr.Object.OType != runtime.ObjTypeArray || arr.Object.ArrayLength() != 1 {
+			t.Errorf("Array.of(5) should be [5], got length %d", arr.Object.ArrayLength())
+		}
+	}
+}
+
+func TestArrayPushLimits(t *testing.T) {
+	rl := setupArray()
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("length", runtime.NewNumber(1))
+	obj.Set("0", runtime.NewString("a"))
+	n, err := rl.arrayPush(runtime.NewObject(obj), []*runtime.Value{runtime.NewString("b")})
+	if err != nil || n.Number != 2 || obj.Get("0").Str != "a" || obj.Get("1").Str != "b" || obj.Get("length").Number != 2 {
+		t.Errorf("push on an array-like: got %v, %v, {0: %v, 1: %v, length: %v}", n, err, obj.Get("0"), obj.Get("1"), obj.Get("length"))
+	}
+	if obj.OType == runtime.ObjTypeArray || len(obj.ArrayData) != 0 {
+		t.Error("push on an array-like should not give it array elements")
+	}
+
+	obj.Set("length", runtime.NewNumber(1<<53-1))
+	if _, err := rl.arrayPush(runtime.NewObject(obj), []*runtime.Value{runtime.NewNumber(1)}); err == nil || !strings.HasPrefix(err.Error(), "TypeError") {
+		t.Errorf("push past 2^53-1 should throw a TypeError, got %v", err)
+	}
+
+	full := rl.newSparseArray(runtime.MaxArrayLength)
+	if _, err := rl.arrayPush(runtime.NewObject(full), []*runtime.Value{runtime.NewNumber(1)}); err == nil || !strings.HasPrefix(err.Error(), "RangeError") {
+		t.Errorf("push past 2^32-1 should throw a RangeError, got %v", err)
+	}
+	if full.ArrayLength() != runtime.MaxArrayLength {
+		t.Errorf("a failed push should leave the length alone, got %d", full.ArrayLength())
+	}
+}
+
+func TestArrayFillArrayLike(t *testing.T) {
+	rl := setupArray()
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("length", runtime.NewNumber(3))
+	rl.arrayFill(runtime.NewObject(obj), []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(1)})
+	if obj.Properties["0"] != nil || obj.Get("1").Number != 1 || obj.Get("2").Number != 1 {
+		t.Errorf("fill on an array-like: got {0: %v, 1: %v, 2: %v}", obj.Get("0"), obj.Get("1"), obj.Get("2"))
+	}
+
+	// Every element written is charged, holes included.
+	defer func(prev *runtime.HeapBudget) { rl.Agent.Heap = prev }(rl.Agent.Heap)
+	rl.Agent.Heap = &runtime.HeapBudget{Limit: 1 << 20}
+	big := runtime.NewObject(rl.newSparseArray(5e7))
+	if _, err := rl.arrayFill(big, []*runtime.Value{runtime.NewNumber(0)}); err == nil || !strings.HasPrefix(err.Error(), "RangeError: heap limit") {
+		t.Errorf("fill of 5e7 elements under a 1MB budget should throw, got %v", err)
+	}
+	if len(big.Object.ArrayData) != 0 {
+		t.Error("a refused fill should write nothing")
+	}
+}
+
+func TestArrayMethodsPrimitiveThis(t *testing.T) {
+	rl := setupArray()
+	call := func(name string, this *runtime.Value, args ...*runtime.Value) (*runtime.Value, error) {
+		return rl.ArrayPrototype.Get(name).Object.Callable(this, args)
+	}
+
+	result, err := call("join", runtime.NewString("abc"), runtime.NewString("-"))
+	if err != nil || result.Str != "a-b-c" {
+		t.Errorf("join on a string: got %v, %v", result, err)
+	}
+	result, err = call("slice", runtime.NewString("abc"), runtime.NewNumber(1))
+	if err != nil {
+		t.Fatalf("slice on a string: %v", err)
+	}
+	if data := getArrayData(result); len(data) != 2 || data[0].Str != "b" || data[1].Str != "c" {
+		t.Errorf("slice on a string: got %v", data)
+	}
+	result, err = call("includes", runtime.NewString("abc"), runtime.NewString("c"))
+	if err != nil || !result.Bool {
+		t.Errorf("includes on a string: got %v, %v", result, err)
+	}
+	result, err = call("concat", runtime.NewString("ab"), runtime.NewNumber(1))
+	if err != nil || len(getArrayData(result)) != 2 {
+		t.Errorf("concat on a string: got %v, %v", result, err)
+	}
+	result, err = call("indexOf", runtime.NewNumber(5), runtime.NewNumber(5))
+	if err != nil || result.Number != -1 {
+		t.Errorf("indexOf on a number: got %v, %v", result, err)
+	}
+
+	for _, this := range []*runtime.Value{runtime.Undefined, runtime.Null} {
+		if _, err := call("map", this); err == nil || !strings.HasPrefix(err.Error(), "TypeError") {
+			t.Errorf("map on %v: expected a TypeError, got %v", this, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package builtins
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createBooleanConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	rl.BooleanPrototype = proto
+
+	rl.setMethod(proto, "toString", 0, booleanToString)
+	rl.setMethod(proto, "valueOf", 0, booleanValueOf)
+
+	ctor := rl.newFuncObject("Boolean", 1, booleanConstructorCall)
+	ctor.Constructor = booleanConstruct
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+// thisBooleanValue returns the boolean held by a Boolean primitive or
+// wrapper object; the Boolean.prototype methods throw a TypeError for
+// anything else.
+func thisBooleanValue(this *runtime.Value, method string) (bool, error) {
+	if this != nil {
+		if this.Type == runtime.TypeBoolean {
+			return this.Bool, nil
+		}
+		if this.Type == runtime.TypeObject {
+			if prim, ok := runtime.PrimitiveData(this.Object); ok && prim.Type == runtime.TypeBoolean {
+				return prim.Bool, nil
+			}
+		}
+	}
+	return false, fmt.Errorf("TypeError: Boolean.prototype.%s requires that 'this' be a Boolean", method)
+}
+
+func booleanConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 0 {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(args[0].ToBoolean()), nil
+}
+
+// booleanConstruct backs new Boolean(value): the instance created by new
+// becomes a wrapper object holding the converted boolean.
+func booleanConstruct(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	b, _ := booleanConstructorCall(this, args)
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil {
+		return b, nil
+	}
+	runtime.SetPrimitiveData(this.Object, b)
+	return this, nil
+}
+
+func booleanToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	b, err := thisBooleanValue(this, "toString")
+	if err != nil {
+		return nil, err
+	}
+	if b {
+		return runtime.NewString("true"), nil
+	}
+	return runtime.NewString("false"), nil
+}
+
+func booleanValueOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	b, err := thisBooleanValue(this, "valueOf")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(b), nil
+}
@@ -3,7 +3,7 @@ package builtins
 import (
 	"testing"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
 func TestBooleanConstructor(t *testing.T) {
@@ -39,3 +39,25 @@ func TestBooleanToString(t *testing.T) {
 		t.Errorf("false.toString(): expected 'false', got %q", result.Str)
 	}
 }
+
+func TestBooleanConstruct(t *testing.T) {
+	instance := runtime.NewObject(runtime.NewOrdinaryObject(nil))
+	result, err := booleanConstruct(instance, []*runtime.Value{runtime.NewNumber(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != instance {
+		t.Fatal("new Boolean() should return the new instance")
+	}
+	v, err := booleanValueOf(instance, nil)
+	if err != nil || v.Bool {
+		t.Errorf("new Boolean(0).valueOf(): expected false, got %v (%v)", v, err)
+	}
+	s, _ := booleanToString(instance, nil)
+	if s.Str != "false" {
+		t.Errorf("new Boolean(0).toString(): expected 'false', got %q", s.Str)
+	}
+	if _, err := booleanValueOf(runtime.NewNumber(1), nil); err == nil {
+		t.Error("Boolean.prototype.valueOf on a number should throw")
+	}
+}
@@ -0,0 +1,123 @@
+package builtins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// registerStructuredClone declares structuredClone(value), which returns a
+// deep copy of value, see runtime.DeepClone.
+func (rl *Realm) registerStructuredClone(env *runtime.Environment) {
+	rl.declareFunc(env, "structuredClone", 1, rl.structuredClone)
+}
+
+func (rl *Realm) structuredClone(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("TypeError: The \"value\" argument must be specified")
+	}
+	v, err := rl.DeepClone(args[0])
+	if err != nil {
+		if msg, ok := strings.CutPrefix(err.Error(), "DataCloneError: "); ok {
+			return nil, runtime.Throw(rl.makeErrorValue("DataCloneError", []*runtime.Value{runtime.NewString(msg)}, rl.ErrorPrototype))
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// cloneBuiltin is runtime.CloneBuiltin: it copies Maps, Sets, Dates,
+// RegExps and errors. A RegExp is copied without its lastIndex, and an
+// error keeps its name only if it is one of the native error types.
+func (rl *Realm) cloneBuiltin(c *runtime.Cloner, obj *runtime.Object) (*runtime.Object, bool, error) {
+	v := runtime.NewObject(obj)
+	switch {
+	case obj.OType == runtime.ObjTypeMap && obj.Internal != nil:
+		dst := &runtime.Object{
+			OType:      runtime.ObjTypeMap,
+			Properties: make(map[string]*runtime.Property),
+			Prototype:  rl.MapPrototype,
+		}
+		c.Remember(obj, dst)
+		entries := getMapEntries(obj)
+		copied := make([]*mapEntry, 0, len(entries))
+		for _, e := range entries {
+			key, err := c.Clone(e.key)
+			if err != nil {
+				return nil, true, err
+			}
+			value, err := c.Clone(e.value)
+			if err != nil {
+				return nil, true, err
+			}
+			copied = append(copied, &mapEntry{key: key, value: value})
+		}
+		setMapEntries(dst, copied)
+		dst.Set("size", runtime.NewNumber(float64(len(copied))))
+		return dst, true, nil
+
+	case obj.OType == runtime.ObjTypeSet && obj.Internal != nil:
+		dst := &runtime.Object{
+			OType:      runtime.ObjTypeSet,
+			Properties: make(map[string]*runtime.Property),
+			Prototype:  rl.SetPrototype,
+		}
+		c.Remember(obj, dst)
+		items := getSetItems(obj)
+		copied := make([]*runtime.Value, 0, len(items))
+		for _, item := range items {
+			v, err := c.Clone(item)
+			if err != nil {
+				return nil, true, err
+			}
+			copied = append(copied, v)
+		}
+		setSetItems(dst, copied)
+		dst.Set("size", runtime.NewNumber(float64(len(copied))))
+		return dst, true, nil
+
+	case isDateObject(v):
+		t, invalid := getDateValue(v)
+		dst := rl.makeDateObject(nil, t, invalid).Object
+		c.Remember(obj, dst)
+		return dst, true, nil
+
+	case obj.Internal != nil && obj.Internal["regexp"] != nil:
+		pattern, _ := obj.Internal["pattern"].(string)
+		flags, _ := obj.Internal["flags"].(string)
+		rx, err := rl.createRegExpObject(pattern, flags)
+		if err != nil {
+			return nil, true, err
+		}
+		c.Remember(obj, rx.Object)
+		return rx.Object, true, nil
+
+	case obj.OType == runtime.ObjTypeError:
+		name := "Error"
+		n, err := obj.GetErr("name")
+		if err != nil {
+			return nil, true, err
+		}
+		if n.Type == runtime.TypeString && rl.errorPrototypes[n.Str] != nil {
+			name = n.Str
+		}
+		dst := rl.makeErrorValue(name, nil, rl.errorPrototypes[name]).Object
+		c.Remember(obj, dst)
+		for _, key := range []string{"message", "stack", "cause"} {
+			if runtime.OwnProperty(obj, key) == nil {
+				continue
+			}
+			val, err := obj.GetErr(key)
+			if err != nil {
+				return nil, true, err
+			}
+			if val, err = c.Clone(val); err != nil {
+				return nil, true, err
+			}
+			dst.Set(key, val)
+		}
+		return dst, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,51 @@
+package builtins
+
+import (
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestStructuredClone(t *testing.T) {
+	rl := registerTestRealm()
+	rl.CloneBuiltin = rl.cloneBuiltin
+	m, _ := rl.mapConstructorCall(runtime.Undefined, nil)
+	key := runtime.NewObject(runtime.NewOrdinaryObject(nil))
+	mapSet(m, []*runtime.Value{key, m})
+	day, _ := dateFromMs(86400000)
+	date := rl.makeDateObject(nil, day, false)
+	rx, _ := rl.createRegExpObject("a+", "gi")
+	rx.Object.Set("lastIndex", runtime.NewNumber(3))
+	errVal := rl.makeErrorValue("RangeError", []*runtime.Value{runtime.NewString("too far")}, rl.errorPrototypes["RangeError"])
+
+	cm, err := rl.structuredClone(runtime.Undefined, []*runtime.Value{m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := getMapEntries(cm.Object)
+	if cm.Object == m.Object || len(entries) != 1 || entries[0].key.Object == key.Object || entries[0].value.Object != cm.Object {
+		t.Error("a Map is copied with its entries, keys cloned and cycles kept")
+	}
+
+	cd, _ := rl.structuredClone(runtime.Undefined, []*runtime.Value{date})
+	if got, _ := getDateValue(cd); cd.Object == date.Object || got.UnixMilli() != 86400000 {
+		t.Errorf("a Date keeps its time value, got %v", got)
+	}
+
+	crx, _ := rl.structuredClone(runtime.Undefined, []*runtime.Value{rx})
+	if crx.Object.Get("source").Str != "a+" || crx.Object.Get("flags").Str != "gi" || toNumber(crx.Object.Get("lastIndex")) != 0 {
+		t.Error("a RegExp keeps its source and flags but not its lastIndex")
+	}
+
+	cerr, _ := rl.structuredClone(runtime.Undefined, []*runtime.Value{errVal})
+	if cerr.Object.Prototype != rl.errorPrototypes["RangeError"] || cerr.Object.Get("message").Str != "too far" {
+		t.Error("an error keeps its type and message")
+	}
+
+	fn := runtime.NewObject(rl.newFuncObject("f", 0, stringToString))
+	_, err = rl.structuredClone(runtime.Undefined, []*runtime.Value{fn})
+	thrown, ok := runtime.ThrownValue(err)
+	if !ok || thrown.Object.Get("name").Str != "DataCloneError" {
+		t.Errorf("cloning a function throws a DataCloneError, got %v", err)
+	}
+}
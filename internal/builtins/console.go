@@ -0,0 +1,429 @@
+package builtins
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// consoleState holds the counters of console.count, the timers of
+// console.time and the indentation of console.group, which belong to one
+// console object.
+type consoleState struct {
+	realm  *Realm
+	counts map[string]int
+	timers map[string]time.Time
+	indent string
+}
+
+func (rl *Realm) createConsoleObject(proto *runtime.Object) *runtime.Object {
+	console := runtime.NewOrdinaryObject(proto)
+	state := &consoleState{realm: rl, counts: make(map[string]int), timers: make(map[string]time.Time)}
+
+	rl.setMethod(console, "log", 0, state.log)
+	rl.setMethod(console, "error", 0, state.error)
+	rl.setMethod(console, "warn", 0, state.error)
+	rl.setMethod(console, "info", 0, state.log)
+	rl.setMethod(console, "debug", 0, state.log)
+	rl.setMethod(console, "dir", 0, state.dir)
+	rl.setMethod(console, "table", 1, state.table)
+	rl.setMethod(console, "assert", 0, state.assert)
+	rl.setMethod(console, "group", 0, state.group)
+	rl.setMethod(console, "groupCollapsed", 0, state.group)
+	rl.setMethod(console, "groupEnd", 0, state.groupEnd)
+	rl.setMethod(console, "count", 0, state.count)
+	rl.setMethod(console, "countReset", 0, state.countReset)
+	rl.setMethod(console, "time", 0, state.time)
+	rl.setMethod(console, "timeLog", 0, state.timeLog)
+	rl.setMethod(console, "timeEnd", 0, state.timeEnd)
+
+	return console
+}
+
+// formatArgs formats the arguments of console.log as Node does. A first
+// argument that is a string may hold format specifiers, each replaced by
+// the next argument: %s as a string, %d and %f as a number, %i as an
+// integer, %j as JSON, %o and %O inspected, %c consumed for CSS that is
+// ignored, and %% as %. The remaining arguments follow, separated by
+// spaces, strings as they are and anything else inspected.
+func (rl *Realm) formatArgs(args []*runtime.Value) string {
+	var sb strings.Builder
+	rest := args
+	if len(args) > 0 && args[0].Type == runtime.TypeString {
+		rest = rl.formatSpecifiers(&sb, args[0].Str, args[1:])
+	}
+	for i, a := range rest {
+		if i > 0 || len(rest) < len(args) {
+			sb.WriteByte(' ')
+		}
+		if a.Type == runtime.TypeString {
+			sb.WriteString(a.Str)
+		} else {
+			sb.WriteString(Inspect(a))
+		}
+	}
+	return sb.String()
+}
+
+// formatSpecifiers writes format with its specifiers replaced by args and
+// returns the arguments left over.
+func (rl *Realm) formatSpecifiers(sb *strings.Builder, format string, args []*runtime.Value) []*runtime.Value {
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 == len(format) {
+			sb.WriteByte(c)
+			continue
+		}
+		verb := format[i+1]
+		if verb == '%' {
+			sb.WriteByte('%')
+			i++
+			continue
+		}
+		if !strings.ContainsRune("sdifjoOc", rune(verb)) || len(args) == 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		arg := args[0]
+		args = args[1:]
+		i++
+		switch verb {
+		case 's':
+			if arg.Type == runtime.TypeObject {
+				sb.WriteString(inspectWithDepth(arg, 0))
+			} else if arg.Type == runtime.TypeString {
+				sb.WriteString(arg.Str)
+			} else {
+				sb.WriteString(Inspect(arg))
+			}
+		case 'd', 'i', 'f':
+			n := math.NaN()
+			if arg.Type != runtime.TypeObject && arg.Type != runtime.TypeSymbol {
+				n, _ = toNumberErr(arg)
+			}
+			if verb == 'i' && !math.IsNaN(n) && !math.IsInf(n, 0) {
+				n = math.Trunc(n)
+			}
+			sb.WriteString(Inspect(runtime.NewNumber(n)))
+		case 'j':
+			sb.WriteString(rl.formatJSON(arg))
+		case 'o':
+			sb.WriteString(inspectWithDepth(arg, 4))
+		case 'O':
+			sb.WriteString(Inspect(arg))
+		case 'c':
+		}
+	}
+	return args
+}
+
+// formatJSON is the %j of console.log: JSON.stringify of v, or [Circular]
+// for a structure it cannot serialize.
+func (rl *Realm) formatJSON(v *runtime.Value) string {
+	s, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{v})
+	if err != nil {
+		return "[Circular]"
+	}
+	return s.ToString()
+}
+
+// printLine writes s and a newline to w, each line of s indented by the
+// groups open. A lone surrogate, which UTF-8 cannot encode, is written as
+// U+FFFD, as Node does.
+func (c *consoleState) printLine(w io.Writer, s string) {
+	if c.indent != "" {
+		s = c.indent + strings.ReplaceAll(s, "\n", "\n"+c.indent)
+	}
+	fmt.Fprintln(w, runtime.ToWellFormed(s))
+}
+
+func (c *consoleState) log(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	c.printLine(c.realm.Agent.IO.Stdout, c.realm.formatArgs(args))
+	return runtime.Undefined, nil
+}
+
+// error implements console.error and console.warn, which write to stderr.
+func (c *consoleState) error(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	c.printLine(c.realm.Agent.IO.Stderr, c.realm.formatArgs(args))
+	return runtime.Undefined, nil
+}
+
+// assert implements console.assert(condition, ...data): nothing when
+// condition is truthy, else "Assertion failed" and data to stderr. A
+// message that is a string follows a colon and may hold format specifiers.
+func (c *consoleState) assert(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if argAt(args, 0).ToBoolean() {
+		return runtime.Undefined, nil
+	}
+	data := args[min(len(args), 1):]
+	switch {
+	case len(data) == 0:
+		data = []*runtime.Value{runtime.NewString("Assertion failed")}
+	case data[0].Type == runtime.TypeString:
+		data = append([]*runtime.Value{runtime.NewString("Assertion failed: " + data[0].Str)}, data[1:]...)
+	default:
+		data = append([]*runtime.Value{runtime.NewString("Assertion failed")}, data...)
+	}
+	return c.error(this, data)
+}
+
+// group implements console.group and console.groupCollapsed: the label,
+// if any, is logged, and what follows is indented by two more spaces
+// until groupEnd.
+func (c *consoleState) group(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) > 0 {
+		c.printLine(c.realm.Agent.IO.Stdout, c.realm.formatArgs(args))
+	}
+	c.indent += "  "
+	return runtime.Undefined, nil
+}
+
+func (c *consoleState) groupEnd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	c.indent = c.indent[:max(len(c.indent)-2, 0)]
+	return runtime.Undefined, nil
+}
+
+// dir implements console.dir(value, options): value inspected, to
+// options.depth levels, or all of them when it is null or Infinity.
+func (c *consoleState) dir(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	depth := InspectDepth
+	if opts := toObject(argAt(args, 1)); opts != nil {
+		switch d := opts.Get("depth"); {
+		case d == nil || d.Type == runtime.TypeUndefined:
+		case d.Type == runtime.TypeNull || d.Type == runtime.TypeNumber && math.IsInf(d.Number, 1):
+			depth = -1
+		default:
+			depth = int(toInteger(d))
+		}
+	}
+	c.printLine(c.realm.Agent.IO.Stdout, inspectWithDepth(argAt(args, 0), depth))
+	return runtime.Undefined, nil
+}
+
+// table implements console.table(data, columns): a table with a
+// row for each element or property of data and a column for each
+// property of the rows that are objects, plus a Values column for the
+// rows that are not. columns limits the table to the properties it names.
+// Data that is not an object is logged as it is.
+func (c *consoleState) table(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil || obj.Callable != nil {
+		return c.log(this, args)
+	}
+
+	keys := enumerableKeys(obj)
+
+	var columns []string
+	known := make(map[string]bool)
+	filtered := false
+	if cols := toObject(argAt(args, 1)); cols != nil && cols.OType == runtime.ObjTypeArray {
+		filtered = true
+		for _, c := range cols.ArrayValues() {
+			name := c.ToString()
+			columns = append(columns, name)
+			known[name] = true
+		}
+	}
+
+	rows := make([]map[string]string, len(keys))
+	values := make([]string, len(keys))
+	hasValues := false
+	for i, key := range keys {
+		rows[i] = make(map[string]string)
+		val := elementOrProperty(obj, key)
+		row := toObject(val)
+		if row == nil || row.Callable != nil {
+			values[i] = inspectWithDepth(val, 0)
+			hasValues = true
+			continue
+		}
+		for _, col := range enumerableKeys(row) {
+			if !known[col] {
+				if filtered {
+					continue
+				}
+				known[col] = true
+				columns = append(columns, col)
+			}
+			rows[i][col] = inspectWithDepth(elementOrProperty(row, col), 0)
+		}
+	}
+
+	header := append([]string{"(index)"}, columns...)
+	cells := make([][]string, len(keys))
+	for i, key := range keys {
+		cells[i] = append(cells[i], key)
+		for _, col := range columns {
+			cells[i] = append(cells[i], rows[i][col])
+		}
+	}
+	if hasValues {
+		header = append(header, "Values")
+		for i := range cells {
+			cells[i] = append(cells[i], values[i])
+		}
+	}
+	c.printLine(c.realm.Agent.IO.Stdout, strings.TrimSuffix(renderTable(header, cells), "\n"))
+	return runtime.Undefined, nil
+}
+
+// renderTable draws header and rows with box-drawing characters, each
+// cell centred in a column as wide as its widest cell plus a space on
+// either side.
+func renderTable(header []string, rows [][]string) string {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = utf8.RuneCountInString(h) + 2
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := utf8.RuneCountInString(cell) + 2; w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	var sb strings.Builder
+	line := func(left, mid, right string) {
+		sb.WriteString(left)
+		for i, w := range widths {
+			if i > 0 {
+				sb.WriteString(mid)
+			}
+			sb.WriteString(strings.Repeat("─", w))
+		}
+		sb.WriteString(right + "\n")
+	}
+	row := func(cells []string) {
+		sb.WriteString("│")
+		for i, w := range widths {
+			if i > 0 {
+				sb.WriteString("│")
+			}
+			pad := w - utf8.RuneCountInString(cells[i])
+			sb.WriteString(strings.Repeat(" ", pad/2) + cells[i] + strings.Repeat(" ", pad-pad/2))
+		}
+		sb.WriteString("│\n")
+	}
+	line("┌", "┬", "┐")
+	row(header)
+	line("├", "┼", "┤")
+	for _, r := range rows {
+		row(r)
+	}
+	line("└", "┴", "┘")
+	return sb.String()
+}
+
+// enumerableKeys returns the own enumerable string keys of obj, with the
+// elements of an array first.
+func enumerableKeys(obj *runtime.Object) []string {
+	var keys []string
+	if obj.OType == runtime.ObjTypeArray {
+		for _, i := range obj.ElementIndices() {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	}
+	for _, key := range runtime.OwnKeys(obj) {
+		prop := obj.Properties[key]
+		if prop == nil || !prop.Enumerable || runtime.IsSymbolKey(key) {
+			continue
+		}
+		if obj.OType == runtime.ObjTypeArray && (isIndexKey(key) || key == "length") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// elementOrProperty reads key from obj, from the elements of an array.
+func elementOrProperty(obj *runtime.Object, key string) *runtime.Value {
+	if obj.OType == runtime.ObjTypeArray {
+		if i, err := strconv.Atoi(key); err == nil {
+			return elementAt(obj, i)
+		}
+	}
+	if v := obj.Get(key); v != nil {
+		return v
+	}
+	return runtime.Undefined
+}
+
+// consoleLabel is the label argument of console.count and console.time.
+func consoleLabel(args []*runtime.Value) string {
+	if label := argAt(args, 0); label.Type != runtime.TypeUndefined {
+		return label.ToString()
+	}
+	return "default"
+}
+
+func (c *consoleState) count(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	label := consoleLabel(args)
+	c.counts[label]++
+	c.printLine(c.realm.Agent.IO.Stdout, fmt.Sprintf("%s: %d", label, c.counts[label]))
+	return runtime.Undefined, nil
+}
+
+func (c *consoleState) countReset(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	label := consoleLabel(args)
+	if _, ok := c.counts[label]; !ok {
+		c.printLine(c.realm.Agent.IO.Stderr, fmt.Sprintf("Warning: Count for '%s' does not exist", label))
+		return runtime.Undefined, nil
+	}
+	c.counts[label] = 0
+	return runtime.Undefined, nil
+}
+
+func (c *consoleState) time(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	label := consoleLabel(args)
+	if _, ok := c.timers[label]; ok {
+		c.printLine(c.realm.Agent.IO.Stderr, fmt.Sprintf("Warning: Label '%s' already exists for console.time()", label))
+		return runtime.Undefined, nil
+	}
+	c.timers[label] = time.Now()
+	return runtime.Undefined, nil
+}
+
+// timeLog prints the time elapsed on a timer, followed by any further
+// arguments, and leaves the timer running.
+func (c *consoleState) timeLog(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return c.printTimer("console.timeLog()", args, false)
+}
+
+func (c *consoleState) timeEnd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return c.printTimer("console.timeEnd()", args, true)
+}
+
+func (c *consoleState) printTimer(method string, args []*runtime.Value, stop bool) (*runtime.Value, error) {
+	label := consoleLabel(args)
+	start, ok := c.timers[label]
+	if !ok {
+		c.printLine(c.realm.Agent.IO.Stderr, fmt.Sprintf("Warning: No such label '%s' for %s", label, method))
+		return runtime.Undefined, nil
+	}
+	if stop {
+		delete(c.timers, label)
+	}
+	line := label + ": " + formatElapsed(time.Since(start))
+	if len(args) > 1 {
+		line += " " + c.realm.formatArgs(args[1:])
+	}
+	c.printLine(c.realm.Agent.IO.Stdout, line)
+	return runtime.Undefined, nil
+}
+
+// formatElapsed formats a timer as Node does: milliseconds with three
+// decimals, or seconds from one second on.
+func formatElapsed(d time.Duration) string {
+	ms := float64(d) / float64(time.Millisecond)
+	if ms >= 1000 {
+		return strconv.FormatFloat(ms/1000, 'f', 3, 64) + "s"
+	}
+	return strconv.FormatFloat(ms, 'f', 3, 64) + "ms"
+}
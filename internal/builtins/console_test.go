@@ -0,0 +1,286 @@
+package builtins
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// captureOutput runs fn with the console writing to buffers, and returns
+// what it printed to stdout and stderr.
+func (rl *Realm) captureOutput(fn func()) (string, string) {
+	var out, errOut bytes.Buffer
+	old := rl.Agent.IO
+	rl.Agent.IO = &runtime.Streams{Stdout: &out, Stderr: &errOut}
+	defer func() { rl.Agent.IO = old }()
+	fn()
+	return out.String(), errOut.String()
+}
+
+// captureStdout is captureOutput for stdout alone.
+func (rl *Realm) captureStdout(fn func()) string {
+	out, _ := rl.captureOutput(fn)
+	return out
+}
+
+func TestConsoleLog(t *testing.T) {
+	rl := registerTestRealm()
+	out := rl.captureStdout(func() {
+		(&consoleState{realm: rl}).log(runtime.Undefined, []*runtime.Value{runtime.NewString("hello"), runtime.NewNumber(42)})
+	})
+	got := strings.TrimSpace(out)
+	if got != "hello 42" {
+		t.Errorf("console.log: got %q, want %q", got, "hello 42")
+	}
+}
+
+func TestConsoleError(t *testing.T) {
+	rl := registerTestRealm()
+	out, errOut := rl.captureOutput(func() {
+		(&consoleState{realm: rl}).error(runtime.Undefined, []*runtime.Value{runtime.NewString("error!")})
+	})
+	if out != "" {
+		t.Errorf("console.error wrote %q to stdout", out)
+	}
+	got := strings.TrimSpace(errOut)
+	if got != "error!" {
+		t.Errorf("console.error: got %q, want %q", got, "error!")
+	}
+}
+
+func TestConsoleLogArray(t *testing.T) {
+	rl := registerTestRealm()
+	arr := rl.newArray([]*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(2), runtime.NewNumber(3)})
+	got := strings.TrimSpace(rl.captureStdout(func() {
+		(&consoleState{realm: rl}).log(runtime.Undefined, []*runtime.Value{runtime.NewObject(arr)})
+	}))
+	if got != "[ 1, 2, 3 ]" {
+		t.Errorf("console.log array: got %q, want %q", got, "[ 1, 2, 3 ]")
+	}
+}
+
+func TestConsoleFormatSpecifiers(t *testing.T) {
+	rl := setupArray()
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("a", runtime.NewNumber(1))
+
+	tests := []struct {
+		args []*runtime.Value
+		want string
+	}{
+		{[]*runtime.Value{runtime.NewString("%s is %d"), runtime.NewString("x"), runtime.NewNumber(42.7)}, "x is 42.7"},
+		{[]*runtime.Value{runtime.NewString("%d|%i"), runtime.NewNumber(42.5), runtime.NewNumber(42.5)}, "42.5|42"},
+		{[]*runtime.Value{runtime.NewString("%d"), runtime.NewString("-0.25")}, "-0.25"},
+		{[]*runtime.Value{runtime.NewString("%i|%f"), runtime.NewString("7.9"), runtime.NewString("1.5")}, "7|1.5"},
+		{[]*runtime.Value{runtime.NewString("%d"), runtime.NewObject(obj)}, "NaN"},
+		{[]*runtime.Value{runtime.NewString("%j %o"), runtime.NewObject(obj), runtime.NewObject(obj)}, `{"a":1} { a: 1 }`},
+		{[]*runtime.Value{runtime.NewString("100%% %c%s"), runtime.NewString("color: red"), runtime.NewString("done")}, "100% done"},
+		{[]*runtime.Value{runtime.NewString("%s and %s"), runtime.NewString("one")}, "one and %s"},
+		{[]*runtime.Value{runtime.NewString("%s"), runtime.NewString("a"), runtime.NewString("b"), runtime.NewNumber(3)}, "a b 3"},
+		{[]*runtime.Value{runtime.NewNumber(1), runtime.NewString("%s")}, "1 %s"},
+	}
+	for _, tt := range tests {
+		if got := rl.formatArgs(tt.args); got != tt.want {
+			t.Errorf("formatArgs: got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	rl := setupArray()
+	inner := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	inner.Set("deep", runtime.NewObject(rl.newArray([]*runtime.Value{runtime.NewNumber(1)})))
+	mid := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	mid.Set("inner", runtime.NewObject(inner))
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("s", runtime.NewString("it's"))
+	obj.Set("my-key", runtime.Null)
+	obj.Set("mid", runtime.NewObject(mid))
+	obj.Set("self", runtime.NewObject(obj))
+
+	want := `{ s: 'it\'s', 'my-key': null, mid: { inner: { deep: [Array] } }, self: [Circular] }`
+	if got := Inspect(runtime.NewObject(obj)); got != want {
+		t.Errorf("Inspect: got %s, want %s", got, want)
+	}
+	if got := inspectWithDepth(runtime.NewObject(obj), -1); !strings.Contains(got, "deep: [ 1 ]") {
+		t.Errorf("Inspect with no depth limit: got %s", got)
+	}
+	if got := Inspect(runtime.NewNumber(math.Copysign(0, -1))); got != "-0" {
+		t.Errorf("Inspect(-0): got %s", got)
+	}
+}
+
+func TestInspectFunctions(t *testing.T) {
+	rl := setupArray()
+	named := func(name string, proto *runtime.Object) *runtime.Object {
+		fn := runtime.NewFunctionObject(proto, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return runtime.Undefined, nil
+		})
+		fn.DefineProperty("name", &runtime.Property{Value: runtime.NewString(name), Configurable: true, HasValue: true})
+		return fn
+	}
+	class := func(name string, parent *runtime.Object) *runtime.Object {
+		fn := named(name, parent)
+		fn.Internal = map[string]interface{}{"isClass": true}
+		return fn
+	}
+	kind := func(fn *runtime.Object, flags ...string) *runtime.Object {
+		fn.Internal = map[string]interface{}{}
+		for _, flag := range flags {
+			fn.Internal[flag] = true
+		}
+		return fn
+	}
+	base := class("K", rl.FunctionPrototype)
+	tests := []struct {
+		fn   *runtime.Object
+		want string
+	}{
+		{named("f", rl.FunctionPrototype), "[Function: f]"},
+		{named("", rl.FunctionPrototype), "[Function (anonymous)]"},
+		{base, "[class K]"},
+		{class("", rl.FunctionPrototype), "[class (anonymous)]"},
+		{class("D", base), "[class D extends K]"},
+		{kind(named("a", rl.FunctionPrototype), "isAsync"), "[AsyncFunction: a]"},
+		{kind(named("g", rl.FunctionPrototype), "isGenerator"), "[GeneratorFunction: g]"},
+		{kind(named("", rl.FunctionPrototype), "isAsync", "isGenerator"), "[AsyncGeneratorFunction (anonymous)]"},
+	}
+	for _, tt := range tests {
+		if got := Inspect(runtime.NewObject(tt.fn)); got != tt.want {
+			t.Errorf("Inspect: got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestInspectBuiltinObjects(t *testing.T) {
+	rl := registerTestRealm()
+	re, err := rl.createRegExpObject("a.b", "gi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty, _ := rl.createRegExpObject("", "")
+	date := rl.makeDateObject(nil, time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC), false)
+	invalid := rl.makeDateObject(nil, time.Time{}, true)
+	promise := func(state int, val *runtime.Value) *runtime.Value {
+		obj, pd := rl.newPromiseObject()
+		if state != promisePending {
+			pd.settle(state, val)
+		}
+		return runtime.NewObject(obj)
+	}
+	sym := &runtime.Value{Type: runtime.TypeSymbol, Symbol: &runtime.Symbol{Description: "tag"}}
+	withSymbol := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	withSymbol.Set("a", runtime.NewNumber(1))
+	withSymbol.Set(sym.ToPropertyKey(), runtime.NewString("s"))
+
+	tests := []struct {
+		v    *runtime.Value
+		want string
+	}{
+		{re, "/a.b/gi"},
+		{empty, "/(?:)/"},
+		{date, "2020-01-02T03:04:05.006Z"},
+		{invalid, "Invalid Date"},
+		{promise(promisePending, nil), "Promise { <pending> }"},
+		{promise(promiseFulfilled, runtime.NewNumber(1)), "Promise { 1 }"},
+		{promise(promiseRejected, runtime.NewString("no")), "Promise { <rejected> 'no' }"},
+		{runtime.NewObject(withSymbol), "{ a: 1, [Symbol(tag)]: 's' }"},
+	}
+	for _, tt := range tests {
+		if got := Inspect(tt.v); got != tt.want {
+			t.Errorf("Inspect: got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestConsoleTable(t *testing.T) {
+	rl := setupArray()
+	row := func(k string, v *runtime.Value) *runtime.Value {
+		o := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+		o.Set(k, v)
+		return runtime.NewObject(o)
+	}
+	data := runtime.NewObject(rl.newArray([]*runtime.Value{row("a", runtime.NewNumber(1)), row("b", runtime.NewString("Y")), runtime.NewNumber(7)}))
+	got := rl.captureStdout(func() { (&consoleState{realm: rl}).table(runtime.Undefined, []*runtime.Value{data}) })
+	want := "" +
+		"┌─────────┬───┬─────┬────────┐\n" +
+		"│ (index) │ a │  b  │ Values │\n" +
+		"├─────────┼───┼─────┼────────┤\n" +
+		"│    0    │ 1 │     │        │\n" +
+		"│    1    │   │ 'Y' │        │\n" +
+		"│    2    │   │     │   7    │\n" +
+		"└─────────┴───┴─────┴────────┘\n"
+	if got != want {
+		t.Errorf("console.table:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConsoleCountAndTime(t *testing.T) {
+	rl := setupArray()
+	console := rl.createConsoleObject(rl.ObjectPrototype)
+	call := func(name string, args ...*runtime.Value) {
+		getCallable(console.Get(name))(runtime.NewObject(console), args)
+	}
+	got := rl.captureStdout(func() {
+		call("count")
+		call("count")
+		call("count", runtime.NewString("x"))
+		call("countReset")
+		call("count")
+	})
+	if got != "default: 1\ndefault: 2\nx: 1\ndefault: 1\n" {
+		t.Errorf("console.count: got %q", got)
+	}
+
+	got = rl.captureStdout(func() {
+		call("time", runtime.NewString("t"))
+		call("timeEnd", runtime.NewString("t"))
+	})
+	if !strings.HasPrefix(got, "t: ") || !strings.HasSuffix(got, "ms\n") {
+		t.Errorf("console.timeEnd: got %q", got)
+	}
+}
+
+func TestConsoleLoneSurrogates(t *testing.T) {
+	rl := registerTestRealm()
+	lone := runtime.NewString(runtime.FromUTF16([]uint16{'a', 0xDE00, '\''}))
+	arr := rl.newArray([]*runtime.Value{lone})
+	got := rl.captureStdout(func() {
+		(&consoleState{realm: rl}).log(runtime.Undefined, []*runtime.Value{lone, runtime.NewObject(arr)})
+	})
+	if want := "a�' [ 'a\\ude00\\'' ]\n"; got != want {
+		t.Errorf("console.log: got %q, want %q", got, want)
+	}
+}
+
+func TestConsoleGroupAndAssert(t *testing.T) {
+	rl := setupArray()
+	console := rl.createConsoleObject(rl.ObjectPrototype)
+	call := func(name string, args ...*runtime.Value) {
+		getCallable(console.Get(name))(runtime.NewObject(console), args)
+	}
+	out, errOut := rl.captureOutput(func() {
+		call("group", runtime.NewString("A"), runtime.NewNumber(1))
+		call("log", runtime.NewString("x\ny"))
+		call("groupCollapsed")
+		call("assert", runtime.False, runtime.NewString("n=%d"), runtime.NewNumber(5))
+		call("count")
+		call("groupEnd")
+		call("groupEnd")
+		call("groupEnd")
+		call("assert", runtime.True, runtime.NewString("no"))
+		call("assert", runtime.NewNumber(0))
+		call("assert", runtime.Null, runtime.NewObject(rl.newArray([]*runtime.Value{runtime.NewNumber(1)})))
+		call("log", runtime.NewString("z"))
+	})
+	if want := "A 1\n  x\n  y\n    default: 1\nz\n"; out != want {
+		t.Errorf("stdout: got %q, want %q", out, want)
+	}
+	if want := "    Assertion failed: n=5\nAssertion failed\nAssertion failed [ 1 ]\n"; errOut != want {
+		t.Errorf("stderr: got %q, want %q", errOut, want)
+	}
+}
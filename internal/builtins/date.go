@@ -0,0 +1,768 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createDateConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	rl.DatePrototype = proto
+
+	// Prototype methods
+	rl.setDateMethod(proto, "getTime", 0, dateGetTime)
+	rl.setDateMethod(proto, "getFullYear", 0, dateGetFullYear)
+	rl.setDateMethod(proto, "getMonth", 0, dateGetMonth)
+	rl.setDateMethod(proto, "getDate", 0, dateGetDate)
+	rl.setDateMethod(proto, "getHours", 0, dateGetHours)
+	rl.setDateMethod(proto, "getMinutes", 0, dateGetMinutes)
+	rl.setDateMethod(proto, "getSeconds", 0, dateGetSeconds)
+	rl.setDateMethod(proto, "getMilliseconds", 0, dateGetMilliseconds)
+	rl.setDateMethod(proto, "getTimezoneOffset", 0, dateGetTimezoneOffset)
+	rl.setDateMethod(proto, "toString", 0, dateToString)
+	rl.setDateMethod(proto, "toDateString", 0, dateToDateString)
+	rl.setDateMethod(proto, "toTimeString", 0, dateToTimeString)
+	rl.setDateMethod(proto, "toISOString", 0, dateToISOString)
+	rl.setMethod(proto, "toJSON", 1, dateToJSON)
+	rl.setDateMethod(proto, "toLocaleDateString", 0, dateToLocaleDateString)
+	rl.setDateMethod(proto, "toLocaleTimeString", 0, dateToLocaleTimeString)
+	rl.setDateMethod(proto, "toLocaleString", 0, dateToLocaleString)
+	rl.setDateMethod(proto, "valueOf", 0, dateValueOf)
+	if SymToPrimitive != nil {
+		fn := rl.newFuncObject("[Symbol.toPrimitive]", 1, dateToPrimitive)
+		setDataProp(proto, SymToPrimitive.Key(), runtime.NewObject(fn), false, false, true)
+	}
+	rl.setDateMethod(proto, "getDay", 0, dateGetDay)
+	rl.setDateMethod(proto, "getUTCFullYear", 0, dateGetUTCFullYear)
+	rl.setDateMethod(proto, "getUTCMonth", 0, dateGetUTCMonth)
+	rl.setDateMethod(proto, "getUTCDate", 0, dateGetUTCDate)
+	rl.setDateMethod(proto, "getUTCHours", 0, dateGetUTCHours)
+	rl.setDateMethod(proto, "getUTCMinutes", 0, dateGetUTCMinutes)
+	rl.setDateMethod(proto, "getUTCSeconds", 0, dateGetUTCSeconds)
+	rl.setDateMethod(proto, "getUTCMilliseconds", 0, dateGetUTCMilliseconds)
+	rl.setDateMethod(proto, "getUTCDay", 0, dateGetUTCDay)
+	rl.setDateMethod(proto, "setTime", 1, dateSetTime)
+	rl.setDateMethod(proto, "setFullYear", 3, dateSetter(dateFieldYear, 3, false))
+	rl.setDateMethod(proto, "setMonth", 2, dateSetter(dateFieldMonth, 2, false))
+	rl.setDateMethod(proto, "setDate", 1, dateSetter(dateFieldDay, 1, false))
+	rl.setDateMethod(proto, "setHours", 4, dateSetter(dateFieldHours, 4, false))
+	rl.setDateMethod(proto, "setMinutes", 3, dateSetter(dateFieldMinutes, 3, false))
+	rl.setDateMethod(proto, "setSeconds", 2, dateSetter(dateFieldSeconds, 2, false))
+	rl.setDateMethod(proto, "setMilliseconds", 1, dateSetter(dateFieldMs, 1, false))
+	rl.setDateMethod(proto, "setUTCFullYear", 3, dateSetter(dateFieldYear, 3, true))
+	rl.setDateMethod(proto, "setUTCMonth", 2, dateSetter(dateFieldMonth, 2, true))
+	rl.setDateMethod(proto, "setUTCDate", 1, dateSetter(dateFieldDay, 1, true))
+	rl.setDateMethod(proto, "setUTCHours", 4, dateSetter(dateFieldHours, 4, true))
+	rl.setDateMethod(proto, "setUTCMinutes", 3, dateSetter(dateFieldMinutes, 3, true))
+	rl.setDateMethod(proto, "setUTCSeconds", 2, dateSetter(dateFieldSeconds, 2, true))
+	rl.setDateMethod(proto, "setUTCMilliseconds", 1, dateSetter(dateFieldMs, 1, true))
+	rl.setDateMethod(proto, "toUTCString", 0, dateToUTCString)
+
+	// Annex B methods
+	rl.setDateMethod(proto, "getYear", 0, dateGetYear)
+	rl.setDateMethod(proto, "setYear", 1, dateSetYear)
+	// toGMTString must be the SAME function object as toUTCString per spec
+	proto.DefineProperty("toGMTString", proto.Properties["toUTCString"])
+
+	// Constructor: Date() as function returns string, new Date() creates object
+	ctor := rl.newFuncObject("Date", 7, dateCall)
+	ctor.Constructor = rl.dateConstruct
+
+	// Static methods
+	rl.setMethod(ctor, "now", 0, dateNow)
+	rl.setMethod(ctor, "parse", 1, dateParse)
+	rl.setMethod(ctor, "UTC", 7, dateUTC)
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+// dateCall is invoked when Date() is called as a function (without new)
+func dateCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(time.Now().Format("Mon Jan 02 2006 15:04:05 GMT-0700 (MST)")), nil
+}
+
+// dateConstruct is invoked for new Date(...)
+func (rl *Realm) dateConstruct(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	switch len(args) {
+	case 0:
+		return rl.makeDateObject(this, time.Now(), false), nil
+	case 1:
+		if isDateObject(args[0]) {
+			t, inv := getDateValue(args[0])
+			return rl.makeDateObject(this, t, inv), nil
+		}
+		prim, err := runtime.ToPrimitive(args[0], "default")
+		if err != nil {
+			return nil, err
+		}
+		if prim.Type == runtime.TypeString {
+			t, err := parseDate(prim.Str)
+			return rl.makeDateObject(this, t, err != nil), nil
+		}
+		ms, err := toNumberErr(prim)
+		if err != nil {
+			return nil, err
+		}
+		t, inv := dateFromMs(ms)
+		return rl.makeDateObject(this, t, inv), nil
+	}
+	// new Date(year, month[, day, hours, minutes, seconds, ms]) in local time
+	fields, err := dateArgFields(args)
+	if err != nil {
+		return nil, err
+	}
+	t, inv := makeDate(fields, time.Local)
+	return rl.makeDateObject(this, t, inv), nil
+}
+
+func (rl *Realm) makeDateObject(this *runtime.Value, t time.Time, invalid bool) *runtime.Value {
+	if this != nil && this.Type == runtime.TypeObject && this.Object != nil {
+		if this.Object.Internal == nil {
+			this.Object.Internal = make(map[string]interface{})
+		}
+		this.Object.OType = runtime.ObjTypeDate
+		this.Object.Internal["DateValue"] = t
+		this.Object.Internal["DateInvalid"] = invalid
+		return this
+	}
+	obj := runtime.NewOrdinaryObject(rl.DatePrototype)
+	obj.OType = runtime.ObjTypeDate
+	obj.Internal = map[string]interface{}{
+		"DateValue":   t,
+		"DateInvalid": invalid,
+	}
+	return runtime.NewObject(obj)
+}
+
+func getDateValue(this *runtime.Value) (time.Time, bool) {
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil {
+		return time.Time{}, true
+	}
+	inv, _ := this.Object.Internal["DateInvalid"].(bool)
+	if inv {
+		return time.Time{}, true
+	}
+	t, ok := this.Object.Internal["DateValue"].(time.Time)
+	if !ok {
+		return time.Time{}, true
+	}
+	return t, false
+}
+
+// isDateObject reports whether v is a Date, that is, has a [[DateValue]].
+func isDateObject(v *runtime.Value) bool {
+	if v == nil || v.Type != runtime.TypeObject || v.Object == nil || v.Object.Internal == nil {
+		return false
+	}
+	_, ok := v.Object.Internal["DateValue"]
+	return ok
+}
+
+// setDateMethod adds a Date.prototype method that throws a TypeError when
+// called on anything but a Date.
+func (rl *Realm) setDateMethod(proto *runtime.Object, name string, length int, fn runtime.CallableFunc) {
+	rl.setMethod(proto, name, length, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if !isDateObject(this) {
+			return nil, fmt.Errorf("TypeError: this is not a Date object")
+		}
+		return fn(this, args)
+	})
+}
+
+// setDateValue stores a new time value in the Date this and returns it as
+// a number, NaN if invalid.
+func setDateValue(this *runtime.Value, t time.Time, invalid bool) *runtime.Value {
+	this.Object.Internal["DateValue"] = t
+	this.Object.Internal["DateInvalid"] = invalid
+	if invalid {
+		return runtime.NaN
+	}
+	return runtime.NewNumber(float64(t.UnixMilli()))
+}
+
+// maxDateMs is the TimeClip limit: a Date is at most 8.64e15 ms, 100
+// million days, from the epoch.
+const maxDateMs = 8.64e15
+
+// Indexes of the components in the fields passed to makeDate.
+const (
+	dateFieldYear = iota
+	dateFieldMonth
+	dateFieldDay
+	dateFieldHours
+	dateFieldMinutes
+	dateFieldSeconds
+	dateFieldMs
+)
+
+// dateFromMs converts a time value in ms since the epoch, applying
+// TimeClip: invalid is true for NaN, infinities and out of range values.
+func dateFromMs(ms float64) (t time.Time, invalid bool) {
+	if math.IsNaN(ms) || math.Abs(ms) > maxDateMs {
+		return time.Time{}, true
+	}
+	return time.UnixMilli(int64(ms)), false
+}
+
+// makeDate builds a time from year, month (0-based), day, hours, minutes,
+// seconds and milliseconds in loc, like MakeDate followed by TimeClip.
+// Components are truncated to integers and out of range ones carry over,
+// so month 12 is January of the next year. invalid is true if a component
+// is not finite or the result is out of range.
+func makeDate(fields [7]float64, loc *time.Location) (t time.Time, invalid bool) {
+	for i, f := range fields {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return time.Time{}, true
+		}
+		fields[i] = math.Trunc(f)
+	}
+	// Anything this far out is past the TimeClip limit; checking first
+	// keeps the arithmetic below from overflowing.
+	if math.Abs(fields[dateFieldYear]) > 400000 || math.Abs(fields[dateFieldMonth]) > 400000*12 {
+		return time.Time{}, true
+	}
+	month := time.Date(int(fields[dateFieldYear]), time.Month(int(fields[dateFieldMonth])+1), 1, 0, 0, 0, 0, time.UTC)
+	// The rest is added as wall clock time, which is then placed in loc.
+	wall := float64(month.UnixMilli()) + (fields[dateFieldDay]-1)*864e5 +
+		fields[dateFieldHours]*36e5 + fields[dateFieldMinutes]*6e4 + fields[dateFieldSeconds]*1e3 + fields[dateFieldMs]
+	if math.Abs(wall) > 2*maxDateMs {
+		return time.Time{}, true
+	}
+	w := time.UnixMilli(int64(wall)).UTC()
+	t = time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), loc)
+	if math.Abs(float64(t.UnixMilli())) > maxDateMs {
+		return time.Time{}, true
+	}
+	return t, false
+}
+
+// dateComponents splits t into the components used by makeDate.
+func dateComponents(t time.Time) [7]float64 {
+	return [7]float64{
+		float64(t.Year()), float64(t.Month() - 1), float64(t.Day()),
+		float64(t.Hour()), float64(t.Minute()), float64(t.Second()), float64(t.Nanosecond() / 1e6),
+	}
+}
+
+// dateArgFields converts the arguments of new Date(year, month, ...) and
+// Date.UTC into makeDate components. Missing ones default to the first of
+// January at midnight, and years 0 to 99 mean 1900 to 1999.
+func dateArgFields(args []*runtime.Value) ([7]float64, error) {
+	fields := [7]float64{0, 0, 1}
+	for i := 0; i < len(args) && i < len(fields); i++ {
+		n, err := toNumberErr(args[i])
+		if err != nil {
+			return fields, err
+		}
+		fields[i] = n
+	}
+	if y := math.Trunc(fields[dateFieldYear]); y >= 0 && y <= 99 {
+		fields[dateFieldYear] = 1900 + y
+	}
+	return fields, nil
+}
+
+func getDateMs(this *runtime.Value) float64 {
+	t, invalid := getDateValue(this)
+	if invalid {
+		return math.NaN()
+	}
+	return float64(t.UnixMilli())
+}
+
+// Static methods
+
+func dateNow(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewNumber(float64(time.Now().UnixMilli())), nil
+}
+
+func dateParse(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := argAt(args, 0).ToString()
+	t, err := parseDate(s)
+	if err != nil {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UnixMilli())), nil
+}
+
+func dateUTC(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 0 {
+		return runtime.NaN, nil
+	}
+	fields, err := dateArgFields(args)
+	if err != nil {
+		return nil, err
+	}
+	t, inv := makeDate(fields, time.UTC)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UnixMilli())), nil
+}
+
+// Prototype methods - getters
+
+func dateGetTime(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewNumber(getDateMs(this)), nil
+}
+
+func dateGetFullYear(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Year())), nil
+}
+
+func dateGetMonth(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Month() - 1)), nil
+}
+
+func dateGetDate(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Day())), nil
+}
+
+func dateGetDay(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Weekday())), nil
+}
+
+func dateGetHours(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Hour())), nil
+}
+
+func dateGetMinutes(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Minute())), nil
+}
+
+func dateGetSeconds(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Second())), nil
+}
+
+func dateGetMilliseconds(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Nanosecond() / 1e6)), nil
+}
+
+func dateGetTimezoneOffset(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	_, offset := t.Zone()
+	return runtime.NewNumber(float64(-offset / 60)), nil
+}
+
+// UTC getters
+
+func dateGetUTCFullYear(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Year())), nil
+}
+
+func dateGetUTCMonth(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Month() - 1)), nil
+}
+
+func dateGetUTCDate(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Day())), nil
+}
+
+func dateGetUTCHours(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Hour())), nil
+}
+
+func dateGetUTCMinutes(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Minute())), nil
+}
+
+func dateGetUTCSeconds(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Second())), nil
+}
+
+func dateGetUTCMilliseconds(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Nanosecond() / 1e6)), nil
+}
+
+func dateGetUTCDay(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.UTC().Weekday())), nil
+}
+
+// Setters
+
+func dateSetTime(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	ms, err := toNumberErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	t, inv := dateFromMs(ms)
+	return setDateValue(this, t, inv), nil
+}
+
+// dateSetter returns a setter that replaces up to n components of the
+// date, starting at first, in local time or UTC. Components past the first
+// are only replaced when passed. On an invalid date only the year setters
+// do anything, starting from the epoch.
+func dateSetter(first, n int, utc bool) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		loc := time.Local
+		if utc {
+			loc = time.UTC
+		}
+		t, inv := getDateValue(this)
+		fields := dateComponents(t.In(loc))
+		if inv {
+			fields = dateComponents(time.UnixMilli(0).UTC())
+		}
+		for i := 0; i < n && (i == 0 || i < len(args)); i++ {
+			v, err := toNumberErr(argAt(args, i))
+			if err != nil {
+				return nil, err
+			}
+			fields[first+i] = v
+		}
+		if inv && first != dateFieldYear {
+			return runtime.NaN, nil
+		}
+		t, inv = makeDate(fields, loc)
+		return setDateValue(this, t, inv), nil
+	}
+}
+
+// Annex B methods
+
+func dateGetYear(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(t.Year() - 1900)), nil
+}
+
+func dateSetYear(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	// Step 3: Read [[DateValue]] FIRST (before ToNumber)
+	t, inv := getDateValue(this)
+	// Step 4: Let y be ? ToNumber(year) - must propagate errors
+	yearArg, err := toNumberErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	// Step 5: If t is NaN, set t to +0; otherwise, set t to LocalTime(t)
+	// When t is NaN, we use UTC epoch components (month=Jan, day=1, time=00:00)
+	// but the final date is constructed in local time (because setYear uses local time)
+	if inv {
+		t = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.Local)
+	}
+	if math.IsNaN(yearArg) {
+		return setDateValue(this, t, true), nil
+	}
+	// Step 6: Let yyyy be MakeFullYear(y)
+	// Must use ToInteger(y) for the 0-99 check per spec:
+	// "If 0 ≤ ToInteger(y) ≤ 99, let yyyy be ToInteger(y) + 1900"
+	intYear := math.Trunc(yearArg)
+	if math.Signbit(yearArg) && intYear == 0 {
+		intYear = 0 // -0 → +0 for the comparison
+	}
+	year := int(intYear)
+	if intYear >= 0 && intYear <= 99 {
+		year += 1900
+	}
+	// Create new date
+	newDate := time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1e6*1e6, t.Location())
+	// Step 9: TimeClip
+	ms := float64(newDate.UnixMilli())
+	if math.Abs(ms) > maxDateMs {
+		return setDateValue(this, t, true), nil
+	}
+	return setDateValue(this, newDate, false), nil
+}
+
+// toString methods
+
+func dateToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(formatDateString(t)), nil
+}
+
+func dateToDateString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(t.Format("Mon Jan 02 2006")), nil
+}
+
+func dateToTimeString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(t.Format("15:04:05 GMT-0700 (MST)")), nil
+}
+
+func dateToISOString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return nil, fmt.Errorf("RangeError: Invalid time value")
+	}
+	return runtime.NewString(formatDateISO(t)), nil
+}
+
+// dateToJSON is generic: it calls toISOString on any object whose time
+// value is finite, and returns null otherwise.
+func dateToJSON(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil {
+		return nil, fmt.Errorf("TypeError: Date.prototype.toJSON called on non-object")
+	}
+	tv, err := runtime.ToPrimitive(this, "number")
+	if err != nil {
+		return nil, err
+	}
+	if tv.Type == runtime.TypeNumber && (math.IsNaN(tv.Number) || math.IsInf(tv.Number, 0)) {
+		return runtime.Null, nil
+	}
+	toISO := getCallable(this.Object.Get("toISOString"))
+	if toISO == nil {
+		return nil, fmt.Errorf("TypeError: toISOString is not a function")
+	}
+	return toISO(this, nil)
+}
+
+func dateToLocaleDateString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(t.Format("1/2/2006")), nil
+}
+
+func dateToLocaleTimeString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(t.Format("3:04:05 PM")), nil
+}
+
+func dateToLocaleString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(t.Format("1/2/2006, 3:04:05 PM")), nil
+}
+
+func dateToUTCString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, inv := getDateValue(this)
+	if inv {
+		return runtime.NewString("Invalid Date"), nil
+	}
+	return runtime.NewString(t.UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT")), nil
+}
+
+func dateValueOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewNumber(getDateMs(this)), nil
+}
+
+// Helpers
+
+func formatDateString(t time.Time) string {
+	return t.Format("Mon Jan 02 2006 15:04:05 GMT-0700 (MST)")
+}
+
+// formatDateISO formats t in the Date Time String Format, with a signed
+// six digit year outside 0000 to 9999.
+func formatDateISO(t time.Time) string {
+	t = t.UTC()
+	year := fmt.Sprintf("%04d", t.Year())
+	if t.Year() < 0 || t.Year() > 9999 {
+		year = fmt.Sprintf("%+07d", t.Year())
+	}
+	return fmt.Sprintf("%s-%02d-%02dT%02d:%02d:%02d.%03dZ", year, t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1e6)
+}
+
+// isoDatePattern matches the Date Time String Format: YYYY, YYYY-MM or
+// YYYY-MM-DD, optionally followed by THH:mm, seconds, a fraction and an
+// offset.
+var isoDatePattern = regexp.MustCompile(`^([+-]\d{6}|\d{4})(?:-(\d{2})(?:-(\d{2}))?)?(?:T(\d{2}):(\d{2})(?::(\d{2})(?:\.(\d+))?)?(Z|[+-]\d{2}:\d{2})?)?$`)
+
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("invalid date")
+	}
+	if m := isoDatePattern.FindStringSubmatch(s); m != nil {
+		return parseISODate(s, m)
+	}
+
+	// Other formats are implementation-defined; they are read in local
+	// time unless they name a zone.
+	formats := []string{
+		"Mon Jan 02 2006 15:04:05 GMT-0700 (MST)",
+		"Mon Jan 02 2006 15:04:05 GMT-0700",
+		"Mon Jan 02 2006 15:04:05",
+		"Mon Jan 02 2006",
+		"Monday, January 2, 2006",
+		"January 2, 2006 15:04:05",
+		"January 2, 2006",
+		"January 2 2006",
+		"2 January 2006",
+		"Jan 2, 2006 15:04:05",
+		"Jan 2, 2006",
+		"Jan 2 2006",
+		"2 Jan 2006",
+		"1/2/2006 15:04:05",
+		"1/2/2006",
+		"2006/01/02 15:04:05",
+		"2006/01/02",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		time.RFC1123,
+		time.RFC1123Z,
+		time.RFC3339,
+	}
+
+	for _, layout := range formats {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", s)
+}
+
+// parseISODate builds the time for a match of isoDatePattern. Date-only
+// forms are UTC, date-time forms without an offset local time.
+func parseISODate(s string, m []string) (time.Time, error) {
+	invalid := fmt.Errorf("invalid date: %s", s)
+	if m[1] == "-000000" {
+		return time.Time{}, invalid
+	}
+	num := func(str string, def int) float64 {
+		if str == "" {
+			return float64(def)
+		}
+		n, _ := strconv.Atoi(str)
+		return float64(n)
+	}
+	fields := [7]float64{
+		num(m[1], 0), num(m[2], 1) - 1, num(m[3], 1),
+		num(m[4], 0), num(m[5], 0), num(m[6], 0),
+	}
+	if frac := m[7]; frac != "" {
+		fields[dateFieldMs] = num((frac + "00")[:3], 0)
+	}
+	year, month, day := int(fields[dateFieldYear]), int(fields[dateFieldMonth])+1, int(fields[dateFieldDay])
+	hour, min, sec := fields[dateFieldHours], fields[dateFieldMinutes], fields[dateFieldSeconds]
+	if month > 12 || month < 1 || day < 1 || day > daysInMonth(year, month) || min > 59 || sec > 59 ||
+		hour > 24 || (hour == 24 && (min != 0 || sec != 0 || fields[dateFieldMs] != 0)) {
+		return time.Time{}, invalid
+	}
+
+	loc := time.UTC
+	switch zone := m[8]; {
+	case zone == "" && m[4] != "":
+		loc = time.Local
+	case zone != "" && zone != "Z":
+		h, min := num(zone[1:3], 0), num(zone[4:6], 0)
+		if h > 23 || min > 59 {
+			return time.Time{}, invalid
+		}
+		offset := h*60 + min
+		if zone[0] == '-' {
+			offset = -offset
+		}
+		fields[dateFieldMinutes] -= offset
+	}
+	t, inv := makeDate(fields, loc)
+	if inv {
+		return time.Time{}, invalid
+	}
+	return t, nil
+}
+
+// dateToPrimitive implements Date.prototype[Symbol.toPrimitive]: dates
+// convert to strings unless a number is explicitly asked for.
+func dateToPrimitive(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil {
+		return nil, fmt.Errorf("TypeError: Date.prototype[Symbol.toPrimitive] called on non-object")
+	}
+	hint := argAt(args, 0)
+	switch {
+	case hint.Type == runtime.TypeString && (hint.Str == "string" || hint.Str == "default"):
+		return runtime.OrdinaryToPrimitive(this, "string")
+	case hint.Type == runtime.TypeString && hint.Str == "number":
+		return runtime.OrdinaryToPrimitive(this, "number")
+	}
+	return nil, fmt.Errorf("TypeError: Invalid hint: %s", hint.ToString())
+}
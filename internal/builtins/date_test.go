@@ -0,0 +1,240 @@
+package builtins
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupDate() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createDateConstructor(rl.ObjectPrototype)
+	return rl
+}
+
+// callDate calls the Date.prototype method name on this.
+func (rl *Realm) callDate(t *testing.T, this *runtime.Value, name string, args ...*runtime.Value) (*runtime.Value, error) {
+	t.Helper()
+	fn := getCallable(rl.DatePrototype.Get(name))
+	if fn == nil {
+		t.Fatalf("Date.prototype.%s is not a function", name)
+	}
+	return fn(this, args)
+}
+
+func (rl *Realm) newDate(t *testing.T, args ...*runtime.Value) *runtime.Value {
+	t.Helper()
+	d, err := rl.dateConstruct(nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func nums(vals ...float64) []*runtime.Value {
+	out := make([]*runtime.Value, len(vals))
+	for i, v := range vals {
+		out[i] = runtime.NewNumber(v)
+	}
+	return out
+}
+
+func TestDateConstruct(t *testing.T) {
+	rl := setupDate()
+	tests := []struct {
+		args []*runtime.Value
+		want float64
+	}{
+		{nums(86400000), 86400000},
+		{nums(1.9), 1},
+		{nums(8.64e15), 8.64e15},
+		{nums(8.64e15 + 1), math.NaN()},
+		{nums(math.Inf(1)), math.NaN()},
+		{[]*runtime.Value{runtime.NewString("2020-01-02T03:04:05.678Z")}, 1577934245678},
+		{[]*runtime.Value{runtime.NewString("2020-01-02")}, 1577923200000},
+		{[]*runtime.Value{runtime.NewString("2020-01")}, 1577836800000},
+		{[]*runtime.Value{runtime.NewString("2020-01-02T03:04+01:00")}, 1577930640000},
+		{[]*runtime.Value{runtime.NewString("+020000-01-01T00:00:00Z")}, 568971820800000},
+		{[]*runtime.Value{runtime.NewString("2020-02-30")}, math.NaN()},
+		{[]*runtime.Value{runtime.NewString("-000000-01-01")}, math.NaN()},
+		{[]*runtime.Value{runtime.NewString("not a date")}, math.NaN()},
+	}
+	for _, tt := range tests {
+		got := getDateMs(rl.newDate(t, tt.args...))
+		if got != tt.want && !(math.IsNaN(got) && math.IsNaN(tt.want)) {
+			t.Errorf("new Date(%v): got %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	// A Date argument is copied.
+	orig := rl.newDate(t, nums(12345)...)
+	if got := getDateMs(rl.newDate(t, orig)); got != 12345 {
+		t.Errorf("new Date(date): got %v, want 12345", got)
+	}
+	// Components carry over and NaN makes the date invalid.
+	d := rl.newDate(t, nums(2020, 12, 1)...)
+	if y, _ := rl.callDate(t, d, "getFullYear"); y.Number != 2021 {
+		t.Errorf("new Date(2020, 12): year %v, want 2021", y.Number)
+	}
+	if got := getDateMs(rl.newDate(t, nums(2020, math.NaN())...)); !math.IsNaN(got) {
+		t.Errorf("new Date(2020, NaN): got %v, want NaN", got)
+	}
+}
+
+func TestDateParseFormats(t *testing.T) {
+	setupDate()
+	day := float64(time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local).UnixMilli())
+	at := float64(time.Date(2024, 3, 5, 10, 20, 30, 0, time.Local).UnixMilli())
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"Tue, 05 Mar 2024 10:20:30 GMT", 1709634030000},
+		{"Tue Mar 05 2024 10:20:30 GMT+0100", 1709630430000},
+		{"Tue Mar 05 2024 10:20:30", at},
+		{"Tue Mar 05 2024", day},
+		{"Tuesday, March 5, 2024", day},
+		{"March 5, 2024 10:20:30", at},
+		{"Mar 5 2024", day},
+		{"5 March 2024", day},
+		{"3/5/2024 10:20:30", at},
+		{"2024/03/05", day},
+		{"2024-03-05 10:20:30", at},
+	}
+	for _, tt := range tests {
+		got, err := dateParse(runtime.Undefined, []*runtime.Value{runtime.NewString(tt.s)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Number != tt.want {
+			t.Errorf("Date.parse(%q): got %v, want %v", tt.s, got.Number, tt.want)
+		}
+	}
+}
+
+func TestDateUTC(t *testing.T) {
+	setupDate()
+	tests := []struct {
+		args []*runtime.Value
+		want float64
+	}{
+		{nums(2020), 1577836800000},
+		{nums(2020, 1, 29, 12), 1582977600000},
+		{nums(99, 0), 915148800000},
+		{nums(2020, math.NaN()), math.NaN()},
+		{nil, math.NaN()},
+	}
+	for _, tt := range tests {
+		got, err := dateUTC(runtime.Undefined, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Number != tt.want && !(math.IsNaN(got.Number) && math.IsNaN(tt.want)) {
+			t.Errorf("Date.UTC(%v): got %v, want %v", tt.args, got.Number, tt.want)
+		}
+	}
+}
+
+func TestDateSetters(t *testing.T) {
+	rl := setupDate()
+	d := rl.newDate(t, nums(0)...)
+
+	r, err := rl.callDate(t, d, "setUTCHours", nums(25, 30)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Number != 91800000 {
+		t.Errorf("setUTCHours(25, 30): got %v, want 91800000", r.Number)
+	}
+	if m, _ := rl.callDate(t, d, "getUTCMinutes"); m.Number != 30 {
+		t.Errorf("getUTCMinutes: got %v, want 30", m.Number)
+	}
+
+	r, _ = rl.callDate(t, d, "setUTCMonth", nums(13)...)
+	if iso, _ := rl.callDate(t, d, "toISOString"); iso.Str != "1971-02-02T01:30:00.000Z" {
+		t.Errorf("setUTCMonth(13): got %s", iso.Str)
+	}
+
+	// NaN invalidates the date; only the year setters revive it.
+	rl.callDate(t, d, "setUTCDate", nums(math.NaN())...)
+	if ms := getDateMs(d); !math.IsNaN(ms) {
+		t.Errorf("setUTCDate(NaN): got %v, want NaN", ms)
+	}
+	if r, _ = rl.callDate(t, d, "setUTCMinutes", nums(5)...); !math.IsNaN(r.Number) {
+		t.Errorf("setUTCMinutes on invalid date: got %v, want NaN", r.Number)
+	}
+	r, _ = rl.callDate(t, d, "setUTCFullYear", nums(2000)...)
+	if r.Number != 946684800000 {
+		t.Errorf("setUTCFullYear on invalid date: got %v, want 946684800000", r.Number)
+	}
+
+	if r, _ = rl.callDate(t, d, "setTime", nums(9e15)...); !math.IsNaN(r.Number) {
+		t.Errorf("setTime(9e15): got %v, want NaN", r.Number)
+	}
+}
+
+func TestDateReceiver(t *testing.T) {
+	rl := setupDate()
+	plain := runtime.NewObject(runtime.NewOrdinaryObject(rl.ObjectPrototype))
+	for _, name := range []string{"getTime", "setTime", "getUTCDay", "setUTCFullYear", "toISOString", "valueOf"} {
+		_, err := rl.callDate(t, plain, name, nums(1)...)
+		if err == nil || !strings.HasPrefix(err.Error(), "TypeError:") {
+			t.Errorf("Date.prototype.%s on a plain object: expected TypeError, got %v", name, err)
+		}
+	}
+}
+
+func TestDateToJSON(t *testing.T) {
+	rl := setupDate()
+	r, err := rl.callDate(t, rl.newDate(t, nums(0)...), "toJSON")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Str != "1970-01-01T00:00:00.000Z" {
+		t.Errorf("toJSON: got %q", r.Str)
+	}
+	r, err = rl.callDate(t, rl.newDate(t, nums(math.NaN())...), "toJSON")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Type != runtime.TypeNull {
+		t.Errorf("toJSON of an invalid date: got %v, want null", r)
+	}
+
+	// toJSON is generic.
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("toISOString", runtime.NewObject(rl.newFuncObject("toISOString", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewString("custom"), nil
+	})))
+	r, err = rl.callDate(t, runtime.NewObject(obj), "toJSON")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Str != "custom" {
+		t.Errorf("toJSON on a plain object: got %q, want %q", r.Str, "custom")
+	}
+
+	if _, err := rl.callDate(t, rl.newDate(t, nums(math.NaN())...), "toISOString"); err == nil {
+		t.Error("toISOString of an invalid date should throw a RangeError")
+	}
+	r, _ = rl.callDate(t, rl.newDate(t, nums(-62198755200000)...), "toISOString")
+	if r.Str != "-000001-01-01T00:00:00.000Z" {
+		t.Errorf("toISOString of year -1: got %q", r.Str)
+	}
+}
+
+func TestDateStringConversion(t *testing.T) {
+	rl := setupDate()
+	d := rl.newDate(t, nums(0)...)
+	want, _ := rl.callDate(t, d, "toString")
+	if got, err := stringConstructorCall(runtime.Undefined, []*runtime.Value{d}); err != nil || got.Str != want.Str {
+		t.Errorf("String(date): got %v (%v), want %q", got, err, want.Str)
+	}
+	if got, _ := objectProtoToString(d, nil); got.Str != "[object Date]" {
+		t.Errorf("Object.prototype.toString(date): got %q", got.Str)
+	}
+}
@@ -0,0 +1,109 @@
+package builtins
+
+import (
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createErrorConstructor(objProto *runtime.Object) *runtime.Object {
+	proto := runtime.NewOrdinaryObject(objProto)
+	proto.OType = runtime.ObjTypeError
+	rl.ErrorPrototype = proto
+	rl.errorPrototypes = map[string]*runtime.Object{"Error": proto}
+
+	setDataProp(proto, "name", runtime.NewString("Error"), true, false, true)
+	setDataProp(proto, "message", runtime.NewString(""), true, false, true)
+	rl.setMethod(proto, "toString", 0, errorToString)
+
+	ctor := rl.newFuncObject("Error", 1, rl.errorConstructorCall)
+	ctor.Constructor = rl.errorConstructorCall
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor
+}
+
+func (rl *Realm) createErrorSubtype(name string, objProto *runtime.Object, errProto *runtime.Object) *runtime.Object {
+	proto := runtime.NewOrdinaryObject(errProto)
+	proto.OType = runtime.ObjTypeError
+	rl.errorPrototypes[name] = proto
+	setDataProp(proto, "name", runtime.NewString(name), true, false, true)
+	setDataProp(proto, "message", runtime.NewString(""), true, false, true)
+
+	ctor := rl.newFuncObject(name, 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return rl.makeErrorValue(name, args, proto), nil
+	})
+	ctor.Constructor = ctor.Callable
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor
+}
+
+func (rl *Realm) errorConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.makeErrorValue("Error", args, rl.ErrorPrototype), nil
+}
+
+func (rl *Realm) makeErrorValue(name string, args []*runtime.Value, proto *runtime.Object) *runtime.Value {
+	obj := &runtime.Object{
+		OType:      runtime.ObjTypeError,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  proto,
+	}
+	msg := ""
+	if len(args) > 0 && args[0].Type != runtime.TypeUndefined {
+		msg = args[0].ToString()
+	}
+	obj.Set("name", runtime.NewString(name))
+	obj.Set("message", runtime.NewString(msg))
+	header := name
+	if msg != "" {
+		header += ": " + msg
+	}
+	obj.Set("stack", runtime.NewString(rl.Agent.ErrorStack(header)))
+	return runtime.NewObject(obj)
+}
+
+func errorToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewString("Error"), nil
+	}
+	name := obj.Get("name")
+	nameStr := "Error"
+	if name != runtime.Undefined {
+		nameStr = name.ToString()
+	}
+	msg := obj.Get("message")
+	msgStr := ""
+	if msg != runtime.Undefined {
+		msgStr = msg.ToString()
+	}
+	if nameStr == "" {
+		return runtime.NewString(msgStr), nil
+	}
+	if msgStr == "" {
+		return runtime.NewString(nameStr), nil
+	}
+	return runtime.NewString(nameStr + ": " + msgStr), nil
+}
+
+// errorToValue converts an error returned by a callable into the JS value it
+// throws: the carried value for JS exceptions, or a new error object for Go
+// errors of the form "TypeError: message".
+func (rl *Realm) errorToValue(err error) *runtime.Value {
+	if val, ok := runtime.ThrownValue(err); ok {
+		return val
+	}
+	msg := err.Error()
+	name := "Error"
+	if i := strings.Index(msg, ": "); i > 0 {
+		if _, ok := rl.errorPrototypes[msg[:i]]; ok {
+			name, msg = msg[:i], msg[i+2:]
+		}
+	}
+	return rl.makeErrorValue(name, []*runtime.Value{runtime.NewString(msg)}, rl.errorPrototypes[name])
+}
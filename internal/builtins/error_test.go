@@ -3,17 +3,19 @@ package builtins
 import (
 	"testing"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
-func setupError() {
-	createObjectConstructor()
-	createErrorConstructor(ObjectPrototype)
+func setupError() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createErrorConstructor(rl.ObjectPrototype)
+	return rl
 }
 
 func TestErrorConstructor(t *testing.T) {
-	setupError()
-	result, err := errorConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewString("something failed")})
+	rl := setupError()
+	result, err := rl.errorConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewString("something failed")})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -30,11 +32,11 @@ func TestErrorConstructor(t *testing.T) {
 }
 
 func TestErrorToString(t *testing.T) {
-	setupError()
+	rl := setupError()
 	obj := &runtime.Object{
 		OType:      runtime.ObjTypeError,
 		Properties: make(map[string]*runtime.Property),
-		Prototype:  ErrorPrototype,
+		Prototype:  rl.ErrorPrototype,
 	}
 	obj.Set("name", runtime.NewString("TypeError"))
 	obj.Set("message", runtime.NewString("not a function"))
@@ -46,8 +48,8 @@ func TestErrorToString(t *testing.T) {
 }
 
 func TestErrorSubtypes(t *testing.T) {
-	setupError()
-	typeErr := createErrorSubtype("TypeError", ObjectPrototype, ErrorPrototype)
+	rl := setupError()
+	typeErr := rl.createErrorSubtype("TypeError", rl.ObjectPrototype, rl.ErrorPrototype)
 	result, err := typeErr.Callable(runtime.Undefined, []*runtime.Value{runtime.NewString("bad type")})
 	if err != nil {
 		t.Fatal(err)
@@ -3,22 +3,20 @@ package builtins
 import (
 	"fmt"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
-var FunctionPrototype *runtime.Object
-
-func createFunctionConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+func (rl *Realm) createFunctionConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
 	proto := runtime.NewOrdinaryObject(objProto)
 	proto.OType = runtime.ObjTypeFunction
-	FunctionPrototype = proto
+	rl.FunctionPrototype = proto
 
-	setMethod(proto, "call", 1, functionCall)
-	setMethod(proto, "apply", 2, functionApply)
-	setMethod(proto, "bind", 1, functionBind)
-	setMethod(proto, "toString", 0, functionToString)
+	rl.setMethod(proto, "call", 1, functionCall)
+	rl.setMethod(proto, "apply", 2, functionApply)
+	rl.setMethod(proto, "bind", 1, rl.functionBind)
+	rl.setMethod(proto, "toString", 0, functionToString)
 
-	ctor := newFuncObject("Function", 1, functionConstructorCall)
+	ctor := rl.newFuncObject("Function", 1, functionConstructorCall)
 	ctor.Constructor = functionConstructorCall
 	ctor.Prototype = proto
 
@@ -55,7 +53,7 @@ func functionApply(this *runtime.Value, args []*runtime.Value) (*runtime.Value,
 	if len(args) > 1 && args[1].Type == runtime.TypeObject && args[1].Object != nil {
 		obj := args[1].Object
 		if obj.OType == runtime.ObjTypeArray {
-			callArgs = obj.ArrayData
+			callArgs = obj.ArrayValues()
 		} else {
 			// Handle array-like objects (e.g., arguments object)
 			lengthVal := obj.Get("length")
@@ -76,7 +74,7 @@ func functionApply(this *runtime.Value, args []*runtime.Value) (*runtime.Value,
 	return fn(thisArg, callArgs)
 }
 
-func functionBind(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) functionBind(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	fn := getCallable(this)
 	if fn == nil {
 		return nil, fmt.Errorf("TypeError: not a function")
@@ -92,7 +90,18 @@ func functionBind(this *runtime.Value, args []*runtime.Value) (*runtime.Value, e
 		allArgs = append(allArgs, callArgs...)
 		return fn(thisArg, allArgs)
 	}
-	obj := newFuncObject("bound ", 0, boundFn)
+	obj := rl.newFuncObject("bound ", 0, boundFn)
+	// A bound constructor constructs its target with the bound arguments.
+	// new looks through it to the target for the instance's prototype.
+	if target := this.Object; target.Constructor != nil {
+		obj.Constructor = func(callThis *runtime.Value, callArgs []*runtime.Value) (*runtime.Value, error) {
+			allArgs := make([]*runtime.Value, 0, len(boundArgs)+len(callArgs))
+			allArgs = append(allArgs, boundArgs...)
+			allArgs = append(allArgs, callArgs...)
+			return target.Constructor(callThis, allArgs)
+		}
+		obj.Internal = map[string]interface{}{"boundTarget": target}
+	}
 	return runtime.NewObject(obj), nil
 }
 
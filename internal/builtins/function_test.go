@@ -0,0 +1,110 @@
+package builtins
+
+import (
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestFunctionCall(t *testing.T) {
+	rl := registerTestRealm()
+	fn := rl.newFuncObject("add", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number + args[1].Number), nil
+	})
+	fnVal := runtime.NewObject(fn)
+
+	result, err := functionCall(fnVal, []*runtime.Value{runtime.Undefined, runtime.NewNumber(3), runtime.NewNumber(4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Number != 7 {
+		t.Errorf("call: expected 7, got %v", result.Number)
+	}
+}
+
+func TestFunctionApply(t *testing.T) {
+	rl := setupArray()
+	fn := rl.newFuncObject("sum", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		total := 0.0
+		for _, a := range args {
+			total += a.Number
+		}
+		return runtime.NewNumber(total), nil
+	})
+	fnVal := runtime.NewObject(fn)
+	argsArr := rl.newArray([]*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(2), runtime.NewNumber(3)})
+
+	result, err := functionApply(fnVal, []*runtime.Value{runtime.Undefined, runtime.NewObject(argsArr)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Number != 6 {
+		t.Errorf("apply: expected 6, got %v", result.Number)
+	}
+}
+
+func TestFunctionBind(t *testing.T) {
+	rl := registerTestRealm()
+	fn := rl.newFuncObject("multiply", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number * args[1].Number), nil
+	})
+	fnVal := runtime.NewObject(fn)
+
+	bound, err := rl.functionBind(fnVal, []*runtime.Value{runtime.Undefined, runtime.NewNumber(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	boundFn := getCallable(bound)
+	result, err := boundFn(runtime.Undefined, []*runtime.Value{runtime.NewNumber(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Number != 10 {
+		t.Errorf("bind: expected 10, got %v", result.Number)
+	}
+}
+
+func TestFunctionBindConstructor(t *testing.T) {
+	rl := registerTestRealm()
+	plain := rl.newFuncObject("plain", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.Undefined, nil
+	})
+	bound, err := rl.functionBind(runtime.NewObject(plain), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound.Object.Constructor != nil {
+		t.Error("binding a non-constructor should not give a constructor")
+	}
+
+	ctor := rl.newFuncObject("Pair", 2, plain.Callable)
+	ctor.Constructor = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return rl.createValueArray(args), nil
+	}
+	bound, err = rl.functionBind(runtime.NewObject(ctor), []*runtime.Value{runtime.Undefined, runtime.NewNumber(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound.Object.Constructor == nil || bound.Object.Internal["boundTarget"] != ctor {
+		t.Fatal("bound constructor should construct its target")
+	}
+	result, err := bound.Object.Constructor(runtime.Undefined, []*runtime.Value{runtime.NewNumber(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(result.Object.ArrayData); n != 2 || result.Object.ArrayData[0].Number != 1 || result.Object.ArrayData[1].Number != 2 {
+		t.Errorf("bound constructor: unexpected arguments %v", result.Object.ArrayData)
+	}
+}
+
+func TestFunctionToString(t *testing.T) {
+	rl := registerTestRealm()
+	fn := rl.newFuncObject("myFunc", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.Undefined, nil
+	})
+	result, _ := functionToString(runtime.NewObject(fn), nil)
+	expected := "function myFunc() { [native code] }"
+	if result.Str != expected {
+		t.Errorf("toString: expected %q, got %q", expected, result.Str)
+	}
+}
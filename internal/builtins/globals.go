@@ -0,0 +1,259 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) registerGlobalFunctions(env *runtime.Environment) {
+	rl.declareFunc(env, "parseInt", 2, globalParseInt)
+	rl.declareFunc(env, "parseFloat", 1, globalParseFloat)
+	rl.declareFunc(env, "isNaN", 1, globalIsNaN)
+	rl.declareFunc(env, "isFinite", 1, globalIsFinite)
+	rl.declareFunc(env, "encodeURI", 1, globalEncodeURI)
+	rl.declareFunc(env, "decodeURI", 1, globalDecodeURI)
+	rl.declareFunc(env, "encodeURIComponent", 1, globalEncodeURIComponent)
+	rl.declareFunc(env, "decodeURIComponent", 1, globalDecodeURIComponent)
+	rl.declareFunc(env, "eval", 1, globalEval)
+	rl.declareFunc(env, "escape", 1, globalEscape)
+	rl.declareFunc(env, "unescape", 1, globalUnescape)
+
+	env.Declare("undefined", "var", runtime.Undefined)
+	env.Declare("NaN", "var", runtime.NaN)
+	env.Declare("Infinity", "var", runtime.PosInf)
+}
+
+func (rl *Realm) declareFunc(env *runtime.Environment, name string, length int, fn runtime.CallableFunc) {
+	obj := rl.newFuncObject(name, length, fn)
+	env.Declare(name, "var", runtime.NewObject(obj))
+}
+
+func globalParseInt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	input, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimLeftFunc(input, runtime.IsStrWhiteSpace)
+	sign := 1.0
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	r, err := toNumberErr(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	radix := int(int32(numberToUint32(r)))
+	stripPrefix := true
+	if radix != 0 {
+		if radix < 2 || radix > 36 {
+			return runtime.NaN, nil
+		}
+		stripPrefix = radix == 16
+	} else {
+		radix = 10
+	}
+	if stripPrefix && len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s, radix = s[2:], 16
+	}
+	end := 0
+	for end < len(s) && digitValue(s[end]) < radix {
+		end++
+	}
+	if end == 0 {
+		return runtime.NaN, nil
+	}
+	// Multiplying keeps the sign of zero: parseInt("-0") is -0.
+	return runtime.NewNumber(sign * parseIntDigits(s[:end], radix)), nil
+}
+
+// digitValue returns the value of c as a digit in radixes up to 36, or 36
+// if c is not a digit at all.
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return 36
+}
+
+// parseIntDigits converts digits, all valid in radix, to the nearest
+// double, however many there are.
+func parseIntDigits(digits string, radix int) float64 {
+	if radix == 10 {
+		f, _ := strconv.ParseFloat(digits, 64) // ±Inf on overflow
+		return f
+	}
+	n, _ := new(big.Int).SetString(digits, radix)
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f
+}
+
+func globalParseFloat(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	input, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimLeftFunc(input, runtime.IsStrWhiteSpace)
+	f, _ := runtime.ParseDecimalPrefix(s)
+	return runtime.NewNumber(f), nil
+}
+
+func globalIsNaN(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	n := toNumber(argAt(args, 0))
+	return runtime.NewBool(math.IsNaN(n)), nil
+}
+
+func globalIsFinite(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	n := toNumber(argAt(args, 0))
+	return runtime.NewBool(!math.IsNaN(n) && !math.IsInf(n, 0)), nil
+}
+
+func globalEncodeURI(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := argAt(args, 0).ToString()
+	// encodeURI does not encode: ; , / ? : @ & = + $ - _ . ! ~ * ' ( ) # and alphanumeric
+	result, err := encodeURIHelper(s, ";,/?:@&=+$-_.!~*'()#")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(result), nil
+}
+
+func globalDecodeURI(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := argAt(args, 0).ToString()
+	decoded, err := url.PathUnescape(s)
+	if err != nil {
+		return nil, fmt.Errorf("URIError: URI malformed")
+	}
+	return runtime.NewString(decoded), nil
+}
+
+func globalEncodeURIComponent(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := argAt(args, 0).ToString()
+	result, err := encodeURIHelper(s, "-_.!~*'()")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(result), nil
+}
+
+func globalDecodeURIComponent(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := argAt(args, 0).ToString()
+	decoded, err := url.PathUnescape(s)
+	if err != nil {
+		return nil, fmt.Errorf("URIError: URI malformed")
+	}
+	return runtime.NewString(decoded), nil
+}
+
+func globalEval(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return nil, fmt.Errorf("EvalError: eval is not supported")
+}
+
+// encodeURIHelper percent-encodes the UTF-8 bytes of every character of s
+// but ASCII letters, digits and those in safe. A lone surrogate has no
+// UTF-8 encoding and throws a URIError.
+func encodeURIHelper(s string, safe string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if _, ok := runtime.LoneSurrogate(s, i); ok {
+			return "", fmt.Errorf("URIError: URI malformed")
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune(safe, r) {
+			sb.WriteRune(r)
+		} else {
+			for j := i; j < i+size; j++ {
+				fmt.Fprintf(&sb, "%%%02X", s[j])
+			}
+		}
+		i += size
+	}
+	return sb.String(), nil
+}
+
+// escape encodes a string using the legacy escape encoding.
+// Characters not encoded: A-Z a-z 0-9 @ * _ + - . /
+// IMPORTANT: escape operates on UTF-16 code units, not Unicode code points.
+// Surrogate pairs are encoded as two separate %uXXXX sequences.
+func globalEscape(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	// Convert to UTF-16 code units to handle surrogate pairs correctly
+	utf16Units := runtime.UTF16(s)
+	var sb strings.Builder
+	for _, cu := range utf16Units {
+		r := rune(cu)
+		if isEscapeSafe(r) {
+			sb.WriteRune(r)
+		} else if cu <= 0xFF {
+			sb.WriteString(fmt.Sprintf("%%%02X", cu))
+		} else {
+			sb.WriteString(fmt.Sprintf("%%u%04X", cu))
+		}
+	}
+	return runtime.NewString(sb.String()), nil
+}
+
+func isEscapeSafe(r rune) bool {
+	if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+		return true
+	}
+	switch r {
+	case '@', '*', '_', '+', '-', '.', '/':
+		return true
+	}
+	return false
+}
+
+// unescape decodes a string produced by escape.
+// Uses UTF-16 code units to match JS behavior.
+func globalUnescape(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	// Collect as UTF-16 code units, then convert back to string
+	var codeUnits []uint16
+	i := 0
+	for i < len(s) {
+		if s[i] == '%' {
+			if i+5 < len(s) && s[i+1] == 'u' {
+				hex := s[i+2 : i+6]
+				n, parseErr := strconv.ParseInt(hex, 16, 32)
+				if parseErr == nil {
+					codeUnits = append(codeUnits, uint16(n))
+					i += 6
+					continue
+				}
+			}
+			if i+2 < len(s) {
+				hex := s[i+1 : i+3]
+				n, parseErr := strconv.ParseInt(hex, 16, 32)
+				if parseErr == nil {
+					codeUnits = append(codeUnits, uint16(n))
+					i += 3
+					continue
+				}
+			}
+		}
+		codeUnits = append(codeUnits, uint16(s[i]))
+		i++
+	}
+	return runtime.NewString(runtime.FromUTF16(codeUnits)), nil
+}
@@ -2,9 +2,10 @@ package builtins
 
 import (
 	"math"
+	"strings"
 	"testing"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
 func TestParseInt(t *testing.T) {
@@ -19,6 +20,13 @@ func TestParseInt(t *testing.T) {
 		{"10", 8, 8},
 		{"  42  ", 10, 42},
 		{"-5", 10, -5},
+		{"  -0x1F", 10, -31},
+		{"0x1F", 16, 31},
+		{"\u00a0\ufeff12px", 10, 12},
+		{"123456789012345678901234567890", 10, 1.2345678901234568e29},
+		{"ffffffffffffffffff", 16, 4722366482869645213696},
+		{"11", 4294967298, 3}, // radix is ToInt32: 2
+		{"z", 36, 35},
 	}
 	for _, tt := range tests {
 		args := []*runtime.Value{runtime.NewString(tt.input)}
@@ -37,6 +45,18 @@ func TestParseIntNaN(t *testing.T) {
 	if !math.IsNaN(result.Number) {
 		t.Errorf("parseInt('abc'): expected NaN, got %v", result.Number)
 	}
+	result, _ = globalParseInt(runtime.Undefined, []*runtime.Value{runtime.NewString("0x1F"), runtime.NewNumber(10)})
+	if result.Number != 0 {
+		t.Errorf("parseInt('0x1F', 10): expected 0, got %v", result.Number)
+	}
+	result, _ = globalParseInt(runtime.Undefined, []*runtime.Value{runtime.NewString("-0")})
+	if result.Number != 0 || !math.Signbit(result.Number) {
+		t.Errorf("parseInt('-0'): expected -0, got %v", result.Number)
+	}
+	result, _ = globalParseInt(runtime.Undefined, []*runtime.Value{runtime.NewString("1"), runtime.NewNumber(37)})
+	if !math.IsNaN(result.Number) {
+		t.Errorf("parseInt('1', 37): expected NaN, got %v", result.Number)
+	}
 }
 
 func TestParseFloat(t *testing.T) {
@@ -47,6 +67,12 @@ func TestParseFloat(t *testing.T) {
 		{"3.14", 3.14},
 		{"  42  ", 42},
 		{"Infinity", math.Inf(1)},
+		{"-Infinityx", math.Inf(-1)},
+		{"-.5e-3abc", -0.0005},
+		{"1.e", 1},
+		{"2e+", 2},
+		{"1e400", math.Inf(1)},
+		{"\ufeff 3.5", 3.5},
 	}
 	for _, tt := range tests {
 		result, _ := globalParseFloat(runtime.Undefined, []*runtime.Value{runtime.NewString(tt.input)})
@@ -95,6 +121,28 @@ func TestEncodeDecodeURI(t *testing.T) {
 	}
 }
 
+func TestEncodeURIEncodesUTF8(t *testing.T) {
+	tests := []struct{ fn, in, want string }{
+		{"encodeURIComponent", "a&b=c/d", "a%26b%3Dc%2Fd"},
+		{"encodeURI", "/a b?c=d#e", "/a%20b?c=d#e"},
+		{"encodeURIComponent", "é😀", "%C3%A9%F0%9F%98%80"},
+	}
+	fns := map[string]runtime.CallableFunc{"encodeURI": globalEncodeURI, "encodeURIComponent": globalEncodeURIComponent}
+	for _, tt := range tests {
+		got, err := fns[tt.fn](runtime.Undefined, []*runtime.Value{runtime.NewString(tt.in)})
+		if err != nil || got.Str != tt.want {
+			t.Errorf("%s(%q) = %v, %v; want %q", tt.fn, tt.in, got, err, tt.want)
+		}
+	}
+	// A lone surrogate has no UTF-8 encoding.
+	for _, fn := range fns {
+		_, err := fn(runtime.Undefined, []*runtime.Value{runtime.NewString("a\xed\xa0\xbd")})
+		if err == nil || !strings.HasPrefix(err.Error(), "URIError") {
+			t.Errorf("lone surrogate: got error %v, want a URIError", err)
+		}
+	}
+}
+
 func TestEvalThrows(t *testing.T) {
 	_, err := globalEval(runtime.Undefined, []*runtime.Value{runtime.NewString("1+1")})
 	if err == nil {
@@ -2,59 +2,93 @@ package builtins
 
 import (
 	"fmt"
+	"math"
+	"sync"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
-func newFuncObject(name string, length int, fn runtime.CallableFunc) *runtime.Object {
-	obj := &runtime.Object{
-		OType:      runtime.ObjTypeFunction,
-		Properties: make(map[string]*runtime.Property),
-		Callable:   fn,
-		Prototype:  FunctionPrototype, // may be nil during early init, fixed by SetFunctionPrototype
+// builtinFunc lays out a built-in function object together with its name
+// and length properties, the value referring to it and the property that
+// holds it as a method, so that creating one takes a single allocation.
+type builtinFunc struct {
+	obj          runtime.Object
+	name, length runtime.Property
+	val          runtime.Value
+	method       runtime.Property
+}
+
+// funcNames holds the name values of built-in functions by name. String
+// values are immutable, so the functions of every realm share them.
+var funcNames sync.Map
+
+func funcName(name string) *runtime.Value {
+	if v, ok := funcNames.Load(name); ok {
+		return v.(*runtime.Value)
 	}
-	obj.DefineProperty("name", &runtime.Property{
-		Value:        runtime.NewString(name),
-		Writable:     false,
-		Enumerable:   false,
-		Configurable: true,
-	})
-	obj.DefineProperty("length", &runtime.Property{
-		Value:        runtime.NewNumber(float64(length)),
-		Writable:     false,
-		Enumerable:   false,
-		Configurable: true,
-	})
-	return obj
+	v, _ := funcNames.LoadOrStore(name, runtime.NewString(name))
+	return v.(*runtime.Value)
+}
+
+func (rl *Realm) newFuncObject(name string, length int, fn runtime.CallableFunc) *runtime.Object {
+	return &rl.newBuiltinFunc(name, length, fn).obj
+}
+
+func (rl *Realm) newBuiltinFunc(name string, length int, fn runtime.CallableFunc) *builtinFunc {
+	f := &builtinFunc{
+		obj: runtime.Object{
+			OType:      runtime.ObjTypeFunction,
+			Properties: make(map[string]*runtime.Property, 2),
+			Callable:   fn,
+			Prototype:  rl.FunctionPrototype, // may be nil during early init, fixed by SetFunctionPrototype
+		},
+		name: runtime.Property{
+			Value:        funcName(name),
+			Writable:     false,
+			Enumerable:   false,
+			Configurable: true,
+		},
+		length: runtime.Property{
+			Value:        runtime.NewNumber(float64(length)),
+			Writable:     false,
+			Enumerable:   false,
+			Configurable: true,
+		},
+	}
+	f.obj.DefineProperty("name", &f.name)
+	f.obj.DefineProperty("length", &f.length)
+	f.val = runtime.Value{Type: runtime.TypeObject, Object: &f.obj}
+	return f
 }
 
 // setFuncPrototypeRecursive walks an object's own properties and sets Prototype
 // on any function objects that have nil Prototype. Called after FunctionPrototype is created.
-func setFuncPrototypeRecursive(obj *runtime.Object) {
+func (rl *Realm) setFuncPrototypeRecursive(obj *runtime.Object) {
 	if obj == nil {
 		return
 	}
 	if obj.OType == runtime.ObjTypeFunction && obj.Prototype == nil {
-		obj.Prototype = FunctionPrototype
+		obj.Prototype = rl.FunctionPrototype
 	}
 	for _, p := range obj.Properties {
 		if p.Value != nil && p.Value.Type == runtime.TypeObject && p.Value.Object != nil {
 			inner := p.Value.Object
 			if inner.OType == runtime.ObjTypeFunction && inner.Prototype == nil {
-				inner.Prototype = FunctionPrototype
+				inner.Prototype = rl.FunctionPrototype
 			}
 		}
 	}
 }
 
-func setMethod(obj *runtime.Object, name string, length int, fn runtime.CallableFunc) {
-	funcObj := newFuncObject(name, length, fn)
-	obj.DefineProperty(name, &runtime.Property{
-		Value:        runtime.NewObject(funcObj),
+func (rl *Realm) setMethod(obj *runtime.Object, name string, length int, fn runtime.CallableFunc) {
+	f := rl.newBuiltinFunc(name, length, fn)
+	f.method = runtime.Property{
+		Value:        &f.val,
 		Writable:     true,
 		Enumerable:   false,
 		Configurable: true,
-	})
+	}
+	obj.DefineProperty(name, &f.method)
 }
 
 func setDataProp(obj *runtime.Object, name string, val *runtime.Value, writable, enumerable, configurable bool) {
@@ -66,6 +100,18 @@ func setDataProp(obj *runtime.Object, name string, val *runtime.Value, writable,
 	})
 }
 
+// setGetter defines a non-enumerable accessor property with only a getter.
+func (rl *Realm) setGetter(obj *runtime.Object, name string, fn func(this *runtime.Value) (*runtime.Value, error)) {
+	getter := rl.newFuncObject("get "+name, 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return fn(this)
+	})
+	obj.DefineProperty(name, &runtime.Property{
+		Getter:       runtime.NewObject(getter),
+		IsAccessor:   true,
+		Configurable: true,
+	})
+}
+
 func setConstant(obj *runtime.Object, name string, val *runtime.Value) {
 	setDataProp(obj, name, val, false, false, false)
 }
@@ -164,7 +210,7 @@ func toNumberErr(v *runtime.Value) (float64, error) {
 	case runtime.TypeNumber:
 		return v.Number, nil
 	case runtime.TypeString:
-		return parseStringToNumber(v.Str), nil
+		return runtime.StringToNumber(v.Str), nil
 	case runtime.TypeSymbol:
 		return 0, fmt.Errorf("TypeError: Cannot convert a Symbol value to a number")
 	case runtime.TypeObject:
@@ -230,17 +276,22 @@ func toInteger(v *runtime.Value) float64 {
 }
 
 func toInt32(v *runtime.Value) int32 {
-	n := toNumber(v)
-	if isNaN(n) || isInf(n, 0) || n == 0 {
-		return 0
-	}
-	return int32(int64(n))
+	return int32(numberToUint32(toNumber(v)))
 }
 
 func toUint32(v *runtime.Value) uint32 {
-	n := toNumber(v)
-	if isNaN(n) || isInf(n, 0) || n == 0 {
+	return numberToUint32(toNumber(v))
+}
+
+// numberToUint32 is ToUint32 on a number: the integer part modulo 2^32, so
+// large values wrap instead of overflowing.
+func numberToUint32(n float64) uint32 {
+	if isNaN(n) || isInf(n, 0) {
 		return 0
 	}
-	return uint32(int64(n))
+	n = math.Mod(math.Trunc(n), 1<<32)
+	if n < 0 {
+		n += 1 << 32
+	}
+	return uint32(n)
 }
@@ -0,0 +1,350 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// InspectDepth is how deep Inspect shows nested objects, as Node's
+// util.inspect does by default; deeper ones print as [Object] or [Array].
+const InspectDepth = 2
+
+// Inspect formats a value for display, the way console.log prints objects
+// and the REPL prints results: strings quoted, arrays, maps, sets and
+// objects with their elements and own enumerable properties, promises with
+// their state, functions by kind and name, regular expressions as literals,
+// dates in ISO format and errors by their stack.
+func Inspect(v *runtime.Value) string {
+	return inspectWithDepth(v, InspectDepth)
+}
+
+// inspectWithDepth is Inspect showing objects nested up to depth levels; a
+// negative depth shows all of them.
+func inspectWithDepth(v *runtime.Value, depth int) string {
+	in := &inspector{maxDepth: depth}
+	var sb strings.Builder
+	in.value(&sb, v, 0)
+	return sb.String()
+}
+
+type inspector struct {
+	maxDepth int
+	seen     []*runtime.Object // objects being printed, to spot cycles
+}
+
+func (in *inspector) value(sb *strings.Builder, v *runtime.Value, depth int) {
+	if v == nil {
+		sb.WriteString("undefined")
+		return
+	}
+	switch v.Type {
+	case runtime.TypeString:
+		sb.WriteString(quoteJSString(v.Str))
+		return
+	case runtime.TypeNumber:
+		if v.Number == 0 && math.Signbit(v.Number) {
+			sb.WriteString("-0")
+			return
+		}
+		sb.WriteString(v.ToString())
+		return
+	case runtime.TypeObject:
+		if v.Object != nil {
+			break
+		}
+		fallthrough
+	default:
+		sb.WriteString(v.ToString())
+		return
+	}
+	obj := v.Object
+	for _, s := range in.seen {
+		if s == obj {
+			sb.WriteString("[Circular]")
+			return
+		}
+	}
+	if obj.Callable != nil {
+		sb.WriteString(functionLabel(obj))
+		return
+	}
+	switch obj.OType {
+	case runtime.ObjTypeError:
+		if stack := obj.Get("stack"); stack != nil && stack.Type == runtime.TypeString {
+			sb.WriteString(stack.Str)
+		} else {
+			sb.WriteString(v.ToString())
+		}
+		return
+	case runtime.ObjTypeRegExp:
+		// RegExp.prototype is no regular expression and prints as an object.
+		if pattern, ok := obj.Internal["pattern"].(string); ok {
+			if pattern == "" {
+				pattern = "(?:)"
+			}
+			flags, _ := obj.Internal["flags"].(string)
+			sb.WriteString("/" + pattern + "/" + flags)
+			return
+		}
+	case runtime.ObjTypeDate:
+		if t, invalid := getDateValue(v); invalid {
+			sb.WriteString("Invalid Date")
+		} else {
+			sb.WriteString(formatDateISO(t))
+		}
+		return
+	}
+
+	open, close := "{", "}"
+	prefix := ""
+	// A wrapper object shows its primitive, as [Number: 5], and then its
+	// properties other than the code units and length of a string.
+	prim, isWrapper := runtime.PrimitiveData(obj)
+	switch obj.OType {
+	case runtime.ObjTypeArray:
+		open, close = "[", "]"
+	case runtime.ObjTypeMap:
+		prefix = "Map(" + strconv.Itoa(len(getMapEntries(obj))) + ") "
+	case runtime.ObjTypeSet:
+		prefix = "Set(" + strconv.Itoa(len(getSetItems(obj))) + ") "
+	case runtime.ObjTypeArguments:
+		prefix = "[Arguments] "
+		open, close = "[", "]"
+	default:
+		if isWrapper {
+			var label strings.Builder
+			in.value(&label, prim, depth)
+			prefix = "[" + wrapperName(prim) + ": " + label.String() + "] "
+		} else if name := constructorName(obj); name != "Object" {
+			prefix = name + " "
+		}
+	}
+	if in.maxDepth >= 0 && depth > in.maxDepth {
+		if open == "[" {
+			sb.WriteString("[Array]")
+		} else if prefix != "" {
+			sb.WriteString("[" + strings.TrimSpace(prefix) + "]")
+		} else {
+			sb.WriteString("[Object]")
+		}
+		return
+	}
+	in.seen = append(in.seen, obj)
+	defer func() { in.seen = in.seen[:len(in.seen)-1] }()
+
+	// The elements are listed before the properties; the length of arrays
+	// and the size maps and sets keep as a property are left out.
+	parts := in.elements(obj, depth)
+	for _, key := range runtime.OwnKeys(obj) {
+		prop := obj.Properties[key]
+		if prop == nil || !prop.Enumerable {
+			continue
+		}
+		if (open == "[" || isWrapper) && (isIndexKey(key) || key == "length") || (obj.OType == runtime.ObjTypeMap || obj.OType == runtime.ObjTypeSet) && key == "size" {
+			continue
+		}
+		var part strings.Builder
+		part.WriteString(propertyKey(key) + ": ")
+		switch {
+		case prop.IsAccessor && prop.Getter != nil && prop.Setter != nil:
+			part.WriteString("[Getter/Setter]")
+		case prop.IsAccessor && prop.Getter != nil:
+			part.WriteString("[Getter]")
+		case prop.IsAccessor:
+			part.WriteString("[Setter]")
+		default:
+			in.value(&part, prop.Value, depth+1)
+		}
+		parts = append(parts, part.String())
+	}
+
+	if isWrapper && len(parts) == 0 {
+		sb.WriteString(strings.TrimSpace(prefix))
+		return
+	}
+	sb.WriteString(prefix)
+	if len(parts) == 0 {
+		sb.WriteString(open + close)
+		return
+	}
+	sb.WriteString(open + " " + strings.Join(parts, ", ") + " " + close)
+}
+
+// wrapperName names the type of the primitive a wrapper object holds.
+func wrapperName(prim *runtime.Value) string {
+	switch prim.Type {
+	case runtime.TypeNumber:
+		return "Number"
+	case runtime.TypeBoolean:
+		return "Boolean"
+	case runtime.TypeSymbol:
+		return "Symbol"
+	}
+	return "String"
+}
+
+// elements formats the elements of an array, arguments object, map or
+// set, and the state of a promise. Runs of holes in an array print as
+// <n empty items>.
+func (in *inspector) elements(obj *runtime.Object, depth int) []string {
+	var parts []string
+	format := func(v *runtime.Value) string {
+		var sb strings.Builder
+		in.value(&sb, v, depth+1)
+		return sb.String()
+	}
+	switch obj.OType {
+	case runtime.ObjTypeArray:
+		holes := func(n int) {
+			switch {
+			case n == 1:
+				parts = append(parts, "<1 empty item>")
+			case n > 1:
+				parts = append(parts, "<"+strconv.Itoa(n)+" empty items>")
+			}
+		}
+		next := 0
+		for _, i := range obj.ElementIndices() {
+			holes(i - next)
+			parts = append(parts, format(elementAt(obj, i)))
+			next = i + 1
+		}
+		holes(obj.ArrayLength() - next)
+	case runtime.ObjTypeArguments:
+		for i, length := 0, lengthOf(obj); i < length; i++ {
+			parts = append(parts, format(elementAt(obj, i)))
+		}
+	case runtime.ObjTypeMap:
+		for _, e := range getMapEntries(obj) {
+			parts = append(parts, format(e.key)+" => "+format(e.value))
+		}
+	case runtime.ObjTypeSet:
+		for _, item := range getSetItems(obj) {
+			parts = append(parts, format(item))
+		}
+	case runtime.ObjTypePromise:
+		if pd := getPromiseData(obj); pd != nil {
+			switch pd.state {
+			case promisePending:
+				parts = append(parts, "<pending>")
+			case promiseFulfilled:
+				parts = append(parts, format(pd.result))
+			case promiseRejected:
+				parts = append(parts, "<rejected> "+format(pd.result))
+			}
+		}
+	}
+	return parts
+}
+
+// functionLabel names a function as [Function: f], or by its kind as
+// [AsyncFunction: f], [GeneratorFunction: f] or [AsyncGeneratorFunction: f],
+// and a class as [class C], or [class D extends C] for a derived class.
+func functionLabel(fn *runtime.Object) string {
+	name := functionName(fn)
+	if fn.Internal["isClass"] == true {
+		if name == "" {
+			name = "(anonymous)"
+		}
+		label := "[class " + name
+		if parent := fn.Prototype; parent != nil && parent.Callable != nil {
+			if parentName := functionName(parent); parentName != "" {
+				label += " extends " + parentName
+			}
+		}
+		return label + "]"
+	}
+	kind := "Function"
+	switch async, generator := fn.Internal["isAsync"] == true, fn.Internal["isGenerator"] == true; {
+	case async && generator:
+		kind = "AsyncGeneratorFunction"
+	case async:
+		kind = "AsyncFunction"
+	case generator:
+		kind = "GeneratorFunction"
+	}
+	if name == "" {
+		return "[" + kind + " (anonymous)]"
+	}
+	return "[" + kind + ": " + name + "]"
+}
+
+// functionName returns the own name property of fn if it is a string; an
+// anonymous derived class would otherwise show the name of its parent.
+func functionName(fn *runtime.Object) string {
+	if prop := runtime.OwnProperty(fn, "name"); prop != nil && !prop.IsAccessor && prop.Value != nil && prop.Value.Type == runtime.TypeString {
+		return prop.Value.Str
+	}
+	return ""
+}
+
+// constructorName returns the name of the constructor of obj's prototype,
+// which is shown before instances of classes.
+func constructorName(obj *runtime.Object) string {
+	if obj.Prototype == nil {
+		return "[Object: null prototype]"
+	}
+	ctor := obj.Prototype.Get("constructor")
+	if ctor == nil || ctor.Type != runtime.TypeObject || ctor.Object == nil {
+		return "Object"
+	}
+	if n := ctor.Object.Get("name"); n != nil && n.Type == runtime.TypeString && n.Str != "" {
+		return n.Str
+	}
+	return "Object"
+}
+
+func isIndexKey(key string) bool {
+	_, err := strconv.ParseUint(key, 10, 32)
+	return err == nil
+}
+
+// propertyKey writes a key unquoted when it is an identifier or an index,
+// and a symbol key as [Symbol(description)].
+func propertyKey(key string) string {
+	if runtime.IsSymbolKey(key) {
+		return "[" + runtime.KeyToValue(key).ToString() + "]"
+	}
+	if key == "" {
+		return "''"
+	}
+	if isIndexKey(key) {
+		return key
+	}
+	for i, r := range key {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return quoteJSString(key)
+		}
+	}
+	return key
+}
+
+// quoteJSString quotes s in single quotes, escaping as JavaScript would.
+// A lone surrogate is written as its \u escape.
+func quoteJSString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if r, ok := runtime.LoneSurrogate(s, i); ok {
+			sb.WriteString(quoteSegment(s[start:i]))
+			fmt.Fprintf(&sb, `\u%04x`, r)
+			i += 2
+			start = i + 1
+		}
+	}
+	sb.WriteString(quoteSegment(s[start:]))
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// quoteSegment escapes s, which has no lone surrogates, for quoteJSString.
+func quoteSegment(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q[1:len(q)-1], `\"`, `"`)
+	return strings.ReplaceAll(q, "'", `\'`)
+}
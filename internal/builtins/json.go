@@ -0,0 +1,660 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createJSONObject(objProto *runtime.Object) *runtime.Object {
+	j := runtime.NewOrdinaryObject(objProto)
+
+	rl.setMethod(j, "parse", 2, rl.jsonParse)
+	rl.setMethod(j, "stringify", 3, rl.jsonStringify)
+
+	j.Set("@@toStringTag", runtime.NewString("JSON"))
+	return j
+}
+
+func (rl *Realm) jsonParse(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	text, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	p := &jsonParser{realm: rl, text: text}
+	p.skipSpace()
+	result, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, p.errorf("Unexpected non-whitespace character after JSON")
+	}
+	reviver := getCallable(argAt(args, 1))
+	if reviver == nil {
+		return result, nil
+	}
+	root := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	root.Set("", result)
+	return internalizeJSONProperty(reviver, root, "", result)
+}
+
+// jsonParser reads JSON text by hand rather than through encoding/json so
+// that object keys keep their source order, lone surrogate escapes survive
+// and syntax errors read like the ones engines report, with the position
+// counted in UTF-16 code units.
+type jsonParser struct {
+	realm *Realm
+	text  string
+	pos   int
+}
+
+// errorf returns a SyntaxError for the current position.
+func (p *jsonParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("SyntaxError: %s in JSON at position %d", fmt.Sprintf(format, args...), runtime.UTF16Length(p.text[:p.pos]))
+}
+
+// unexpected reports the character at the current position, or the end of
+// the input if there is none.
+func (p *jsonParser) unexpected() error {
+	if p.pos >= len(p.text) {
+		return fmt.Errorf("SyntaxError: Unexpected end of JSON input")
+	}
+	r, _ := utf8.DecodeRuneInString(p.text[p.pos:])
+	return p.errorf("Unexpected token '%c'", r)
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jsonParser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+func (p *jsonParser) parseValue() (*runtime.Value, error) {
+	switch c := p.peek(); {
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return runtime.NewString(s), nil
+	case c == '-' || c >= '0' && c <= '9':
+		return p.parseNumber()
+	case strings.HasPrefix(p.text[p.pos:], "true"):
+		p.pos += 4
+		return runtime.True, nil
+	case strings.HasPrefix(p.text[p.pos:], "false"):
+		p.pos += 5
+		return runtime.False, nil
+	case strings.HasPrefix(p.text[p.pos:], "null"):
+		p.pos += 4
+		return runtime.Null, nil
+	}
+	return nil, p.unexpected()
+}
+
+// parseObject builds the object property by property so that its keys keep
+// the order of the source text.
+func (p *jsonParser) parseObject() (*runtime.Value, error) {
+	p.pos++
+	obj := runtime.NewOrdinaryObject(p.realm.ObjectPrototype)
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return runtime.NewObject(obj), nil
+	}
+	for {
+		if p.peek() != '"' {
+			if p.pos >= len(p.text) {
+				return nil, p.unexpected()
+			}
+			return nil, p.errorf("Expected property name or '}'")
+		}
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ':' {
+			if p.pos >= len(p.text) {
+				return nil, p.unexpected()
+			}
+			return nil, p.errorf("Expected ':' after property name")
+		}
+		p.pos++
+		p.skipSpace()
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		obj.Set(key, item)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case '}':
+			p.pos++
+			return runtime.NewObject(obj), nil
+		default:
+			if p.pos >= len(p.text) {
+				return nil, p.unexpected()
+			}
+			return nil, p.errorf("Expected ',' or '}' after property value")
+		}
+	}
+}
+
+func (p *jsonParser) parseArray() (*runtime.Value, error) {
+	p.pos++
+	var data []*runtime.Value
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return runtime.NewObject(p.realm.newArray(data)), nil
+	}
+	for {
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, item)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			p.skipSpace()
+		case ']':
+			p.pos++
+			return runtime.NewObject(p.realm.newArray(data)), nil
+		default:
+			if p.pos >= len(p.text) {
+				return nil, p.unexpected()
+			}
+			return nil, p.errorf("Expected ',' or ']' after array element")
+		}
+	}
+}
+
+// parseString reads a string literal starting at its opening quote. Escapes
+// are decoded as UTF-16 code units, so an escaped lone surrogate is kept.
+func (p *jsonParser) parseString() (string, error) {
+	p.pos++
+	var units []uint16
+	start := p.pos
+	for {
+		if p.pos >= len(p.text) {
+			return "", p.errorf("Unterminated string")
+		}
+		c := p.text[p.pos]
+		switch {
+		case c == '"':
+			if units == nil {
+				s := p.text[start:p.pos]
+				p.pos++
+				return s, nil
+			}
+			units = append(units, runtime.UTF16(p.text[start:p.pos])...)
+			p.pos++
+			return runtime.FromUTF16(units), nil
+		case c < 0x20:
+			return "", p.errorf("Bad control character in string literal")
+		case c == '\\':
+			units = append(units, runtime.UTF16(p.text[start:p.pos])...)
+			p.pos++
+			unit, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			units = append(units, unit)
+			start = p.pos
+		default:
+			p.pos++
+		}
+	}
+}
+
+// parseEscape decodes the escape sequence after a backslash.
+func (p *jsonParser) parseEscape() (uint16, error) {
+	if p.pos >= len(p.text) {
+		return 0, p.errorf("Unterminated string")
+	}
+	c := p.text[p.pos]
+	p.pos++
+	switch c {
+	case '"', '\\', '/':
+		return uint16(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		if p.pos+4 <= len(p.text) {
+			if n, err := strconv.ParseUint(p.text[p.pos:p.pos+4], 16, 16); err == nil {
+				p.pos += 4
+				return uint16(n), nil
+			}
+		}
+		return 0, p.errorf("Bad Unicode escape")
+	}
+	p.pos--
+	return 0, p.errorf("Bad escaped character")
+}
+
+func (p *jsonParser) parseNumber() (*runtime.Value, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("No number after minus sign")
+		}
+	}
+	if p.peek() == '0' {
+		p.pos++
+	} else {
+		p.skipDigits()
+	}
+	if p.peek() == '.' {
+		p.pos++
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("Unterminated fractional number")
+		}
+		p.skipDigits()
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("Exponent part is missing a number")
+		}
+		p.skipDigits()
+	}
+	// A range error still carries the correctly rounded infinity or zero.
+	n, _ := strconv.ParseFloat(p.text[start:p.pos], 64)
+	return runtime.NewNumber(n), nil
+}
+
+func (p *jsonParser) skipDigits() {
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// internalizeJSONProperty implements InternalizeJSONProperty: the reviver
+// sees every value bottom-up, called on its holder with its key as a
+// string, and a result of undefined removes the property.
+func internalizeJSONProperty(reviver runtime.CallableFunc, holder *runtime.Object, key string, val *runtime.Value) (*runtime.Value, error) {
+	if obj := toObject(val); obj != nil && val.Type == runtime.TypeObject {
+		if obj.OType == runtime.ObjTypeArray {
+			for i := 0; i < obj.ArrayLength(); i++ {
+				newVal, err := internalizeJSONProperty(reviver, obj, strconv.Itoa(i), elementAt(obj, i))
+				if err != nil {
+					return nil, err
+				}
+				if i < obj.ArrayLength() {
+					obj.SetArrayElement(i, newVal)
+				}
+			}
+		} else {
+			for _, k := range jsonPropertyKeys(obj) {
+				newVal, err := internalizeJSONProperty(reviver, obj, k, obj.Get(k))
+				if err != nil {
+					return nil, err
+				}
+				if newVal.Type == runtime.TypeUndefined {
+					delete(obj.Properties, k)
+				} else {
+					obj.Set(k, newVal)
+				}
+			}
+		}
+	}
+	result, err := reviver(runtime.NewObject(holder), []*runtime.Value{runtime.NewString(key), val})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return runtime.Undefined, nil
+	}
+	return result, nil
+}
+
+// jsonPropertyKeys returns the enumerable own string keys of obj in
+// property order.
+func jsonPropertyKeys(obj *runtime.Object) []string {
+	return ownKeys(obj, runtime.EnumerableStringKeys)
+}
+
+// jsonSerializer holds the state of one JSON.stringify call.
+type jsonSerializer struct {
+	realm        *Realm
+	replacer     runtime.CallableFunc
+	propertyList []string // keys picked by an array replacer, nil for all
+	gap          string
+	stack        []*runtime.Object // objects being serialized, for cycle detection
+}
+
+func (rl *Realm) jsonStringify(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := &jsonSerializer{realm: rl}
+	if rep := toObject(argAt(args, 1)); rep != nil && args[1].Type == runtime.TypeObject {
+		if rep.Callable != nil {
+			s.replacer = rep.Callable
+		} else if rep.OType == runtime.ObjTypeArray {
+			s.propertyList = jsonPropertyList(rep)
+		}
+	}
+	s.gap = jsonGap(argAt(args, 2))
+
+	wrapper := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	val := argAt(args, 0)
+	wrapper.Set("", val)
+	str, ok, err := s.serializeProperty(wrapper, "", val, "")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return runtime.Undefined, nil
+	}
+	return runtime.NewString(str), nil
+}
+
+// jsonPropertyList collects the keys named by an array replacer: strings,
+// numbers and their wrapper objects, without duplicates.
+func jsonPropertyList(rep *runtime.Object) []string {
+	list := []string{}
+	seen := make(map[string]bool)
+	for _, v := range rep.ArrayValues() {
+		var key string
+		switch {
+		case v == nil:
+			continue
+		case v.Type == runtime.TypeString, v.Type == runtime.TypeNumber:
+			key = v.ToString()
+		case v.Type == runtime.TypeObject:
+			p := unwrapPrimitive(v)
+			if p == nil || p.Type == runtime.TypeBoolean {
+				continue
+			}
+			key = p.ToString()
+		default:
+			continue
+		}
+		if !seen[key] {
+			seen[key] = true
+			list = append(list, key)
+		}
+	}
+	return list
+}
+
+// jsonGap computes the indentation unit from the space argument: up to ten
+// spaces for a number, the first ten characters of a string.
+func jsonGap(space *runtime.Value) string {
+	if space.Type == runtime.TypeObject {
+		if p := unwrapPrimitive(space); p != nil && (p.Type == runtime.TypeNumber || p.Type == runtime.TypeString) {
+			space = p
+		}
+	}
+	switch space.Type {
+	case runtime.TypeNumber:
+		n := math.Min(10, space.Number)
+		if n >= 1 {
+			return strings.Repeat(" ", int(n))
+		}
+	case runtime.TypeString:
+		if runtime.UTF16Length(space.Str) > 10 {
+			return runtime.UTF16Slice(space.Str, 0, 10)
+		}
+		return space.Str
+	}
+	return ""
+}
+
+// unwrapPrimitive returns the primitive held by a Number, String or Boolean
+// object, or nil for other values.
+func unwrapPrimitive(v *runtime.Value) *runtime.Value {
+	if v.Type != runtime.TypeObject {
+		return nil
+	}
+	if prim, ok := runtime.PrimitiveData(v.Object); ok && prim.Type != runtime.TypeSymbol {
+		return prim
+	}
+	return nil
+}
+
+// serializeProperty implements SerializeJSONProperty for the value val of
+// holder[key]. ok is false when the value has no JSON representation
+// (undefined, a function or a symbol).
+func (s *jsonSerializer) serializeProperty(holder *runtime.Object, key string, val *runtime.Value, indent string) (str string, ok bool, err error) {
+	if val == nil {
+		val = runtime.Undefined
+	}
+	if val.Type == runtime.TypeObject && val.Object != nil {
+		toJSONVal, err := val.Object.GetErr("toJSON")
+		if err != nil {
+			return "", false, err
+		}
+		if toJSON := getCallable(toJSONVal); toJSON != nil {
+			if val, err = toJSON(val, []*runtime.Value{runtime.NewString(key)}); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	if s.replacer != nil {
+		if val, err = s.replacer(runtime.NewObject(holder), []*runtime.Value{runtime.NewString(key), val}); err != nil {
+			return "", false, err
+		}
+	}
+	if val == nil {
+		return "", false, nil
+	}
+	if p := unwrapPrimitive(val); p != nil {
+		val = p
+	}
+
+	switch val.Type {
+	case runtime.TypeNull:
+		return "null", true, nil
+	case runtime.TypeBoolean:
+		if val.Bool {
+			return "true", true, nil
+		}
+		return "false", true, nil
+	case runtime.TypeNumber:
+		if isNaN(val.Number) || isInf(val.Number, 0) {
+			return "null", true, nil
+		}
+		return val.ToString(), true, nil
+	case runtime.TypeString:
+		str, err := s.realm.chargeJSON(quoteJSONString(val.Str))
+		return str, err == nil, err
+	case runtime.TypeObject:
+		if val.Object == nil || val.Object.Callable != nil {
+			return "", false, nil
+		}
+		isArray, err := runtime.IsArray(val)
+		if err != nil {
+			return "", false, err
+		}
+		if isArray {
+			str, err := s.serializeArray(val.Object, indent)
+			return str, err == nil, err
+		}
+		str, err := s.serializeObject(val.Object, indent)
+		return str, err == nil, err
+	}
+	return "", false, nil
+}
+
+// enter pushes obj on the stack of objects being serialized, failing with a
+// TypeError if it is already there.
+func (s *jsonSerializer) enter(obj *runtime.Object) error {
+	for _, o := range s.stack {
+		if o == obj {
+			return fmt.Errorf("TypeError: Converting circular structure to JSON")
+		}
+	}
+	s.stack = append(s.stack, obj)
+	return nil
+}
+
+func (s *jsonSerializer) leave() {
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+func (s *jsonSerializer) serializeObject(obj *runtime.Object, indent string) (string, error) {
+	if err := s.enter(obj); err != nil {
+		return "", err
+	}
+	defer s.leave()
+
+	keys := s.propertyList
+	if keys == nil {
+		var err error
+		if keys, err = s.realm.enumerableOwnKeys(obj); err != nil {
+			return "", err
+		}
+	}
+	stepback := indent
+	indent += s.gap
+	var parts []string
+	for _, k := range keys {
+		val, err := obj.GetErr(k)
+		if err != nil {
+			return "", err
+		}
+		str, ok, err := s.serializeProperty(obj, k, val, indent)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+		member := quoteJSONString(k) + ":"
+		if s.gap != "" {
+			member += " "
+		}
+		parts = append(parts, member+str)
+	}
+	return s.realm.chargeJSON(joinJSON("{", "}", parts, s.gap, indent, stepback))
+}
+
+func (s *jsonSerializer) serializeArray(arr *runtime.Object, indent string) (string, error) {
+	if err := s.enter(arr); err != nil {
+		return "", err
+	}
+	defer s.leave()
+
+	stepback := indent
+	indent += s.gap
+	length := lengthOf(arr)
+	if err := s.realm.Agent.ChargeSlots(length); err != nil {
+		return "", err
+	}
+	parts := make([]string, 0, length)
+	for i := 0; i < length; i++ {
+		str, ok, err := s.serializeProperty(arr, strconv.Itoa(i), elementAt(arr, i), indent)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			str = "null"
+		}
+		parts = append(parts, str)
+	}
+	return s.realm.chargeJSON(joinJSON("[", "]", parts, s.gap, indent, stepback))
+}
+
+// chargeJSON counts the serialization str of an object or array against
+// the heap budget, as each level of nesting copies the text of the levels
+// below it.
+func (rl *Realm) chargeJSON(str string) (string, error) {
+	if err := rl.Agent.Charge(int64(len(str))); err != nil {
+		return "", err
+	}
+	return str, nil
+}
+
+// joinJSON brackets the serialized members, one per line when there is a
+// gap.
+func joinJSON(open, close string, parts []string, gap, indent, stepback string) string {
+	if len(parts) == 0 {
+		return open + close
+	}
+	if gap == "" {
+		return open + strings.Join(parts, ",") + close
+	}
+	return open + "\n" + indent + strings.Join(parts, ",\n"+indent) + "\n" + stepback + close
+}
+
+// quoteJSONString implements QuoteJSONString: only quotes, backslashes and
+// control characters are escaped.
+func quoteJSONString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(&sb, `\u%04x`, c)
+			} else if r, ok := runtime.LoneSurrogate(s, i); ok {
+				// Lone surrogates are escaped, so the output is valid UTF-8.
+				fmt.Fprintf(&sb, `\u%04x`, r)
+				i += 2
+			} else {
+				sb.WriteByte(c)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,331 @@
+package builtins
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupJSON() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createArrayConstructor(rl.ObjectPrototype)
+	return rl
+}
+
+func TestJSONParseSimple(t *testing.T) {
+	rl := setupJSON()
+	tests := []struct {
+		input string
+		check func(*runtime.Value) bool
+	}{
+		{`42`, func(v *runtime.Value) bool { return v.Number == 42 }},
+		{`"hello"`, func(v *runtime.Value) bool { return v.Str == "hello" }},
+		{`true`, func(v *runtime.Value) bool { return v.Bool == true }},
+		{`null`, func(v *runtime.Value) bool { return v.Type == runtime.TypeNull }},
+		{`[1,2,3]`, func(v *runtime.Value) bool {
+			arr := toObject(v)
+			return arr != nil && len(arr.ArrayData) == 3
+		}},
+		{`{"a":1}`, func(v *runtime.Value) bool {
+			obj := toObject(v)
+			return obj != nil && obj.Get("a").Number == 1
+		}},
+	}
+	for _, tt := range tests {
+		result, err := rl.jsonParse(runtime.Undefined, []*runtime.Value{runtime.NewString(tt.input)})
+		if err != nil {
+			t.Errorf("JSON.parse(%q): %v", tt.input, err)
+			continue
+		}
+		if !tt.check(result) {
+			t.Errorf("JSON.parse(%q): check failed, got %v", tt.input, result)
+		}
+	}
+}
+
+func TestJSONStringifySimple(t *testing.T) {
+	rl := setupJSON()
+	tests := []struct {
+		val  *runtime.Value
+		want string
+	}{
+		{runtime.NewNumber(42), "42"},
+		{runtime.NewString("hello"), `"hello"`},
+		{runtime.True, "true"},
+		{runtime.Null, "null"},
+		{runtime.NewString(runtime.FromUTF16([]uint16{0xD83D, 'a', 0xD83D, 0xDE00})), `"\ud83da😀"`},
+	}
+	for _, tt := range tests {
+		result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{tt.val})
+		if err != nil {
+			t.Errorf("JSON.stringify(%v): %v", tt.val, err)
+			continue
+		}
+		if result.Str != tt.want {
+			t.Errorf("JSON.stringify(%v): got %q, want %q", tt.val, result.Str, tt.want)
+		}
+	}
+}
+
+func TestJSONStringifyObject(t *testing.T) {
+	rl := setupJSON()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("a", runtime.NewNumber(1))
+	obj.Set("b", runtime.NewString("hello"))
+
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys are sorted, so we get a deterministic output
+	expected1 := `{"a":1,"b":"hello"}`
+	expected2 := `{"b":"hello","a":1}`
+	if result.Str != expected1 && result.Str != expected2 {
+		t.Errorf("JSON.stringify object: got %q", result.Str)
+	}
+}
+
+func TestJSONStringifyArray(t *testing.T) {
+	rl := setupJSON()
+	arr := rl.newArray([]*runtime.Value{runtime.NewNumber(1), runtime.NewString("two"), runtime.True})
+
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(arr)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != `[1,"two",true]` {
+		t.Errorf("JSON.stringify array: got %q, want %q", result.Str, `[1,"two",true]`)
+	}
+}
+
+func TestJSONStringifyWithIndent(t *testing.T) {
+	rl := setupJSON()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(1))
+
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.Undefined, runtime.NewNumber(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n  \"x\": 1\n}"
+	if result.Str != expected {
+		t.Errorf("JSON.stringify with indent: got %q, want %q", result.Str, expected)
+	}
+}
+
+func TestJSONParseSyntaxError(t *testing.T) {
+	rl := registerTestRealm()
+	tests := []struct {
+		input, want string
+	}{
+		{"{invalid}", "SyntaxError: Expected property name or '}' in JSON at position 1"},
+		{"", "SyntaxError: Unexpected end of JSON input"},
+		{"[1, 2", "SyntaxError: Unexpected end of JSON input"},
+		{"[1 2]", "SyntaxError: Expected ',' or ']' after array element in JSON at position 3"},
+		{`{"a" 1}`, "SyntaxError: Expected ':' after property name in JSON at position 5"},
+		{`{"a": 1 "b": 2}`, "SyntaxError: Expected ',' or '}' after property value in JSON at position 8"},
+		{"[1,]", "SyntaxError: Unexpected token ']' in JSON at position 3"},
+		{"tru", "SyntaxError: Unexpected token 't' in JSON at position 0"},
+		{"1 2", "SyntaxError: Unexpected non-whitespace character after JSON in JSON at position 2"},
+		{`"abc`, "SyntaxError: Unterminated string in JSON at position 4"},
+		{"\"a\tb\"", "SyntaxError: Bad control character in string literal in JSON at position 2"},
+		{`"\x"`, "SyntaxError: Bad escaped character in JSON at position 2"},
+		{`"\u12"`, "SyntaxError: Bad Unicode escape in JSON at position 3"},
+		{"-", "SyntaxError: No number after minus sign in JSON at position 1"},
+		{"1.", "SyntaxError: Unterminated fractional number in JSON at position 2"},
+		{"1e+", "SyntaxError: Exponent part is missing a number in JSON at position 3"},
+		{"01", "SyntaxError: Unexpected non-whitespace character after JSON in JSON at position 1"},
+		{`["😀", x]`, "SyntaxError: Unexpected token 'x' in JSON at position 7"},
+	}
+	for _, tt := range tests {
+		_, err := rl.jsonParse(runtime.Undefined, []*runtime.Value{runtime.NewString(tt.input)})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("JSON.parse(%q): got %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestJSONParseStrings(t *testing.T) {
+	rl := registerTestRealm()
+	tests := []struct {
+		input string
+		want  []uint16
+	}{
+		{`"a\u0062c"`, []uint16{'a', 'b', 'c'}},
+		{`"\ud83d\ude00"`, []uint16{0xD83D, 0xDE00}},
+		{`"\ud800x"`, []uint16{0xD800, 'x'}},
+		{`"é\n"`, []uint16{0xE9, '\n'}},
+	}
+	for _, tt := range tests {
+		result, err := rl.jsonParse(runtime.Undefined, []*runtime.Value{runtime.NewString(tt.input)})
+		if err != nil {
+			t.Errorf("JSON.parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got := runtime.UTF16(result.Str); !slices.Equal(got, tt.want) {
+			t.Errorf("JSON.parse(%q): got %x, want %x", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestJSONStringifyNaN(t *testing.T) {
+	rl := registerTestRealm()
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NaN})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != "null" {
+		t.Errorf("JSON.stringify(NaN): expected 'null', got %q", result.Str)
+	}
+}
+
+func TestJSONStringifyCycle(t *testing.T) {
+	rl := setupJSON()
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	inner := rl.newArray([]*runtime.Value{runtime.NewObject(obj)})
+	obj.Set("a", runtime.NewObject(inner))
+
+	_, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err == nil || !strings.HasPrefix(err.Error(), "TypeError:") {
+		t.Fatalf("JSON.stringify of a cycle: expected TypeError, got %v", err)
+	}
+
+	// The same object twice, but not nested in itself, is fine.
+	shared := runtime.NewObject(runtime.NewOrdinaryObject(rl.ObjectPrototype))
+	arr := rl.newArray([]*runtime.Value{shared, shared})
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(arr)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != "[{},{}]" {
+		t.Errorf("JSON.stringify shared object: got %q", result.Str)
+	}
+}
+
+func TestJSONStringifyToJSON(t *testing.T) {
+	rl := setupJSON()
+	var gotKey string
+	toJSON := rl.newFuncObject("toJSON", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		gotKey = argAt(args, 0).Str
+		return runtime.NewString("replaced"), nil
+	})
+	inner := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	inner.Set("toJSON", runtime.NewObject(toJSON))
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("k", runtime.NewObject(inner))
+
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != `{"k":"replaced"}` {
+		t.Errorf("JSON.stringify with toJSON: got %q", result.Str)
+	}
+	if gotKey != "k" {
+		t.Errorf("toJSON key: got %q, want %q", gotKey, "k")
+	}
+}
+
+func TestJSONStringifyReplacer(t *testing.T) {
+	rl := setupJSON()
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set("a", runtime.NewNumber(1))
+	obj.Set("b", runtime.NewString("x"))
+	obj.Set("c", runtime.True)
+
+	replacer := rl.newFuncObject("replacer", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		v := argAt(args, 1)
+		if v.Type == runtime.TypeNumber {
+			return runtime.NewNumber(v.Number * 10), nil
+		}
+		if v.Type == runtime.TypeBoolean {
+			return runtime.Undefined, nil
+		}
+		return v, nil
+	})
+	result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewObject(replacer)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != `{"a":10,"b":"x"}` {
+		t.Errorf("JSON.stringify with replacer function: got %q", result.Str)
+	}
+
+	list := rl.newArray([]*runtime.Value{runtime.NewString("c"), runtime.NewString("a"), runtime.NewString("c")})
+	result, err = rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewObject(list)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Str != `{"c":true,"a":1}` {
+		t.Errorf("JSON.stringify with replacer array: got %q", result.Str)
+	}
+}
+
+func TestJSONStringifyGap(t *testing.T) {
+	rl := setupJSON()
+	arr := rl.newArray([]*runtime.Value{runtime.NewNumber(1)})
+	tests := []struct {
+		space *runtime.Value
+		want  string
+	}{
+		{runtime.NewNumber(20), "[\n" + strings.Repeat(" ", 10) + "1\n]"},
+		{runtime.NewString("--"), "[\n--1\n]"},
+		{runtime.NewNumber(0), "[1]"},
+	}
+	for _, tt := range tests {
+		result, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewObject(arr), runtime.Undefined, tt.space})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Str != tt.want {
+			t.Errorf("JSON.stringify space %v: got %q, want %q", tt.space, result.Str, tt.want)
+		}
+	}
+}
+
+func TestJSONParseReviver(t *testing.T) {
+	rl := setupJSON()
+	var holders []string
+	reviver := rl.newFuncObject("reviver", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		key, val := argAt(args, 0), argAt(args, 1)
+		holders = append(holders, key.Str)
+		if key.Str == "drop" {
+			return runtime.Undefined, nil
+		}
+		if val.Type == runtime.TypeNumber {
+			return runtime.NewNumber(val.Number + 1), nil
+		}
+		return val, nil
+	})
+	result, err := rl.jsonParse(runtime.Undefined, []*runtime.Value{runtime.NewString(`{"a":1,"drop":2,"b":[3]}`), runtime.NewObject(reviver)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	if obj.Get("a").Number != 2 || obj.Get("b").Object.ArrayData[0].Number != 4 {
+		t.Errorf("JSON.parse reviver: got a=%v b=%v", obj.Get("a"), obj.Get("b"))
+	}
+	if _, ok := obj.Properties["drop"]; ok {
+		t.Error("JSON.parse reviver: undefined result should delete the property")
+	}
+	if got := strings.Join(holders, ","); got != "a,drop,0,b," {
+		t.Errorf("JSON.parse reviver keys: got %q", got)
+	}
+}
+
+func TestJSONCallbackErrors(t *testing.T) {
+	rl := setupJSON()
+	fail := rl.newFuncObject("fail", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("RangeError: boom")
+	})
+	if _, err := rl.jsonParse(runtime.Undefined, []*runtime.Value{runtime.NewString(`[1]`), runtime.NewObject(fail)}); err == nil || err.Error() != "RangeError: boom" {
+		t.Errorf("JSON.parse reviver error: got %v", err)
+	}
+	if _, err := rl.jsonStringify(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1), runtime.NewObject(fail)}); err == nil || err.Error() != "RangeError: boom" {
+		t.Errorf("JSON.stringify replacer error: got %v", err)
+	}
+}
@@ -3,14 +3,7 @@ package builtins
 import (
 	"fmt"
 
-	"github.com/example/jsgo/runtime"
-)
-
-var (
-	MapPrototype     *runtime.Object
-	SetPrototype     *runtime.Object
-	WeakMapPrototype *runtime.Object
-	WeakSetPrototype *runtime.Object
+	"github.com/example/jsgo/internal/runtime"
 )
 
 // mapEntry stores key-value pairs preserving insertion order
@@ -19,23 +12,29 @@ type mapEntry struct {
 	value *runtime.Value
 }
 
-func createMapConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+func (rl *Realm) createMapConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
 	proto := runtime.NewOrdinaryObject(objProto)
 	proto.OType = runtime.ObjTypeMap
-	MapPrototype = proto
-
-	setMethod(proto, "get", 1, mapGet)
-	setMethod(proto, "set", 2, mapSet)
-	setMethod(proto, "has", 1, mapHas)
-	setMethod(proto, "delete", 1, mapDelete)
-	setMethod(proto, "clear", 0, mapClear)
-	setMethod(proto, "forEach", 1, mapForEach)
-	setMethod(proto, "keys", 0, mapKeys)
-	setMethod(proto, "values", 0, mapValues)
-	setMethod(proto, "entries", 0, mapEntries)
-
-	ctor := newFuncObject("Map", 0, mapConstructorCall)
-	ctor.Constructor = mapConstructorCall
+	rl.MapPrototype = proto
+
+	rl.setMethod(proto, "get", 1, mapGet)
+	rl.setMethod(proto, "set", 2, mapSet)
+	rl.setMethod(proto, "has", 1, mapHas)
+	rl.setMethod(proto, "delete", 1, mapDelete)
+	rl.setMethod(proto, "clear", 0, mapClear)
+	rl.setMethod(proto, "forEach", 1, mapForEach)
+	rl.setMethod(proto, "keys", 0, rl.mapKeys)
+	rl.setMethod(proto, "values", 0, rl.mapValues)
+	rl.setMethod(proto, "entries", 0, rl.mapEntries)
+	if SymIterator != nil {
+		// Map.prototype[Symbol.iterator] is the same function as entries.
+		setDataProp(proto, SymIterator.Key(), proto.Get("entries"), true, false, true)
+	}
+
+	ctor := rl.newFuncObject("Map", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor Map requires 'new'")
+	})
+	ctor.Constructor = rl.mapConstructorCall
 
 	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
 	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
@@ -67,21 +66,29 @@ func findMapEntry(entries []*mapEntry, key *runtime.Value) int {
 	return -1
 }
 
-func mapConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) mapConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := &runtime.Object{
 		OType:      runtime.ObjTypeMap,
 		Properties: make(map[string]*runtime.Property),
-		Prototype:  MapPrototype,
+		Prototype:  rl.MapPrototype,
 		Internal:   map[string]interface{}{"entries": []*mapEntry{}},
 	}
 	obj.Set("size", runtime.NewNumber(0))
 	result := runtime.NewObject(obj)
-	if len(args) > 0 && args[0].Type == runtime.TypeObject && args[0].Object != nil && args[0].Object.OType == runtime.ObjTypeArray {
-		for _, item := range args[0].Object.ArrayData {
-			if item.Type == runtime.TypeObject && item.Object != nil && item.Object.OType == runtime.ObjTypeArray && len(item.Object.ArrayData) >= 2 {
-				_, _ = mapSet(result, []*runtime.Value{item.Object.ArrayData[0], item.Object.ArrayData[1]})
-			}
+	iterable := argAt(args, 0)
+	if iterable.Type == runtime.TypeUndefined || iterable.Type == runtime.TypeNull {
+		return result, nil
+	}
+	err := rl.iterate(iterable, func(item *runtime.Value) error {
+		entry := toObject(item)
+		if entry == nil {
+			return fmt.Errorf("TypeError: Iterator value %s is not an entry object", item.ToString())
 		}
+		_, err := mapSet(result, []*runtime.Value{elementAt(entry, 0), elementAt(entry, 1)})
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
@@ -165,7 +172,7 @@ func mapForEach(this *runtime.Value, args []*runtime.Value) (*runtime.Value, err
 	return runtime.Undefined, nil
 }
 
-func mapKeys(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) mapKeys(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := toObject(this)
 	entries := getMapEntries(obj)
 	idx := 0
@@ -181,11 +188,11 @@ func mapKeys(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error)
 			return v, false
 		},
 	}
-	setMethod(iter, "next", 0, makeIteratorNext(iter))
+	rl.setIteratorMethods(iter)
 	return runtime.NewObject(iter), nil
 }
 
-func mapValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) mapValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := toObject(this)
 	entries := getMapEntries(obj)
 	idx := 0
@@ -201,11 +208,11 @@ func mapValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, erro
 			return v, false
 		},
 	}
-	setMethod(iter, "next", 0, makeIteratorNext(iter))
+	rl.setIteratorMethods(iter)
 	return runtime.NewObject(iter), nil
 }
 
-func mapEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) mapEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := toObject(this)
 	entries := getMapEntries(obj)
 	idx := 0
@@ -216,33 +223,38 @@ func mapEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, err
 			if idx >= len(entries) {
 				return runtime.Undefined, true
 			}
-			pair := createValueArray([]*runtime.Value{entries[idx].key, entries[idx].value})
+			pair := rl.createValueArray([]*runtime.Value{entries[idx].key, entries[idx].value})
 			idx++
 			return pair, false
 		},
 	}
-	setMethod(iter, "next", 0, makeIteratorNext(iter))
+	rl.setIteratorMethods(iter)
 	return runtime.NewObject(iter), nil
 }
 
 // --- Set ---
 
-func createSetConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+func (rl *Realm) createSetConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
 	proto := runtime.NewOrdinaryObject(objProto)
 	proto.OType = runtime.ObjTypeSet
-	SetPrototype = proto
+	rl.SetPrototype = proto
 
-	setMethod(proto, "add", 1, setAdd)
-	setMethod(proto, "has", 1, setHas)
-	setMethod(proto, "delete", 1, setDelete)
-	setMethod(proto, "clear", 0, setClear)
-	setMethod(proto, "forEach", 1, setForEach)
-	setMethod(proto, "keys", 0, setValues) // Set.keys === Set.values
-	setMethod(proto, "values", 0, setValues)
-	setMethod(proto, "entries", 0, setEntries)
+	rl.setMethod(proto, "add", 1, setAdd)
+	rl.setMethod(proto, "has", 1, setHas)
+	rl.setMethod(proto, "delete", 1, setDelete)
+	rl.setMethod(proto, "clear", 0, setClear)
+	rl.setMethod(proto, "forEach", 1, setForEach)
+	rl.setMethod(proto, "keys", 0, rl.setValues) // Set.keys === Set.values
+	rl.setMethod(proto, "values", 0, rl.setValues)
+	rl.setMethod(proto, "entries", 0, rl.setEntries)
+	if SymIterator != nil {
+		setDataProp(proto, SymIterator.Key(), proto.Get("values"), true, false, true)
+	}
 
-	ctor := newFuncObject("Set", 0, setConstructorCall)
-	ctor.Constructor = setConstructorCall
+	ctor := rl.newFuncObject("Set", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor Set requires 'new'")
+	})
+	ctor.Constructor = rl.setConstructorCall
 
 	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
 	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
@@ -274,19 +286,25 @@ func findSetItem(items []*runtime.Value, val *runtime.Value) int {
 	return -1
 }
 
-func setConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) setConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := &runtime.Object{
 		OType:      runtime.ObjTypeSet,
 		Properties: make(map[string]*runtime.Property),
-		Prototype:  SetPrototype,
+		Prototype:  rl.SetPrototype,
 		Internal:   map[string]interface{}{"items": []*runtime.Value{}},
 	}
 	obj.Set("size", runtime.NewNumber(0))
 	result := runtime.NewObject(obj)
-	if len(args) > 0 && args[0].Type == runtime.TypeObject && args[0].Object != nil && args[0].Object.OType == runtime.ObjTypeArray {
-		for _, item := range args[0].Object.ArrayData {
-			_, _ = setAdd(result, []*runtime.Value{item})
-		}
+	iterable := argAt(args, 0)
+	if iterable.Type == runtime.TypeUndefined || iterable.Type == runtime.TypeNull {
+		return result, nil
+	}
+	err := rl.iterate(iterable, func(item *runtime.Value) error {
+		_, err := setAdd(result, []*runtime.Value{item})
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
@@ -355,7 +373,7 @@ func setForEach(this *runtime.Value, args []*runtime.Value) (*runtime.Value, err
 	return runtime.Undefined, nil
 }
 
-func setValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) setValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := toObject(this)
 	items := getSetItems(obj)
 	idx := 0
@@ -371,11 +389,11 @@ func setValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, erro
 			return v, false
 		},
 	}
-	setMethod(iter, "next", 0, makeIteratorNext(iter))
+	rl.setIteratorMethods(iter)
 	return runtime.NewObject(iter), nil
 }
 
-func setEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) setEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := toObject(this)
 	items := getSetItems(obj)
 	idx := 0
@@ -386,29 +404,31 @@ func setEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, err
 			if idx >= len(items) {
 				return runtime.Undefined, true
 			}
-			pair := createValueArray([]*runtime.Value{items[idx], items[idx]})
+			pair := rl.createValueArray([]*runtime.Value{items[idx], items[idx]})
 			idx++
 			return pair, false
 		},
 	}
-	setMethod(iter, "next", 0, makeIteratorNext(iter))
+	rl.setIteratorMethods(iter)
 	return runtime.NewObject(iter), nil
 }
 
 // --- WeakMap ---
 
-func createWeakMapConstructor(objProto *runtime.Object) *runtime.Object {
+func (rl *Realm) createWeakMapConstructor(objProto *runtime.Object) *runtime.Object {
 	proto := runtime.NewOrdinaryObject(objProto)
 	proto.OType = runtime.ObjTypeWeakMap
-	WeakMapPrototype = proto
+	rl.WeakMapPrototype = proto
 
-	setMethod(proto, "get", 1, weakMapGet)
-	setMethod(proto, "set", 2, weakMapSet)
-	setMethod(proto, "has", 1, weakMapHas)
-	setMethod(proto, "delete", 1, weakMapDelete)
+	rl.setMethod(proto, "get", 1, weakMapGet)
+	rl.setMethod(proto, "set", 2, weakMapSet)
+	rl.setMethod(proto, "has", 1, weakMapHas)
+	rl.setMethod(proto, "delete", 1, weakMapDelete)
 
-	ctor := newFuncObject("WeakMap", 0, weakMapConstructorCall)
-	ctor.Constructor = weakMapConstructorCall
+	ctor := rl.newFuncObject("WeakMap", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor WeakMap requires 'new'")
+	})
+	ctor.Constructor = rl.weakMapConstructorCall
 
 	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
 	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
@@ -425,11 +445,11 @@ func getWeakMapStore(obj *runtime.Object) map[*runtime.Object]*runtime.Value {
 	return store
 }
 
-func weakMapConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) weakMapConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := &runtime.Object{
 		OType:      runtime.ObjTypeWeakMap,
 		Properties: make(map[string]*runtime.Property),
-		Prototype:  WeakMapPrototype,
+		Prototype:  rl.WeakMapPrototype,
 		Internal:   map[string]interface{}{"store": make(map[*runtime.Object]*runtime.Value)},
 	}
 	return runtime.NewObject(obj), nil
@@ -487,17 +507,19 @@ func weakMapDelete(this *runtime.Value, args []*runtime.Value) (*runtime.Value,
 
 // --- WeakSet ---
 
-func createWeakSetConstructor(objProto *runtime.Object) *runtime.Object {
+func (rl *Realm) createWeakSetConstructor(objProto *runtime.Object) *runtime.Object {
 	proto := runtime.NewOrdinaryObject(objProto)
 	proto.OType = runtime.ObjTypeWeakSet
-	WeakSetPrototype = proto
+	rl.WeakSetPrototype = proto
 
-	setMethod(proto, "add", 1, weakSetAdd)
-	setMethod(proto, "has", 1, weakSetHas)
-	setMethod(proto, "delete", 1, weakSetDelete)
+	rl.setMethod(proto, "add", 1, weakSetAdd)
+	rl.setMethod(proto, "has", 1, weakSetHas)
+	rl.setMethod(proto, "delete", 1, weakSetDelete)
 
-	ctor := newFuncObject("WeakSet", 0, weakSetConstructorCall)
-	ctor.Constructor = weakSetConstructorCall
+	ctor := rl.newFuncObject("WeakSet", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor WeakSet requires 'new'")
+	})
+	ctor.Constructor = rl.weakSetConstructorCall
 
 	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
 	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
@@ -513,11 +535,11 @@ func getWeakSetStore(obj *runtime.Object) map[*runtime.Object]struct{} {
 	return store
 }
 
-func weakSetConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+func (rl *Realm) weakSetConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 	obj := &runtime.Object{
 		OType:      runtime.ObjTypeWeakSet,
 		Properties: make(map[string]*runtime.Property),
-		Prototype:  WeakSetPrototype,
+		Prototype:  rl.WeakSetPrototype,
 		Internal:   map[string]interface{}{"store": make(map[*runtime.Object]struct{})},
 	}
 	return runtime.NewObject(obj), nil
@@ -3,19 +3,21 @@ package builtins
 import (
 	"testing"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
-func setupMapSet() {
-	createObjectConstructor()
-	createArrayConstructor(ObjectPrototype)
-	createMapConstructor(ObjectPrototype)
-	createSetConstructor(ObjectPrototype)
+func setupMapSet() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createArrayConstructor(rl.ObjectPrototype)
+	rl.createMapConstructor(rl.ObjectPrototype)
+	rl.createSetConstructor(rl.ObjectPrototype)
+	return rl
 }
 
 func TestMapBasic(t *testing.T) {
-	setupMapSet()
-	m, err := mapConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	m, err := rl.mapConstructorCall(runtime.Undefined, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -46,9 +48,27 @@ func TestMapBasic(t *testing.T) {
 	}
 }
 
+func TestMapRequiresNew(t *testing.T) {
+	rl := setupMapSet()
+	for _, name := range []string{"Map", "Set"} {
+		var ctor *runtime.Object
+		if name == "Map" {
+			ctor, _ = rl.createMapConstructor(rl.ObjectPrototype)
+		} else {
+			ctor, _ = rl.createSetConstructor(rl.ObjectPrototype)
+		}
+		if _, err := ctor.Callable(runtime.Undefined, nil); err == nil || err.Error() != "TypeError: Constructor "+name+" requires 'new'" {
+			t.Errorf("%s(): unexpected error %v", name, err)
+		}
+		if _, err := ctor.Constructor(runtime.Undefined, nil); err != nil {
+			t.Errorf("new %s(): %v", name, err)
+		}
+	}
+}
+
 func TestMapDelete(t *testing.T) {
-	setupMapSet()
-	m, _ := mapConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	m, _ := rl.mapConstructorCall(runtime.Undefined, nil)
 	mapSet(m, []*runtime.Value{runtime.NewString("a"), runtime.NewNumber(1)})
 	mapSet(m, []*runtime.Value{runtime.NewString("b"), runtime.NewNumber(2)})
 
@@ -68,8 +88,8 @@ func TestMapDelete(t *testing.T) {
 }
 
 func TestMapClear(t *testing.T) {
-	setupMapSet()
-	m, _ := mapConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	m, _ := rl.mapConstructorCall(runtime.Undefined, nil)
 	mapSet(m, []*runtime.Value{runtime.NewString("x"), runtime.NewNumber(1)})
 	mapClear(m, nil)
 	obj := toObject(m)
@@ -79,8 +99,8 @@ func TestMapClear(t *testing.T) {
 }
 
 func TestSetBasic(t *testing.T) {
-	setupMapSet()
-	s, err := setConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	s, err := rl.setConstructorCall(runtime.Undefined, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -106,8 +126,8 @@ func TestSetBasic(t *testing.T) {
 }
 
 func TestSetDelete(t *testing.T) {
-	setupMapSet()
-	s, _ := setConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	s, _ := rl.setConstructorCall(runtime.Undefined, nil)
 	setAdd(s, []*runtime.Value{runtime.NewNumber(1)})
 	setAdd(s, []*runtime.Value{runtime.NewNumber(2)})
 
@@ -123,13 +143,13 @@ func TestSetDelete(t *testing.T) {
 }
 
 func TestMapForEach(t *testing.T) {
-	setupMapSet()
-	m, _ := mapConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	m, _ := rl.mapConstructorCall(runtime.Undefined, nil)
 	mapSet(m, []*runtime.Value{runtime.NewString("a"), runtime.NewNumber(1)})
 	mapSet(m, []*runtime.Value{runtime.NewString("b"), runtime.NewNumber(2)})
 
 	count := 0
-	cb := newFuncObject("cb", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	cb := rl.newFuncObject("cb", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 		count++
 		return runtime.Undefined, nil
 	})
@@ -140,13 +160,13 @@ func TestMapForEach(t *testing.T) {
 }
 
 func TestSetForEach(t *testing.T) {
-	setupMapSet()
-	s, _ := setConstructorCall(runtime.Undefined, nil)
+	rl := setupMapSet()
+	s, _ := rl.setConstructorCall(runtime.Undefined, nil)
 	setAdd(s, []*runtime.Value{runtime.NewNumber(10)})
 	setAdd(s, []*runtime.Value{runtime.NewNumber(20)})
 
 	count := 0
-	cb := newFuncObject("cb", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	cb := rl.newFuncObject("cb", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
 		count++
 		return runtime.Undefined, nil
 	})
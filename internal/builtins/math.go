@@ -0,0 +1,360 @@
+package builtins
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createMathObject(objProto *runtime.Object) *runtime.Object {
+	m := runtime.NewOrdinaryObject(objProto)
+
+	setConstant(m, "PI", runtime.NewNumber(math.Pi))
+	setConstant(m, "E", runtime.NewNumber(math.E))
+	setConstant(m, "LN2", runtime.NewNumber(math.Ln2))
+	setConstant(m, "LN10", runtime.NewNumber(math.Ln10))
+	setConstant(m, "LOG2E", runtime.NewNumber(math.Log2E))
+	setConstant(m, "LOG10E", runtime.NewNumber(math.Log10E))
+	setConstant(m, "SQRT2", runtime.NewNumber(math.Sqrt2))
+	setConstant(m, "SQRT1_2", runtime.NewNumber(1.0/math.Sqrt2))
+
+	rl.setMethod(m, "abs", 1, mathAbs)
+	rl.setMethod(m, "ceil", 1, mathCeil)
+	rl.setMethod(m, "floor", 1, mathFloor)
+	rl.setMethod(m, "round", 1, mathRound)
+	rl.setMethod(m, "trunc", 1, mathTrunc)
+	rl.setMethod(m, "sign", 1, mathSign)
+	rl.setMethod(m, "max", 2, mathMax)
+	rl.setMethod(m, "min", 2, mathMin)
+	rl.setMethod(m, "pow", 2, mathPow)
+	rl.setMethod(m, "sqrt", 1, mathSqrt)
+	rl.setMethod(m, "cbrt", 1, mathCbrt)
+	rl.setMethod(m, "hypot", 2, mathHypot)
+	rl.setMethod(m, "log", 1, mathLog)
+	rl.setMethod(m, "log2", 1, mathLog2)
+	rl.setMethod(m, "log10", 1, mathLog10)
+	rl.setMethod(m, "exp", 1, mathExp)
+	rl.setMethod(m, "expm1", 1, mathExpm1)
+	rl.setMethod(m, "log1p", 1, mathLog1p)
+	rl.setMethod(m, "sin", 1, mathSin)
+	rl.setMethod(m, "cos", 1, mathCos)
+	rl.setMethod(m, "tan", 1, mathTan)
+	rl.setMethod(m, "asin", 1, mathAsin)
+	rl.setMethod(m, "acos", 1, mathAcos)
+	rl.setMethod(m, "atan", 1, mathAtan)
+	rl.setMethod(m, "atan2", 2, mathAtan2)
+	rl.setMethod(m, "sinh", 1, mathSinh)
+	rl.setMethod(m, "cosh", 1, mathCosh)
+	rl.setMethod(m, "tanh", 1, mathTanh)
+	rl.setMethod(m, "asinh", 1, mathAsinh)
+	rl.setMethod(m, "acosh", 1, mathAcosh)
+	rl.setMethod(m, "atanh", 1, mathAtanh)
+	rl.setMethod(m, "fround", 1, mathFround)
+	rl.setMethod(m, "clz32", 1, mathClz32)
+	rl.setMethod(m, "imul", 2, mathImul)
+
+	// Each realm has its own random source so SeedMathRandom affects only
+	// that realm.
+	rl.random = &randomSource{}
+	rl.setMethod(m, "random", 0, rl.random.random)
+
+	m.Set("@@toStringTag", runtime.NewString("Math"))
+	return m
+}
+
+// randomSource backs Math.random: the process-wide source unless seeded.
+type randomSource struct {
+	rng *rand.Rand
+}
+
+func (r *randomSource) random(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if r.rng != nil {
+		return runtime.NewNumber(r.rng.Float64()), nil
+	}
+	return mathRandom(this, args)
+}
+
+func mathRandom(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewNumber(rand.Float64()), nil
+}
+
+// SeedMathRandom makes the realm's Math.random return the deterministic
+// sequence for seed, for reproducible runs, even if a script has replaced
+// the global Math. RegisterAll must have run first.
+func (rl *Realm) SeedMathRandom(seed int64) {
+	rl.random.rng = rand.New(rand.NewSource(seed))
+}
+
+func mathUnary(args []*runtime.Value, fn func(float64) float64) (*runtime.Value, error) {
+	n, err := toNumberErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(fn(n)), nil
+}
+
+// mathArgs converts every argument to a number, in order, as max, min and
+// hypot do before looking at any of them.
+func mathArgs(args []*runtime.Value) ([]float64, error) {
+	nums := make([]float64, len(args))
+	for i, a := range args {
+		n, err := toNumberErr(a)
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+func mathAbs(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Abs)
+}
+
+func mathCeil(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Ceil)
+}
+
+func mathFloor(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Floor)
+}
+
+// mathRound rounds halves up, toward +Infinity, unlike math.Round, and
+// keeps the sign of zero results: Math.round(-0.4) is -0.
+func mathRound(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, func(n float64) float64 {
+		if isNaN(n) || isInf(n, 0) || n == 0 {
+			return n
+		}
+		r := math.Floor(n)
+		if n-r >= 0.5 {
+			r++
+		}
+		if r == 0 && n < 0 {
+			return math.Copysign(0, -1)
+		}
+		return r
+	})
+}
+
+func mathTrunc(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Trunc)
+}
+
+func mathSign(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, func(n float64) float64 {
+		switch {
+		case n > 0:
+			return 1
+		case n < 0:
+			return -1
+		}
+		return n // NaN, +0 or -0
+	})
+}
+
+// mathMax treats +0 as larger than -0, and returns NaN if any argument is
+// NaN once all of them have been converted.
+func mathMax(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	nums, err := mathArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	result := math.Inf(-1)
+	for _, n := range nums {
+		if isNaN(n) {
+			return runtime.NaN, nil
+		}
+		if n > result || (n == 0 && result == 0 && !math.Signbit(n)) {
+			result = n
+		}
+	}
+	return runtime.NewNumber(result), nil
+}
+
+// mathMin treats -0 as smaller than +0, and returns NaN if any argument is
+// NaN once all of them have been converted.
+func mathMin(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	nums, err := mathArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	result := math.Inf(1)
+	for _, n := range nums {
+		if isNaN(n) {
+			return runtime.NaN, nil
+		}
+		if n < result || (n == 0 && result == 0 && math.Signbit(n)) {
+			result = n
+		}
+	}
+	return runtime.NewNumber(result), nil
+}
+
+// mathPow follows Number::exponentiate, which differs from math.Pow when
+// the exponent is NaN or the base is ±1 and the exponent infinite: both
+// give NaN.
+func mathPow(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	base, err := toNumberErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	exp, err := toNumberErr(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	if isNaN(exp) || (isInf(exp, 0) && math.Abs(base) == 1) {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(math.Pow(base, exp)), nil
+}
+
+func mathSqrt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Sqrt)
+}
+
+func mathCbrt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Cbrt)
+}
+
+// mathHypot returns Infinity if any argument is infinite, even when another
+// is NaN, and scales the sum so large arguments do not overflow.
+func mathHypot(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	nums, err := mathArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	largest, nan := 0.0, false
+	for _, n := range nums {
+		switch {
+		case isInf(n, 0):
+			return runtime.PosInf, nil
+		case isNaN(n):
+			nan = true
+		default:
+			largest = math.Max(largest, math.Abs(n))
+		}
+	}
+	if nan {
+		return runtime.NaN, nil
+	}
+	if largest == 0 {
+		return runtime.Zero, nil
+	}
+	sum := 0.0
+	for _, n := range nums {
+		r := n / largest
+		sum += r * r
+	}
+	return runtime.NewNumber(largest * math.Sqrt(sum)), nil
+}
+
+func mathLog(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Log)
+}
+
+func mathLog2(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Log2)
+}
+
+func mathLog10(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Log10)
+}
+
+func mathExp(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Exp)
+}
+
+func mathExpm1(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Expm1)
+}
+
+func mathLog1p(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Log1p)
+}
+
+func mathSin(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Sin)
+}
+
+func mathCos(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Cos)
+}
+
+func mathTan(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Tan)
+}
+
+func mathAsin(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Asin)
+}
+
+func mathAcos(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Acos)
+}
+
+func mathAtan(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Atan)
+}
+
+func mathAtan2(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	y, err := toNumberErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	x, err := toNumberErr(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(math.Atan2(y, x)), nil
+}
+
+func mathSinh(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Sinh)
+}
+
+func mathCosh(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Cosh)
+}
+
+func mathTanh(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Tanh)
+}
+
+func mathAsinh(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Asinh)
+}
+
+func mathAcosh(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Acosh)
+}
+
+func mathAtanh(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, math.Atanh)
+}
+
+func mathFround(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, func(n float64) float64 {
+		return float64(float32(n))
+	})
+}
+
+func mathClz32(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return mathUnary(args, func(n float64) float64 {
+		return float64(bits.LeadingZeros32(numberToUint32(n)))
+	})
+}
+
+func mathImul(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a, err := toNumberErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	b, err := toNumberErr(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(float64(int32(numberToUint32(a) * numberToUint32(b)))), nil
+}
@@ -0,0 +1,243 @@
+package builtins
+
+import (
+	"math"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestMathConstants(t *testing.T) {
+	rl := registerTestRealm()
+	objProto := runtime.NewOrdinaryObject(nil)
+	m := rl.createMathObject(objProto)
+
+	pi := m.Get("PI")
+	if pi.Number != math.Pi {
+		t.Errorf("Math.PI: expected %v, got %v", math.Pi, pi.Number)
+	}
+	e := m.Get("E")
+	if e.Number != math.E {
+		t.Errorf("Math.E: expected %v, got %v", math.E, e.Number)
+	}
+}
+
+func TestMathAbs(t *testing.T) {
+	result, _ := mathAbs(nil, []*runtime.Value{runtime.NewNumber(-5)})
+	if result.Number != 5 {
+		t.Errorf("Math.abs(-5): expected 5, got %v", result.Number)
+	}
+}
+
+func TestMathFloorCeilRound(t *testing.T) {
+	result, _ := mathFloor(nil, []*runtime.Value{runtime.NewNumber(4.7)})
+	if result.Number != 4 {
+		t.Errorf("Math.floor(4.7): expected 4, got %v", result.Number)
+	}
+	result, _ = mathCeil(nil, []*runtime.Value{runtime.NewNumber(4.1)})
+	if result.Number != 5 {
+		t.Errorf("Math.ceil(4.1): expected 5, got %v", result.Number)
+	}
+	result, _ = mathRound(nil, []*runtime.Value{runtime.NewNumber(4.5)})
+	if result.Number != 5 {
+		t.Errorf("Math.round(4.5): expected 5, got %v", result.Number)
+	}
+}
+
+func TestMathMinMax(t *testing.T) {
+	result, _ := mathMax(nil, []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(5), runtime.NewNumber(3)})
+	if result.Number != 5 {
+		t.Errorf("Math.max(1,5,3): expected 5, got %v", result.Number)
+	}
+	result, _ = mathMin(nil, []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(5), runtime.NewNumber(3)})
+	if result.Number != 1 {
+		t.Errorf("Math.min(1,5,3): expected 1, got %v", result.Number)
+	}
+}
+
+func TestMathPow(t *testing.T) {
+	result, _ := mathPow(nil, []*runtime.Value{runtime.NewNumber(2), runtime.NewNumber(10)})
+	if result.Number != 1024 {
+		t.Errorf("Math.pow(2,10): expected 1024, got %v", result.Number)
+	}
+}
+
+func TestMathSqrt(t *testing.T) {
+	result, _ := mathSqrt(nil, []*runtime.Value{runtime.NewNumber(144)})
+	if result.Number != 12 {
+		t.Errorf("Math.sqrt(144): expected 12, got %v", result.Number)
+	}
+}
+
+func TestMathSign(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want float64
+	}{
+		{5, 1},
+		{-3, -1},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		result, _ := mathSign(nil, []*runtime.Value{runtime.NewNumber(tt.in)})
+		if result.Number != tt.want {
+			t.Errorf("Math.sign(%v): expected %v, got %v", tt.in, tt.want, result.Number)
+		}
+	}
+}
+
+func TestMathTrunc(t *testing.T) {
+	result, _ := mathTrunc(nil, []*runtime.Value{runtime.NewNumber(4.9)})
+	if result.Number != 4 {
+		t.Errorf("Math.trunc(4.9): expected 4, got %v", result.Number)
+	}
+	result, _ = mathTrunc(nil, []*runtime.Value{runtime.NewNumber(-4.9)})
+	if result.Number != -4 {
+		t.Errorf("Math.trunc(-4.9): expected -4, got %v", result.Number)
+	}
+}
+
+func TestMathRandom(t *testing.T) {
+	result, _ := mathRandom(nil, nil)
+	if result.Number < 0 || result.Number >= 1 {
+		t.Errorf("Math.random(): expected [0,1), got %v", result.Number)
+	}
+}
+
+func TestMathClz32(t *testing.T) {
+	result, _ := mathClz32(nil, []*runtime.Value{runtime.NewNumber(1)})
+	if result.Number != 31 {
+		t.Errorf("Math.clz32(1): expected 31, got %v", result.Number)
+	}
+	result, _ = mathClz32(nil, []*runtime.Value{runtime.NewNumber(0)})
+	if result.Number != 32 {
+		t.Errorf("Math.clz32(0): expected 32, got %v", result.Number)
+	}
+}
+
+func TestMathImul(t *testing.T) {
+	result, _ := mathImul(nil, []*runtime.Value{runtime.NewNumber(3), runtime.NewNumber(4)})
+	if result.Number != 12 {
+		t.Errorf("Math.imul(3,4): expected 12, got %v", result.Number)
+	}
+}
+
+func TestMathHypot(t *testing.T) {
+	result, _ := mathHypot(nil, []*runtime.Value{runtime.NewNumber(3), runtime.NewNumber(4)})
+	if result.Number != 5 {
+		t.Errorf("Math.hypot(3,4): expected 5, got %v", result.Number)
+	}
+}
+
+func mathCall(t *testing.T, fn func(*runtime.Value, []*runtime.Value) (*runtime.Value, error), args ...float64) float64 {
+	t.Helper()
+	vals := make([]*runtime.Value, len(args))
+	for i, a := range args {
+		vals[i] = runtime.NewNumber(a)
+	}
+	result, err := fn(nil, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.Number
+}
+
+// sameNumber reports whether a and b are the same value, telling -0 from +0
+// and treating NaN as equal to itself.
+func sameNumber(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return a == b && math.Signbit(a) == math.Signbit(b)
+}
+
+func TestMathEdgeCases(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	inf, nan := math.Inf(1), math.NaN()
+	tests := []struct {
+		name string
+		fn   func(*runtime.Value, []*runtime.Value) (*runtime.Value, error)
+		args []float64
+		want float64
+	}{
+		{"round", mathRound, []float64{-2.5}, -2},
+		{"round", mathRound, []float64{2.5}, 3},
+		{"round", mathRound, []float64{-0.4}, negZero},
+		{"round", mathRound, []float64{0.49999999999999994}, 0},
+		{"round", mathRound, []float64{negZero}, negZero},
+		{"max", mathMax, []float64{negZero, 0}, 0},
+		{"max", mathMax, nil, math.Inf(-1)},
+		{"max", mathMax, []float64{1, nan, 3}, nan},
+		{"min", mathMin, []float64{0, negZero}, negZero},
+		{"min", mathMin, nil, inf},
+		{"pow", mathPow, []float64{1, inf}, nan},
+		{"pow", mathPow, []float64{-1, math.Inf(-1)}, nan},
+		{"pow", mathPow, []float64{1, nan}, nan},
+		{"pow", mathPow, []float64{nan, 0}, 1},
+		{"hypot", mathHypot, []float64{nan, inf}, inf},
+		{"hypot", mathHypot, []float64{nan, 1}, nan},
+		{"hypot", mathHypot, []float64{1e200, 1e200}, 1e200 * math.Sqrt2},
+		{"hypot", mathHypot, nil, 0},
+		{"sign", mathSign, []float64{negZero}, negZero},
+		{"sign", mathSign, []float64{nan}, nan},
+		{"sinh", mathSinh, []float64{negZero}, negZero},
+		{"cosh", mathCosh, []float64{0}, 1},
+		{"tanh", mathTanh, []float64{math.Inf(-1)}, -1},
+		{"asinh", mathAsinh, []float64{0}, 0},
+		{"acosh", mathAcosh, []float64{0.5}, nan},
+		{"atanh", mathAtanh, []float64{1}, inf},
+		{"fround", mathFround, []float64{5.05}, float64(float32(5.05))},
+		{"clz32", mathClz32, []float64{-1}, 0},
+		{"clz32", mathClz32, []float64{4294967297}, 31},
+		{"clz32", mathClz32, []float64{nan}, 32},
+		{"imul", mathImul, []float64{0xffffffff, 5}, -5},
+		{"imul", mathImul, []float64{1099511627779, 3}, 9},
+	}
+	for _, tt := range tests {
+		if got := mathCall(t, tt.fn, tt.args...); !sameNumber(got, tt.want) {
+			t.Errorf("Math.%s(%v): expected %v, got %v", tt.name, tt.args, tt.want, got)
+		}
+	}
+}
+
+func TestMathArgumentErrors(t *testing.T) {
+	sym := &runtime.Value{Type: runtime.TypeSymbol, Symbol: &runtime.Symbol{Description: "s"}}
+	for name, fn := range map[string]func(*runtime.Value, []*runtime.Value) (*runtime.Value, error){
+		"abs": mathAbs, "max": mathMax, "pow": mathPow, "hypot": mathHypot, "imul": mathImul,
+	} {
+		if _, err := fn(nil, []*runtime.Value{sym, runtime.NewNumber(1)}); err == nil {
+			t.Errorf("Math.%s(symbol, 1): expected a TypeError", name)
+		}
+	}
+	// max converts every argument even after seeing NaN.
+	if _, err := mathMax(nil, []*runtime.Value{runtime.NaN, sym}); err == nil {
+		t.Error("Math.max(NaN, symbol): expected a TypeError")
+	}
+}
+
+func TestSeedMathRandom(t *testing.T) {
+	sequence := func() []float64 {
+		env := runtime.NewEnvironment(nil, false)
+		rl := RegisterAll(runtime.NewRealm(runtime.NewAgent()), env, nil)
+		m, _ := env.Get("Math")
+		// Replacing the global Math does not hide the realm's.
+		env.Set("Math", runtime.Undefined)
+		rl.SeedMathRandom(7)
+		random := getCallable(m.Object.Get("random"))
+		out := make([]float64, 3)
+		for i := range out {
+			r, _ := random(runtime.Undefined, nil)
+			if r.Number < 0 || r.Number >= 1 {
+				t.Fatalf("Math.random(): expected [0,1), got %v", r.Number)
+			}
+			out[i] = r.Number
+		}
+		return out
+	}
+	a, b := sequence(), sequence()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("expected the same sequence for the same seed, got %v and %v", a, b)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createNumberConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	rl.NumberPrototype = proto
+
+	rl.setMethod(proto, "toFixed", 1, numberToFixed)
+	rl.setMethod(proto, "toPrecision", 1, numberToPrecision)
+	rl.setMethod(proto, "toExponential", 1, numberToExponential)
+	rl.setMethod(proto, "toString", 1, numberToString)
+	rl.setMethod(proto, "valueOf", 0, numberValueOf)
+
+	ctor := rl.newFuncObject("Number", 1, numberConstructorCall)
+	ctor.Constructor = numberConstruct
+
+	rl.setMethod(ctor, "isInteger", 1, numberIsInteger)
+	rl.setMethod(ctor, "isFinite", 1, numberIsFinite)
+	rl.setMethod(ctor, "isNaN", 1, numberIsNaN)
+	rl.setMethod(ctor, "isSafeInteger", 1, numberIsSafeInteger)
+	rl.setMethod(ctor, "parseInt", 2, globalParseInt)
+	rl.setMethod(ctor, "parseFloat", 1, globalParseFloat)
+
+	setConstant(ctor, "EPSILON", runtime.NewNumber(math.SmallestNonzeroFloat64*math.Pow(2, 1022)))
+	setConstant(ctor, "MAX_SAFE_INTEGER", runtime.NewNumber(9007199254740991))
+	setConstant(ctor, "MIN_SAFE_INTEGER", runtime.NewNumber(-9007199254740991))
+	setConstant(ctor, "MAX_VALUE", runtime.NewNumber(math.MaxFloat64))
+	setConstant(ctor, "MIN_VALUE", runtime.NewNumber(math.SmallestNonzeroFloat64))
+	setConstant(ctor, "NaN", runtime.NaN)
+	setConstant(ctor, "POSITIVE_INFINITY", runtime.PosInf)
+	setConstant(ctor, "NEGATIVE_INFINITY", runtime.NegInf)
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+// thisNumberValue returns the number held by a Number primitive or wrapper
+// object; the Number.prototype methods throw a TypeError for anything else.
+func thisNumberValue(this *runtime.Value, method string) (float64, error) {
+	if this != nil {
+		if this.Type == runtime.TypeNumber {
+			return this.Number, nil
+		}
+		if this.Type == runtime.TypeObject {
+			if prim, ok := runtime.PrimitiveData(this.Object); ok && prim.Type == runtime.TypeNumber {
+				return prim.Number, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("TypeError: Number.prototype.%s requires that 'this' be a Number", method)
+}
+
+func numberConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 0 {
+		return runtime.Zero, nil
+	}
+	n, err := toNumberErr(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(n), nil
+}
+
+// numberConstruct backs new Number(value): the instance created by new
+// becomes a wrapper object holding the converted number.
+func numberConstruct(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	n, err := numberConstructorCall(this, args)
+	if err != nil {
+		return nil, err
+	}
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil {
+		return n, nil
+	}
+	runtime.SetPrimitiveData(this.Object, n)
+	return this, nil
+}
+
+func numberToFixed(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	x, err := thisNumberValue(this, "toFixed")
+	if err != nil {
+		return nil, err
+	}
+	f, err := toIntegerErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	if f < 0 || f > 100 {
+		return nil, fmt.Errorf("RangeError: toFixed() digits argument must be between 0 and 100")
+	}
+	if isNaN(x) || isInf(x, 0) {
+		return runtime.NewString(runtime.NumberToString(x)), nil
+	}
+	sign := ""
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	if x >= 1e21 {
+		return runtime.NewString(sign + runtime.NumberToString(x)), nil
+	}
+	digits, point := exactDigits(x)
+	digits, point = roundDigits(digits, point, point+int(f))
+	return runtime.NewString(sign + fixedString(digits, point, int(f))), nil
+}
+
+func numberToPrecision(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	x, err := thisNumberValue(this, "toPrecision")
+	if err != nil {
+		return nil, err
+	}
+	if argAt(args, 0).Type == runtime.TypeUndefined {
+		return runtime.NewString(runtime.NumberToString(x)), nil
+	}
+	p, err := toIntegerErr(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if isNaN(x) || isInf(x, 0) {
+		return runtime.NewString(runtime.NumberToString(x)), nil
+	}
+	if p < 1 || p > 100 {
+		return nil, fmt.Errorf("RangeError: toPrecision() argument must be between 1 and 100")
+	}
+	sign := ""
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	digits, point := exactDigits(x)
+	digits, point = roundDigits(digits, point, int(p))
+	if e := point - 1; e < -6 || e >= int(p) {
+		return runtime.NewString(sign + exponentialString(digits, point)), nil
+	}
+	return runtime.NewString(sign + fixedString(digits, point, int(p)-point)), nil
+}
+
+func numberToExponential(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	x, err := thisNumberValue(this, "toExponential")
+	if err != nil {
+		return nil, err
+	}
+	f, err := toIntegerErr(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	if isNaN(x) || isInf(x, 0) {
+		return runtime.NewString(runtime.NumberToString(x)), nil
+	}
+	if f < 0 || f > 100 {
+		return nil, fmt.Errorf("RangeError: toExponential() argument must be between 0 and 100")
+	}
+	sign := ""
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	var digits []byte
+	var point int
+	if argAt(args, 0).Type == runtime.TypeUndefined {
+		// As many digits as it takes to identify x uniquely.
+		digits, point = shortestDigits(x)
+	} else {
+		digits, point = exactDigits(x)
+		digits, point = roundDigits(digits, point, int(f)+1)
+	}
+	return runtime.NewString(sign + exponentialString(digits, point)), nil
+}
+
+func numberToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	x, err := thisNumberValue(this, "toString")
+	if err != nil {
+		return nil, err
+	}
+	radix := 10.0
+	if argAt(args, 0).Type != runtime.TypeUndefined {
+		if radix, err = toIntegerErr(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if radix < 2 || radix > 36 {
+		return nil, fmt.Errorf("RangeError: toString() radix must be between 2 and 36")
+	}
+	if radix == 10 || isNaN(x) || isInf(x, 0) || x == 0 {
+		return runtime.NewString(runtime.NumberToString(x)), nil
+	}
+	return runtime.NewString(radixString(x, int(radix))), nil
+}
+
+func numberValueOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	x, err := thisNumberValue(this, "valueOf")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(x), nil
+}
+
+func numberIsInteger(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a := argAt(args, 0)
+	if a.Type != runtime.TypeNumber {
+		return runtime.False, nil
+	}
+	if isNaN(a.Number) || isInf(a.Number, 0) {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(math.Floor(a.Number) == a.Number), nil
+}
+
+func numberIsFinite(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a := argAt(args, 0)
+	if a.Type != runtime.TypeNumber {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(!isNaN(a.Number) && !isInf(a.Number, 0)), nil
+}
+
+func numberIsNaN(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a := argAt(args, 0)
+	if a.Type != runtime.TypeNumber {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(isNaN(a.Number)), nil
+}
+
+func numberIsSafeInteger(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a := argAt(args, 0)
+	if a.Type != runtime.TypeNumber {
+		return runtime.False, nil
+	}
+	if isNaN(a.Number) || isInf(a.Number, 0) {
+		return runtime.False, nil
+	}
+	if math.Floor(a.Number) != a.Number {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(math.Abs(a.Number) <= 9007199254740991), nil
+}
+
+// The formatting methods work on decimal digit strings: digits with no
+// leading zeros and a point such that the value is 0.digits × 10^point.
+
+// exactDigits returns the exact decimal expansion of the finite x > 0. A
+// float64 has at most 767 significant decimal digits, so nothing is rounded.
+func exactDigits(x float64) ([]byte, int) {
+	return decimalDigits(strconv.FormatFloat(x, 'e', 767, 64))
+}
+
+// shortestDigits returns the fewest digits that still identify x > 0.
+func shortestDigits(x float64) ([]byte, int) {
+	return decimalDigits(strconv.FormatFloat(x, 'e', -1, 64))
+}
+
+// decimalDigits splits FormatFloat's "d.ddde±XX" output.
+func decimalDigits(s string) ([]byte, int) {
+	mant, exp, _ := strings.Cut(s, "e")
+	e, _ := strconv.Atoi(exp)
+	digits := strings.TrimRight(strings.Replace(mant, ".", "", 1), "0")
+	if digits == "" {
+		return nil, 1 // zero
+	}
+	return []byte(digits), e + 1
+}
+
+// roundDigits rounds to the first n digits. Ties round up, to the larger
+// candidate, as toFixed, toExponential and toPrecision specify; an exact
+// expansion makes ties rare, unlike FormatFloat's round-half-even. A
+// negative n leaves nothing, so the value rounds to zero.
+func roundDigits(digits []byte, point, n int) ([]byte, int) {
+	if n < 0 {
+		return nil, point
+	}
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = digitAt(digits, i)
+	}
+	if n < len(digits) && digits[n] >= '5' {
+		i := n - 1
+		for ; i >= 0 && out[i] == '9'; i-- {
+			out[i] = '0'
+		}
+		if i >= 0 {
+			out[i]++
+		} else {
+			// 99.5 → 100: one more integer digit, still n digits long
+			// unless n is zero.
+			out = append([]byte{'1'}, out[:max(n-1, 0)]...)
+			point++
+		}
+	}
+	return out, point
+}
+
+// digitAt returns digit i, treating positions outside digits as zeros.
+func digitAt(digits []byte, i int) byte {
+	if i >= 0 && i < len(digits) {
+		return digits[i]
+	}
+	return '0'
+}
+
+// fixedString writes the digits in positional notation with exactly frac
+// digits after the decimal point.
+func fixedString(digits []byte, point, frac int) string {
+	var b strings.Builder
+	if point <= 0 {
+		b.WriteByte('0')
+	}
+	for i := 0; i < point; i++ {
+		b.WriteByte(digitAt(digits, i))
+	}
+	if frac > 0 {
+		b.WriteByte('.')
+		for i := point; i < point+frac; i++ {
+			b.WriteByte(digitAt(digits, i))
+		}
+	}
+	return b.String()
+}
+
+// exponentialString writes the digits as d.ddde±x, or 0e+0 for zero.
+func exponentialString(digits []byte, point int) string {
+	if len(digits) == 0 {
+		return "0e+0"
+	}
+	s := string(digits[:1])
+	if len(digits) > 1 {
+		s += "." + string(digits[1:])
+	}
+	if point-1 < 0 {
+		return s + "e-" + strconv.Itoa(1-point)
+	}
+	return s + "e+" + strconv.Itoa(point-1)
+}
+
+const radixDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// radixString formats the finite, nonzero x in radix. Fraction digits are
+// written only until they distinguish x from its neighbouring doubles, the
+// way V8 does, so (0.1).toString(2) stops rather than running to the exact
+// binary expansion.
+func radixString(x float64, radix int) string {
+	sign := ""
+	if x < 0 {
+		sign, x = "-", -x
+	}
+	integer := math.Floor(x)
+	fraction := x - integer
+	// Half the gap to the next double: digits below it are noise.
+	delta := math.Max(0.5*(math.Nextafter(x, math.Inf(1))-x), math.SmallestNonzeroFloat64)
+	var frac []byte
+	if fraction >= delta {
+		for {
+			fraction *= float64(radix)
+			delta *= float64(radix)
+			digit := int(fraction)
+			frac = append(frac, radixDigits[digit])
+			fraction -= float64(digit)
+			if (fraction > 0.5 || (fraction == 0.5 && digit&1 == 1)) && fraction+delta > 1 {
+				// Round up, dropping digits that carry over.
+				for {
+					i := len(frac) - 1
+					if i < 0 {
+						integer++
+						break
+					}
+					if d := strings.IndexByte(radixDigits, frac[i]); d+1 < radix {
+						frac[i] = radixDigits[d+1]
+						break
+					}
+					frac = frac[:i]
+				}
+				break
+			}
+			if fraction < delta {
+				break
+			}
+		}
+	}
+	n, _ := new(big.Float).SetFloat64(integer).Int(nil)
+	s := sign + n.Text(radix)
+	if len(frac) > 0 {
+		s += "." + string(frac)
+	}
+	return s
+}
@@ -0,0 +1,221 @@
+package builtins
+
+import (
+	"math"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestNumberIsInteger(t *testing.T) {
+	tests := []struct {
+		val  *runtime.Value
+		want bool
+	}{
+		{runtime.NewNumber(5), true},
+		{runtime.NewNumber(5.5), false},
+		{runtime.NewNumber(0), true},
+		{runtime.NaN, false},
+		{runtime.PosInf, false},
+		{runtime.NewString("5"), false},
+	}
+	for i, tt := range tests {
+		result, _ := numberIsInteger(runtime.Undefined, []*runtime.Value{tt.val})
+		if result.Bool != tt.want {
+			t.Errorf("test %d: Number.isInteger(%v) = %v, want %v", i, tt.val, result.Bool, tt.want)
+		}
+	}
+}
+
+func TestNumberIsFinite(t *testing.T) {
+	result, _ := numberIsFinite(runtime.Undefined, []*runtime.Value{runtime.NewNumber(42)})
+	if !result.Bool {
+		t.Error("Number.isFinite(42) should be true")
+	}
+	result, _ = numberIsFinite(runtime.Undefined, []*runtime.Value{runtime.PosInf})
+	if result.Bool {
+		t.Error("Number.isFinite(Infinity) should be false")
+	}
+}
+
+func TestNumberIsNaN(t *testing.T) {
+	result, _ := numberIsNaN(runtime.Undefined, []*runtime.Value{runtime.NaN})
+	if !result.Bool {
+		t.Error("Number.isNaN(NaN) should be true")
+	}
+	result, _ = numberIsNaN(runtime.Undefined, []*runtime.Value{runtime.NewNumber(42)})
+	if result.Bool {
+		t.Error("Number.isNaN(42) should be false")
+	}
+	result, _ = numberIsNaN(runtime.Undefined, []*runtime.Value{runtime.NewString("NaN")})
+	if result.Bool {
+		t.Error("Number.isNaN('NaN') should be false (no coercion)")
+	}
+}
+
+func TestNumberIsSafeInteger(t *testing.T) {
+	result, _ := numberIsSafeInteger(runtime.Undefined, []*runtime.Value{runtime.NewNumber(9007199254740991)})
+	if !result.Bool {
+		t.Error("Number.isSafeInteger(MAX_SAFE_INTEGER) should be true")
+	}
+	result, _ = numberIsSafeInteger(runtime.Undefined, []*runtime.Value{runtime.NewNumber(9007199254740992)})
+	if result.Bool {
+		t.Error("Number.isSafeInteger(MAX_SAFE_INTEGER+1) should be false")
+	}
+}
+
+func TestNumberToFixed(t *testing.T) {
+	this := runtime.NewNumber(3.14159)
+	result, _ := numberToFixed(this, []*runtime.Value{runtime.NewNumber(2)})
+	if result.Str != "3.14" {
+		t.Errorf("toFixed(2): expected '3.14', got %q", result.Str)
+	}
+}
+
+func TestNumberToString(t *testing.T) {
+	this := runtime.NewNumber(255)
+	result, _ := numberToString(this, []*runtime.Value{runtime.NewNumber(16)})
+	if result.Str != "ff" {
+		t.Errorf("toString(16): expected 'ff', got %q", result.Str)
+	}
+
+	this = runtime.NewNumber(10)
+	result, _ = numberToString(this, []*runtime.Value{runtime.NewNumber(2)})
+	if result.Str != "1010" {
+		t.Errorf("toString(2): expected '1010', got %q", result.Str)
+	}
+}
+
+func TestNumberConstants(t *testing.T) {
+	rl := registerTestRealm()
+	_, objProto := rl.createObjectConstructor()
+	ctor, _ := rl.createNumberConstructor(objProto)
+
+	epsilon := ctor.Get("EPSILON")
+	if epsilon.Number <= 0 {
+		t.Error("EPSILON should be positive")
+	}
+
+	maxSafe := ctor.Get("MAX_SAFE_INTEGER")
+	if maxSafe.Number != 9007199254740991 {
+		t.Errorf("MAX_SAFE_INTEGER: expected 9007199254740991, got %v", maxSafe.Number)
+	}
+
+	maxVal := ctor.Get("MAX_VALUE")
+	if maxVal.Number != math.MaxFloat64 {
+		t.Error("MAX_VALUE should be math.MaxFloat64")
+	}
+}
+
+func TestNumberFormatting(t *testing.T) {
+	tests := []struct {
+		method func(*runtime.Value, []*runtime.Value) (*runtime.Value, error)
+		name   string
+		x      float64
+		arg    *runtime.Value
+		want   string
+	}{
+		{numberToFixed, "toFixed", 0.1, runtime.NewNumber(20), "0.10000000000000000555"},
+		{numberToFixed, "toFixed", 1.005, runtime.NewNumber(2), "1.00"},
+		{numberToFixed, "toFixed", 2.5, runtime.NewNumber(0), "3"},
+		{numberToFixed, "toFixed", -1.5, runtime.NewNumber(0), "-2"},
+		{numberToFixed, "toFixed", 999.99, runtime.NewNumber(1), "1000.0"},
+		{numberToFixed, "toFixed", -0.0000001, runtime.NewNumber(2), "-0.00"},
+		{numberToFixed, "toFixed", 123.456, runtime.Undefined, "123"},
+		{numberToFixed, "toFixed", 1e21, runtime.NewNumber(2), "1e+21"},
+		{numberToExponential, "toExponential", 123.456, runtime.Undefined, "1.23456e+2"},
+		{numberToExponential, "toExponential", 1.25, runtime.NewNumber(1), "1.3e+0"},
+		{numberToExponential, "toExponential", -5e-7, runtime.NewNumber(3), "-5.000e-7"},
+		{numberToExponential, "toExponential", 0, runtime.Undefined, "0e+0"},
+		{numberToExponential, "toExponential", 0, runtime.NewNumber(2), "0.00e+0"},
+		{numberToExponential, "toExponential", math.Inf(1), runtime.NewNumber(1000), "Infinity"},
+		{numberToPrecision, "toPrecision", 123.456, runtime.NewNumber(4), "123.5"},
+		{numberToPrecision, "toPrecision", 0.000123, runtime.NewNumber(2), "0.00012"},
+		{numberToPrecision, "toPrecision", 0.00000123, runtime.NewNumber(2), "0.0000012"},
+		{numberToPrecision, "toPrecision", 1e-7, runtime.NewNumber(1), "1e-7"},
+		{numberToPrecision, "toPrecision", 123456, runtime.NewNumber(2), "1.2e+5"},
+		{numberToPrecision, "toPrecision", 99.99, runtime.NewNumber(3), "100"},
+		{numberToPrecision, "toPrecision", 0, runtime.NewNumber(3), "0.00"},
+		{numberToPrecision, "toPrecision", 1234567, runtime.Undefined, "1234567"},
+		{numberToString, "toString", 1234567, runtime.Undefined, "1234567"},
+		{numberToString, "toString", -255.5, runtime.NewNumber(16), "-ff.8"},
+		{numberToString, "toString", 0.1, runtime.NewNumber(2), "0.0001100110011001100110011001100110011001100110011001101"},
+		{numberToString, "toString", 1e21, runtime.NewNumber(16), "3635c9adc5dea00000"},
+		{numberToString, "toString", math.Copysign(0, -1), runtime.NewNumber(2), "0"},
+		{numberToString, "toString", math.NaN(), runtime.NewNumber(2), "NaN"},
+	}
+	for _, tt := range tests {
+		result, err := tt.method(runtime.NewNumber(tt.x), []*runtime.Value{tt.arg})
+		if err != nil {
+			t.Errorf("(%v).%s(%v): %v", tt.x, tt.name, tt.arg, err)
+			continue
+		}
+		if result.Str != tt.want {
+			t.Errorf("(%v).%s(%v): expected %q, got %q", tt.x, tt.name, tt.arg, tt.want, result.Str)
+		}
+	}
+}
+
+func TestNumberFormattingErrors(t *testing.T) {
+	x := runtime.NewNumber(1)
+	if _, err := numberToFixed(x, []*runtime.Value{runtime.NewNumber(101)}); err == nil {
+		t.Error("toFixed(101) should throw a RangeError")
+	}
+	if _, err := numberToPrecision(x, []*runtime.Value{runtime.NewNumber(0)}); err == nil {
+		t.Error("toPrecision(0) should throw a RangeError")
+	}
+	if _, err := numberToExponential(x, []*runtime.Value{runtime.NewNumber(-1)}); err == nil {
+		t.Error("toExponential(-1) should throw a RangeError")
+	}
+	if _, err := numberToString(x, []*runtime.Value{runtime.NewNumber(37)}); err == nil {
+		t.Error("toString(37) should throw a RangeError")
+	}
+	for _, this := range []*runtime.Value{runtime.NewString("1"), runtime.NewObject(runtime.NewOrdinaryObject(nil))} {
+		if _, err := numberValueOf(this, nil); err == nil {
+			t.Errorf("valueOf on %v should throw a TypeError", this)
+		}
+	}
+}
+
+func TestNumberConstruct(t *testing.T) {
+	instance := runtime.NewObject(runtime.NewOrdinaryObject(nil))
+	result, err := numberConstruct(instance, []*runtime.Value{runtime.NewString("2.5")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != instance {
+		t.Fatal("new Number() should return the new instance")
+	}
+	v, err := numberValueOf(instance, nil)
+	if err != nil || v.Number != 2.5 {
+		t.Errorf("new Number('2.5').valueOf(): expected 2.5, got %v (%v)", v, err)
+	}
+	s, _ := numberToFixed(instance, []*runtime.Value{runtime.NewNumber(2)})
+	if s.Str != "2.50" {
+		t.Errorf("new Number('2.5').toFixed(2): expected '2.50', got %q", s.Str)
+	}
+}
+
+func TestNumberFromString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"0x10", 16},
+		{"0b101", 5},
+		{"0O17", 15},
+		{" 12.5e1 ", 125},
+		{"1e400", math.Inf(1)},
+	}
+	for _, tt := range tests {
+		result, err := numberConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewString(tt.in)})
+		if err != nil || result.Number != tt.want {
+			t.Errorf("Number(%q) = %v, %v, want %v", tt.in, result, err, tt.want)
+		}
+	}
+	for _, in := range []string{"0x", "0b2", "-0x1", "1_0", "nan"} {
+		if result, _ := numberConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewString(in)}); !math.IsNaN(result.Number) {
+			t.Errorf("Number(%q) = %v, want NaN", in, result.Number)
+		}
+	}
+}
@@ -0,0 +1,938 @@
+package builtins
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createObjectConstructor() (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(nil)
+	rl.ObjectPrototype = proto
+
+	// Object.prototype methods
+	rl.setMethod(proto, "hasOwnProperty", 1, rl.objectProtoHasOwnProperty)
+	rl.setMethod(proto, "toString", 0, objectProtoToString)
+	rl.setMethod(proto, "valueOf", 0, objectProtoValueOf)
+	rl.setMethod(proto, "isPrototypeOf", 1, rl.objectProtoIsPrototypeOf)
+	rl.setMethod(proto, "propertyIsEnumerable", 1, rl.objectProtoPropertyIsEnumerable)
+
+	// Object constructor
+	ctor := rl.newFuncObject("Object", 1, rl.objectConstructorCall)
+	ctor.Constructor = rl.objectConstructorCall
+
+	rl.setMethod(ctor, "keys", 1, rl.objectKeys)
+	rl.setMethod(ctor, "values", 1, rl.objectValues)
+	rl.setMethod(ctor, "entries", 1, rl.objectEntries)
+	rl.setMethod(ctor, "fromEntries", 1, rl.objectFromEntries)
+	rl.setMethod(ctor, "assign", 2, rl.objectAssign)
+	rl.setMethod(ctor, "create", 2, rl.objectCreate)
+	rl.setMethod(ctor, "defineProperty", 3, rl.objectDefineProperty)
+	rl.setMethod(ctor, "defineProperties", 2, objectDefineProperties)
+	rl.setMethod(ctor, "getOwnPropertyDescriptor", 2, rl.objectGetOwnPropertyDescriptor)
+	rl.setMethod(ctor, "getOwnPropertyDescriptors", 1, rl.objectGetOwnPropertyDescriptors)
+	rl.setMethod(ctor, "getOwnPropertyNames", 1, rl.objectGetOwnPropertyNames)
+	rl.setMethod(ctor, "getOwnPropertySymbols", 1, rl.objectGetOwnPropertySymbols)
+	rl.setMethod(ctor, "hasOwn", 2, rl.objectHasOwn)
+	rl.setMethod(ctor, "getPrototypeOf", 1, rl.objectGetPrototypeOf)
+	rl.setMethod(ctor, "setPrototypeOf", 2, objectSetPrototypeOf)
+	rl.setMethod(ctor, "freeze", 1, objectFreeze)
+	rl.setMethod(ctor, "seal", 1, objectSeal)
+	rl.setMethod(ctor, "isFrozen", 1, objectIsFrozen)
+	rl.setMethod(ctor, "isSealed", 1, objectIsSealed)
+	rl.setMethod(ctor, "preventExtensions", 1, objectPreventExtensions)
+	rl.setMethod(ctor, "isExtensible", 1, objectIsExtensible)
+	rl.setMethod(ctor, "is", 2, objectIs)
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+func (rl *Realm) objectConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	arg := argAt(args, 0)
+	if arg.Type == runtime.TypeUndefined || arg.Type == runtime.TypeNull {
+		return runtime.NewObject(runtime.NewOrdinaryObject(rl.ObjectPrototype)), nil
+	}
+	obj, err := rl.ToObject(arg)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(obj), nil
+}
+
+func (rl *Realm) objectProtoHasOwnProperty(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	name, err := runtime.ToPropertyKey(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	if this != nil && this.Type == runtime.TypeString {
+		_, ok := runtime.StringOwnProperty(this.Str, name)
+		return runtime.NewBool(ok), nil
+	}
+	obj, err := rl.thisObject(this)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(obj.HasOwnProperty(name)), nil
+}
+
+func objectProtoToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil || this.Type == runtime.TypeUndefined {
+		return runtime.NewString("[object Undefined]"), nil
+	}
+	if this.Type == runtime.TypeNull {
+		return runtime.NewString("[object Null]"), nil
+	}
+	tag := "Object"
+	switch this.Type {
+	case runtime.TypeString:
+		tag = "String"
+	case runtime.TypeNumber:
+		tag = "Number"
+	case runtime.TypeBoolean:
+		tag = "Boolean"
+	}
+	if this.Type == runtime.TypeObject && this.Object != nil {
+		switch this.Object.OType {
+		case runtime.ObjTypeArray:
+			tag = "Array"
+		case runtime.ObjTypeFunction:
+			tag = "Function"
+		case runtime.ObjTypeRegExp:
+			tag = "RegExp"
+		case runtime.ObjTypeError:
+			tag = "Error"
+		case runtime.ObjTypeMap:
+			tag = "Map"
+		case runtime.ObjTypeSet:
+			tag = "Set"
+		case runtime.ObjTypeArguments:
+			tag = "Arguments"
+		case runtime.ObjTypeProxy:
+			isArray, err := runtime.IsArray(this)
+			if err != nil {
+				return nil, err
+			}
+			if isArray {
+				tag = "Array"
+			} else if this.Object.Callable != nil {
+				tag = "Function"
+			}
+		default:
+			if isDateObject(this) {
+				tag = "Date"
+			} else if prim, ok := runtime.PrimitiveData(this.Object); ok {
+				switch prim.Type {
+				case runtime.TypeString:
+					tag = "String"
+				case runtime.TypeNumber:
+					tag = "Number"
+				case runtime.TypeBoolean:
+					tag = "Boolean"
+				}
+			}
+		}
+		if ts := this.Object.Get("@@toStringTag"); ts != runtime.Undefined {
+			tag = ts.ToString()
+		}
+	}
+	return runtime.NewString("[object " + tag + "]"), nil
+}
+
+func objectProtoValueOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil {
+		return runtime.Undefined, nil
+	}
+	return this, nil
+}
+
+func (rl *Realm) objectProtoIsPrototypeOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := toObject(argAt(args, 0))
+	if target == nil {
+		return runtime.False, nil
+	}
+	obj, err := rl.thisObject(this)
+	if err != nil {
+		return nil, err
+	}
+	p := target.Prototype
+	for p != nil {
+		if p == obj {
+			return runtime.True, nil
+		}
+		p = p.Prototype
+	}
+	return runtime.False, nil
+}
+
+func (rl *Realm) objectProtoPropertyIsEnumerable(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	name, err := runtime.ToPropertyKey(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	obj, err := rl.thisObject(this)
+	if err != nil {
+		return nil, err
+	}
+	prop := runtime.OwnProperty(obj, name)
+	return runtime.NewBool(prop != nil && prop.Enumerable), nil
+}
+
+// thisObject converts the this value of an Object.prototype method to an
+// object, throwing for undefined and null as ToObject does.
+func (rl *Realm) thisObject(this *runtime.Value) (*runtime.Object, error) {
+	if this == nil {
+		this = runtime.Undefined
+	}
+	return rl.ToObject(this)
+}
+
+// Object.keys, values and entries convert a primitive argument to an
+// object, so that Object.keys("ab") lists the indices of the string.
+
+func (rl *Realm) objectKeys(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj, err := rl.ToObject(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	keys, err := rl.enumerableOwnKeys(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.Agent.ChargeSlots(len(keys)); err != nil {
+		return nil, err
+	}
+	return rl.createStringArray(keys), nil
+}
+
+func (rl *Realm) objectValues(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj, err := rl.ToObject(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	vals := []*runtime.Value{}
+	err = rl.enumerableOwnEntries(obj, func(k string, v *runtime.Value) error {
+		if err := rl.Agent.ChargeSlots(1); err != nil {
+			return err
+		}
+		vals = append(vals, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rl.createValueArray(vals), nil
+}
+
+func (rl *Realm) objectEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj, err := rl.ToObject(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	entries := []*runtime.Value{}
+	err = rl.enumerableOwnEntries(obj, func(k string, v *runtime.Value) error {
+		if err := rl.Agent.Charge(runtime.ObjectSize + 3*runtime.SlotSize); err != nil {
+			return err
+		}
+		entries = append(entries, rl.createValueArray([]*runtime.Value{runtime.NewString(k), v}))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rl.createValueArray(entries), nil
+}
+
+// objectFromEntries builds an object from an iterable of [key, value]
+// entries, as a Map or the result of Object.entries is.
+func (rl *Realm) objectFromEntries(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	iterable := argAt(args, 0)
+	if iterable.Type == runtime.TypeUndefined || iterable.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: %s is not iterable", iterable.ToString())
+	}
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	err := rl.iterate(iterable, func(entry *runtime.Value) error {
+		if entry.Type != runtime.TypeObject || entry.Object == nil {
+			return fmt.Errorf("TypeError: Iterator value %s is not an entry object", entry.ToString())
+		}
+		key, err := runtime.ToPropertyKey(elementAt(entry.Object, 0))
+		if err != nil {
+			return err
+		}
+		mergeAndDefineProperty(obj, key, &runtime.Property{Value: elementAt(entry.Object, 1), Writable: true, Enumerable: true, Configurable: true, HasValue: true})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(obj), nil
+}
+
+// objectAssign copies the enumerable own properties of each source, read
+// with their getters, onto the target through its setters. Undefined and
+// null sources are skipped, and a string source copies its characters.
+func (rl *Realm) objectAssign(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target, err := rl.ToObject(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(args); i++ {
+		if args[i].Type == runtime.TypeUndefined || args[i].Type == runtime.TypeNull {
+			continue
+		}
+		src, err := rl.ToObject(args[i])
+		if err != nil {
+			return nil, err
+		}
+		for k, p := range runtime.OwnPropertyIterator(src, runtime.EnumerableKeys) {
+			v := p.GetValue(src)
+			// Elements of an array target live in its ArrayData.
+			if idx, ok := runtime.ArrayIndex(k); ok && target.OType == runtime.ObjTypeArray && target.Properties[k] == nil {
+				if target.CanSetElement(idx) {
+					target.SetArrayElement(idx, v)
+				}
+				continue
+			}
+			if err := target.SetErr(k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return runtime.NewObject(target), nil
+}
+
+func (rl *Realm) objectCreate(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	arg := argAt(args, 0)
+	var proto *runtime.Object
+	if arg.Type == runtime.TypeObject && arg.Object != nil {
+		proto = arg.Object
+	} else if arg.Type != runtime.TypeNull {
+		return runtime.Undefined, fmt.Errorf("TypeError: Object prototype may only be an Object or null: %s", arg.ToString())
+	}
+	obj := runtime.NewOrdinaryObject(proto)
+	if props := argAt(args, 1); props.Type != runtime.TypeUndefined {
+		descs, err := rl.ToObject(props)
+		if err != nil {
+			return nil, err
+		}
+		if err := definePropertiesFromDescriptors(obj, descs); err != nil {
+			return runtime.Undefined, err
+		}
+	}
+	return runtime.NewObject(obj), nil
+}
+
+func (rl *Realm) objectDefineProperty(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	arg0 := argAt(args, 0)
+	if arg0.Type != runtime.TypeObject || arg0.Object == nil {
+		return runtime.Undefined, fmt.Errorf("TypeError: Object.defineProperty called on non-object")
+	}
+	obj := arg0.Object
+	name, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	descArg := argAt(args, 2)
+	if descArg.Type != runtime.TypeObject || descArg.Object == nil {
+		return runtime.Undefined, fmt.Errorf("TypeError: Property description must be an object")
+	}
+	desc, err := descriptorToProperty(descArg.Object)
+	if err != nil {
+		return runtime.Undefined, err
+	}
+	if obj.OType == runtime.ObjTypeProxy {
+		ok, err := rl.defineOwnProperty(obj, name, desc)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("TypeError: 'defineProperty' on proxy: trap returned falsish for property '%s'", runtime.KeyToValue(name).ToString())
+		}
+		return args[0], nil
+	}
+	if err := validateDefineOwnProperty(obj, name, desc); err != nil {
+		return runtime.Undefined, err
+	}
+	mergeAndDefineProperty(obj, name, desc)
+	return args[0], nil
+}
+
+func objectDefineProperties(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	arg0 := argAt(args, 0)
+	if arg0.Type != runtime.TypeObject || arg0.Object == nil {
+		return runtime.Undefined, fmt.Errorf("TypeError: Object.defineProperties called on non-object")
+	}
+	obj := arg0.Object
+	props := toObject(argAt(args, 1))
+	if props == nil {
+		return args[0], nil
+	}
+	if err := definePropertiesFromDescriptors(obj, props); err != nil {
+		return runtime.Undefined, err
+	}
+	return args[0], nil
+}
+
+func (rl *Realm) objectGetOwnPropertyDescriptor(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return runtime.Undefined, nil
+	}
+	name, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	prop, err := rl.getOwnProperty(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	if prop == nil {
+		return runtime.Undefined, nil
+	}
+	return rl.propertyToDescriptor(prop), nil
+}
+
+func (rl *Realm) objectGetOwnPropertyDescriptors(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return runtime.Undefined, fmt.Errorf("TypeError: Cannot convert undefined or null to object")
+	}
+	descs := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	if obj.OType != runtime.ObjTypeProxy {
+		for k, p := range runtime.OwnPropertyIterator(obj, nil) {
+			descs.Set(k, rl.propertyToDescriptor(p))
+		}
+		return runtime.NewObject(descs), nil
+	}
+	keys, err := runtime.OwnKeysErr(obj)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		p, err := rl.getOwnProperty(obj, k)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			descs.Set(k, rl.propertyToDescriptor(p))
+		}
+	}
+	return runtime.NewObject(descs), nil
+}
+
+func (rl *Realm) objectGetOwnPropertyNames(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return runtime.Undefined, fmt.Errorf("TypeError: Object.getOwnPropertyNames called on non-object")
+	}
+	keys := ownKeys(obj, runtime.StringKeys)
+	return rl.createStringArray(keys), nil
+}
+
+// objectGetOwnPropertySymbols returns the own symbol keys of an object in
+// creation order, asking a proxy's ownKeys trap.
+func (rl *Realm) objectGetOwnPropertySymbols(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj, err := rl.ToObject(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	keys, err := runtime.OwnKeysErr(obj)
+	if err != nil {
+		return nil, err
+	}
+	var syms []*runtime.Value
+	for _, key := range keys {
+		if v := runtime.KeyToValue(key); v.Type == runtime.TypeSymbol {
+			syms = append(syms, v)
+		}
+	}
+	return rl.createValueArray(syms), nil
+}
+
+// objectHasOwn is Object.prototype.hasOwnProperty with the object passed
+// as an argument; a proxy answers through its getOwnPropertyDescriptor trap.
+func (rl *Realm) objectHasOwn(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	v := argAt(args, 0)
+	if v.Type == runtime.TypeUndefined || v.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: Cannot convert undefined or null to object")
+	}
+	name, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	if v.Type == runtime.TypeString {
+		_, ok := runtime.StringOwnProperty(v.Str, name)
+		return runtime.NewBool(ok), nil
+	}
+	obj, err := rl.ToObject(v)
+	if err != nil {
+		return nil, err
+	}
+	prop, err := rl.getOwnProperty(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(prop != nil), nil
+}
+
+func (rl *Realm) objectGetPrototypeOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	v := argAt(args, 0)
+	if v.Type == runtime.TypeUndefined || v.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: Cannot convert undefined or null to object")
+	}
+	if proto := rl.PrimitivePrototype(v); proto != nil {
+		return runtime.NewObject(proto), nil
+	}
+	obj := toObject(v)
+	if obj == nil {
+		return runtime.Null, nil
+	}
+	proto, err := obj.GetPrototypeOfErr()
+	if err != nil {
+		return nil, err
+	}
+	if proto == nil {
+		return runtime.Null, nil
+	}
+	return runtime.NewObject(proto), nil
+}
+
+func objectSetPrototypeOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	if target.Type == runtime.TypeUndefined || target.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: Object.setPrototypeOf called on null or undefined")
+	}
+	var proto *runtime.Object
+	switch arg := argAt(args, 1); arg.Type {
+	case runtime.TypeNull:
+	case runtime.TypeObject:
+		proto = arg.Object
+	default:
+		return runtime.Undefined, fmt.Errorf("TypeError: Object prototype may only be an Object or null: %s", arg.ToString())
+	}
+	// The prototype of a primitive cannot change; the call does nothing.
+	obj := toObject(target)
+	if obj == nil {
+		return target, nil
+	}
+	if !obj.SetPrototypeOf(proto) {
+		if !obj.IsExtensible() {
+			return nil, fmt.Errorf("TypeError: #<Object> is not extensible")
+		}
+		return nil, fmt.Errorf("TypeError: Cyclic __proto__ value")
+	}
+	return target, nil
+}
+
+func objectFreeze(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return argAt(args, 0), nil
+	}
+	obj.Freeze()
+	return args[0], nil
+}
+
+func objectSeal(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return argAt(args, 0), nil
+	}
+	obj.Seal()
+	return args[0], nil
+}
+
+func objectPreventExtensions(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return argAt(args, 0), nil
+	}
+	ok, err := obj.PreventExtensionsErr()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("TypeError: 'preventExtensions' on proxy: trap returned falsish")
+	}
+	return args[0], nil
+}
+
+func objectIsFrozen(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return runtime.True, nil
+	}
+	return runtime.NewBool(obj.IsFrozen()), nil
+}
+
+func objectIsSealed(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return runtime.True, nil
+	}
+	return runtime.NewBool(obj.IsSealed()), nil
+}
+
+func objectIsExtensible(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(argAt(args, 0))
+	if obj == nil {
+		return runtime.False, nil
+	}
+	extensible, err := obj.IsExtensibleErr()
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(extensible), nil
+}
+
+func objectIs(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a := argAt(args, 0)
+	b := argAt(args, 1)
+	return runtime.NewBool(runtime.SameValue(a, b)), nil
+}
+
+// helpers
+
+// getEnumerableOwnKeys returns the enumerable own string keys of obj in
+// property order.
+func getEnumerableOwnKeys(obj *runtime.Object) []string {
+	return ownKeys(obj, runtime.EnumerableStringKeys)
+}
+
+// getAllOwnKeys returns every own key of obj, symbols included.
+func getAllOwnKeys(obj *runtime.Object) []string {
+	return ownKeys(obj, nil)
+}
+
+func ownKeys(obj *runtime.Object, filter runtime.PropertyFilter) []string {
+	var keys []string
+	for k := range runtime.OwnPropertyIterator(obj, filter) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (rl *Realm) createStringArray(strs []string) *runtime.Value {
+	arr := &runtime.Object{
+		OType:      runtime.ObjTypeArray,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  rl.ArrayPrototype,
+		ArrayData:  make([]*runtime.Value, len(strs)),
+	}
+	for i, s := range strs {
+		arr.ArrayData[i] = runtime.NewString(s)
+	}
+	arr.Set("length", runtime.NewNumber(float64(len(strs))))
+	return runtime.NewObject(arr)
+}
+
+func (rl *Realm) createValueArray(vals []*runtime.Value) *runtime.Value {
+	arr := &runtime.Object{
+		OType:      runtime.ObjTypeArray,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  rl.ArrayPrototype,
+		ArrayData:  vals,
+	}
+	arr.Set("length", runtime.NewNumber(float64(len(vals))))
+	return runtime.NewObject(arr)
+}
+
+func descriptorToProperty(desc *runtime.Object) (*runtime.Property, error) {
+	prop := &runtime.Property{}
+
+	hasValue := desc.HasProperty("value")
+	hasWritable := desc.HasProperty("writable")
+	hasGet := desc.HasProperty("get")
+	hasSet := desc.HasProperty("set")
+
+	// Check for mixed accessor + data descriptor (spec 8.10.5 step 9)
+	isAccessorDesc := hasGet || hasSet
+	isDataDesc := hasValue || hasWritable
+	if isAccessorDesc && isDataDesc {
+		return nil, fmt.Errorf("TypeError: Invalid property descriptor. Cannot both specify accessors and a value or writable attribute")
+	}
+
+	if hasValue {
+		v := desc.Get("value")
+		if v == nil {
+			v = runtime.Undefined
+		}
+		prop.Value = v
+		prop.HasValue = true
+	}
+	if hasWritable {
+		v := desc.Get("writable")
+		if v != nil {
+			prop.Writable = v.ToBoolean()
+		}
+		prop.HasWritable = true
+	}
+	if desc.HasProperty("enumerable") {
+		v := desc.Get("enumerable")
+		if v != nil {
+			prop.Enumerable = v.ToBoolean()
+		}
+		prop.HasEnumerable = true
+	}
+	if desc.HasProperty("configurable") {
+		v := desc.Get("configurable")
+		if v != nil {
+			prop.Configurable = v.ToBoolean()
+		}
+		prop.HasConfigurable = true
+	}
+	if hasGet {
+		v := desc.Get("get")
+		if v == nil {
+			v = runtime.Undefined
+		}
+		if v != runtime.Undefined {
+			if v.Type != runtime.TypeObject || v.Object == nil || v.Object.Callable == nil {
+				return nil, fmt.Errorf("TypeError: Getter must be a function")
+			}
+			prop.Getter = v
+		}
+		prop.IsAccessor = true
+		prop.HasGet = true
+	}
+	if hasSet {
+		v := desc.Get("set")
+		if v == nil {
+			v = runtime.Undefined
+		}
+		if v != runtime.Undefined {
+			if v.Type != runtime.TypeObject || v.Object == nil || v.Object.Callable == nil {
+				return nil, fmt.Errorf("TypeError: Setter must be a function")
+			}
+			prop.Setter = v
+		}
+		prop.IsAccessor = true
+		prop.HasSet = true
+	}
+	return prop, nil
+}
+
+// mergeAndDefineProperty merges a new property descriptor with an existing one
+// (if any) and sets the result on the object. Implements ES5 8.12.9 steps 4-12.
+func mergeAndDefineProperty(obj *runtime.Object, name string, desc *runtime.Property) {
+	if obj.OType == runtime.ObjTypeArray {
+		if i, ok := runtime.ArrayIndex(name); ok {
+			// An element is merged as a property of its own, leaving a hole
+			// in ArrayData, and the length grows past a new one.
+			if v, ok := obj.ArrayElement(i); ok && i < len(obj.ArrayData) && obj.ArrayData[i] != nil {
+				obj.ArrayData[i] = nil
+				obj.DefineProperty(name, &runtime.Property{Value: v, Writable: true, Enumerable: true, Configurable: true})
+			}
+			if i >= obj.ArrayLength() {
+				defer obj.SetArrayLength(i + 1)
+			}
+		} else if name == "length" && desc.HasValue {
+			// Validated by validateDefineOwnProperty.
+			n, _ := runtime.ToArrayLength(desc.Value)
+			obj.SetArrayLength(n)
+			desc.Value = runtime.NewNumber(float64(obj.ArrayLength()))
+		}
+	}
+	current, exists := obj.Properties[name]
+	if !exists {
+		// New property: fill in defaults for unspecified attributes
+		prop := &runtime.Property{}
+		if desc.IsAccessor {
+			prop.IsAccessor = true
+			prop.Getter = desc.Getter
+			prop.Setter = desc.Setter
+		} else {
+			if desc.HasValue {
+				prop.Value = desc.Value
+			} else {
+				prop.Value = runtime.Undefined
+			}
+			prop.Writable = desc.Writable
+		}
+		prop.Enumerable = desc.Enumerable
+		prop.Configurable = desc.Configurable
+		obj.DefineProperty(name, prop)
+		return
+	}
+
+	// Existing property: merge only the specified attributes from desc into current.
+	if desc.IsAccessor && !current.IsAccessor {
+		// Converting data to accessor
+		current.IsAccessor = true
+		current.Value = nil
+		current.Writable = false
+		if desc.HasGet {
+			current.Getter = desc.Getter
+		}
+		if desc.HasSet {
+			current.Setter = desc.Setter
+		}
+	} else if !desc.IsAccessor && current.IsAccessor && (desc.HasValue || desc.HasWritable) {
+		// Converting accessor to data
+		current.IsAccessor = false
+		current.Getter = nil
+		current.Setter = nil
+		if desc.HasValue {
+			current.Value = desc.Value
+		} else {
+			current.Value = runtime.Undefined
+		}
+		if desc.HasWritable {
+			current.Writable = desc.Writable
+		} else {
+			current.Writable = false
+		}
+	} else if desc.IsAccessor {
+		// Both accessor: update only specified get/set
+		if desc.HasGet {
+			current.Getter = desc.Getter
+		}
+		if desc.HasSet {
+			current.Setter = desc.Setter
+		}
+	} else {
+		// Both data: update only specified value/writable
+		if desc.HasValue {
+			current.Value = desc.Value
+		}
+		if desc.HasWritable {
+			current.Writable = desc.Writable
+		}
+	}
+	if desc.HasEnumerable {
+		current.Enumerable = desc.Enumerable
+	}
+	if desc.HasConfigurable {
+		current.Configurable = desc.Configurable
+	}
+	current.HasValue = desc.HasValue
+	obj.DefineProperty(name, current)
+}
+
+// validateDefineOwnProperty implements the [[DefineOwnProperty]] validation
+// per ES5 8.12.9. It checks if redefining a property is allowed.
+func validateDefineOwnProperty(obj *runtime.Object, name string, desc *runtime.Property) error {
+	if obj.OType == runtime.ObjTypeArray && name == "length" && desc.HasValue {
+		if _, err := runtime.ToArrayLength(desc.Value); err != nil {
+			return err
+		}
+	}
+	current := obj.Properties[name]
+	return validateRedefinition(name, current, obj.IsExtensible(), desc)
+}
+
+// validateRedefinition checks that desc may be defined over current, the
+// own property name of an object, or nil if it has none.
+func validateRedefinition(name string, current *runtime.Property, extensible bool, desc *runtime.Property) error {
+	if current == nil {
+		if !extensible {
+			return fmt.Errorf("TypeError: Cannot define property %s, object is not extensible", name)
+		}
+		return nil
+	}
+
+	// If current property is configurable, any change is allowed
+	if current.Configurable {
+		return nil
+	}
+
+	// Current is non-configurable.
+
+	// Can't make it configurable (step 7a)
+	if desc.HasConfigurable && desc.Configurable {
+		return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+	}
+
+	// Check enumerable change on non-configurable (step 7b)
+	if desc.HasEnumerable && desc.Enumerable != current.Enumerable {
+		return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+	}
+
+	// Generic descriptor (no value/writable/get/set) - allowed on non-configurable
+	isGenericDesc := !desc.IsAccessor && !desc.HasValue && !desc.HasWritable
+
+	if !isGenericDesc {
+		// If changing between accessor and data descriptor types (step 9)
+		if desc.IsAccessor != current.IsAccessor {
+			// Changing data to accessor or accessor to data on non-configurable
+			if desc.IsAccessor || (desc.HasValue || desc.HasWritable) {
+				return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+			}
+		}
+
+		if current.IsAccessor && desc.IsAccessor {
+			// For accessor properties: can't change get or set on non-configurable (step 11)
+			if desc.HasGet && desc.Getter != current.Getter {
+				return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+			}
+			if desc.HasSet && desc.Setter != current.Setter {
+				return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+			}
+		} else if !current.IsAccessor && !desc.IsAccessor {
+			// For data properties (step 10)
+			if !current.Writable {
+				// Can't change writable from false to true on non-configurable
+				if desc.HasWritable && desc.Writable {
+					return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+				}
+				// Can't change value on non-writable non-configurable
+				if desc.HasValue && !runtime.SameValue(desc.Value, current.Value) {
+					return fmt.Errorf("TypeError: Cannot redefine property: %s", name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rl *Realm) propertyToDescriptor(prop *runtime.Property) *runtime.Value {
+	desc := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	if prop.IsAccessor {
+		if prop.Getter != nil {
+			desc.Set("get", prop.Getter)
+		} else {
+			desc.Set("get", runtime.Undefined)
+		}
+		if prop.Setter != nil {
+			desc.Set("set", prop.Setter)
+		} else {
+			desc.Set("set", runtime.Undefined)
+		}
+	} else {
+		desc.Set("value", prop.Value)
+		desc.Set("writable", runtime.NewBool(prop.Writable))
+	}
+	desc.Set("enumerable", runtime.NewBool(prop.Enumerable))
+	desc.Set("configurable", runtime.NewBool(prop.Configurable))
+	return runtime.NewObject(desc)
+}
+
+func definePropertiesFromDescriptors(obj *runtime.Object, descs *runtime.Object) error {
+	// All descriptors are read and checked before any property is defined.
+	type pending struct {
+		key  string
+		prop *runtime.Property
+	}
+	var props []pending
+	for k, p := range runtime.OwnPropertyIterator(descs, runtime.EnumerableKeys) {
+		descVal := p.GetValue(descs)
+		if descVal.Type != runtime.TypeObject || descVal.Object == nil {
+			return fmt.Errorf("TypeError: Property description must be an object: %s", descVal.ToString())
+		}
+		prop, err := descriptorToProperty(descVal.Object)
+		if err != nil {
+			return err
+		}
+		props = append(props, pending{k, prop})
+	}
+	for _, p := range props {
+		if err := validateDefineOwnProperty(obj, p.key, p.prop); err != nil {
+			return err
+		}
+		mergeAndDefineProperty(obj, p.key, p.prop)
+	}
+	return nil
+}
@@ -0,0 +1,450 @@
+package builtins
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupObject() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	return rl
+}
+
+func TestObjectKeys(t *testing.T) {
+	rl := setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("a", runtime.NewNumber(1))
+	obj.Set("b", runtime.NewNumber(2))
+
+	result, err := rl.objectKeys(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := toObject(result)
+	if arr == nil || len(arr.ArrayData) != 2 {
+		t.Fatalf("expected 2 keys, got %v", arr)
+	}
+}
+
+func TestObjectKeysOrder(t *testing.T) {
+	rl := setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	for _, k := range []string{"b", "10", "a", "@@sym(s)@1", "2"} {
+		obj.Set(k, runtime.NewNumber(1))
+	}
+	obj.DefineProperty("hidden", &runtime.Property{Value: runtime.NewNumber(1)})
+	obj.Set("b", runtime.NewNumber(2)) // redefining keeps the original position
+
+	result, err := rl.objectKeys(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, v := range toObject(result).ArrayData {
+		keys = append(keys, v.Str)
+	}
+	if got := strings.Join(keys, ","); got != "2,10,b,a" {
+		t.Errorf("Object.keys: got %q, want %q", got, "2,10,b,a")
+	}
+
+	result, err = rl.objectGetOwnPropertyNames(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(toObject(result).ArrayData); n != 5 {
+		t.Errorf("Object.getOwnPropertyNames: got %d keys, want 5", n)
+	}
+
+	arr := rl.newArray([]*runtime.Value{runtime.NewString("x"), runtime.NewString("y")})
+	result, err = rl.objectEntries(runtime.Undefined, []*runtime.Value{runtime.NewObject(arr)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := toObject(result).ArrayData
+	if len(entries) != 2 || entries[1].Object.ArrayData[0].Str != "1" || entries[1].Object.ArrayData[1].Str != "y" {
+		t.Errorf("Object.entries of an array: got %v", entries)
+	}
+}
+
+func TestObjectValues(t *testing.T) {
+	rl := setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(10))
+	obj.Set("y", runtime.NewNumber(20))
+
+	result, err := rl.objectValues(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := toObject(result)
+	if arr == nil || len(arr.ArrayData) != 2 {
+		t.Fatalf("expected 2 values, got %v", arr)
+	}
+}
+
+func TestObjectAssign(t *testing.T) {
+	rl := setupObject()
+	target := runtime.NewOrdinaryObject(nil)
+	target.Set("a", runtime.NewNumber(1))
+	source := runtime.NewOrdinaryObject(nil)
+	source.Set("b", runtime.NewNumber(2))
+	source.Set("c", runtime.NewNumber(3))
+
+	result, err := rl.objectAssign(runtime.Undefined, []*runtime.Value{
+		runtime.NewObject(target),
+		runtime.NewObject(source),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	if obj.Get("a").Number != 1 || obj.Get("b").Number != 2 || obj.Get("c").Number != 3 {
+		t.Error("Object.assign did not merge properties correctly")
+	}
+}
+
+func TestObjectFreezeSeal(t *testing.T) {
+	setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(42))
+	val := runtime.NewObject(obj)
+
+	objectFreeze(runtime.Undefined, []*runtime.Value{val})
+	frozen, _ := objectIsFrozen(runtime.Undefined, []*runtime.Value{val})
+	if !frozen.Bool {
+		t.Error("expected frozen")
+	}
+
+	obj2 := runtime.NewOrdinaryObject(nil)
+	obj2.Set("y", runtime.NewNumber(1))
+	val2 := runtime.NewObject(obj2)
+	objectSeal(runtime.Undefined, []*runtime.Value{val2})
+	sealed, _ := objectIsSealed(runtime.Undefined, []*runtime.Value{val2})
+	if !sealed.Bool {
+		t.Error("expected sealed")
+	}
+}
+
+func TestObjectPreventExtensions(t *testing.T) {
+	setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	val := runtime.NewObject(obj)
+	if r, _ := objectIsExtensible(runtime.Undefined, []*runtime.Value{val}); !r.Bool {
+		t.Error("a new object is extensible")
+	}
+	objectPreventExtensions(runtime.Undefined, []*runtime.Value{val})
+	if r, _ := objectIsExtensible(runtime.Undefined, []*runtime.Value{val}); r.Bool {
+		t.Error("expected non-extensible")
+	}
+	// An empty non-extensible object is frozen as well as sealed.
+	if r, _ := objectIsFrozen(runtime.Undefined, []*runtime.Value{val}); !r.Bool {
+		t.Error("expected frozen")
+	}
+
+	desc := runtime.NewOrdinaryObject(nil)
+	desc.Set("value", runtime.NewNumber(1))
+	_, err := registerTestRealm().objectDefineProperty(runtime.Undefined, []*runtime.Value{val, runtime.NewString("x"), runtime.NewObject(desc)})
+	if err == nil || !strings.Contains(err.Error(), "not extensible") {
+		t.Errorf("defineProperty on a non-extensible object: got %v", err)
+	}
+	// Primitives are returned unchanged and report non-extensible.
+	if r, _ := objectIsExtensible(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)}); r.Bool {
+		t.Error("primitives are not extensible")
+	}
+}
+
+func TestObjectGetOwnPropertyDescriptors(t *testing.T) {
+	rl := setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("b", runtime.NewNumber(1))
+	setDataProp(obj, "a", runtime.NewNumber(2), false, false, true)
+	result, err := rl.objectGetOwnPropertyDescriptors(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	descs := toObject(result)
+	if got := strings.Join(runtime.OwnKeys(descs), ","); got != "b,a" {
+		t.Errorf("keys: got %s, want b,a", got)
+	}
+	a := toObject(descs.Get("a"))
+	if a.Get("value").Number != 2 || a.Get("writable").Bool || a.Get("enumerable").Bool || !a.Get("configurable").Bool {
+		t.Error("descriptor of a does not match its attributes")
+	}
+
+	arr := rl.createValueArray([]*runtime.Value{runtime.NewNumber(7)})
+	objectFreeze(runtime.Undefined, []*runtime.Value{arr})
+	elem, _ := rl.objectGetOwnPropertyDescriptor(runtime.Undefined, []*runtime.Value{arr, runtime.NewString("0")})
+	if d := toObject(elem); d == nil || d.Get("value").Number != 7 || d.Get("writable").Bool {
+		t.Error("descriptor of a frozen array element")
+	}
+}
+
+func TestObjectDefinePropertiesValidatesFirst(t *testing.T) {
+	setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	good := runtime.NewOrdinaryObject(nil)
+	good.Set("value", runtime.NewNumber(1))
+	props := runtime.NewOrdinaryObject(nil)
+	props.Set("a", runtime.NewObject(good))
+	props.Set("b", runtime.NewNumber(1))
+	if _, err := objectDefineProperties(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewObject(props)}); err == nil {
+		t.Fatal("expected a TypeError for a non-object descriptor")
+	}
+	if obj.HasOwnProperty("a") {
+		t.Error("no property is defined when a descriptor is invalid")
+	}
+}
+
+func TestObjectIs(t *testing.T) {
+	sym := &runtime.Value{Type: runtime.TypeSymbol, Symbol: &runtime.Symbol{Description: "s"}}
+	tests := []struct {
+		a, b *runtime.Value
+		want bool
+	}{
+		{runtime.NaN, runtime.NaN, true},
+		{runtime.Zero, runtime.NewNumber(-0.0), true}, // both are 0, but +0/-0 check
+		{runtime.NewNumber(1), runtime.NewNumber(1), true},
+		{runtime.NewString("a"), runtime.NewString("a"), true},
+		{runtime.NewString("a"), runtime.NewString("b"), false},
+		{runtime.Null, runtime.Null, true},
+		{runtime.Undefined, runtime.Undefined, true},
+		{runtime.Null, runtime.Undefined, false},
+		{sym, sym, true},
+		{sym, &runtime.Value{Type: runtime.TypeSymbol, Symbol: &runtime.Symbol{Description: "s"}}, false},
+	}
+	for i, tt := range tests {
+		result, _ := objectIs(runtime.Undefined, []*runtime.Value{tt.a, tt.b})
+		if result.Bool != tt.want {
+			t.Errorf("test %d: Object.is(%v, %v) = %v, want %v", i, tt.a, tt.b, result.Bool, tt.want)
+		}
+	}
+}
+
+func TestObjectCreate(t *testing.T) {
+	rl := setupObject()
+	proto := runtime.NewOrdinaryObject(nil)
+	proto.Set("hello", runtime.NewString("world"))
+	result, err := rl.objectCreate(runtime.Undefined, []*runtime.Value{runtime.NewObject(proto)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	if obj.Prototype != proto {
+		t.Error("prototype not set correctly")
+	}
+	if obj.Get("hello").Str != "world" {
+		t.Error("prototype lookup failed")
+	}
+}
+
+func TestObjectHasOwnProperty(t *testing.T) {
+	rl := setupObject()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(1))
+	thisVal := runtime.NewObject(obj)
+
+	result, _ := rl.objectProtoHasOwnProperty(thisVal, []*runtime.Value{runtime.NewString("x")})
+	if !result.Bool {
+		t.Error("expected true for own property")
+	}
+	result, _ = rl.objectProtoHasOwnProperty(thisVal, []*runtime.Value{runtime.NewString("y")})
+	if result.Bool {
+		t.Error("expected false for non-existent property")
+	}
+}
+
+func TestObjectHasOwnAndSymbols(t *testing.T) {
+	rl := setupObject()
+	sym := &runtime.Symbol{Description: "s"}
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(1))
+	obj.Set(sym.Key(), runtime.NewNumber(2))
+	objVal := runtime.NewObject(obj)
+
+	symVal := &runtime.Value{Type: runtime.TypeSymbol, Symbol: sym}
+	for _, key := range []*runtime.Value{runtime.NewString("x"), symVal} {
+		result, err := rl.objectHasOwn(runtime.Undefined, []*runtime.Value{objVal, key})
+		if err != nil || !result.Bool {
+			t.Errorf("Object.hasOwn(obj, %s) = %v, %v; want true", key.ToString(), result, err)
+		}
+	}
+	result, _ := rl.objectHasOwn(runtime.Undefined, []*runtime.Value{objVal, runtime.NewString("y")})
+	if result.Bool {
+		t.Error("expected false for non-existent property")
+	}
+	if _, err := rl.objectHasOwn(runtime.Undefined, []*runtime.Value{runtime.Undefined}); err == nil || !strings.HasPrefix(err.Error(), "TypeError") {
+		t.Errorf("Object.hasOwn(undefined): got error %v, want a TypeError", err)
+	}
+
+	result, err := rl.objectGetOwnPropertySymbols(runtime.Undefined, []*runtime.Value{objVal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syms := toObject(result).ArrayData; len(syms) != 1 || syms[0].Symbol != sym {
+		t.Errorf("Object.getOwnPropertySymbols: got %v, want [Symbol(s)]", syms)
+	}
+}
+
+func TestObjectKeysOfPrimitives(t *testing.T) {
+	rl := setupObject()
+	result, err := rl.objectKeys(runtime.Undefined, []*runtime.Value{runtime.NewString("ab")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := toObject(result).ArrayData; len(keys) != 2 || keys[0].Str != "0" || keys[1].Str != "1" {
+		t.Errorf(`Object.keys("ab") = %v`, keys)
+	}
+	result, err = rl.objectValues(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)})
+	if err != nil || len(toObject(result).ArrayData) != 0 {
+		t.Errorf("Object.values(1) = %v, %v", result, err)
+	}
+	for _, fn := range []runtime.CallableFunc{rl.objectKeys, rl.objectValues, rl.objectEntries, rl.objectAssign} {
+		if _, err := fn(runtime.Undefined, []*runtime.Value{runtime.Null}); err == nil || !strings.HasPrefix(err.Error(), "TypeError") {
+			t.Errorf("null argument: got error %v, want a TypeError", err)
+		}
+	}
+}
+
+func TestObjectAssignSources(t *testing.T) {
+	rl := setupArray()
+	target := rl.newArray([]*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(2)})
+	source := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	source.Set("1", runtime.NewString("x"))
+	source.DefineProperty("hidden", &runtime.Property{Value: runtime.True, HasValue: true})
+	_, err := rl.objectAssign(runtime.Undefined, []*runtime.Value{
+		runtime.NewObject(target), runtime.NewObject(source), runtime.Null, runtime.NewString("z"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := target.ArrayData; len(got) != 2 || got[0].Str != "z" || got[1].Str != "x" {
+		t.Errorf("assigned array = %v", got)
+	}
+	if target.HasOwnProperty("hidden") {
+		t.Error("non-enumerable properties should not be copied")
+	}
+
+	errorSetter := rl.newFuncObject("set", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, errors.New("RangeError: no")
+	})
+	guarded := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	guarded.DefineProperty("a", &runtime.Property{Setter: runtime.NewObject(errorSetter), IsAccessor: true})
+	if _, err := rl.objectAssign(runtime.Undefined, []*runtime.Value{runtime.NewObject(guarded), runtime.NewObject(source)}); err != nil {
+		t.Errorf("a source without a: unexpected error %v", err)
+	}
+	source.Set("a", runtime.NewNumber(1))
+	if _, err := rl.objectAssign(runtime.Undefined, []*runtime.Value{runtime.NewObject(guarded), runtime.NewObject(source)}); err == nil || err.Error() != "RangeError: no" {
+		t.Errorf("the setter's error: got %v", err)
+	}
+}
+
+func TestObjectFromEntries(t *testing.T) {
+	rl := registerTestRealm()
+	entry := func(k, v *runtime.Value) *runtime.Value {
+		return runtime.NewObject(rl.newArray([]*runtime.Value{k, v}))
+	}
+	entries := rl.newArray([]*runtime.Value{
+		entry(runtime.NewString("a"), runtime.NewNumber(1)),
+		entry(runtime.NewNumber(2), runtime.NewNumber(2)),
+		entry(runtime.NewString("a"), runtime.NewNumber(3)),
+	})
+	result, err := rl.objectFromEntries(runtime.Undefined, []*runtime.Value{runtime.NewObject(entries)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	if obj.Prototype != rl.ObjectPrototype || obj.Get("a").Number != 3 || obj.Get("2").Number != 2 {
+		t.Errorf("Object.fromEntries = %v", obj.Properties)
+	}
+	if keys := ownKeys(obj, nil); strings.Join(keys, ",") != "2,a" {
+		t.Errorf("keys = %v", keys)
+	}
+
+	bad := rl.newArray([]*runtime.Value{runtime.NewNumber(1)})
+	if _, err := rl.objectFromEntries(runtime.Undefined, []*runtime.Value{runtime.NewObject(bad)}); err == nil || !strings.Contains(err.Error(), "not an entry object") {
+		t.Errorf("a number entry: got %v", err)
+	}
+	if _, err := rl.objectFromEntries(runtime.Undefined, nil); err == nil || !strings.Contains(err.Error(), "not iterable") {
+		t.Errorf("no argument: got %v", err)
+	}
+}
+
+func TestObjectSetPrototypeOf(t *testing.T) {
+	rl := setupObject()
+	a := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	b := runtime.NewOrdinaryObject(a)
+	setProto := func(obj, proto *runtime.Value) error {
+		_, err := objectSetPrototypeOf(runtime.Undefined, []*runtime.Value{obj, proto})
+		return err
+	}
+	if err := setProto(runtime.NewObject(a), runtime.NewObject(b)); err == nil || !strings.Contains(err.Error(), "Cyclic") {
+		t.Errorf("a cycle: got %v", err)
+	}
+	if a.Prototype != rl.ObjectPrototype {
+		t.Error("a failed call should keep the prototype")
+	}
+	if err := setProto(runtime.NewObject(b), runtime.Null); err != nil || b.Prototype != nil {
+		t.Errorf("null prototype: %v", err)
+	}
+	b.PreventExtensions()
+	if err := setProto(runtime.NewObject(b), runtime.NewObject(a)); err == nil || !strings.Contains(err.Error(), "not extensible") {
+		t.Errorf("a non-extensible object: got %v", err)
+	}
+	if err := setProto(runtime.NewObject(b), runtime.Null); err != nil {
+		t.Errorf("keeping the prototype of a non-extensible object: %v", err)
+	}
+	if err := setProto(runtime.NewNumber(1), runtime.Null); err != nil {
+		t.Errorf("a primitive: %v", err)
+	}
+	if err := setProto(runtime.Undefined, runtime.Null); err == nil {
+		t.Error("undefined should throw")
+	}
+	if err := setProto(runtime.NewObject(a), runtime.NewNumber(1)); err == nil {
+		t.Error("a number prototype should throw")
+	}
+}
+
+func TestObjectPrototypePredicates(t *testing.T) {
+	rl := setupArray()
+	sym := &runtime.Value{Type: runtime.TypeSymbol, Symbol: &runtime.Symbol{Description: "s"}}
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	obj.Set(sym.Symbol.Key(), runtime.NewNumber(1))
+	arr := runtime.NewObject(rl.newArray([]*runtime.Value{runtime.NewNumber(1)}))
+	call := func(fn runtime.CallableFunc, this *runtime.Value, arg *runtime.Value) bool {
+		t.Helper()
+		v, err := fn(this, []*runtime.Value{arg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.Bool
+	}
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"symbol hasOwnProperty", call(rl.objectProtoHasOwnProperty, runtime.NewObject(obj), sym), true},
+		{"symbol propertyIsEnumerable", call(rl.objectProtoPropertyIsEnumerable, runtime.NewObject(obj), sym), true},
+		{"element hasOwnProperty", call(rl.objectProtoHasOwnProperty, arr, runtime.NewNumber(0)), true},
+		{"hole hasOwnProperty", call(rl.objectProtoHasOwnProperty, arr, runtime.NewNumber(1)), false},
+		{"element propertyIsEnumerable", call(rl.objectProtoPropertyIsEnumerable, arr, runtime.NewString("0")), true},
+		{"length propertyIsEnumerable", call(rl.objectProtoPropertyIsEnumerable, arr, runtime.NewString("length")), false},
+		{"isPrototypeOf", call(rl.objectProtoIsPrototypeOf, runtime.NewObject(rl.ObjectPrototype), arr), true},
+		{"isPrototypeOf itself", call(rl.objectProtoIsPrototypeOf, arr, arr), false},
+		{"isPrototypeOf a primitive", call(rl.objectProtoIsPrototypeOf, runtime.Null, runtime.NewNumber(1)), false},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	for _, fn := range []runtime.CallableFunc{rl.objectProtoHasOwnProperty, rl.objectProtoPropertyIsEnumerable, rl.objectProtoIsPrototypeOf} {
+		if _, err := fn(runtime.Undefined, []*runtime.Value{arr}); err == nil {
+			t.Error("an undefined this should throw")
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package builtins
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+const (
+	promisePending   = 0
+	promiseFulfilled = 1
+	promiseRejected  = 2
+)
+
+func (rl *Realm) createPromiseConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	proto.OType = runtime.ObjTypePromise
+	rl.PromisePrototype = proto
+
+	rl.setMethod(proto, "then", 2, rl.promiseThen)
+	rl.setMethod(proto, "catch", 1, promiseCatch)
+	rl.setMethod(proto, "finally", 1, rl.promiseFinally)
+	setDataProp(proto, "@@toStringTag", runtime.NewString("Promise"), false, false, true)
+
+	ctor := rl.newFuncObject("Promise", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Promise constructor cannot be invoked without 'new'")
+	})
+	ctor.Constructor = rl.promiseConstructorCall
+
+	rl.setMethod(ctor, "resolve", 1, rl.promiseResolve)
+	rl.setMethod(ctor, "reject", 1, rl.promiseReject)
+	rl.setMethod(ctor, "all", 1, rl.promiseAll)
+	rl.setMethod(ctor, "race", 1, rl.promiseRace)
+	rl.setMethod(ctor, "allSettled", 1, rl.promiseAllSettled)
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+// promiseReaction is a handler registered with then(). The derived promise is
+// settled with the handler's outcome when the reaction job runs.
+type promiseReaction struct {
+	handler *runtime.Value
+	derived *promiseData
+}
+
+type promiseData struct {
+	realm            *Realm
+	state            int
+	result           *runtime.Value
+	fulfillReactions []*promiseReaction
+	rejectReactions  []*promiseReaction
+	self             *runtime.Object
+	alreadyResolved  bool
+}
+
+func getPromiseData(obj *runtime.Object) *promiseData {
+	if obj == nil || obj.Internal == nil {
+		return nil
+	}
+	pd, _ := obj.Internal["promise"].(*promiseData)
+	return pd
+}
+
+func (rl *Realm) newPromiseObject() (*runtime.Object, *promiseData) {
+	pd := &promiseData{
+		realm:  rl,
+		state:  promisePending,
+		result: runtime.Undefined,
+	}
+	obj := &runtime.Object{
+		OType:      runtime.ObjTypePromise,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  rl.PromisePrototype,
+		Internal:   map[string]interface{}{"promise": pd},
+	}
+	pd.self = obj
+	return obj, pd
+}
+
+// resolvePromise implements the promise resolve function: thenables are
+// adopted in a later job, anything else fulfills the promise. Only the first
+// call to resolvePromise or rejectPromise has any effect.
+func resolvePromise(pd *promiseData, val *runtime.Value) {
+	if pd.alreadyResolved {
+		return
+	}
+	pd.alreadyResolved = true
+	pd.adopt(val)
+}
+
+func rejectPromise(pd *promiseData, reason *runtime.Value) {
+	if pd.alreadyResolved {
+		return
+	}
+	pd.alreadyResolved = true
+	pd.settle(promiseRejected, reason)
+}
+
+func (pd *promiseData) adopt(val *runtime.Value) {
+	if val.Type != runtime.TypeObject || val.Object == nil {
+		pd.settle(promiseFulfilled, val)
+		return
+	}
+	if val.Object == pd.self {
+		pd.settle(promiseRejected, pd.realm.errorToValue(fmt.Errorf("TypeError: Chaining cycle detected for promise")))
+		return
+	}
+	then := getCallable(val.Object.Get("then"))
+	if then == nil {
+		pd.settle(promiseFulfilled, val)
+		return
+	}
+	pd.realm.Agent.Events.EnqueueJob(func() error {
+		// Resolving functions passed to the thenable get their own
+		// already-resolved flag, separate from the outer promise's.
+		resolved := false
+		resolveFn := pd.realm.newFuncObject("", 1, func(_ *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			if !resolved {
+				resolved = true
+				pd.adopt(argAt(args, 0))
+			}
+			return runtime.Undefined, nil
+		})
+		rejectFn := pd.realm.newFuncObject("", 1, func(_ *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			if !resolved {
+				resolved = true
+				pd.settle(promiseRejected, argAt(args, 0))
+			}
+			return runtime.Undefined, nil
+		})
+		_, err := then(val, []*runtime.Value{runtime.NewObject(resolveFn), runtime.NewObject(rejectFn)})
+		if err != nil && !resolved {
+			resolved = true
+			pd.settle(promiseRejected, pd.realm.errorToValue(err))
+		}
+		return nil
+	})
+}
+
+// settle moves a pending promise to its final state and schedules the
+// reactions registered so far.
+func (pd *promiseData) settle(state int, val *runtime.Value) {
+	if pd.state != promisePending {
+		return
+	}
+	reactions := pd.fulfillReactions
+	if state == promiseRejected {
+		reactions = pd.rejectReactions
+	}
+	pd.state = state
+	pd.result = val
+	pd.fulfillReactions = nil
+	pd.rejectReactions = nil
+	for _, r := range reactions {
+		pd.realm.enqueueReaction(r, state, val)
+	}
+}
+
+// enqueueReaction schedules a promise reaction job. A missing handler passes
+// the value or reason through to the derived promise unchanged.
+func (rl *Realm) enqueueReaction(r *promiseReaction, state int, val *runtime.Value) {
+	rl.Agent.Events.EnqueueJob(func() error {
+		fn := getCallable(r.handler)
+		if fn == nil {
+			if r.derived == nil {
+				return nil
+			}
+			if state == promiseFulfilled {
+				resolvePromise(r.derived, val)
+			} else {
+				rejectPromise(r.derived, val)
+			}
+			return nil
+		}
+		result, err := fn(runtime.Undefined, []*runtime.Value{val})
+		if r.derived == nil {
+			return nil
+		}
+		if err != nil {
+			rejectPromise(r.derived, rl.errorToValue(err))
+			return nil
+		}
+		if result == nil {
+			result = runtime.Undefined
+		}
+		resolvePromise(r.derived, result)
+		return nil
+	})
+}
+
+// performThen registers onFulfilled/onRejected on pd. derived, when non-nil,
+// is settled with the outcome of whichever handler runs.
+func (rl *Realm) performThen(pd *promiseData, onFulfilled, onRejected *runtime.Value, derived *promiseData) {
+	fulfill := &promiseReaction{handler: onFulfilled, derived: derived}
+	reject := &promiseReaction{handler: onRejected, derived: derived}
+	switch pd.state {
+	case promisePending:
+		pd.fulfillReactions = append(pd.fulfillReactions, fulfill)
+		pd.rejectReactions = append(pd.rejectReactions, reject)
+	case promiseFulfilled:
+		rl.enqueueReaction(fulfill, promiseFulfilled, pd.result)
+	case promiseRejected:
+		rl.enqueueReaction(reject, promiseRejected, pd.result)
+	}
+}
+
+// toPromise implements PromiseResolve for the intrinsic constructor: promises
+// are returned as-is, other values are wrapped in a promise resolved with them.
+func (rl *Realm) toPromise(val *runtime.Value) (*runtime.Object, *promiseData) {
+	if val.Type == runtime.TypeObject {
+		if pd := getPromiseData(val.Object); pd != nil {
+			return val.Object, pd
+		}
+	}
+	obj, pd := rl.newPromiseObject()
+	resolvePromise(pd, val)
+	return obj, pd
+}
+
+// newPromiseCapability returns a pending promise and Go functions that
+// resolve or reject it, for hosts that settle promises from native code.
+func (rl *Realm) newPromiseCapability() (*runtime.Value, func(*runtime.Value), func(*runtime.Value)) {
+	obj, pd := rl.newPromiseObject()
+	resolve := func(val *runtime.Value) { resolvePromise(pd, val) }
+	reject := func(reason *runtime.Value) { rejectPromise(pd, reason) }
+	return runtime.NewObject(obj), resolve, reject
+}
+
+// awaitValue subscribes to val the way await does: the value is converted
+// with PromiseResolve and the callbacks run as reaction jobs.
+func (rl *Realm) awaitValue(val *runtime.Value, onFulfilled, onRejected func(*runtime.Value)) {
+	_, pd := rl.toPromise(val)
+	rl.performThen(pd, rl.nativeHandler(onFulfilled), rl.nativeHandler(onRejected), nil)
+}
+
+func (rl *Realm) promiseConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	executor := getCallable(argAt(args, 0))
+	if executor == nil {
+		return nil, fmt.Errorf("TypeError: Promise resolver is not a function")
+	}
+	obj, pd := rl.newPromiseObject()
+	resolveFn := rl.newFuncObject("", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		resolvePromise(pd, argAt(args, 0))
+		return runtime.Undefined, nil
+	})
+	rejectFn := rl.newFuncObject("", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		rejectPromise(pd, argAt(args, 0))
+		return runtime.Undefined, nil
+	})
+	_, err := executor(runtime.Undefined, []*runtime.Value{runtime.NewObject(resolveFn), runtime.NewObject(rejectFn)})
+	if err != nil {
+		rejectPromise(pd, rl.errorToValue(err))
+	}
+	return runtime.NewObject(obj), nil
+}
+
+func (rl *Realm) promiseThen(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	pd := getPromiseData(toObject(this))
+	if pd == nil {
+		return nil, fmt.Errorf("TypeError: Promise.prototype.then called on incompatible receiver")
+	}
+	newObj, newPd := rl.newPromiseObject()
+	rl.performThen(pd, argAt(args, 0), argAt(args, 1), newPd)
+	return runtime.NewObject(newObj), nil
+}
+
+// invokeThen calls this.then(onFulfilled, onRejected) through property
+// lookup, so overridden then methods are honoured by catch and finally.
+func invokeThen(this *runtime.Value, onFulfilled, onRejected *runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return nil, fmt.Errorf("TypeError: Promise.prototype method called on non-object")
+	}
+	then := getCallable(obj.Get("then"))
+	if then == nil {
+		return nil, fmt.Errorf("TypeError: then is not a function")
+	}
+	return then(this, []*runtime.Value{onFulfilled, onRejected})
+}
+
+func promiseCatch(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return invokeThen(this, runtime.Undefined, argAt(args, 0))
+}
+
+func (rl *Realm) promiseFinally(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	onFinally := argAt(args, 0)
+	fn := getCallable(onFinally)
+	if fn == nil {
+		return invokeThen(this, onFinally, onFinally)
+	}
+	// Both paths run onFinally, wait for its result to settle, then restore
+	// the original outcome unless onFinally itself threw or rejected.
+	after := func(restore runtime.CallableFunc) runtime.CallableFunc {
+		return func(_ *runtime.Value, callArgs []*runtime.Value) (*runtime.Value, error) {
+			result, err := fn(runtime.Undefined, nil)
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				result = runtime.Undefined
+			}
+			_, pd := rl.toPromise(result)
+			outcome := argAt(callArgs, 0)
+			restoreFn := rl.newFuncObject("", 0, func(_ *runtime.Value, _ []*runtime.Value) (*runtime.Value, error) {
+				return restore(runtime.Undefined, []*runtime.Value{outcome})
+			})
+			newObj, newPd := rl.newPromiseObject()
+			rl.performThen(pd, runtime.NewObject(restoreFn), runtime.Undefined, newPd)
+			return runtime.NewObject(newObj), nil
+		}
+	}
+	thenFinally := rl.newFuncObject("", 1, after(func(_ *runtime.Value, a []*runtime.Value) (*runtime.Value, error) {
+		return argAt(a, 0), nil
+	}))
+	catchFinally := rl.newFuncObject("", 1, after(func(_ *runtime.Value, a []*runtime.Value) (*runtime.Value, error) {
+		return nil, runtime.Throw(argAt(a, 0))
+	}))
+	return invokeThen(this, runtime.NewObject(thenFinally), runtime.NewObject(catchFinally))
+}
+
+func (rl *Realm) promiseResolve(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj, _ := rl.toPromise(argAt(args, 0))
+	return runtime.NewObject(obj), nil
+}
+
+func (rl *Realm) promiseReject(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj, pd := rl.newPromiseObject()
+	rejectPromise(pd, argAt(args, 0))
+	return runtime.NewObject(obj), nil
+}
+
+// promiseCombinator holds the shared bookkeeping of Promise.all and
+// Promise.allSettled: one slot per input and a count of unsettled inputs.
+type promiseCombinator struct {
+	pd        *promiseData
+	results   []*runtime.Value
+	remaining int
+}
+
+func (c *promiseCombinator) store(i int, val *runtime.Value) {
+	c.results[i] = val
+	c.release()
+}
+
+// release drops one outstanding count and resolves the combined promise once
+// none remain.
+func (c *promiseCombinator) release() {
+	c.remaining--
+	if c.remaining == 0 {
+		resolvePromise(c.pd, runtime.NewObject(c.pd.realm.newArray(c.results)))
+	}
+}
+
+func (rl *Realm) promiseIterableArg(args []*runtime.Value, method string) ([]*runtime.Value, error) {
+	iterable := argAt(args, 0)
+	if !rl.isIterable(iterable) {
+		return nil, fmt.Errorf("TypeError: Promise.%s requires an iterable", method)
+	}
+	return rl.iterableToList(iterable)
+}
+
+// nativeHandler wraps a Go callback as a one-argument JS function.
+func (rl *Realm) nativeHandler(fn func(val *runtime.Value)) *runtime.Value {
+	return runtime.NewObject(rl.newFuncObject("", 1, func(_ *runtime.Value, a []*runtime.Value) (*runtime.Value, error) {
+		fn(argAt(a, 0))
+		return runtime.Undefined, nil
+	}))
+}
+
+func (rl *Realm) promiseAll(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	items, err := rl.promiseIterableArg(args, "all")
+	if err != nil {
+		return nil, err
+	}
+	obj, pd := rl.newPromiseObject()
+	c := &promiseCombinator{pd: pd, results: make([]*runtime.Value, len(items)), remaining: len(items) + 1}
+	for i, item := range items {
+		idx := i
+		_, ipd := rl.toPromise(item)
+		rl.performThen(ipd,
+			rl.nativeHandler(func(val *runtime.Value) { c.store(idx, val) }),
+			rl.nativeHandler(func(reason *runtime.Value) { rejectPromise(pd, reason) }),
+			nil)
+	}
+	// The extra count keeps the promise pending until every input has been
+	// subscribed, matching the spec's remainingElements bookkeeping.
+	c.release()
+	return runtime.NewObject(obj), nil
+}
+
+func (rl *Realm) promiseRace(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	items, err := rl.promiseIterableArg(args, "race")
+	if err != nil {
+		return nil, err
+	}
+	obj, pd := rl.newPromiseObject()
+	for _, item := range items {
+		_, ipd := rl.toPromise(item)
+		rl.performThen(ipd,
+			rl.nativeHandler(func(val *runtime.Value) { resolvePromise(pd, val) }),
+			rl.nativeHandler(func(reason *runtime.Value) { rejectPromise(pd, reason) }),
+			nil)
+	}
+	return runtime.NewObject(obj), nil
+}
+
+func (rl *Realm) promiseAllSettled(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	items, err := rl.promiseIterableArg(args, "allSettled")
+	if err != nil {
+		return nil, err
+	}
+	obj, pd := rl.newPromiseObject()
+	c := &promiseCombinator{pd: pd, results: make([]*runtime.Value, len(items)), remaining: len(items) + 1}
+	settled := func(idx int, status, key string) *runtime.Value {
+		return rl.nativeHandler(func(val *runtime.Value) {
+			r := runtime.NewOrdinaryObject(nil)
+			r.Set("status", runtime.NewString(status))
+			r.Set(key, val)
+			c.store(idx, runtime.NewObject(r))
+		})
+	}
+	for i, item := range items {
+		_, ipd := rl.toPromise(item)
+		rl.performThen(ipd, settled(i, "fulfilled", "value"), settled(i, "rejected", "reason"), nil)
+	}
+	c.release()
+	return runtime.NewObject(obj), nil
+}
@@ -0,0 +1,228 @@
+package builtins
+
+import (
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupPromise() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createArrayConstructor(rl.ObjectPrototype)
+	rl.createPromiseConstructor(rl.ObjectPrototype)
+	return rl
+}
+
+func TestPromiseResolve(t *testing.T) {
+	rl := setupPromise()
+	result, err := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(42)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	pd := getPromiseData(obj)
+	if pd == nil {
+		t.Fatal("expected promise data")
+	}
+	if pd.state != promiseFulfilled {
+		t.Error("expected fulfilled state")
+	}
+	if pd.result.Number != 42 {
+		t.Errorf("expected result 42, got %v", pd.result.Number)
+	}
+}
+
+func TestPromiseReject(t *testing.T) {
+	rl := setupPromise()
+	result, err := rl.promiseReject(runtime.Undefined, []*runtime.Value{runtime.NewString("error!")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	pd := getPromiseData(obj)
+	if pd.state != promiseRejected {
+		t.Error("expected rejected state")
+	}
+	if pd.result.Str != "error!" {
+		t.Errorf("expected 'error!', got %q", pd.result.Str)
+	}
+}
+
+func TestPromiseConstructorSync(t *testing.T) {
+	rl := setupPromise()
+	executor := rl.newFuncObject("executor", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		resolve := getCallable(args[0])
+		resolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(100)})
+		return runtime.Undefined, nil
+	})
+
+	result, err := rl.promiseConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewObject(executor)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	pd := getPromiseData(obj)
+	if pd.state != promiseFulfilled || pd.result.Number != 100 {
+		t.Error("promise should be fulfilled with 100")
+	}
+}
+
+func TestPromiseThen(t *testing.T) {
+	rl := setupPromise()
+	executor := rl.newFuncObject("executor", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		resolve := getCallable(args[0])
+		resolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(5)})
+		return runtime.Undefined, nil
+	})
+	promise, _ := rl.promiseConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewObject(executor)})
+
+	onFulfilled := rl.newFuncObject("onFulfilled", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(args[0].Number * 2), nil
+	})
+
+	thenResult, err := rl.promiseThen(promise, []*runtime.Value{runtime.NewObject(onFulfilled)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thenObj := toObject(thenResult)
+	thenPd := getPromiseData(thenObj)
+	if thenPd.state != promisePending {
+		t.Fatal("then: handler must not run before the microtask queue is drained")
+	}
+	rl.Agent.Events.RunJobs()
+	if thenPd.state != promiseFulfilled || thenPd.result.Number != 10 {
+		t.Errorf("then: expected fulfilled with 10, got state=%d result=%v", thenPd.state, thenPd.result)
+	}
+}
+
+func TestPromiseAll(t *testing.T) {
+	rl := registerTestRealm()
+	p1, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)})
+	p2, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(2)})
+	p3, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(3)})
+
+	arr := runtime.NewObject(rl.newArray([]*runtime.Value{p1, p2, p3}))
+	result, err := rl.promiseAll(runtime.Undefined, []*runtime.Value{arr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl.Agent.Events.RunJobs()
+	obj := toObject(result)
+	pd := getPromiseData(obj)
+	if pd.state != promiseFulfilled {
+		t.Error("Promise.all should be fulfilled")
+	}
+	resArr := toObject(pd.result)
+	if resArr == nil || len(resArr.ArrayData) != 3 {
+		t.Error("Promise.all result should be array of 3 items")
+	}
+}
+
+func TestPromiseRace(t *testing.T) {
+	rl := registerTestRealm()
+	p1, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewString("first")})
+	p2, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewString("second")})
+
+	arr := runtime.NewObject(rl.newArray([]*runtime.Value{p1, p2}))
+	result, err := rl.promiseRace(runtime.Undefined, []*runtime.Value{arr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl.Agent.Events.RunJobs()
+	obj := toObject(result)
+	pd := getPromiseData(obj)
+	if pd.state != promiseFulfilled || pd.result.Str != "first" {
+		t.Error("Promise.race should resolve with first value")
+	}
+}
+
+func TestPromiseReactionOrder(t *testing.T) {
+	rl := setupPromise()
+	var order []string
+	record := func(name string) *runtime.Value {
+		return runtime.NewObject(rl.newFuncObject("", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			order = append(order, name)
+			return args[0], nil
+		}))
+	}
+	p1, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)})
+	p2, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(2)})
+	a, _ := rl.promiseThen(p1, []*runtime.Value{record("a1")})
+	rl.promiseThen(p2, []*runtime.Value{record("b1")})
+	rl.promiseThen(a, []*runtime.Value{record("a2")})
+	if len(order) != 0 {
+		t.Fatalf("reactions ran synchronously: %v", order)
+	}
+	rl.Agent.Events.RunJobs()
+	expected := []string{"a1", "b1", "a2"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestPromiseAdoptsThenable(t *testing.T) {
+	rl := setupPromise()
+	thenable := runtime.NewOrdinaryObject(nil)
+	rl.setMethod(thenable, "then", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		getCallable(args[0])(runtime.Undefined, []*runtime.Value{runtime.NewString("adopted")})
+		return runtime.Undefined, nil
+	})
+	result, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewObject(thenable)})
+	pd := getPromiseData(toObject(result))
+	if pd.state != promisePending {
+		t.Fatal("thenable must be adopted in a later job")
+	}
+	rl.Agent.Events.RunJobs()
+	if pd.state != promiseFulfilled || pd.result.Str != "adopted" {
+		t.Errorf("expected fulfilled with 'adopted', got state=%d result=%v", pd.state, pd.result)
+	}
+}
+
+func TestPromiseSelfResolution(t *testing.T) {
+	rl := setupPromise()
+	rl.createErrorConstructor(rl.ObjectPrototype)
+	rl.createErrorSubtype("TypeError", rl.ObjectPrototype, rl.ErrorPrototype)
+	obj, pd := rl.newPromiseObject()
+	resolvePromise(pd, runtime.NewObject(obj))
+	if pd.state != promiseRejected {
+		t.Fatal("self-resolution should reject")
+	}
+	if name := pd.result.Object.Get("name").Str; name != "TypeError" {
+		t.Errorf("expected TypeError, got %s", name)
+	}
+}
+
+func TestPromiseHandlerThrowRejects(t *testing.T) {
+	rl := setupPromise()
+	p, _ := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)})
+	thrower := rl.newFuncObject("", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, runtime.Throw(runtime.NewString("boom"))
+	})
+	derived, _ := rl.promiseThen(p, []*runtime.Value{runtime.NewObject(thrower)})
+	rl.Agent.Events.RunJobs()
+	pd := getPromiseData(toObject(derived))
+	if pd.state != promiseRejected || pd.result.Str != "boom" {
+		t.Errorf("expected rejection with 'boom', got state=%d result=%v", pd.state, pd.result)
+	}
+}
+
+func TestPromiseToStringTag(t *testing.T) {
+	rl := setupPromise()
+	p, err := rl.promiseResolve(runtime.Undefined, []*runtime.Value{runtime.Undefined})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := objectProtoToString(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Str != "[object Promise]" {
+		t.Errorf("got %q, want %q", got.Str, "[object Promise]")
+	}
+}
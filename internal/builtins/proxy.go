@@ -0,0 +1,498 @@
+package builtins
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createProxyConstructor(objProto *runtime.Object) *runtime.Object {
+	ctor := rl.newFuncObject("Proxy", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor Proxy requires 'new'")
+	})
+	ctor.Constructor = rl.proxyConstructorCall
+	rl.setMethod(ctor, "revocable", 2, rl.proxyRevocable)
+	return ctor
+}
+
+func (rl *Realm) proxyConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	proxy, err := rl.newProxy(argAt(args, 0), argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(proxy), nil
+}
+
+// newProxy creates a proxy object. Property operations on it are routed to
+// the handler's traps by the runtime; calling and constructing it, which
+// only a callable target allows, go through the apply and construct traps.
+func (rl *Realm) newProxy(targetVal, handlerVal *runtime.Value) (*runtime.Object, error) {
+	target := toObject(targetVal)
+	handler := toObject(handlerVal)
+	if targetVal.Type != runtime.TypeObject || handlerVal.Type != runtime.TypeObject || target == nil || handler == nil {
+		return nil, fmt.Errorf("TypeError: Cannot create proxy with a non-object as target or handler")
+	}
+	proxy := &runtime.Object{
+		OType:      runtime.ObjTypeProxy,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  target.Prototype,
+		Internal: map[string]interface{}{
+			"target":  target,
+			"handler": handler,
+		},
+	}
+	if target.Callable != nil {
+		proxy.Callable = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			target, trap, handler, err := proxy.ProxyTrap("apply")
+			if err != nil {
+				return nil, err
+			}
+			if trap == nil {
+				return target.Callable(this, args)
+			}
+			return trap(handler, []*runtime.Value{runtime.NewObject(target), this, rl.createValueArray(append([]*runtime.Value(nil), args...))})
+		}
+	}
+	if target.Constructor != nil {
+		proxy.Constructor = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			target, trap, handler, err := proxy.ProxyTrap("construct")
+			if err != nil {
+				return nil, err
+			}
+			if trap == nil {
+				return target.Constructor(this, args)
+			}
+			result, err := trap(handler, []*runtime.Value{runtime.NewObject(target), rl.createValueArray(append([]*runtime.Value(nil), args...)), runtime.NewObject(proxy)})
+			if err != nil {
+				return nil, err
+			}
+			if result == nil || result.Type != runtime.TypeObject {
+				return nil, fmt.Errorf("TypeError: proxy trap 'construct' returned a non-object")
+			}
+			return result, nil
+		}
+	}
+	return proxy, nil
+}
+
+// getOwnProperty implements [[GetOwnProperty]]. A proxy asks its
+// getOwnPropertyDescriptor trap, whose answer must agree with the target;
+// other objects answer with runtime.OwnProperty.
+func (rl *Realm) getOwnProperty(obj *runtime.Object, key string) (*runtime.Property, error) {
+	if obj.OType != runtime.ObjTypeProxy {
+		return runtime.OwnProperty(obj, key), nil
+	}
+	target, trap, handler, err := obj.ProxyTrap("getOwnPropertyDescriptor")
+	if err != nil {
+		return nil, err
+	}
+	if trap == nil {
+		return rl.getOwnProperty(target, key)
+	}
+	result, err := trap(handler, []*runtime.Value{runtime.NewObject(target), runtime.KeyToValue(key)})
+	if err != nil {
+		return nil, err
+	}
+	current, err := rl.getOwnProperty(target, key)
+	if err != nil {
+		return nil, err
+	}
+	extensible, err := target.IsExtensibleErr()
+	if err != nil {
+		return nil, err
+	}
+	name := runtime.KeyToValue(key).ToString()
+	if result == nil || result.Type == runtime.TypeUndefined {
+		if current != nil && (!current.Configurable || !extensible) {
+			return nil, fmt.Errorf("TypeError: 'getOwnPropertyDescriptor' on proxy: trap returned undefined for property '%s' which is non-configurable in the proxy target or the target is non-extensible", name)
+		}
+		return nil, nil
+	}
+	if result.Type != runtime.TypeObject || result.Object == nil {
+		return nil, fmt.Errorf("TypeError: 'getOwnPropertyDescriptor' on proxy: trap returned neither object nor undefined for property '%s'", name)
+	}
+	prop, err := descriptorToProperty(result.Object)
+	if err != nil {
+		return nil, err
+	}
+	if !prop.IsAccessor && prop.Value == nil {
+		prop.Value = runtime.Undefined
+	}
+	if validateRedefinition(name, current, extensible, prop) != nil {
+		return nil, fmt.Errorf("TypeError: 'getOwnPropertyDescriptor' on proxy: trap returned descriptor for property '%s' that is incompatible with the existing property in the proxy target", name)
+	}
+	if !prop.Configurable && (current == nil || current.Configurable) {
+		return nil, fmt.Errorf("TypeError: 'getOwnPropertyDescriptor' on proxy: trap reported non-configurability for property '%s' which is either non-existent or configurable in the proxy target", name)
+	}
+	return prop, nil
+}
+
+// defineOwnProperty implements [[DefineOwnProperty]], reporting whether
+// desc could be defined as the property key of obj. A proxy asks its
+// defineProperty trap, which cannot report a definition the target does
+// not allow.
+func (rl *Realm) defineOwnProperty(obj *runtime.Object, key string, desc *runtime.Property) (bool, error) {
+	if obj.OType != runtime.ObjTypeProxy {
+		if validateDefineOwnProperty(obj, key, desc) != nil {
+			return false, nil
+		}
+		mergeAndDefineProperty(obj, key, desc)
+		return true, nil
+	}
+	target, trap, handler, err := obj.ProxyTrap("defineProperty")
+	if err != nil {
+		return false, err
+	}
+	if trap == nil {
+		return rl.defineOwnProperty(target, key, desc)
+	}
+	result, err := trap(handler, []*runtime.Value{runtime.NewObject(target), runtime.KeyToValue(key), rl.descriptorObject(desc)})
+	if err != nil || result == nil || !result.ToBoolean() {
+		return false, err
+	}
+	current, err := rl.getOwnProperty(target, key)
+	if err != nil {
+		return false, err
+	}
+	extensible, err := target.IsExtensibleErr()
+	if err != nil {
+		return false, err
+	}
+	name := runtime.KeyToValue(key).ToString()
+	if validateRedefinition(name, current, extensible, desc) != nil {
+		return false, fmt.Errorf("TypeError: 'defineProperty' on proxy: trap returned truish for adding property '%s' that is incompatible with the existing property in the proxy target", name)
+	}
+	if desc.HasConfigurable && !desc.Configurable && (current == nil || current.Configurable) {
+		return false, fmt.Errorf("TypeError: 'defineProperty' on proxy: trap returned truish for defining non-configurable property '%s' which is either non-existent or configurable in the proxy target", name)
+	}
+	return true, nil
+}
+
+// descriptorObject returns desc as the object a defineProperty trap is
+// passed, with only the attributes desc specifies.
+func (rl *Realm) descriptorObject(desc *runtime.Property) *runtime.Value {
+	obj := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	orUndefined := func(v *runtime.Value) *runtime.Value {
+		if v == nil {
+			return runtime.Undefined
+		}
+		return v
+	}
+	if desc.HasValue {
+		obj.Set("value", orUndefined(desc.Value))
+	}
+	if desc.HasWritable {
+		obj.Set("writable", runtime.NewBool(desc.Writable))
+	}
+	if desc.HasGet {
+		obj.Set("get", orUndefined(desc.Getter))
+	}
+	if desc.HasSet {
+		obj.Set("set", orUndefined(desc.Setter))
+	}
+	if desc.HasEnumerable {
+		obj.Set("enumerable", runtime.NewBool(desc.Enumerable))
+	}
+	if desc.HasConfigurable {
+		obj.Set("configurable", runtime.NewBool(desc.Configurable))
+	}
+	return runtime.NewObject(obj)
+}
+
+// enumerableOwnKeys returns the enumerable own string keys of obj in
+// property order, as Object.keys lists them. A proxy is asked through its
+// ownKeys and getOwnPropertyDescriptor traps.
+func (rl *Realm) enumerableOwnKeys(obj *runtime.Object) ([]string, error) {
+	if obj.OType != runtime.ObjTypeProxy {
+		return getEnumerableOwnKeys(obj), nil
+	}
+	keys, err := runtime.OwnKeysErr(obj)
+	if err != nil {
+		return nil, err
+	}
+	var enumerable []string
+	for _, key := range keys {
+		if runtime.IsSymbolKey(key) {
+			continue
+		}
+		prop, err := rl.getOwnProperty(obj, key)
+		if err != nil {
+			return nil, err
+		}
+		if prop != nil && prop.Enumerable {
+			enumerable = append(enumerable, key)
+		}
+	}
+	return enumerable, nil
+}
+
+// enumerableOwnEntries calls yield with the enumerable own string keys of
+// obj and their values, as Object.values and Object.entries list them. The
+// values of a proxy are read through its get trap.
+func (rl *Realm) enumerableOwnEntries(obj *runtime.Object, yield func(key string, val *runtime.Value) error) error {
+	if obj.OType != runtime.ObjTypeProxy {
+		for k, p := range runtime.OwnPropertyIterator(obj, runtime.EnumerableStringKeys) {
+			if err := yield(k, p.GetValue(obj)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	keys, err := rl.enumerableOwnKeys(obj)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		v, err := obj.GetErr(k)
+		if err != nil {
+			return err
+		}
+		if err := yield(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proxyRevocable implements Proxy.revocable, returning { proxy, revoke }.
+// Revoking drops the target and handler, after which every operation on
+// the proxy throws.
+func (rl *Realm) proxyRevocable(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	proxy, err := rl.newProxy(argAt(args, 0), argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	result := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	result.Set("proxy", runtime.NewObject(proxy))
+	result.Set("revoke", runtime.NewObject(rl.newFuncObject("", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		delete(proxy.Internal, "target")
+		delete(proxy.Internal, "handler")
+		return runtime.Undefined, nil
+	})))
+	return runtime.NewObject(result), nil
+}
+
+func (rl *Realm) createReflectObject(objProto *runtime.Object) *runtime.Object {
+	reflect := runtime.NewOrdinaryObject(objProto)
+
+	rl.setMethod(reflect, "get", 2, reflectGet)
+	rl.setMethod(reflect, "set", 3, reflectSet)
+	rl.setMethod(reflect, "has", 2, reflectHas)
+	rl.setMethod(reflect, "deleteProperty", 2, reflectDeleteProperty)
+	rl.setMethod(reflect, "apply", 3, reflectApply)
+	rl.setMethod(reflect, "construct", 2, rl.reflectConstruct)
+	rl.setMethod(reflect, "ownKeys", 1, rl.reflectOwnKeys)
+	rl.setMethod(reflect, "getPrototypeOf", 1, reflectGetPrototypeOf)
+	rl.setMethod(reflect, "defineProperty", 3, rl.reflectDefineProperty)
+	rl.setMethod(reflect, "getOwnPropertyDescriptor", 2, rl.reflectGetOwnPropertyDescriptor)
+	rl.setMethod(reflect, "isExtensible", 1, reflectIsExtensible)
+	rl.setMethod(reflect, "preventExtensions", 1, reflectPreventExtensions)
+
+	reflect.Set("@@toStringTag", runtime.NewString("Reflect"))
+	return reflect
+}
+
+func reflectGet(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := toObject(argAt(args, 0))
+	if target == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.get requires object target")
+	}
+	key, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	receiver := argAt(args, 0)
+	if len(args) > 2 {
+		receiver = args[2]
+	}
+	return target.GetWithReceiver(key, receiver)
+}
+
+func reflectSet(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := toObject(argAt(args, 0))
+	if target == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.set requires object target")
+	}
+	key, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	val := argAt(args, 2)
+	receiver := argAt(args, 0)
+	if len(args) > 3 {
+		receiver = args[3]
+	}
+	ok, err := target.TrySetWithReceiver(key, val, receiver)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(ok), nil
+}
+
+func reflectHas(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := toObject(argAt(args, 0))
+	if target == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.has requires object target")
+	}
+	key, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	has, err := target.HasPropertyErr(key)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(has), nil
+}
+
+func reflectDeleteProperty(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := toObject(argAt(args, 0))
+	if target == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.deleteProperty requires object target")
+	}
+	key, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := target.DeleteErr(key)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(deleted), nil
+}
+
+func reflectApply(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	fn := getCallable(target)
+	if fn == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.apply requires callable target")
+	}
+	thisArg := argAt(args, 1)
+	var callArgs []*runtime.Value
+	argsArray := toObject(argAt(args, 2))
+	if argsArray != nil && argsArray.OType == runtime.ObjTypeArray {
+		callArgs = argsArray.ArrayValues()
+	}
+	return fn(thisArg, callArgs)
+}
+
+func (rl *Realm) reflectConstruct(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	targetObj := toObject(target)
+	if targetObj == nil || targetObj.Constructor == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.construct requires constructor target")
+	}
+	newTarget := targetObj
+	if len(args) > 2 {
+		newTarget = toObject(args[2])
+		if newTarget == nil || newTarget.Constructor == nil {
+			return nil, fmt.Errorf("TypeError: Reflect.construct requires constructor newTarget")
+		}
+	}
+	var ctorArgs []*runtime.Value
+	argsArray := toObject(argAt(args, 1))
+	if argsArray != nil && argsArray.OType == runtime.ObjTypeArray {
+		ctorArgs = argsArray.ArrayValues()
+	}
+	return rl.Construct(targetObj, ctorArgs, newTarget)
+}
+
+func (rl *Realm) reflectOwnKeys(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := toObject(argAt(args, 0))
+	if target == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.ownKeys requires object target")
+	}
+	keys, err := runtime.OwnKeysErr(target)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]*runtime.Value, len(keys))
+	for i, key := range keys {
+		vals[i] = runtime.KeyToValue(key)
+	}
+	return rl.createValueArray(vals), nil
+}
+
+// reflectGetPrototypeOf implements Reflect.getPrototypeOf. Unlike
+// Object.getPrototypeOf it does not box primitives.
+func reflectGetPrototypeOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	if target.Type != runtime.TypeObject || target.Object == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.getPrototypeOf called on non-object")
+	}
+	proto, err := target.Object.GetPrototypeOfErr()
+	if err != nil {
+		return nil, err
+	}
+	if proto == nil {
+		return runtime.Null, nil
+	}
+	return runtime.NewObject(proto), nil
+}
+
+func (rl *Realm) reflectDefineProperty(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	if target.Type != runtime.TypeObject || target.Object == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.defineProperty called on non-object")
+	}
+	key, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	descArg := argAt(args, 2)
+	if descArg.Type != runtime.TypeObject || descArg.Object == nil {
+		return nil, fmt.Errorf("TypeError: Property description must be an object")
+	}
+	desc, err := descriptorToProperty(descArg.Object)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := rl.defineOwnProperty(target.Object, key, desc)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(ok), nil
+}
+
+func (rl *Realm) reflectGetOwnPropertyDescriptor(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	if target.Type != runtime.TypeObject || target.Object == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.getOwnPropertyDescriptor called on non-object")
+	}
+	key, err := runtime.ToPropertyKey(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	prop, err := rl.getOwnProperty(target.Object, key)
+	if err != nil {
+		return nil, err
+	}
+	if prop == nil {
+		return runtime.Undefined, nil
+	}
+	return rl.propertyToDescriptor(prop), nil
+}
+
+func reflectIsExtensible(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	if target.Type != runtime.TypeObject || target.Object == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.isExtensible called on non-object")
+	}
+	extensible, err := target.Object.IsExtensibleErr()
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(extensible), nil
+}
+
+func reflectPreventExtensions(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	target := argAt(args, 0)
+	if target.Type != runtime.TypeObject || target.Object == nil {
+		return nil, fmt.Errorf("TypeError: Reflect.preventExtensions called on non-object")
+	}
+	ok, err := target.Object.PreventExtensionsErr()
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(ok), nil
+}
@@ -0,0 +1,335 @@
+package builtins
+
+import (
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestReflectGet(t *testing.T) {
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(42))
+
+	result, err := reflectGet(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewString("x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Number != 42 {
+		t.Errorf("Reflect.get: expected 42, got %v", result.Number)
+	}
+}
+
+func TestReflectSet(t *testing.T) {
+	obj := runtime.NewOrdinaryObject(nil)
+	result, err := reflectSet(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewString("x"), runtime.NewNumber(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Bool {
+		t.Error("Reflect.set should return true")
+	}
+	if obj.Get("x").Number != 10 {
+		t.Error("property not set")
+	}
+	obj.Freeze()
+	result, err = reflectSet(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewString("x"), runtime.NewNumber(11)})
+	if err != nil || result.Bool || obj.Get("x").Number != 10 {
+		t.Errorf("Reflect.set on a frozen object: got %v, %v", result, err)
+	}
+}
+
+func TestReflectReceiver(t *testing.T) {
+	rl := registerTestRealm()
+	proto := runtime.NewOrdinaryObject(nil)
+	proto.DefineProperty("x", &runtime.Property{
+		IsAccessor: true,
+		Getter: runtime.NewObject(rl.newFuncObject("get x", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return this.Object.Get("tag"), nil
+		})),
+		Setter: runtime.NewObject(rl.newFuncObject("set x", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			this.Object.Set("seen", args[0])
+			return runtime.Undefined, nil
+		})),
+	})
+	proto.Set("tag", runtime.NewString("proto"))
+	receiver := runtime.NewOrdinaryObject(nil)
+	receiver.Set("tag", runtime.NewString("receiver"))
+	recv := runtime.NewObject(receiver)
+
+	got, err := reflectGet(runtime.Undefined, []*runtime.Value{runtime.NewObject(proto), runtime.NewString("x"), recv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Str != "receiver" {
+		t.Errorf("Reflect.get with receiver: got %q, want %q", got.Str, "receiver")
+	}
+
+	if _, err := reflectSet(runtime.Undefined, []*runtime.Value{runtime.NewObject(proto), runtime.NewString("x"), runtime.NewNumber(1), recv}); err != nil {
+		t.Fatal(err)
+	}
+	if receiver.Get("seen").Number != 1 || proto.HasOwnProperty("seen") {
+		t.Error("Reflect.set with receiver should call the setter on the receiver")
+	}
+	// A data property is created on the receiver, not on the target.
+	if _, err := reflectSet(runtime.Undefined, []*runtime.Value{runtime.NewObject(proto), runtime.NewString("tag"), runtime.NewString("new"), recv}); err != nil {
+		t.Fatal(err)
+	}
+	if receiver.Get("tag").Str != "new" || proto.Get("tag").Str != "proto" {
+		t.Error("Reflect.set with receiver should write the receiver's own property")
+	}
+}
+
+func TestReflectHas(t *testing.T) {
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("a", runtime.NewNumber(1))
+
+	result, _ := reflectHas(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewString("a")})
+	if !result.Bool {
+		t.Error("Reflect.has('a') should be true")
+	}
+	result, _ = reflectHas(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewString("b")})
+	if result.Bool {
+		t.Error("Reflect.has('b') should be false")
+	}
+}
+
+func TestReflectDeleteProperty(t *testing.T) {
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("x", runtime.NewNumber(1))
+
+	result, _ := reflectDeleteProperty(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj), runtime.NewString("x")})
+	if !result.Bool {
+		t.Error("Reflect.deleteProperty should return true")
+	}
+	if obj.HasOwnProperty("x") {
+		t.Error("property should be deleted")
+	}
+}
+
+func TestReflectOwnKeys(t *testing.T) {
+	rl := registerTestRealm()
+	obj := runtime.NewOrdinaryObject(nil)
+	obj.Set("a", runtime.NewNumber(1))
+	obj.Set("b", runtime.NewNumber(2))
+
+	result, err := rl.reflectOwnKeys(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := toObject(result)
+	if arr == nil || len(arr.ArrayData) != 2 {
+		t.Errorf("Reflect.ownKeys: expected 2 keys, got %v", arr)
+	}
+}
+
+func TestReflectApply(t *testing.T) {
+	rl := registerTestRealm()
+	fn := rl.newFuncObject("add", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		a := args[0].Number
+		b := args[1].Number
+		return runtime.NewNumber(a + b), nil
+	})
+	argsArr := rl.newArray([]*runtime.Value{runtime.NewNumber(3), runtime.NewNumber(4)})
+
+	result, err := reflectApply(runtime.Undefined, []*runtime.Value{runtime.NewObject(fn), runtime.Undefined, runtime.NewObject(argsArr)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Number != 7 {
+		t.Errorf("Reflect.apply: expected 7, got %v", result.Number)
+	}
+}
+
+func TestReflectGetPrototypeOf(t *testing.T) {
+	rl := registerTestRealm()
+	proto := runtime.NewOrdinaryObject(nil)
+	obj := runtime.NewOrdinaryObject(proto)
+	result, err := reflectGetPrototypeOf(runtime.Undefined, []*runtime.Value{runtime.NewObject(obj)})
+	if err != nil || result.Object != proto {
+		t.Errorf("Reflect.getPrototypeOf: got %v, %v", result, err)
+	}
+	result, err = reflectGetPrototypeOf(runtime.Undefined, []*runtime.Value{runtime.NewObject(proto)})
+	if err != nil || result.Type != runtime.TypeNull {
+		t.Errorf("Reflect.getPrototypeOf without a prototype: got %v, %v", result, err)
+	}
+	if _, err := reflectGetPrototypeOf(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)}); err == nil {
+		t.Error("Reflect.getPrototypeOf(1): expected a TypeError")
+	}
+
+	other := runtime.NewOrdinaryObject(nil)
+	handler := runtime.NewOrdinaryObject(nil)
+	handler.Set("getPrototypeOf", runtime.NewObject(rl.newFuncObject("", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewObject(other), nil
+	})))
+	proxy, _ := rl.newProxy(runtime.NewObject(obj), runtime.NewObject(handler))
+	result, err = reflectGetPrototypeOf(runtime.Undefined, []*runtime.Value{runtime.NewObject(proxy)})
+	if err != nil || result.Object != other {
+		t.Errorf("getPrototypeOf trap: got %v, %v", result, err)
+	}
+	handler.Set("getPrototypeOf", runtime.NewObject(rl.newFuncObject("", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(1), nil
+	})))
+	if _, err := reflectGetPrototypeOf(runtime.Undefined, []*runtime.Value{runtime.NewObject(proxy)}); err == nil {
+		t.Error("getPrototypeOf trap returning a number: expected a TypeError")
+	}
+	bare, _ := rl.newProxy(runtime.NewObject(obj), runtime.NewObject(runtime.NewOrdinaryObject(nil)))
+	result, err = rl.objectGetPrototypeOf(runtime.Undefined, []*runtime.Value{runtime.NewObject(bare)})
+	if err != nil || result.Object != proto {
+		t.Errorf("Object.getPrototypeOf through a proxy without the trap: got %v, %v", result, err)
+	}
+}
+
+func TestProxyIsArray(t *testing.T) {
+	rl := registerTestRealm()
+	empty := func() *runtime.Value { return runtime.NewObject(runtime.NewOrdinaryObject(nil)) }
+	arr := runtime.NewObject(rl.newArray(nil))
+	proxy, _ := rl.newProxy(arr, empty())
+	nested, _ := rl.newProxy(runtime.NewObject(proxy), empty())
+	plain, _ := rl.newProxy(empty(), empty())
+	tests := []struct {
+		name string
+		val  *runtime.Value
+		want bool
+	}{
+		{"array", arr, true},
+		{"proxy of an array", runtime.NewObject(proxy), true},
+		{"proxy of a proxy of an array", runtime.NewObject(nested), true},
+		{"proxy of an object", runtime.NewObject(plain), false},
+		{"string", runtime.NewString("[]"), false},
+	}
+	for _, tt := range tests {
+		result, err := arrayIsArray(runtime.Undefined, []*runtime.Value{tt.val})
+		if err != nil || result.Bool != tt.want {
+			t.Errorf("Array.isArray(%s) = %v, %v, want %v", tt.name, result, err, tt.want)
+		}
+	}
+
+	revocable, _ := rl.proxyRevocable(runtime.Undefined, []*runtime.Value{arr, empty()})
+	revocable.Object.Get("revoke").Object.Callable(runtime.Undefined, nil)
+	if _, err := arrayIsArray(runtime.Undefined, []*runtime.Value{revocable.Object.Get("proxy")}); err == nil {
+		t.Error("Array.isArray of a revoked proxy: expected a TypeError")
+	}
+}
+
+func TestProxyConstructor(t *testing.T) {
+	rl := registerTestRealm()
+	target := runtime.NewOrdinaryObject(nil)
+	target.Set("x", runtime.NewNumber(1))
+	handler := runtime.NewOrdinaryObject(nil)
+
+	result, err := rl.proxyConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewObject(target), runtime.NewObject(handler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := toObject(result)
+	if obj == nil || obj.OType != runtime.ObjTypeProxy {
+		t.Error("expected proxy object")
+	}
+}
+
+func TestProxyTraps(t *testing.T) {
+	rl := registerTestRealm()
+	target := runtime.NewOrdinaryObject(nil)
+	target.Set("x", runtime.NewNumber(1))
+	var log []string
+	handler := runtime.NewOrdinaryObject(nil)
+	rl.setMethod(handler, "get", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		log = append(log, "get "+args[1].ToString())
+		return runtime.NewString("trapped"), nil
+	})
+	rl.setMethod(handler, "set", 4, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		log = append(log, "set "+args[1].ToString())
+		toObject(args[0]).Set(args[1].ToString(), runtime.NewNumber(args[2].ToNumber()*2))
+		return runtime.True, nil
+	})
+	rl.setMethod(handler, "has", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewBool(args[1].ToString() == "virtual"), nil
+	})
+	rl.setMethod(handler, "deleteProperty", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.False, nil
+	})
+	rl.setMethod(handler, "ownKeys", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return rl.createStringArray([]string{"b", "a"}), nil
+	})
+	result, err := rl.proxyConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewObject(target), runtime.NewObject(handler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := result.Object
+
+	if v := proxy.Get("x"); v.ToString() != "trapped" {
+		t.Errorf("get trap: got %v", v.ToString())
+	}
+	proxy.Set("y", runtime.NewNumber(5))
+	if v := target.Get("y"); v.Number != 10 {
+		t.Errorf("set trap should write the target, got %v", v.ToString())
+	}
+	if proxy.HasProperty("x") || !proxy.HasProperty("virtual") {
+		t.Error("has trap not used")
+	}
+	if proxy.Delete("x") || !target.HasOwnProperty("x") {
+		t.Error("deleteProperty trap not used")
+	}
+	if keys := runtime.OwnKeys(proxy); len(keys) != 2 || keys[0] != "b" || keys[1] != "a" {
+		t.Errorf("ownKeys trap: got %v", keys)
+	}
+	if len(log) != 2 || log[0] != "get x" || log[1] != "set y" {
+		t.Errorf("unexpected trap calls %v", log)
+	}
+}
+
+func TestProxyForwardsWithoutTraps(t *testing.T) {
+	rl := registerTestRealm()
+	target := runtime.NewOrdinaryObject(nil)
+	target.Set("x", runtime.NewNumber(1))
+	fn := rl.newFuncObject("f", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(argAt(args, 0).ToNumber() + 1), nil
+	})
+	handler := runtime.NewOrdinaryObject(nil)
+
+	result, err := rl.proxyConstructorCall(runtime.Undefined, []*runtime.Value{runtime.NewObject(target), runtime.NewObject(handler)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := result.Object
+	proxy.Set("y", runtime.NewNumber(2))
+	if proxy.Get("x").Number != 1 || target.Get("y").Number != 2 || len(proxy.Properties) != 0 {
+		t.Error("operations should be forwarded to the target")
+	}
+
+	fnProxy, err := rl.newProxy(runtime.NewObject(fn), runtime.NewObject(handler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fnProxy.Callable == nil {
+		t.Fatal("a proxy for a function should be callable")
+	}
+	if v, _ := fnProxy.Callable(runtime.Undefined, []*runtime.Value{runtime.NewNumber(4)}); v.Number != 5 {
+		t.Errorf("apply forwarding: got %v", v.ToString())
+	}
+	rl.setMethod(handler, "apply", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewNumber(toObject(args[2]).Get("length").ToNumber()), nil
+	})
+	if v, _ := fnProxy.Callable(runtime.Undefined, []*runtime.Value{runtime.NewNumber(4), runtime.NewNumber(5)}); v.Number != 2 {
+		t.Errorf("apply trap: got %v", v.ToString())
+	}
+}
+
+func TestProxyRevocable(t *testing.T) {
+	rl := registerTestRealm()
+	result, err := rl.proxyRevocable(runtime.Undefined, []*runtime.Value{
+		runtime.NewObject(runtime.NewOrdinaryObject(nil)),
+		runtime.NewObject(runtime.NewOrdinaryObject(nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := result.Object.Get("proxy").Object
+	if _, err := proxy.GetErr("x"); err != nil {
+		t.Fatalf("unexpected error before revoking: %v", err)
+	}
+	result.Object.Get("revoke").Object.Callable(runtime.Undefined, nil)
+	if _, err := proxy.GetErr("x"); err == nil {
+		t.Error("expected a TypeError on a revoked proxy")
+	}
+}
@@ -0,0 +1,42 @@
+package builtins
+
+import "github.com/example/jsgo/internal/runtime"
+
+// Realm is a realm with the built-ins installed: on top of the intrinsics
+// of runtime.Realm, it holds the other built-in prototypes. The built-in
+// functions are methods on the realm they belong to, so that the objects
+// they make inherit from its prototypes whichever realm calls them.
+type Realm struct {
+	*runtime.Realm
+
+	// ArrayIteratorPrototype is the prototype of the iterators returned by
+	// keys, values and entries.
+	ArrayIteratorPrototype *runtime.Object
+	// RegExpStringIteratorPrototype is the prototype of the iterators
+	// returned by matchAll.
+	RegExpStringIteratorPrototype *runtime.Object
+
+	ErrorPrototype   *runtime.Object
+	RegExpPrototype  *runtime.Object
+	DatePrototype    *runtime.Object
+	PromisePrototype *runtime.Object
+	MapPrototype     *runtime.Object
+	SetPrototype     *runtime.Object
+	WeakMapPrototype *runtime.Object
+	WeakSetPrototype *runtime.Object
+
+	PlainDatePrototype     *runtime.Object
+	PlainDateTimePrototype *runtime.Object
+	DurationPrototype      *runtime.Object
+
+	// errorPrototypes maps each native error name to its prototype so Go
+	// errors such as "TypeError: ..." can be turned back into JS error
+	// objects.
+	errorPrototypes map[string]*runtime.Object
+	// random is the source of the realm's Math.random, which
+	// SeedMathRandom seeds.
+	random *randomSource
+	// joining holds the arrays Array.prototype.join is joining, so that
+	// an array that contains itself joins it as "".
+	joining map[*runtime.Object]bool
+}
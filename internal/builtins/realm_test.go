@@ -0,0 +1,66 @@
+package builtins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// newTestRealm returns a realm without built-ins, for tests that create the
+// ones they need.
+func newTestRealm() *Realm {
+	return &Realm{Realm: runtime.NewRealm(runtime.NewAgent())}
+}
+
+// registerTestRealm returns a realm with all the built-ins.
+func registerTestRealm() *Realm {
+	return RegisterAll(runtime.NewRealm(runtime.NewAgent()), runtime.NewEnvironment(nil, false), nil)
+}
+
+func TestRealmIntrinsics(t *testing.T) {
+	agent := runtime.NewAgent()
+	first := RegisterAll(runtime.NewRealm(agent), runtime.NewEnvironment(nil, false), nil)
+	second := RegisterAll(runtime.NewRealm(agent), runtime.NewEnvironment(nil, false), nil)
+	if first.ArrayPrototype == second.ArrayPrototype || first.errorPrototypes["TypeError"] == second.errorPrototypes["TypeError"] {
+		t.Fatal("each realm has its own intrinsics")
+	}
+	if second.FunctionPrototype.Prototype != second.ObjectPrototype || second.ArrayPrototype.Prototype != second.ObjectPrototype {
+		t.Error("a realm's intrinsics link to each other, not to another realm's")
+	}
+	if v := first.errorToValue(errors.New("TypeError: x")); v.Object.Prototype != first.errorPrototypes["TypeError"] {
+		t.Error("a Go error becomes an error of the realm converting it")
+	}
+	if arr := second.newArray(nil); arr.Prototype != second.ArrayPrototype {
+		t.Error("a realm's arrays inherit from its own Array.prototype")
+	}
+}
+
+func TestRealmBuiltinFunctions(t *testing.T) {
+	agent := runtime.NewAgent()
+	first := RegisterAll(runtime.NewRealm(agent), runtime.NewEnvironment(nil, false), nil)
+	second := RegisterAll(runtime.NewRealm(agent), runtime.NewEnvironment(nil, false), nil)
+	firstMap, secondMap := first.ArrayPrototype.Get("map").Object, second.ArrayPrototype.Get("map").Object
+	if firstMap == secondMap {
+		t.Fatal("each realm has its own built-in functions")
+	}
+	firstMap.DefineProperty("name", &runtime.Property{Value: runtime.NewString("changed"), Configurable: true})
+	firstMap.Set("extra", runtime.True)
+	if name := secondMap.Get("name"); name.ToString() != "map" {
+		t.Errorf("another realm's map is named %q, want map", name.ToString())
+	}
+	if secondMap.HasProperty("extra") {
+		t.Error("a property added to a built-in function shows up in another realm")
+	}
+	first.ArrayPrototype.Set("map", runtime.Undefined)
+	if v := second.ArrayPrototype.Get("map"); v.Type != runtime.TypeObject || v.Object != secondMap {
+		t.Error("replacing a method in one realm replaces it in another")
+	}
+}
+
+func BenchmarkRegisterAll(b *testing.B) {
+	agent := runtime.NewAgent()
+	for i := 0; i < b.N; i++ {
+		RegisterAll(runtime.NewRealm(agent), runtime.NewEnvironment(nil, false), nil)
+	}
+}
@@ -0,0 +1,1332 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createRegExpConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	proto.OType = runtime.ObjTypeRegExp
+	rl.RegExpPrototype = proto
+
+	rl.RegExpStringIteratorPrototype = runtime.NewOrdinaryObject(objProto)
+	rl.setMethod(rl.RegExpStringIteratorPrototype, "next", 0, rl.regexpStringIteratorNext)
+	setDataProp(rl.RegExpStringIteratorPrototype, "@@toStringTag", runtime.NewString("RegExp String Iterator"), false, false, true)
+	if SymIterator != nil {
+		self := rl.newFuncObject("[Symbol.iterator]", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return this, nil
+		})
+		setDataProp(rl.RegExpStringIteratorPrototype, SymIterator.Key(), runtime.NewObject(self), true, false, true)
+	}
+
+	rl.setMethod(proto, "test", 1, rl.regexpTest)
+	rl.setMethod(proto, "exec", 1, rl.regexpExec)
+	rl.setMethod(proto, "toString", 0, regexpToString)
+	rl.setMethod(proto, "compile", 2, regexpCompile)
+
+	// Well-known symbol methods
+	if SymSplit != nil {
+		splitFn := rl.newFuncObject("[Symbol.split]", 2, rl.regexpSymbolSplit)
+		proto.Set(SymSplit.Key(), runtime.NewObject(splitFn))
+	}
+	if SymMatch != nil {
+		matchFn := rl.newFuncObject("[Symbol.match]", 1, rl.regexpSymbolMatch)
+		proto.Set(SymMatch.Key(), runtime.NewObject(matchFn))
+	}
+	if SymMatchAll != nil {
+		matchAllFn := rl.newFuncObject("[Symbol.matchAll]", 1, rl.regexpSymbolMatchAll)
+		proto.Set(SymMatchAll.Key(), runtime.NewObject(matchAllFn))
+	}
+	if SymReplace != nil {
+		replaceFn := rl.newFuncObject("[Symbol.replace]", 2, rl.regexpSymbolReplace)
+		proto.Set(SymReplace.Key(), runtime.NewObject(replaceFn))
+	}
+	if SymSearch != nil {
+		searchFn := rl.newFuncObject("[Symbol.search]", 1, rl.regexpSymbolSearch)
+		proto.Set(SymSearch.Key(), runtime.NewObject(searchFn))
+	}
+
+	ctor := rl.newFuncObject("RegExp", 2, rl.regexpConstructorCall)
+	ctor.Constructor = rl.regexpConstructorCall
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+func (rl *Realm) regexpConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	patternArg := argAt(args, 0)
+	flagsArg := argAt(args, 1)
+
+	pattern := ""
+	flags := ""
+
+	// Check if first arg is a RegExp object
+	patternObj := toObject(patternArg)
+	if patternObj != nil && isRegExp(patternObj) {
+		// Extract pattern and flags from the RegExp object
+		srcVal := patternObj.Get("source")
+		if srcVal != nil && srcVal != runtime.Undefined {
+			pattern = srcVal.ToString()
+		}
+		if flagsArg.Type == runtime.TypeUndefined {
+			flVal := patternObj.Get("flags")
+			if flVal != nil && flVal != runtime.Undefined {
+				flags = flVal.ToString()
+			}
+		} else {
+			flags = flagsArg.ToString()
+		}
+	} else {
+		if patternArg.Type != runtime.TypeUndefined {
+			pattern = patternArg.ToString()
+		}
+		if flagsArg.Type != runtime.TypeUndefined {
+			flags = flagsArg.ToString()
+		}
+	}
+
+	return rl.createRegExpObject(pattern, flags)
+}
+
+func validateRegExpFlags(flags string) error {
+	valid := "gimsuy"
+	seen := make(map[byte]bool)
+	for i := 0; i < len(flags); i++ {
+		c := flags[i]
+		if !strings.ContainsRune(valid, rune(c)) {
+			return fmt.Errorf("SyntaxError: Invalid flags supplied to RegExp constructor '%s'", flags)
+		}
+		if seen[c] {
+			return fmt.Errorf("SyntaxError: Invalid flags supplied to RegExp constructor '%s'", flags)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+func (rl *Realm) createRegExpObject(pattern, flags string) (*runtime.Value, error) {
+	if err := validateRegExpFlags(flags); err != nil {
+		return nil, err
+	}
+	flags = canonicalizeFlags(flags)
+	if err := validateDuplicateNamedGroups(pattern); err != nil {
+		return nil, err
+	}
+	// Validate pattern with 'u' flag more strictly
+	if strings.Contains(flags, "u") {
+		// In unicode mode, lone { is invalid
+		if strings.Contains(pattern, "{") && !strings.Contains(pattern, "\\{") {
+			// Check for unbalanced braces
+			depth := 0
+			for _, c := range pattern {
+				if c == '{' {
+					depth++
+				} else if c == '}' {
+					depth--
+				}
+			}
+			if depth != 0 {
+				return nil, fmt.Errorf("SyntaxError: Invalid regular expression: /%s/: Lone quantifier brackets", pattern)
+			}
+		}
+	}
+	re, err := compileRegExp(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+	obj := &runtime.Object{
+		OType:      runtime.ObjTypeRegExp,
+		Properties: make(map[string]*runtime.Property),
+		Prototype:  rl.RegExpPrototype,
+	}
+	installRegExp(obj, re, pattern, flags)
+	obj.Set("lastIndex", runtime.NewNumber(0))
+	return runtime.NewObject(obj), nil
+}
+
+// compileRegExp translates a JS pattern to Go syntax and compiles it with
+// the i, m and s flags applied.
+func compileRegExp(pattern, flags string) (*regexp.Regexp, error) {
+	goPattern := jsRegexpToGo(maskSurrogates(pattern))
+	mode := ""
+	for _, f := range "ims" {
+		if strings.ContainsRune(flags, f) {
+			mode += string(f)
+		}
+	}
+	if mode != "" {
+		goPattern = "(?" + mode + ")" + goPattern
+	}
+	re, err := regexp.Compile(goPattern)
+	if err != nil {
+		return nil, fmt.Errorf("SyntaxError: Invalid regular expression: %s", err)
+	}
+	return re, nil
+}
+
+// installRegExp stores a compiled pattern in obj's internal slots and
+// defines the source and flag properties.
+func installRegExp(obj *runtime.Object, re *regexp.Regexp, pattern, flags string) {
+	if obj.Internal == nil {
+		obj.Internal = make(map[string]interface{})
+	}
+	obj.Internal["regexp"] = re
+	obj.Internal["pattern"] = pattern
+	obj.Internal["flags"] = flags
+	obj.Internal["leftContext"] = usesLeftContext(pattern)
+
+	setDataProp(obj, "source", runtime.NewString(pattern), false, false, true)
+	setDataProp(obj, "flags", runtime.NewString(flags), false, false, true)
+	setDataProp(obj, "global", runtime.NewBool(strings.Contains(flags, "g")), false, false, true)
+	setDataProp(obj, "ignoreCase", runtime.NewBool(strings.Contains(flags, "i")), false, false, true)
+	setDataProp(obj, "multiline", runtime.NewBool(strings.Contains(flags, "m")), false, false, true)
+	setDataProp(obj, "dotAll", runtime.NewBool(strings.Contains(flags, "s")), false, false, true)
+	setDataProp(obj, "sticky", runtime.NewBool(strings.Contains(flags, "y")), false, false, true)
+	setDataProp(obj, "unicode", runtime.NewBool(strings.Contains(flags, "u")), false, false, true)
+}
+
+func jsRegexpToGo(pattern string) string {
+	// Go's regexp doesn't support backreferences (\1, \2, etc.)
+	// Count capture groups first to determine which \N are valid backreferences
+	numGroups := countCaptureGroups(pattern)
+
+	var result strings.Builder
+	inCharClass := false
+	i := 0
+	for i < len(pattern) {
+		if !inCharClass && pattern[i] == '[' {
+			inCharClass = true
+		} else if inCharClass && pattern[i] == ']' {
+			inCharClass = false
+		}
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			next := pattern[i+1]
+			// Handle octal escapes and backreferences
+			if next >= '0' && next <= '9' && !(next == '0' && (i+2 >= len(pattern) || pattern[i+2] < '0' || pattern[i+2] > '9')) {
+				if next == '0' || inCharClass {
+					// In character classes or \0, treat as octal
+					val := 0
+					j := i + 1
+					for j < len(pattern) && pattern[j] >= '0' && pattern[j] <= '7' && val*8+int(pattern[j]-'0') <= 255 {
+						val = val*8 + int(pattern[j]-'0')
+						j++
+						if j-i-1 >= 3 {
+							break
+						}
+					}
+					// Write as hex escape for Go
+					result.WriteString(fmt.Sprintf("\\x%02x", val))
+					i = j
+					continue
+				}
+				// Outside character class: backreference
+				groupNum := int(next - '0')
+				if groupNum <= numGroups {
+					// Valid backreference - Go doesn't support these
+					// For now, replace with empty match (imperfect but handles many cases)
+					result.WriteString("(?:)")
+				} else {
+					// Invalid backreference (> num groups) - spec says always fails
+					result.WriteString("[^\\w\\W]")
+				}
+				i += 2
+				continue
+			}
+			if next == 'x' {
+				// \xHH - Go supports this
+				// But \x without two hex digits should be identity escape (Annex B)
+				if i+3 < len(pattern) && isHexDigit(pattern[i+2]) && i+4 <= len(pattern) && isHexDigit(pattern[i+3]) {
+					result.WriteByte(pattern[i])
+					result.WriteByte(pattern[i+1])
+					i += 2
+					continue
+				}
+				// Incomplete \x - treat as identity escape (match literal 'x')
+				result.WriteByte('x')
+				i += 2
+				continue
+			}
+			if next == 'u' {
+				// \uHHHH - Go spells it \x{HHHH}. A surrogate pair of
+				// escapes is one character, and a lone surrogate is
+				// masked as in the input, see maskSurrogates.
+				if cu, ok := hexEscape(pattern, i); ok {
+					r := rune(cu)
+					i += 6
+					if low, ok := hexEscape(pattern, i); ok && utf16.IsSurrogate(r) && r < 0xDC00 && low >= 0xDC00 && low <= 0xDFFF {
+						r = utf16.DecodeRune(r, rune(low))
+						i += 6
+					} else if utf16.IsSurrogate(r) {
+						r += surrogateMask
+					}
+					fmt.Fprintf(&result, "\\x{%X}", r)
+					continue
+				}
+				// \u{HHHH} form
+				if i+2 < len(pattern) && pattern[i+2] == '{' {
+					result.WriteString("\\x")
+					i += 2
+					continue
+				}
+				// Incomplete \u - treat as identity escape (match literal 'u')
+				result.WriteByte('u')
+				i += 2
+				continue
+			}
+			// Special JS escapes not in Go
+			if next == 'v' {
+				// \v = vertical tab (0x0B) - Go doesn't support \v
+				result.WriteString("\\x0b")
+				i += 2
+				continue
+			}
+			if next == 'c' && i+2 < len(pattern) && ((pattern[i+2] >= 'a' && pattern[i+2] <= 'z') || (pattern[i+2] >= 'A' && pattern[i+2] <= 'Z')) {
+				// \cX = control character - Go doesn't support this
+				ctrl := pattern[i+2] & 0x1F
+				result.WriteString(fmt.Sprintf("\\x%02x", ctrl))
+				i += 3
+				continue
+			}
+			// Check if Go's regexp understands this escape
+			if isGoRegexpEscape(next) {
+				result.WriteByte(pattern[i])
+				result.WriteByte(pattern[i+1])
+			} else {
+				// Identity escape: convert \X to literal match of X
+				// Use \x{HH} for safety (avoids Go rejecting the escape)
+				if next < 0x80 {
+					if isRegexpSpecial(next) {
+						result.WriteByte('\\')
+						result.WriteByte(next)
+					} else {
+						result.WriteString(fmt.Sprintf("\\x%02x", next))
+					}
+				} else {
+					// Multi-byte UTF-8: write the literal character bytes
+					// Skip the backslash, copy the UTF-8 bytes for the rune
+					_, size := utf8.DecodeRuneInString(pattern[i+1:])
+					result.WriteString(pattern[i+1 : i+1+size])
+					i += 1 + size
+					continue
+				}
+			}
+			i += 2
+			continue
+		}
+		result.WriteByte(pattern[i])
+		i++
+	}
+	return result.String()
+}
+
+// hexEscape parses the \uHHHH escape at byte i of pattern.
+func hexEscape(pattern string, i int) (uint16, bool) {
+	if i+5 >= len(pattern) || pattern[i] != '\\' || pattern[i+1] != 'u' {
+		return 0, false
+	}
+	for j := i + 2; j < i+6; j++ {
+		if !isHexDigit(pattern[j]) {
+			return 0, false
+		}
+	}
+	cu, _ := strconv.ParseUint(pattern[i+2:i+6], 16, 16)
+	return uint16(cu), true
+}
+
+// surrogateMask moves the surrogates U+D800 to U+DFFF onto the private use
+// characters U+F000 to U+F7FF, see maskSurrogates.
+const surrogateMask = 0xF000 - 0xD800
+
+// maskSurrogates prepares a pattern or an input for Go's regexp, which
+// reads strings as UTF-8: a lone surrogate, three bytes that are not valid
+// UTF-8, would match one byte at a time. Each becomes the private use
+// character surrogateMask away, which has as many bytes, so that it
+// matches as the single code unit it is and byte offsets into the input
+// stay those of s.
+func maskSurrogates(s string) string {
+	if runtime.IsWellFormed(s) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if r, ok := runtime.LoneSurrogate(s, i); ok {
+			sb.WriteRune(r + surrogateMask)
+			i += 3
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+func countCaptureGroups(pattern string) int {
+	count := 0
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' {
+			i++ // skip escaped character
+			continue
+		}
+		if pattern[i] == '[' {
+			// Skip character class
+			for i++; i < len(pattern); i++ {
+				if pattern[i] == '\\' {
+					i++
+					continue
+				}
+				if pattern[i] == ']' {
+					break
+				}
+			}
+			continue
+		}
+		if pattern[i] == '(' {
+			// Check if it's a non-capturing group (?:...) or (?=...) etc
+			if i+1 < len(pattern) && pattern[i+1] == '?' {
+				continue // non-capturing group - don't count
+			}
+			count++
+		}
+	}
+	return count
+}
+
+// validateDuplicateNamedGroups checks for duplicate named capturing groups
+// in the same alternative. (?<x>a)(?<x>b) is invalid but (?<x>a)|(?<x>b) is valid.
+func validateDuplicateNamedGroups(pattern string) error {
+	// Track named groups per alternative at each nesting level.
+	// We use a stack of sets: each entry is the set of names in the current alternative
+	// at that group nesting level.
+	type level struct {
+		names map[string]bool
+	}
+	stack := []level{{names: make(map[string]bool)}}
+
+	i := 0
+	for i < len(pattern) {
+		switch pattern[i] {
+		case '\\':
+			i += 2 // skip escaped char
+			continue
+		case '[':
+			// skip character class
+			i++
+			for i < len(pattern) {
+				if pattern[i] == '\\' {
+					i += 2
+					continue
+				}
+				if pattern[i] == ']' {
+					break
+				}
+				i++
+			}
+		case '(':
+			// Push a new level
+			stack = append(stack, level{names: make(map[string]bool)})
+			// Check for named group (?<name>...)
+			if i+3 < len(pattern) && pattern[i+1] == '?' && pattern[i+2] == '<' && pattern[i+3] != '=' && pattern[i+3] != '!' {
+				// Extract the name
+				nameStart := i + 3
+				nameEnd := nameStart
+				for nameEnd < len(pattern) && pattern[nameEnd] != '>' {
+					nameEnd++
+				}
+				if nameEnd < len(pattern) {
+					name := pattern[nameStart:nameEnd]
+					// Check in parent level (the alternative containing this group)
+					parent := &stack[len(stack)-2]
+					if parent.names[name] {
+						return fmt.Errorf("SyntaxError: Invalid regular expression: /%s/: Duplicate capture group name", pattern)
+					}
+					parent.names[name] = true
+				}
+			}
+		case ')':
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case '|':
+			// Reset names at current nesting level - new alternative
+			stack[len(stack)-1].names = make(map[string]bool)
+		}
+		i++
+	}
+	return nil
+}
+
+// isGoRegexpEscape returns true if \c is a valid Go regexp escape sequence
+// that has the SAME meaning in both JS and Go regexps.
+func isGoRegexpEscape(c byte) bool {
+	switch c {
+	case 'd', 'D', 'w', 'W', 's', 'S': // character classes
+		return true
+	case 'b', 'B': // word boundary
+		return true
+	case 'f', 'n', 'r', 't': // common escapes Go accepts
+		return true
+	}
+	// Regex metacharacters escaped: \. \* \+ \? \( \) \[ \] \{ \} \| \^ \$ \\
+	if isRegexpSpecial(c) {
+		return true
+	}
+	return false
+}
+
+// isRegexpSpecial returns true if the byte is a regexp metacharacter.
+func isRegexpSpecial(c byte) bool {
+	switch c {
+	case '.', '*', '+', '?', '(', ')', '[', ']', '{', '}', '|', '^', '$', '\\':
+		return true
+	}
+	return false
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+// isRegExp implements IsRegExp: an object's Symbol.match property, which may
+// be a getter with side effects, decides whether it is treated as a RegExp;
+// without one, only objects with a compiled pattern are.
+func isRegExp(obj *runtime.Object) bool {
+	if SymMatch != nil {
+		if matcher := obj.GetSymbol(SymMatch); matcher != nil && matcher.Type != runtime.TypeUndefined {
+			return matcher.ToBoolean()
+		}
+	}
+	return obj.Internal != nil && obj.Internal["regexp"] != nil
+}
+
+func getRegExp(this *runtime.Value) *regexp.Regexp {
+	obj := toObject(this)
+	if obj == nil || obj.Internal == nil {
+		return nil
+	}
+	re, ok := obj.Internal["regexp"].(*regexp.Regexp)
+	if !ok {
+		return nil
+	}
+	return re
+}
+
+// usesLeftContext reports whether pattern has an assertion that looks at
+// the text before the current position: ^, \b or \B outside a class.
+func usesLeftContext(pattern string) bool {
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			if !inClass && (pattern[i] == 'b' || pattern[i] == 'B') {
+				return true
+			}
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '^' && !inClass:
+			return true
+		}
+	}
+	return false
+}
+
+// regexpMatchFrom returns the leftmost match of re in s that starts at or
+// after the byte offset from, as byte offsets in the FindStringSubmatchIndex
+// layout. Go's regexp cannot start a search mid-string, so the search runs
+// on s[from:]. That would make ^, \b and \B treat from as the start of the
+// input, so patterns using them search all of s and skip matches that
+// start too early instead.
+func regexpMatchFrom(re *regexp.Regexp, leftContext bool, s string, from int) []int {
+	s = maskSurrogates(s)
+	if from == 0 {
+		return re.FindStringSubmatchIndex(s)
+	}
+	if from > len(s) {
+		return nil
+	}
+	if leftContext {
+		for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+			if loc[0] >= from {
+				return loc
+			}
+		}
+		return nil
+	}
+	loc := re.FindStringSubmatchIndex(s[from:])
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += from
+		}
+	}
+	return loc
+}
+
+// utf16Offset converts a byte offset in s to an index in UTF-16 code units,
+// the unit of JS string indices. Bytes are grouped into characters the way
+// stringToUTF16 groups them.
+func utf16Offset(s string, byteOffset int) int {
+	n := 0
+	for i := 0; i < byteOffset && i < len(s); {
+		size, units := utf8SeqLen(s[i])
+		i += size
+		n += units
+	}
+	return n
+}
+
+// byteOffset converts a UTF-16 index in s to a byte offset. An index inside
+// a surrogate pair moves to the end of that character.
+func byteOffset(s string, index int) int {
+	n := 0
+	i := 0
+	for i < len(s) && n < index {
+		size, units := utf8SeqLen(s[i])
+		i += size
+		n += units
+	}
+	if i > len(s) {
+		return len(s)
+	}
+	return i
+}
+
+// utf8SeqLen returns the byte length of the sequence that starts with b and
+// the number of UTF-16 code units it encodes.
+func utf8SeqLen(b byte) (size, units int) {
+	switch {
+	case b < 0xC0:
+		return 1, 1
+	case b < 0xE0:
+		return 2, 1
+	case b < 0xF0:
+		return 3, 1
+	}
+	return 4, 2
+}
+
+// advanceIndex returns the UTF-16 index of the character after the one at
+// index in s.
+func advanceIndex(s string, index int) int {
+	i := byteOffset(s, index)
+	if i >= len(s) {
+		return index + 1
+	}
+	_, units := utf8SeqLen(s[i])
+	return index + units
+}
+
+// setLastIndex assigns rx.lastIndex, failing like a strict-mode assignment
+// when the property is read-only.
+func setLastIndex(rx *runtime.Object, index *runtime.Value) error {
+	if prop, ok := rx.Properties["lastIndex"]; ok && !prop.IsAccessor && !prop.Writable {
+		return fmt.Errorf("TypeError: Cannot assign to read only property 'lastIndex'")
+	}
+	rx.Set("lastIndex", index)
+	return nil
+}
+
+// regexpBuiltinExec matches rx against s. Global and sticky regexps start at
+// lastIndex and update it; a sticky match must start exactly there. It
+// returns the match array or null.
+func (rl *Realm) regexpBuiltinExec(rx *runtime.Object, s string) (*runtime.Value, error) {
+	re, ok := rx.Internal["regexp"].(*regexp.Regexp)
+	if !ok {
+		return nil, fmt.Errorf("TypeError: RegExp.prototype.exec called on incompatible receiver")
+	}
+	flags, _ := rx.Internal["flags"].(string)
+	leftContext, _ := rx.Internal["leftContext"].(bool)
+	global := strings.Contains(flags, "g")
+	sticky := strings.Contains(flags, "y")
+
+	n, err := toIntegerErr(rx.Get("lastIndex"))
+	if err != nil {
+		return nil, err
+	}
+	lastIndex := 0
+	if (global || sticky) && n > 0 {
+		lastIndex = int(math.Min(n, math.MaxInt32))
+	}
+	fail := func() (*runtime.Value, error) {
+		if global || sticky {
+			if err := setLastIndex(rx, runtime.NewNumber(0)); err != nil {
+				return nil, err
+			}
+		}
+		return runtime.Null, nil
+	}
+	if lastIndex > utf16Offset(s, len(s)) {
+		return fail()
+	}
+	from := byteOffset(s, lastIndex)
+	loc := regexpMatchFrom(re, leftContext, s, from)
+	if loc == nil || (sticky && loc[0] != from) {
+		return fail()
+	}
+	if global || sticky {
+		if err := setLastIndex(rx, runtime.NewNumber(float64(utf16Offset(s, loc[1])))); err != nil {
+			return nil, err
+		}
+	}
+	return runtime.NewObject(rl.regexpMatchArray(re, s, loc)), nil
+}
+
+// regexpMatchArray builds the array exec returns: the match and its
+// captures, with index, input and groups properties.
+func (rl *Realm) regexpMatchArray(re *regexp.Regexp, s string, loc []int) *runtime.Object {
+	captures := make([]*runtime.Value, 0, len(loc)/2)
+	for i := 0; i < len(loc); i += 2 {
+		if loc[i] == -1 {
+			captures = append(captures, runtime.Undefined)
+		} else {
+			captures = append(captures, runtime.NewString(s[loc[i]:loc[i+1]]))
+		}
+	}
+	result := rl.newArray(captures)
+	result.Set("index", runtime.NewNumber(float64(utf16Offset(s, loc[0]))))
+	result.Set("input", runtime.NewString(s))
+	groups := runtime.Undefined
+	for i, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if groups == runtime.Undefined {
+			groups = runtime.NewObject(runtime.NewOrdinaryObject(nil))
+		}
+		groups.Object.Set(name, captures[i])
+	}
+	result.Set("groups", groups)
+	return result
+}
+
+// regexpExecAbstract implements RegExpExec: an exec method other than the
+// built-in one, as a subclass may define, is called instead of the matcher.
+func (rl *Realm) regexpExecAbstract(rx *runtime.Object, s string) (*runtime.Value, error) {
+	exec := getCallable(rx.Get("exec"))
+	if exec == nil {
+		return rl.regexpBuiltinExec(rx, s)
+	}
+	result, err := exec(runtime.NewObject(rx), []*runtime.Value{runtime.NewString(s)})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || (result.Type != runtime.TypeObject && result.Type != runtime.TypeNull) {
+		return nil, fmt.Errorf("TypeError: exec result must be an object or null")
+	}
+	return result, nil
+}
+
+// regexpReceiver returns this as an object and the string argument of a
+// RegExp.prototype method.
+func regexpReceiver(this *runtime.Value, args []*runtime.Value, method string) (*runtime.Object, string, error) {
+	rx := toObject(this)
+	if rx == nil {
+		return nil, "", fmt.Errorf("TypeError: RegExp.prototype.%s called on incompatible receiver", method)
+	}
+	s, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, "", err
+	}
+	return rx, s, nil
+}
+
+func (rl *Realm) regexpTest(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx, s, err := regexpReceiver(this, args, "test")
+	if err != nil {
+		return nil, err
+	}
+	result, err := rl.regexpExecAbstract(rx, s)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(result.Type != runtime.TypeNull), nil
+}
+
+func (rl *Realm) regexpExec(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx, s, err := regexpReceiver(this, args, "exec")
+	if err != nil {
+		return nil, err
+	}
+	return rl.regexpBuiltinExec(rx, s)
+}
+
+func canonicalizeFlags(flags string) string {
+	order := "dgimsuy"
+	var result strings.Builder
+	for _, c := range order {
+		if strings.ContainsRune(flags, c) {
+			result.WriteRune(c)
+		}
+	}
+	return result.String()
+}
+
+func regexpCompile(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil || obj.Internal == nil || obj.Internal["regexp"] == nil {
+		return nil, fmt.Errorf("TypeError: Method RegExp.prototype.compile called on incompatible receiver")
+	}
+
+	patternArg := argAt(args, 0)
+	flagsArg := argAt(args, 1)
+
+	var pattern, flags string
+
+	patternObj := toObject(patternArg)
+	isRegExp := patternObj != nil && patternObj.Internal != nil && patternObj.Internal["regexp"] != nil
+
+	if isRegExp {
+		if flagsArg.Type != runtime.TypeUndefined {
+			return nil, fmt.Errorf("TypeError: Cannot supply flags when constructing one RegExp from another")
+		}
+		if p, ok := patternObj.Internal["pattern"].(string); ok {
+			pattern = p
+		}
+		if f, ok := patternObj.Internal["flags"].(string); ok {
+			flags = f
+		}
+	} else {
+		if patternArg.Type == runtime.TypeUndefined {
+			pattern = ""
+		} else {
+			if patternArg.Type == runtime.TypeSymbol {
+				return nil, fmt.Errorf("TypeError: Cannot convert a Symbol value to a string")
+			}
+			s, err := jsToString(patternArg)
+			if err != nil {
+				return nil, err
+			}
+			pattern = s
+		}
+		if flagsArg.Type == runtime.TypeUndefined {
+			flags = ""
+		} else {
+			if flagsArg.Type == runtime.TypeSymbol {
+				return nil, fmt.Errorf("TypeError: Cannot convert a Symbol value to a string")
+			}
+			s, err := jsToString(flagsArg)
+			if err != nil {
+				return nil, err
+			}
+			flags = s
+		}
+	}
+
+	if err := validateRegExpFlags(flags); err != nil {
+		return nil, err
+	}
+
+	flags = canonicalizeFlags(flags)
+
+	if err := validateDuplicateNamedGroups(pattern); err != nil {
+		return nil, err
+	}
+
+	// Validate pattern in unicode mode
+	if strings.Contains(flags, "u") {
+		// In unicode mode, lone { and backreferences like \2 are invalid
+		for i := 0; i < len(pattern); i++ {
+			if pattern[i] == '{' {
+				return nil, fmt.Errorf("SyntaxError: Invalid regular expression: /%s/u: Lone quantifier brackets", pattern)
+			}
+			if pattern[i] == '\\' && i+1 < len(pattern) && pattern[i+1] >= '1' && pattern[i+1] <= '9' {
+				return nil, fmt.Errorf("SyntaxError: Invalid regular expression: /%s/u: Invalid escape", pattern)
+			}
+			if pattern[i] == '\\' && i+1 < len(pattern) {
+				i++ // skip escaped character
+			}
+		}
+	}
+
+	re, err := compileRegExp(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+	installRegExp(obj, re, pattern, flags)
+	if err := setLastIndex(obj, runtime.NewNumber(0)); err != nil {
+		return nil, err
+	}
+
+	return this, nil
+}
+
+func regexpToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	obj := toObject(this)
+	if obj == nil {
+		return runtime.NewString("/(?:)/"), nil
+	}
+	source := obj.Get("source").ToString()
+	flags := obj.Get("flags").ToString()
+	return runtime.NewString("/" + source + "/" + flags), nil
+}
+
+// regexpSymbolSplit implements RegExp.prototype[@@split].
+// Simplified implementation per spec 22.2.5.13.
+func (rl *Realm) regexpSymbolSplit(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx := toObject(this)
+	if rx == nil {
+		return nil, fmt.Errorf("TypeError: RegExp.prototype[@@split] called on incompatible receiver")
+	}
+
+	s, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: Get constructor C
+	// Per spec: C = SpeciesConstructor(rx, %RegExp%)
+	// For now, always use RegExp constructor
+
+	// Step 6: Get flags
+	flagsVal := rx.Get("flags")
+	flags := ""
+	if flagsVal != nil && flagsVal != runtime.Undefined {
+		flags = flagsVal.ToString()
+	}
+
+	// Step 7-8: Add 'y' (sticky) flag if not present
+	newFlags := flags
+	if !strings.Contains(newFlags, "y") {
+		newFlags += "y"
+	}
+
+	// Step 10: Construct splitter via RegExp constructor
+	// Per spec: Construct(C, « rx, newFlags »)
+	// This goes through the RegExp constructor, which calls IsRegExp(pattern)
+	// reading Symbol.match - triggering side effects like compile("b")
+	splitter, err := rl.regexpConstructorCall(runtime.Undefined, []*runtime.Value{this, runtime.NewString(newFlags)})
+	if err != nil {
+		return nil, err
+	}
+	splitterObj := toObject(splitter)
+	if splitterObj == nil {
+		return nil, fmt.Errorf("TypeError: RegExp constructor returned non-object")
+	}
+
+	// Step 13: Handle limit
+	var lim uint32
+	limitArg := argAt(args, 1)
+	if limitArg.Type == runtime.TypeUndefined {
+		lim = 0xFFFFFFFF
+	} else {
+		// ToUint32 - this triggers valueOf which is the second test's side-effect
+		limF, err := toNumberErr(limitArg)
+		if err != nil {
+			return nil, err
+		}
+		lim = uint32(limF)
+	}
+
+	if lim == 0 {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+
+	// Re-read the regexp from splitter (may have been recompiled by side effects)
+	re := getRegExp(splitter)
+	if re == nil {
+		return runtime.NewObject(rl.newArray(nil)), nil
+	}
+	leftContext, _ := splitterObj.Internal["leftContext"].(bool)
+
+	if len(s) == 0 {
+		// If string is empty, test if it matches
+		if loc := regexpMatchFrom(re, leftContext, s, 0); loc != nil {
+			return runtime.NewObject(rl.newArray(nil)), nil
+		}
+		return runtime.NewObject(rl.newArray([]*runtime.Value{runtime.NewString("")})), nil
+	}
+
+	// The spec tries a sticky match at each position q; searching from q
+	// finds the first position that matches directly. p is the end of the
+	// last separator.
+	var result []*runtime.Value
+	push := func(v *runtime.Value) (bool, error) {
+		if err := rl.Agent.ChargeSlots(1); err != nil {
+			return false, err
+		}
+		result = append(result, v)
+		return uint32(len(result)) >= lim, nil
+	}
+	p, q := 0, 0
+	for q < len(s) {
+		loc := regexpMatchFrom(re, leftContext, s, q)
+		if loc == nil || loc[0] >= len(s) {
+			break
+		}
+		if loc[1] == p {
+			// An empty separator right after the last one splits nothing.
+			size, _ := utf8SeqLen(s[q])
+			q += size
+			continue
+		}
+		if done, err := push(runtime.NewString(s[p:loc[0]])); err != nil {
+			return nil, err
+		} else if done {
+			return runtime.NewObject(rl.newArray(result)), nil
+		}
+		for i := 2; i < len(loc); i += 2 {
+			capture := runtime.Undefined
+			if loc[i] >= 0 {
+				capture = runtime.NewString(s[loc[i]:loc[i+1]])
+			}
+			if done, err := push(capture); err != nil {
+				return nil, err
+			} else if done {
+				return runtime.NewObject(rl.newArray(result)), nil
+			}
+		}
+		p = loc[1]
+		q = p
+	}
+
+	// Add remaining string
+	result = append(result, runtime.NewString(s[p:]))
+
+	return runtime.NewObject(rl.newArray(result)), nil
+}
+
+// regexpMatchAll runs rx against s the way @@match and @@replace do: once,
+// or for a global regexp repeatedly from lastIndex 0 until exec fails,
+// stepping past empty matches.
+func (rl *Realm) regexpMatchAll(rx *runtime.Object, s string) ([]*runtime.Object, error) {
+	flags, err := jsToString(rx.Get("flags"))
+	if err != nil {
+		return nil, err
+	}
+	global := strings.Contains(flags, "g")
+	if global {
+		if err := setLastIndex(rx, runtime.NewNumber(0)); err != nil {
+			return nil, err
+		}
+	}
+	var results []*runtime.Object
+	for {
+		result, err := rl.regexpExecAbstract(rx, s)
+		if err != nil {
+			return nil, err
+		}
+		if result.Type == runtime.TypeNull {
+			return results, nil
+		}
+		results = append(results, result.Object)
+		if !global {
+			return results, nil
+		}
+		matched, err := jsToString(elementAt(result.Object, 0))
+		if err != nil {
+			return nil, err
+		}
+		if matched == "" {
+			n, err := toIntegerErr(rx.Get("lastIndex"))
+			if err != nil {
+				return nil, err
+			}
+			next := advanceIndex(s, int(math.Max(0, math.Min(n, math.MaxInt32))))
+			if err := setLastIndex(rx, runtime.NewNumber(float64(next))); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// regexpSymbolMatch implements RegExp.prototype[@@match]: the exec result
+// for a non-global regexp, otherwise an array of every matched string.
+func (rl *Realm) regexpSymbolMatch(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx, s, err := regexpReceiver(this, args, "[Symbol.match]")
+	if err != nil {
+		return nil, err
+	}
+	flags, err := jsToString(rx.Get("flags"))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(flags, "g") {
+		return rl.regexpExecAbstract(rx, s)
+	}
+	results, err := rl.regexpMatchAll(rx, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return runtime.Null, nil
+	}
+	matches := make([]*runtime.Value, len(results))
+	for i, result := range results {
+		matched, err := jsToString(elementAt(result, 0))
+		if err != nil {
+			return nil, err
+		}
+		matches[i] = runtime.NewString(matched)
+	}
+	return runtime.NewObject(rl.newArray(matches)), nil
+}
+
+// regexpSymbolMatchAll implements RegExp.prototype[@@matchAll]. It matches
+// with a copy of the regexp that starts at this.lastIndex, so the iterator
+// it returns neither reads nor moves the original's lastIndex.
+func (rl *Realm) regexpSymbolMatchAll(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx, s, err := regexpReceiver(this, args, "[Symbol.matchAll]")
+	if err != nil {
+		return nil, err
+	}
+	source, err := jsToString(rx.Get("source"))
+	if err != nil {
+		return nil, err
+	}
+	flags, err := jsToString(rx.Get("flags"))
+	if err != nil {
+		return nil, err
+	}
+	lastIndex, err := toIntegerErr(rx.Get("lastIndex"))
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := rl.createRegExpObject(source, flags)
+	if err != nil {
+		return nil, err
+	}
+	if err := setLastIndex(matcher.Object, runtime.NewNumber(lastIndex)); err != nil {
+		return nil, err
+	}
+	iter := runtime.NewOrdinaryObject(rl.RegExpStringIteratorPrototype)
+	iter.Internal = map[string]interface{}{
+		"matcher": matcher.Object,
+		"string":  s,
+		"global":  strings.Contains(flags, "g"),
+	}
+	return runtime.NewObject(iter), nil
+}
+
+// regexpStringIteratorNext steps an iterator returned by matchAll. A global
+// iterator runs exec until it fails, stepping past empty matches; any other
+// yields the first match only.
+func (rl *Realm) regexpStringIteratorNext(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	iter := toObject(this)
+	if iter == nil || iter.Internal["matcher"] == nil {
+		return nil, fmt.Errorf("TypeError: next method called on incompatible receiver")
+	}
+	result := func(val *runtime.Value, done bool) (*runtime.Value, error) {
+		res := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+		res.Set("value", val)
+		res.Set("done", runtime.NewBool(done))
+		return runtime.NewObject(res), nil
+	}
+	if finished, _ := iter.Internal["done"].(bool); finished {
+		return result(runtime.Undefined, true)
+	}
+	rx := iter.Internal["matcher"].(*runtime.Object)
+	s := iter.Internal["string"].(string)
+	match, err := rl.regexpExecAbstract(rx, s)
+	if err != nil {
+		return nil, err
+	}
+	if match.Type == runtime.TypeNull {
+		iter.Internal["done"] = true
+		return result(runtime.Undefined, true)
+	}
+	if global, _ := iter.Internal["global"].(bool); !global {
+		iter.Internal["done"] = true
+		return result(match, false)
+	}
+	matched, err := jsToString(elementAt(match.Object, 0))
+	if err != nil {
+		return nil, err
+	}
+	if matched == "" {
+		n, err := toIntegerErr(rx.Get("lastIndex"))
+		if err != nil {
+			return nil, err
+		}
+		next := advanceIndex(s, int(math.Max(0, math.Min(n, math.MaxInt32))))
+		if err := setLastIndex(rx, runtime.NewNumber(float64(next))); err != nil {
+			return nil, err
+		}
+	}
+	return result(match, false)
+}
+
+// regexpSymbolReplace implements RegExp.prototype[@@replace]. The
+// replacement is either a function called with the match, its captures,
+// position and the input, or a template expanded by getSubstitution.
+func (rl *Realm) regexpSymbolReplace(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx, s, err := regexpReceiver(this, args, "[Symbol.replace]")
+	if err != nil {
+		return nil, err
+	}
+	replaceFn := getCallable(argAt(args, 1))
+	template := ""
+	if replaceFn == nil {
+		if template, err = jsToString(argAt(args, 1)); err != nil {
+			return nil, err
+		}
+	}
+	results, err := rl.regexpMatchAll(rx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	next := 0 // byte offset just past the last replaced match
+	for _, result := range results {
+		n, err := toIntegerErr(result.Get("length"))
+		if err != nil {
+			return nil, err
+		}
+		matched, err := jsToString(elementAt(result, 0))
+		if err != nil {
+			return nil, err
+		}
+		index, err := toIntegerErr(result.Get("index"))
+		if err != nil {
+			return nil, err
+		}
+		pos := byteOffset(s, int(math.Max(0, math.Min(index, math.MaxInt32))))
+		captures := make([]*runtime.Value, 0, int(math.Max(n-1, 0)))
+		for k := 1; k < int(n); k++ {
+			capture := elementAt(result, k)
+			if capture.Type != runtime.TypeUndefined {
+				str, err := jsToString(capture)
+				if err != nil {
+					return nil, err
+				}
+				capture = runtime.NewString(str)
+			}
+			captures = append(captures, capture)
+		}
+		groups := result.Get("groups")
+
+		var replacement string
+		if replaceFn != nil {
+			callArgs := append([]*runtime.Value{runtime.NewString(matched)}, captures...)
+			callArgs = append(callArgs, runtime.NewNumber(float64(utf16Offset(s, pos))), runtime.NewString(s))
+			if groups.Type != runtime.TypeUndefined {
+				callArgs = append(callArgs, groups)
+			}
+			val, err := replaceFn(runtime.Undefined, callArgs)
+			if err != nil {
+				return nil, err
+			}
+			if replacement, err = jsToString(val); err != nil {
+				return nil, err
+			}
+		} else {
+			if replacement, err = getSubstitution(matched, s, pos, captures, groups, template); err != nil {
+				return nil, err
+			}
+		}
+		if pos >= next {
+			if err := rl.Agent.Charge(int64(pos - next + len(replacement))); err != nil {
+				return nil, err
+			}
+			sb.WriteString(s[next:pos])
+			sb.WriteString(replacement)
+			next = min(pos+len(matched), len(s))
+		}
+	}
+	if err := rl.Agent.Charge(int64(len(s) - next)); err != nil {
+		return nil, err
+	}
+	sb.WriteString(s[next:])
+	return runtime.NewString(sb.String()), nil
+}
+
+// getSubstitution expands the $ patterns of a replacement template for a
+// match of matched at byte offset pos in str: $$, $&, $`, $', $n, $nn and
+// $<name>.
+func getSubstitution(matched, str string, pos int, captures []*runtime.Value, groups *runtime.Value, template string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '$' || i+1 >= len(template) {
+			sb.WriteByte(c)
+			continue
+		}
+		switch next := template[i+1]; {
+		case next == '$':
+			sb.WriteByte('$')
+			i++
+		case next == '&':
+			sb.WriteString(matched)
+			i++
+		case next == '`':
+			sb.WriteString(str[:pos])
+			i++
+		case next == '\'':
+			if end := pos + len(matched); end < len(str) {
+				sb.WriteString(str[end:])
+			}
+			i++
+		case next >= '0' && next <= '9':
+			n, width := int(next-'0'), 1
+			if i+2 < len(template) && template[i+2] >= '0' && template[i+2] <= '9' {
+				if two := n*10 + int(template[i+2]-'0'); two >= 1 && two <= len(captures) {
+					n, width = two, 2
+				}
+			}
+			if n < 1 || n > len(captures) {
+				sb.WriteByte('$')
+				continue
+			}
+			if capture := captures[n-1]; capture.Type != runtime.TypeUndefined {
+				sb.WriteString(capture.Str)
+			}
+			i += width
+		case next == '<' && toObject(groups) != nil:
+			end := strings.IndexByte(template[i+2:], '>')
+			if end < 0 {
+				sb.WriteByte('$')
+				continue
+			}
+			if capture := groups.Object.Get(template[i+2 : i+2+end]); capture.Type != runtime.TypeUndefined {
+				str, err := jsToString(capture)
+				if err != nil {
+					return "", err
+				}
+				sb.WriteString(str)
+			}
+			i += 2 + end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String(), nil
+}
+
+// regexpSymbolSearch implements RegExp.prototype[@@search]: the index of
+// the first match, or -1. lastIndex is left as it was.
+func (rl *Realm) regexpSymbolSearch(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	rx, s, err := regexpReceiver(this, args, "[Symbol.search]")
+	if err != nil {
+		return nil, err
+	}
+	previous := rx.Get("lastIndex")
+	if !runtime.SameValue(previous, runtime.NewNumber(0)) {
+		if err := setLastIndex(rx, runtime.NewNumber(0)); err != nil {
+			return nil, err
+		}
+	}
+	result, err := rl.regexpExecAbstract(rx, s)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.SameValue(rx.Get("lastIndex"), previous) {
+		if err := setLastIndex(rx, previous); err != nil {
+			return nil, err
+		}
+	}
+	if result.Type == runtime.TypeNull {
+		return runtime.NewNumber(-1), nil
+	}
+	return result.Object.Get("index"), nil
+}
@@ -0,0 +1,293 @@
+package builtins
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupRegExp() *Realm {
+	rl := newTestRealm()
+	rl.createObjectConstructor()
+	rl.createArrayConstructor(rl.ObjectPrototype)
+	rl.createRegExpConstructor(rl.ObjectPrototype)
+	return rl
+}
+
+func TestRegExpTest(t *testing.T) {
+	rl := setupRegExp()
+	re, err := rl.createRegExpObject("[0-9]+", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := rl.regexpTest(re, []*runtime.Value{runtime.NewString("hello123")})
+	if !result.Bool {
+		t.Error("test('hello123') should match")
+	}
+
+	result, _ = rl.regexpTest(re, []*runtime.Value{runtime.NewString("hello")})
+	if result.Bool {
+		t.Error("test('hello') should not match")
+	}
+}
+
+func TestRegExpExec(t *testing.T) {
+	rl := setupRegExp()
+	re, err := rl.createRegExpObject("(\\w+)@(\\w+)", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _ := rl.regexpExec(re, []*runtime.Value{runtime.NewString("user@host")})
+	if result.Type == runtime.TypeNull {
+		t.Fatal("exec should return match")
+	}
+	obj := toObject(result)
+	if obj == nil || len(obj.ArrayData) != 3 {
+		t.Fatalf("expected 3 groups, got %v", obj)
+	}
+	if obj.ArrayData[0].Str != "user@host" {
+		t.Errorf("full match: expected 'user@host', got %q", obj.ArrayData[0].Str)
+	}
+	if obj.ArrayData[1].Str != "user" {
+		t.Errorf("group 1: expected 'user', got %q", obj.ArrayData[1].Str)
+	}
+}
+
+func TestRegExpLoneSurrogates(t *testing.T) {
+	rl := setupRegExp()
+	hi, lo := "\xed\xa0\xbd", "\xed\xb8\x80"
+	tests := []struct{ pattern, input, want string }{
+		{".", hi, hi},
+		{"a.b", "a" + lo + "b", "a" + lo + "b"},
+		{"\\uD83D", "x" + hi, hi},
+		{"\\uD83D\\uDE00", "x😀", "😀"},
+		{"[\\uDC00-\\uDFFF]+", "a" + lo + lo, lo + lo},
+		{hi, "x" + hi, hi},
+		{"\\u0041", "A", "A"},
+	}
+	for _, tt := range tests {
+		re, err := rl.createRegExpObject(tt.pattern, "")
+		if err != nil {
+			t.Errorf("/%s/: %v", tt.pattern, err)
+			continue
+		}
+		result, err := rl.regexpExec(re, []*runtime.Value{runtime.NewString(tt.input)})
+		if err != nil || result.Type == runtime.TypeNull {
+			t.Errorf("/%s/.exec(%q) = %v, %v", tt.pattern, tt.input, result, err)
+			continue
+		}
+		if got := toObject(result).ArrayData[0].Str; got != tt.want {
+			t.Errorf("/%s/.exec(%q) matched %q, want %q", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+	// The surrogates of a pair are not lone.
+	re, _ := rl.createRegExpObject("\\uD83D", "")
+	if result, _ := rl.regexpTest(re, []*runtime.Value{runtime.NewString("😀")}); result.Bool {
+		t.Error("/\\uD83D/ should not match a surrogate pair")
+	}
+}
+
+func TestRegExpExecNoMatch(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("xyz", "")
+	result, _ := rl.regexpExec(re, []*runtime.Value{runtime.NewString("abc")})
+	if result.Type != runtime.TypeNull {
+		t.Error("exec should return null for no match")
+	}
+}
+
+func TestRegExpToString(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("abc", "gi")
+	result, _ := regexpToString(re, nil)
+	if result.Str != "/abc/gi" {
+		t.Errorf("toString: expected '/abc/gi', got %q", result.Str)
+	}
+}
+
+func TestRegExpCaseInsensitive(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("hello", "i")
+	result, _ := rl.regexpTest(re, []*runtime.Value{runtime.NewString("HELLO")})
+	if !result.Bool {
+		t.Error("case insensitive test should match")
+	}
+}
+
+func TestRegExpGlobalLastIndex(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("a", "g")
+	var got []float64
+	for {
+		result, err := rl.regexpExec(re, []*runtime.Value{runtime.NewString("banana")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Type == runtime.TypeNull {
+			break
+		}
+		got = append(got, result.Object.Get("index").Number, re.Object.Get("lastIndex").Number)
+	}
+	want := []float64{1, 2, 3, 4, 5, 6}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	if li := re.Object.Get("lastIndex").Number; li != 0 {
+		t.Errorf("lastIndex after failed match: got %v, want 0", li)
+	}
+}
+
+func TestRegExpSticky(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("a", "y")
+	input := []*runtime.Value{runtime.NewString("banana")}
+	if result, _ := rl.regexpTest(re, input); result.Bool {
+		t.Error("sticky match at 0 should fail")
+	}
+	re.Object.Set("lastIndex", runtime.NewNumber(1))
+	if result, _ := rl.regexpTest(re, input); !result.Bool {
+		t.Error("sticky match at 1 should succeed")
+	}
+	if li := re.Object.Get("lastIndex").Number; li != 2 {
+		t.Errorf("lastIndex: got %v, want 2", li)
+	}
+}
+
+func TestRegExpMultilineAndAnchors(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("^b", "m")
+	if result, _ := rl.regexpTest(re, []*runtime.Value{runtime.NewString("a\nb")}); !result.Bool {
+		t.Error("m flag: ^ should match after a newline")
+	}
+	// A search resumed at lastIndex must not treat that position as the
+	// start of input.
+	re, _ = rl.createRegExpObject("^a", "g")
+	result, _ := rl.regexpSymbolMatch(re, []*runtime.Value{runtime.NewString("aaa")})
+	if arr := toObject(result); arr == nil || len(arr.ArrayData) != 1 {
+		t.Errorf("^a with g flag: expected a single match, got %v", result)
+	}
+}
+
+func TestRegExpSymbolReplace(t *testing.T) {
+	rl := setupRegExp()
+	tests := []struct {
+		pattern, flags, input, replacement, want string
+	}{
+		{"(\\w)", "g", "xy", "$1$1", "xxyy"},
+		{"b", "", "abc", "[$`|$&|$']", "a[a|b|c]c"},
+		{"(?<y>\\d+)-(?<m>\\d+)", "", "2024-05", "$<m>/$<y>", "05/2024"},
+		{"x", "g", "axbx", "$$", "a$b$"},
+		{"", "g", "ab", "-", "-a-b-"},
+		{"ö", "", "wörld", "o", "world"},
+	}
+	for _, tt := range tests {
+		re, err := rl.createRegExpObject(tt.pattern, tt.flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := rl.regexpSymbolReplace(re, []*runtime.Value{runtime.NewString(tt.input), runtime.NewString(tt.replacement)})
+		if err != nil {
+			t.Errorf("/%s/%s: %v", tt.pattern, tt.flags, err)
+			continue
+		}
+		if result.Str != tt.want {
+			t.Errorf("%q.replace(/%s/%s, %q): got %q, want %q", tt.input, tt.pattern, tt.flags, tt.replacement, result.Str, tt.want)
+		}
+	}
+
+	re, _ := rl.createRegExpObject("\\d+", "g")
+	fn := rl.newFuncObject("", 2, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewString("<" + args[0].Str + args[1].ToString() + ">"), nil
+	})
+	result, _ := rl.regexpSymbolReplace(re, []*runtime.Value{runtime.NewString("a1b22"), runtime.NewObject(fn)})
+	if result.Str != "a<11>b<223>" {
+		t.Errorf("function replacer: got %q", result.Str)
+	}
+}
+
+func TestRegExpSymbolSearchAndSplit(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("l+", "g")
+	re.Object.Set("lastIndex", runtime.NewNumber(3))
+	result, _ := rl.regexpSymbolSearch(re, []*runtime.Value{runtime.NewString("hello")})
+	if result.Number != 2 {
+		t.Errorf("search: got %v, want 2", result.Number)
+	}
+	if li := re.Object.Get("lastIndex").Number; li != 3 {
+		t.Errorf("search should restore lastIndex: got %v", li)
+	}
+
+	re, _ = rl.createRegExpObject("([,;])", "")
+	result, err := rl.regexpSymbolSplit(re, []*runtime.Value{runtime.NewString("a,b;c")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parts []string
+	for _, v := range toObject(result).ArrayData {
+		parts = append(parts, v.Str)
+	}
+	if got := strings.Join(parts, "|"); got != "a|,|b|;|c" {
+		t.Errorf("split with captures: got %q", got)
+	}
+}
+
+func TestRegExpDotAll(t *testing.T) {
+	rl := setupRegExp()
+	re, _ := rl.createRegExpObject("a.b", "s")
+	if !re.Object.Get("dotAll").Bool {
+		t.Error("dotAll should be true with the s flag")
+	}
+	result, _ := rl.regexpTest(re, []*runtime.Value{runtime.NewString("a\nb")})
+	if !result.Bool {
+		t.Error("/a.b/s should match across a newline")
+	}
+	plain, _ := rl.createRegExpObject("a.b", "")
+	if plain.Object.Get("dotAll").Bool {
+		t.Error("dotAll should be false without the s flag")
+	}
+}
+
+func TestStringMatchAll(t *testing.T) {
+	rl := registerTestRealm()
+	collect := func(s string, pattern *runtime.Value) []string {
+		t.Helper()
+		iter, err := rl.stringMatchAll(runtime.NewString(s), []*runtime.Value{pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		err = rl.iterate(iter, func(m *runtime.Value) error {
+			got = append(got, m.Object.ArrayData[0].Str+"@"+m.Object.Get("index").ToString())
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	re, _ := rl.createRegExpObject("\\d+", "g")
+	re.Object.Set("lastIndex", runtime.NewNumber(2))
+	if got := strings.Join(collect("a1b22c333", re), " "); got != "22@3 333@6" {
+		t.Errorf("matchAll(/\\d+/g) from lastIndex 2: got %q", got)
+	}
+	if re.Object.Get("lastIndex").Number != 2 {
+		t.Error("matchAll should not move the regexp's lastIndex")
+	}
+	if got := strings.Join(collect("ab", runtime.NewString("")), " "); got != "@0 @1 @2" {
+		t.Errorf("matchAll(''): got %q", got)
+	}
+
+	nonGlobal, _ := rl.createRegExpObject("a", "")
+	if _, err := rl.stringMatchAll(runtime.NewString("a"), []*runtime.Value{nonGlobal}); err == nil || !strings.Contains(err.Error(), "non-global") {
+		t.Errorf("matchAll with a non-global RegExp: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package builtins
+
+import (
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// RegisterAll declares the built-ins of realm in env, making them its
+// intrinsics, and returns the realm with the built-ins installed.
+// globalObj, if not nil, is made to inherit from the realm's
+// Object.prototype.
+//
+// Every object a script can change is the realm's own, so realms stay
+// isolated. What no script can change is shared: the name values of the
+// built-in functions are created once per process, and each function is
+// laid out with its properties in a single allocation (see builtinFunc).
+// BenchmarkRegisterAll measures the cost of a realm.
+func RegisterAll(realm *runtime.Realm, env *runtime.Environment, globalObj *runtime.Object) *Realm {
+	rl := &Realm{Realm: realm}
+
+	// 1. Object (foundational - other prototypes derive from it)
+	objectCtor, objProto := rl.createObjectConstructor()
+	env.Declare("Object", "var", runtime.NewObject(objectCtor))
+
+	// 2. Function
+	functionCtor, _ := rl.createFunctionConstructor(objProto)
+	env.Declare("Function", "var", runtime.NewObject(functionCtor))
+
+	// Fix up Object ctor/proto methods that were created before FunctionPrototype existed
+	rl.setFuncPrototypeRecursive(objectCtor)
+	rl.setFuncPrototypeRecursive(objProto)
+	// Also fix the Function ctor itself
+	rl.setFuncPrototypeRecursive(functionCtor)
+
+	// 3. Array
+	arrayCtor, arrayProto := rl.createArrayConstructor(objProto)
+	env.Declare("Array", "var", runtime.NewObject(arrayCtor))
+
+	// 4. String
+	stringCtor, stringProto := rl.createStringConstructor(objProto)
+	env.Declare("String", "var", runtime.NewObject(stringCtor))
+
+	// 5. Number
+	numberCtor, _ := rl.createNumberConstructor(objProto)
+	env.Declare("Number", "var", runtime.NewObject(numberCtor))
+
+	// 6. Boolean
+	booleanCtor, _ := rl.createBooleanConstructor(objProto)
+	env.Declare("Boolean", "var", runtime.NewObject(booleanCtor))
+
+	// 7. Symbol
+	symbolCtor, _ := rl.createSymbolConstructor(objProto)
+	env.Declare("Symbol", "var", runtime.NewObject(symbolCtor))
+	rl.installIteratorMethods(arrayProto, stringProto)
+	installArrayUnscopables(arrayProto)
+
+	// 8. Error types
+	errorCtor := rl.createErrorConstructor(objProto)
+	env.Declare("Error", "var", runtime.NewObject(errorCtor))
+
+	typeErrorCtor := rl.createErrorSubtype("TypeError", objProto, rl.ErrorPrototype)
+	env.Declare("TypeError", "var", runtime.NewObject(typeErrorCtor))
+
+	refErrorCtor := rl.createErrorSubtype("ReferenceError", objProto, rl.ErrorPrototype)
+	env.Declare("ReferenceError", "var", runtime.NewObject(refErrorCtor))
+
+	syntaxErrorCtor := rl.createErrorSubtype("SyntaxError", objProto, rl.ErrorPrototype)
+	env.Declare("SyntaxError", "var", runtime.NewObject(syntaxErrorCtor))
+
+	rangeErrorCtor := rl.createErrorSubtype("RangeError", objProto, rl.ErrorPrototype)
+	env.Declare("RangeError", "var", runtime.NewObject(rangeErrorCtor))
+
+	uriErrorCtor := rl.createErrorSubtype("URIError", objProto, rl.ErrorPrototype)
+	env.Declare("URIError", "var", runtime.NewObject(uriErrorCtor))
+
+	evalErrorCtor := rl.createErrorSubtype("EvalError", objProto, rl.ErrorPrototype)
+	env.Declare("EvalError", "var", runtime.NewObject(evalErrorCtor))
+
+	// 9. RegExp
+	regexpCtor, _ := rl.createRegExpConstructor(objProto)
+	env.Declare("RegExp", "var", runtime.NewObject(regexpCtor))
+
+	// 10. Map, Set, WeakMap, WeakSet
+	mapCtor, _ := rl.createMapConstructor(objProto)
+	env.Declare("Map", "var", runtime.NewObject(mapCtor))
+
+	setCtor, _ := rl.createSetConstructor(objProto)
+	env.Declare("Set", "var", runtime.NewObject(setCtor))
+
+	weakMapCtor := rl.createWeakMapConstructor(objProto)
+	env.Declare("WeakMap", "var", runtime.NewObject(weakMapCtor))
+
+	weakSetCtor := rl.createWeakSetConstructor(objProto)
+	env.Declare("WeakSet", "var", runtime.NewObject(weakSetCtor))
+
+	// 11. Promise
+	promiseCtor, _ := rl.createPromiseConstructor(objProto)
+	env.Declare("Promise", "var", runtime.NewObject(promiseCtor))
+	rl.NewPromise = rl.newPromiseCapability
+	rl.AwaitValue = rl.awaitValue
+
+	// 12. Proxy and Reflect
+	proxyCtor := rl.createProxyConstructor(objProto)
+	env.Declare("Proxy", "var", runtime.NewObject(proxyCtor))
+
+	reflectObj := rl.createReflectObject(objProto)
+	env.Declare("Reflect", "var", runtime.NewObject(reflectObj))
+
+	// 13. Math
+	mathObj := rl.createMathObject(objProto)
+	env.Declare("Math", "var", runtime.NewObject(mathObj))
+
+	// 14. JSON
+	jsonObj := rl.createJSONObject(objProto)
+	env.Declare("JSON", "var", runtime.NewObject(jsonObj))
+
+	// 15. Console
+	consoleObj := rl.createConsoleObject(objProto)
+	env.Declare("console", "var", runtime.NewObject(consoleObj))
+
+	// 16. Date
+	dateCtor, _ := rl.createDateConstructor(objProto)
+	env.Declare("Date", "var", runtime.NewObject(dateCtor))
+
+	// 17. Global functions (parseInt, parseFloat, isNaN, etc.)
+	rl.registerGlobalFunctions(env)
+	// Number.parseInt and Number.parseFloat are the global functions
+	// themselves, not copies.
+	for _, name := range []string{"parseInt", "parseFloat"} {
+		if fn, err := env.Get(name); err == nil {
+			setDataProp(numberCtor, name, fn, true, false, true)
+		}
+	}
+
+	// 18. Timers
+	rl.registerTimers(env)
+
+	// 19. structuredClone
+	rl.registerStructuredClone(env)
+	rl.CloneBuiltin = rl.cloneBuiltin
+	rl.GetOwnProperty = rl.getOwnProperty
+
+	// 20. Set up global object properties if provided
+	if globalObj != nil {
+		globalObj.Prototype = objProto
+	}
+
+	return rl
+}
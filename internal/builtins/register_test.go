@@ -3,14 +3,14 @@ package builtins
 import (
 	"testing"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
 func TestRegisterAll(t *testing.T) {
 	env := runtime.NewEnvironment(nil, false)
 	globalObj := runtime.NewOrdinaryObject(nil)
 
-	RegisterAll(env, globalObj)
+	RegisterAll(runtime.NewRealm(runtime.NewAgent()), env, globalObj)
 
 	// Check that core built-ins are registered
 	names := []string{
@@ -0,0 +1,924 @@
+package builtins
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func (rl *Realm) createStringConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	rl.StringPrototype = proto
+
+	method := func(name string, length int, fn runtime.CallableFunc) {
+		rl.setMethod(proto, name, length, coercibleThis(name, fn))
+	}
+	method("charAt", 1, stringCharAt)
+	method("charCodeAt", 1, stringCharCodeAt)
+	method("codePointAt", 1, stringCodePointAt)
+	method("indexOf", 1, stringIndexOf)
+	method("lastIndexOf", 1, stringLastIndexOf)
+	method("includes", 1, stringIncludes)
+	method("startsWith", 1, stringStartsWith)
+	method("endsWith", 1, stringEndsWith)
+	method("slice", 2, stringSlice)
+	method("substring", 2, stringSubstring)
+	method("substr", 2, stringSubstr)
+	method("toUpperCase", 0, stringToUpperCase)
+	method("toLowerCase", 0, stringToLowerCase)
+	method("trim", 0, stringTrim)
+	method("trimStart", 0, stringTrimStart)
+	method("trimEnd", 0, stringTrimEnd)
+	method("repeat", 1, rl.stringRepeat)
+	method("padStart", 1, rl.stringPadStart)
+	method("padEnd", 1, rl.stringPadEnd)
+	method("split", 1, rl.stringSplit)
+	method("replace", 2, rl.stringReplace)
+	method("replaceAll", 2, rl.stringReplaceAll)
+	method("match", 1, rl.stringMatch)
+	method("matchAll", 1, rl.stringMatchAll)
+	method("search", 1, rl.stringSearch)
+	method("concat", 1, rl.stringConcat)
+	method("normalize", 0, stringNormalize)
+	rl.setMethod(proto, "toString", 0, stringToString)
+	rl.setMethod(proto, "valueOf", 0, stringValueOf)
+	method("at", 1, stringAt)
+	method("localeCompare", 1, stringLocaleCompare)
+	method("toLocaleLowerCase", 0, stringToLowerCase)
+	method("toLocaleUpperCase", 0, stringToUpperCase)
+	method("isWellFormed", 0, stringIsWellFormed)
+	method("toWellFormed", 0, stringToWellFormed)
+	// Annex B aliases - must be the SAME function object as the original
+	trimStartProp := proto.Properties["trimStart"]
+	proto.DefineProperty("trimLeft", trimStartProp)
+	trimEndProp := proto.Properties["trimEnd"]
+	proto.DefineProperty("trimRight", trimEndProp)
+	// Annex B HTML methods
+	rl.setMethod(proto, "anchor", 1, makeHTMLWrapper("a", "name"))
+	rl.setMethod(proto, "big", 0, makeHTMLSimple("big"))
+	rl.setMethod(proto, "blink", 0, makeHTMLSimple("blink"))
+	rl.setMethod(proto, "bold", 0, makeHTMLSimple("b"))
+	rl.setMethod(proto, "fixed", 0, makeHTMLSimple("tt"))
+	rl.setMethod(proto, "fontcolor", 1, makeHTMLWrapper("font", "color"))
+	rl.setMethod(proto, "fontsize", 1, makeHTMLWrapper("font", "size"))
+	rl.setMethod(proto, "italics", 0, makeHTMLSimple("i"))
+	rl.setMethod(proto, "link", 1, makeHTMLWrapper("a", "href"))
+	rl.setMethod(proto, "small", 0, makeHTMLSimple("small"))
+	rl.setMethod(proto, "strike", 0, makeHTMLSimple("strike"))
+	rl.setMethod(proto, "sub", 0, makeHTMLSimple("sub"))
+	rl.setMethod(proto, "sup", 0, makeHTMLSimple("sup"))
+
+	ctor := rl.newFuncObject("String", 1, stringConstructorCall)
+	ctor.Constructor = rl.stringConstruct
+
+	rl.setMethod(ctor, "fromCharCode", 1, stringFromCharCode)
+	rl.setMethod(ctor, "fromCodePoint", 1, stringFromCodePoint)
+	rl.setMethod(ctor, "raw", 1, stringRaw)
+
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	return ctor, proto
+}
+
+func getStringValue(this *runtime.Value) string {
+	s, _ := getStringValueErr(this)
+	return s
+}
+
+func getStringValueErr(this *runtime.Value) (string, error) {
+	if this == nil {
+		return "", nil
+	}
+	if this.Type == runtime.TypeString {
+		return this.Str, nil
+	}
+	if this.Type == runtime.TypeObject && this.Object != nil {
+		if s, ok := runtime.StringData(this.Object); ok {
+			return s, nil
+		}
+		return jsToString(this)
+	}
+	return this.ToString(), nil
+}
+
+func stringConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 0 {
+		return runtime.NewString(""), nil
+	}
+	// String(symbol) is the one conversion of a symbol to a string that
+	// does not throw.
+	if args[0].Type == runtime.TypeSymbol {
+		return runtime.NewString(args[0].ToString()), nil
+	}
+	s, err := jsToString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(s), nil
+}
+
+// stringConstruct is new String(value): it makes this a String object
+// wrapping the string String(value) returns.
+func (rl *Realm) stringConstruct(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := ""
+	if len(args) > 0 {
+		v, err := stringConstructorCall(nil, args)
+		if err != nil {
+			return nil, err
+		}
+		s = v.Str
+	}
+	if this == nil || this.Type != runtime.TypeObject || this.Object == nil {
+		return runtime.NewObject(rl.NewStringObject(s)), nil
+	}
+	runtime.SetStringData(this.Object, s)
+	return this, nil
+}
+
+func stringCharAt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	idx := 0
+	if len(args) > 0 {
+		idx = int(toInteger(args[0]))
+	}
+	if idx < 0 || idx >= runtime.UTF16Length(s) {
+		return runtime.NewString(""), nil
+	}
+	return runtime.NewString(runtime.UTF16Slice(s, idx, idx+1)), nil
+}
+
+func stringCharCodeAt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	idx := 0
+	if len(args) > 0 {
+		idx = int(toInteger(args[0]))
+	}
+	cu, ok := runtime.CodeUnitAt(s, idx)
+	if !ok {
+		return runtime.NaN, nil
+	}
+	return runtime.NewNumber(float64(cu)), nil
+}
+
+// stringCodePointAt implements String.prototype.codePointAt: the code point
+// starting at a code unit index, which is a whole surrogate pair at the
+// index of its first half and a lone surrogate at the second's.
+func stringCodePointAt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	idx := 0
+	if len(args) > 0 {
+		idx = int(toInteger(args[0]))
+	}
+	cp, ok := runtime.CodePointAt(s, idx)
+	if !ok {
+		return runtime.Undefined, nil
+	}
+	return runtime.NewNumber(float64(cp)), nil
+}
+
+// stringIterator implements String.prototype[Symbol.iterator], which yields
+// the string one code point at a time: a surrogate pair together, a lone
+// surrogate on its own.
+func (rl *Realm) stringIterator(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: String.prototype[Symbol.iterator] called on null or undefined")
+	}
+	s, err := getStringValueErr(this)
+	if err != nil {
+		return nil, err
+	}
+	points := runtime.CodePoints(s)
+	idx := 0
+	iter := &runtime.Object{
+		OType:      runtime.ObjTypeIterator,
+		Properties: make(map[string]*runtime.Property),
+		IteratorNext: func() (*runtime.Value, bool) {
+			if idx >= len(points) {
+				return runtime.Undefined, true
+			}
+			v := runtime.NewString(points[idx])
+			idx++
+			return v, false
+		},
+	}
+	rl.setIteratorMethods(iter)
+	return runtime.NewObject(iter), nil
+}
+
+func stringIndexOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	search := argAt(args, 0).ToString()
+	pos := 0
+	if len(args) > 1 {
+		pos = int(toInteger(args[1]))
+		if pos < 0 {
+			pos = 0
+		}
+	}
+	if length := runtime.UTF16Length(s); pos > length {
+		pos = length
+	}
+	return runtime.NewNumber(float64(runtime.UTF16Index(s, search, pos))), nil
+}
+
+func stringLastIndexOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	search := argAt(args, 0).ToString()
+	pos := runtime.UTF16Length(s)
+	// A NaN position, undefined included, searches from the end.
+	if n := toNumber(argAt(args, 1)); !isNaN(n) {
+		pos = int(math.Max(0, math.Min(math.Trunc(n), float64(pos))))
+	}
+	return runtime.NewNumber(float64(runtime.UTF16LastIndex(s, search, pos))), nil
+}
+
+func stringIncludes(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	search := argAt(args, 0).ToString()
+	pos := 0
+	if len(args) > 1 {
+		pos = int(toInteger(args[1]))
+		if pos < 0 {
+			pos = 0
+		}
+	}
+	if pos > runtime.UTF16Length(s) {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(runtime.UTF16Index(s, search, pos) >= 0), nil
+}
+
+func stringStartsWith(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	search := argAt(args, 0).ToString()
+	pos := 0
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		pos = int(toInteger(args[1]))
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	length := runtime.UTF16Length(s)
+	if pos > length {
+		return runtime.False, nil
+	}
+	return runtime.NewBool(strings.HasPrefix(runtime.UTF16Slice(s, pos, length), search)), nil
+}
+
+func stringEndsWith(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	search := argAt(args, 0).ToString()
+	length := runtime.UTF16Length(s)
+	end := length
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		end = int(toInteger(args[1]))
+	}
+	if end < 0 {
+		end = 0
+	}
+	if end > length {
+		end = length
+	}
+	return runtime.NewBool(strings.HasSuffix(runtime.UTF16Slice(s, 0, end), search)), nil
+}
+
+func stringSlice(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	length := runtime.UTF16Length(s)
+	start := 0
+	end := length
+	if len(args) > 0 {
+		start = int(toInteger(args[0]))
+		if start < 0 {
+			start = length + start
+			if start < 0 {
+				start = 0
+			}
+		}
+	}
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		end = int(toInteger(args[1]))
+		if end < 0 {
+			end = length + end
+			if end < 0 {
+				end = 0
+			}
+		}
+	}
+	if start > length {
+		start = length
+	}
+	if end > length {
+		end = length
+	}
+	if start >= end {
+		return runtime.NewString(""), nil
+	}
+	return runtime.NewString(runtime.UTF16Slice(s, start, end)), nil
+}
+
+func stringSubstring(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	length := runtime.UTF16Length(s)
+	start := 0
+	end := length
+	if len(args) > 0 {
+		start = int(toInteger(args[0]))
+	}
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		end = int(toInteger(args[1]))
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end < 0 {
+		end = 0
+	}
+	if start > length {
+		start = length
+	}
+	if end > length {
+		end = length
+	}
+	if start > end {
+		start, end = end, start
+	}
+	return runtime.NewString(runtime.UTF16Slice(s, start, end)), nil
+}
+
+func stringSubstr(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+		return nil, fmt.Errorf("TypeError: Cannot read properties of %s", this.ToString())
+	}
+	s, err := getStringValueErr(this)
+	if err != nil {
+		return nil, err
+	}
+	length := runtime.UTF16Length(s)
+	intStart, err2 := toIntegerErr(argAt(args, 0))
+	if err2 != nil {
+		return nil, err2
+	}
+	if math.IsInf(intStart, -1) {
+		intStart = 0
+	} else if intStart < 0 {
+		intStart = math.Max(float64(length)+intStart, 0)
+	} else if intStart > float64(length) {
+		intStart = float64(length)
+	}
+	start := int(intStart)
+	if start < 0 {
+		start = 0
+	}
+	if start > length {
+		start = length
+	}
+	var intLength float64
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		intLength, err2 = toIntegerErr(args[1])
+		if err2 != nil {
+			return nil, err2
+		}
+	} else {
+		intLength = float64(length)
+	}
+	count := int(math.Min(math.Max(intLength, 0), float64(length)))
+	if start >= length || count == 0 {
+		return runtime.NewString(""), nil
+	}
+	end := start + count
+	if end > length {
+		end = length
+	}
+	return runtime.NewString(runtime.UTF16Slice(s, start, end)), nil
+}
+
+func stringToUpperCase(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(runtime.MapWellFormed(getStringValue(this), strings.ToUpper)), nil
+}
+
+func stringToLowerCase(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(runtime.MapWellFormed(getStringValue(this), strings.ToLower)), nil
+}
+
+func stringTrim(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(strings.TrimSpace(getStringValue(this))), nil
+}
+
+func stringTrimStart(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(strings.TrimLeft(getStringValue(this), " \t\n\r\v\f")), nil
+}
+
+func stringTrimEnd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(strings.TrimRight(getStringValue(this), " \t\n\r\v\f")), nil
+}
+
+func (rl *Realm) stringRepeat(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	count := 0
+	if len(args) > 0 {
+		count = int(toInteger(args[0]))
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("RangeError: Invalid count value")
+	}
+	if len(s) > 0 && count > math.MaxInt64/len(s) {
+		return nil, fmt.Errorf("RangeError: Invalid string length")
+	}
+	if err := rl.Agent.Charge(int64(len(s)) * int64(count)); err != nil {
+		return nil, err
+	}
+	return runtime.NewString(strings.Repeat(s, count)), nil
+}
+
+func (rl *Realm) stringPadStart(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	targetLen := 0
+	if len(args) > 0 {
+		targetLen = int(toInteger(args[0]))
+	}
+	padStr := " "
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		padStr = args[1].ToString()
+	}
+	length := runtime.UTF16Length(s)
+	if length >= targetLen || padStr == "" {
+		return runtime.NewString(s), nil
+	}
+	needed := targetLen - length
+	if err := rl.Agent.Charge(int64(len(s)) + int64(needed)*int64(len(padStr))); err != nil {
+		return nil, err
+	}
+	padding := padString(padStr, needed)
+	return runtime.NewString(padding + s), nil
+}
+
+func (rl *Realm) stringPadEnd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	targetLen := 0
+	if len(args) > 0 {
+		targetLen = int(toInteger(args[0]))
+	}
+	padStr := " "
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		padStr = args[1].ToString()
+	}
+	length := runtime.UTF16Length(s)
+	if length >= targetLen || padStr == "" {
+		return runtime.NewString(s), nil
+	}
+	needed := targetLen - length
+	if err := rl.Agent.Charge(int64(len(s)) + int64(needed)*int64(len(padStr))); err != nil {
+		return nil, err
+	}
+	padding := padString(padStr, needed)
+	return runtime.NewString(s + padding), nil
+}
+
+// padString repeats padStr and cuts it to needed code units, for padStart
+// and padEnd.
+func padString(padStr string, needed int) string {
+	padding := strings.Repeat(padStr, needed/runtime.UTF16Length(padStr)+1)
+	return runtime.UTF16Slice(padding, 0, needed)
+}
+
+func (rl *Realm) stringSplit(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	if splitter, err := symbolMethod(argAt(args, 0), SymSplit); err != nil || splitter != nil {
+		if err != nil {
+			return nil, err
+		}
+		return splitter(args[0], []*runtime.Value{runtime.NewString(s), argAt(args, 1)})
+	}
+	if len(args) == 0 || args[0].Type == runtime.TypeUndefined {
+		return rl.createStringArray([]string{s}), nil
+	}
+	sep := args[0].ToString()
+	limit := -1
+	if len(args) > 1 && args[1].Type != runtime.TypeUndefined {
+		limit = int(toUint32(args[1]))
+	}
+	var parts []string
+	if sep == "" {
+		// An empty separator splits s into code units, cutting
+		// surrogate pairs.
+		units := runtime.UTF16(s)
+		if err := rl.Agent.ChargeSlots(len(units)); err != nil {
+			return nil, err
+		}
+		parts = make([]string, len(units))
+		for i, cu := range units {
+			parts[i] = runtime.FromCodePoint(rune(cu))
+		}
+	} else {
+		if err := rl.Agent.ChargeSlots(strings.Count(s, sep) + 1); err != nil {
+			return nil, err
+		}
+		parts = strings.Split(s, sep)
+	}
+	if limit >= 0 && len(parts) > limit {
+		parts = parts[:limit]
+	}
+	return rl.createStringArray(parts), nil
+}
+
+func (rl *Realm) stringReplace(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.replaceString(getStringValue(this), args, false)
+}
+
+// stringReplaceAll implements String.prototype.replaceAll. A RegExp search
+// value must have the g flag; it then behaves as in replace.
+func (rl *Realm) stringReplaceAll(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if rx := toObject(argAt(args, 0)); rx != nil && isRegExp(rx) {
+		flags, err := jsToString(rx.Get("flags"))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(flags, "g") {
+			return nil, fmt.Errorf("TypeError: replaceAll must be called with a global RegExp")
+		}
+	}
+	return rl.replaceString(getStringValue(this), args, true)
+}
+
+// replaceString replaces the first occurrence of args[0] in s, or every
+// occurrence if all is set. A search value with a Symbol.replace method,
+// such as a RegExp, does the replacement itself. The replacement args[1] is
+// either a function called with the match, its position and s, or a
+// template with $ patterns.
+func (rl *Realm) replaceString(s string, args []*runtime.Value, all bool) (*runtime.Value, error) {
+	searchValue, replaceValue := argAt(args, 0), argAt(args, 1)
+	if replacer, err := symbolMethod(searchValue, SymReplace); err != nil || replacer != nil {
+		if err != nil {
+			return nil, err
+		}
+		return replacer(searchValue, []*runtime.Value{runtime.NewString(s), replaceValue})
+	}
+	search, err := jsToString(searchValue)
+	if err != nil {
+		return nil, err
+	}
+	replaceFn := getCallable(replaceValue)
+	template := ""
+	if replaceFn == nil {
+		if template, err = jsToString(replaceValue); err != nil {
+			return nil, err
+		}
+	}
+
+	// An empty search string matches between every pair of characters.
+	var positions []int
+	for pos := strings.Index(s, search); pos >= 0; {
+		positions = append(positions, pos)
+		if !all || (search == "" && pos == len(s)) {
+			break
+		}
+		next := pos + len(search)
+		if search == "" {
+			size, _ := utf8SeqLen(s[pos])
+			next += size
+		}
+		i := strings.Index(s[next:], search)
+		if i < 0 {
+			break
+		}
+		pos = next + i
+	}
+
+	var sb strings.Builder
+	end := 0
+	for _, pos := range positions {
+		var replacement string
+		if replaceFn != nil {
+			val, err := replaceFn(runtime.Undefined, []*runtime.Value{
+				runtime.NewString(search), runtime.NewNumber(float64(utf16Offset(s, pos))), runtime.NewString(s),
+			})
+			if err != nil {
+				return nil, err
+			}
+			if replacement, err = jsToString(val); err != nil {
+				return nil, err
+			}
+		} else if replacement, err = getSubstitution(search, s, pos, nil, runtime.Undefined, template); err != nil {
+			return nil, err
+		}
+		if err := rl.Agent.Charge(int64(pos - end + len(replacement))); err != nil {
+			return nil, err
+		}
+		sb.WriteString(s[end:pos])
+		sb.WriteString(replacement)
+		end = pos + len(search)
+	}
+	if err := rl.Agent.Charge(int64(len(s) - end)); err != nil {
+		return nil, err
+	}
+	sb.WriteString(s[end:])
+	return runtime.NewString(sb.String()), nil
+}
+
+func (rl *Realm) stringMatch(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	if matcher, err := symbolMethod(argAt(args, 0), SymMatch); err != nil || matcher != nil {
+		if err != nil {
+			return nil, err
+		}
+		return matcher(args[0], []*runtime.Value{runtime.NewString(s)})
+	}
+	return rl.invokeNewRegExp(argAt(args, 0), "", SymMatch, rl.regexpSymbolMatch, s)
+}
+
+// stringMatchAll implements String.prototype.matchAll, which returns an
+// iterator over every match. A RegExp argument must be global.
+func (rl *Realm) stringMatchAll(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	if rx := toObject(argAt(args, 0)); rx != nil && isRegExp(rx) {
+		flags, err := jsToString(rx.Get("flags"))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(flags, "g") {
+			return nil, fmt.Errorf("TypeError: String.prototype.matchAll called with a non-global RegExp argument")
+		}
+	}
+	if matcher, err := symbolMethod(argAt(args, 0), SymMatchAll); err != nil || matcher != nil {
+		if err != nil {
+			return nil, err
+		}
+		return matcher(args[0], []*runtime.Value{runtime.NewString(s)})
+	}
+	return rl.invokeNewRegExp(argAt(args, 0), "g", SymMatchAll, rl.regexpSymbolMatchAll, s)
+}
+
+func (rl *Realm) stringSearch(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	if searcher, err := symbolMethod(argAt(args, 0), SymSearch); err != nil || searcher != nil {
+		if err != nil {
+			return nil, err
+		}
+		return searcher(args[0], []*runtime.Value{runtime.NewString(s)})
+	}
+	return rl.invokeNewRegExp(argAt(args, 0), "", SymSearch, rl.regexpSymbolSearch, s)
+}
+
+// symbolMethod returns the method v[sym] that String.prototype.match,
+// replace, search and split hand over to when v is an object, such as
+// RegExp.prototype[Symbol.match]. It returns nil if v has none.
+func symbolMethod(v *runtime.Value, sym *runtime.Symbol) (runtime.CallableFunc, error) {
+	obj := toObject(v)
+	if obj == nil || sym == nil {
+		return nil, nil
+	}
+	method := obj.GetSymbol(sym)
+	if method == nil || method.Type == runtime.TypeUndefined || method.Type == runtime.TypeNull {
+		return nil, nil
+	}
+	fn := getCallable(method)
+	if fn == nil {
+		return nil, fmt.Errorf("TypeError: %s is not a function", sym.Description)
+	}
+	return fn, nil
+}
+
+// invokeNewRegExp creates a RegExp from pattern and flags, as match,
+// matchAll and search do for arguments that are not RegExps, and calls its
+// sym method on s. builtin is used when the method cannot be looked up.
+func (rl *Realm) invokeNewRegExp(pattern *runtime.Value, flags string, sym *runtime.Symbol, builtin runtime.CallableFunc, s string) (*runtime.Value, error) {
+	source := ""
+	if pattern.Type != runtime.TypeUndefined {
+		str, err := jsToString(pattern)
+		if err != nil {
+			return nil, err
+		}
+		source = str
+	}
+	rx, err := rl.createRegExpObject(source, flags)
+	if err != nil {
+		return nil, err
+	}
+	method, err := symbolMethod(rx, sym)
+	if err != nil {
+		return nil, err
+	}
+	if method == nil {
+		method = builtin
+	}
+	return method(rx, []*runtime.Value{runtime.NewString(s)})
+}
+
+func (rl *Realm) stringConcat(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	var sb strings.Builder
+	sb.WriteString(getStringValue(this))
+	for _, a := range args {
+		sb.WriteString(a.ToString())
+	}
+	if err := rl.Agent.Charge(int64(sb.Len())); err != nil {
+		return nil, err
+	}
+	return runtime.NewString(sb.String()), nil
+}
+
+// stringNormalize returns the string in the Unicode normalization form
+// named by its argument, NFC by default. Lone surrogates are kept as they
+// are, as they take part in no composition.
+func stringNormalize(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	form := norm.NFC
+	if arg := argAt(args, 0); arg.Type != runtime.TypeUndefined {
+		f, err := jsToString(arg)
+		if err != nil {
+			return nil, err
+		}
+		switch f {
+		case "NFC":
+		case "NFD":
+			form = norm.NFD
+		case "NFKC":
+			form = norm.NFKC
+		case "NFKD":
+			form = norm.NFKD
+		default:
+			return nil, fmt.Errorf("RangeError: The normalization form should be one of NFC, NFD, NFKC, NFKD.")
+		}
+	}
+	return runtime.NewString(form.String(s)), nil
+}
+
+// stringLocaleCompare compares without locale data, ignoring the locales
+// and options arguments: letters compare case-insensitively, and only
+// strings that differ in case alone are ordered by it, lowercase first, as
+// the default collation of Node does.
+func stringLocaleCompare(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	that, err := jsToString(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(float64(localeCompare(getStringValue(this), that))), nil
+}
+
+func localeCompare(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	byCase := 0
+	for i := 0; i < len(ra) && i < len(rb); i++ {
+		la, lb := unicode.ToLower(ra[i]), unicode.ToLower(rb[i])
+		if la != lb {
+			return cmp.Compare(la, lb)
+		}
+		if byCase == 0 && ra[i] != rb[i] {
+			byCase = 1
+			if ra[i] == la {
+				byCase = -1
+			}
+		}
+	}
+	if len(ra) != len(rb) {
+		return cmp.Compare(len(ra), len(rb))
+	}
+	return byCase
+}
+
+func stringIsWellFormed(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewBool(runtime.IsWellFormed(getStringValue(this))), nil
+}
+
+func stringToWellFormed(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(runtime.ToWellFormed(getStringValue(this))), nil
+}
+
+// coercibleThis makes a String.prototype method throw when it is called on
+// undefined or null, as RequireObjectCoercible does.
+func coercibleThis(name string, fn runtime.CallableFunc) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+			return nil, fmt.Errorf("TypeError: String.prototype.%s called on null or undefined", name)
+		}
+		return fn(this, args)
+	}
+}
+
+func stringToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(getStringValue(this)), nil
+}
+
+func stringValueOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return runtime.NewString(getStringValue(this)), nil
+}
+
+func stringAt(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	s := getStringValue(this)
+	length := runtime.UTF16Length(s)
+	idx := 0
+	if len(args) > 0 {
+		idx = int(toInteger(args[0]))
+	}
+	if idx < 0 {
+		idx = length + idx
+	}
+	if idx < 0 || idx >= length {
+		return runtime.Undefined, nil
+	}
+	return runtime.NewString(runtime.UTF16Slice(s, idx, idx+1)), nil
+}
+
+// stringFromCharCode implements String.fromCharCode, which builds a string
+// from UTF-16 code units; a surrogate pair among them makes one character.
+func stringFromCharCode(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	units := make([]uint16, len(args))
+	for i, a := range args {
+		n, err := toNumberErr(a)
+		if err != nil {
+			return nil, err
+		}
+		units[i] = uint16(toUint32(runtime.NewNumber(n)))
+	}
+	return runtime.NewString(runtime.FromUTF16(units)), nil
+}
+
+// stringFromCodePoint implements String.fromCodePoint. Code points above
+// U+FFFF become surrogate pairs; a surrogate code point stays lone.
+func stringFromCodePoint(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	var units []uint16
+	for _, a := range args {
+		cp, err := toNumberErr(a)
+		if err != nil {
+			return nil, err
+		}
+		if cp < 0 || cp > 0x10FFFF || cp != math.Trunc(cp) {
+			return nil, fmt.Errorf("RangeError: Invalid code point %s", runtime.NumberToString(cp))
+		}
+		if cp >= 0x10000 {
+			c := int(cp) - 0x10000
+			units = append(units, uint16(0xD800+c>>10), uint16(0xDC00+c&0x3FF))
+		} else {
+			units = append(units, uint16(cp))
+		}
+	}
+	return runtime.NewString(runtime.FromUTF16(units)), nil
+}
+
+func stringRaw(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if len(args) == 0 {
+		return runtime.NewString(""), nil
+	}
+	template := toObject(args[0])
+	if template == nil {
+		return runtime.NewString(""), nil
+	}
+	rawProp := template.Get("raw")
+	rawObj := toObject(rawProp)
+	if rawObj == nil || rawObj.ArrayData == nil {
+		return runtime.NewString(""), nil
+	}
+	var sb strings.Builder
+	subs := args[1:]
+	for i, v := range rawObj.ArrayValues() {
+		sb.WriteString(v.ToString())
+		if i < len(subs) {
+			sb.WriteString(subs[i].ToString())
+		}
+	}
+	return runtime.NewString(sb.String()), nil
+}
+
+// Annex B HTML string methods
+func makeHTMLSimple(tag string) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+			return nil, fmt.Errorf("TypeError: String.prototype.%s requires that 'this' not be undefined or null", tag)
+		}
+		s, err := jsToString(this)
+		if err != nil {
+			return nil, err
+		}
+		return runtime.NewString("<" + tag + ">" + s + "</" + tag + ">"), nil
+	}
+}
+
+func makeHTMLWrapper(tag, attr string) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if this == nil || this.Type == runtime.TypeUndefined || this.Type == runtime.TypeNull {
+			return nil, fmt.Errorf("TypeError: String.prototype.%s requires that 'this' not be undefined or null", tag)
+		}
+		s, err := jsToString(this)
+		if err != nil {
+			return nil, err
+		}
+		val := ""
+		if len(args) > 0 {
+			v, verr := jsToString(args[0])
+			if verr != nil {
+				return nil, verr
+			}
+			val = v
+		}
+		// Escape double quotes in attribute value
+		val = strings.ReplaceAll(val, "\"", "&quot;")
+		return runtime.NewString("<" + tag + " " + attr + "=\"" + val + "\">" + s + "</" + tag + ">"), nil
+	}
+}
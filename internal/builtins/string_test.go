@@ -0,0 +1,411 @@
+package builtins
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestStringCharAt(t *testing.T) {
+	this := runtime.NewString("hello")
+	result, _ := stringCharAt(this, []*runtime.Value{runtime.NewNumber(1)})
+	if result.Str != "e" {
+		t.Errorf("charAt(1): expected 'e', got %q", result.Str)
+	}
+
+	result, _ = stringCharAt(this, []*runtime.Value{runtime.NewNumber(10)})
+	if result.Str != "" {
+		t.Errorf("charAt(10): expected '', got %q", result.Str)
+	}
+}
+
+func TestStringCharCodeAt(t *testing.T) {
+	this := runtime.NewString("A")
+	result, _ := stringCharCodeAt(this, []*runtime.Value{runtime.NewNumber(0)})
+	if result.Number != 65 {
+		t.Errorf("charCodeAt(0): expected 65, got %v", result.Number)
+	}
+}
+
+func TestStringIndexOf(t *testing.T) {
+	this := runtime.NewString("hello world")
+	result, _ := stringIndexOf(this, []*runtime.Value{runtime.NewString("world")})
+	if result.Number != 6 {
+		t.Errorf("indexOf('world'): expected 6, got %v", result.Number)
+	}
+
+	result, _ = stringIndexOf(this, []*runtime.Value{runtime.NewString("xyz")})
+	if result.Number != -1 {
+		t.Errorf("indexOf('xyz'): expected -1, got %v", result.Number)
+	}
+}
+
+func TestStringIncludes(t *testing.T) {
+	this := runtime.NewString("hello world")
+	result, _ := stringIncludes(this, []*runtime.Value{runtime.NewString("world")})
+	if !result.Bool {
+		t.Error("includes('world') should be true")
+	}
+}
+
+func TestStringStartsEndsWith(t *testing.T) {
+	this := runtime.NewString("hello world")
+	result, _ := stringStartsWith(this, []*runtime.Value{runtime.NewString("hello")})
+	if !result.Bool {
+		t.Error("startsWith('hello') should be true")
+	}
+	result, _ = stringEndsWith(this, []*runtime.Value{runtime.NewString("world")})
+	if !result.Bool {
+		t.Error("endsWith('world') should be true")
+	}
+}
+
+func TestStringSlice(t *testing.T) {
+	this := runtime.NewString("hello world")
+	result, _ := stringSlice(this, []*runtime.Value{runtime.NewNumber(0), runtime.NewNumber(5)})
+	if result.Str != "hello" {
+		t.Errorf("slice(0,5): expected 'hello', got %q", result.Str)
+	}
+
+	result, _ = stringSlice(this, []*runtime.Value{runtime.NewNumber(-5)})
+	if result.Str != "world" {
+		t.Errorf("slice(-5): expected 'world', got %q", result.Str)
+	}
+}
+
+func TestStringUpperLower(t *testing.T) {
+	this := runtime.NewString("Hello")
+	result, _ := stringToUpperCase(this, nil)
+	if result.Str != "HELLO" {
+		t.Errorf("toUpperCase: expected 'HELLO', got %q", result.Str)
+	}
+	result, _ = stringToLowerCase(this, nil)
+	if result.Str != "hello" {
+		t.Errorf("toLowerCase: expected 'hello', got %q", result.Str)
+	}
+
+	// Lone surrogates are kept as they are.
+	hi := "\xed\xa0\xbd"
+	result, _ = stringToUpperCase(runtime.NewString("a"+hi+"é"), nil)
+	if result.Str != "A"+hi+"É" {
+		t.Errorf("toUpperCase with a lone surrogate: got %q", result.Str)
+	}
+	result, _ = stringToLowerCase(runtime.NewString(hi+"B"), nil)
+	if result.Str != hi+"b" {
+		t.Errorf("toLowerCase with a lone surrogate: got %q", result.Str)
+	}
+}
+
+func TestStringTrim(t *testing.T) {
+	this := runtime.NewString("  hello  ")
+	result, _ := stringTrim(this, nil)
+	if result.Str != "hello" {
+		t.Errorf("trim: expected 'hello', got %q", result.Str)
+	}
+	result, _ = stringTrimStart(this, nil)
+	if result.Str != "hello  " {
+		t.Errorf("trimStart: expected 'hello  ', got %q", result.Str)
+	}
+	result, _ = stringTrimEnd(this, nil)
+	if result.Str != "  hello" {
+		t.Errorf("trimEnd: expected '  hello', got %q", result.Str)
+	}
+}
+
+func TestStringRepeat(t *testing.T) {
+	rl := registerTestRealm()
+	this := runtime.NewString("ab")
+	result, _ := rl.stringRepeat(this, []*runtime.Value{runtime.NewNumber(3)})
+	if result.Str != "ababab" {
+		t.Errorf("repeat(3): expected 'ababab', got %q", result.Str)
+	}
+}
+
+func TestStringPadStartEnd(t *testing.T) {
+	rl := registerTestRealm()
+	this := runtime.NewString("5")
+	result, _ := rl.stringPadStart(this, []*runtime.Value{runtime.NewNumber(3), runtime.NewString("0")})
+	if result.Str != "005" {
+		t.Errorf("padStart(3,'0'): expected '005', got %q", result.Str)
+	}
+
+	result, _ = rl.stringPadEnd(this, []*runtime.Value{runtime.NewNumber(3), runtime.NewString("0")})
+	if result.Str != "500" {
+		t.Errorf("padEnd(3,'0'): expected '500', got %q", result.Str)
+	}
+}
+
+func TestStringSplit(t *testing.T) {
+	rl := registerTestRealm()
+	this := runtime.NewString("a,b,c")
+	result, _ := rl.stringSplit(this, []*runtime.Value{runtime.NewString(",")})
+	data := getArrayData(result)
+	if len(data) != 3 || data[0].Str != "a" || data[1].Str != "b" || data[2].Str != "c" {
+		t.Error("split(','): expected ['a','b','c']")
+	}
+}
+
+func TestStringReplace(t *testing.T) {
+	rl := registerTestRealm()
+	this := runtime.NewString("hello world")
+	result, _ := rl.stringReplace(this, []*runtime.Value{runtime.NewString("world"), runtime.NewString("go")})
+	if result.Str != "hello go" {
+		t.Errorf("replace: expected 'hello go', got %q", result.Str)
+	}
+}
+
+func TestStringRegExpMethods(t *testing.T) {
+	rl := registerTestRealm()
+	re := func(pattern, flags string) *runtime.Value {
+		v, err := rl.createRegExpObject(pattern, flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	this := runtime.NewString("a1b22c333")
+
+	result, _ := rl.stringReplace(this, []*runtime.Value{re("\\d+", "g"), runtime.NewString("#")})
+	if result.Str != "a#b#c#" {
+		t.Errorf("replace(/\\d+/g): got %q", result.Str)
+	}
+	result, _ = rl.stringReplace(this, []*runtime.Value{runtime.NewString("b"), runtime.NewString("[$&]")})
+	if result.Str != "a1[b]22c333" {
+		t.Errorf("replace with $&: got %q", result.Str)
+	}
+	result, _ = rl.stringSearch(this, []*runtime.Value{re("c", "")})
+	if result.Number != 5 {
+		t.Errorf("search(/c/): got %v, want 5", result.Number)
+	}
+	result, _ = rl.stringMatch(this, []*runtime.Value{re("\\d+", "g")})
+	if data := getArrayData(result); len(data) != 3 || data[2].Str != "333" {
+		t.Errorf("match(/\\d+/g): got %v", data)
+	}
+	result, _ = rl.stringSplit(this, []*runtime.Value{re("\\d+", "")})
+	if data := getArrayData(result); len(data) != 4 || data[3].Str != "" {
+		t.Errorf("split(/\\d+/): got %v", data)
+	}
+}
+
+func TestStringReplaceAll(t *testing.T) {
+	rl := registerTestRealm()
+	tests := []struct {
+		s, search, replacement, want string
+	}{
+		{"a.b.c", ".", "-", "a-b-c"},
+		{"aaa", "aa", "b", "ba"},
+		{"abc", "", "_", "_a_b_c_"},
+		{"héé", "é", "[$&]", "h[é][é]"},
+		{"abc", "x", "y", "abc"},
+	}
+	for _, tt := range tests {
+		result, err := rl.stringReplaceAll(runtime.NewString(tt.s), []*runtime.Value{runtime.NewString(tt.search), runtime.NewString(tt.replacement)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Str != tt.want {
+			t.Errorf("%q.replaceAll(%q, %q): got %q, want %q", tt.s, tt.search, tt.replacement, result.Str, tt.want)
+		}
+	}
+
+	var offsets []float64
+	fn := rl.newFuncObject("", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		offsets = append(offsets, args[1].Number)
+		return runtime.NewString("+"), nil
+	})
+	result, _ := rl.stringReplaceAll(runtime.NewString("x-y-z"), []*runtime.Value{runtime.NewString("-"), runtime.NewObject(fn)})
+	if result.Str != "x+y+z" || len(offsets) != 2 || offsets[0] != 1 || offsets[1] != 3 {
+		t.Errorf("function replacer: got %q with offsets %v", result.Str, offsets)
+	}
+
+	rx, _ := rl.createRegExpObject("a", "")
+	if _, err := rl.stringReplaceAll(runtime.NewString("a"), []*runtime.Value{rx, runtime.NewString("b")}); err == nil {
+		t.Error("non-global RegExp: expected a TypeError")
+	}
+	rx, _ = rl.createRegExpObject("\\d", "g")
+	result, _ = rl.stringReplaceAll(runtime.NewString("a1b2"), []*runtime.Value{rx, runtime.NewString("<$&>")})
+	if result.Str != "a<1>b<2>" {
+		t.Errorf("global RegExp: got %q", result.Str)
+	}
+}
+
+func TestStringConcat(t *testing.T) {
+	rl := registerTestRealm()
+	this := runtime.NewString("hello")
+	result, _ := rl.stringConcat(this, []*runtime.Value{runtime.NewString(" "), runtime.NewString("world")})
+	if result.Str != "hello world" {
+		t.Errorf("concat: expected 'hello world', got %q", result.Str)
+	}
+}
+
+func TestStringFromCharCode(t *testing.T) {
+	result, _ := stringFromCharCode(runtime.Undefined, []*runtime.Value{runtime.NewNumber(72), runtime.NewNumber(105)})
+	if result.Str != "Hi" {
+		t.Errorf("fromCharCode(72,105): expected 'Hi', got %q", result.Str)
+	}
+}
+
+func TestStringSubstring(t *testing.T) {
+	this := runtime.NewString("hello")
+	result, _ := stringSubstring(this, []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(4)})
+	if result.Str != "ell" {
+		t.Errorf("substring(1,4): expected 'ell', got %q", result.Str)
+	}
+}
+
+func TestStringAt(t *testing.T) {
+	this := runtime.NewString("hello")
+	result, _ := stringAt(this, []*runtime.Value{runtime.NewNumber(-1)})
+	if result.Str != "o" {
+		t.Errorf("at(-1): expected 'o', got %q", result.Str)
+	}
+}
+
+func TestStringCodeUnits(t *testing.T) {
+	this := runtime.NewString("héllo😀")
+	num := func(n float64) []*runtime.Value { return []*runtime.Value{runtime.NewNumber(n)} }
+
+	if r, _ := stringCharAt(this, num(1)); r.Str != "é" {
+		t.Errorf("charAt(1): got %q", r.Str)
+	}
+	if r, _ := stringCharCodeAt(this, num(6)); r.Number != 0xDE00 {
+		t.Errorf("charCodeAt(6): got %x", int(r.Number))
+	}
+	if r, _ := stringCodePointAt(this, num(5)); r.Number != 0x1F600 {
+		t.Errorf("codePointAt(5): got %x", int(r.Number))
+	}
+	if r, _ := stringSlice(this, []*runtime.Value{runtime.NewNumber(1), runtime.NewNumber(3)}); r.Str != "él" {
+		t.Errorf("slice(1, 3): got %q", r.Str)
+	}
+	if r, _ := stringIndexOf(this, []*runtime.Value{runtime.NewString("😀")}); r.Number != 5 {
+		t.Errorf("indexOf: got %v", r.Number)
+	}
+	if r, _ := stringAt(this, num(-1)); runtime.UTF16Length(r.Str) != 1 {
+		t.Errorf("at(-1) is the low surrogate alone, got %q", r.Str)
+	}
+
+	pair, _ := stringFromCharCode(runtime.Undefined, []*runtime.Value{runtime.NewNumber(0xD83D), runtime.NewNumber(0xDE00)})
+	if pair.Str != "😀" {
+		t.Errorf("fromCharCode joins a surrogate pair, got %q", pair.Str)
+	}
+	cp, _ := stringFromCodePoint(runtime.Undefined, num(0x1F600))
+	if cp.Str != "😀" {
+		t.Errorf("fromCodePoint(0x1F600): got %q", cp.Str)
+	}
+	if _, err := stringFromCodePoint(runtime.Undefined, num(0x110000)); err == nil {
+		t.Error("fromCodePoint(0x110000) should throw a RangeError")
+	}
+}
+
+func TestStringObject(t *testing.T) {
+	rl := registerTestRealm()
+	this := runtime.NewObject(runtime.NewOrdinaryObject(rl.StringPrototype))
+	result, err := rl.stringConstruct(this, []*runtime.Value{runtime.NewString("a😀")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := runtime.StringData(result.Object); !ok || s != "a😀" {
+		t.Errorf("new String: StringData %q, %v", s, ok)
+	}
+	if n := result.Object.Get("length").Number; n != 3 {
+		t.Errorf("new String: length %v, want 3", n)
+	}
+	upper, _ := stringToUpperCase(result, nil)
+	if upper.Str != "A😀" {
+		t.Errorf("toUpperCase on a String object: got %q", upper.Str)
+	}
+	tag, _ := objectProtoToString(result, nil)
+	if tag.Str != "[object String]" {
+		t.Errorf("Object.prototype.toString: got %q", tag.Str)
+	}
+}
+
+func TestStringLocaleCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"a", "b", -1},
+		{"b", "a", 1},
+		{"a", "a", 0},
+		{"a", "B", -1},
+		{"C", "b", 1},
+		{"a", "A", -1},
+		{"Ab", "aB", 1},
+		{"ab", "abc", -1},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		result, err := stringLocaleCompare(runtime.NewString(tt.a), []*runtime.Value{runtime.NewString(tt.b)})
+		if err != nil || result.Number != tt.want {
+			t.Errorf("%q.localeCompare(%q) = %v, %v, want %v", tt.a, tt.b, result, err, tt.want)
+		}
+	}
+}
+
+func TestStringWellFormed(t *testing.T) {
+	lone := runtime.FromUTF16([]uint16{'a', 0xD800, 'b'})
+	if r, _ := stringIsWellFormed(runtime.NewString("a😀"), nil); !r.Bool {
+		t.Error("a surrogate pair is well formed")
+	}
+	if r, _ := stringIsWellFormed(runtime.NewString(lone), nil); r.Bool {
+		t.Error("a lone surrogate is not well formed")
+	}
+	if r, _ := stringToWellFormed(runtime.NewString(lone), nil); r.Str != "a�b" {
+		t.Errorf("toWellFormed: got %q", r.Str)
+	}
+}
+
+func TestStringNormalizeForm(t *testing.T) {
+	if _, err := stringNormalize(runtime.NewString("x"), []*runtime.Value{runtime.NewString("NFKD")}); err != nil {
+		t.Errorf("NFKD: %v", err)
+	}
+	if _, err := stringNormalize(runtime.NewString("x"), []*runtime.Value{runtime.NewString("nfc")}); err == nil || !strings.HasPrefix(err.Error(), "RangeError") {
+		t.Errorf("an unknown form: got %v, want a RangeError", err)
+	}
+}
+
+func TestStringNormalize(t *testing.T) {
+	tests := []struct {
+		in, form, want string
+	}{
+		{"e\u0301", "", "\u00e9"},
+		{"e\u0301", "NFC", "\u00e9"},
+		{"\u00e9", "NFD", "e\u0301"},
+		{"\u00e9", "NFC", "\u00e9"},
+		{"\u1e9b\u0323", "NFKC", "\u1e69"},
+		{"\u1e9b\u0323", "NFKD", "s\u0323\u0307"},
+		{"\ufb01", "NFC", "\ufb01"},
+		{"\ufb01", "NFKC", "fi"},
+	}
+	for _, tt := range tests {
+		var args []*runtime.Value
+		if tt.form != "" {
+			args = []*runtime.Value{runtime.NewString(tt.form)}
+		}
+		result, err := stringNormalize(runtime.NewString(tt.in), args)
+		if err != nil || result.Str != tt.want {
+			t.Errorf("%+q.normalize(%q) = %v, %v, want %+q", tt.in, tt.form, result, err, tt.want)
+		}
+	}
+	lone := runtime.FromUTF16([]uint16{'e', 0x0301, 0xD800})
+	result, _ := stringNormalize(runtime.NewString(lone), nil)
+	if got := runtime.UTF16(result.Str); len(got) != 2 || got[0] != 0xE9 || got[1] != 0xD800 {
+		t.Errorf("normalize with a lone surrogate = %x", got)
+	}
+}
+
+func TestStringMethodsRequireThis(t *testing.T) {
+	rl := registerTestRealm()
+	rl.createStringConstructor(runtime.NewOrdinaryObject(nil))
+	for _, name := range []string{"at", "localeCompare", "trim", "isWellFormed"} {
+		fn := rl.StringPrototype.Get(name).Object.Callable
+		if _, err := fn(runtime.Null, nil); err == nil || err.Error() != "TypeError: String.prototype."+name+" called on null or undefined" {
+			t.Errorf("%s on null: got %v", name, err)
+		}
+		if _, err := fn(runtime.NewNumber(1), nil); err != nil {
+			t.Errorf("%s on a number: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package builtins
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+var (
+	symbolCounter uint64
+
+	// symbolRegistry is the registry of Symbol.for, shared by every agent
+	// and so guarded by symbolRegistryMu.
+	symbolRegistry   = make(map[string]*runtime.Symbol)
+	symbolRegistryMu sync.Mutex
+
+	// The well-known symbols are shared by every realm, like the registry
+	// of Symbol.for.
+	SymIterator      = &runtime.Symbol{Description: "Symbol.iterator"}
+	SymAsyncIterator = &runtime.Symbol{Description: "Symbol.asyncIterator"}
+	SymToPrimitive   = &runtime.Symbol{Description: "Symbol.toPrimitive"}
+	SymHasInstance   = &runtime.Symbol{Description: "Symbol.hasInstance"}
+	SymToStringTag   = &runtime.Symbol{Description: "Symbol.toStringTag"}
+	SymMatch         = &runtime.Symbol{Description: "Symbol.match"}
+	SymMatchAll      = &runtime.Symbol{Description: "Symbol.matchAll"}
+	SymSplit         = &runtime.Symbol{Description: "Symbol.split"}
+	SymSearch        = &runtime.Symbol{Description: "Symbol.search"}
+	SymReplace       = &runtime.Symbol{Description: "Symbol.replace"}
+	SymSpecies       = &runtime.Symbol{Description: "Symbol.species"}
+	SymUnscopables   = &runtime.Symbol{Description: "Symbol.unscopables"}
+)
+
+func init() {
+	// The keys of symbols are made on first use, which for symbols shared
+	// by every agent might happen on several goroutines at once.
+	for _, sym := range []*runtime.Symbol{SymIterator, SymAsyncIterator, SymToPrimitive, SymHasInstance, SymToStringTag,
+		SymMatch, SymMatchAll, SymSplit, SymSearch, SymReplace, SymSpecies, SymUnscopables} {
+		sym.Key()
+	}
+	runtime.SymbolIterator = SymIterator
+	runtime.SymbolAsyncIterator = SymAsyncIterator
+	runtime.SymbolToPrimitive = SymToPrimitive
+	runtime.SymbolUnscopables = SymUnscopables
+}
+
+func nextSymbolID() uint64 {
+	return atomic.AddUint64(&symbolCounter, 1)
+}
+
+func (rl *Realm) createSymbolConstructor(objProto *runtime.Object) (*runtime.Object, *runtime.Object) {
+	proto := runtime.NewOrdinaryObject(objProto)
+	rl.SymbolPrototype = proto
+
+	rl.setMethod(proto, "toString", 0, symbolToString)
+	rl.setMethod(proto, "valueOf", 0, symbolValueOf)
+	rl.setGetter(proto, "description", symbolDescription)
+
+	ctor := rl.newFuncObject("Symbol", 0, symbolConstructorCall)
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+
+	rl.setMethod(ctor, "for", 1, symbolFor)
+	rl.setMethod(ctor, "keyFor", 1, symbolKeyFor)
+
+	setConstant(ctor, "iterator", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymIterator})
+	setConstant(ctor, "asyncIterator", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymAsyncIterator})
+	setConstant(ctor, "toPrimitive", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymToPrimitive})
+	setConstant(ctor, "hasInstance", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymHasInstance})
+	setConstant(ctor, "toStringTag", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymToStringTag})
+	setConstant(ctor, "match", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymMatch})
+	setConstant(ctor, "matchAll", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymMatchAll})
+	setConstant(ctor, "split", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymSplit})
+	setConstant(ctor, "search", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymSearch})
+	setConstant(ctor, "replace", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymReplace})
+	setConstant(ctor, "species", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymSpecies})
+	setConstant(ctor, "unscopables", &runtime.Value{Type: runtime.TypeSymbol, Symbol: SymUnscopables})
+
+	return ctor, proto
+}
+
+// thisSymbolValue returns the symbol that Symbol.prototype methods were
+// called on.
+func thisSymbolValue(this *runtime.Value, method string) (*runtime.Value, error) {
+	if this != nil && this.Type == runtime.TypeSymbol && this.Symbol != nil {
+		return this, nil
+	}
+	if this != nil && this.Type == runtime.TypeObject {
+		if prim, ok := runtime.PrimitiveData(this.Object); ok && prim.Type == runtime.TypeSymbol {
+			return prim, nil
+		}
+	}
+	return nil, fmt.Errorf("TypeError: Symbol.prototype.%s requires that 'this' be a Symbol", method)
+}
+
+func symbolToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	sym, err := thisSymbolValue(this, "toString")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(sym.ToString()), nil
+}
+
+func symbolValueOf(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return thisSymbolValue(this, "valueOf")
+}
+
+func symbolDescription(this *runtime.Value) (*runtime.Value, error) {
+	sym, err := thisSymbolValue(this, "description")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(sym.Symbol.Description), nil
+}
+
+func symbolConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	desc := ""
+	if len(args) > 0 && args[0].Type != runtime.TypeUndefined {
+		desc = args[0].ToString()
+	}
+	sym := &runtime.Symbol{Description: desc}
+	return &runtime.Value{Type: runtime.TypeSymbol, Symbol: sym}, nil
+}
+
+func symbolFor(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	key := argAt(args, 0).ToString()
+	symbolRegistryMu.Lock()
+	defer symbolRegistryMu.Unlock()
+	if sym, ok := symbolRegistry[key]; ok {
+		return &runtime.Value{Type: runtime.TypeSymbol, Symbol: sym}, nil
+	}
+	sym := &runtime.Symbol{Description: key}
+	sym.Key()
+	symbolRegistry[key] = sym
+	return &runtime.Value{Type: runtime.TypeSymbol, Symbol: sym}, nil
+}
+
+func symbolKeyFor(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a := argAt(args, 0)
+	if a.Type != runtime.TypeSymbol || a.Symbol == nil {
+		return nil, fmt.Errorf("TypeError: Symbol.keyFor requires a symbol")
+	}
+	symbolRegistryMu.Lock()
+	defer symbolRegistryMu.Unlock()
+	for k, v := range symbolRegistry {
+		if v == a.Symbol {
+			return runtime.NewString(k), nil
+		}
+	}
+	return runtime.Undefined, nil
+}
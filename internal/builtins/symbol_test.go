@@ -3,7 +3,7 @@ package builtins
 import (
 	"testing"
 
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/runtime"
 )
 
 func TestSymbolConstructor(t *testing.T) {
@@ -53,7 +53,8 @@ func TestSymbolKeyFor(t *testing.T) {
 }
 
 func TestWellKnownSymbols(t *testing.T) {
-	createSymbolConstructor(nil)
+	rl := registerTestRealm()
+	rl.createSymbolConstructor(nil)
 
 	if SymIterator == nil {
 		t.Error("Symbol.iterator should be defined")
@@ -0,0 +1,1339 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// Temporal is opt-in: RegisterAll leaves it out and hosts call
+// RegisterTemporal to add it. The subset covers PlainDate, PlainDateTime,
+// Duration and Now in the ISO 8601 calendar. Plain dates and date-times are
+// stored as time.Time values in UTC, which only serves as a zone-free wall
+// clock, and durations as their ten fields.
+
+// RegisterTemporal declares the global Temporal namespace of rl in env,
+// the global scope RegisterAll declared rl's other built-ins in.
+func (rl *Realm) RegisterTemporal(env *runtime.Environment) {
+	temporal := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	setDataProp(temporal, "@@toStringTag", runtime.NewString("Temporal"), false, false, true)
+	setDataProp(temporal, "PlainDate", runtime.NewObject(rl.createPlainDateConstructor()), true, false, true)
+	setDataProp(temporal, "PlainDateTime", runtime.NewObject(rl.createPlainDateTimeConstructor()), true, false, true)
+	setDataProp(temporal, "Duration", runtime.NewObject(rl.createDurationConstructor()), true, false, true)
+	setDataProp(temporal, "Now", runtime.NewObject(rl.createTemporalNow()), true, false, true)
+	env.Declare("Temporal", "var", runtime.NewObject(temporal))
+}
+
+// --- Temporal.PlainDate ---
+
+func (rl *Realm) createPlainDateConstructor() *runtime.Object {
+	proto := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	rl.PlainDatePrototype = proto
+	setDataProp(proto, "@@toStringTag", runtime.NewString("Temporal.PlainDate"), false, false, true)
+	for _, f := range dateFields {
+		get := f.get
+		rl.setGetter(proto, f.name, func(this *runtime.Value) (*runtime.Value, error) {
+			t, err := thisTemporal(this, "PlainDate", "getter")
+			if err != nil {
+				return nil, err
+			}
+			return get(t), nil
+		})
+	}
+	rl.setMethod(proto, "add", 1, rl.plainDateAdd)
+	rl.setMethod(proto, "subtract", 1, rl.plainDateSubtract)
+	rl.setMethod(proto, "with", 1, rl.plainDateWith)
+	rl.setMethod(proto, "until", 1, rl.plainDateUntil)
+	rl.setMethod(proto, "since", 1, rl.plainDateSince)
+	rl.setMethod(proto, "equals", 1, plainDateEquals)
+	rl.setMethod(proto, "toPlainDateTime", 0, rl.plainDateToPlainDateTime)
+	rl.setMethod(proto, "toString", 0, plainDateToString)
+	rl.setMethod(proto, "toJSON", 0, plainDateToString)
+	rl.setMethod(proto, "toLocaleString", 0, plainDateToString)
+	rl.setMethod(proto, "valueOf", 0, temporalValueOf("PlainDate"))
+
+	ctor := rl.newFuncObject("PlainDate", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor Temporal.PlainDate requires 'new'")
+	})
+	ctor.Constructor = rl.plainDateConstructorCall
+	rl.setMethod(ctor, "from", 1, rl.plainDateFrom)
+	rl.setMethod(ctor, "compare", 2, plainDateCompare)
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+	return ctor
+}
+
+func (rl *Realm) plainDateConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	var f [3]int
+	for i := range f {
+		n, err := toTemporalInt(argAt(args, i))
+		if err != nil {
+			return nil, err
+		}
+		f[i] = n
+	}
+	t, err := makeISODateTime(f[0], f[1], f[2], 0, true)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDate(t), nil
+}
+
+func (rl *Realm) newPlainDate(t time.Time) *runtime.Value {
+	obj := runtime.NewOrdinaryObject(rl.PlainDatePrototype)
+	obj.Internal = map[string]interface{}{"PlainDate": t}
+	return runtime.NewObject(obj)
+}
+
+func (rl *Realm) plainDateFrom(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	reject, err := overflowOption(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	t, err := toPlainDate(argAt(args, 0), reject)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDate(t), nil
+}
+
+func plainDateCompare(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a, err := toPlainDate(argAt(args, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toPlainDate(argAt(args, 1), false)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(float64(a.Compare(b))), nil
+}
+
+// toPlainDate converts a PlainDate, PlainDateTime, ISO string or property
+// bag to a date.
+func toPlainDate(v *runtime.Value, reject bool) (time.Time, error) {
+	if v.Type == runtime.TypeObject && v.Object != nil {
+		if t, ok := v.Object.Internal["PlainDate"].(time.Time); ok {
+			return t, nil
+		}
+		if t, ok := v.Object.Internal["PlainDateTime"].(time.Time); ok {
+			return truncateToDate(t), nil
+		}
+		fields, err := readTemporalFields(v.Object, dateTimeFieldNames[:3], true)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return makeISODateTime(fields[0], fields[1], fields[2], 0, reject)
+	}
+	if v.Type != runtime.TypeString {
+		return time.Time{}, fmt.Errorf("TypeError: cannot convert %s to a Temporal.PlainDate", v.ToString())
+	}
+	t, err := parseISODateTime(v.Str)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return truncateToDate(t), nil
+}
+
+func (rl *Realm) plainDateAdd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateAddDuration(this, args, "add", 1)
+}
+
+func (rl *Realm) plainDateSubtract(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateAddDuration(this, args, "subtract", -1)
+}
+
+func (rl *Realm) plainDateAddDuration(this *runtime.Value, args []*runtime.Value, method string, sign float64) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDate", method)
+	if err != nil {
+		return nil, err
+	}
+	d, err := toDuration(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	reject, err := overflowOption(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	t, err = addISODuration(t, d.scale(sign), reject, true)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDate(t), nil
+}
+
+func (rl *Realm) plainDateWith(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDate", "with")
+	if err != nil {
+		return nil, err
+	}
+	t, err = withTemporalFields(t, argAt(args, 0), argAt(args, 1), dateTimeFieldNames[:3])
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDate(t), nil
+}
+
+func (rl *Realm) plainDateUntil(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateDifference(this, args, "until", false)
+}
+
+func (rl *Realm) plainDateSince(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateDifference(this, args, "since", true)
+}
+
+func (rl *Realm) plainDateDifference(this *runtime.Value, args []*runtime.Value, method string, since bool) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDate", method)
+	if err != nil {
+		return nil, err
+	}
+	other, err := toPlainDate(argAt(args, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	largest, err := largestUnitOption(argAt(args, 1), durDays, durYears, durDays)
+	if err != nil {
+		return nil, err
+	}
+	if since {
+		t, other = other, t
+	}
+	return rl.newDuration(differenceISO(t, other, largest))
+}
+
+func plainDateEquals(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDate", "equals")
+	if err != nil {
+		return nil, err
+	}
+	other, err := toPlainDate(argAt(args, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(t.Equal(other)), nil
+}
+
+func (rl *Realm) plainDateToPlainDateTime(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDate", "toPlainDateTime")
+	if err != nil {
+		return nil, err
+	}
+	if bag := argAt(args, 0); bag.Type != runtime.TypeUndefined {
+		if t, err = withTemporalFields(t, bag, runtime.Undefined, dateTimeFieldNames[3:]); err != nil {
+			return nil, err
+		}
+	}
+	return rl.newPlainDateTime(t), nil
+}
+
+func plainDateToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDate", "toString")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(formatISODate(t)), nil
+}
+
+// --- Temporal.PlainDateTime ---
+
+func (rl *Realm) createPlainDateTimeConstructor() *runtime.Object {
+	proto := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	rl.PlainDateTimePrototype = proto
+	setDataProp(proto, "@@toStringTag", runtime.NewString("Temporal.PlainDateTime"), false, false, true)
+	for _, f := range append(append([]temporalField{}, dateFields...), timeFields...) {
+		get := f.get
+		rl.setGetter(proto, f.name, func(this *runtime.Value) (*runtime.Value, error) {
+			t, err := thisTemporal(this, "PlainDateTime", "getter")
+			if err != nil {
+				return nil, err
+			}
+			return get(t), nil
+		})
+	}
+	rl.setMethod(proto, "add", 1, rl.plainDateTimeAdd)
+	rl.setMethod(proto, "subtract", 1, rl.plainDateTimeSubtract)
+	rl.setMethod(proto, "with", 1, rl.plainDateTimeWith)
+	rl.setMethod(proto, "until", 1, rl.plainDateTimeUntil)
+	rl.setMethod(proto, "since", 1, rl.plainDateTimeSince)
+	rl.setMethod(proto, "equals", 1, plainDateTimeEquals)
+	rl.setMethod(proto, "toPlainDate", 0, rl.plainDateTimeToPlainDate)
+	rl.setMethod(proto, "toString", 0, plainDateTimeToString)
+	rl.setMethod(proto, "toJSON", 0, plainDateTimeToString)
+	rl.setMethod(proto, "toLocaleString", 0, plainDateTimeToString)
+	rl.setMethod(proto, "valueOf", 0, temporalValueOf("PlainDateTime"))
+
+	ctor := rl.newFuncObject("PlainDateTime", 3, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor Temporal.PlainDateTime requires 'new'")
+	})
+	ctor.Constructor = rl.plainDateTimeConstructorCall
+	rl.setMethod(ctor, "from", 1, rl.plainDateTimeFrom)
+	rl.setMethod(ctor, "compare", 2, plainDateTimeCompare)
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+	return ctor
+}
+
+func (rl *Realm) plainDateTimeConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	var f [9]int
+	for i := range f {
+		v := argAt(args, i)
+		if i >= 3 && v.Type == runtime.TypeUndefined {
+			continue
+		}
+		n, err := toTemporalInt(v)
+		if err != nil {
+			return nil, err
+		}
+		f[i] = n
+	}
+	t, err := fieldsToDateTime(f[:], true)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDateTime(t), nil
+}
+
+func (rl *Realm) newPlainDateTime(t time.Time) *runtime.Value {
+	obj := runtime.NewOrdinaryObject(rl.PlainDateTimePrototype)
+	obj.Internal = map[string]interface{}{"PlainDateTime": t}
+	return runtime.NewObject(obj)
+}
+
+func (rl *Realm) plainDateTimeFrom(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	reject, err := overflowOption(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	t, err := toPlainDateTime(argAt(args, 0), reject)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDateTime(t), nil
+}
+
+func plainDateTimeCompare(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a, err := toPlainDateTime(argAt(args, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toPlainDateTime(argAt(args, 1), false)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewNumber(float64(a.Compare(b))), nil
+}
+
+// toPlainDateTime converts a PlainDateTime, PlainDate (at midnight), ISO
+// string or property bag to a date-time.
+func toPlainDateTime(v *runtime.Value, reject bool) (time.Time, error) {
+	if v.Type == runtime.TypeObject && v.Object != nil {
+		if t, ok := v.Object.Internal["PlainDateTime"].(time.Time); ok {
+			return t, nil
+		}
+		if t, ok := v.Object.Internal["PlainDate"].(time.Time); ok {
+			return t, nil
+		}
+		fields, err := readTemporalFields(v.Object, dateTimeFieldNames, true)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return fieldsToDateTime(fields, reject)
+	}
+	if v.Type != runtime.TypeString {
+		return time.Time{}, fmt.Errorf("TypeError: cannot convert %s to a Temporal.PlainDateTime", v.ToString())
+	}
+	return parseISODateTime(v.Str)
+}
+
+func (rl *Realm) plainDateTimeAdd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateTimeAddDuration(this, args, "add", 1)
+}
+
+func (rl *Realm) plainDateTimeSubtract(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateTimeAddDuration(this, args, "subtract", -1)
+}
+
+func (rl *Realm) plainDateTimeAddDuration(this *runtime.Value, args []*runtime.Value, method string, sign float64) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDateTime", method)
+	if err != nil {
+		return nil, err
+	}
+	d, err := toDuration(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	reject, err := overflowOption(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	t, err = addISODuration(t, d.scale(sign), reject, false)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDateTime(t), nil
+}
+
+func (rl *Realm) plainDateTimeWith(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDateTime", "with")
+	if err != nil {
+		return nil, err
+	}
+	t, err = withTemporalFields(t, argAt(args, 0), argAt(args, 1), dateTimeFieldNames)
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDateTime(t), nil
+}
+
+func (rl *Realm) plainDateTimeUntil(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateTimeDifference(this, args, "until", false)
+}
+
+func (rl *Realm) plainDateTimeSince(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.plainDateTimeDifference(this, args, "since", true)
+}
+
+func (rl *Realm) plainDateTimeDifference(this *runtime.Value, args []*runtime.Value, method string, since bool) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDateTime", method)
+	if err != nil {
+		return nil, err
+	}
+	other, err := toPlainDateTime(argAt(args, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	largest, err := largestUnitOption(argAt(args, 1), durDays, durYears, durNanoseconds)
+	if err != nil {
+		return nil, err
+	}
+	if since {
+		t, other = other, t
+	}
+	return rl.newDuration(differenceISO(t, other, largest))
+}
+
+func plainDateTimeEquals(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDateTime", "equals")
+	if err != nil {
+		return nil, err
+	}
+	other, err := toPlainDateTime(argAt(args, 0), false)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewBool(t.Equal(other)), nil
+}
+
+func (rl *Realm) plainDateTimeToPlainDate(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDateTime", "toPlainDate")
+	if err != nil {
+		return nil, err
+	}
+	return rl.newPlainDate(truncateToDate(t)), nil
+}
+
+func plainDateTimeToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	t, err := thisTemporal(this, "PlainDateTime", "toString")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(formatISODate(t) + "T" + formatISOTime(t)), nil
+}
+
+// --- Temporal.Duration ---
+
+// The fields of a duration, largest unit first. Unit indexes double as the
+// values of largestUnit options.
+const (
+	durYears = iota
+	durMonths
+	durWeeks
+	durDays
+	durHours
+	durMinutes
+	durSeconds
+	durMilliseconds
+	durMicroseconds
+	durNanoseconds
+)
+
+var durationFieldNames = [...]string{"years", "months", "weeks", "days", "hours", "minutes", "seconds", "milliseconds", "microseconds", "nanoseconds"}
+
+// unitNanos is the length of each fixed-length unit; days are always 24
+// hours since plain dates have no time zone.
+var unitNanos = [...]int64{0, 0, 0, 86400e9, 3600e9, 60e9, 1e9, 1e6, 1e3, 1}
+
+const dayNanos = int64(86400e9)
+
+type duration [10]float64
+
+func (d duration) sign() int {
+	for _, v := range d {
+		if v > 0 {
+			return 1
+		}
+		if v < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+func (d duration) scale(k float64) duration {
+	for i := range d {
+		if d[i] != 0 {
+			d[i] *= k
+		}
+	}
+	return d
+}
+
+func (d duration) hasCalendarUnits() bool {
+	return d[durYears] != 0 || d[durMonths] != 0 || d[durWeeks] != 0
+}
+
+// largestUnit is the largest non-zero field, or nanoseconds for a zero
+// duration.
+func (d duration) largestUnit() int {
+	for i, v := range d {
+		if v != 0 {
+			return i
+		}
+	}
+	return durNanoseconds
+}
+
+// timeNanos splits the day and time fields into whole days and nanoseconds
+// of the same sign.
+func (d duration) timeNanos() (int64, int64) {
+	return normalizeTime(int64(d[durDays]), d.nanos())
+}
+
+// nanos sums the time fields of d.
+func (d duration) nanos() int64 {
+	var ns int64
+	for u := durHours; u <= durNanoseconds; u++ {
+		ns += int64(d[u]) * unitNanos[u]
+	}
+	return ns
+}
+
+func normalizeTime(days, ns int64) (int64, int64) {
+	days += ns / dayNanos
+	ns %= dayNanos
+	if days > 0 && ns < 0 {
+		days--
+		ns += dayNanos
+	} else if days < 0 && ns > 0 {
+		days++
+		ns -= dayNanos
+	}
+	return days, ns
+}
+
+// balanceDuration spreads days and nanoseconds over weeks (only when
+// largest is durWeeks), days and the time units no larger than largest.
+func balanceDuration(days, ns int64, largest int) duration {
+	days, ns = normalizeTime(days, ns)
+	var d duration
+	if largest <= durDays {
+		if largest == durWeeks {
+			d[durWeeks] = float64(days / 7)
+			days %= 7
+		}
+		d[durDays] = float64(days)
+	} else {
+		ns += days * dayNanos
+	}
+	for u := durHours; u < durNanoseconds; u++ {
+		if u < largest {
+			continue
+		}
+		d[u] = float64(ns / unitNanos[u])
+		ns %= unitNanos[u]
+	}
+	d[durNanoseconds] = float64(ns)
+	return d
+}
+
+func (rl *Realm) createDurationConstructor() *runtime.Object {
+	proto := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	rl.DurationPrototype = proto
+	setDataProp(proto, "@@toStringTag", runtime.NewString("Temporal.Duration"), false, false, true)
+	for i, name := range durationFieldNames {
+		idx := i
+		rl.setGetter(proto, name, func(this *runtime.Value) (*runtime.Value, error) {
+			d, err := thisDuration(this, "getter")
+			if err != nil {
+				return nil, err
+			}
+			return runtime.NewNumber(d[idx]), nil
+		})
+	}
+	rl.setGetter(proto, "sign", func(this *runtime.Value) (*runtime.Value, error) {
+		d, err := thisDuration(this, "getter")
+		if err != nil {
+			return nil, err
+		}
+		return runtime.NewNumber(float64(d.sign())), nil
+	})
+	rl.setGetter(proto, "blank", func(this *runtime.Value) (*runtime.Value, error) {
+		d, err := thisDuration(this, "getter")
+		if err != nil {
+			return nil, err
+		}
+		return runtime.NewBool(d.sign() == 0), nil
+	})
+	rl.setMethod(proto, "negated", 0, rl.durationNegated)
+	rl.setMethod(proto, "abs", 0, rl.durationAbs)
+	rl.setMethod(proto, "add", 1, rl.durationAdd)
+	rl.setMethod(proto, "subtract", 1, rl.durationSubtract)
+	rl.setMethod(proto, "with", 1, rl.durationWith)
+	rl.setMethod(proto, "total", 1, durationTotal)
+	rl.setMethod(proto, "toString", 0, durationToString)
+	rl.setMethod(proto, "toJSON", 0, durationToString)
+	rl.setMethod(proto, "toLocaleString", 0, durationToString)
+	rl.setMethod(proto, "valueOf", 0, temporalValueOf("Duration"))
+
+	ctor := rl.newFuncObject("Duration", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Constructor Temporal.Duration requires 'new'")
+	})
+	ctor.Constructor = rl.durationConstructorCall
+	rl.setMethod(ctor, "from", 1, rl.durationFrom)
+	rl.setMethod(ctor, "compare", 2, durationCompare)
+	setDataProp(ctor, "prototype", runtime.NewObject(proto), false, false, false)
+	setDataProp(proto, "constructor", runtime.NewObject(ctor), true, false, true)
+	return ctor
+}
+
+func (rl *Realm) durationConstructorCall(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	var d duration
+	for i := range d {
+		v := argAt(args, i)
+		if v.Type == runtime.TypeUndefined {
+			continue
+		}
+		n, err := toDurationField(v)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = n
+	}
+	return rl.newDuration(d)
+}
+
+// newDuration creates a Temporal.Duration, rejecting mixed signs.
+func (rl *Realm) newDuration(d duration) (*runtime.Value, error) {
+	sign := d.sign()
+	for _, v := range d {
+		if (v > 0 && sign < 0) || (v < 0 && sign > 0) {
+			return nil, fmt.Errorf("RangeError: mixed-sign values not allowed as duration fields")
+		}
+	}
+	obj := runtime.NewOrdinaryObject(rl.DurationPrototype)
+	obj.Internal = map[string]interface{}{"Duration": d}
+	return runtime.NewObject(obj), nil
+}
+
+func thisDuration(this *runtime.Value, method string) (duration, error) {
+	if this != nil && this.Type == runtime.TypeObject && this.Object != nil {
+		if d, ok := this.Object.Internal["Duration"].(duration); ok {
+			return d, nil
+		}
+	}
+	return duration{}, fmt.Errorf("TypeError: Temporal.Duration.prototype.%s called on incompatible receiver", method)
+}
+
+func (rl *Realm) durationFrom(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	d, err := toDuration(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	return rl.newDuration(d)
+}
+
+// toDuration converts a Duration, ISO 8601 duration string or property bag
+// with at least one duration field.
+func toDuration(v *runtime.Value) (duration, error) {
+	if v.Type == runtime.TypeString {
+		return parseISODuration(v.Str)
+	}
+	if v.Type != runtime.TypeObject || v.Object == nil {
+		return duration{}, fmt.Errorf("TypeError: cannot convert %s to a Temporal.Duration", v.ToString())
+	}
+	if d, ok := v.Object.Internal["Duration"].(duration); ok {
+		return d, nil
+	}
+	var d duration
+	found := false
+	for i, name := range durationFieldNames {
+		fv := v.Object.Get(name)
+		if fv == nil || fv.Type == runtime.TypeUndefined {
+			continue
+		}
+		n, err := toDurationField(fv)
+		if err != nil {
+			return duration{}, err
+		}
+		d[i] = n
+		found = true
+	}
+	if !found {
+		return duration{}, fmt.Errorf("TypeError: duration object must have at least one duration property")
+	}
+	sign := d.sign()
+	for _, n := range d {
+		if (n > 0 && sign < 0) || (n < 0 && sign > 0) {
+			return duration{}, fmt.Errorf("RangeError: mixed-sign values not allowed as duration fields")
+		}
+	}
+	return d, nil
+}
+
+func toDurationField(v *runtime.Value) (float64, error) {
+	n, err := toNumberErr(v)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) || n != math.Trunc(n) {
+		return 0, fmt.Errorf("RangeError: invalid duration field %s", v.ToString())
+	}
+	return n, nil
+}
+
+func durationCompare(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	a, err := toDuration(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	b, err := toDuration(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	if a.hasCalendarUnits() || b.hasCalendarUnits() {
+		return nil, fmt.Errorf("RangeError: a starting point is required for years, months and weeks")
+	}
+	ad, ans := a.timeNanos()
+	bd, bns := b.timeNanos()
+	switch {
+	case ad < bd || (ad == bd && ans < bns):
+		return runtime.NewNumber(-1), nil
+	case ad > bd || (ad == bd && ans > bns):
+		return runtime.NewNumber(1), nil
+	}
+	return runtime.NewNumber(0), nil
+}
+
+func (rl *Realm) durationNegated(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	d, err := thisDuration(this, "negated")
+	if err != nil {
+		return nil, err
+	}
+	return rl.newDuration(d.scale(-1))
+}
+
+func (rl *Realm) durationAbs(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	d, err := thisDuration(this, "abs")
+	if err != nil {
+		return nil, err
+	}
+	if d.sign() < 0 {
+		d = d.scale(-1)
+	}
+	return rl.newDuration(d)
+}
+
+func (rl *Realm) durationAdd(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.durationAddDuration(this, args, "add", 1)
+}
+
+func (rl *Realm) durationSubtract(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	return rl.durationAddDuration(this, args, "subtract", -1)
+}
+
+// durationAddDuration adds two durations without calendar units and
+// balances the sum up to the larger of their largest units.
+func (rl *Realm) durationAddDuration(this *runtime.Value, args []*runtime.Value, method string, sign float64) (*runtime.Value, error) {
+	a, err := thisDuration(this, method)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toDuration(argAt(args, 0))
+	if err != nil {
+		return nil, err
+	}
+	b = b.scale(sign)
+	if a.hasCalendarUnits() || b.hasCalendarUnits() {
+		return nil, fmt.Errorf("RangeError: a starting point is required for years, months and weeks")
+	}
+	largest := a.largestUnit()
+	if l := b.largestUnit(); l < largest {
+		largest = l
+	}
+	ad, ans := a.timeNanos()
+	bd, bns := b.timeNanos()
+	return rl.newDuration(balanceDuration(ad+bd, ans+bns, largest))
+}
+
+func (rl *Realm) durationWith(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	d, err := thisDuration(this, "with")
+	if err != nil {
+		return nil, err
+	}
+	bag := argAt(args, 0)
+	if bag.Type != runtime.TypeObject || bag.Object == nil {
+		return nil, fmt.Errorf("TypeError: invalid argument to Temporal.Duration.prototype.with")
+	}
+	found := false
+	for i, name := range durationFieldNames {
+		fv := bag.Object.Get(name)
+		if fv == nil || fv.Type == runtime.TypeUndefined {
+			continue
+		}
+		if d[i], err = toDurationField(fv); err != nil {
+			return nil, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("TypeError: duration object must have at least one duration property")
+	}
+	return rl.newDuration(d)
+}
+
+// durationTotal implements total(unit) for durations without calendar
+// units. The unit may be given directly or as {unit}.
+func durationTotal(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	d, err := thisDuration(this, "total")
+	if err != nil {
+		return nil, err
+	}
+	unitArg := argAt(args, 0)
+	if unitArg.Type == runtime.TypeObject && unitArg.Object != nil {
+		unitArg = unitArg.Object.Get("unit")
+	}
+	if unitArg == nil || unitArg.Type == runtime.TypeUndefined {
+		return nil, fmt.Errorf("RangeError: unit is required")
+	}
+	unit, err := parseTemporalUnit(unitArg)
+	if err != nil {
+		return nil, err
+	}
+	if d.hasCalendarUnits() || unit < durDays {
+		return nil, fmt.Errorf("RangeError: a starting point is required for years, months and weeks")
+	}
+	days, ns := d.timeNanos()
+	total := float64(days)*(float64(dayNanos)/float64(unitNanos[unit])) + float64(ns)/float64(unitNanos[unit])
+	return runtime.NewNumber(total), nil
+}
+
+func durationToString(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	d, err := thisDuration(this, "toString")
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewString(formatISODuration(d)), nil
+}
+
+// formatISODuration writes d in ISO 8601 form, folding the sub-second
+// fields into fractional seconds.
+func formatISODuration(d duration) string {
+	var b strings.Builder
+	if d.sign() < 0 {
+		b.WriteByte('-')
+		d = d.scale(-1)
+	}
+	b.WriteByte('P')
+	for i, designator := range "YMWD" {
+		if d[i] != 0 {
+			fmt.Fprintf(&b, "%.0f%c", d[i], designator)
+		}
+	}
+	sub := d[durMilliseconds]*1e6 + d[durMicroseconds]*1e3 + d[durNanoseconds]
+	secs := d[durSeconds] + math.Floor(sub/1e9)
+	frac := math.Mod(sub, 1e9)
+	var t strings.Builder
+	if d[durHours] != 0 {
+		fmt.Fprintf(&t, "%.0fH", d[durHours])
+	}
+	if d[durMinutes] != 0 {
+		fmt.Fprintf(&t, "%.0fM", d[durMinutes])
+	}
+	if secs != 0 || frac != 0 || d.sign() == 0 {
+		fmt.Fprintf(&t, "%.0f", secs)
+		if frac != 0 {
+			t.WriteString("." + strings.TrimRight(fmt.Sprintf("%09.0f", frac), "0"))
+		}
+		t.WriteByte('S')
+	}
+	if t.Len() > 0 {
+		b.WriteByte('T')
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+var isoDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)(?:[.,](\d{1,9}))?S)?)?$`)
+
+func parseISODuration(s string) (duration, error) {
+	m := isoDurationPattern.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil || strings.HasSuffix(m[0], "P") || strings.HasSuffix(m[0], "T") {
+		return duration{}, fmt.Errorf("RangeError: invalid duration: %s", s)
+	}
+	var d duration
+	for i := 0; i < 7; i++ {
+		if m[i+2] != "" {
+			d[i], _ = strconv.ParseFloat(m[i+2], 64)
+		}
+	}
+	if frac := m[9]; frac != "" {
+		frac += strings.Repeat("0", 9-len(frac))
+		for i := 0; i < 3; i++ {
+			d[durMilliseconds+i], _ = strconv.ParseFloat(frac[i*3:i*3+3], 64)
+		}
+	}
+	if m[1] == "-" {
+		d = d.scale(-1)
+	}
+	return d, nil
+}
+
+// --- Temporal.Now ---
+
+func (rl *Realm) createTemporalNow() *runtime.Object {
+	now := runtime.NewOrdinaryObject(rl.ObjectPrototype)
+	setDataProp(now, "@@toStringTag", runtime.NewString("Temporal.Now"), false, false, true)
+	rl.setMethod(now, "plainDateISO", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		t, err := wallClockNow(argAt(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		return rl.newPlainDate(truncateToDate(t)), nil
+	})
+	rl.setMethod(now, "plainDateTimeISO", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		t, err := wallClockNow(argAt(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		return rl.newPlainDateTime(t), nil
+	})
+	return now
+}
+
+// wallClockNow returns the current wall-clock time in the named IANA time
+// zone, or the host's local zone, as a zone-free UTC time.
+func wallClockNow(zone *runtime.Value) (time.Time, error) {
+	t := time.Now()
+	if zone.Type != runtime.TypeUndefined {
+		loc, err := time.LoadLocation(zone.ToString())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("RangeError: invalid time zone: %s", zone.ToString())
+		}
+		t = t.In(loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), nil
+}
+
+// --- shared helpers ---
+
+type temporalField struct {
+	name string
+	get  func(t time.Time) *runtime.Value
+}
+
+var dateFields = []temporalField{
+	{"calendarId", func(time.Time) *runtime.Value { return runtime.NewString("iso8601") }},
+	{"year", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Year())) }},
+	{"month", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Month())) }},
+	{"monthCode", func(t time.Time) *runtime.Value { return runtime.NewString(fmt.Sprintf("M%02d", t.Month())) }},
+	{"day", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Day())) }},
+	{"dayOfWeek", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64((int(t.Weekday())+6)%7 + 1)) }},
+	{"dayOfYear", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.YearDay())) }},
+	{"weekOfYear", func(t time.Time) *runtime.Value { _, w := t.ISOWeek(); return runtime.NewNumber(float64(w)) }},
+	{"daysInWeek", func(time.Time) *runtime.Value { return runtime.NewNumber(7) }},
+	{"daysInMonth", func(t time.Time) *runtime.Value {
+		return runtime.NewNumber(float64(daysInMonth(t.Year(), int(t.Month()))))
+	}},
+	{"daysInYear", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(daysInMonth(t.Year(), 2) + 337)) }},
+	{"monthsInYear", func(time.Time) *runtime.Value { return runtime.NewNumber(12) }},
+	{"inLeapYear", func(t time.Time) *runtime.Value { return runtime.NewBool(daysInMonth(t.Year(), 2) == 29) }},
+}
+
+var timeFields = []temporalField{
+	{"hour", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Hour())) }},
+	{"minute", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Minute())) }},
+	{"second", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Second())) }},
+	{"millisecond", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Nanosecond() / 1e6)) }},
+	{"microsecond", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Nanosecond() / 1e3 % 1e3)) }},
+	{"nanosecond", func(t time.Time) *runtime.Value { return runtime.NewNumber(float64(t.Nanosecond() % 1e3)) }},
+}
+
+// dateTimeFieldNames lists the property-bag fields in the order
+// fieldsToDateTime takes them; the first three are the date.
+var dateTimeFieldNames = []string{"year", "month", "day", "hour", "minute", "second", "millisecond", "microsecond", "nanosecond"}
+
+func thisTemporal(this *runtime.Value, slot, method string) (time.Time, error) {
+	if this != nil && this.Type == runtime.TypeObject && this.Object != nil {
+		if t, ok := this.Object.Internal[slot].(time.Time); ok {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("TypeError: Temporal.%s.prototype.%s called on incompatible receiver", slot, method)
+}
+
+// temporalValueOf returns a valueOf that always throws, so relational
+// operators cannot silently compare Temporal objects.
+func temporalValueOf(name string) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: use Temporal.%s.compare to compare Temporal.%s values", name, name)
+	}
+}
+
+func daysInMonth(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// makeISODateTime builds a date with the clock time ns after midnight. Out of
+// range months and days are clamped, or rejected with a RangeError if reject
+// is set.
+func makeISODateTime(year, month, day int, ns int64, reject bool) (time.Time, error) {
+	if year < -271821 || year > 275760 {
+		return time.Time{}, fmt.Errorf("RangeError: year %d is out of range", year)
+	}
+	if month < 1 || month > 12 {
+		if reject {
+			return time.Time{}, fmt.Errorf("RangeError: month %d is out of range", month)
+		}
+		month = clampInt(month, 1, 12)
+	}
+	if dim := daysInMonth(year, month); day < 1 || day > dim {
+		if reject {
+			return time.Time{}, fmt.Errorf("RangeError: day %d is out of range", day)
+		}
+		day = clampInt(day, 1, dim)
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Add(time.Duration(ns)), nil
+}
+
+// fieldsToDateTime builds a date-time from year, month, day, hour, minute,
+// second, millisecond, microsecond and nanosecond.
+func fieldsToDateTime(f []int, reject bool) (time.Time, error) {
+	limits := []int{23, 59, 59, 999, 999, 999}
+	var ns int64
+	for i, limit := range limits {
+		v := f[3+i]
+		if v < 0 || v > limit {
+			if reject {
+				return time.Time{}, fmt.Errorf("RangeError: %s %d is out of range", dateTimeFieldNames[3+i], v)
+			}
+			v = clampInt(v, 0, limit)
+		}
+		ns += int64(v) * unitNanos[durHours+i]
+	}
+	return makeISODateTime(f[0], f[1], f[2], ns, reject)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func toTemporalInt(v *runtime.Value) (int, error) {
+	n, err := toNumberErr(v)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("RangeError: invalid number %s", v.ToString())
+	}
+	return int(math.Trunc(n)), nil
+}
+
+// readTemporalFields reads the named fields of a property bag. Missing
+// fields are 0, or a TypeError if required.
+func readTemporalFields(obj *runtime.Object, names []string, required bool) ([]int, error) {
+	fields := make([]int, len(dateTimeFieldNames))
+	for i, name := range names {
+		v := obj.Get(name)
+		if v == nil || v.Type == runtime.TypeUndefined {
+			if required && i < 3 {
+				return nil, fmt.Errorf("TypeError: required property '%s' missing or undefined", name)
+			}
+			continue
+		}
+		n, err := toTemporalInt(v)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = n
+	}
+	return fields, nil
+}
+
+// withTemporalFields returns t with the given fields of bag replaced. The bag
+// must set at least one of them.
+func withTemporalFields(t time.Time, bag, options *runtime.Value, names []string) (time.Time, error) {
+	if bag.Type != runtime.TypeObject || bag.Object == nil {
+		return time.Time{}, fmt.Errorf("TypeError: invalid argument: expected an object")
+	}
+	reject, err := overflowOption(options)
+	if err != nil {
+		return time.Time{}, err
+	}
+	fields := []int{t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(),
+		t.Nanosecond() / 1e6, t.Nanosecond() / 1e3 % 1e3, t.Nanosecond() % 1e3}
+	found := false
+	for _, name := range names {
+		v := bag.Object.Get(name)
+		if v == nil || v.Type == runtime.TypeUndefined {
+			continue
+		}
+		n, err := toTemporalInt(v)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for i, field := range dateTimeFieldNames {
+			if field == name {
+				fields[i] = n
+			}
+		}
+		found = true
+	}
+	if !found {
+		return time.Time{}, fmt.Errorf("TypeError: object must have at least one of %s", strings.Join(names, ", "))
+	}
+	return fieldsToDateTime(fields, reject)
+}
+
+func overflowOption(options *runtime.Value) (bool, error) {
+	v, err := temporalOption(options, "overflow")
+	if err != nil || v.Type == runtime.TypeUndefined {
+		return false, err
+	}
+	switch v.ToString() {
+	case "constrain":
+		return false, nil
+	case "reject":
+		return true, nil
+	}
+	return false, fmt.Errorf("RangeError: %s is not a valid value for overflow", v.ToString())
+}
+
+// largestUnitOption reads options.largestUnit, which must lie between the
+// units largest and smallest.
+func largestUnitOption(options *runtime.Value, def, largest, smallest int) (int, error) {
+	v, err := temporalOption(options, "largestUnit")
+	if err != nil {
+		return 0, err
+	}
+	if v.Type == runtime.TypeUndefined || v.ToString() == "auto" {
+		return def, nil
+	}
+	unit, err := parseTemporalUnit(v)
+	if err != nil {
+		return 0, err
+	}
+	if unit < largest || unit > smallest {
+		return 0, fmt.Errorf("RangeError: %s is not a valid value for largestUnit", v.ToString())
+	}
+	return unit, nil
+}
+
+func temporalOption(options *runtime.Value, name string) (*runtime.Value, error) {
+	if options.Type == runtime.TypeUndefined {
+		return runtime.Undefined, nil
+	}
+	if options.Type != runtime.TypeObject || options.Object == nil {
+		return nil, fmt.Errorf("TypeError: options must be an object")
+	}
+	v := options.Object.Get(name)
+	if v == nil {
+		return runtime.Undefined, nil
+	}
+	return v, nil
+}
+
+// parseTemporalUnit accepts a unit name in singular or plural form.
+func parseTemporalUnit(v *runtime.Value) (int, error) {
+	s := v.ToString()
+	for i, name := range durationFieldNames {
+		if s == name || s == strings.TrimSuffix(name, "s") {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("RangeError: %s is not a valid unit", s)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// addMonths moves t by a number of months, clamping the day to the end of
+// the target month.
+func addMonths(t time.Time, months int) time.Time {
+	m := int(t.Month()) - 1 + months
+	year := t.Year() + floorDiv(m, 12)
+	month := m - floorDiv(m, 12)*12 + 1
+	day := t.Day()
+	if dim := daysInMonth(year, month); day > dim {
+		day = dim
+	}
+	return time.Date(year, time.Month(month), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// addISODuration adds years and months first, keeping the day of month in
+// range, then weeks, days and time. With dateOnly, time fields only count
+// in whole days.
+func addISODuration(t time.Time, d duration, reject, dateOnly bool) (time.Time, error) {
+	if months := int(d[durYears])*12 + int(d[durMonths]); months != 0 {
+		moved := addMonths(t, months)
+		if reject && moved.Day() != t.Day() {
+			return time.Time{}, fmt.Errorf("RangeError: day %d does not exist in the resulting month", t.Day())
+		}
+		t = moved
+	}
+	days, ns := normalizeTime(int64(d[durWeeks])*7+int64(d[durDays]), d.nanos())
+	if dateOnly {
+		ns = 0
+	}
+	t = t.AddDate(0, 0, int(days)).Add(time.Duration(ns))
+	if y := t.Year(); y < -271821 || y > 275760 {
+		return time.Time{}, fmt.Errorf("RangeError: date is out of range")
+	}
+	return t, nil
+}
+
+// differenceISO returns the duration from a to b with no unit larger than
+// largest. Whole months are counted first for year and month units, as long
+// as they do not pass b, then the rest is balanced from days down.
+func differenceISO(a, b time.Time, largest int) duration {
+	var d duration
+	if largest <= durMonths {
+		months := (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+		for months != 0 {
+			m := int(a.Month()) - 1 + months
+			y, mo := a.Year()+floorDiv(m, 12), m-floorDiv(m, 12)*12+1
+			c := compareFields(y, mo, a.Day(), nsOfDay(a), b.Year(), int(b.Month()), b.Day(), nsOfDay(b))
+			if (months > 0 && c > 0) || (months < 0 && c < 0) {
+				if months > 0 {
+					months--
+				} else {
+					months++
+				}
+				continue
+			}
+			break
+		}
+		a = addMonths(a, months)
+		if largest == durYears {
+			d[durYears] = float64(months / 12)
+			months %= 12
+		}
+		d[durMonths] = float64(months)
+		largest = durDays
+	}
+	rest := balanceDuration(dayNumber(b)-dayNumber(a), nsOfDay(b)-nsOfDay(a), largest)
+	copy(d[durWeeks:], rest[durWeeks:])
+	return d
+}
+
+func compareFields(y1, m1, d1 int, ns1 int64, y2, m2, d2 int, ns2 int64) int {
+	switch {
+	case y1 != y2:
+		return sign(y1 - y2)
+	case m1 != m2:
+		return sign(m1 - m2)
+	case d1 != d2:
+		return sign(d1 - d2)
+	case ns1 < ns2:
+		return -1
+	case ns1 > ns2:
+		return 1
+	}
+	return 0
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func dayNumber(t time.Time) int64 {
+	return int64(floorDiv(int(t.Unix()), 86400))
+}
+
+func nsOfDay(t time.Time) int64 {
+	return int64(t.Hour())*unitNanos[durHours] + int64(t.Minute())*unitNanos[durMinutes] +
+		int64(t.Second())*unitNanos[durSeconds] + int64(t.Nanosecond())
+}
+
+var isoDateTimePattern = regexp.MustCompile(`^([+-]\d{6}|\d{4})-(\d{2})-(\d{2})(?:[Tt ](\d{2}):(\d{2})(?::(\d{2})(?:[.,](\d{1,9}))?)?)?(?:[+-]\d{2}:\d{2})?(?:\[[^\]]*\])*$`)
+
+// parseISODateTime parses an ISO 8601 date or date-time. A UTC offset and
+// bracketed annotations are accepted and ignored; "Z" is rejected because a
+// plain value has no time zone.
+func parseISODateTime(s string) (time.Time, error) {
+	m := isoDateTimePattern.FindStringSubmatch(s)
+	if m == nil || m[1] == "-000000" {
+		return time.Time{}, fmt.Errorf("RangeError: invalid ISO 8601 string: %s", s)
+	}
+	f := make([]int, len(dateTimeFieldNames))
+	for i := 1; i <= 6; i++ {
+		if m[i] != "" {
+			f[i-1], _ = strconv.Atoi(m[i])
+		}
+	}
+	if frac := m[7]; frac != "" {
+		frac += strings.Repeat("0", 9-len(frac))
+		for i := 0; i < 3; i++ {
+			f[6+i], _ = strconv.Atoi(frac[i*3 : i*3+3])
+		}
+	}
+	if f[5] == 60 {
+		f[5] = 59 // leap second
+	}
+	return fieldsToDateTime(f, true)
+}
+
+func formatISODate(t time.Time) string {
+	year := t.Year()
+	ys := fmt.Sprintf("%04d", year)
+	if year < 0 {
+		ys = fmt.Sprintf("-%06d", -year)
+	} else if year > 9999 {
+		ys = fmt.Sprintf("+%06d", year)
+	}
+	return fmt.Sprintf("%s-%02d-%02d", ys, t.Month(), t.Day())
+}
+
+func formatISOTime(t time.Time) string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour(), t.Minute(), t.Second())
+	if ns := t.Nanosecond(); ns != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%09d", ns), "0")
+	}
+	return s
+}
@@ -0,0 +1,159 @@
+package builtins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func setupTemporal() *Realm {
+	env := runtime.NewEnvironment(nil, false)
+	rl := RegisterAll(runtime.NewRealm(runtime.NewAgent()), env, nil)
+	rl.RegisterTemporal(env)
+	return rl
+}
+
+func date(y, m, d int) time.Time {
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRegisterTemporal(t *testing.T) {
+	env := runtime.NewEnvironment(nil, false)
+	rl := RegisterAll(runtime.NewRealm(runtime.NewAgent()), env, nil)
+	if _, err := env.Get("Temporal"); err == nil {
+		t.Fatalf("RegisterAll should not declare Temporal")
+	}
+	rl.RegisterTemporal(env)
+	temporal, err := env.Get("Temporal")
+	if err != nil {
+		t.Fatalf("Temporal not declared: %v", err)
+	}
+	for _, name := range []string{"PlainDate", "PlainDateTime", "Duration", "Now"} {
+		if v := temporal.Object.Get(name); v.Type != runtime.TypeObject {
+			t.Errorf("Temporal.%s missing", name)
+		}
+	}
+}
+
+func TestPlainDateArithmetic(t *testing.T) {
+	setupTemporal()
+	tests := []struct {
+		name  string
+		start time.Time
+		add   duration
+		want  string
+	}{
+		{"month end is clamped", date(2024, 1, 31), duration{durMonths: 1}, "2024-02-29"},
+		{"years then days", date(2024, 2, 29), duration{durYears: 1, durDays: 1}, "2025-03-01"},
+		{"weeks", date(2024, 12, 25), duration{durWeeks: 2}, "2025-01-08"},
+		{"negative months", date(2024, 3, 31), duration{durMonths: -1}, "2024-02-29"},
+		{"hours count in whole days", date(2024, 1, 1), duration{durHours: 47}, "2024-01-02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addISODuration(tt.start, tt.add, false, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if formatISODate(got) != tt.want {
+				t.Errorf("got %s, want %s", formatISODate(got), tt.want)
+			}
+		})
+	}
+	if _, err := addISODuration(date(2024, 1, 31), duration{durMonths: 1}, true, true); err == nil {
+		t.Errorf("overflow reject: expected a RangeError")
+	}
+}
+
+func TestDifferenceISO(t *testing.T) {
+	tests := []struct {
+		a, b    time.Time
+		largest int
+		want    string
+	}{
+		{date(2024, 1, 1), date(2024, 3, 1), durDays, "P60D"},
+		{date(2024, 1, 31), date(2024, 2, 29), durMonths, "P29D"},
+		{date(2024, 1, 31), date(2024, 3, 31), durMonths, "P2M"},
+		{date(2020, 2, 29), date(2024, 3, 1), durYears, "P4Y1D"},
+		{date(2024, 3, 1), date(2024, 1, 15), durMonths, "-P1M17D"},
+		{date(2024, 1, 1), date(2024, 1, 20), durWeeks, "P2W5D"},
+		{date(2024, 1, 1), date(2024, 1, 2).Add(90 * time.Minute), durHours, "PT25H30M"},
+		{date(2024, 1, 2), date(2024, 1, 1).Add(time.Hour), durDays, "-PT23H"},
+	}
+	for _, tt := range tests {
+		got := formatISODuration(differenceISO(tt.a, tt.b, tt.largest))
+		if got != tt.want {
+			t.Errorf("%s until %s (largest %s): got %s, want %s",
+				formatISODate(tt.a), formatISODate(tt.b), durationFieldNames[tt.largest], got, tt.want)
+		}
+	}
+}
+
+func TestISODurationRoundTrip(t *testing.T) {
+	for _, s := range []string{"PT0S", "P1Y2M3W4D", "PT5H6M7.008009S", "-P1DT0.5S", "PT0.000000001S"} {
+		d, err := parseISODuration(s)
+		if err != nil {
+			t.Errorf("parse %s: %v", s, err)
+			continue
+		}
+		if got := formatISODuration(d); got != s {
+			t.Errorf("round trip %s: got %s", s, got)
+		}
+	}
+	for _, s := range []string{"P", "PT", "1D", "P1.5D", "PT1H2"} {
+		if _, err := parseISODuration(s); err == nil {
+			t.Errorf("parse %s: expected an error", s)
+		}
+	}
+}
+
+func TestDurationBalance(t *testing.T) {
+	rl := setupTemporal()
+	a, _ := rl.newDuration(duration{durHours: 1, durMinutes: 90})
+	sum, err := rl.durationAdd(a, []*runtime.Value{runtime.NewString("PT30M")})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if got, _ := durationToString(sum, nil); got.Str != "PT3H" {
+		t.Errorf("add: got %s, want PT3H", got.Str)
+	}
+	total, err := durationTotal(a, []*runtime.Value{runtime.NewString("minutes")})
+	if err != nil || total.Number != 150 {
+		t.Errorf("total: got %v, %v; want 150", total, err)
+	}
+	if _, err := rl.newDuration(duration{durDays: 1, durHours: -1}); err == nil {
+		t.Errorf("mixed signs: expected a RangeError")
+	}
+	cal, _ := rl.newDuration(duration{durMonths: 1})
+	if _, err := rl.durationAdd(cal, []*runtime.Value{runtime.NewString("P1D")}); err == nil {
+		t.Errorf("calendar units: expected a RangeError")
+	}
+}
+
+func TestParseISODateTime(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-05-06", "2024-05-06T00:00:00"},
+		{"2024-05-06T07:08", "2024-05-06T07:08:00"},
+		{"2024-05-06T07:08:09.120", "2024-05-06T07:08:09.12"},
+		{"2024-05-06T07:08:09+02:00[Europe/Paris]", "2024-05-06T07:08:09"},
+		{"+012345-01-01", "+012345-01-01T00:00:00"},
+	}
+	for _, tt := range tests {
+		got, err := parseISODateTime(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.in, err)
+			continue
+		}
+		if s := formatISODate(got) + "T" + formatISOTime(got); s != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.in, s, tt.want)
+		}
+	}
+	for _, in := range []string{"2024-02-30", "2024-13-01", "2024-05-06T24:00", "2024-05-06Z", "20240506"} {
+		if _, err := parseISODateTime(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
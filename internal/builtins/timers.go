@@ -0,0 +1,80 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// registerTimers declares setTimeout, setInterval, clearTimeout and
+// clearInterval, and queueMicrotask. The timers they set run from the
+// host's event loop, see Interpreter.RunLoop, once the script has finished;
+// microtasks run before that, as soon as the script has.
+func (rl *Realm) registerTimers(env *runtime.Environment) {
+	rl.declareFunc(env, "setTimeout", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return rl.setTimer(args, false)
+	})
+	rl.declareFunc(env, "setInterval", 1, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return rl.setTimer(args, true)
+	})
+	rl.declareFunc(env, "clearTimeout", 1, rl.clearTimer)
+	rl.declareFunc(env, "clearInterval", 1, rl.clearTimer)
+	rl.declareFunc(env, "queueMicrotask", 1, rl.queueMicrotask)
+}
+
+// setTimer implements setTimeout(callback, delay, ...args) and
+// setInterval: callback is called with args after delay milliseconds, a
+// delay that is missing or not a positive number meaning as soon as
+// possible. It returns the id of the timer.
+func (rl *Realm) setTimer(args []*runtime.Value, repeat bool) (*runtime.Value, error) {
+	callback := argAt(args, 0)
+	fn := getCallable(callback)
+	if fn == nil {
+		return nil, fmt.Errorf("TypeError: The \"callback\" argument must be of type function")
+	}
+	ms, err := toNumberErr(argAt(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(ms) || ms < 0 {
+		ms = 0
+	}
+	// Delays too long for a Duration wait forever, which is as good as the
+	// timer never firing.
+	delay := time.Duration(math.Min(ms, float64(math.MaxInt64/time.Millisecond)) * float64(time.Millisecond))
+	var extra []*runtime.Value
+	if len(args) > 2 {
+		extra = append(extra, args[2:]...)
+	}
+	id := rl.Agent.Events.AddTimer(delay, repeat, func() error {
+		_, err := fn(runtime.Undefined, extra)
+		return err
+	})
+	return runtime.NewNumber(float64(id)), nil
+}
+
+// clearTimer implements clearTimeout and clearInterval, which cancel a
+// timer of either kind by id and ignore anything else.
+func (rl *Realm) clearTimer(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	if id := argAt(args, 0); id.Type == runtime.TypeNumber {
+		rl.Agent.Events.ClearTimer(int(id.Number))
+	}
+	return runtime.Undefined, nil
+}
+
+// queueMicrotask implements queueMicrotask(callback): callback is called
+// with no arguments from the microtask queue, in turn with promise
+// reactions. An exception it throws is uncaught.
+func (rl *Realm) queueMicrotask(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+	fn := getCallable(argAt(args, 0))
+	if fn == nil {
+		return nil, fmt.Errorf("TypeError: The \"callback\" argument must be of type function")
+	}
+	rl.Agent.Events.EnqueueJob(func() error {
+		_, err := fn(runtime.Undefined, nil)
+		return err
+	})
+	return runtime.Undefined, nil
+}
@@ -0,0 +1,79 @@
+package builtins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+func TestTimers(t *testing.T) {
+	rl := registerTestRealm()
+	var calls []string
+	record := func(name string) *runtime.Value {
+		return runtime.NewObject(rl.newFuncObject(name, 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			calls = append(calls, name+":"+argAt(args, 0).ToString())
+			return runtime.Undefined, nil
+		}))
+	}
+
+	if _, err := rl.setTimer([]*runtime.Value{runtime.NewNumber(1)}, false); err == nil {
+		t.Error("setTimeout with a non-function callback should throw")
+	}
+
+	if _, err := rl.setTimer([]*runtime.Value{record("late"), runtime.NewNumber(1), runtime.NewString("x")}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.setTimer([]*runtime.Value{record("first"), runtime.NaN, runtime.NewString("y")}, false); err != nil {
+		t.Fatal(err)
+	}
+	cleared, _ := rl.setTimer([]*runtime.Value{record("cleared")}, false)
+	rl.clearTimer(runtime.Undefined, []*runtime.Value{cleared})
+	interval, _ := rl.setTimer([]*runtime.Value{record("interval")}, true)
+
+	// Run the timers until the late one has fired; the interval keeps
+	// firing in the meantime.
+	late := false
+	for !late {
+		when, ok := rl.Agent.Events.NextTimer()
+		if !ok {
+			t.Fatalf("the timers ran out before the late one fired: %v", calls)
+		}
+		time.Sleep(time.Until(when))
+		if _, err := rl.Agent.Events.RunTimer(); err != nil {
+			t.Fatal(err)
+		}
+		late = calls[len(calls)-1] == "late:x"
+	}
+	rl.clearTimer(runtime.Undefined, []*runtime.Value{interval})
+	if n := rl.Agent.Events.PendingTimers(); n != 0 {
+		t.Errorf("expected no pending timers, got %d", n)
+	}
+	if len(calls) < 3 || calls[0] != "first:y" || calls[1] != "interval:undefined" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	for _, c := range calls {
+		if c == "cleared:undefined" {
+			t.Errorf("a cleared timer ran: %v", calls)
+		}
+	}
+}
+
+func TestQueueMicrotask(t *testing.T) {
+	rl := registerTestRealm()
+	if _, err := rl.queueMicrotask(runtime.Undefined, []*runtime.Value{runtime.NewNumber(1)}); err == nil {
+		t.Error("queueMicrotask with a non-function callback should throw")
+	}
+	calls := 0
+	cb := runtime.NewObject(rl.newFuncObject("cb", 0, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		calls++
+		return runtime.Undefined, nil
+	}))
+	rl.queueMicrotask(runtime.Undefined, []*runtime.Value{cb})
+	if calls != 0 || rl.Agent.Events.PendingJobs() != 1 {
+		t.Fatal("the callback waits on the microtask queue")
+	}
+	if err := rl.Agent.Events.RunJobs(); err != nil || calls != 1 {
+		t.Errorf("the callback runs once the queue drains: %d, %v", calls, err)
+	}
+}
@@ -0,0 +1,183 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// asyncGenerator is the internal state of an async generator object. Its
+// body runs as a coroutine that suspends at both await and yield. Calls to
+// next, throw and return each get a promise and are queued; they are served
+// in order, one at a time, as the body reaches its yields.
+type asyncGenerator struct {
+	co    *coroutine
+	state generatorState
+	queue []asyncGenRequest
+}
+
+type asyncGenRequest struct {
+	msg             coResume
+	resolve, reject func(*runtime.Value)
+}
+
+func getAsyncGenerator(val *runtime.Value) *asyncGenerator {
+	if val == nil || val.Type != runtime.TypeObject || val.Object == nil || val.Object.Internal == nil {
+		return nil
+	}
+	g, _ := val.Object.Internal["asyncGenerator"].(*asyncGenerator)
+	return g
+}
+
+// asyncGeneratorFunction makes the callable of an async generator function.
+// Like generatorFunction, a call binds the parameters and returns an async
+// generator object without running the body.
+func (interp *Interpreter) asyncGeneratorFunction(start func(this *runtime.Value, args []*runtime.Value) (func() (*runtime.Value, error), error), fnObj **runtime.Object) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if interp.realm.NewPromise == nil {
+			return nil, fmt.Errorf("ReferenceError: Promise is not defined")
+		}
+		body, err := start(this, args)
+		if err != nil {
+			return nil, err
+		}
+		g := &asyncGenerator{}
+		// A returned value is awaited before it completes the generator.
+		g.co = newCoroutine(func() (*runtime.Value, error) {
+			val, err := body()
+			if err != nil || val.Type != runtime.TypeObject {
+				return val, err
+			}
+			val, sig := interp.await(val, interp.global)
+			if sig.typ == sigThrow {
+				return nil, &jsError{value: sig.value}
+			}
+			return val, nil
+		})
+		g.co.generator = true
+		g.co.async = true
+
+		proto := interp.asyncGeneratorPrototype()
+		if p := (*fnObj).Get("prototype"); p.Type == runtime.TypeObject && p.Object != nil {
+			proto = p.Object
+		}
+		obj := runtime.NewOrdinaryObject(proto)
+		obj.OType = runtime.ObjTypeGenerator
+		obj.Internal = map[string]interface{}{"asyncGenerator": g}
+		return runtime.NewObject(obj), nil
+	}
+}
+
+// enqueue queues a next, throw or return call and returns its promise. The
+// request is served at once unless the body is running or awaiting.
+func (g *asyncGenerator) enqueue(interp *Interpreter, msg coResume) *runtime.Value {
+	promise, resolve, reject := interp.realm.NewPromise()
+	g.queue = append(g.queue, asyncGenRequest{msg: msg, resolve: resolve, reject: reject})
+	if g.state != genExecuting {
+		g.drain(interp)
+	}
+	return promise
+}
+
+// drain serves queued requests until one resumes the body. Requests made
+// after the generator completed settle immediately; a return request awaits
+// its value first.
+func (g *asyncGenerator) drain(interp *Interpreter) {
+	for len(g.queue) > 0 {
+		req := g.queue[0]
+		if g.state == genSuspendedStart && req.msg.mode != resumeNext {
+			g.state = genCompleted
+		}
+		if g.state != genCompleted {
+			g.state = genExecuting
+			g.step(interp, interp.resume(g.co, req.msg))
+			return
+		}
+		g.queue = g.queue[1:]
+		switch req.msg.mode {
+		case resumeThrow:
+			req.reject(req.msg.value)
+		case resumeReturn:
+			g.state = genExecuting
+			interp.realm.AwaitValue(req.msg.value, func(val *runtime.Value) {
+				g.state = genCompleted
+				req.resolve(interp.iterResult(val, true))
+				g.drain(interp)
+			}, func(reason *runtime.Value) {
+				g.state = genCompleted
+				req.reject(reason)
+				g.drain(interp)
+			})
+			return
+		default:
+			req.resolve(interp.iterResult(runtime.Undefined, true))
+		}
+	}
+}
+
+// step handles the body suspending or completing. An await is settled and
+// the body resumed from a job; a yield or completion settles the request at
+// the head of the queue.
+func (g *asyncGenerator) step(interp *Interpreter, y coYield) {
+	if !y.done && y.await {
+		interp.realm.AwaitValue(y.value, func(val *runtime.Value) {
+			g.step(interp, interp.resume(g.co, coResume{value: val}))
+		}, func(reason *runtime.Value) {
+			g.step(interp, interp.resume(g.co, coResume{value: reason, mode: resumeThrow}))
+		})
+		return
+	}
+	req := g.queue[0]
+	g.queue = g.queue[1:]
+	switch {
+	case !y.done:
+		g.state = genSuspendedYield
+		req.resolve(interp.iterResult(y.value, false))
+	case y.err != nil:
+		g.state = genCompleted
+		req.reject(interp.errorFromGoError(y.err, interp.global))
+	default:
+		g.state = genCompleted
+		req.resolve(interp.iterResult(y.value, true))
+	}
+	g.drain(interp)
+}
+
+// asyncGeneratorPrototype returns %AsyncGeneratorPrototype%, which holds
+// next, return and throw. Async generator functions' prototype objects
+// inherit from it.
+func (interp *Interpreter) asyncGeneratorPrototype() *runtime.Object {
+	if interp.asyncGenProto != nil {
+		return interp.asyncGenProto
+	}
+	proto := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+	method := func(name string, mode resumeMode) {
+		fn := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			arg := runtime.Undefined
+			if len(args) > 0 {
+				arg = args[0]
+			}
+			g := getAsyncGenerator(this)
+			if g == nil {
+				promise, _, reject := interp.realm.NewPromise()
+				reject(interp.makeErrorObject("TypeError", name+" method called on incompatible receiver", interp.global))
+				return promise, nil
+			}
+			return g.enqueue(interp, coResume{value: arg, mode: mode}), nil
+		})
+		fn.DefineProperty("name", &runtime.Property{Value: runtime.NewString(name), Configurable: true})
+		fn.DefineProperty("length", &runtime.Property{Value: runtime.NewNumber(1), Configurable: true})
+		proto.DefineProperty(name, &runtime.Property{Value: runtime.NewObject(fn), Writable: true, Configurable: true})
+	}
+	method("next", resumeNext)
+	method("return", resumeReturn)
+	method("throw", resumeThrow)
+	if runtime.SymbolAsyncIterator != nil {
+		self := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return this, nil
+		})
+		proto.DefineProperty(runtime.SymbolAsyncIterator.Key(), &runtime.Property{Value: runtime.NewObject(self), Writable: true, Configurable: true})
+	}
+	interp.asyncGenProto = proto
+	return proto
+}
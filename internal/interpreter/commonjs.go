@@ -0,0 +1,178 @@
+package interpreter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/example/jsgo/internal/parser"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// EvalCommonJS runs filename as a CommonJS module, the way Node runs its
+// entry script, and returns its module.exports. The module scope provides
+// require, module, exports, __filename and __dirname. require resolves
+// relative and absolute paths, trying the path as given, then with ".js" and
+// ".json" appended, then as a directory with an index.js. Each file is
+// evaluated once per interpreter; later requires return the cached exports.
+func (interp *Interpreter) EvalCommonJS(filename string) (_ *runtime.Value, err error) {
+	interp.prepareGlobalEnv()
+	defer interp.drainJobs(&err)
+
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	exports, sig := interp.requireFile(path, interp.global)
+	if sig.typ == sigThrow {
+		return nil, interp.uncaught(sig.value)
+	}
+	return exports, nil
+}
+
+// requireFile loads the module at the absolute path, or returns the exports
+// of the cached module. The module is cached before it runs, so a require
+// cycle sees the exports as they were when the cycle started.
+func (interp *Interpreter) requireFile(path string, env *runtime.Environment) (*runtime.Value, signal) {
+	if module, ok := interp.cjsModules[path]; ok {
+		return module.Get("exports"), signal{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("Error", fmt.Sprintf("Cannot find module '%s'", path), env)}
+	}
+
+	exports := runtime.NewObject(runtime.NewOrdinaryObject(interp.realm.ObjectPrototype))
+	module := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+	module.Set("exports", exports)
+	module.Set("id", runtime.NewString(path))
+	module.Set("filename", runtime.NewString(path))
+	module.Set("loaded", runtime.False)
+	if interp.cjsModules == nil {
+		interp.cjsModules = make(map[string]*runtime.Object)
+	}
+	interp.cjsModules[path] = module
+
+	var sig signal
+	if strings.HasSuffix(path, ".json") {
+		sig = interp.loadJSONModule(module, string(data), env)
+	} else {
+		sig = interp.runCommonJS(module, path, string(data))
+	}
+	if sig.typ == sigThrow {
+		// Like Node, forget a module that failed so it can be required again.
+		delete(interp.cjsModules, path)
+		return nil, sig
+	}
+	module.Set("loaded", runtime.True)
+	return module.Get("exports"), signal{}
+}
+
+// runCommonJS evaluates source in a fresh function-like scope holding the
+// CommonJS module variables. this is the initial exports object, and a
+// top-level return ends the module early.
+func (interp *Interpreter) runCommonJS(module *runtime.Object, path, source string) signal {
+	env := runtime.NewEnvironment(interp.global, false)
+	program, errs := parser.New(source).ParseProgram()
+	if len(errs) > 0 {
+		return signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", fmt.Sprintf("%s: %v", path, errs[0]), env)}
+	}
+
+	env.SetStrict(program.Strict)
+	if program.Scope.DirectEval {
+		env.MarkCaptureBoundary()
+	}
+
+	dir := filepath.Dir(path)
+	env.Declare("this", "const", module.Get("exports"))
+	env.Declare("module", "var", runtime.NewObject(module))
+	env.Declare("exports", "var", module.Get("exports"))
+	env.Declare("require", "var", runtime.NewObject(interp.makeRequire(dir, env)))
+	env.Declare("__filename", "var", runtime.NewString(path))
+	env.Declare("__dirname", "var", runtime.NewString(dir))
+
+	defer interp.enterFrame(nil, path)()
+	interp.hoist(program.Statements, env)
+	for _, stmt := range program.Statements {
+		_, sig := interp.execStatement(stmt, env)
+		switch sig.typ {
+		case sigThrow:
+			interp.noteThrow(sig.value)
+			return sig
+		case sigReturn:
+			return signal{}
+		}
+	}
+	return signal{}
+}
+
+// loadJSONModule sets module.exports to the parsed contents of a .json file.
+func (interp *Interpreter) loadJSONModule(module *runtime.Object, source string, env *runtime.Environment) signal {
+	jsonVal, err := env.Get("JSON")
+	if err != nil || jsonVal.Type != runtime.TypeObject || jsonVal.Object == nil {
+		return signal{typ: sigThrow, value: interp.makeErrorObject("Error", "JSON is not available to load .json modules", env)}
+	}
+	parse := jsonVal.Object.Get("parse")
+	if parse == nil || parse.Type != runtime.TypeObject || parse.Object == nil || parse.Object.Callable == nil {
+		return signal{typ: sigThrow, value: interp.makeErrorObject("Error", "JSON is not available to load .json modules", env)}
+	}
+	val, err := parse.Object.Callable(jsonVal, []*runtime.Value{runtime.NewString(source)})
+	if err != nil {
+		return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	module.Set("exports", val)
+	return signal{}
+}
+
+// makeRequire creates the require function for modules in dir.
+func (interp *Interpreter) makeRequire(dir string, env *runtime.Environment) *runtime.Object {
+	resolve := func(args []*runtime.Value) (string, signal) {
+		if len(args) == 0 || args[0].Type != runtime.TypeString {
+			return "", signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "The \"id\" argument must be of type string", env)}
+		}
+		path, ok := resolveCommonJS(args[0].Str, dir)
+		if !ok {
+			return "", signal{typ: sigThrow, value: interp.makeErrorObject("Error", fmt.Sprintf("Cannot find module '%s' from '%s'", args[0].Str, dir), env)}
+		}
+		return path, signal{}
+	}
+
+	require := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		path, sig := resolve(args)
+		if sig.typ == sigNone {
+			var exports *runtime.Value
+			if exports, sig = interp.requireFile(path, env); sig.typ == sigNone {
+				return exports, nil
+			}
+		}
+		return nil, &jsError{value: sig.value}
+	})
+	require.Set("resolve", runtime.NewObject(runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		path, sig := resolve(args)
+		if sig.typ != sigNone {
+			return nil, &jsError{value: sig.value}
+		}
+		return runtime.NewString(path), nil
+	})))
+	return require
+}
+
+// resolveCommonJS maps a require specifier to an existing file. Bare
+// specifiers (packages) are not supported.
+func resolveCommonJS(specifier, dir string) (string, bool) {
+	if !strings.HasPrefix(specifier, "./") && !strings.HasPrefix(specifier, "../") &&
+		specifier != "." && specifier != ".." && !filepath.IsAbs(specifier) {
+		return "", false
+	}
+	path := specifier
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, specifier)
+	}
+	for _, candidate := range []string{path, path + ".js", path + ".json", filepath.Join(path, "index.js")} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
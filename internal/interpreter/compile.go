@@ -0,0 +1,939 @@
+package interpreter
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// Mode selects how an interpreter runs the bodies of functions.
+type Mode int
+
+const (
+	// TreeWalk, the mode of a new interpreter, evaluates every function
+	// by walking its syntax tree.
+	TreeWalk Mode = iota
+	// Compiled compiles the body of a plain
+	// function to bytecode the first time the function is called and runs
+	// the bytecode on a stack machine from then on. Its parameters and local
+	// variables live in slots of the frame rather than in an environment.
+	// Functions the compiler does not support, such as async functions,
+	// generators, functions that use arguments, eval, classes, try or
+	// switch, are walked as in TreeWalk, which remains the reference for the
+	// semantics of both.
+	Compiled
+)
+
+// SetMode selects how functions created from now on are run. The top level
+// of scripts and modules is always walked.
+func (interp *Interpreter) SetMode(mode Mode) { interp.mode = mode }
+
+// Mode returns the mode set with SetMode.
+func (interp *Interpreter) Mode() Mode { return interp.mode }
+
+// compileFunction returns the bytecode of a function, or nil if the
+// function has to be walked. Bytecode is cached by body, so the closures
+// created from one function expression share it.
+func (interp *Interpreter) compileFunction(selfName string, params []ast.Expression, defaults []ast.Expression, rest ast.Expression, body *ast.BlockStatement, scope *ast.Scope, strict bool) *bytecode {
+	if code, ok := interp.compiled[body]; ok {
+		return code
+	}
+	var code *bytecode
+	if scope != nil && !scope.Dynamic && !scope.DirectEval && rest == nil {
+		// The first pass resolves which locals the functions nested in the
+		// body capture; the second keeps those in an environment the
+		// closures share instead of in slots.
+		c := newCompiler(nil)
+		ok := c.function(selfName, params, defaults, body)
+		if ok && len(c.captures) > 0 {
+			c = newCompiler(c.captures)
+			ok = c.function(selfName, params, defaults, body)
+		}
+		if ok {
+			code = c.code
+			code.strict = strict
+		}
+	}
+	if interp.compiled == nil {
+		interp.compiled = make(map[*ast.BlockStatement]*bytecode)
+	}
+	interp.compiled[body] = code
+	return code
+}
+
+// A compiler lowers the body of a function to bytecode. Any construct it
+// does not support makes it give up, leaving the function to the
+// tree-walker.
+type compiler struct {
+	code     *bytecode
+	scopes   []map[string]local // innermost last
+	loops    []*loopJumps
+	envDepth int                               // block environments entered, see block
+	hoisted  map[*ast.FunctionDeclaration]bool // function declarations created on entry
+	captures map[int]bool                      // slots of the locals nested functions refer to
+	captured map[int]bool                      // captures found by the first pass
+	failed   bool
+}
+
+func newCompiler(captured map[int]bool) *compiler {
+	return &compiler{code: &bytecode{self: -1}, captures: map[int]bool{}, captured: captured}
+}
+
+// A local is a variable of the function, kept in a slot of the frame
+// unless a nested function captures it. A captured local is the index-th
+// binding of the environment entered at envDepth level, 0 being the
+// function's own.
+type local struct {
+	slot     int
+	kind     string // "param", "var", "let", "const" or "self"
+	captured bool
+	level    int
+	index    int
+}
+
+func (l local) constant() bool {
+	return l.kind == "const" || l.kind == "self"
+}
+
+// loopJumps collects the jumps of break and continue statements in a loop,
+// to be patched once its end and continue target are known.
+type loopJumps struct {
+	breaks, continues []int
+	envDepth          int
+}
+
+func (c *compiler) function(selfName string, params []ast.Expression, defaults []ast.Expression, body *ast.BlockStatement) bool {
+	// The function's own name is in a scope of its own, which the
+	// parameters and variables shadow, but the parameters take the first
+	// slots.
+	c.pushScope()
+	c.pushScope()
+	for i, param := range params {
+		ident, ok := param.(*ast.Identifier)
+		if !ok || (i < len(defaults) && defaults[i] != nil) || c.scopes[1][ident.Value] != (local{}) {
+			return false
+		}
+		c.declare(ident.Value, "param")
+	}
+	c.code.params = len(params)
+	if selfName != "" {
+		c.code.self = c.declareIn(c.scopes[0], selfName, "self")
+	}
+	var vars []string
+	c.collectVars(body.Statements, &vars)
+	c.hoisted = map[*ast.FunctionDeclaration]bool{}
+	for _, stmt := range body.Statements {
+		if fn, ok := stmt.(*ast.FunctionDeclaration); ok {
+			c.hoisted[fn] = true
+			vars = append(vars, fn.Name.Value)
+		}
+	}
+	for _, name := range vars {
+		if _, ok := c.scopes[1][name]; !ok {
+			c.code.vars = append(c.code.vars, c.declare(name, "var"))
+		}
+	}
+	// Lexical declarations of the body start out uninitialized, as all
+	// slots but those of parameters and vars do.
+	c.declareLexical(body.Statements)
+	for _, scope := range c.scopes {
+		for name, l := range scope {
+			if l.captured {
+				c.code.decls = append(c.code.decls, envDecl{name: name, kind: l.kind, slot: l.slot - 1})
+			}
+		}
+	}
+	slices.SortFunc(c.code.decls, func(a, b envDecl) int { return a.slot - b.slot })
+	for i, d := range c.code.decls {
+		c.place(d, 0, i)
+	}
+
+	for _, stmt := range body.Statements {
+		if fn, ok := stmt.(*ast.FunctionDeclaration); ok {
+			c.closure(fn, fn.Scope)
+			c.initIdentifier(fn.Name, "var")
+		}
+	}
+	for _, stmt := range body.Statements {
+		c.statement(stmt)
+	}
+	c.emit(opReturnUndefined, 0, 0)
+	return !c.failed
+}
+
+func (c *compiler) pushScope() {
+	c.scopes = append(c.scopes, map[string]local{})
+}
+
+func (c *compiler) popScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// declare allocates a slot for name in the innermost scope.
+func (c *compiler) declare(name, kind string) int {
+	return c.declareIn(c.scopes[len(c.scopes)-1], name, kind)
+}
+
+func (c *compiler) declareIn(scope map[string]local, name, kind string) int {
+	slot := c.code.slots
+	c.code.slots++
+	c.code.slotNames = append(c.code.slotNames, name)
+	// Slot numbers start at one in the scope maps so that the zero local
+	// means "not declared".
+	scope[name] = local{slot: slot + 1, kind: kind, captured: c.captured[slot]}
+	if c.captured[slot] {
+		c.code.env = true
+	}
+	return slot
+}
+
+// lookup returns the slot of the local name, if it is one.
+func (c *compiler) lookup(name string) (local, bool) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if l, ok := c.scopes[i][name]; ok {
+			l.slot--
+			return l, true
+		}
+	}
+	return local{}, false
+}
+
+// collectVars appends the names of the var declarations in stmts to vars.
+func (c *compiler) collectVars(stmts []ast.Statement, vars *[]string) {
+	for _, stmt := range stmts {
+		c.collectVarsIn(stmt, vars)
+	}
+}
+
+func (c *compiler) collectVarsIn(stmt ast.Node, vars *[]string) {
+	switch s := stmt.(type) {
+	case *ast.VariableDeclaration:
+		if s.Kind != "var" {
+			return
+		}
+		for _, decl := range s.Declarations {
+			if ident, ok := decl.Name.(*ast.Identifier); ok {
+				*vars = append(*vars, ident.Value)
+			} else {
+				c.failed = true
+			}
+		}
+	case *ast.BlockStatement:
+		c.collectVars(s.Statements, vars)
+	case *ast.IfStatement:
+		c.collectVarsIn(s.Consequence, vars)
+		if s.Alternative != nil {
+			c.collectVarsIn(s.Alternative, vars)
+		}
+	case *ast.WhileStatement:
+		c.collectVarsIn(s.Body, vars)
+	case *ast.DoWhileStatement:
+		c.collectVarsIn(s.Body, vars)
+	case *ast.ForStatement:
+		if s.Init != nil {
+			c.collectVarsIn(s.Init, vars)
+		}
+		c.collectVarsIn(s.Body, vars)
+	}
+}
+
+// declareLexical declares the let and const bindings of a block in the
+// innermost scope and returns their slots.
+func (c *compiler) declareLexical(stmts []ast.Statement) []int {
+	slots := []int{}
+	for _, stmt := range stmts {
+		decl, ok := stmt.(*ast.VariableDeclaration)
+		if !ok || decl.Kind == "var" {
+			continue
+		}
+		for _, d := range decl.Declarations {
+			ident, ok := d.Name.(*ast.Identifier)
+			if !ok {
+				c.failed = true
+				continue
+			}
+			slots = append(slots, c.declare(ident.Value, decl.Kind))
+		}
+	}
+	return slots
+}
+
+// enterLexical makes the let and const bindings declared in slots
+// uninitialized. Those that nested functions capture are created in an
+// environment of their own, entered until leaveLexical, so that a closure
+// created in a loop keeps the bindings of its iteration.
+func (c *compiler) enterLexical(slots []int) bool {
+	var decls []envDecl
+	for _, slot := range slots {
+		if !c.captured[slot] {
+			c.emit(opClear, slot, 0)
+			continue
+		}
+		name := c.code.slotNames[slot]
+		l, _ := c.lookup(name)
+		decls = append(decls, envDecl{name: name, kind: l.kind, slot: slot})
+	}
+	if len(decls) == 0 {
+		return false
+	}
+	c.code.blocks = append(c.code.blocks, decls)
+	c.emit(opPushScope, len(c.code.blocks)-1, 0)
+	c.envDepth++
+	for i, d := range decls {
+		c.place(d, c.envDepth, i)
+	}
+	return true
+}
+
+// place records that the captured local d is the index-th binding of the
+// environment entered at level.
+func (c *compiler) place(d envDecl, level, index int) {
+	for _, scope := range c.scopes {
+		if l, ok := scope[d.name]; ok && l.slot == d.slot+1 {
+			l.level, l.index = level, index
+			scope[d.name] = l
+		}
+	}
+}
+
+// envRef returns the operand of the instructions that reach the captured
+// local l, named name, from the current environment.
+func (c *compiler) envRef(name string, l local) int {
+	c.code.refs = append(c.code.refs, envRef{name: name, depth: c.envDepth - l.level, index: l.index})
+	return len(c.code.refs) - 1
+}
+
+func (c *compiler) leaveLexical(entered bool) {
+	if entered {
+		c.emit(opPopScope, 0, 0)
+		c.envDepth--
+	}
+}
+
+func (c *compiler) emit(op opcode, a, b int) int {
+	c.code.code = append(c.code.code, instr{op: op, a: int32(a), b: int32(b)})
+	return len(c.code.code) - 1
+}
+
+// here returns the index of the next instruction.
+func (c *compiler) here() int {
+	return len(c.code.code)
+}
+
+// patch makes the jump at pc go to the next instruction.
+func (c *compiler) patch(pc int) {
+	c.code.code[pc].a = int32(c.here())
+}
+
+func (c *compiler) node(n ast.Node) int {
+	c.code.nodes = append(c.code.nodes, n)
+	return len(c.code.nodes) - 1
+}
+
+func (c *compiler) constant(v *runtime.Value) int {
+	c.code.consts = append(c.code.consts, v)
+	return len(c.code.consts) - 1
+}
+
+func (c *compiler) name(s string) int {
+	for i, n := range c.code.names {
+		if n == s {
+			return i
+		}
+	}
+	c.code.names = append(c.code.names, s)
+	return len(c.code.names) - 1
+}
+
+func (c *compiler) statement(stmt ast.Statement) {
+	c.emit(opStatement, c.node(stmt), 0)
+	switch s := stmt.(type) {
+	case *ast.ExpressionStatement:
+		c.expression(s.Expression)
+		c.emit(opPop, 0, 0)
+	case *ast.VariableDeclaration:
+		for _, decl := range s.Declarations {
+			ident, ok := decl.Name.(*ast.Identifier)
+			if !ok {
+				c.failed = true
+				return
+			}
+			if decl.Value == nil {
+				if s.Kind == "var" {
+					continue
+				}
+				c.emit(opConst, c.constant(runtime.Undefined), 0)
+			} else {
+				c.expression(decl.Value)
+				c.nameFunction(decl.Value, ident.Value)
+			}
+			c.initIdentifier(ident, s.Kind)
+		}
+	case *ast.BlockStatement:
+		c.block(s.Statements)
+	case *ast.ReturnStatement:
+		if s.Value == nil {
+			c.emit(opReturnUndefined, 0, 0)
+			return
+		}
+		c.expression(s.Value)
+		c.emit(opReturn, 0, 0)
+	case *ast.IfStatement:
+		c.expression(s.Condition)
+		jump := c.emit(opJumpIfFalse, 0, 0)
+		c.statement(s.Consequence)
+		if s.Alternative == nil {
+			c.patch(jump)
+			return
+		}
+		end := c.emit(opJump, 0, 0)
+		c.patch(jump)
+		c.statement(s.Alternative)
+		c.patch(end)
+	case *ast.WhileStatement:
+		top := c.here()
+		c.expression(s.Condition)
+		exit := c.emit(opJumpIfFalse, 0, 0)
+		jumps := c.loopBody(s.Body)
+		c.patchAll(jumps.continues, top)
+		c.emit(opJump, top, 0)
+		c.patch(exit)
+		c.patchAll(jumps.breaks, c.here())
+	case *ast.DoWhileStatement:
+		top := c.here()
+		jumps := c.loopBody(s.Body)
+		c.patchAll(jumps.continues, c.here())
+		c.expression(s.Condition)
+		c.emit(opJumpIfTrue, top, 0)
+		c.patchAll(jumps.breaks, c.here())
+	case *ast.ForStatement:
+		c.pushScope()
+		switch init := s.Init.(type) {
+		case nil:
+		case *ast.VariableDeclaration:
+			// Each iteration would need a copy of a captured binding.
+			for _, slot := range c.declareLexical([]ast.Statement{init}) {
+				if c.captured[slot] {
+					c.failed = true
+				}
+				c.emit(opClear, slot, 0)
+			}
+			c.statement(init)
+		case ast.Expression:
+			c.expression(init)
+			c.emit(opPop, 0, 0)
+		default:
+			c.failed = true
+		}
+		top := c.here()
+		exit := -1
+		if s.Test != nil {
+			c.expression(s.Test)
+			exit = c.emit(opJumpIfFalse, 0, 0)
+		}
+		jumps := c.loopBody(s.Body)
+		c.patchAll(jumps.continues, c.here())
+		if s.Update != nil {
+			c.expression(s.Update)
+			c.emit(opPop, 0, 0)
+		}
+		c.emit(opJump, top, 0)
+		if exit >= 0 {
+			c.patch(exit)
+		}
+		c.patchAll(jumps.breaks, c.here())
+		c.popScope()
+	case *ast.BreakStatement:
+		if s.Label != nil || len(c.loops) == 0 {
+			c.failed = true
+			return
+		}
+		loop := c.loops[len(c.loops)-1]
+		c.leaveEnvs(loop)
+		loop.breaks = append(loop.breaks, c.emit(opJump, 0, 0))
+	case *ast.ContinueStatement:
+		if s.Label != nil || len(c.loops) == 0 {
+			c.failed = true
+			return
+		}
+		loop := c.loops[len(c.loops)-1]
+		c.leaveEnvs(loop)
+		loop.continues = append(loop.continues, c.emit(opJump, 0, 0))
+	case *ast.ThrowStatement:
+		c.expression(s.Argument)
+		c.emit(opThrow, c.node(s), 0)
+	case *ast.FunctionDeclaration:
+		// Only those of the body are supported, which are created on entry.
+		if !c.hoisted[s] {
+			c.failed = true
+		}
+	case *ast.EmptyStatement, *ast.DebuggerStatement:
+	default:
+		c.failed = true
+	}
+}
+
+// block compiles the statements of a block in a scope of their own. Its
+// let and const bindings are uninitialized whenever the block is entered.
+func (c *compiler) block(stmts []ast.Statement) {
+	c.pushScope()
+	entered := c.enterLexical(c.declareLexical(stmts))
+	for _, stmt := range stmts {
+		c.statement(stmt)
+	}
+	c.leaveLexical(entered)
+	c.popScope()
+}
+
+// leaveEnvs leaves the block environments entered inside loop before
+// jumping out of them.
+func (c *compiler) leaveEnvs(loop *loopJumps) {
+	for range c.envDepth - loop.envDepth {
+		c.emit(opPopScope, 0, 0)
+	}
+}
+
+func (c *compiler) loopBody(body ast.Statement) *loopJumps {
+	jumps := &loopJumps{envDepth: c.envDepth}
+	c.loops = append(c.loops, jumps)
+	c.statement(body)
+	c.loops = c.loops[:len(c.loops)-1]
+	return jumps
+}
+
+func (c *compiler) patchAll(pcs []int, target int) {
+	for _, pc := range pcs {
+		c.code.code[pc].a = int32(target)
+	}
+}
+
+// binary compiles a binary operator. The operators with a fast path for
+// numbers have an instruction of their own; opBinary applies the others.
+func (c *compiler) binary(op string) {
+	for code, name := range opNames {
+		if name == op {
+			c.emit(code, 0, 0)
+			return
+		}
+	}
+	c.emit(opBinary, c.name(op), 0)
+}
+
+func (c *compiler) expression(expr ast.Expression) {
+	switch e := expr.(type) {
+	case *ast.NumberLiteral:
+		c.emit(opConst, c.constant(runtime.NewNumber(e.Value)), 0)
+	case *ast.StringLiteral:
+		c.emit(opConst, c.constant(runtime.NewString(e.Value)), 0)
+	case *ast.BooleanLiteral:
+		c.emit(opConst, c.constant(runtime.NewBool(e.Value)), 0)
+	case *ast.NullLiteral:
+		c.emit(opConst, c.constant(runtime.Null), 0)
+	case *ast.UndefinedLiteral:
+		c.emit(opConst, c.constant(runtime.Undefined), 0)
+	case *ast.Identifier:
+		c.identifier(e)
+	case *ast.ThisExpression:
+		c.emit(opThis, 0, 0)
+	case *ast.ArrayLiteral:
+		for _, elem := range e.Elements {
+			if elem == nil {
+				c.failed = true
+				return
+			}
+			if _, ok := elem.(*ast.SpreadElement); ok {
+				c.failed = true
+				return
+			}
+			c.expression(elem)
+		}
+		c.emit(opArray, len(e.Elements), 0)
+	case *ast.ObjectLiteral:
+		c.emit(opObject, len(e.Properties), 0)
+		for _, prop := range e.Properties {
+			key, ok := literalKey(prop)
+			if !ok {
+				c.failed = true
+				return
+			}
+			if prop.Shorthand {
+				c.expression(prop.Key)
+			} else {
+				c.expression(prop.Value)
+				c.nameFunction(prop.Value, key)
+			}
+			c.emit(opDefine, c.name(key), 0)
+		}
+	case *ast.FunctionExpression:
+		c.closure(e, e.Scope)
+	case *ast.ArrowFunctionExpression:
+		// An arrow function shares the arguments and super of the function
+		// it is in, which compiled code does not bind.
+		if e.Scope == nil || slices.Contains(e.Scope.Free, "arguments") || slices.Contains(e.Scope.Free, "super") {
+			c.failed = true
+			return
+		}
+		c.closure(e, e.Scope)
+	case *ast.UnaryExpression:
+		c.unary(e)
+	case *ast.UpdateExpression:
+		c.update(e)
+	case *ast.BinaryExpression:
+		c.expression(e.Left)
+		c.expression(e.Right)
+		c.binary(e.Operator)
+	case *ast.LogicalExpression:
+		c.expression(e.Left)
+		var jump int
+		switch e.Operator {
+		case "&&":
+			jump = c.emit(opAnd, 0, 0)
+		case "||":
+			jump = c.emit(opOr, 0, 0)
+		default:
+			jump = c.emit(opNullish, 0, 0)
+		}
+		c.expression(e.Right)
+		c.patch(jump)
+	case *ast.ConditionalExpression:
+		c.expression(e.Test)
+		jump := c.emit(opJumpIfFalse, 0, 0)
+		c.expression(e.Consequent)
+		end := c.emit(opJump, 0, 0)
+		c.patch(jump)
+		c.expression(e.Alternate)
+		c.patch(end)
+	case *ast.SequenceExpression:
+		for i, sub := range e.Expressions {
+			if i > 0 {
+				c.emit(opPop, 0, 0)
+			}
+			c.expression(sub)
+		}
+	case *ast.AssignmentExpression:
+		c.assignment(e)
+	case *ast.MemberExpression:
+		if !c.memberBase(e) {
+			return
+		}
+		if e.Computed {
+			c.expression(e.Property)
+			c.emit(opToKey, 0, 0)
+			c.emit(opGetElem, 0, 0)
+			return
+		}
+		c.emit(opGetProp, c.name(e.Property.(*ast.Identifier).Value), 0)
+	case *ast.CallExpression:
+		c.call(e)
+	case *ast.NewExpression:
+		c.expression(e.Callee)
+		c.emit(opCheckConstructor, c.name(calleeName(e.Callee, "expression")), c.node(e))
+		if !c.arguments(e.Arguments) {
+			return
+		}
+		c.emit(opNew, len(e.Arguments), c.node(e))
+	default:
+		c.failed = true
+	}
+}
+
+// literalKey returns the key of a property of an object literal that
+// simply defines a data property.
+func literalKey(prop *ast.Property) (string, bool) {
+	if prop.Kind != "init" || prop.Computed || prop.Method {
+		return "", false
+	}
+	var key string
+	switch k := prop.Key.(type) {
+	case *ast.Identifier:
+		key = k.Value
+	case *ast.StringLiteral:
+		key = k.Value
+	default:
+		return "", false
+	}
+	// __proto__: value sets the prototype rather than a property.
+	return key, key != "__proto__"
+}
+
+// calleeName is the name an error about a callee that cannot be called
+// gives it.
+func calleeName(callee ast.Expression, fallback string) string {
+	switch c := callee.(type) {
+	case *ast.Identifier:
+		return c.Value
+	case *ast.MemberExpression:
+		if prop, ok := c.Property.(*ast.Identifier); ok && !c.Computed {
+			return prop.Value
+		}
+	}
+	return fallback
+}
+
+// closure compiles the creation of a nested function, which captures the
+// locals its scope refers to.
+func (c *compiler) closure(fn ast.Node, scope *ast.Scope) {
+	if scope == nil {
+		c.failed = true
+		return
+	}
+	for _, name := range scope.Free {
+		if l, ok := c.lookup(name); ok {
+			c.captures[l.slot] = true
+		}
+	}
+	c.code.env = true
+	c.emit(opClosure, c.node(fn), 0)
+}
+
+// nameFunction compiles naming the anonymous function that expr, whose
+// value is on top of the stack, evaluates to after the variable or
+// property it is assigned to.
+func (c *compiler) nameFunction(expr ast.Expression, name string) {
+	switch expr.(type) {
+	case *ast.FunctionExpression, *ast.ArrowFunctionExpression:
+		c.emit(opNameFunction, c.node(expr), c.name(name))
+	}
+}
+
+func (c *compiler) identifier(e *ast.Identifier) {
+	l, ok := c.lookup(e.Value)
+	switch {
+	case ok && l.captured:
+		c.emit(opLoadEnv, c.envRef(e.Value, l), 0)
+		return
+	case ok:
+		c.emit(opLoad, l.slot, 0)
+		return
+	}
+	if e.Value == "arguments" {
+		c.failed = true
+		return
+	}
+	c.emit(opLoadName, c.node(e), len(c.code.locals))
+	var locals []string
+	for _, scope := range c.scopes {
+		for name := range scope {
+			locals = append(locals, name)
+		}
+	}
+	c.code.locals = append(c.code.locals, locals)
+}
+
+// memberBase compiles the object of a member expression, reporting whether
+// the member is one the compiler supports.
+func (c *compiler) memberBase(e *ast.MemberExpression) bool {
+	if e.Optional || isSuperMember(e) {
+		c.failed = true
+		return false
+	}
+	if _, ok := e.Property.(*ast.Identifier); !ok && !e.Computed {
+		c.failed = true
+		return false
+	}
+	c.expression(e.Object)
+	return true
+}
+
+// memberReference compiles the object and key of an assignment target,
+// leaving them on the stack for opGetElem and opSetElem.
+func (c *compiler) memberReference(e *ast.MemberExpression) bool {
+	if !c.memberBase(e) {
+		return false
+	}
+	if e.Computed {
+		c.expression(e.Property)
+		c.emit(opToKey, 0, 0)
+	} else {
+		c.emit(opConst, c.constant(runtime.NewString(e.Property.(*ast.Identifier).Value)), 0)
+	}
+	return true
+}
+
+func (c *compiler) unary(e *ast.UnaryExpression) {
+	if e.Operator == "typeof" {
+		if ident, ok := e.Operand.(*ast.Identifier); ok {
+			if _, local := c.lookup(ident.Value); !local {
+				if ident.Value == "arguments" {
+					c.failed = true
+					return
+				}
+				c.emit(opTypeofName, c.node(ident), 0)
+				return
+			}
+		}
+		c.expression(e.Operand)
+		c.emit(opTypeof, 0, 0)
+		return
+	}
+	var op opcode
+	switch e.Operator {
+	case "-":
+		op = opNeg
+	case "+":
+		op = opToNumber
+	case "~":
+		op = opBitNot
+	case "!":
+		op = opNot
+	case "void":
+		op = opVoid
+	default:
+		c.failed = true
+		return
+	}
+	c.expression(e.Operand)
+	c.emit(op, 0, 0)
+}
+
+func (c *compiler) update(e *ast.UpdateExpression) {
+	delta := 1
+	if e.Operator == "--" {
+		delta = -1
+	}
+	prefix := 0
+	if e.Prefix {
+		prefix = 1
+	}
+	switch target := e.Operand.(type) {
+	case *ast.Identifier:
+		c.identifier(target)
+		c.emit(opToNumber, 0, 0)
+		if !e.Prefix {
+			c.emit(opDup, 0, 0)
+		}
+		c.emit(opIncrement, delta, 0)
+		c.storeIdentifier(target)
+		if !e.Prefix {
+			c.emit(opPop, 0, 0)
+		}
+	case *ast.MemberExpression:
+		if c.memberReference(target) {
+			c.emit(opUpdateElem, delta, prefix)
+		}
+	default:
+		c.failed = true
+	}
+}
+
+func (c *compiler) assignment(e *ast.AssignmentExpression) {
+	op := ""
+	switch e.Operator {
+	case "=":
+	case "&&=", "||=", "??=":
+		c.failed = true
+		return
+	default:
+		op = strings.TrimSuffix(e.Operator, "=")
+	}
+	switch target := e.Left.(type) {
+	case *ast.Identifier:
+		if op != "" {
+			c.identifier(target)
+		}
+		c.expression(e.Right)
+		if op != "" {
+			c.binary(op)
+		} else {
+			c.nameFunction(e.Right, target.Value)
+		}
+		c.storeIdentifier(target)
+	case *ast.MemberExpression:
+		if !c.memberReference(target) {
+			return
+		}
+		if op != "" {
+			c.emit(opDup2, 0, 0)
+			c.emit(opGetElem, 0, 0)
+		}
+		c.expression(e.Right)
+		if op != "" {
+			c.binary(op)
+		}
+		c.emit(opSetElem, 0, 0)
+	default:
+		c.failed = true
+	}
+}
+
+// storeIdentifier assigns the value on top of the stack to a variable,
+// leaving it there.
+func (c *compiler) storeIdentifier(e *ast.Identifier) {
+	l, ok := c.lookup(e.Value)
+	switch {
+	case ok && l.constant():
+		c.failed = true
+	case ok && l.captured:
+		c.emit(opStoreEnv, c.envRef(e.Value, l), 0)
+	case ok:
+		c.emit(opStore, l.slot, 0)
+	case e.Value == "arguments":
+		c.failed = true
+	default:
+		c.emit(opStoreName, c.node(e), 0)
+	}
+}
+
+// initIdentifier initializes a declared variable with the value popped
+// from the stack.
+func (c *compiler) initIdentifier(e *ast.Identifier, kind string) {
+	l, _ := c.lookup(e.Value)
+	if l.captured {
+		c.emit(opInitEnv, c.envRef(e.Value, l), 0)
+	} else {
+		c.emit(opInit, l.slot, 0)
+	}
+}
+
+// call compiles a call. The callee is checked to be callable before the
+// arguments are evaluated, and the call itself finds the callee, its this
+// value and the arguments on the stack.
+func (c *compiler) call(e *ast.CallExpression) {
+	if e.Optional {
+		c.failed = true
+		return
+	}
+	switch callee := e.Callee.(type) {
+	case *ast.MemberExpression:
+		if !c.memberReference(callee) {
+			return
+		}
+		c.emit(opGetMethod, c.node(e), 0)
+	case *ast.Identifier:
+		if callee.Value == "eval" {
+			c.failed = true
+			return
+		}
+		c.identifier(callee)
+		if _, local := c.lookup(callee.Value); local {
+			c.emit(opConst, c.constant(runtime.Undefined), 0)
+		} else {
+			c.emit(opWithThis, c.node(callee), 0)
+		}
+		c.emit(opCheckCallable, c.name(callee.Value), c.node(e))
+	case *ast.SuperExpression:
+		c.failed = true
+		return
+	default:
+		c.expression(callee)
+		c.emit(opConst, c.constant(runtime.Undefined), 0)
+		c.emit(opCheckCallable, c.name(""), c.node(e))
+	}
+	if !c.arguments(e.Arguments) {
+		return
+	}
+	c.emit(opCall, len(e.Arguments), c.node(e))
+}
+
+func (c *compiler) arguments(args []ast.Expression) bool {
+	for _, arg := range args {
+		if _, ok := arg.(*ast.SpreadElement); ok {
+			c.failed = true
+			return false
+		}
+		c.expression(arg)
+	}
+	return true
+}
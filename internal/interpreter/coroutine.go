@@ -0,0 +1,193 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// coroutine runs a function body on its own goroutine so that evaluation can
+// be suspended in the middle of an expression (at an await) and picked up
+// again later. Control is handed back and forth over unbuffered channels, so
+// exactly one side runs at any time and the interpreter never sees
+// concurrent access.
+type coroutine struct {
+	body      func() (*runtime.Value, error)
+	resumeCh  chan coResume
+	yieldCh   chan coYield
+	started   bool
+	done      bool
+	generator bool       // suspends at yield
+	async     bool       // suspends at await; set with generator for async generators
+	frame     *callFrame // innermost frame of the body while it is suspended
+}
+
+type resumeMode int
+
+const (
+	resumeNext resumeMode = iota
+	resumeThrow
+	resumeReturn
+)
+
+// coResume is sent into a suspended coroutine. The mode decides whether the
+// value is returned from the suspension point, thrown there, or returned
+// from the whole function (running any finally blocks on the way out).
+type coResume struct {
+	value *runtime.Value
+	mode  resumeMode
+}
+
+// completion turns a resumption into the result of the suspended expression.
+func (msg coResume) completion() (*runtime.Value, signal) {
+	switch msg.mode {
+	case resumeThrow:
+		return nil, signal{typ: sigThrow, value: msg.value}
+	case resumeReturn:
+		return nil, signal{typ: sigReturn, value: msg.value}
+	}
+	return msg.value, signal{}
+}
+
+// coYield is what the coroutine hands back to whoever resumed it: either a
+// suspension carrying a value, or its completion.
+type coYield struct {
+	value *runtime.Value
+	err   error
+	done  bool
+	await bool // suspended at an await rather than a yield
+	panic interface{}
+}
+
+func newCoroutine(body func() (*runtime.Value, error)) *coroutine {
+	return &coroutine{
+		body:     body,
+		resumeCh: make(chan coResume),
+		yieldCh:  make(chan coYield),
+	}
+}
+
+// resume runs the coroutine until it next suspends or completes. The first
+// call starts the body and ignores msg. A panic inside the body is re-raised
+// on the resuming goroutine.
+func (interp *Interpreter) resume(co *coroutine, msg coResume) coYield {
+	if co.done {
+		return coYield{value: runtime.Undefined, done: true}
+	}
+	prev, prevFrame := interp.co, interp.frame
+	interp.co = co
+	if co.frame != nil {
+		interp.frame = co.frame
+	}
+	if !co.started {
+		co.started = true
+		go co.run()
+	} else {
+		co.resumeCh <- msg
+	}
+	y := <-co.yieldCh
+	co.frame = interp.frame
+	interp.co, interp.frame = prev, prevFrame
+	if y.done {
+		co.done = true
+	}
+	if y.panic != nil {
+		panic(y.panic)
+	}
+	return y
+}
+
+func (co *coroutine) run() {
+	defer func() {
+		if r := recover(); r != nil {
+			co.yieldCh <- coYield{done: true, panic: r}
+		}
+	}()
+	val, err := co.body()
+	if val == nil {
+		val = runtime.Undefined
+	}
+	co.yieldCh <- coYield{value: val, err: err, done: true}
+}
+
+// suspend is called from inside the coroutine. It hands val to the resumer
+// and blocks until the coroutine is resumed again.
+func (co *coroutine) suspend(val *runtime.Value) coResume {
+	co.yieldCh <- coYield{value: val}
+	return <-co.resumeCh
+}
+
+// suspendAwait is suspend for an await: the resumer settles val and resumes
+// the coroutine with the outcome.
+func (co *coroutine) suspendAwait(val *runtime.Value) coResume {
+	co.yieldCh <- coYield{value: val, await: true}
+	return <-co.resumeCh
+}
+
+// leaveCoroutine detaches the current coroutine while an ordinary function
+// runs, so that an await or yield reached there cannot suspend the async
+// function or generator that called it. The returned function restores it.
+func (interp *Interpreter) leaveCoroutine() func() {
+	prev := interp.co
+	interp.co = nil
+	return func() { interp.co = prev }
+}
+
+// asyncFunction wraps the callable of an async function. Each call runs the
+// body as a coroutine and returns a promise that settles with its outcome.
+// The body runs synchronously up to the first await.
+func (interp *Interpreter) asyncFunction(body runtime.CallableFunc) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if interp.realm.NewPromise == nil {
+			return nil, fmt.Errorf("ReferenceError: Promise is not defined")
+		}
+		promise, resolve, reject := interp.realm.NewPromise()
+		co := newCoroutine(func() (*runtime.Value, error) {
+			return body(this, args)
+		})
+		co.async = true
+		interp.stepAsync(co, interp.resume(co, coResume{}), resolve, reject)
+		return promise, nil
+	}
+}
+
+// stepAsync settles the async function's promise once the coroutine
+// completes, or subscribes to the awaited value and resumes the coroutine
+// from a job when it settles.
+func (interp *Interpreter) stepAsync(co *coroutine, y coYield, resolve, reject func(*runtime.Value)) {
+	if y.done {
+		if y.err != nil {
+			reject(interp.errorFromGoError(y.err, interp.global))
+			return
+		}
+		resolve(y.value)
+		return
+	}
+	interp.realm.AwaitValue(y.value, func(val *runtime.Value) {
+		interp.stepAsync(co, interp.resume(co, coResume{value: val}), resolve, reject)
+	}, func(reason *runtime.Value) {
+		interp.stepAsync(co, interp.resume(co, coResume{value: reason, mode: resumeThrow}), resolve, reject)
+	})
+}
+
+func (interp *Interpreter) evalAwait(e *ast.AwaitExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	if co := interp.co; co == nil || !co.async {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", "await is only valid in async functions", env)}
+	}
+	val, sig := interp.evalExpression(e.Argument, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	return interp.await(val, env)
+}
+
+// await suspends the running async function or async generator until val
+// settles, returning its value or throwing its rejection reason.
+func (interp *Interpreter) await(val *runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	co := interp.co
+	if co == nil || !co.async {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", "await is only valid in async functions", env)}
+	}
+	return co.suspendAwait(val).completion()
+}
@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"context"
+	"time"
+)
+
+// RunLoop runs the event loop once a script has finished: it waits for
+// the timers the interpreter's scripts set with setTimeout and
+// setInterval and runs each one as it comes due, followed by the microtasks it queued, until no timers are
+// left. It returns ctx.Err() if ctx is done first, and the error of a
+// timer callback or microtask that throws, which ends the loop as an
+// uncaught exception ends a Node process. ctx may be nil.
+func (interp *Interpreter) RunLoop(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	defer interp.enterFrame(nil, anonymousFile)()
+	if err := interp.agent.Events.RunJobs(); err != nil {
+		return interp.uncaught(interp.errorFromGoError(err, interp.global))
+	}
+	for {
+		when, ok := interp.agent.Events.NextTimer()
+		if !ok {
+			return nil
+		}
+		if wait := time.Until(when); wait > 0 {
+			// Other goroutines may run scripts while the loop waits.
+			t := time.NewTimer(wait)
+			interp.agent.Unlocked(func() {
+				select {
+				case <-ctx.Done():
+				case <-t.C:
+				}
+			})
+			t.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := interp.agent.Events.RunTimer(); err != nil {
+			return interp.uncaught(interp.errorFromGoError(err, interp.global))
+		}
+	}
+}
+
+// EnqueueJob appends job to the microtask queue, behind the promise
+// reactions and queueMicrotask callbacks already waiting there. The queue
+// is drained when a script, module or timer callback finishes, or by
+// DrainJobs, so a host running its own event loop can interleave its work
+// with the script's. An error returned by job is reported as an uncaught
+// exception by whatever drains the queue.
+func (interp *Interpreter) EnqueueJob(job func() error) {
+	interp.agent.Events.EnqueueJob(job)
+}
+
+// DrainJobs runs the microtask queue until it is empty, including the jobs
+// queued meanwhile. A job that throws stops the drain, and DrainJobs
+// returns the exception as uncaught; the jobs after it stay queued.
+func (interp *Interpreter) DrainJobs() (err error) {
+	defer interp.enterFrame(nil, anonymousFile)()
+	interp.drainJobs(&err)
+	return err
+}
+
+// PendingJobs reports how many jobs are waiting on the microtask queue.
+func (interp *Interpreter) PendingJobs() int {
+	return interp.agent.Events.PendingJobs()
+}
+
+// drainJobs runs the microtask queue once a script or module has finished,
+// and sets *err to the exception of a job that throws, unless the script
+// itself threw.
+func (interp *Interpreter) drainJobs(err *error) {
+	if jobErr := interp.agent.Events.RunJobs(); jobErr != nil && *err == nil {
+		*err = interp.uncaught(interp.errorFromGoError(jobErr, interp.global))
+	}
+}
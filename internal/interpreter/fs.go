@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/parser"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// EvalFS reads the script at name from fsys, which may be an embed.FS, and
+// runs it as a global script. Parsed programs are cached by name for the
+// lifetime of the interpreter, so evaluating the same file again skips the
+// parse; the file system is expected not to change. If no module resolver
+// is set, EvalFS also installs FSResolver(fsys) so later EvalModule calls
+// load modules from the same file system.
+func (interp *Interpreter) EvalFS(fsys fs.FS, name string) (*runtime.Value, error) {
+	name = cleanFSPath(name)
+	program, ok := interp.programs[name]
+	if !ok {
+		source, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var errs []error
+		program, errs = parser.New(string(source)).ParseProgram()
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("%s: parse errors: %v", name, errs)
+		}
+		if interp.programs == nil {
+			interp.programs = make(map[string]*ast.Program)
+		}
+		interp.programs[name] = program
+	}
+	if interp.resolveModule == nil {
+		interp.resolveModule = FSResolver(fsys)
+	}
+	return interp.runScript(program, name)
+}
+
+// FSResolver returns a module resolver that loads modules from fsys.
+// Specifiers starting with "./" or "../" are resolved against the importing
+// module's directory, and "/"-rooted specifiers against the root of fsys.
+// The entry module passed to EvalModule is looked up from the root. Module
+// names are the cleaned paths within fsys.
+func FSResolver(fsys fs.FS) ModuleResolver {
+	return func(specifier, referrer string) (string, string, error) {
+		name := specifier
+		if referrer != "" {
+			switch {
+			case strings.HasPrefix(specifier, "./"), strings.HasPrefix(specifier, "../"):
+				name = path.Join(path.Dir(referrer), specifier)
+			case strings.HasPrefix(specifier, "/"):
+			default:
+				return "", "", fmt.Errorf("cannot find module '%s' imported from %s", specifier, referrer)
+			}
+		}
+		name = cleanFSPath(name)
+		source, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot find module '%s': %v", specifier, err)
+		}
+		return name, string(source), nil
+	}
+}
+
+// cleanFSPath turns a slash-separated path into the unrooted form fs.FS
+// expects.
+func cleanFSPath(name string) string {
+	return path.Clean(strings.TrimPrefix(path.Clean("/"+name), "/"))
+}
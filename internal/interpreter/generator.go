@@ -0,0 +1,478 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+type generatorState int
+
+const (
+	genSuspendedStart generatorState = iota
+	genSuspendedYield
+	genExecuting
+	genCompleted
+)
+
+// generator is the internal state of a generator object. Its body runs as a
+// coroutine that suspends at every yield.
+type generator struct {
+	co    *coroutine
+	state generatorState
+}
+
+func getGenerator(val *runtime.Value) *generator {
+	if val == nil || val.Type != runtime.TypeObject || val.Object == nil || val.Object.Internal == nil {
+		return nil
+	}
+	g, _ := val.Object.Internal["generator"].(*generator)
+	return g
+}
+
+// generatorFunction makes the callable of a generator function. A call runs
+// start, which binds the parameters and returns the body, but not the body
+// itself; it returns a generator object whose next, return and throw
+// methods drive it. fnObj supplies the prototype for the object.
+func (interp *Interpreter) generatorFunction(start func(this *runtime.Value, args []*runtime.Value) (func() (*runtime.Value, error), error), fnObj **runtime.Object) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		body, err := start(this, args)
+		if err != nil {
+			return nil, err
+		}
+		g := &generator{}
+		g.co = newCoroutine(body)
+		g.co.generator = true
+
+		proto := interp.generatorPrototype()
+		if p := (*fnObj).Get("prototype"); p.Type == runtime.TypeObject && p.Object != nil {
+			proto = p.Object
+		}
+		obj := runtime.NewOrdinaryObject(proto)
+		obj.OType = runtime.ObjTypeGenerator
+		obj.Internal = map[string]interface{}{"generator": g}
+		return runtime.NewObject(obj), nil
+	}
+}
+
+// resume runs the generator until its next yield or completion, reporting
+// the yielded or returned value and whether the generator is done.
+func (g *generator) resume(interp *Interpreter, msg coResume) (*runtime.Value, bool, error) {
+	switch g.state {
+	case genExecuting:
+		return nil, false, fmt.Errorf("TypeError: Generator is already running")
+	case genSuspendedStart:
+		if msg.mode != resumeNext {
+			g.state = genCompleted
+		}
+	}
+	if g.state == genCompleted {
+		switch msg.mode {
+		case resumeThrow:
+			return nil, false, runtime.Throw(msg.value)
+		case resumeReturn:
+			return msg.value, true, nil
+		}
+		return runtime.Undefined, true, nil
+	}
+
+	g.state = genExecuting
+	y := interp.resume(g.co, msg)
+	if y.done {
+		g.state = genCompleted
+		if y.err != nil {
+			return nil, false, y.err
+		}
+		return y.value, true, nil
+	}
+	g.state = genSuspendedYield
+	return y.value, false, nil
+}
+
+// generatorPrototype returns %GeneratorPrototype%, which holds next, return
+// and throw. Generator functions' prototype objects inherit from it.
+func (interp *Interpreter) generatorPrototype() *runtime.Object {
+	if interp.genProto != nil {
+		return interp.genProto
+	}
+	proto := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+	method := func(name string, mode resumeMode) {
+		fn := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			g := getGenerator(this)
+			if g == nil {
+				return nil, fmt.Errorf("TypeError: %s method called on incompatible receiver", name)
+			}
+			arg := runtime.Undefined
+			if len(args) > 0 {
+				arg = args[0]
+			}
+			val, done, err := g.resume(interp, coResume{value: arg, mode: mode})
+			if err != nil {
+				return nil, err
+			}
+			return interp.iterResult(val, done), nil
+		})
+		fn.DefineProperty("name", &runtime.Property{Value: runtime.NewString(name), Configurable: true})
+		fn.DefineProperty("length", &runtime.Property{Value: runtime.NewNumber(1), Configurable: true})
+		proto.DefineProperty(name, &runtime.Property{Value: runtime.NewObject(fn), Writable: true, Configurable: true})
+		if mode == resumeNext {
+			interp.genNext = fn
+		}
+	}
+	method("next", resumeNext)
+	method("return", resumeReturn)
+	method("throw", resumeThrow)
+	if runtime.SymbolIterator != nil {
+		self := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return this, nil
+		})
+		proto.DefineProperty(runtime.SymbolIterator.Key(), &runtime.Property{Value: runtime.NewObject(self), Writable: true, Configurable: true})
+	}
+	interp.genProto = proto
+	return proto
+}
+
+func (interp *Interpreter) iterResult(val *runtime.Value, done bool) *runtime.Value {
+	result := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+	result.Set("value", val)
+	result.Set("done", runtime.NewBool(done))
+	return runtime.NewObject(result)
+}
+
+func (interp *Interpreter) evalYield(e *ast.YieldExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	co := interp.co
+	if co == nil || !co.generator {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", "yield is only valid in generator functions", env)}
+	}
+	val := runtime.Undefined
+	if e.Argument != nil {
+		var sig signal
+		val, sig = interp.evalExpression(e.Argument, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+	}
+	if e.Delegate {
+		return interp.yieldDelegate(co, val, env)
+	}
+	if !co.async {
+		return co.suspend(val).completion()
+	}
+	// An async generator awaits the operand before yielding it, and the
+	// value of a return request before returning.
+	val, sig := interp.await(val, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	msg := co.suspend(val)
+	if msg.mode == resumeReturn {
+		val, sig := interp.await(msg.value, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		return nil, signal{typ: sigReturn, value: val}
+	}
+	return msg.completion()
+}
+
+// yieldDelegate implements yield*: every value of the inner iterator is
+// yielded in turn, and next, throw and return calls on the outer generator
+// are forwarded to it. The result is the inner iterator's return value.
+func (interp *Interpreter) yieldDelegate(co *coroutine, iterable *runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	getIterator := interp.getIterator
+	if co.async {
+		getIterator = interp.getAsyncIterator
+	}
+	it, sig := getIterator(iterable, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	msg := coResume{value: runtime.Undefined}
+	for {
+		val, done, sig := it.resume(msg, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if it.awaitValues {
+			if val, sig = interp.await(val, env); sig.typ != sigNone {
+				return nil, sig
+			}
+		}
+		if done {
+			if msg.mode == resumeReturn {
+				return nil, signal{typ: sigReturn, value: val}
+			}
+			return val, signal{}
+		}
+		msg = co.suspend(val)
+	}
+}
+
+// iterator steps through an iterable for for-of, spread, destructuring and
+// yield*. Arrays and strings that still use the built-in Symbol.iterator are
+// read directly, generators are resumed without allocating result objects,
+// and anything else goes through the iterator protocol.
+type iterator struct {
+	interp *Interpreter
+	direct bool // array, string or native iterator, read without protocol calls
+	array  *runtime.Object
+	chars  []string // a string's code points
+	index  int
+	gen    *generator
+	obj    *runtime.Value // protocol iterator object
+	next   runtime.CallableFunc
+	native func() (*runtime.Value, bool)
+
+	async       bool // obj is an async iterator: its results are awaited
+	awaitValues bool // a sync iterator driven by for await: values are awaited
+}
+
+func (interp *Interpreter) getIterator(val *runtime.Value, env *runtime.Environment) (*iterator, signal) {
+	it := &iterator{interp: interp}
+	if interp.realm.HasDefaultIterator(val) {
+		if val.Type == runtime.TypeString {
+			it.chars = runtime.CodePoints(val.Str)
+		} else {
+			it.array = val.Object
+		}
+		it.direct = true
+		return it, signal{}
+	}
+	if g := getGenerator(val); g != nil && runtime.SymbolIterator == nil {
+		it.gen = g
+		return it, signal{}
+	}
+
+	var method *runtime.Value
+	switch {
+	case runtime.SymbolIterator == nil:
+	case val.Type == runtime.TypeString:
+		if interp.realm.StringPrototype != nil {
+			method = interp.realm.StringPrototype.GetSymbol(runtime.SymbolIterator)
+		}
+	case val.Type == runtime.TypeObject && val.Object != nil:
+		method = val.Object.GetSymbol(runtime.SymbolIterator)
+	}
+	if method == nil || method.Type != runtime.TypeObject || method.Object == nil || method.Object.Callable == nil {
+		if val.Type == runtime.TypeObject && val.Object != nil {
+			if val.Object.IteratorNext != nil {
+				it.native = val.Object.IteratorNext
+				it.direct = true
+				return it, signal{}
+			}
+			return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "object is not iterable", env)}
+		}
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", val.ToString()+" is not iterable", env)}
+	}
+
+	iterVal, err := method.Object.Callable(val, nil)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	if iterVal == nil || iterVal.Type != runtime.TypeObject || iterVal.Object == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Result of the Symbol.iterator method is not an object", env)}
+	}
+	// A generator whose next method is still the built-in one is resumed
+	// directly instead of through next() and a result object.
+	if g := getGenerator(iterVal); g != nil && interp.genNext != nil {
+		if next := iterVal.Object.Get("next"); next.Type == runtime.TypeObject && next.Object == interp.genNext {
+			it.gen = g
+			return it, signal{}
+		}
+	}
+	it.obj = iterVal
+	if next := iterVal.Object.Get("next"); next != nil && next.Type == runtime.TypeObject && next.Object != nil {
+		it.next = next.Object.Callable
+	}
+	if it.next == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "iterator.next is not a function", env)}
+	}
+	return it, signal{}
+}
+
+// getAsyncIterator returns the iterator for a for await loop or a yield* in
+// an async generator: the object's Symbol.asyncIterator method if it has
+// one, and otherwise its sync iterator with each value awaited.
+func (interp *Interpreter) getAsyncIterator(val *runtime.Value, env *runtime.Environment) (*iterator, signal) {
+	method := runtime.Undefined
+	if runtime.SymbolAsyncIterator != nil && val.Type == runtime.TypeObject && val.Object != nil {
+		var err error
+		if method, err = val.Object.GetErr(runtime.SymbolAsyncIterator.Key()); err != nil {
+			return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+	}
+	if isNullish(method) {
+		it, sig := interp.getIterator(val, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		it.awaitValues = true
+		return it, signal{}
+	}
+	if method.Type != runtime.TypeObject || method.Object == nil || method.Object.Callable == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Symbol.asyncIterator is not a function", env)}
+	}
+	iterVal, err := method.Object.Callable(val, nil)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	if iterVal == nil || iterVal.Type != runtime.TypeObject || iterVal.Object == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Result of the Symbol.asyncIterator method is not an object", env)}
+	}
+	it := &iterator{interp: interp, obj: iterVal, async: true}
+	if next := iterVal.Object.Get("next"); next != nil && next.Type == runtime.TypeObject && next.Object != nil {
+		it.next = next.Object.Callable
+	}
+	if it.next == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "iterator.next is not a function", env)}
+	}
+	return it, signal{}
+}
+
+// appendIterated appends every value of a non-array iterable to dst, for
+// spread elements and arguments.
+func (interp *Interpreter) appendIterated(dst []*runtime.Value, val *runtime.Value, env *runtime.Environment) ([]*runtime.Value, signal) {
+	it, sig := interp.getIterator(val, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	for {
+		elem, done, sig := it.step(env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if done {
+			return dst, signal{}
+		}
+		dst = append(dst, elem)
+	}
+}
+
+// step returns the next value, or done once the iterable is exhausted.
+func (it *iterator) step(env *runtime.Environment) (*runtime.Value, bool, signal) {
+	return it.resume(coResume{value: runtime.Undefined}, env)
+}
+
+// resume advances the iterator the way a resumption of yield* would: next
+// passes the value in, throw and return are forwarded when the iterator
+// supports them.
+func (it *iterator) resume(msg coResume, env *runtime.Environment) (*runtime.Value, bool, signal) {
+	switch {
+	case it.direct:
+		switch msg.mode {
+		case resumeThrow:
+			return nil, false, signal{typ: sigThrow, value: msg.value}
+		case resumeReturn:
+			return msg.value, true, signal{}
+		}
+		return it.nextDirect()
+	case it.gen != nil:
+		val, done, err := it.gen.resume(it.interp, msg)
+		if err != nil {
+			return nil, false, signal{typ: sigThrow, value: it.interp.errorFromGoError(err, env)}
+		}
+		return val, done, signal{}
+	}
+
+	fn := it.next
+	switch msg.mode {
+	case resumeThrow:
+		fn = it.method("throw")
+		if fn == nil {
+			if sig := it.close(env); sig.typ != sigNone {
+				return nil, false, sig
+			}
+			return nil, false, signal{typ: sigThrow, value: it.interp.makeErrorObject("TypeError", "The iterator does not provide a 'throw' method", env)}
+		}
+	case resumeReturn:
+		fn = it.method("return")
+		if fn == nil {
+			return msg.value, true, signal{}
+		}
+	}
+	result, err := fn(it.obj, []*runtime.Value{msg.value})
+	if err != nil {
+		return nil, false, signal{typ: sigThrow, value: it.interp.errorFromGoError(err, env)}
+	}
+	if it.async {
+		var sig signal
+		if result, sig = it.interp.await(result, env); sig.typ != sigNone {
+			return nil, false, sig
+		}
+	}
+	if result == nil || result.Type != runtime.TypeObject || result.Object == nil {
+		return nil, false, signal{typ: sigThrow, value: it.interp.makeErrorObject("TypeError", "Iterator result is not an object", env)}
+	}
+	return result.Object.Get("value"), result.Object.Get("done").ToBoolean(), signal{}
+}
+
+func (it *iterator) nextDirect() (*runtime.Value, bool, signal) {
+	switch {
+	case it.array != nil:
+		// Arrays are read live, so elements pushed during iteration are seen.
+		if it.index >= it.array.ArrayLength() {
+			return runtime.Undefined, true, signal{}
+		}
+		val, ok := it.array.ArrayElement(it.index)
+		if !ok {
+			// A hole reads through the prototype chain.
+			if val = it.array.Get(strconv.Itoa(it.index)); val == nil {
+				val = runtime.Undefined
+			}
+		}
+		it.index++
+		return val, false, signal{}
+	case it.native != nil:
+		val, done := it.native()
+		if done {
+			return runtime.Undefined, true, signal{}
+		}
+		return val, false, signal{}
+	}
+	if it.index >= len(it.chars) {
+		return runtime.Undefined, true, signal{}
+	}
+	it.index++
+	return runtime.NewString(it.chars[it.index-1]), false, signal{}
+}
+
+func (it *iterator) method(name string) runtime.CallableFunc {
+	fn := it.obj.Object.Get(name)
+	if fn == nil || fn.Type != runtime.TypeObject || fn.Object == nil {
+		return nil
+	}
+	return fn.Object.Callable
+}
+
+// close implements IteratorClose for a loop that exits early: generators
+// run their finally blocks and protocol iterators get their return method
+// called.
+func (it *iterator) close(env *runtime.Environment) signal {
+	switch {
+	case it.gen != nil:
+		if _, _, err := it.gen.resume(it.interp, coResume{value: runtime.Undefined, mode: resumeReturn}); err != nil {
+			return signal{typ: sigThrow, value: it.interp.errorFromGoError(err, env)}
+		}
+	case it.obj != nil:
+		ret := it.method("return")
+		if ret == nil {
+			return signal{}
+		}
+		result, err := ret(it.obj, nil)
+		if err != nil {
+			return signal{typ: sigThrow, value: it.interp.errorFromGoError(err, env)}
+		}
+		if it.async {
+			var sig signal
+			if result, sig = it.interp.await(result, env); sig.typ != sigNone {
+				return sig
+			}
+		}
+		if result == nil || result.Type != runtime.TypeObject {
+			return signal{typ: sigThrow, value: it.interp.makeErrorObject("TypeError", "Iterator result is not an object", env)}
+		}
+	}
+	return signal{}
+}
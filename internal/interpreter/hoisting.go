@@ -1,8 +1,8 @@
 package interpreter
 
 import (
-	"github.com/example/jsgo/ast"
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
 )
 
 // hoistComprehensive performs comprehensive var and function hoisting.
@@ -28,25 +28,47 @@ func (interp *Interpreter) hoistComprehensiveImpl(stmts []ast.Statement, env *ru
 	// First pass: recursively hoist all var declarations to function scope.
 	interp.collectVarDecls(stmts, funcScope)
 
-	// Second pass: hoist function declarations at this level with their values.
+	// Create this level's let, const and class bindings uninitialized before
+	// any function is created, so closures capture them rather than a
+	// binding of the same name further out. The global scope is left alone:
+	// closures never capture from it and look its names up when they run.
+	if env.Outer() != nil {
+		interp.declareLexicalNames(stmts, env)
+	}
+
+	// Second pass: hoist function declarations at this level with their
+	// values. Their names are bound before any of them is created, so that
+	// the functions capture their own bindings and those of one another.
+	var funcDecls []*ast.FunctionDeclaration
 	for _, stmt := range stmts {
 		switch s := stmt.(type) {
 		case *ast.FunctionDeclaration:
-			fnVal := interp.createFunction(s.Name, s.Params, s.Defaults, s.Rest, s.Body, env, false)
-			env.Declare(s.Name.Value, "function", fnVal)
+			funcDecls = append(funcDecls, s)
 		case *ast.LabeledStatement:
 			if fd, ok := s.Body.(*ast.FunctionDeclaration); ok {
-				fnVal := interp.createFunction(fd.Name, fd.Params, fd.Defaults, fd.Rest, fd.Body, env, false)
-				env.Declare(fd.Name.Value, "function", fnVal)
+				funcDecls = append(funcDecls, fd)
 			}
 		}
 	}
+	for _, fd := range funcDecls {
+		if _, ok := env.GetBinding(fd.Name.Value); !ok {
+			env.Declare(fd.Name.Value, "function", runtime.Undefined)
+		}
+	}
+	for _, fd := range funcDecls {
+		fnVal := interp.createFunctionFromDecl(fd, env)
+		if b, ok := env.GetBinding(fd.Name.Value); ok && (b.Kind == "var" || b.Kind == "function") {
+			env.SetInCurrentScope(fd.Name.Value, fnVal)
+		} else {
+			env.Declare(fd.Name.Value, "function", fnVal)
+		}
+	}
 
 	// Annex B: if this is a function/program scope (not a block scope),
 	// also hoist function declarations found inside blocks to the function scope.
 	// Per spec, skip names that would conflict with lexical (let/const) declarations
 	// or parameter names (including "arguments").
-	if funcScope == env {
+	if funcScope == env && hasBlockFuncDecls(stmts, false) {
 		lexicalNames := interp.collectTopLevelLexicalNames(stmts)
 		if !isEval {
 			// Per spec B.3.3.1: skip names that are in parameterNames.
@@ -85,6 +107,25 @@ func (interp *Interpreter) collectTopLevelLexicalNames(stmts []ast.Statement) ma
 	return names
 }
 
+// declareLexicalNames creates uninitialized bindings in env for the let,
+// const and class declarations at the top level of stmts.
+func (interp *Interpreter) declareLexicalNames(stmts []ast.Statement, env *runtime.Environment) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.VariableDeclaration:
+			if s.Kind == "let" || s.Kind == "const" {
+				for _, decl := range s.Declarations {
+					for _, name := range interp.extractBindingNames(decl.Name) {
+						env.DeclareUninitialized(name, s.Kind)
+					}
+				}
+			}
+		case *ast.ClassDeclaration:
+			env.DeclareUninitialized(s.Name.Value, "let")
+		}
+	}
+}
+
 // collectVarDecls recursively walks all nested structures to find var declarations
 // and hoists them to the given function scope with undefined.
 func (interp *Interpreter) collectVarDecls(stmts []ast.Statement, funcScope *runtime.Environment) {
@@ -100,7 +141,7 @@ func (interp *Interpreter) collectVarDeclsFromStmt(stmt ast.Statement, funcScope
 			for _, decl := range s.Declarations {
 				names := interp.extractBindingNames(decl.Name)
 				for _, name := range names {
-					funcScope.SetInCurrentScope(name, runtime.Undefined)
+					hoistVar(funcScope, name)
 				}
 			}
 		}
@@ -127,7 +168,7 @@ func (interp *Interpreter) collectVarDeclsFromStmt(stmt ast.Statement, funcScope
 			for _, decl := range left.Declarations {
 				names := interp.extractBindingNames(decl.Name)
 				for _, name := range names {
-					funcScope.SetInCurrentScope(name, runtime.Undefined)
+					hoistVar(funcScope, name)
 				}
 			}
 		}
@@ -139,7 +180,7 @@ func (interp *Interpreter) collectVarDeclsFromStmt(stmt ast.Statement, funcScope
 			for _, decl := range left.Declarations {
 				names := interp.extractBindingNames(decl.Name)
 				for _, name := range names {
-					funcScope.SetInCurrentScope(name, runtime.Undefined)
+					hoistVar(funcScope, name)
 				}
 			}
 		}
@@ -150,6 +191,10 @@ func (interp *Interpreter) collectVarDeclsFromStmt(stmt ast.Statement, funcScope
 		if s.Body != nil {
 			interp.collectVarDeclsFromStmt(s.Body, funcScope)
 		}
+	case *ast.WithStatement:
+		if s.Body != nil {
+			interp.collectVarDeclsFromStmt(s.Body, funcScope)
+		}
 	case *ast.DoWhileStatement:
 		if s.Body != nil {
 			interp.collectVarDeclsFromStmt(s.Body, funcScope)
@@ -175,6 +220,18 @@ func (interp *Interpreter) collectVarDeclsFromStmt(stmt ast.Statement, funcScope
 	}
 }
 
+// hoistVar creates a var binding initialized to undefined. A var that names
+// an existing binding (a parameter, or a global from an earlier script) or a
+// property of the global object keeps its value.
+func hoistVar(funcScope *runtime.Environment, name string) {
+	if obj := funcScope.GlobalObject(); obj != nil && obj.HasOwnProperty(name) {
+		return
+	}
+	if !funcScope.HasBinding(name) {
+		funcScope.SetInCurrentScope(name, runtime.Undefined)
+	}
+}
+
 // collectBlockFuncDecls walks into blocks/if/switch/try to find function declarations
 // and hoists their NAMES to the function scope as var (initialized to undefined).
 // Per Annex B semantics, the actual function value is NOT assigned here;
@@ -336,6 +393,10 @@ func (interp *Interpreter) collectBlockFuncDeclsFromStmt(stmt ast.Statement, env
 		if s.Body != nil {
 			interp.collectBlockFuncDeclsFromStmt(s.Body, env, lexicalNames, isEval)
 		}
+	case *ast.WithStatement:
+		if s.Body != nil {
+			interp.collectBlockFuncDeclsFromStmt(s.Body, env, lexicalNames, isEval)
+		}
 	case *ast.DoWhileStatement:
 		if s.Body != nil {
 			interp.collectBlockFuncDeclsFromStmt(s.Body, env, lexicalNames, isEval)
@@ -344,3 +405,59 @@ func (interp *Interpreter) collectBlockFuncDeclsFromStmt(stmt ast.Statement, env
 		interp.collectBlockFuncDeclsFromStmt(s.Body, env, lexicalNames, isEval)
 	}
 }
+
+// hasBlockFuncDecls reports whether any function declaration is nested in a
+// block reachable by collectBlockFuncDecls. nested is true once the walk is
+// inside a block. It lets function entry skip building the Annex B lexical
+// name sets when there is nothing to hoist.
+func hasBlockFuncDecls(stmts []ast.Statement, nested bool) bool {
+	for _, stmt := range stmts {
+		if hasBlockFuncDeclsInStmt(stmt, nested) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBlockFuncDeclsInStmt(stmt ast.Statement, nested bool) bool {
+	switch s := stmt.(type) {
+	case *ast.FunctionDeclaration:
+		return nested
+	case *ast.BlockStatement:
+		return hasBlockFuncDecls(s.Statements, true)
+	case *ast.IfStatement:
+		if s.Consequence != nil && hasBlockFuncDecls(s.Consequence.Statements, true) {
+			return true
+		}
+		return s.Alternative != nil && hasBlockFuncDeclsInStmt(s.Alternative, true)
+	case *ast.SwitchStatement:
+		for _, c := range s.Cases {
+			if hasBlockFuncDecls(c.Consequent, true) {
+				return true
+			}
+		}
+	case *ast.TryStatement:
+		if s.Block != nil && hasBlockFuncDecls(s.Block.Statements, true) {
+			return true
+		}
+		if s.Handler != nil && s.Handler.Body != nil && hasBlockFuncDecls(s.Handler.Body.Statements, true) {
+			return true
+		}
+		return s.Finalizer != nil && hasBlockFuncDecls(s.Finalizer.Statements, true)
+	case *ast.ForStatement:
+		return s.Body != nil && hasBlockFuncDeclsInStmt(s.Body, nested)
+	case *ast.ForInStatement:
+		return s.Body != nil && hasBlockFuncDeclsInStmt(s.Body, nested)
+	case *ast.ForOfStatement:
+		return s.Body != nil && hasBlockFuncDeclsInStmt(s.Body, nested)
+	case *ast.WhileStatement:
+		return s.Body != nil && hasBlockFuncDeclsInStmt(s.Body, nested)
+	case *ast.WithStatement:
+		return s.Body != nil && hasBlockFuncDeclsInStmt(s.Body, nested)
+	case *ast.DoWhileStatement:
+		return s.Body != nil && hasBlockFuncDeclsInStmt(s.Body, nested)
+	case *ast.LabeledStatement:
+		return hasBlockFuncDeclsInStmt(s.Body, nested)
+	}
+	return false
+}
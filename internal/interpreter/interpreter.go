@@ -0,0 +1,3990 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/bind"
+	"github.com/example/jsgo/internal/parser"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// Signal types for control flow
+type signalType int
+
+const (
+	sigNone signalType = iota
+	sigReturn
+	sigBreak
+	sigContinue
+	sigThrow
+	// sigShortCircuit ends an optional chain whose base was nullish. Only
+	// links inside a ChainExpression produce it, and evalChain consumes it.
+	sigShortCircuit
+)
+
+// signal is a statement completion. It is returned by value and holds only
+// pointers and a string, so propagating it never allocates.
+type signal struct {
+	typ   signalType
+	value *runtime.Value
+	label string // for labeled break/continue
+}
+
+// jsError wraps a JS value as a Go error for try/catch. An exception that
+// escaped a script also records where it was thrown.
+type jsError struct {
+	value    *runtime.Value
+	location string // file:line:column, for uncaught exceptions
+}
+
+func (e *jsError) Error() string {
+	if e.location != "" {
+		return e.message() + " at " + e.location
+	}
+	return e.message()
+}
+
+func (e *jsError) message() string {
+	if e.value == nil {
+		return "undefined"
+	}
+	if e.value.Type == runtime.TypeObject && e.value.Object != nil {
+		name := e.value.Object.Get("name")
+		msg := e.value.Object.Get("message")
+		if name != nil && name.Type == runtime.TypeString && msg != nil && msg.Type == runtime.TypeString {
+			return name.Str + ": " + msg.Str
+		}
+		if msg != nil && msg.Type == runtime.TypeString {
+			return msg.Str
+		}
+		toStr := e.value.Object.Get("toString")
+		if toStr != nil && toStr.Type == runtime.TypeObject && toStr.Object != nil && toStr.Object.Callable != nil {
+			result, err := toStr.Object.Callable(e.value, nil)
+			if err == nil && result != nil {
+				return result.ToString()
+			}
+		}
+	}
+	return e.value.ToString()
+}
+
+// ThrownValue exposes the wrapped value so native code can recover it.
+func (e *jsError) ThrownValue() *runtime.Value {
+	return e.value
+}
+
+// makeErrorObject creates a proper JS Error object (TypeError, ReferenceError, etc.)
+// that works with instanceof. It looks up the constructor from the environment to get
+// the right prototype chain. Falls back to a simple object if the constructor isn't available.
+func (interp *Interpreter) makeErrorObject(errorType string, message string, env *runtime.Environment) *runtime.Value {
+	ctorVal, err := env.Get(errorType)
+	if err == nil && ctorVal.Type == runtime.TypeObject && ctorVal.Object != nil {
+		protoProp := ctorVal.Object.Get("prototype")
+		if protoProp.Type == runtime.TypeObject && protoProp.Object != nil {
+			obj := &runtime.Object{
+				OType:      runtime.ObjTypeError,
+				Properties: make(map[string]*runtime.Property),
+				Prototype:  protoProp.Object,
+			}
+			obj.Set("name", runtime.NewString(errorType))
+			obj.Set("message", runtime.NewString(message))
+			obj.Set("stack", runtime.NewString(interp.agent.ErrorStack(errorType+": "+message)))
+			return runtime.NewObject(obj)
+		}
+	}
+	// Fallback: create a simple error object without prototype chain
+	obj := runtime.NewErrorObject(nil, message)
+	obj.Set("name", runtime.NewString(errorType))
+	return runtime.NewObject(obj)
+}
+
+// errorFromGoError converts a Go error (from environment.Get/Set) into a proper JS Error object.
+// It parses the error type prefix (e.g. "ReferenceError: ...") and creates the right error type.
+func (interp *Interpreter) errorFromGoError(goErr error, env *runtime.Environment) *runtime.Value {
+	if val, ok := runtime.ThrownValue(goErr); ok {
+		return val
+	}
+	msg := goErr.Error()
+	errorTypes := []string{"TypeError", "ReferenceError", "SyntaxError", "RangeError", "URIError", "EvalError"}
+	for _, et := range errorTypes {
+		prefix := et + ": "
+		if strings.HasPrefix(msg, prefix) {
+			return interp.makeErrorObject(et, strings.TrimPrefix(msg, prefix), env)
+		}
+	}
+	return interp.makeErrorObject("Error", msg, env)
+}
+
+// Interpreter evaluates an AST using tree-walking.
+type Interpreter struct {
+	global        *runtime.Environment
+	natives       map[string]runtime.CallableFunc
+	nativeObjs    map[string]*nativeObject
+	globalObject  *runtime.Value
+	co            *coroutine // async function or generator body currently running, if any
+	genProto      *runtime.Object
+	genNext       *runtime.Object // the built-in generator next method
+	newTarget     *runtime.Value  // new.target for the constructor about to run, see construct
+	loopLabels    []string        // labels of the loop about to run, see execLabeled
+	frame         *callFrame      // innermost frame of the call stack
+	thrown        thrownAt        // where the exception being propagated was thrown
+	asyncGenProto *runtime.Object
+
+	resolveModule ModuleResolver
+	modules       map[string]*module         // loaded modules by canonical name
+	cjsModules    map[string]*runtime.Object // CommonJS module objects by absolute path
+	programs      map[string]*ast.Program    // scripts parsed by EvalFS, by path
+
+	tags    tagState         // tagged evaluations, see EvalTagged
+	limits  *limitState      // budget of the evaluation run by EvalWithOptions, if any
+	streams *runtime.Streams // standard streams of scripts, see SetStdout
+	agent   *runtime.Agent   // runs the scripts of the interpreter and of its other realms, see Agent
+	realm   *runtime.Realm   // intrinsics of the global scope, see Realm
+
+	mode     Mode                              // see SetMode
+	compiled map[*ast.BlockStatement]*bytecode // function bodies by the compiler, nil for those it does not support
+	maxDepth int                               // see SetMaxCallDepth
+
+	nativeDepth int // conversions run by native code, see enterNative
+}
+
+func New() *Interpreter {
+	return newInterpreter(runtime.NewAgent())
+}
+
+// newInterpreter returns an interpreter for a new realm of agent.
+func newInterpreter(agent *runtime.Agent) *Interpreter {
+	interp := &Interpreter{
+		global:       runtime.NewEnvironment(nil, false),
+		natives:      make(map[string]runtime.CallableFunc),
+		globalObject: runtime.NewObject(runtime.NewOrdinaryObject(nil)),
+		streams:      runtime.StandardStreams(),
+		agent:        agent,
+		realm:        runtime.NewRealm(agent),
+		maxDepth:     DefaultMaxCallDepth,
+	}
+	agent.SaveState(interp.saveState)
+	return interp
+}
+
+// Realm returns the realm of the interpreter's global scope, for the
+// builtins package to install the built-ins in. Until it does, the global
+// object has no prototype and scripts have no built-ins.
+func (interp *Interpreter) Realm() *runtime.Realm { return interp.realm }
+
+// Agent returns the agent that runs the interpreter's scripts, shared with
+// the interpreters of its other realms. A host that uses the interpreter
+// from several goroutines holds the agent's lock around every use.
+func (interp *Interpreter) Agent() *runtime.Agent { return interp.agent }
+
+// NewRealm returns an interpreter for a new realm: its own global scope,
+// global object and intrinsics, so that scripts run in it can neither see
+// the globals of interp's scripts nor change the built-in prototypes they
+// use. The caller installs the built-ins of the new realm, see Realm. The
+// new interpreter shares interp's natives, including those registered
+// later, which are declared in its global scope when it first runs, its
+// module resolver and its agent, with the event queue and the execution
+// lock, as realms of one agent do, and starts with interp's standard
+// streams. Objects passed between realms keep their own realm's
+// prototypes.
+func (interp *Interpreter) NewRealm() *Interpreter {
+	child := newInterpreter(interp.agent)
+	child.natives = interp.natives
+	child.resolveModule = interp.resolveModule
+	*child.streams = *interp.streams
+	child.maxDepth = interp.maxDepth
+	return child
+}
+
+// SetStdout makes console.log and the other builtins that write output
+// write to w instead of os.Stdout.
+func (interp *Interpreter) SetStdout(w io.Writer) { interp.streams.Stdout = w }
+
+// SetStderr makes console.error and console.warn write to w instead of
+// os.Stderr.
+func (interp *Interpreter) SetStderr(w io.Writer) { interp.streams.Stderr = w }
+
+// SetStdin makes builtins that read input read r instead of os.Stdin.
+func (interp *Interpreter) SetStdin(r io.Reader) { interp.streams.Stdin = r }
+
+// Stdout returns the writer scripts print to, for natives that print.
+func (interp *Interpreter) Stdout() io.Writer { return interp.streams.Stdout }
+
+// Stderr returns the writer scripts print errors to.
+func (interp *Interpreter) Stderr() io.Writer { return interp.streams.Stderr }
+
+// Stdin returns the reader scripts read input from.
+func (interp *Interpreter) Stdin() io.Reader { return interp.streams.Stdin }
+
+// GlobalObject returns the global this object.
+func (interp *Interpreter) GlobalObject() *runtime.Value {
+	return interp.globalObject
+}
+
+// RegisterNative registers a native Go function as a global JS function.
+func (interp *Interpreter) RegisterNative(name string, fn runtime.CallableFunc) {
+	interp.natives[name] = fn
+}
+
+// RegisterFunc registers a Go function of any signature as a global JS
+// function, converting its arguments and results by reflection as
+// runtime.GoConverter does. A non-nil error result throws an Error with its
+// message, or the error type its message starts with.
+func (interp *Interpreter) RegisterFunc(name string, fn interface{}) error {
+	callable, err := (&runtime.GoConverter{Realm: interp.realm}).WrapFunc(fn)
+	if err != nil {
+		return err
+	}
+	interp.RegisterNative(name, callable)
+	return nil
+}
+
+// SetGlobal binds the global variable name to x, converted by reflection
+// as runtime.GoConverter does, except that a struct or a pointer to one
+// becomes a live host object with its exported fields and methods, see
+// bind.Binder.
+func (interp *Interpreter) SetGlobal(name string, x interface{}) error {
+	val, err := (&bind.Binder{Conv: &runtime.GoConverter{Realm: interp.realm}}).ToValue(x)
+	if err != nil {
+		return err
+	}
+	if _, ok := interp.global.GetBinding(name); ok {
+		return interp.global.Set(name, val)
+	}
+	return interp.global.Declare(name, "var", val)
+}
+
+// NativeMethodOption configures one method of an object registered with
+// RegisterNativeObject. Methods without an option get length 0 and are
+// writable, configurable and non-enumerable, like built-in methods.
+type NativeMethodOption struct {
+	Method     string // name of the method the option applies to
+	Length     int    // value of the function's length property
+	ReadOnly   bool   // make the method non-writable and non-configurable
+	Enumerable bool   // list the method in for-in and Object.keys
+}
+
+type nativeObject struct {
+	methods map[string]runtime.CallableFunc
+	options map[string]NativeMethodOption
+}
+
+// RegisterNativeObject registers native Go functions as the methods of a
+// single global object, so a host API is reachable as host.readConfig()
+// rather than as separate globals. If a global object with that name
+// already exists the methods are added to it.
+func (interp *Interpreter) RegisterNativeObject(name string, methods map[string]runtime.CallableFunc, options ...NativeMethodOption) {
+	obj := &nativeObject{
+		methods: make(map[string]runtime.CallableFunc, len(methods)),
+		options: make(map[string]NativeMethodOption, len(options)),
+	}
+	for method, fn := range methods {
+		obj.methods[method] = fn
+	}
+	for _, opt := range options {
+		obj.options[opt.Method] = opt
+	}
+	if interp.nativeObjs == nil {
+		interp.nativeObjs = make(map[string]*nativeObject)
+	}
+	interp.nativeObjs[name] = obj
+}
+
+// installNativeObject defines the methods of a registered native object on
+// the global named name, creating the object if needed.
+func (interp *Interpreter) installNativeObject(name string, native *nativeObject, env *runtime.Environment) {
+	var obj *runtime.Object
+	if existing, err := env.Get(name); err == nil && existing.Type == runtime.TypeObject && existing.Object != nil {
+		obj = existing.Object
+	} else {
+		obj = runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+		env.Declare(name, "var", runtime.NewObject(obj))
+	}
+	for method, fn := range native.methods {
+		opt := native.options[method]
+		fnObj := runtime.NewFunctionObject(interp.realm.FunctionPrototype, fn)
+		fnObj.DefineProperty("name", &runtime.Property{Value: runtime.NewString(method), Configurable: true})
+		fnObj.DefineProperty("length", &runtime.Property{Value: runtime.NewNumber(float64(opt.Length)), Configurable: true})
+		obj.DefineProperty(method, &runtime.Property{
+			Value:        runtime.NewObject(fnObj),
+			Writable:     !opt.ReadOnly,
+			Enumerable:   opt.Enumerable,
+			Configurable: !opt.ReadOnly,
+		})
+	}
+}
+
+// GlobalEnv returns the interpreter's global environment for builtin registration.
+func (interp *Interpreter) GlobalEnv() *runtime.Environment {
+	return interp.global
+}
+
+// Eval parses and evaluates a JS source string as a global script. Every
+// script an interpreter runs shares its global scope: the var, let, const,
+// function and class declarations of one evaluation stay visible to the
+// next, as with the scripts of a web page or the lines of a REPL.
+func (interp *Interpreter) Eval(source string) (*runtime.Value, error) {
+	program, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	return interp.Run(program)
+}
+
+// Compile parses source into a program for Run, so that code evaluated
+// often is parsed once.
+func Compile(source string) (*ast.Program, error) {
+	program, errs := parser.New(source).ParseProgram()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("parse errors: %v", errs)
+	}
+	return program, nil
+}
+
+// EvalFile is Eval for source read from the file name, which locates the
+// errors the script throws.
+func (interp *Interpreter) EvalFile(name, source string) (*runtime.Value, error) {
+	program, errs := parser.New(source).ParseProgram()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: parse errors: %v", name, errs)
+	}
+	return interp.runScript(program, name)
+}
+
+// Run evaluates an already parsed program as a global script in the
+// interpreter's persistent global scope, see Eval. A program may be run any
+// number of times, on any interpreter.
+func (interp *Interpreter) Run(program *ast.Program) (*runtime.Value, error) {
+	return interp.runScript(program, anonymousFile)
+}
+
+// runScript runs program as a global script loaded from file, which names
+// it in error stacks.
+func (interp *Interpreter) runScript(program *ast.Program, file string) (_ *runtime.Value, err error) {
+	env := interp.prepareGlobalEnv()
+	defer interp.enterFrame(nil, file)()
+	// The global scope is strict only while a strict script runs in it.
+	defer env.SetStrict(env.IsStrict())
+	env.SetStrict(program.Strict)
+
+	// hoist var declarations and function declarations
+	interp.hoist(program.Statements, env)
+
+	// Microtasks queued by the script run once it has finished, whether it
+	// completed normally or threw.
+	defer interp.drainJobs(&err)
+
+	var result *runtime.Value
+	for _, stmt := range program.Statements {
+		val, sig := interp.execStatement(stmt, env)
+		if sig.typ == sigThrow {
+			return nil, interp.uncaught(sig.value)
+		}
+		if sig.typ == sigReturn {
+			return sig.value, nil
+		}
+		if val != nil {
+			result = val
+		}
+	}
+
+	if result == nil {
+		return runtime.Undefined, nil
+	}
+	return result, nil
+}
+
+// prepareGlobalEnv links the global environment to the global object and
+// installs the interpreter-provided globals (natives, eval and the Function
+// constructor) before a script or module runs.
+func (interp *Interpreter) prepareGlobalEnv() *runtime.Environment {
+	// Link the global env to the global object so builtins get mirrored
+	global := interp.globalObject.Object
+	interp.global.SetGlobalObject(global)
+	if global.Prototype == nil {
+		// Created before the builtins were registered.
+		global.Prototype = interp.realm.ObjectPrototype
+	}
+	if !global.HasOwnProperty("globalThis") {
+		global.DefineProperty("globalThis", &runtime.Property{
+			Value:        interp.globalObject,
+			Writable:     true,
+			Configurable: true,
+			HasValue:     true,
+		})
+	}
+
+	// Use the global env directly so var declarations and eval()-created
+	// bindings all live in the same scope (matching JS spec behavior for
+	// global script evaluation).
+	env := interp.global
+
+	// register natives (only if not already registered)
+	for name, fn := range interp.natives {
+		if _, err := env.Get(name); err != nil {
+			fnObj := runtime.NewFunctionObject(interp.realm.FunctionPrototype, fn)
+			env.Declare(name, "var", runtime.NewObject(fnObj))
+		}
+	}
+	// native objects are installed once; later runs keep any changes the
+	// scripts made to them
+	for name, native := range interp.nativeObjs {
+		interp.installNativeObject(name, native, env)
+		delete(interp.nativeObjs, name)
+	}
+
+	// register eval — tagged via Internal so evalCall can detect direct eval
+	// Always re-declare eval because the interpreter's version has scope access
+	// and the isBuiltinEval tag for direct eval detection
+	evalFnObj := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		// indirect eval: evaluate in global scope
+		if len(args) == 0 {
+			return runtime.Undefined, nil
+		}
+		if args[0].Type != runtime.TypeString {
+			return args[0], nil
+		}
+		result, sig := interp.evalCodeInEnv(args[0].Str, env)
+		if sig.typ == sigThrow {
+			return nil, &jsError{value: sig.value}
+		}
+		return result, nil
+	})
+	evalFnObj.Internal = map[string]interface{}{"isBuiltinEval": true}
+	evalFnObj.DefineProperty("name", &runtime.Property{
+		Value:        runtime.NewString("eval"),
+		Writable:     false,
+		Enumerable:   false,
+		Configurable: true,
+	})
+	evalFnObj.DefineProperty("length", &runtime.Property{
+		Value:        runtime.NewNumber(1),
+		Writable:     false,
+		Enumerable:   false,
+		Configurable: true,
+	})
+	env.Declare("eval", "var", runtime.NewObject(evalFnObj))
+
+	// Upgrade the builtins' Function constructor with actual implementation
+	// The builtins version has proper Function.prototype with call/apply/bind,
+	// but its Constructor is a stub. Replace just the Callable/Constructor.
+	if existing, _ := env.Get("Function"); existing != nil && existing != runtime.Undefined &&
+		existing.Type == runtime.TypeObject && existing.Object != nil {
+		implCtor := interp.makeFunctionConstructorImpl(env)
+		existing.Object.Callable = implCtor
+		existing.Object.Constructor = implCtor
+	} else {
+		funcCtor := interp.makeFunctionConstructor(env)
+		env.Declare("Function", "var", funcCtor)
+	}
+
+	return env
+}
+
+// EvalGlobalScript evaluates source code in the global environment directly.
+// This is used for $262.evalScript which should evaluate as a global Script,
+// not as eval code. Let/const declarations persist in the global env.
+func (interp *Interpreter) EvalGlobalScript(source string) (*runtime.Value, error) {
+	p := parser.New(source)
+	program, errs := p.ParseProgram()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("parse errors: %v", errs)
+	}
+
+	env := interp.global
+	defer interp.enterFrame(nil, anonymousFile)()
+	defer env.SetStrict(env.IsStrict())
+	env.SetStrict(program.Strict)
+	interp.hoist(program.Statements, env)
+
+	var result *runtime.Value
+	for _, stmt := range program.Statements {
+		val, sig := interp.execStatement(stmt, env)
+		if sig.typ == sigThrow {
+			return nil, interp.uncaught(sig.value)
+		}
+		if sig.typ == sigReturn {
+			return sig.value, nil
+		}
+		if val != nil {
+			result = val
+		}
+	}
+
+	if result == nil {
+		return runtime.Undefined, nil
+	}
+	return result, nil
+}
+
+// hoist performs var and function hoisting (delegates to hoistComprehensive in hoisting.go).
+func (interp *Interpreter) hoist(stmts []ast.Statement, env *runtime.Environment) {
+	interp.hoistComprehensive(stmts, env)
+}
+
+func (interp *Interpreter) extractBindingNames(node ast.Expression) []string {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		return []string{n.Value}
+	case *ast.ObjectPattern:
+		var names []string
+		for _, prop := range n.Properties {
+			if rest, ok := prop.Value.(*ast.RestElement); ok {
+				names = append(names, interp.extractBindingNames(rest.Argument)...)
+			} else if prop.Value != nil {
+				names = append(names, interp.extractBindingNames(prop.Value)...)
+			} else {
+				names = append(names, interp.extractBindingNames(prop.Key)...)
+			}
+		}
+		return names
+	case *ast.ArrayPattern:
+		var names []string
+		for _, elem := range n.Elements {
+			if elem != nil {
+				names = append(names, interp.extractBindingNames(elem)...)
+			}
+		}
+		return names
+	case *ast.AssignmentPattern:
+		return interp.extractBindingNames(n.Left)
+	case *ast.RestElement:
+		return interp.extractBindingNames(n.Argument)
+	}
+	return nil
+}
+
+// execStatement executes a statement, returning a value and a control flow signal.
+func (interp *Interpreter) execStatement(stmt ast.Statement, env *runtime.Environment) (*runtime.Value, signal) {
+	if sig := interp.checkpoint(); sig.typ != sigNone {
+		return nil, sig
+	}
+	interp.at(stmt)
+	switch s := stmt.(type) {
+	case *ast.ExpressionStatement:
+		val, sig := interp.evalExpression(s.Expression, env)
+		return val, sig
+	case *ast.VariableDeclaration:
+		return interp.execVarDecl(s, env)
+	case *ast.BlockStatement:
+		return interp.execBlock(s, env)
+	case *ast.ReturnStatement:
+		return interp.execReturn(s, env)
+	case *ast.IfStatement:
+		return interp.execIf(s, env)
+	case *ast.WhileStatement:
+		return interp.execWhile(s, env)
+	case *ast.DoWhileStatement:
+		return interp.execDoWhile(s, env)
+	case *ast.ForStatement:
+		return interp.execFor(s, env)
+	case *ast.ForInStatement:
+		return interp.execForIn(s, env)
+	case *ast.ForOfStatement:
+		return interp.execForOf(s, env)
+	case *ast.BreakStatement:
+		label := ""
+		if s.Label != nil {
+			label = s.Label.Value
+		}
+		return nil, signal{typ: sigBreak, label: label}
+	case *ast.ContinueStatement:
+		label := ""
+		if s.Label != nil {
+			label = s.Label.Value
+		}
+		return nil, signal{typ: sigContinue, label: label}
+	case *ast.SwitchStatement:
+		return interp.execSwitch(s, env)
+	case *ast.ThrowStatement:
+		return interp.execThrow(s, env)
+	case *ast.TryStatement:
+		return interp.execTry(s, env)
+	case *ast.FunctionDeclaration:
+		// In function/program scope: fully hoisted already, nothing to do.
+		// In block scope (Annex B): the name was hoisted to the block scope,
+		// but we also need to propagate the value to the enclosing function scope.
+		// Only propagate if:
+		// 1. The function scope has a var binding for this name
+		// 2. The name was actually Annex B hoisted (not skipped due to conflicts)
+		// 3. No enclosing block scope has a lexical binding for the same name
+		if env.IsBlock() {
+			fnVal := interp.createFunctionFromDecl(s, env)
+			env.SetInCurrentScope(s.Name.Value, fnVal)
+			funcScope := env.GetFunctionScope()
+			if funcScope != env && funcScope.IsAnnexBHoisted(s.Name.Value) {
+				if !env.HasLexicalInEnclosingBlocks(s.Name.Value, funcScope) {
+					funcScope.SetInCurrentScope(s.Name.Value, fnVal)
+				}
+			}
+		}
+		return nil, signal{}
+	case *ast.ClassDeclaration:
+		return interp.execClassDecl(s, env)
+	case *ast.LabeledStatement:
+		return interp.execLabeled(s, env)
+	case *ast.EmptyStatement:
+		return nil, signal{}
+	case *ast.DebuggerStatement:
+		return nil, signal{}
+	case *ast.WithStatement:
+		return interp.execWith(s, env)
+	default:
+		return nil, signal{typ: sigThrow, value: runtime.NewString(fmt.Sprintf("unsupported statement: %T", stmt))}
+	}
+}
+
+func (interp *Interpreter) execVarDecl(s *ast.VariableDeclaration, env *runtime.Environment) (*runtime.Value, signal) {
+	for _, decl := range s.Declarations {
+		if decl.Value == nil && s.Kind == "var" {
+			// var with no initializer: already hoisted, don't overwrite
+			continue
+		}
+		var val *runtime.Value
+		if decl.Value != nil {
+			var sig signal
+			val, sig = interp.evalExpression(decl.Value, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if ident, ok := decl.Name.(*ast.Identifier); ok {
+				name := ident.Value
+				if name == defaultExportName {
+					name = "default"
+				}
+				nameFunction(decl.Value, val, name)
+			}
+		} else {
+			val = runtime.Undefined
+		}
+
+		sig := interp.bindPattern(decl.Name, val, s.Kind, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+	}
+	return nil, signal{}
+}
+
+func (interp *Interpreter) bindPattern(pattern ast.Expression, val *runtime.Value, kind string, env *runtime.Environment) signal {
+	switch p := pattern.(type) {
+	case *ast.Identifier:
+		if kind == "var" {
+			if obj := env.WithBase(p.Value); obj != nil {
+				if err := obj.SetErr(p.Value, val); err != nil {
+					return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+				}
+				return signal{}
+			}
+			funcScope := env.GetFunctionScope()
+			funcScope.SetInCurrentScope(p.Value, val)
+		} else {
+			if err := env.Declare(p.Value, kind, val); err != nil {
+				return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+		}
+	case *ast.ObjectPattern:
+		if val == nil || val.Type == runtime.TypeUndefined || val.Type == runtime.TypeNull {
+			return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Cannot destructure "+val.ToString(), env)}
+		}
+		if val.Type != runtime.TypeObject || val.Object == nil {
+			return signal{}
+		}
+		used := make(map[string]bool)
+		for _, prop := range p.Properties {
+			if rest, ok := prop.Value.(*ast.RestElement); ok {
+				restObj := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+				if err := interp.copyDataProperties(restObj, val.Object, used); err != nil {
+					return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+				}
+				return interp.bindPattern(rest.Argument, runtime.NewObject(restObj), kind, env)
+			}
+			key, sig := interp.getPropertyKey(prop.Key, prop.Computed, env)
+			if sig.typ != sigNone {
+				return sig
+			}
+			used[key] = true
+			propVal := val.Object.Get(key)
+			target := prop.Value
+			if target == nil {
+				target = prop.Key
+			}
+			if ap, ok := target.(*ast.AssignmentPattern); ok {
+				if propVal == nil || propVal.Type == runtime.TypeUndefined {
+					var sig signal
+					propVal, sig = interp.evalExpression(ap.Right, env)
+					if sig.typ != sigNone {
+						return sig
+					}
+				}
+				target = ap.Left
+			}
+			if sig := interp.bindPattern(target, propVal, kind, env); sig.typ != sigNone {
+				return sig
+			}
+		}
+	case *ast.ArrayPattern:
+		elements, sig := interp.patternElements(p, val, env)
+		if sig.typ != sigNone {
+			return sig
+		}
+		for i, elem := range p.Elements {
+			if elem == nil {
+				continue
+			}
+			if rest, ok := elem.(*ast.RestElement); ok {
+				var restElems []*runtime.Value
+				if i < len(elements) {
+					restElems = elements[i:]
+				}
+				restArr := runtime.NewArrayObject(interp.realm.ArrayPrototype, restElems)
+				return interp.bindPattern(rest.Argument, runtime.NewObject(restArr), kind, env)
+			}
+			var elemVal *runtime.Value
+			if i < len(elements) {
+				elemVal = elements[i]
+			} else {
+				elemVal = runtime.Undefined
+			}
+			if ap, ok := elem.(*ast.AssignmentPattern); ok {
+				if elemVal == nil || elemVal.Type == runtime.TypeUndefined {
+					var sig signal
+					elemVal, sig = interp.evalExpression(ap.Right, env)
+					if sig.typ != sigNone {
+						return sig
+					}
+				}
+				sig := interp.bindPattern(ap.Left, elemVal, kind, env)
+				if sig.typ != sigNone {
+					return sig
+				}
+				continue
+			}
+			sig := interp.bindPattern(elem, elemVal, kind, env)
+			if sig.typ != sigNone {
+				return sig
+			}
+		}
+	case *ast.AssignmentPattern:
+		if val == nil || val.Type == runtime.TypeUndefined {
+			var sig signal
+			val, sig = interp.evalExpression(p.Right, env)
+			if sig.typ != sigNone {
+				return sig
+			}
+		}
+		return interp.bindPattern(p.Left, val, kind, env)
+	}
+	return signal{}
+}
+
+func (interp *Interpreter) execBlock(s *ast.BlockStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	// A block without lexical declarations cannot observe its own scope, and
+	// its vars were hoisted with the enclosing function, so run it in env.
+	// This saves an environment per loop iteration in the common case.
+	blockEnv := env
+	if needsBlockScope(s.Statements) {
+		blockEnv = runtime.NewEnvironment(env, true)
+		interp.hoist(s.Statements, blockEnv)
+	}
+	var result *runtime.Value
+	for _, stmt := range s.Statements {
+		val, sig := interp.execStatement(stmt, blockEnv)
+		if sig.typ != sigNone {
+			return val, sig
+		}
+		if val != nil {
+			result = val
+		}
+	}
+	return result, signal{}
+}
+
+// needsBlockScope reports whether stmts declare anything scoped to their
+// block: let/const, classes, or function declarations.
+func needsBlockScope(stmts []ast.Statement) bool {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.VariableDeclaration:
+			if s.Kind != "var" {
+				return true
+			}
+		case *ast.FunctionDeclaration, *ast.ClassDeclaration:
+			return true
+		case *ast.LabeledStatement:
+			if _, ok := s.Body.(*ast.FunctionDeclaration); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (interp *Interpreter) execReturn(s *ast.ReturnStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	if s.Value == nil {
+		return nil, signal{typ: sigReturn, value: runtime.Undefined}
+	}
+	val, sig := interp.evalExpression(s.Value, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	return nil, signal{typ: sigReturn, value: val}
+}
+
+func (interp *Interpreter) execIf(s *ast.IfStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	cond, sig := interp.evalExpression(s.Condition, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	if cond.ToBoolean() {
+		return interp.execStatement(s.Consequence, env)
+	}
+	if s.Alternative != nil {
+		return interp.execStatement(s.Alternative, env)
+	}
+	return nil, signal{}
+}
+
+func (interp *Interpreter) execWhile(s *ast.WhileStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	labels := interp.takeLoopLabels()
+	var result *runtime.Value
+	for {
+		cond, sig := interp.evalExpression(s.Condition, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if !cond.ToBoolean() {
+			break
+		}
+		val, sig := interp.execStatement(s.Body, env)
+		if sig.typ == sigBreak {
+			if sig.label != "" {
+				return val, sig // propagate labeled break
+			}
+			break
+		}
+		if sig.typ == sigContinue {
+			if !continuesLoop(sig, labels) {
+				return val, sig
+			}
+			continue
+		}
+		if sig.typ != sigNone {
+			return val, sig
+		}
+		if val != nil {
+			result = val
+		}
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) execWith(s *ast.WithStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	val, sig := interp.evalExpression(s.Object, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	var obj *runtime.Object
+	switch val.Type {
+	case runtime.TypeUndefined, runtime.TypeNull:
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Cannot convert undefined or null to object", env)}
+	case runtime.TypeObject:
+		obj = val.Object
+	default:
+		obj = interp.primitiveWrapper(val)
+	}
+	return interp.execStatement(s.Body, runtime.NewObjectEnvironment(obj, env))
+}
+
+// primitiveWrapper returns an object that resolves properties the way the
+// primitive v does, for use as a with statement's binding object.
+func (interp *Interpreter) primitiveWrapper(v *runtime.Value) *runtime.Object {
+	var proto *runtime.Object
+	switch v.Type {
+	case runtime.TypeString:
+		return interp.realm.NewStringObject(v.Str)
+	case runtime.TypeNumber:
+		proto = interp.realm.NumberPrototype
+	case runtime.TypeBoolean:
+		proto = interp.realm.BooleanPrototype
+	default:
+		proto = interp.realm.ObjectPrototype
+	}
+	return runtime.NewOrdinaryObject(proto)
+}
+
+func (interp *Interpreter) execDoWhile(s *ast.DoWhileStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	labels := interp.takeLoopLabels()
+	var result *runtime.Value
+	for {
+		val, sig := interp.execStatement(s.Body, env)
+		if sig.typ == sigBreak {
+			if sig.label != "" {
+				return val, sig
+			}
+			break
+		}
+		if sig.typ == sigContinue {
+			if !continuesLoop(sig, labels) {
+				return val, sig
+			}
+			// continue goes to condition check
+		} else if sig.typ != sigNone {
+			return val, sig
+		}
+		if val != nil {
+			result = val
+		}
+		cond, sig := interp.evalExpression(s.Condition, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if !cond.ToBoolean() {
+			break
+		}
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) execFor(s *ast.ForStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	labels := interp.takeLoopLabels()
+	forEnv := runtime.NewEnvironment(env, true)
+
+	if s.Init != nil {
+		switch init := s.Init.(type) {
+		case ast.Statement:
+			_, sig := interp.execStatement(init, forEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+		case ast.Expression:
+			_, sig := interp.evalExpression(init, forEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+		}
+	}
+
+	var result *runtime.Value
+	for {
+		if s.Test != nil {
+			cond, sig := interp.evalExpression(s.Test, forEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if !cond.ToBoolean() {
+				break
+			}
+		}
+
+		val, sig := interp.execStatement(s.Body, forEnv)
+		if sig.typ == sigBreak {
+			if sig.label != "" {
+				return val, sig
+			}
+			break
+		}
+		if sig.typ == sigContinue {
+			if !continuesLoop(sig, labels) {
+				return val, sig
+			}
+			// fall through to update
+		} else if sig.typ != sigNone {
+			return val, sig
+		}
+		if val != nil {
+			result = val
+		}
+
+		if s.Update != nil {
+			_, sig := interp.evalExpression(s.Update, forEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+		}
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) execForIn(s *ast.ForInStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	labels := interp.takeLoopLabels()
+	rightVal, sig := interp.evalExpression(s.Right, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	if rightVal.Type != runtime.TypeObject || rightVal.Object == nil {
+		return nil, signal{}
+	}
+
+	keys, err := interp.getEnumerableKeys(rightVal.Object)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+
+	var result *runtime.Value
+	for _, key := range keys {
+		loopEnv := runtime.NewEnvironment(env, true)
+		if sig := interp.assignLoopVar(s.Left, runtime.NewString(key), loopEnv); sig.typ != sigNone {
+			return nil, sig
+		}
+
+		val, sig := interp.execStatement(s.Body, loopEnv)
+		if sig.typ == sigBreak {
+			if sig.label != "" {
+				return val, sig
+			}
+			break
+		}
+		if sig.typ == sigContinue {
+			if !continuesLoop(sig, labels) {
+				return val, sig
+			}
+			continue
+		}
+		if sig.typ != sigNone {
+			return val, sig
+		}
+		if val != nil {
+			result = val
+		}
+	}
+	return result, signal{}
+}
+
+// getEnumerableKeys lists the keys visited by for-in: the enumerable string
+// keys of obj and then of each prototype, every key once. A non-enumerable
+// property still hides an enumerable one further up the chain. A proxy
+// lists its keys and their descriptors through its traps.
+func (interp *Interpreter) getEnumerableKeys(obj *runtime.Object) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for o := obj; o != nil; {
+		if o.OType == runtime.ObjTypeProxy {
+			own, err := runtime.OwnKeysErr(o)
+			if err != nil {
+				return nil, err
+			}
+			for _, k := range own {
+				if runtime.IsSymbolKey(k) || seen[k] {
+					continue
+				}
+				prop, err := interp.realm.GetOwnProperty(o, k)
+				if err != nil {
+					return nil, err
+				}
+				if prop == nil {
+					continue
+				}
+				seen[k] = true
+				if prop.Enumerable {
+					keys = append(keys, k)
+				}
+			}
+		} else {
+			for k, prop := range runtime.OwnPropertyIterator(o, runtime.StringKeys) {
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				if prop.Enumerable {
+					keys = append(keys, k)
+				}
+			}
+		}
+		var err error
+		if o, err = o.GetPrototypeOfErr(); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// copyDataProperties copies the enumerable own properties of src, symbols
+// included, onto target as data properties, skipping the keys in excluded.
+// Object spread and object rest patterns share it. A proxy source lists its
+// keys and their descriptors through its traps and its values through its
+// get trap.
+func (interp *Interpreter) copyDataProperties(target, src *runtime.Object, excluded map[string]bool) error {
+	define := func(k string, v *runtime.Value) {
+		target.DefineProperty(k, &runtime.Property{
+			Value:        v,
+			Writable:     true,
+			Enumerable:   true,
+			Configurable: true,
+			HasValue:     true,
+		})
+	}
+	if src.OType != runtime.ObjTypeProxy {
+		for k, prop := range runtime.OwnPropertyIterator(src, runtime.EnumerableKeys) {
+			if !excluded[k] {
+				define(k, prop.GetValue(src))
+			}
+		}
+		return nil
+	}
+	keys, err := runtime.OwnKeysErr(src)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if excluded[k] {
+			continue
+		}
+		prop, err := interp.realm.GetOwnProperty(src, k)
+		if err != nil {
+			return err
+		}
+		if prop == nil || !prop.Enumerable {
+			continue
+		}
+		v, err := src.GetErr(k)
+		if err != nil {
+			return err
+		}
+		define(k, v)
+	}
+	return nil
+}
+
+func (interp *Interpreter) execForOf(s *ast.ForOfStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	labels := interp.takeLoopLabels()
+	rightVal, sig := interp.evalExpression(s.Right, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	getIterator := interp.getIterator
+	if s.Await {
+		if interp.co == nil || !interp.co.async {
+			return nil, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", "for await is only valid in async functions", env)}
+		}
+		getIterator = interp.getAsyncIterator
+	}
+	it, sig := getIterator(rightVal, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	// Values are pulled one at a time, so infinite iterators work with break.
+	// Leaving the loop early closes the iterator; a throw from the body takes
+	// precedence over any error raised while closing.
+	exit := func(val *runtime.Value, sig signal) (*runtime.Value, signal) {
+		closeSig := it.close(env)
+		if sig.typ != sigThrow && closeSig.typ != sigNone {
+			return nil, closeSig
+		}
+		return val, sig
+	}
+
+	var result *runtime.Value
+	for {
+		elem, done, sig := it.step(env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if done {
+			break
+		}
+		if it.awaitValues {
+			if elem, sig = interp.await(elem, env); sig.typ != sigNone {
+				return nil, sig
+			}
+		}
+		loopEnv := runtime.NewEnvironment(env, true)
+		if sig := interp.assignLoopVar(s.Left, elem, loopEnv); sig.typ != sigNone {
+			return exit(nil, sig)
+		}
+
+		val, sig := interp.execStatement(s.Body, loopEnv)
+		if sig.typ == sigBreak {
+			if sig.label != "" {
+				return exit(val, sig)
+			}
+			if val, sig = exit(val, signal{}); sig.typ != sigNone {
+				return val, sig
+			}
+			if val != nil {
+				result = val
+			}
+			break
+		}
+		if sig.typ == sigContinue {
+			if !continuesLoop(sig, labels) {
+				return exit(val, sig)
+			}
+			continue
+		}
+		if sig.typ != sigNone {
+			return exit(val, sig)
+		}
+		if val != nil {
+			result = val
+		}
+	}
+	return result, signal{}
+}
+
+// assignLoopVar binds the value of one for-in/for-of iteration. Declarations
+// bind into the fresh iteration scope; any other head is an ordinary
+// assignment target (identifier, member expression or destructuring pattern)
+// resolved through env, so it updates the existing binding or object.
+func (interp *Interpreter) assignLoopVar(left ast.Node, val *runtime.Value, env *runtime.Environment) signal {
+	switch l := left.(type) {
+	case *ast.VariableDeclaration:
+		if len(l.Declarations) > 0 {
+			return interp.bindPattern(l.Declarations[0].Name, val, l.Kind, env)
+		}
+	case ast.Expression:
+		return interp.assignToExpression(l, val, env)
+	}
+	return signal{}
+}
+
+func (interp *Interpreter) execSwitch(s *ast.SwitchStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	disc, sig := interp.evalExpression(s.Discriminant, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	switchEnv := runtime.NewEnvironment(env, true)
+
+	// Hoist function declarations from all cases into the switch block scope
+	var allCaseStmts []ast.Statement
+	for _, c := range s.Cases {
+		allCaseStmts = append(allCaseStmts, c.Consequent...)
+	}
+	interp.hoist(allCaseStmts, switchEnv)
+
+	matched := false
+	defaultIdx := -1
+
+	for i, c := range s.Cases {
+		if c.Test == nil {
+			defaultIdx = i
+			continue
+		}
+		testVal, sig := interp.evalExpression(c.Test, switchEnv)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if runtime.StrictEquals(disc, testVal) {
+			matched = true
+		}
+		if matched {
+			for _, stmt := range c.Consequent {
+				val, sig := interp.execStatement(stmt, switchEnv)
+				if sig.typ == sigBreak {
+					return val, signal{}
+				}
+				if sig.typ != sigNone {
+					return val, sig
+				}
+			}
+		}
+	}
+
+	if !matched && defaultIdx >= 0 {
+		for i := defaultIdx; i < len(s.Cases); i++ {
+			for _, stmt := range s.Cases[i].Consequent {
+				val, sig := interp.execStatement(stmt, switchEnv)
+				if sig.typ == sigBreak {
+					return val, signal{}
+				}
+				if sig.typ != sigNone {
+					return val, sig
+				}
+			}
+		}
+	}
+
+	return nil, signal{}
+}
+
+func (interp *Interpreter) execThrow(s *ast.ThrowStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	val, sig := interp.evalExpression(s.Argument, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	// A rethrown value is located at its new throw statement.
+	interp.at(s)
+	interp.thrown = thrownAt{}
+	interp.noteThrow(val)
+	return nil, signal{typ: sigThrow, value: val}
+}
+
+func (interp *Interpreter) execTry(s *ast.TryStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	val, sig := interp.execBlock(s.Block, env)
+	if sig.typ == sigThrow {
+		interp.noteThrow(sig.value)
+	}
+
+	if sig.typ == sigThrow && catchable(sig.value) && s.Handler != nil {
+		interp.thrown = thrownAt{}
+		catchEnv := runtime.NewEnvironment(env, true)
+		if s.Handler.Param != nil {
+			// Simple identifier catch params use "catch" kind to allow Annex B
+			// function hoisting through them (per B.3.5). Destructuring params
+			// use "let" kind which blocks Annex B hoisting.
+			catchKind := "let"
+			if _, isIdent := s.Handler.Param.(*ast.Identifier); isIdent {
+				catchKind = "catch"
+			}
+			interp.bindPattern(s.Handler.Param, sig.value, catchKind, catchEnv)
+		}
+		interp.hoist(s.Handler.Body.Statements, catchEnv)
+		var catchResult *runtime.Value
+		for _, stmt := range s.Handler.Body.Statements {
+			r, csig := interp.execStatement(stmt, catchEnv)
+			if csig.typ != sigNone {
+				val = r
+				sig = csig
+				goto finalizer
+			}
+			if r != nil {
+				catchResult = r
+			}
+		}
+		val = catchResult
+		sig = signal{}
+	}
+
+finalizer:
+	if s.Finalizer != nil {
+		fval, fsig := interp.execBlock(s.Finalizer, env)
+		_ = fval
+		if fsig.typ != sigNone {
+			return nil, fsig
+		}
+	}
+
+	return val, sig
+}
+
+// execLabeled runs a labeled statement, which completes normally when its
+// body breaks to the label. A loop's labels are handed to the loop, which
+// takes them with takeLoopLabels, so that continue label starts its next
+// iteration; labels on the same loop, as in a: b: for, are collected.
+func (interp *Interpreter) execLabeled(s *ast.LabeledStatement, env *runtime.Environment) (*runtime.Value, signal) {
+	labels := append(interp.loopLabels, s.Label.Value)
+	interp.loopLabels = nil
+	switch s.Body.(type) {
+	case *ast.LabeledStatement, *ast.WhileStatement, *ast.DoWhileStatement, *ast.ForStatement, *ast.ForInStatement, *ast.ForOfStatement:
+		interp.loopLabels = labels
+	}
+	val, sig := interp.execStatement(s.Body, env)
+	interp.loopLabels = nil
+	if sig.typ == sigBreak && sig.label == s.Label.Value {
+		return val, signal{}
+	}
+	return val, sig
+}
+
+// takeLoopLabels returns the labels of the loop that is starting and clears
+// them, so that loops nested in its body do not see them.
+func (interp *Interpreter) takeLoopLabels() []string {
+	labels := interp.loopLabels
+	interp.loopLabels = nil
+	return labels
+}
+
+// continuesLoop reports whether sig, a continue completion, starts the next
+// iteration of the loop with labels rather than of an enclosing loop.
+func continuesLoop(sig signal, labels []string) bool {
+	if sig.label == "" {
+		return true
+	}
+	for _, label := range labels {
+		if label == sig.label {
+			return true
+		}
+	}
+	return false
+}
+
+func (interp *Interpreter) execClassDecl(s *ast.ClassDeclaration, env *runtime.Environment) (*runtime.Value, signal) {
+	classVal, sig := interp.buildClass(s.Name, s.SuperClass, s.Body, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	if err := env.Declare(s.Name.Value, "let", classVal); err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return nil, signal{}
+}
+
+func (interp *Interpreter) buildClass(name *ast.Identifier, superExpr ast.Expression, body *ast.ClassBody, env *runtime.Environment) (*runtime.Value, signal) {
+	// A base class is a function inheriting from Function.prototype, whose
+	// instances inherit from Object.prototype; extends null clears only the
+	// latter.
+	superProto := interp.realm.ObjectPrototype
+	var parent *runtime.Object
+	if superExpr != nil {
+		superVal, sig := interp.evalExpression(superExpr, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if superVal.Type != runtime.TypeNull && (superVal.Type != runtime.TypeObject || superVal.Object == nil || superVal.Object.Constructor == nil) {
+			return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("Class extends value %s is not a constructor or null", superVal.ToString()), env)}
+		}
+		superProto = nil
+		if superVal.Type == runtime.TypeObject {
+			parent = superVal.Object
+			protoProp := superVal.Object.Get("prototype")
+			if protoProp.Type == runtime.TypeObject && protoProp.Object != nil {
+				superProto = protoProp.Object
+			}
+		}
+	}
+
+	proto := runtime.NewOrdinaryObject(superProto)
+	var constructorFn runtime.CallableFunc
+
+	// A derived class inherits its parent's static members, and static
+	// methods look up super.name on the parent.
+	ctorProto := parent
+	if ctorProto == nil {
+		ctorProto = interp.realm.FunctionPrototype
+	}
+	classObj := runtime.NewFunctionObject(ctorProto, nil)
+	// The tag lets console.log show the class as [class Name].
+	classObj.Internal = map[string]interface{}{"isClass": true}
+	classObj.DefineProperty("prototype", &runtime.Property{Value: runtime.NewObject(proto), HasValue: true})
+	if name != nil {
+		classObj.DefineProperty("name", &runtime.Property{Value: runtime.NewString(name.Value), Configurable: true, HasValue: true})
+	}
+
+	// The class body has a scope of its own, binding the class name and
+	// the private names it declares. Every evaluation of the class creates
+	// new private names.
+	classEnv := runtime.NewEnvironment(env, true)
+	if name != nil {
+		classEnv.Declare(name.Value, "const", runtime.NewObject(classObj))
+	}
+	for _, privateName := range classPrivateNames(body) {
+		classEnv.Declare(privateName, "const", runtime.NewPrivateName(privateName))
+	}
+	instance := &classElements{env: interp.homeEnv(proto, classEnv)}
+	static := &classElements{env: interp.homeEnv(classObj, classEnv)}
+
+	for _, method := range body.Methods {
+		target, elements := proto, instance
+		if method.Static {
+			target, elements = classObj, static
+		}
+		fnVal := asMethod(interp.createFunctionFromExpr(method.Value, classEnv), target)
+
+		if key, ok := method.Key.(*ast.PrivateIdentifier); ok {
+			privateName, sig := interp.privateName(key, classEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			nameMethod(fnVal, method.Kind, key.Name)
+			elements.addPrivateMethod(privateName, method.Kind, fnVal)
+			continue
+		}
+		methodName, sig := interp.getPropertyKey(method.Key, method.Computed, classEnv)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		nameMethod(fnVal, method.Kind, methodName)
+
+		if method.Kind == "constructor" {
+			constructorFn = interp.makeConstructor(method.Value, classEnv, classObj, proto, parent, instance)
+			continue
+		}
+
+		if method.Kind == "get" {
+			if existing, ok := target.Properties[methodName]; ok && existing.IsAccessor {
+				existing.Getter = fnVal
+			} else {
+				target.DefineProperty(methodName, &runtime.Property{
+					Getter:       fnVal,
+					IsAccessor:   true,
+					Configurable: true,
+				})
+			}
+		} else if method.Kind == "set" {
+			if existing, ok := target.Properties[methodName]; ok && existing.IsAccessor {
+				existing.Setter = fnVal
+			} else {
+				target.DefineProperty(methodName, &runtime.Property{
+					Setter:       fnVal,
+					IsAccessor:   true,
+					Configurable: true,
+				})
+			}
+		} else {
+			target.DefineProperty(methodName, &runtime.Property{
+				Value:        fnVal,
+				Writable:     true,
+				Configurable: true,
+				HasValue:     true,
+			})
+		}
+	}
+
+	for _, field := range body.Fields {
+		elements := instance
+		if field.Static {
+			elements = static
+		}
+		if field.Block != nil {
+			elements.fields = append(elements.fields, classField{block: field.Block})
+			continue
+		}
+		f := classField{init: field.Value}
+		if key, ok := field.Key.(*ast.PrivateIdentifier); ok {
+			privateName, sig := interp.privateName(key, classEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			f.private = privateName
+		} else {
+			key, sig := interp.getPropertyKey(field.Key, field.Computed, classEnv)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			f.key = key
+		}
+		elements.fields = append(elements.fields, f)
+	}
+
+	if constructorFn == nil {
+		constructorFn = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			newTarget := interp.takeNewTarget(classObj)
+			if parent != nil {
+				var err error
+				if this, err = interp.superConstruct(parent, this, args, newTarget); err != nil {
+					return nil, err
+				}
+			}
+			if err := interp.initializeElements(this, instance); err != nil {
+				return nil, err
+			}
+			return this, nil
+		}
+	}
+
+	className := "anonymous"
+	if name != nil {
+		className = name.Value
+	}
+	classObj.Callable = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return nil, fmt.Errorf("TypeError: Class constructor %s cannot be invoked without 'new'", className)
+	}
+	classObj.Constructor = constructorFn
+
+	proto.DefineProperty("constructor", &runtime.Property{
+		Value:        runtime.NewObject(classObj),
+		Writable:     true,
+		Configurable: true,
+		HasValue:     true,
+	})
+
+	// Static elements are added to the class itself once it is complete.
+	classVal := runtime.NewObject(classObj)
+	if err := interp.initializeElements(classVal, static); err != nil {
+		if jsErr, ok := err.(*jsError); ok {
+			return nil, signal{typ: sigThrow, value: jsErr.value}
+		}
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return classVal, signal{}
+}
+
+// classElements are the private methods and fields a class adds to each
+// instance when it is constructed, or to itself for static elements.
+type classElements struct {
+	env     *runtime.Environment // scope of the field initializers
+	methods map[*runtime.Symbol]*runtime.Property
+	order   []*runtime.Symbol // private methods in declaration order
+	fields  []classField
+}
+
+// classField is a field declaration: key names a public field and private
+// a private one. init is the initializer expression, or nil. A static
+// block has only its block.
+type classField struct {
+	key     string
+	private *runtime.Symbol
+	init    ast.Expression
+	block   *ast.BlockStatement
+}
+
+// addPrivateMethod records the private method, getter or setter fn. A
+// getter and setter with the same name form one accessor.
+func (c *classElements) addPrivateMethod(name *runtime.Symbol, kind string, fn *runtime.Value) {
+	if c.methods == nil {
+		c.methods = make(map[*runtime.Symbol]*runtime.Property)
+	}
+	prop := c.methods[name]
+	if prop == nil {
+		prop = &runtime.Property{}
+		c.methods[name] = prop
+		c.order = append(c.order, name)
+	}
+	switch kind {
+	case "get":
+		prop.IsAccessor = true
+		prop.Getter = fn
+	case "set":
+		prop.IsAccessor = true
+		prop.Setter = fn
+	default:
+		prop.Value = fn
+		prop.HasValue = true
+	}
+}
+
+// classPrivateNames returns the private names a class body declares.
+func classPrivateNames(body *ast.ClassBody) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(key ast.Expression) {
+		if ident, ok := key.(*ast.PrivateIdentifier); ok && !seen[ident.Name] {
+			seen[ident.Name] = true
+			names = append(names, ident.Name)
+		}
+	}
+	for _, method := range body.Methods {
+		add(method.Key)
+	}
+	for _, field := range body.Fields {
+		add(field.Key)
+	}
+	return names
+}
+
+// initializeElements adds the private methods and then the fields of a
+// class to this. Field initializers run in order, each like a method
+// called on this.
+func (interp *Interpreter) initializeElements(this *runtime.Value, elements *classElements) error {
+	obj := this.Object
+	for _, name := range elements.order {
+		if err := obj.AddPrivate(name, elements.methods[name]); err != nil {
+			return err
+		}
+	}
+	for _, field := range elements.fields {
+		if field.block != nil {
+			if err := interp.runStaticBlock(field.block, this, elements.env); err != nil {
+				return err
+			}
+			continue
+		}
+		val := runtime.Undefined
+		if field.init != nil {
+			fieldEnv := runtime.NewEnvironment(elements.env, false)
+			fieldEnv.SetStrict(true)
+			fieldEnv.Declare("this", "const", this)
+			fieldEnv.Declare("new.target", "const", runtime.Undefined)
+			v, sig := interp.evalExpression(field.init, fieldEnv)
+			if sig.typ == sigThrow {
+				return &jsError{value: sig.value}
+			}
+			val = v
+		}
+		if field.private != nil {
+			if err := obj.AddPrivate(field.private, &runtime.Property{Value: val, Writable: true, HasValue: true}); err != nil {
+				return err
+			}
+			continue
+		}
+		obj.DefineProperty(field.key, &runtime.Property{
+			Value:        val,
+			Writable:     true,
+			Enumerable:   true,
+			Configurable: true,
+			HasValue:     true,
+		})
+	}
+	return nil
+}
+
+// runStaticBlock runs a static { ... } block of a class with this bound to
+// the class. Like a function body, the block has its own var scope.
+func (interp *Interpreter) runStaticBlock(block *ast.BlockStatement, this *runtime.Value, env *runtime.Environment) error {
+	blockEnv := runtime.NewEnvironment(env, false)
+	blockEnv.SetStrict(true)
+	blockEnv.Declare("this", "const", this)
+	blockEnv.Declare("new.target", "const", runtime.Undefined)
+	interp.hoist(block.Statements, blockEnv)
+	for _, stmt := range block.Statements {
+		_, sig := interp.execStatement(stmt, blockEnv)
+		switch sig.typ {
+		case sigThrow:
+			return &jsError{value: sig.value}
+		case sigReturn:
+			return &jsError{value: interp.makeErrorObject("SyntaxError", "Illegal return statement", blockEnv)}
+		}
+	}
+	return nil
+}
+
+// privateName resolves a private name such as #x to the name created by
+// the innermost enclosing class that declares it.
+func (interp *Interpreter) privateName(ident *ast.PrivateIdentifier, env *runtime.Environment) (*runtime.Symbol, signal) {
+	val, err := env.Get(ident.Name)
+	if err != nil || val.Type != runtime.TypeSymbol || !val.Symbol.IsPrivate() {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", fmt.Sprintf("Private field '%s' must be declared in an enclosing class", ident.Name), env)}
+	}
+	return val.Symbol, signal{}
+}
+
+// makeConstructor returns the [[Construct]] behaviour of the class classObj
+// defined by an explicit constructor method. In a derived class, parent is
+// the class it extends, which super(...) constructs; the instance elements
+// are added once it returns, or on entry in a base class.
+func (interp *Interpreter) makeConstructor(fe *ast.FunctionExpression, env *runtime.Environment, classObj, proto, parent *runtime.Object, elements *classElements) runtime.CallableFunc {
+	env = captureEnv(fe.Scope, env)
+	file := interp.currentFile()
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		newTarget := interp.takeNewTarget(classObj)
+		if err := interp.checkCallDepth(); err != nil {
+			return nil, err
+		}
+		defer interp.enterFrame(classObj, file)()
+		if parent == nil {
+			if err := interp.initializeElements(this, elements); err != nil {
+				return nil, err
+			}
+		}
+		fnEnv := runtime.NewEnvironment(env, false)
+		fnEnv.SetStrict(true)
+		if fe.Scope != nil && fe.Scope.DirectEval {
+			fnEnv.MarkCaptureBoundary()
+		}
+
+		// bind this
+		fnEnv.Declare("this", "const", this)
+		fnEnv.Declare("new.target", "const", newTarget)
+
+		// super function
+		var superCall runtime.CallableFunc
+		if parent != nil {
+			superCall = func(thisVal *runtime.Value, superArgs []*runtime.Value) (*runtime.Value, error) {
+				result, err := interp.superConstruct(parent, this, superArgs, newTarget)
+				if err != nil {
+					return nil, err
+				}
+				// The parent may have returned another object, which
+				// becomes this.
+				if result != this {
+					this = result
+					if b, ok := fnEnv.GetBinding("this"); ok {
+						b.Value = result
+					}
+				}
+				if err := interp.initializeElements(this, elements); err != nil {
+					return nil, err
+				}
+				return this, nil
+			}
+		}
+		fnEnv.Declare("super", "const", runtime.NewObject(interp.superBinding(proto, superCall)))
+
+		if sig := interp.bindFunctionParams(fe.Params, fe.Defaults, fe.Rest, args, fnEnv); sig.typ == sigThrow {
+			return nil, &jsError{value: sig.value}
+		}
+		interp.hoist(fe.Body.Statements, fnEnv)
+
+		for _, stmt := range fe.Body.Statements {
+			_, sig := interp.execStatement(stmt, fnEnv)
+			if sig.typ == sigReturn {
+				if sig.value != nil && sig.value.Type == runtime.TypeObject {
+					return sig.value, nil
+				}
+				return this, nil
+			}
+			if sig.typ == sigThrow {
+				return nil, &jsError{value: sig.value}
+			}
+		}
+		return this, nil
+	}
+}
+
+// getPropertyKey returns the key of a property definition or pattern
+// property. A computed key is evaluated and converted with ToPropertyKey.
+func (interp *Interpreter) getPropertyKey(key ast.Expression, computed bool, env *runtime.Environment) (string, signal) {
+	if computed {
+		val, sig := interp.evalExpression(key, env)
+		if sig.typ != sigNone {
+			return "", sig
+		}
+		return interp.toPropertyKey(val, env)
+	}
+	switch k := key.(type) {
+	case *ast.Identifier:
+		return k.Value, signal{}
+	case *ast.StringLiteral:
+		return k.Value, signal{}
+	case *ast.NumberLiteral:
+		return runtime.NumberToString(k.Value), signal{}
+	}
+	return "", signal{}
+}
+
+// ---------- Expression evaluation ----------
+
+func (interp *Interpreter) evalExpression(expr ast.Expression, env *runtime.Environment) (*runtime.Value, signal) {
+	switch e := expr.(type) {
+	case *ast.NumberLiteral:
+		return runtime.NewNumber(e.Value), signal{}
+	case *ast.StringLiteral:
+		return runtime.NewString(e.Value), signal{}
+	case *ast.BooleanLiteral:
+		return runtime.NewBool(e.Value), signal{}
+	case *ast.NullLiteral:
+		return runtime.Null, signal{}
+	case *ast.UndefinedLiteral:
+		return runtime.Undefined, signal{}
+	case *ast.Identifier:
+		return interp.evalIdentifier(e, env)
+	case *ast.PrivateIdentifier:
+		name, sig := interp.privateName(e, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		return &runtime.Value{Type: runtime.TypeSymbol, Symbol: name}, signal{}
+	case *ast.MetaProperty:
+		val, err := env.Get("new.target")
+		if err != nil {
+			return nil, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", "new.target expression is not allowed here", env)}
+		}
+		return val, signal{}
+	case *ast.ThisExpression:
+		val, err := env.Get("this")
+		if err != nil || val == nil || val == runtime.Undefined {
+			return interp.globalObject, signal{}
+		}
+		return val, signal{}
+	case *ast.RegExpLiteral:
+		return interp.evalRegExpLiteral(e, env)
+	case *ast.ArrayLiteral:
+		return interp.evalArrayLiteral(e, env)
+	case *ast.ObjectLiteral:
+		return interp.evalObjectLiteral(e, env)
+	case *ast.FunctionExpression:
+		return interp.createFunctionFromExpr(e, env), signal{}
+	case *ast.ArrowFunctionExpression:
+		return interp.createArrowFunction(e, env), signal{}
+	case *ast.AwaitExpression:
+		return interp.evalAwait(e, env)
+	case *ast.YieldExpression:
+		return interp.evalYield(e, env)
+	case *ast.UnaryExpression:
+		return interp.evalUnary(e, env)
+	case *ast.UpdateExpression:
+		return interp.evalUpdate(e, env)
+	case *ast.BinaryExpression:
+		return interp.evalBinary(e, env)
+	case *ast.LogicalExpression:
+		return interp.evalLogical(e, env)
+	case *ast.AssignmentExpression:
+		return interp.evalAssignment(e, env)
+	case *ast.ConditionalExpression:
+		return interp.evalConditional(e, env)
+	case *ast.CallExpression:
+		return interp.evalCall(e, env)
+	case *ast.MemberExpression:
+		return interp.evalMember(e, env)
+	case *ast.ChainExpression:
+		return interp.evalChain(e, env)
+	case *ast.NewExpression:
+		return interp.evalNew(e, env)
+	case *ast.SequenceExpression:
+		return interp.evalSequence(e, env)
+	case *ast.TemplateLiteralExpr:
+		return interp.evalTemplateLiteral(e, env)
+	case *ast.SpreadElement:
+		return interp.evalExpression(e.Argument, env)
+	case *ast.ClassExpression:
+		return interp.buildClass(e.Name, e.SuperClass, e.Body, env)
+	case *ast.SuperExpression:
+		val, err := env.Get("super")
+		if err != nil {
+			return runtime.Undefined, signal{}
+		}
+		return val, signal{}
+	case *ast.ComputedPropertyName:
+		return interp.evalExpression(e.Expression, env)
+	default:
+		return runtime.Undefined, signal{typ: sigThrow, value: runtime.NewString(fmt.Sprintf("unsupported expression: %T", expr))}
+	}
+}
+
+func (interp *Interpreter) evalIdentifier(e *ast.Identifier, env *runtime.Environment) (*runtime.Value, signal) {
+	return interp.lookupIdentifier(e, env, nil)
+}
+
+// lookupIdentifier evaluates an identifier that compiled code with the
+// given locals did not resolve to one of them.
+func (interp *Interpreter) lookupIdentifier(e *ast.Identifier, env *runtime.Environment, locals []string) (*runtime.Value, signal) {
+	// Handle special globals
+	switch e.Value {
+	case "NaN":
+		return runtime.NaN, signal{}
+	case "Infinity":
+		return runtime.PosInf, signal{}
+	}
+	val, err := env.Get(e.Value)
+	if err != nil {
+		if err.Error() == "ReferenceError: "+e.Value+" is not defined" {
+			return nil, signal{typ: sigThrow, value: interp.undefinedReference(e.Value, env, locals...)}
+		}
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return val, signal{}
+}
+
+func (interp *Interpreter) evalRegExpLiteral(e *ast.RegExpLiteral, env *runtime.Environment) (*runtime.Value, signal) {
+	// Look up RegExp constructor and call it with new
+	regexpCtor, err := env.Get("RegExp")
+	if err != nil || regexpCtor == nil || regexpCtor.Type != runtime.TypeObject || regexpCtor.Object == nil || regexpCtor.Object.Constructor == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "RegExp is not defined", env)}
+	}
+	args := []*runtime.Value{runtime.NewString(e.Pattern), runtime.NewString(e.Flags)}
+	result, callErr := regexpCtor.Object.Constructor(regexpCtor, args)
+	if callErr != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(callErr, env)}
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) evalArrayLiteral(e *ast.ArrayLiteral, env *runtime.Environment) (*runtime.Value, signal) {
+	var elements []*runtime.Value
+	for _, elem := range e.Elements {
+		if elem == nil {
+			// An elision leaves a hole.
+			elements = append(elements, nil)
+			continue
+		}
+		if spread, ok := elem.(*ast.SpreadElement); ok {
+			arrVal, sig := interp.evalExpression(spread.Argument, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if arrVal.Type == runtime.TypeObject && interp.realm.HasDefaultIterator(arrVal) {
+				elements = append(elements, arrVal.Object.ArrayValues()...)
+				continue
+			}
+			if elements, sig = interp.appendIterated(elements, arrVal, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			continue
+		}
+		val, sig := interp.evalExpression(elem, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		elements = append(elements, val)
+	}
+	if sig := interp.charge(runtime.ObjectSize+int64(len(elements))*runtime.SlotSize, env); sig.typ != sigNone {
+		return nil, sig
+	}
+	arr := runtime.NewArrayObject(interp.realm.ArrayPrototype, elements)
+	return runtime.NewObject(arr), signal{}
+}
+
+func (interp *Interpreter) evalObjectLiteral(e *ast.ObjectLiteral, env *runtime.Environment) (*runtime.Value, signal) {
+	if sig := interp.charge(runtime.ObjectSize+int64(len(e.Properties))*runtime.PropertySize, env); sig.typ != sigNone {
+		return nil, sig
+	}
+	obj := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+	for _, prop := range e.Properties {
+		if spread, ok := prop.Key.(*ast.SpreadElement); ok {
+			srcVal, sig := interp.evalExpression(spread.Argument, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if srcVal.Type == runtime.TypeObject && srcVal.Object != nil {
+				if err := interp.copyDataProperties(obj, srcVal.Object, nil); err != nil {
+					return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+				}
+			}
+			continue
+		}
+
+		key, sig := interp.getPropertyKey(prop.Key, prop.Computed, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+
+		if prop.Kind == "get" || prop.Kind == "set" {
+			fnVal, sig := interp.evalExpression(prop.Value, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			fnVal = asMethod(fnVal, obj)
+			nameMethod(fnVal, prop.Kind, key)
+			if prop.Kind == "get" {
+				existing := obj.Properties[key]
+				if existing != nil && existing.IsAccessor {
+					existing.Getter = fnVal
+				} else {
+					obj.DefineProperty(key, &runtime.Property{
+						Getter:       fnVal,
+						IsAccessor:   true,
+						Enumerable:   true,
+						Configurable: true,
+					})
+				}
+			} else {
+				existing := obj.Properties[key]
+				if existing != nil && existing.IsAccessor {
+					existing.Setter = fnVal
+				} else {
+					obj.DefineProperty(key, &runtime.Property{
+						Setter:       fnVal,
+						IsAccessor:   true,
+						Enumerable:   true,
+						Configurable: true,
+					})
+				}
+			}
+			continue
+		}
+
+		if prop.Shorthand {
+			val, sig := interp.evalExpression(prop.Key, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			obj.Set(key, val)
+			continue
+		}
+
+		val, sig := interp.evalExpression(prop.Value, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if prop.Method {
+			val = asMethod(val, obj)
+		}
+		nameFunction(prop.Value, val, key)
+		// __proto__: value sets the prototype of the literal, which its
+		// methods' super.name lookups start from, rather than a property.
+		if key == "__proto__" && !prop.Computed && !prop.Method {
+			if val.Type == runtime.TypeNull {
+				obj.Prototype = nil
+			} else if val.Type == runtime.TypeObject && val.Object != nil {
+				obj.Prototype = val.Object
+			}
+			continue
+		}
+		obj.Set(key, val)
+	}
+	return runtime.NewObject(obj), signal{}
+}
+
+func (interp *Interpreter) createFunctionFromDecl(s *ast.FunctionDeclaration, env *runtime.Environment) *runtime.Value {
+	return interp.createFunctionImpl(s.Name, s.Params, s.Defaults, s.Rest, s.Body, s.Scope, env, false, false, s.Async, s.Generator, s.Strict)
+}
+
+// captureEnv returns the environment a closure created in env keeps alive.
+// A function the parser analyzed captures only the bindings it can refer to,
+// so unrelated bindings of enclosing scopes can be collected. Functions that
+// use direct eval, or were never analyzed, keep the whole scope chain.
+func captureEnv(scope *ast.Scope, env *runtime.Environment) *runtime.Environment {
+	if scope == nil || scope.Dynamic {
+		return env
+	}
+	return env.Capture(scope.Free)
+}
+
+func (interp *Interpreter) createFunctionImpl(name *ast.Identifier, params []ast.Expression, defaults []ast.Expression, rest ast.Expression, body *ast.BlockStatement, scope *ast.Scope, env *runtime.Environment, isArrow bool, isExpression bool, isAsync bool, isGenerator bool, strict bool) *runtime.Value {
+	closureEnv := captureEnv(scope, env)
+	file := interp.currentFile()
+	var fnName string
+	if name != nil {
+		fnName = name.Value
+	}
+
+	// A sloppy function whose parameters are all plain names maps the
+	// elements of its arguments object to them.
+	// A strict one never does.
+	simpleParams := make([]string, 0, len(params))
+	for i, param := range params {
+		ident, ok := param.(*ast.Identifier)
+		if !ok || (i < len(defaults) && defaults[i] != nil) || rest != nil {
+			simpleParams = nil
+			break
+		}
+		simpleParams = append(simpleParams, ident.Value)
+	}
+	if strict {
+		simpleParams = nil
+	}
+	// Only a function that mentions super needs its own super binding; in
+	// one that is not a method it hides the binding of an enclosing method.
+	usesSuper := !isArrow && (scope == nil || scope.Dynamic || slices.Contains(scope.Free, "super"))
+	// Likewise only one that mentions arguments needs the arguments object,
+	// which is costly to make on every call.
+	usesArguments := !isArrow && (scope == nil || scope.Dynamic || slices.Contains(scope.Free, "arguments"))
+
+	var callable runtime.CallableFunc
+	var fnObj *runtime.Object
+	// enter creates the environment of a call and binds this, arguments,
+	// new.target and the parameters in it; run evaluates the body.
+	enter := func(this *runtime.Value, args []*runtime.Value, newTarget *runtime.Value) (*runtime.Environment, error) {
+		fnEnv := runtime.NewEnvironment(closureEnv, false)
+		fnEnv.SetStrict(strict)
+		if scope != nil && scope.DirectEval {
+			fnEnv.MarkCaptureBoundary()
+		}
+
+		var argsObj *runtime.Object
+		if !isArrow {
+			fnEnv.Declare("this", "const", this)
+			fnEnv.Declare("new.target", "const", newTarget)
+		}
+		if usesArguments {
+			if strict {
+				argsObj = interp.realm.NewArgumentsObject(args, nil)
+			} else {
+				argsObj = interp.realm.NewArgumentsObject(args, runtime.NewObject(fnObj))
+			}
+			fnEnv.Declare("arguments", "var", runtime.NewObject(argsObj))
+		}
+		if usesSuper {
+			home, _ := fnObj.Internal["homeObject"].(*runtime.Object)
+			fnEnv.Declare("super", "const", runtime.NewObject(interp.superBinding(home, nil)))
+		}
+
+		// Only named function expressions get an immutable self-reference binding.
+		// Function declarations do not - their name binds in the enclosing scope.
+		if fnName != "" && isExpression {
+			fnEnv.Declare(fnName, "const", runtime.NewObject(fnObj))
+		}
+
+		if sig := interp.bindFunctionParams(params, defaults, rest, args, fnEnv); sig.typ == sigThrow {
+			return nil, &jsError{value: sig.value}
+		}
+		if argsObj != nil && simpleParams != nil {
+			argsObj.MapArguments(fnEnv, simpleParams)
+		}
+		return fnEnv, nil
+	}
+	run := func(fnEnv *runtime.Environment) (*runtime.Value, error) {
+		if err := interp.checkCallDepth(); err != nil {
+			return nil, err
+		}
+		defer interp.enterFrame(fnObj, file)()
+		interp.hoist(body.Statements, fnEnv)
+
+		for _, stmt := range body.Statements {
+			_, sig := interp.execStatement(stmt, fnEnv)
+			if sig.typ == sigReturn {
+				return sig.value, nil
+			}
+			if sig.typ == sigThrow {
+				interp.noteThrow(sig.value)
+				return nil, &jsError{value: sig.value}
+			}
+		}
+		return runtime.Undefined, nil
+	}
+	// In Compiled mode a plain function runs the bytecode of its body,
+	// which keeps its variables in slots instead of in fnEnv.
+	var code *bytecode
+	compiled := false
+	bytecodeOf := func() *bytecode {
+		if interp.mode != Compiled || isAsync || isGenerator {
+			return nil
+		}
+		if !compiled {
+			compiled = true
+			selfName := ""
+			if isExpression {
+				selfName = fnName
+			}
+			code = interp.compileFunction(selfName, params, defaults, rest, body, scope, strict)
+		}
+		return code
+	}
+	callable = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if !isAsync && !isGenerator && interp.co != nil {
+			defer interp.leaveCoroutine()()
+		}
+		if code := bytecodeOf(); code != nil {
+			return interp.runBytecode(code, fnObj, file, closureEnv, this, args, runtime.Undefined)
+		}
+		fnEnv, err := enter(this, args, runtime.Undefined)
+		if err != nil {
+			return nil, err
+		}
+		return run(fnEnv)
+	}
+	switch {
+	case isAsync && isGenerator:
+		callable = interp.asyncGeneratorFunction(func(this *runtime.Value, args []*runtime.Value) (func() (*runtime.Value, error), error) {
+			fnEnv, err := enter(this, args, runtime.Undefined)
+			if err != nil {
+				return nil, err
+			}
+			return func() (*runtime.Value, error) { return run(fnEnv) }, nil
+		}, &fnObj)
+	case isAsync:
+		callable = interp.asyncFunction(callable)
+	case isGenerator:
+		// Parameters are bound when the generator function is called, so
+		// errors in them are thrown by the call rather than the first next.
+		callable = interp.generatorFunction(func(this *runtime.Value, args []*runtime.Value) (func() (*runtime.Value, error), error) {
+			fnEnv, err := enter(this, args, runtime.Undefined)
+			if err != nil {
+				return nil, err
+			}
+			return func() (*runtime.Value, error) { return run(fnEnv) }, nil
+		}, &fnObj)
+	}
+
+	fnObj = runtime.NewFunctionObject(interp.realm.FunctionPrototype, callable)
+	switch {
+	case isAsync && isGenerator:
+		fnObj.Internal = map[string]interface{}{"isGenerator": true, "isAsync": true}
+		fnObj.DefineProperty("prototype", &runtime.Property{
+			Value:    runtime.NewObject(runtime.NewOrdinaryObject(interp.asyncGeneratorPrototype())),
+			Writable: true,
+		})
+	case isAsync:
+		// Async functions are not constructors and have no prototype object.
+		fnObj.Internal = map[string]interface{}{"isAsync": true}
+	case isGenerator:
+		// A generator function's prototype is the prototype of the generator
+		// objects it returns; it has no constructor property.
+		fnObj.Internal = map[string]interface{}{"isGenerator": true}
+		fnObj.DefineProperty("prototype", &runtime.Property{
+			Value:    runtime.NewObject(runtime.NewOrdinaryObject(interp.generatorPrototype())),
+			Writable: true,
+		})
+	default:
+		// Only ordinary functions are constructors.
+		fnObj.Constructor = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			newTarget := interp.takeNewTarget(fnObj)
+			if interp.co != nil {
+				defer interp.leaveCoroutine()()
+			}
+			if code := bytecodeOf(); code != nil {
+				return interp.runBytecode(code, fnObj, file, closureEnv, this, args, newTarget)
+			}
+			fnEnv, err := enter(this, args, newTarget)
+			if err != nil {
+				return nil, err
+			}
+			return run(fnEnv)
+		}
+		fnProto := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+		fnProto.DefineProperty("constructor", &runtime.Property{
+			Value:        runtime.NewObject(fnObj),
+			Writable:     true,
+			Enumerable:   false,
+			Configurable: true,
+		})
+		fnObj.DefineProperty("prototype", &runtime.Property{
+			Value:        runtime.NewObject(fnProto),
+			Writable:     true,
+			Enumerable:   false,
+			Configurable: false,
+		})
+	}
+	if fnName != "" {
+		fnObj.DefineProperty("name", &runtime.Property{
+			Value:        runtime.NewString(fnName),
+			Writable:     false,
+			Enumerable:   false,
+			Configurable: true,
+		})
+	}
+	fnObj.DefineProperty("length", &runtime.Property{
+		Value:        runtime.NewNumber(float64(len(params))),
+		Writable:     false,
+		Enumerable:   false,
+		Configurable: true,
+	})
+
+	return runtime.NewObject(fnObj)
+}
+
+func (interp *Interpreter) createFunctionFromExpr(e *ast.FunctionExpression, env *runtime.Environment) *runtime.Value {
+	return interp.createFunctionImpl(e.Name, e.Params, e.Defaults, e.Rest, e.Body, e.Scope, env, false, true, e.Async, e.Generator, e.Strict)
+}
+
+func (interp *Interpreter) createArrowFunction(e *ast.ArrowFunctionExpression, env *runtime.Environment) *runtime.Value {
+	closureEnv := captureEnv(e.Scope, env)
+	file := interp.currentFile()
+
+	var callable runtime.CallableFunc
+	var fnObj *runtime.Object
+	callable = func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if !e.Async && interp.co != nil {
+			defer interp.leaveCoroutine()()
+		}
+		if err := interp.checkCallDepth(); err != nil {
+			return nil, err
+		}
+		defer interp.enterFrame(fnObj, file)()
+		fnEnv := runtime.NewEnvironment(closureEnv, false)
+		fnEnv.SetStrict(e.Strict)
+		if e.Scope != nil && e.Scope.DirectEval {
+			fnEnv.MarkCaptureBoundary()
+		}
+
+		if sig := interp.bindFunctionParams(e.Params, e.Defaults, e.Rest, args, fnEnv); sig.typ == sigThrow {
+			return nil, &jsError{value: sig.value}
+		}
+
+		switch body := e.Body.(type) {
+		case *ast.BlockStatement:
+			interp.hoist(body.Statements, fnEnv)
+			for _, stmt := range body.Statements {
+				_, sig := interp.execStatement(stmt, fnEnv)
+				if sig.typ == sigReturn {
+					return sig.value, nil
+				}
+				if sig.typ == sigThrow {
+					interp.noteThrow(sig.value)
+					return nil, &jsError{value: sig.value}
+				}
+			}
+			return runtime.Undefined, nil
+		case ast.Expression:
+			interp.at(body)
+			val, sig := interp.evalExpression(body, fnEnv)
+			if sig.typ == sigThrow {
+				interp.noteThrow(sig.value)
+				return nil, &jsError{value: sig.value}
+			}
+			return val, nil
+		}
+		return runtime.Undefined, nil
+	}
+	if e.Async {
+		callable = interp.asyncFunction(callable)
+	}
+
+	fnObj = runtime.NewFunctionObject(interp.realm.FunctionPrototype, callable)
+	fnObj.Internal = map[string]interface{}{"isArrow": true}
+	if e.Async {
+		fnObj.Internal["isAsync"] = true
+	}
+	fnObj.DefineProperty("length", &runtime.Property{
+		Value:        runtime.NewNumber(float64(len(e.Params))),
+		Writable:     false,
+		Enumerable:   false,
+		Configurable: true,
+	})
+	return runtime.NewObject(fnObj)
+}
+
+// bindFunctionParams binds the parameters of a call to args. Defaults are
+// evaluated left to right in the parameter scope, where later parameters
+// are still uninitialized, and any exception they or a destructuring
+// pattern throw is returned.
+func (interp *Interpreter) bindFunctionParams(params []ast.Expression, defaults []ast.Expression, rest ast.Expression, args []*runtime.Value, env *runtime.Environment) signal {
+	// A closure in a default value captures the parameter bindings when it
+	// is created, so create them all before any default is evaluated.
+	for _, param := range params {
+		if ident, ok := param.(*ast.Identifier); ok {
+			env.DeclareUninitialized(ident.Value, "let")
+		} else {
+			for _, name := range interp.extractBindingNames(param) {
+				env.DeclareUninitialized(name, "let")
+			}
+		}
+	}
+	for i, param := range params {
+		var val *runtime.Value
+		if i < len(args) {
+			val = args[i]
+		} else {
+			val = runtime.Undefined
+		}
+		if val.Type == runtime.TypeUndefined && i < len(defaults) && defaults[i] != nil {
+			defVal, sig := interp.evalExpression(defaults[i], env)
+			if sig.typ != sigNone {
+				return sig
+			}
+			val = defVal
+		}
+		if ident, ok := param.(*ast.Identifier); ok {
+			// A repeated name (allowed in sloppy mode) takes the last
+			// value, and a parameter shadows arguments or the name of a
+			// function expression.
+			if b, ok := env.GetBinding(ident.Value); ok && b.Declared {
+				b.Value, b.Kind, b.Mutable = val, "let", true
+				continue
+			}
+		}
+		if sig := interp.bindPattern(param, val, "let", env); sig.typ != sigNone {
+			return sig
+		}
+	}
+	if rest != nil {
+		// Copy, so the rest array does not share storage with args.
+		var restArgs []*runtime.Value
+		if len(params) < len(args) {
+			restArgs = append(restArgs, args[len(params):]...)
+		}
+		restArr := runtime.NewArrayObject(interp.realm.ArrayPrototype, restArgs)
+		restVal := runtime.NewObject(restArr)
+		// rest may be *ast.RestElement wrapping an Identifier
+		if re, ok := rest.(*ast.RestElement); ok {
+			rest = re.Argument
+		}
+		return interp.bindPattern(rest, restVal, "let", env)
+	}
+	return signal{}
+}
+
+func (interp *Interpreter) evalUnary(e *ast.UnaryExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	if e.Operator == "typeof" {
+		if ident, ok := e.Operand.(*ast.Identifier); ok {
+			return interp.typeofIdentifier(ident, env)
+		}
+		val, sig := interp.evalExpression(e.Operand, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		return interp.typeofValue(val), signal{}
+	}
+
+	if e.Operator == "delete" {
+		operand := e.Operand
+		if chain, ok := operand.(*ast.ChainExpression); ok {
+			// delete a?.b is a no-op returning true when the chain
+			// short-circuits.
+			operand = chain.Expression
+		}
+		if member, ok := operand.(*ast.MemberExpression); ok {
+			objVal, sig := interp.evalExpression(member.Object, env)
+			if sig.typ == sigShortCircuit || (sig.typ == sigNone && member.Optional && isNullish(objVal)) {
+				return runtime.True, signal{}
+			}
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			key, sig := interp.memberKey(member, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if objVal.Type == runtime.TypeObject && objVal.Object != nil {
+				deleted, err := objVal.Object.DeleteErr(key)
+				if err != nil {
+					return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+				}
+				// Strict mode code throws where delete would return false.
+				if !deleted && env.IsStrict() {
+					return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", failedDeleteMessage(objVal.Object, key), env)}
+				}
+				return runtime.NewBool(deleted), signal{}
+			}
+		}
+		if ident, ok := operand.(*ast.Identifier); ok {
+			deleted, err := env.Delete(ident.Value)
+			if err != nil {
+				return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+			return runtime.NewBool(deleted), signal{}
+		}
+		return runtime.True, signal{}
+	}
+
+	operand, sig := interp.evalExpression(e.Operand, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	switch e.Operator {
+	case "-", "+", "~":
+		n, sig := interp.toNumber(operand, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		switch e.Operator {
+		case "-":
+			return runtime.NewNumber(-n), signal{}
+		case "+":
+			return runtime.NewNumber(n), signal{}
+		}
+		return runtime.NewNumber(float64(^int32(n))), signal{}
+	case "!":
+		return runtime.NewBool(!operand.ToBoolean()), signal{}
+	case "void":
+		return runtime.Undefined, signal{}
+	}
+	return runtime.Undefined, signal{}
+}
+
+// typeofIdentifier evaluates typeof name. A name that resolves to no
+// binding gives "undefined", but other errors, such as reading a let
+// binding before its declaration, are thrown.
+func (interp *Interpreter) typeofIdentifier(e *ast.Identifier, env *runtime.Environment) (*runtime.Value, signal) {
+	val, err := env.Get(e.Value)
+	if err != nil {
+		if err.Error() == "ReferenceError: "+e.Value+" is not defined" {
+			return runtime.NewString("undefined"), signal{}
+		}
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return interp.typeofValue(val), signal{}
+}
+
+func (interp *Interpreter) typeofValue(val *runtime.Value) *runtime.Value {
+	if val == nil {
+		return runtime.NewString("undefined")
+	}
+	switch val.Type {
+	case runtime.TypeUndefined:
+		return runtime.NewString("undefined")
+	case runtime.TypeNull:
+		return runtime.NewString("object")
+	case runtime.TypeBoolean:
+		return runtime.NewString("boolean")
+	case runtime.TypeNumber:
+		return runtime.NewString("number")
+	case runtime.TypeString:
+		return runtime.NewString("string")
+	case runtime.TypeObject:
+		if val.Object != nil && val.Object.Callable != nil {
+			return runtime.NewString("function")
+		}
+		return runtime.NewString("object")
+	case runtime.TypeSymbol:
+		return runtime.NewString("symbol")
+	}
+	return runtime.NewString("undefined")
+}
+
+func (interp *Interpreter) evalUpdate(e *ast.UpdateExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	ref, sig := interp.evalReference(e.Operand, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	old, sig := interp.getReference(ref, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	oldNum, sig := interp.toNumber(old, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	var newNum float64
+	if e.Operator == "++" {
+		newNum = oldNum + 1
+	} else {
+		newNum = oldNum - 1
+	}
+	newVal := runtime.NewNumber(newNum)
+	asig := interp.putReference(ref, newVal, env)
+	if asig.typ != sigNone {
+		return nil, asig
+	}
+
+	if e.Prefix {
+		return newVal, signal{}
+	}
+	if old.Type == runtime.TypeNumber {
+		return old, signal{}
+	}
+	return runtime.NewNumber(oldNum), signal{}
+}
+
+func (interp *Interpreter) evalBinary(e *ast.BinaryExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	left, sig := interp.evalExpression(e.Left, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	right, sig := interp.evalExpression(e.Right, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	return interp.binaryOp(e.Operator, left, right, env)
+}
+
+// binaryOp applies the binary operator op to the evaluated operands left
+// and right.
+func (interp *Interpreter) binaryOp(op string, left, right *runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	left, right, sig := interp.primitiveOperands(op, left, right, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	switch op {
+	case "+":
+		if left.Type == runtime.TypeString || right.Type == runtime.TypeString {
+			return interp.concatStrings(left, right, env)
+		}
+		return runtime.NewNumber(left.ToNumber() + right.ToNumber()), signal{}
+	case "-":
+		return runtime.NewNumber(left.ToNumber() - right.ToNumber()), signal{}
+	case "*":
+		return runtime.NewNumber(left.ToNumber() * right.ToNumber()), signal{}
+	case "/":
+		return runtime.NewNumber(left.ToNumber() / right.ToNumber()), signal{}
+	case "%":
+		rn := right.ToNumber()
+		if rn == 0 {
+			return runtime.NaN, signal{}
+		}
+		return runtime.NewNumber(math.Mod(left.ToNumber(), rn)), signal{}
+	case "**":
+		return runtime.NewNumber(math.Pow(left.ToNumber(), right.ToNumber())), signal{}
+	case "==", "!=":
+		eq, err := runtime.LooseEquals(left, right)
+		if err != nil {
+			return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+		return runtime.NewBool(eq == (op == "==")), signal{}
+	case "===":
+		return runtime.NewBool(runtime.StrictEquals(left, right)), signal{}
+	case "!==":
+		return runtime.NewBool(!runtime.StrictEquals(left, right)), signal{}
+	case "<", ">", "<=", ">=":
+		r, err := runtime.Compare(op, left, right)
+		if err != nil {
+			return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+		return runtime.NewBool(r), signal{}
+	case "&":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) & int32(right.ToNumber()))), signal{}
+	case "|":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) | int32(right.ToNumber()))), signal{}
+	case "^":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) ^ int32(right.ToNumber()))), signal{}
+	case "<<":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) << (uint32(right.ToNumber()) & 0x1f))), signal{}
+	case ">>":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) >> (uint32(right.ToNumber()) & 0x1f))), signal{}
+	case ">>>":
+		return runtime.NewNumber(float64(uint32(left.ToNumber()) >> (uint32(right.ToNumber()) & 0x1f))), signal{}
+	case "instanceof":
+		return interp.evalInstanceof(left, right), signal{}
+	case "in":
+		return interp.evalIn(left, right, env)
+	}
+	return runtime.Undefined, signal{}
+}
+
+// primitiveOperands applies ToPrimitive to object operands of arithmetic,
+// bitwise and relational operators, so user valueOf, toString and
+// Symbol.toPrimitive methods run (and may throw) before the operation. +
+// uses hint "default"; the others use "number".
+func (interp *Interpreter) primitiveOperands(op string, left, right *runtime.Value, env *runtime.Environment) (*runtime.Value, *runtime.Value, signal) {
+	if left.Type != runtime.TypeObject && right.Type != runtime.TypeObject {
+		return left, right, signal{}
+	}
+	hint := "number"
+	switch op {
+	case "+", "+=":
+		hint = "default"
+	case "-", "*", "/", "%", "**", "<", ">", "<=", ">=", "&", "|", "^", "<<", ">>", ">>>",
+		"-=", "*=", "/=", "%=", "**=", "&=", "|=", "^=", "<<=", ">>=", ">>>=":
+	default:
+		return left, right, signal{}
+	}
+	l, sig := interp.toPrimitive(left, hint, env)
+	if sig.typ != sigNone {
+		return nil, nil, sig
+	}
+	r, sig := interp.toPrimitive(right, hint, env)
+	if sig.typ != sigNone {
+		return nil, nil, sig
+	}
+	return l, r, signal{}
+}
+
+// toPrimitive is runtime.ToPrimitive with errors thrown as JS exceptions.
+func (interp *Interpreter) toPrimitive(val *runtime.Value, hint string, env *runtime.Environment) (*runtime.Value, signal) {
+	prim, err := interp.agent.ToPrimitive(val, hint)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return prim, signal{}
+}
+
+// toString is ToString with errors from object conversion thrown. Unlike
+// Value.ToString, it throws for a symbol, which only String() and
+// property keys convert.
+func (interp *Interpreter) toString(val *runtime.Value, env *runtime.Environment) (string, signal) {
+	s, err := interp.agent.ToString(val)
+	if err != nil {
+		return "", signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return s, signal{}
+}
+
+// toPropertyKey is runtime.ToPropertyKey with errors thrown.
+func (interp *Interpreter) toPropertyKey(val *runtime.Value, env *runtime.Environment) (string, signal) {
+	key, err := runtime.ToPropertyKey(val)
+	if err != nil {
+		return "", signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return key, signal{}
+}
+
+// toNumber is ToNumber with errors from object conversion thrown.
+func (interp *Interpreter) toNumber(val *runtime.Value, env *runtime.Environment) (float64, signal) {
+	prim, sig := interp.toPrimitive(val, "number", env)
+	if sig.typ != sigNone {
+		return 0, sig
+	}
+	return prim.ToNumber(), signal{}
+}
+
+func (interp *Interpreter) evalInstanceof(left, right *runtime.Value) *runtime.Value {
+	if right.Type != runtime.TypeObject || right.Object == nil || right.Object.Callable == nil {
+		return runtime.False
+	}
+	if left.Type != runtime.TypeObject || left.Object == nil {
+		return runtime.False
+	}
+
+	protoProp := right.Object.Get("prototype")
+	if protoProp.Type != runtime.TypeObject || protoProp.Object == nil {
+		return runtime.False
+	}
+
+	proto := left.Object.Prototype
+	for proto != nil {
+		if proto == protoProp.Object {
+			return runtime.True
+		}
+		proto = proto.Prototype
+	}
+	return runtime.False
+}
+
+func (interp *Interpreter) evalIn(left, right *runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	// #x in obj checks for the private element, evaluating #x to its name.
+	if left.Type == runtime.TypeSymbol && left.Symbol.IsPrivate() {
+		if right.Type != runtime.TypeObject || right.Object == nil {
+			return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("Cannot use 'in' operator to search for '%s' in %s", left.Symbol.Description, right.ToString()), env)}
+		}
+		return runtime.NewBool(right.Object.HasPrivate(left.Symbol)), signal{}
+	}
+	if right.Type != runtime.TypeObject || right.Object == nil {
+		return runtime.False, signal{}
+	}
+	key, sig := interp.toPropertyKey(left, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	if right.Object.OType == runtime.ObjTypeArray {
+		if idx, ok := runtime.ArrayIndex(key); ok && idx < len(right.Object.ArrayData) && right.Object.ArrayData[idx] != nil {
+			return runtime.True, signal{}
+		}
+	}
+	has, err := right.Object.HasPropertyErr(key)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return runtime.NewBool(has), signal{}
+}
+
+func (interp *Interpreter) evalLogical(e *ast.LogicalExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	left, sig := interp.evalExpression(e.Left, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	switch e.Operator {
+	case "&&":
+		if !left.ToBoolean() {
+			return left, signal{}
+		}
+		return interp.evalExpression(e.Right, env)
+	case "||":
+		if left.ToBoolean() {
+			return left, signal{}
+		}
+		return interp.evalExpression(e.Right, env)
+	case "??":
+		if left.Type != runtime.TypeNull && left.Type != runtime.TypeUndefined {
+			return left, signal{}
+		}
+		return interp.evalExpression(e.Right, env)
+	}
+	return left, signal{}
+}
+
+func (interp *Interpreter) evalAssignment(e *ast.AssignmentExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	ref, sig := interp.evalReference(e.Left, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	var right *runtime.Value
+	switch e.Operator {
+	case "=":
+		if right, sig = interp.evalExpression(e.Right, env); sig.typ != sigNone {
+			return nil, sig
+		}
+		if ident, ok := e.Left.(*ast.Identifier); ok {
+			nameFunction(e.Right, right, ident.Value)
+		}
+	case "&&=", "||=", "??=":
+		// Logical assignment only evaluates and stores the right operand
+		// when the target's current value does not decide the result.
+		old, sig := interp.getReference(ref, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		switch e.Operator {
+		case "&&=":
+			if !old.ToBoolean() {
+				return old, signal{}
+			}
+		case "||=":
+			if old.ToBoolean() {
+				return old, signal{}
+			}
+		default:
+			if !isNullish(old) {
+				return old, signal{}
+			}
+		}
+		if right, sig = interp.evalExpression(e.Right, env); sig.typ != sigNone {
+			return nil, sig
+		}
+	default:
+		old, sig := interp.getReference(ref, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if right, sig = interp.evalExpression(e.Right, env); sig.typ != sigNone {
+			return nil, sig
+		}
+		if old, right, sig = interp.primitiveOperands(e.Operator, old, right, env); sig.typ != sigNone {
+			return nil, sig
+		}
+		if e.Operator == "+=" && (old.Type == runtime.TypeString || right.Type == runtime.TypeString) {
+			if right, sig = interp.concatStrings(old, right, env); sig.typ != sigNone {
+				return nil, sig
+			}
+		} else {
+			right = interp.applyCompoundOp(e.Operator, old, right)
+		}
+	}
+
+	if sig := interp.putReference(ref, right, env); sig.typ != sigNone {
+		return nil, sig
+	}
+	return right, signal{}
+}
+
+// reference is an evaluated assignment target. For a member expression the
+// object and key have already been evaluated, so compound assignments and
+// updates read and write the target without evaluating them twice. It is
+// passed by value, since an assignment to a variable is too common to
+// allocate one.
+type reference struct {
+	target ast.Expression
+	base   *runtime.Value // object of a member target
+	key    string
+	this   *runtime.Value // receiver of a super reference
+}
+
+func (interp *Interpreter) evalReference(target ast.Expression, env *runtime.Environment) (reference, signal) {
+	member, ok := target.(*ast.MemberExpression)
+	if !ok {
+		return reference{target: target}, signal{}
+	}
+	if isSuperMember(member) {
+		return interp.superReference(member, env)
+	}
+	base, sig := interp.evalExpression(member.Object, env)
+	if sig.typ != sigNone {
+		return reference{}, sig
+	}
+	key, sig := interp.memberKey(member, env)
+	if sig.typ != sigNone {
+		return reference{}, sig
+	}
+	return reference{target: target, base: base, key: key}, signal{}
+}
+
+// superReference evaluates super[key] in a method: the key is looked up
+// on the prototype of the method's home object, with the method's this as
+// the receiver.
+func (interp *Interpreter) superReference(member *ast.MemberExpression, env *runtime.Environment) (reference, signal) {
+	superVal, err := env.Get("super")
+	var home *runtime.Object
+	if err == nil && superVal.Type == runtime.TypeObject && superVal.Object != nil && superVal.Object.Internal != nil {
+		home, _ = superVal.Object.Internal["homeObject"].(*runtime.Object)
+	}
+	if home == nil {
+		return reference{}, signal{typ: sigThrow, value: interp.makeErrorObject("SyntaxError", "'super' keyword unexpected here", env)}
+	}
+	thisVal, err := env.Get("this")
+	if err != nil {
+		return reference{}, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	key, sig := interp.memberKey(member, env)
+	if sig.typ != sigNone {
+		return reference{}, sig
+	}
+	base := runtime.Null
+	if home.Prototype != nil {
+		base = runtime.NewObject(home.Prototype)
+	}
+	return reference{target: member, base: base, key: key, this: thisVal}, signal{}
+}
+
+func (interp *Interpreter) getReference(ref reference, env *runtime.Environment) (*runtime.Value, signal) {
+	if ref.base == nil {
+		return interp.evalExpression(ref.target, env)
+	}
+	if ref.this != nil && ref.base.Type == runtime.TypeObject {
+		val, err := ref.base.Object.GetWithReceiver(ref.key, ref.this)
+		if err != nil {
+			return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+		return val, signal{}
+	}
+	return interp.getMember(ref.base, ref.key, env)
+}
+
+func (interp *Interpreter) putReference(ref reference, val *runtime.Value, env *runtime.Environment) signal {
+	if ref.base == nil {
+		return interp.assignToExpression(ref.target, val, env)
+	}
+	if ref.this != nil && ref.base.Type == runtime.TypeObject {
+		if err := ref.base.Object.SetWithReceiver(ref.key, val, ref.this); err != nil {
+			return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+		return signal{}
+	}
+	return interp.setMember(ref.base, ref.key, val, env.IsStrict(), env)
+}
+
+// homeEnv returns the environment in which the field initializers and
+// static blocks of home run. Its super binding carries home, as a method's
+// does, so that super.name in them starts its lookup at home's prototype.
+func (interp *Interpreter) homeEnv(home *runtime.Object, env *runtime.Environment) *runtime.Environment {
+	methodEnv := runtime.NewEnvironment(env, true)
+	methodEnv.Declare("super", "const", runtime.NewObject(interp.superBinding(home, nil)))
+	return methodEnv
+}
+
+// asMethod turns the function created for a method or accessor of home
+// into a method. Its home object is home, which super.name in it looks up
+// the prototype of, wherever the function is later copied to. Unlike
+// function declarations and expressions, methods have no [[Construct]]
+// and, unless they are generators, no prototype.
+func asMethod(fn *runtime.Value, home *runtime.Object) *runtime.Value {
+	if fn.Type != runtime.TypeObject || fn.Object == nil {
+		return fn
+	}
+	if fn.Object.Internal == nil {
+		fn.Object.Internal = make(map[string]interface{})
+	}
+	fn.Object.Internal["homeObject"] = home
+	if fn.Object.Constructor != nil {
+		fn.Object.Constructor = nil
+		delete(fn.Object.Properties, "prototype")
+	}
+	return fn
+}
+
+// nameFunction gives the function or class created by expr, if expr is an
+// anonymous function or class expression, the name of the binding or
+// property it is defined for, as in var f = function () {}. A symbol key
+// names it [description].
+func nameFunction(expr ast.Expression, val *runtime.Value, key string) {
+	switch expr.(type) {
+	case *ast.FunctionExpression, *ast.ArrowFunctionExpression, *ast.ClassExpression:
+	default:
+		return
+	}
+	if val == nil || val.Type != runtime.TypeObject || val.Object == nil {
+		return
+	}
+	if _, ok := val.Object.Properties["name"]; ok {
+		return
+	}
+	nameMethod(val, "", key)
+}
+
+// nameMethod names a method or accessor after its key; accessors are named
+// "get key" and "set key".
+func nameMethod(fn *runtime.Value, kind, key string) {
+	if fn == nil || fn.Type != runtime.TypeObject || fn.Object == nil {
+		return
+	}
+	name := key
+	if runtime.IsSymbolKey(key) {
+		name = "[" + runtime.KeyToValue(key).Symbol.Description + "]"
+	}
+	if kind == "get" || kind == "set" {
+		name = kind + " " + name
+	}
+	fn.Object.DefineProperty("name", &runtime.Property{Value: runtime.NewString(name), Configurable: true, HasValue: true})
+}
+
+// superBinding returns the value bound to super in a method of home. In a
+// derived constructor it is also callable as super(...).
+func (interp *Interpreter) superBinding(home *runtime.Object, call runtime.CallableFunc) *runtime.Object {
+	obj := runtime.NewFunctionObject(interp.realm.FunctionPrototype, call)
+	if call == nil {
+		obj = runtime.NewOrdinaryObject(nil)
+	}
+	obj.Internal = map[string]interface{}{"homeObject": home}
+	return obj
+}
+
+func (interp *Interpreter) destructureAssign(pattern *ast.ObjectPattern, val *runtime.Value, env *runtime.Environment) signal {
+	if val == nil || val.Type == runtime.TypeUndefined || val.Type == runtime.TypeNull {
+		return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Cannot destructure "+val.ToString(), env)}
+	}
+	if val.Type != runtime.TypeObject || val.Object == nil {
+		return signal{}
+	}
+	used := make(map[string]bool)
+	for _, prop := range pattern.Properties {
+		if rest, ok := prop.Value.(*ast.RestElement); ok {
+			restObj := runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)
+			if err := interp.copyDataProperties(restObj, val.Object, used); err != nil {
+				return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+			return interp.assignToExpression(rest.Argument, runtime.NewObject(restObj), env)
+		}
+		key, sig := interp.getPropertyKey(prop.Key, prop.Computed, env)
+		if sig.typ != sigNone {
+			return sig
+		}
+		used[key] = true
+		propVal := val.Object.Get(key)
+		target := prop.Value
+		if target == nil {
+			target = prop.Key
+		}
+		if ap, ok := target.(*ast.AssignmentPattern); ok {
+			if propVal == nil || propVal.Type == runtime.TypeUndefined {
+				var sig signal
+				propVal, sig = interp.evalExpression(ap.Right, env)
+				if sig.typ != sigNone {
+					return sig
+				}
+			}
+			target = ap.Left
+		}
+		if sig := interp.assignToExpression(target, propVal, env); sig.typ != sigNone {
+			return sig
+		}
+	}
+	return signal{}
+}
+
+func (interp *Interpreter) destructureAssignArray(pattern *ast.ArrayPattern, val *runtime.Value, env *runtime.Environment) signal {
+	elements, sig := interp.patternElements(pattern, val, env)
+	if sig.typ != sigNone {
+		return sig
+	}
+	for i, elem := range pattern.Elements {
+		if elem == nil {
+			continue
+		}
+		if rest, ok := elem.(*ast.RestElement); ok {
+			var restElems []*runtime.Value
+			if i < len(elements) {
+				restElems = append(restElems, elements[i:]...)
+			}
+			restArr := runtime.NewArrayObject(interp.realm.ArrayPrototype, restElems)
+			return interp.assignToExpression(rest.Argument, runtime.NewObject(restArr), env)
+		}
+		elemVal := runtime.Undefined
+		if i < len(elements) && elements[i] != nil {
+			elemVal = elements[i]
+		}
+		if ap, ok := elem.(*ast.AssignmentPattern); ok {
+			if elemVal.Type == runtime.TypeUndefined {
+				var sig signal
+				elemVal, sig = interp.evalExpression(ap.Right, env)
+				if sig.typ != sigNone {
+					return sig
+				}
+			}
+			elem = ap.Left
+		}
+		if sig := interp.assignToExpression(elem, elemVal, env); sig.typ != sigNone {
+			return sig
+		}
+	}
+	return signal{}
+}
+
+// patternElements returns the values an array pattern destructures from val.
+// Arrays with the built-in iterator are read directly; any other iterable (Map, Set, generators, custom
+// iterators) is stepped through the iterator protocol, only as far as the
+// pattern needs, and closed if it is not exhausted.
+func (interp *Interpreter) patternElements(pattern *ast.ArrayPattern, val *runtime.Value, env *runtime.Environment) ([]*runtime.Value, signal) {
+	if val == nil || val.Type == runtime.TypeUndefined || val.Type == runtime.TypeNull {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", val.ToString()+" is not iterable", env)}
+	}
+	if val.Type == runtime.TypeObject && interp.realm.HasDefaultIterator(val) {
+		return val.Object.ArrayValues(), signal{}
+	}
+	it, sig := interp.getIterator(val, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	want := len(pattern.Elements)
+	if want > 0 {
+		if _, ok := pattern.Elements[want-1].(*ast.RestElement); ok {
+			want = -1
+		}
+	}
+	var elements []*runtime.Value
+	for want < 0 || len(elements) < want {
+		elem, done, sig := it.step(env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if done {
+			return elements, signal{}
+		}
+		elements = append(elements, elem)
+	}
+	return elements, it.close(env)
+}
+
+// applyCompoundOp evaluates the numeric compound assignments; string
+// concatenation with += goes through concatStrings.
+func (interp *Interpreter) applyCompoundOp(op string, left, right *runtime.Value) *runtime.Value {
+	switch op {
+	case "+=":
+		return runtime.NewNumber(left.ToNumber() + right.ToNumber())
+	case "-=":
+		return runtime.NewNumber(left.ToNumber() - right.ToNumber())
+	case "*=":
+		return runtime.NewNumber(left.ToNumber() * right.ToNumber())
+	case "/=":
+		return runtime.NewNumber(left.ToNumber() / right.ToNumber())
+	case "%=":
+		return runtime.NewNumber(math.Mod(left.ToNumber(), right.ToNumber()))
+	case "**=":
+		return runtime.NewNumber(math.Pow(left.ToNumber(), right.ToNumber()))
+	case "&=":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) & int32(right.ToNumber())))
+	case "|=":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) | int32(right.ToNumber())))
+	case "^=":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) ^ int32(right.ToNumber())))
+	case "<<=":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) << (uint32(right.ToNumber()) & 0x1f)))
+	case ">>=":
+		return runtime.NewNumber(float64(int32(left.ToNumber()) >> (uint32(right.ToNumber()) & 0x1f)))
+	case ">>>=":
+		return runtime.NewNumber(float64(uint32(left.ToNumber()) >> (uint32(right.ToNumber()) & 0x1f)))
+	}
+	return right
+}
+
+func (interp *Interpreter) assignToExpression(expr ast.Expression, val *runtime.Value, env *runtime.Environment) signal {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		err := env.Set(e.Value, val)
+		if err != nil {
+			errMsg := err.Error()
+			if strings.Contains(errMsg, "TypeError") {
+				return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+			// An undeclared name becomes a global.
+			if err := env.SetImplicitGlobal(e.Value, val); err != nil {
+				return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+		}
+	case *ast.ObjectPattern:
+		return interp.destructureAssign(e, val, env)
+	case *ast.ArrayPattern:
+		return interp.destructureAssignArray(e, val, env)
+	case *ast.MemberExpression:
+		if isSuperMember(e) {
+			ref, sig := interp.superReference(e, env)
+			if sig.typ != sigNone {
+				return sig
+			}
+			return interp.putReference(ref, val, env)
+		}
+		obj, sig := interp.evalExpression(e.Object, env)
+		if sig.typ != sigNone {
+			return sig
+		}
+		key, sig := interp.memberKey(e, env)
+		if sig.typ != sigNone {
+			return sig
+		}
+		return interp.setMember(obj, key, val, env.IsStrict(), env)
+	default:
+		// The parser rejects other targets; this guards hand-built ASTs.
+		return signal{typ: sigThrow, value: interp.makeErrorObject("ReferenceError", "Invalid left-hand side in assignment", env)}
+	}
+	return signal{}
+}
+
+// isSuperMember reports whether e is super.name or super[key].
+func isSuperMember(e *ast.MemberExpression) bool {
+	_, ok := e.Object.(*ast.SuperExpression)
+	return ok
+}
+
+// memberKey evaluates the property key of a member expression. A computed
+// key is evaluated exactly once per call.
+func (interp *Interpreter) memberKey(e *ast.MemberExpression, env *runtime.Environment) (string, signal) {
+	if e.Computed {
+		keyVal, sig := interp.evalExpression(e.Property, env)
+		if sig.typ != sigNone {
+			return "", sig
+		}
+		return interp.toPropertyKey(keyVal, env)
+	}
+	if ident, ok := e.Property.(*ast.Identifier); ok {
+		return ident.Value, signal{}
+	}
+	// obj.#x is keyed by the private name, which getMember and setMember
+	// recognize.
+	if ident, ok := e.Property.(*ast.PrivateIdentifier); ok {
+		name, sig := interp.privateName(ident, env)
+		if sig.typ != sigNone {
+			return "", sig
+		}
+		return name.Key(), signal{}
+	}
+	return "", signal{}
+}
+
+func (interp *Interpreter) evalConditional(e *ast.ConditionalExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	test, sig := interp.evalExpression(e.Test, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	if test.ToBoolean() {
+		return interp.evalExpression(e.Consequent, env)
+	}
+	return interp.evalExpression(e.Alternate, env)
+}
+
+func (interp *Interpreter) evalCall(e *ast.CallExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	// Handle super() calls
+	if _, ok := e.Callee.(*ast.SuperExpression); ok {
+		return interp.evalSuperCall(e, env)
+	}
+
+	// Handle direct eval() calls — eval needs access to the calling scope
+	if ident, ok := e.Callee.(*ast.Identifier); ok && ident.Value == "eval" {
+		val, err := env.Get("eval")
+		if err == nil && val.Type == runtime.TypeObject && val.Object != nil &&
+			val.Object.Internal != nil && val.Object.Internal["isBuiltinEval"] != nil {
+			args, argSig := interp.evalArguments(e.Arguments, env)
+			if argSig.typ != sigNone {
+				return nil, argSig
+			}
+			return interp.evalDirectEval(args, env)
+		}
+	}
+
+	var thisVal *runtime.Value
+	var callee *runtime.Value
+	var sig signal
+
+	// determine this binding
+	name := ""
+	if member, ok := e.Callee.(*ast.MemberExpression); ok && isSuperMember(member) {
+		ref, refSig := interp.superReference(member, env)
+		if refSig.typ != sigNone {
+			return nil, refSig
+		}
+		name, thisVal = ref.key, ref.this
+		callee, sig = interp.getReference(ref, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+	} else if member, ok := e.Callee.(*ast.MemberExpression); ok {
+		thisVal, callee, name, sig = interp.evalMethod(member, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+	} else if member, ok := chainMember(e.Callee); ok {
+		// (o?.f)() calls f as a method of o, and calls undefined when the
+		// chain short-circuits.
+		thisVal, callee, name, sig = interp.evalMethod(member, env)
+		if sig.typ == sigShortCircuit {
+			thisVal, callee, sig = runtime.Undefined, runtime.Undefined, signal{}
+			if prop, ok := member.Property.(*ast.Identifier); ok && !member.Computed {
+				name = prop.Value
+			}
+		}
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+	} else {
+		callee, sig = interp.evalExpression(e.Callee, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		thisVal = runtime.Undefined
+		// A callee found on a with object is called as its method.
+		if ident, ok := e.Callee.(*ast.Identifier); ok {
+			if obj := env.WithBase(ident.Value); obj != nil {
+				thisVal = runtime.NewObject(obj)
+			}
+		}
+	}
+
+	if e.Optional && isNullish(callee) {
+		return nil, signal{typ: sigShortCircuit}
+	}
+	if callee == nil || callee.Type != runtime.TypeObject || callee.Object == nil || callee.Object.Callable == nil {
+		if ident, ok := e.Callee.(*ast.Identifier); ok {
+			name = ident.Value
+		}
+		if privateName := runtime.PrivateNameForKey(name); privateName != nil {
+			name = privateName.Description
+		}
+		interp.at(e)
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("%s is not a function", name), env)}
+	}
+
+	// evaluate arguments
+	args, argSig := interp.evalArguments(e.Arguments, env)
+	if argSig.typ != sigNone {
+		return nil, argSig
+	}
+
+	interp.at(e)
+	return interp.callFunction(callee, thisVal, args, env)
+}
+
+// evalMethod evaluates the callee of a method call: the object member
+// reads from, which becomes the this value of the call, the function read
+// and its key.
+func (interp *Interpreter) evalMethod(member *ast.MemberExpression, env *runtime.Environment) (thisVal, callee *runtime.Value, name string, sig signal) {
+	thisVal, sig = interp.evalExpression(member.Object, env)
+	if sig.typ != sigNone {
+		return nil, nil, "", sig
+	}
+	if member.Optional && isNullish(thisVal) {
+		return nil, nil, "", signal{typ: sigShortCircuit}
+	}
+	name, sig = interp.memberKey(member, env)
+	if sig.typ != sigNone {
+		return nil, nil, "", sig
+	}
+	callee, sig = interp.getMember(thisVal, name, env)
+	return thisVal, callee, name, sig
+}
+
+// chainMember returns the member access that ends the optional chain e,
+// if e is one that does, as in (o?.f)().
+func chainMember(e ast.Expression) (*ast.MemberExpression, bool) {
+	chain, ok := e.(*ast.ChainExpression)
+	if !ok {
+		return nil, false
+	}
+	member, ok := chain.Expression.(*ast.MemberExpression)
+	if !ok || isSuperMember(member) {
+		return nil, false
+	}
+	return member, true
+}
+
+// callFunction calls the function callee with the evaluated this value and
+// arguments.
+func (interp *Interpreter) callFunction(callee, thisVal *runtime.Value, args []*runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	// In non-strict mode, plain function calls (not method calls) get the global
+	// object as `this` instead of undefined. Arrow functions are excluded because
+	// they don't bind their own `this` (they inherit from enclosing scope).
+	if (thisVal == nil || thisVal == runtime.Undefined) && callee.Object != nil {
+		isArrow := callee.Object.Internal != nil && callee.Object.Internal["isArrow"] != nil
+		if !isArrow {
+			thisVal = interp.globalObject
+		}
+	}
+
+	result, err := callee.Object.Callable(thisVal, args)
+	if err != nil {
+		if jsErr, ok := err.(*jsError); ok {
+			return nil, signal{typ: sigThrow, value: jsErr.value}
+		}
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	if result == nil {
+		result = runtime.Undefined
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) evalSuperCall(e *ast.CallExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	superVal, err := env.Get("super")
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("ReferenceError", "super is not defined", env)}
+	}
+	if superVal.Type != runtime.TypeObject || superVal.Object == nil || superVal.Object.Callable == nil {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "super is not a function", env)}
+	}
+	thisVal, _ := env.Get("this")
+
+	args, argSig := interp.evalArguments(e.Arguments, env)
+	if argSig.typ != sigNone {
+		return nil, argSig
+	}
+
+	result, callErr := superVal.Object.Callable(thisVal, args)
+	if callErr != nil {
+		if jsErr, ok := callErr.(*jsError); ok {
+			return nil, signal{typ: sigThrow, value: jsErr.value}
+		}
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(callErr, env)}
+	}
+	if result == nil {
+		result = runtime.Undefined
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) evalArguments(arguments []ast.Expression, env *runtime.Environment) ([]*runtime.Value, signal) {
+	var args []*runtime.Value
+	for _, arg := range arguments {
+		if spread, ok := arg.(*ast.SpreadElement); ok {
+			arrVal, sig := interp.evalExpression(spread.Argument, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if arrVal.Type == runtime.TypeObject && interp.realm.HasDefaultIterator(arrVal) {
+				args = append(args, arrVal.Object.ArrayValues()...)
+				continue
+			}
+			if args, sig = interp.appendIterated(args, arrVal, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			continue
+		}
+		val, sig := interp.evalExpression(arg, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		args = append(args, val)
+	}
+	return args, signal{}
+}
+
+// evalChain evaluates an optional chain. A short-circuited chain evaluates
+// to undefined without evaluating the rest of its keys or call arguments.
+func (interp *Interpreter) evalChain(e *ast.ChainExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	val, sig := interp.evalExpression(e.Expression, env)
+	if sig.typ == sigShortCircuit {
+		return runtime.Undefined, signal{}
+	}
+	return val, sig
+}
+
+func isNullish(v *runtime.Value) bool {
+	return v == nil || v.Type == runtime.TypeUndefined || v.Type == runtime.TypeNull
+}
+
+func (interp *Interpreter) evalMember(e *ast.MemberExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	if isSuperMember(e) {
+		ref, sig := interp.superReference(e, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		return interp.getReference(ref, env)
+	}
+	obj, sig := interp.evalExpression(e.Object, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	if e.Optional && isNullish(obj) {
+		return nil, signal{typ: sigShortCircuit}
+	}
+	key, sig := interp.memberKey(e, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+	return interp.getMember(obj, key, env)
+}
+
+// getMember reads property key of obj. A getter that throws is reported
+// as a throw signal.
+func (interp *Interpreter) getMember(obj *runtime.Value, key string, env *runtime.Environment) (*runtime.Value, signal) {
+	if name := runtime.PrivateNameForKey(key); name != nil {
+		if obj == nil || obj.Type != runtime.TypeObject || obj.Object == nil {
+			return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("Cannot read private member %s from an object whose class did not declare it", name.Description), env)}
+		}
+		val, err := obj.Object.GetPrivate(name)
+		if err != nil {
+			return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+		return val, signal{}
+	}
+	if obj == nil || obj.Type == runtime.TypeUndefined || obj.Type == runtime.TypeNull {
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("Cannot read properties of %s (reading '%s')", obj.ToString(), key), env)}
+	}
+
+	var proto *runtime.Object
+	switch obj.Type {
+	case runtime.TypeString:
+		if val, ok := runtime.StringOwnProperty(obj.Str, key); ok {
+			return val, signal{}
+		}
+		proto = interp.realm.StringPrototype
+	case runtime.TypeObject:
+		if obj.Object == nil {
+			return runtime.Undefined, signal{}
+		}
+		// array length and index access
+		if obj.Object.OType == runtime.ObjTypeArray {
+			if key == "length" {
+				return runtime.NewNumber(float64(obj.Object.ArrayLength())), signal{}
+			}
+			// Holes and the elements kept as properties are looked up
+			// like any other property.
+			if idx, ok := runtime.ArrayIndex(key); ok && idx < len(obj.Object.ArrayData) && obj.Object.ArrayData[idx] != nil {
+				return obj.Object.ArrayData[idx], signal{}
+			}
+		}
+		proto = obj.Object
+	default:
+		proto = interp.realm.PrimitivePrototype(obj)
+	}
+	if proto == nil {
+		return runtime.Undefined, signal{}
+	}
+	val, err := proto.GetWithReceiver(key, obj)
+	if err != nil {
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return val, signal{}
+}
+
+// setMember assigns val to property key of obj. A setter that throws is
+// reported as a throw signal, and so, in strict mode code, is an assignment
+// that does not take effect, such as one to a frozen property.
+func (interp *Interpreter) setMember(obj *runtime.Value, key string, val *runtime.Value, strict bool, env *runtime.Environment) signal {
+	if name := runtime.PrivateNameForKey(key); name != nil {
+		if obj == nil || obj.Type != runtime.TypeObject || obj.Object == nil {
+			return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("Cannot write private member %s to an object whose class did not declare it", name.Description), env)}
+		}
+		if err := obj.Object.SetPrivate(name, val); err != nil {
+			return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+		}
+		return signal{}
+	}
+	if obj == nil || obj.Type == runtime.TypeUndefined || obj.Type == runtime.TypeNull {
+		return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", "Cannot set properties of "+obj.ToString()+" (setting '"+key+"')", env)}
+	}
+	if obj.Type != runtime.TypeObject || obj.Object == nil {
+		return signal{}
+	}
+	if obj.Object.OType == runtime.ObjTypeArray {
+		arr := obj.Object
+		if key == "length" {
+			n, err := runtime.ToArrayLength(val)
+			if err != nil {
+				return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+			if !arr.SetArrayLength(n) && strict {
+				msg := failedAssignmentMessage(arr, key)
+				if prop := arr.Properties["length"]; prop == nil || prop.Writable {
+					// An element that cannot be deleted stopped the truncation.
+					msg = failedDeleteMessage(arr, strconv.Itoa(arr.ArrayLength()-1))
+				}
+				return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", msg, env)}
+			}
+			return signal{}
+		}
+		// Elements defined as accessors or read-only are set like other
+		// properties.
+		if idx, ok := runtime.ArrayIndex(key); ok && arr.Properties[key] == nil {
+			if !arr.CanSetElement(idx) {
+				if strict {
+					return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", failedAssignmentMessage(arr, key), env)}
+				}
+				return signal{}
+			}
+			if n := len(arr.ArrayData); idx > n+runtime.MaxArrayGap {
+				if sig := interp.charge(runtime.PropertySize, env); sig.typ != sigNone {
+					return sig
+				}
+			} else if idx >= n {
+				if sig := interp.charge(int64(idx+1-n)*runtime.SlotSize, env); sig.typ != sigNone {
+					return sig
+				}
+			}
+			arr.SetArrayElement(idx, val)
+			return signal{}
+		}
+	}
+	if _, ok := obj.Object.Properties[key]; !ok {
+		if sig := interp.charge(runtime.PropertySize, env); sig.typ != sigNone {
+			return sig
+		}
+	}
+	ok, err := obj.Object.TrySet(key, val)
+	if err != nil {
+		return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	if !ok && strict {
+		return signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", failedAssignmentMessage(obj.Object, key), env)}
+	}
+	return signal{}
+}
+
+// failedAssignmentMessage explains why assigning property key of obj had
+// no effect, for the TypeError strict mode code throws.
+func failedAssignmentMessage(obj *runtime.Object, key string) string {
+	name := runtime.KeyToValue(key).ToString()
+	for o := obj; o != nil; o = o.Prototype {
+		if o.OType == runtime.ObjTypeProxy {
+			return fmt.Sprintf("'set' on proxy: trap returned falsish for property '%s'", name)
+		}
+		if prop := runtime.OwnProperty(o, key); prop != nil {
+			if prop.IsAccessor {
+				return fmt.Sprintf("Cannot set property %s which has only a getter", name)
+			}
+			return fmt.Sprintf("Cannot assign to read only property '%s' of object", name)
+		}
+	}
+	return fmt.Sprintf("Cannot add property %s, object is not extensible", name)
+}
+
+// failedDeleteMessage describes a delete of key from obj that strict mode
+// code rejects.
+func failedDeleteMessage(obj *runtime.Object, key string) string {
+	name := runtime.KeyToValue(key).ToString()
+	if obj.OType == runtime.ObjTypeProxy {
+		return fmt.Sprintf("'deleteProperty' on proxy: trap returned falsish for property '%s'", name)
+	}
+	if obj.OType == runtime.ObjTypeArray {
+		return fmt.Sprintf("Cannot delete property '%s' of [object Array]", name)
+	}
+	return fmt.Sprintf("Cannot delete property '%s' of [object Object]", name)
+}
+
+func (interp *Interpreter) evalNew(e *ast.NewExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	callee, sig := interp.evalExpression(e.Callee, env)
+	if sig.typ != sigNone {
+		return nil, sig
+	}
+
+	// Arrow functions, methods, async and generator functions and most
+	// builtin functions are callable but not constructors.
+	if callee.Type != runtime.TypeObject || callee.Object == nil || callee.Object.Constructor == nil {
+		name := "expression"
+		switch c := e.Callee.(type) {
+		case *ast.Identifier:
+			name = c.Value
+		case *ast.MemberExpression:
+			if prop, ok := c.Property.(*ast.Identifier); ok && !c.Computed {
+				name = prop.Value
+			}
+		}
+		interp.at(e)
+		return nil, signal{typ: sigThrow, value: interp.makeErrorObject("TypeError", fmt.Sprintf("%s is not a constructor", name), env)}
+	}
+
+	args, argSig := interp.evalArguments(e.Arguments, env)
+	if argSig.typ != sigNone {
+		return nil, argSig
+	}
+	interp.at(e)
+	if sig := interp.charge(runtime.ObjectSize, env); sig.typ != sigNone {
+		return nil, sig
+	}
+
+	result, err := interp.construct(callee.Object, args, callee.Object)
+	if err != nil {
+		if jsErr, ok := err.(*jsError); ok {
+			return nil, signal{typ: sigThrow, value: jsErr.value}
+		}
+		return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return result, signal{}
+}
+
+// construct creates an instance with the constructor callee, with
+// newTarget as new.target: the instance inherits from newTarget.prototype,
+// and an object the constructor returns replaces it. Constructing a bound
+// function constructs its target.
+func (interp *Interpreter) construct(callee *runtime.Object, args []*runtime.Value, newTarget *runtime.Object) (*runtime.Value, error) {
+	for {
+		target, ok := newTarget.Internal["boundTarget"].(*runtime.Object)
+		if !ok {
+			break
+		}
+		newTarget = target
+	}
+	proto := interp.realm.ObjectPrototype
+	protoProp, err := newTarget.GetErr("prototype")
+	if err != nil {
+		return nil, err
+	}
+	if protoProp.Type == runtime.TypeObject && protoProp.Object != nil {
+		proto = protoProp.Object
+	}
+	this := runtime.NewObject(runtime.NewOrdinaryObject(proto))
+
+	saved := interp.newTarget
+	interp.newTarget = runtime.NewObject(newTarget)
+	result, err := callee.Constructor(this, args)
+	interp.newTarget = saved
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && result.Type == runtime.TypeObject {
+		return result, nil
+	}
+	return this, nil
+}
+
+// takeNewTarget returns the new.target a constructor of fn was invoked
+// with and clears it, so that the functions the constructor calls do not
+// see it. A constructor a builtin invokes directly gets fn itself.
+func (interp *Interpreter) takeNewTarget(fn *runtime.Object) *runtime.Value {
+	newTarget := interp.newTarget
+	interp.newTarget = nil
+	if newTarget == nil {
+		return runtime.NewObject(fn)
+	}
+	return newTarget
+}
+
+// superConstruct runs super(...args) in a derived constructor: it runs the
+// constructor of parent on this, passing newTarget on, and returns the
+// value this is bound to afterwards. A builtin parent ignores this and
+// creates its own object instead; this then takes that object over,
+// keeping the prototype of the derived class. An object an interpreted
+// parent returns replaces this.
+func (interp *Interpreter) superConstruct(parent *runtime.Object, this *runtime.Value, args []*runtime.Value, newTarget *runtime.Value) (*runtime.Value, error) {
+	interp.newTarget = newTarget
+	result, err := parent.Constructor(this, args)
+	// Interpreted constructors take new.target when they start.
+	builtin := interp.newTarget == newTarget
+	interp.newTarget = nil
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || result.Type != runtime.TypeObject || result.Object == this.Object {
+		return this, nil
+	}
+	if !builtin {
+		return result, nil
+	}
+	proto := this.Object.Prototype
+	*this.Object = *result.Object
+	this.Object.Prototype = proto
+	return this, nil
+}
+
+func (interp *Interpreter) evalSequence(e *ast.SequenceExpression, env *runtime.Environment) (*runtime.Value, signal) {
+	var result *runtime.Value
+	for _, expr := range e.Expressions {
+		var sig signal
+		result, sig = interp.evalExpression(expr, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+	}
+	return result, signal{}
+}
+
+func (interp *Interpreter) evalTemplateLiteral(e *ast.TemplateLiteralExpr, env *runtime.Environment) (*runtime.Value, signal) {
+	var sb strings.Builder
+	for i, quasi := range e.Quasis {
+		sb.WriteString(quasi.Value)
+		if i < len(e.Expressions) {
+			val, sig := interp.evalExpression(e.Expressions[i], env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			str, sig := interp.toString(val, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			sb.WriteString(str)
+		}
+	}
+	if sig := interp.charge(int64(sb.Len()), env); sig.typ != sigNone {
+		return nil, sig
+	}
+	return runtime.NewString(sb.String()), signal{}
+}
+
+// syntaxErrorFromParse converts the errors reported by the parser for eval or
+// Function source into a single SyntaxError. The first error carries the
+// message and position; the rest are usually cascades and are only counted.
+func (interp *Interpreter) syntaxErrorFromParse(errs []error) *runtime.Value {
+	msg := errs[0].Error()
+	var serr *parser.SyntaxError
+	if errors.As(errs[0], &serr) {
+		msg = fmt.Sprintf("%s (%d:%d)", serr.Message, serr.Line, serr.Column)
+	}
+	if len(errs) == 2 {
+		msg += " and 1 more error"
+	} else if len(errs) > 2 {
+		msg += fmt.Sprintf(" and %d more errors", len(errs)-1)
+	}
+	// Resolve the constructor from the global scope so a local binding
+	// named SyntaxError cannot change what is thrown.
+	return interp.makeErrorObject("SyntaxError", msg, interp.global)
+}
+
+// evalCodeInEnv parses JS source code and evaluates it in the given environment.
+// Used by both direct eval and indirect eval.
+func (interp *Interpreter) evalCodeInEnv(code string, env *runtime.Environment) (*runtime.Value, signal) {
+	p := parser.New(code)
+	program, errs := p.ParseProgram()
+	if len(errs) > 0 {
+		return nil, signal{typ: sigThrow, value: interp.syntaxErrorFromParse(errs)}
+	}
+
+	// Use eval-specific hoisting: per B.3.3.3, Annex B block function hoisting
+	// in eval code should not skip names that are parameters in the enclosing function.
+	interp.hoistComprehensiveEval(program.Statements, env)
+
+	var result *runtime.Value
+	for _, stmt := range program.Statements {
+		val, sig := interp.execStatement(stmt, env)
+		if sig.typ != sigNone {
+			return nil, sig
+		}
+		if val != nil {
+			result = val
+		}
+	}
+	if result == nil {
+		return runtime.Undefined, signal{}
+	}
+	return result, signal{}
+}
+
+// evalDirectEval handles direct eval(code) calls with access to the calling scope.
+func (interp *Interpreter) evalDirectEval(args []*runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	if len(args) == 0 {
+		return runtime.Undefined, signal{}
+	}
+	if args[0].Type != runtime.TypeString {
+		return args[0], signal{}
+	}
+	return interp.evalCodeInEnv(args[0].Str, env)
+}
+
+// parseDynamicFunction parses the function that Function(p1, ..., body)
+// creates: the arguments before the last are joined into its parameter
+// list and the last is its body. A parse error becomes a SyntaxError whose
+// position is in the parameters or the body the caller wrote rather than in
+// the wrapper around them.
+func (interp *Interpreter) parseDynamicFunction(args []*runtime.Value) (*ast.FunctionDeclaration, error) {
+	var paramStr, bodyStr string
+	if len(args) == 1 {
+		bodyStr = args[0].ToString()
+	} else if len(args) > 1 {
+		params := make([]string, len(args)-1)
+		for i := 0; i < len(args)-1; i++ {
+			params[i] = args[i].ToString()
+		}
+		paramStr = strings.Join(params, ",")
+		bodyStr = args[len(args)-1].ToString()
+	}
+
+	const head = "function anonymous("
+	source := head + paramStr + "\n) {\n" + bodyStr + "\n}"
+	program, errs := parser.New(source).ParseProgram()
+	if len(errs) > 0 {
+		var serr *parser.SyntaxError
+		if errors.As(errs[0], &serr) {
+			errs = slices.Clone(errs)
+			moved := *serr
+			moved.Line, moved.Column = dynamicFunctionPosition(paramStr, bodyStr, len(head), serr.Line, serr.Column)
+			errs[0] = &moved
+		}
+		return nil, &jsError{value: interp.syntaxErrorFromParse(errs)}
+	}
+	if len(program.Statements) == 0 {
+		return nil, nil
+	}
+	funcDecl, _ := program.Statements[0].(*ast.FunctionDeclaration)
+	return funcDecl, nil
+}
+
+// dynamicFunctionPosition maps a line and column in the source that
+// parseDynamicFunction builds back to params or body. The head, of
+// headLen runes, precedes params on the first line, and params and body
+// each end with a line of the wrapper, which maps to their end.
+func dynamicFunctionPosition(params, body string, headLen, line, col int) (int, int) {
+	paramLines := strings.Count(params, "\n")
+	bodyLines := strings.Count(body, "\n")
+	end := func(s string) (int, int) {
+		return strings.Count(s, "\n") + 1, utf8.RuneCountInString(s[strings.LastIndexByte(s, '\n')+1:]) + 1
+	}
+	switch {
+	case line == 1:
+		return 1, max(col-headLen, 1)
+	case line <= paramLines+1:
+		return line, col
+	case line == paramLines+2:
+		return end(params)
+	case line <= paramLines+bodyLines+3:
+		return line - paramLines - 2, col
+	}
+	return end(body)
+}
+
+// makeFunctionConstructorImpl returns just the callable for the Function constructor.
+func (interp *Interpreter) makeFunctionConstructorImpl(env *runtime.Environment) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		funcDecl, err := interp.parseDynamicFunction(args)
+		if err != nil || funcDecl == nil {
+			return runtime.Undefined, err
+		}
+
+		fnVal := interp.createFunctionFromDecl(funcDecl, env)
+		return fnVal, nil
+	}
+}
+
+// makeFunctionConstructor creates the global Function constructor.
+func (interp *Interpreter) makeFunctionConstructor(env *runtime.Environment) *runtime.Value {
+	ctor := func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		funcDecl, err := interp.parseDynamicFunction(args)
+		if err != nil || funcDecl == nil {
+			return runtime.Undefined, err
+		}
+
+		// Function constructor creates functions that execute in the global scope
+		fnVal := interp.createFunctionFromDecl(funcDecl, env)
+		return fnVal, nil
+	}
+
+	fnObj := runtime.NewFunctionObject(interp.realm.FunctionPrototype, ctor)
+	fnObj.Constructor = ctor
+	fnObj.DefineProperty("prototype", &runtime.Property{
+		Value:        runtime.NewObject(runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)),
+		Writable:     true,
+		Enumerable:   false,
+		Configurable: false,
+	})
+	fnObj.DefineProperty("length", &runtime.Property{
+		Value:        runtime.NewNumber(1),
+		Writable:     false,
+		Enumerable:   false,
+		Configurable: true,
+	})
+	fnObj.DefineProperty("name", &runtime.Property{
+		Value:        runtime.NewString("Function"),
+		Writable:     false,
+		Enumerable:   false,
+		Configurable: true,
+	})
+	return runtime.NewObject(fnObj)
+}
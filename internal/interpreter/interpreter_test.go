@@ -0,0 +1,4165 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/builtins"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// newTestInterp returns a new interpreter with the builtins registered.
+func newTestInterp(tb testing.TB) *Interpreter {
+	tb.Helper()
+	interp := New()
+	builtins.RegisterAll(interp.Realm(), interp.GlobalEnv(), nil)
+	return interp
+}
+
+// evalExpect evaluates source with the builtins registered: arrays get
+// their methods from Array.prototype.
+func evalExpect(t *testing.T, source string) *runtime.Value {
+	t.Helper()
+	interp := newTestInterp(t)
+	val, err := interp.Eval(source)
+	if err != nil {
+		t.Fatalf("Eval error for %q: %v", source, err)
+	}
+	return val
+}
+
+func evalExpectError(t *testing.T, source string) error {
+	t.Helper()
+	interp := New()
+	_, err := interp.Eval(source)
+	if err == nil {
+		t.Fatalf("expected error for %q but got none", source)
+	}
+	return err
+}
+
+func expectNumber(t *testing.T, source string, expected float64) {
+	t.Helper()
+	val := evalExpect(t, source)
+	if val.Type != runtime.TypeNumber {
+		t.Fatalf("expected number for %q, got %v (type=%v)", source, val, val.Type)
+	}
+	if math.IsNaN(expected) {
+		if !math.IsNaN(val.Number) {
+			t.Fatalf("expected NaN for %q, got %v", source, val.Number)
+		}
+		return
+	}
+	if val.Number != expected {
+		t.Fatalf("expected %v for %q, got %v", expected, source, val.Number)
+	}
+}
+
+func expectString(t *testing.T, source string, expected string) {
+	t.Helper()
+	val := evalExpect(t, source)
+	if val.Type != runtime.TypeString {
+		t.Fatalf("expected string for %q, got type=%v val=%v", source, val.Type, val)
+	}
+	if val.Str != expected {
+		t.Fatalf("expected %q for %q, got %q", expected, source, val.Str)
+	}
+}
+
+func expectBool(t *testing.T, source string, expected bool) {
+	t.Helper()
+	val := evalExpect(t, source)
+	if val.Type != runtime.TypeBoolean {
+		t.Fatalf("expected boolean for %q, got type=%v", source, val.Type)
+	}
+	if val.Bool != expected {
+		t.Fatalf("expected %v for %q, got %v", expected, source, val.Bool)
+	}
+}
+
+func expectUndefined(t *testing.T, source string) {
+	t.Helper()
+	val := evalExpect(t, source)
+	if val.Type != runtime.TypeUndefined {
+		t.Fatalf("expected undefined for %q, got type=%v", source, val.Type)
+	}
+}
+
+func expectNull(t *testing.T, source string) {
+	t.Helper()
+	val := evalExpect(t, source)
+	if val.Type != runtime.TypeNull {
+		t.Fatalf("expected null for %q, got type=%v", source, val.Type)
+	}
+}
+
+// --- Literals ---
+
+func TestLiterals(t *testing.T) {
+	expectNumber(t, "42", 42)
+	expectNumber(t, "3.14", 3.14)
+	expectString(t, `"hello"`, "hello")
+	expectString(t, "'world'", "world")
+	expectBool(t, "true", true)
+	expectBool(t, "false", false)
+	expectNull(t, "null")
+	expectUndefined(t, "undefined")
+}
+
+// --- Arithmetic ---
+
+func TestArithmetic(t *testing.T) {
+	expectNumber(t, "2 + 3", 5)
+	expectNumber(t, "10 - 3", 7)
+	expectNumber(t, "4 * 5", 20)
+	expectNumber(t, "10 / 3", 10.0/3.0)
+	expectNumber(t, "10 % 3", 1)
+	expectNumber(t, "2 ** 10", 1024)
+	expectNumber(t, "-5", -5)
+	expectNumber(t, "+true", 1)
+}
+
+// --- String concatenation ---
+
+func TestStringConcat(t *testing.T) {
+	expectString(t, `"hello" + " " + "world"`, "hello world")
+	expectString(t, `"num: " + 42`, "num: 42")
+	expectString(t, `1 + "2"`, "12")
+}
+
+// --- Comparison operators ---
+
+func TestComparisons(t *testing.T) {
+	expectBool(t, "1 < 2", true)
+	expectBool(t, "2 > 1", true)
+	expectBool(t, "1 <= 1", true)
+	expectBool(t, "1 >= 2", false)
+	expectBool(t, "1 == 1", true)
+	expectBool(t, "1 == '1'", true)
+	expectBool(t, "1 === '1'", false)
+	expectBool(t, "1 === 1", true)
+	expectBool(t, "1 != 2", true)
+	expectBool(t, "1 !== '1'", true)
+	expectBool(t, "null == undefined", true)
+	expectBool(t, "null === undefined", false)
+}
+
+// --- Logical operators ---
+
+func TestLogical(t *testing.T) {
+	expectNumber(t, "1 && 2", 2)
+	expectNumber(t, "0 && 2", 0)
+	expectNumber(t, "1 || 2", 1)
+	expectNumber(t, "0 || 2", 2)
+	expectBool(t, "!true", false)
+	expectBool(t, "!false", true)
+	expectBool(t, "!0", true)
+	expectBool(t, "!1", false)
+}
+
+// --- Nullish coalescing ---
+
+func TestNullishCoalescing(t *testing.T) {
+	expectNumber(t, "null ?? 42", 42)
+	expectNumber(t, "undefined ?? 42", 42)
+	expectNumber(t, "0 ?? 42", 0)
+	expectString(t, `"" ?? "fallback"`, "")
+}
+
+// --- Variables ---
+
+func TestVariables(t *testing.T) {
+	expectNumber(t, "var x = 10; x", 10)
+	expectNumber(t, "let x = 20; x", 20)
+	expectNumber(t, "const x = 30; x", 30)
+	expectNumber(t, "var x = 1; x = 2; x", 2)
+}
+
+func TestConstAssignment(t *testing.T) {
+	err := evalExpectError(t, "const x = 1; x = 2")
+	if !strings.Contains(err.Error(), "constant") {
+		t.Fatalf("expected constant assignment error, got: %v", err)
+	}
+}
+
+func TestLetBlockScoping(t *testing.T) {
+	expectNumber(t, `
+		let x = 1;
+		{
+			let x = 2;
+			x;
+		}
+	`, 2)
+	expectNumber(t, `
+		let x = 1;
+		{
+			let x = 2;
+		}
+		x;
+	`, 1)
+}
+
+func TestVarHoisting(t *testing.T) {
+	expectUndefined(t, `
+		var x;
+		x;
+	`)
+	expectNumber(t, `
+		x = 5;
+		var x;
+		x;
+	`, 5)
+}
+
+// --- If/Else ---
+
+func TestIfElse(t *testing.T) {
+	expectNumber(t, "var x; if (true) { x = 1 } else { x = 2 } x", 1)
+	expectNumber(t, "var x; if (false) { x = 1 } else { x = 2 } x", 2)
+	expectNumber(t, `
+		var x;
+		if (false) { x = 1 }
+		else if (true) { x = 2 }
+		else { x = 3 }
+		x
+	`, 2)
+}
+
+// --- Ternary ---
+
+func TestTernary(t *testing.T) {
+	expectNumber(t, "true ? 1 : 2", 1)
+	expectNumber(t, "false ? 1 : 2", 2)
+	expectString(t, `1 > 0 ? "yes" : "no"`, "yes")
+}
+
+// --- While loop ---
+
+func TestWhileLoop(t *testing.T) {
+	expectNumber(t, `
+		var i = 0;
+		var sum = 0;
+		while (i < 5) {
+			sum = sum + i;
+			i = i + 1;
+		}
+		sum;
+	`, 10)
+}
+
+// --- Do-while loop ---
+
+func TestDoWhileLoop(t *testing.T) {
+	expectNumber(t, `
+		var i = 0;
+		do {
+			i = i + 1;
+		} while (i < 5);
+		i;
+	`, 5)
+}
+
+// --- For loop ---
+
+func TestForLoop(t *testing.T) {
+	expectNumber(t, `
+		var sum = 0;
+		for (var i = 0; i < 5; i++) {
+			sum = sum + i;
+		}
+		sum;
+	`, 10)
+}
+
+// --- Break and Continue ---
+
+func TestBreakContinue(t *testing.T) {
+	expectNumber(t, `
+		var sum = 0;
+		for (var i = 0; i < 10; i++) {
+			if (i === 5) break;
+			sum = sum + i;
+		}
+		sum;
+	`, 10)
+
+	expectNumber(t, `
+		var sum = 0;
+		for (var i = 0; i < 5; i++) {
+			if (i === 2) continue;
+			sum = sum + i;
+		}
+		sum;
+	`, 8)
+}
+
+// --- Functions ---
+
+func TestFunctionDeclaration(t *testing.T) {
+	expectNumber(t, `
+		function add(a, b) { return a + b; }
+		add(3, 4);
+	`, 7)
+}
+
+func TestFunctionExpression(t *testing.T) {
+	expectNumber(t, `
+		var mul = function(a, b) { return a * b; };
+		mul(3, 4);
+	`, 12)
+}
+
+func TestArrowFunction(t *testing.T) {
+	expectNumber(t, `
+		var add = (a, b) => a + b;
+		add(3, 4);
+	`, 7)
+
+	expectNumber(t, `
+		var square = (x) => { return x * x; };
+		square(5);
+	`, 25)
+}
+
+func TestClosure(t *testing.T) {
+	expectNumber(t, `
+		function makeCounter() {
+			var count = 0;
+			return function() {
+				count = count + 1;
+				return count;
+			};
+		}
+		var counter = makeCounter();
+		counter();
+		counter();
+		counter();
+	`, 3)
+}
+
+func TestDefaultParams(t *testing.T) {
+	expectNumber(t, `
+		function greet(x, y = 10) {
+			return x + y;
+		}
+		greet(5);
+	`, 15)
+
+	expectNumber(t, `
+		function greet(x, y = 10) {
+			return x + y;
+		}
+		greet(5, 20);
+	`, 25)
+}
+
+func TestRestParams(t *testing.T) {
+	expectNumber(t, `
+		function sum(...nums) {
+			var total = 0;
+			for (var i = 0; i < nums.length; i++) {
+				total = total + nums[i];
+			}
+			return total;
+		}
+		sum(1, 2, 3, 4, 5);
+	`, 15)
+}
+
+func TestParameterBinding(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"rest keeps explicit undefined", `
+			function f(a, ...rest) { return rest.length + ":" + typeof rest[0] + typeof rest[1]; }
+			f(1, undefined, undefined);`, "2:undefinedundefined"},
+		{"rest empty with fewer args", `
+			function f(a, b, ...rest) { return rest.length + ":" + b; }
+			f(1);`, "0:undefined"},
+		{"rest is a fresh array", `
+			function f(...rest) { rest.push(9); return rest.length; }
+			var args = [1, 2];
+			f(...args) + ":" + args.length;`, "3:2"},
+		{"rest pattern", `
+			function f(a, ...[b, c]) { return a + b + c; }
+			"" + f(1, 2, 3, 4);`, "6"},
+		{"explicit undefined takes the default", `
+			function f(a = 1, b = 2) { return a + ":" + b; }
+			f(undefined, null);`, "1:null"},
+		{"default sees earlier params", `
+			function f(a, b = a + 1, c = b * 2) { return a + ":" + b + ":" + c; }
+			f(1);`, "1:2:4"},
+		{"default reads later param", `
+			function f(a = b, b) { return a; }
+			try { f(); "no error"; } catch (e) { e.name; }`, "ReferenceError"},
+		{"default reads itself", `
+			function f(a = a) { return a; }
+			try { f(); "no error"; } catch (e) { e.name; }`, "ReferenceError"},
+		{"passed value skips the default", `
+			function f(a = b, b) { return a; }
+			"" + f(1);`, "1"},
+		{"arrow default reads later param", `
+			var f = (a = b, b = 1) => a;
+			try { f(); "no error"; } catch (e) { e.name; }`, "ReferenceError"},
+		{"default throws", `
+			function f(a = (function() { throw "boom"; })()) { return "body"; }
+			try { f(); } catch (e) { e; }`, "boom"},
+		{"destructuring undefined", `
+			function f({ a }) { return a; }
+			try { f(); "no error"; } catch (e) { e.name; }`, "TypeError"},
+		{"generator params bound at call", `
+			function* g(a = b, b) { yield a; }
+			try { g(); "no error"; } catch (e) { e.name; }`, "ReferenceError"},
+		{"class constructor default", `
+			class K { constructor(a = b, b) { this.a = a; } }
+			try { new K(); "no error"; } catch (e) { e.name; }`, "ReferenceError"},
+		{"duplicate names take the last value", `
+			function f(x, x) { return x; }
+			"" + f(1, 2);`, "2"},
+		{"param shadows function expression name", `
+			var f = function g(g) { g = g + 1; return g; };
+			"" + f(1);`, "2"},
+		{"arguments counts passed args only", `
+			function f(a, b) { return arguments.length + ":" + b; }
+			f(1) + "," + f(1, undefined);`, "1:undefined,2:undefined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectString(t, tt.src, tt.want)
+		})
+	}
+}
+
+func TestArgumentsObject(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"callee", `
+			function f() { return arguments.callee === f; }
+			"" + f();`, "true"},
+		{"only elements are enumerable", `
+			function f() { var keys = []; for (var k in arguments) keys.push(k); return keys.join(); }
+			f("a", "b");`, "0,1"},
+		{"assigning a parameter updates arguments", `
+			function f(a) { a = 2; return arguments[0]; }
+			"" + f(1);`, "2"},
+		{"assigning arguments updates the parameter", `
+			function f(a) { arguments[0] = 3; return a; }
+			"" + f(1);`, "3"},
+		{"missing arguments are not mapped", `
+			function f(a, b) { b = 2; return arguments.length + ":" + arguments[1]; }
+			f(1);`, "1:undefined"},
+		{"delete unmaps", `
+			function f(a) { delete arguments[0]; a = 2; arguments[0] = 3; return a + "," + arguments[0]; }
+			f(1);`, "2,3"},
+		{"defaults leave arguments unmapped", `
+			function f(a = 0) { a = 2; return arguments[0]; }
+			"" + f(1);`, "1"},
+		{"repeated names map the last position", `
+			function f(a, a) { a = 3; return arguments[0] + "," + arguments[1]; }
+			f(1, 2);`, "1,3"},
+		{"strict functions are unmapped", `
+			function f(a) { "use strict"; a = 2; arguments[0] = 3; return a + "," + arguments[0]; }
+			f(1);`, "2,3"},
+		{"strict callee throws", `
+			function f() { "use strict"; try { return arguments.callee; } catch (e) { return e.name; } }
+			f();`, "TypeError"},
+		{"functions in strict code are strict", `
+			"use strict";
+			function f(a) { a = 2; return arguments[0]; }
+			"" + f(1);`, "1"},
+		{"class methods are strict", `
+			class C { m(a) { a = 2; return arguments[0]; } }
+			"" + new C().m(1);`, "1"},
+		{"a directive after a statement is not strict", `
+			function f(a) { var x; "use strict"; a = 2; return arguments[0]; }
+			"" + f(1);`, "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectString(t, tt.src, tt.want)
+		})
+	}
+}
+
+func TestRecursion(t *testing.T) {
+	expectNumber(t, `
+		function fib(n) {
+			if (n <= 1) return n;
+			return fib(n - 1) + fib(n - 2);
+		}
+		fib(10);
+	`, 55)
+
+	expectNumber(t, `
+		function fact(n) {
+			if (n <= 1) return 1;
+			return n * fact(n - 1);
+		}
+		fact(5);
+	`, 120)
+}
+
+func TestFunctionHoisting(t *testing.T) {
+	expectNumber(t, `
+		var result = add(3, 4);
+		function add(a, b) { return a + b; }
+		result;
+	`, 7)
+}
+
+// --- Arrow function lexical this ---
+
+func TestArrowLexicalThis(t *testing.T) {
+	expectNumber(t, `
+		var obj = {
+			x: 10,
+			getX: function() {
+				var inner = () => this.x;
+				return inner();
+			}
+		};
+		obj.getX();
+	`, 10)
+}
+
+// --- Typeof ---
+
+func TestTypeof(t *testing.T) {
+	expectString(t, `typeof 42`, "number")
+	expectString(t, `typeof "hello"`, "string")
+	expectString(t, `typeof true`, "boolean")
+	expectString(t, `typeof undefined`, "undefined")
+	expectString(t, `typeof null`, "object")
+	expectString(t, `typeof nonexistent`, "undefined")
+	expectString(t, `typeof function(){}`, "function")
+}
+
+// --- Objects ---
+
+func TestObjectLiteral(t *testing.T) {
+	expectNumber(t, `
+		var obj = { x: 1, y: 2 };
+		obj.x + obj.y;
+	`, 3)
+}
+
+func TestObjectPropertyAccess(t *testing.T) {
+	expectNumber(t, `
+		var obj = { x: 10 };
+		obj["x"];
+	`, 10)
+
+	expectNumber(t, `
+		var key = "x";
+		var obj = { x: 42 };
+		obj[key];
+	`, 42)
+}
+
+func TestObjectMutation(t *testing.T) {
+	expectNumber(t, `
+		var obj = { x: 1 };
+		obj.x = 10;
+		obj.x;
+	`, 10)
+
+	expectNumber(t, `
+		var obj = {};
+		obj.y = 42;
+		obj.y;
+	`, 42)
+}
+
+func TestObjectShorthand(t *testing.T) {
+	expectNumber(t, `
+		var x = 10;
+		var obj = { x };
+		obj.x;
+	`, 10)
+}
+
+func TestObjectMethod(t *testing.T) {
+	expectNumber(t, `
+		var obj = {
+			x: 5,
+			getX: function() { return this.x; }
+		};
+		obj.getX();
+	`, 5)
+}
+
+// --- Arrays ---
+
+func TestArrayLiteral(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		arr[0];
+	`, 1)
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		arr.length;
+	`, 3)
+}
+
+func TestArrayMethods(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		arr.push(4);
+		arr.length;
+	`, 4)
+
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		arr.pop();
+	`, 3)
+
+	expectString(t, `
+		var arr = [1, 2, 3];
+		arr.join("-");
+	`, "1-2-3")
+
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		arr.indexOf(2);
+	`, 1)
+
+	expectBool(t, `
+		var arr = [1, 2, 3];
+		arr.includes(2);
+	`, true)
+}
+
+func TestArrayIndexOfFromIndex(t *testing.T) {
+	expectNumber(t, `[1, 2, 3, 2, 1].indexOf(2, 2)`, 3)
+	expectNumber(t, `[1, 2, 3, 2, 1].indexOf(2, -2)`, 3)
+	expectNumber(t, `[1, 2, 3, 2, 1].indexOf(2, -100)`, 1)
+	expectNumber(t, `[1, 2, 3, 2, 1].indexOf(2, Infinity)`, -1)
+	expectNumber(t, `[1, 2, 3, 2, 1].lastIndexOf(2)`, 3)
+	expectNumber(t, `[1, 2, 3, 2, 1].lastIndexOf(2, 2)`, 1)
+	expectNumber(t, `[1, 2, 3, 2, 1].lastIndexOf(2, -3)`, 1)
+	expectNumber(t, `[1, 2, 3, 2, 1].lastIndexOf(2, -100)`, -1)
+	expectNumber(t, `[undefined].indexOf()`, 0)
+}
+
+func TestArrayMap(t *testing.T) {
+	expectString(t, `
+		var arr = [1, 2, 3];
+		var doubled = arr.map(function(x) { return x * 2; });
+		doubled.join(",");
+	`, "2,4,6")
+}
+
+func TestArrayFilter(t *testing.T) {
+	expectString(t, `
+		var arr = [1, 2, 3, 4, 5];
+		var evens = arr.filter(function(x) { return x % 2 === 0; });
+		evens.join(",");
+	`, "2,4")
+}
+
+func TestArrayReduce(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3, 4, 5];
+		arr.reduce(function(acc, x) { return acc + x; }, 0);
+	`, 15)
+}
+
+func TestArrayPrototypeMethods(t *testing.T) {
+	// Calls on arrays go through Array.prototype, so its methods and
+	// overrides of them are reachable, and deleted ones are gone.
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var a = [1, 2, 3];
+		var out = [];
+		out.push(a.reduceRight(function(acc, x) { return acc + x; }, ""));
+		for (var k of a.keys()) out.push("k" + k);
+		for (var [i, v] of a.entries()) out.push(i + ":" + v);
+		out.push([...a.values()].length);
+		out.push(Object.prototype.toString.call(a.values()));
+		var it = a.values();
+		out.push(it[Symbol.iterator]() === it);
+		var push = Array.prototype.push;
+		Array.prototype.push = function() { return "patched"; };
+		var patched = a.push(4);
+		Array.prototype.push = push;
+		out.push(patched, a.length);
+		var map = Array.prototype.map;
+		delete Array.prototype.map;
+		try { a.map(String); } catch (e) { out.push(e.name); }
+		Array.prototype.map = map;
+		out.join(",");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "321,k0,k1,k2,0:1,1:2,2:3,3,[object Array Iterator],true,patched,3,TypeError"
+	if val.Str != want {
+		t.Errorf("got %q, want %q", val.Str, want)
+	}
+}
+
+func TestPrimitivePrototypes(t *testing.T) {
+	// Properties of primitives are looked up on the prototype of their
+	// wrapper, which scripts can extend.
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		String.prototype.shout = function() { return this.toUpperCase() + "!"; };
+		Number.prototype.double = function() { return this * 2; };
+		var g = Object.getPrototypeOf;
+		[
+			"hi".shout(), (4).double(), Symbol("s").toString(), Symbol("d").description,
+			g("") === String.prototype, g(1) === Number.prototype,
+			g(false) === Boolean.prototype, g(Symbol()) === Symbol.prototype,
+		].join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HI!,8,Symbol(s),d,true,true,true,true"
+	if val.Str != want {
+		t.Errorf("got %q, want %q", val.Str, want)
+	}
+}
+
+func TestWrapperObjects(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var n = new Number(5), b = new Boolean(false), s = new String("ab");
+		var toString = Object.prototype.toString;
+		[
+			typeof n, typeof b, typeof Object(true), n.valueOf(), b.valueOf(), String(b),
+			n == 5, n === 5, n === Object(n), new Number(1) === new Number(1),
+			Object(1) instanceof Number, Object(false) instanceof Boolean, 1 instanceof Number,
+			toString.call(n), toString.call(b), toString.call(2), toString.call(s),
+			JSON.stringify([n, b, s]), n * 2, !!b, (5).toFixed(2), true.toString(),
+		].join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "object,object,object,5,false,false,true,false,true,false,true,true,false," +
+		"[object Number],[object Boolean],[object Number],[object String]," +
+		`[5,false,"ab"],10,true,5.00,true`
+	if val.Str != want {
+		t.Errorf("got %q\nwant %q", val.Str, want)
+	}
+}
+
+func TestArrayForEach(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		var sum = 0;
+		arr.forEach(function(x) { sum = sum + x; });
+		sum;
+	`, 6)
+}
+
+func TestArraySpread(t *testing.T) {
+	expectNumber(t, `
+		var arr1 = [1, 2];
+		var arr2 = [3, 4];
+		var arr3 = [...arr1, ...arr2];
+		arr3.length;
+	`, 4)
+}
+
+// --- Switch ---
+
+func TestSwitch(t *testing.T) {
+	expectNumber(t, `
+		var x = 2;
+		var result;
+		switch (x) {
+			case 1: result = 10; break;
+			case 2: result = 20; break;
+			case 3: result = 30; break;
+			default: result = 0;
+		}
+		result;
+	`, 20)
+}
+
+func TestSwitchDefault(t *testing.T) {
+	expectNumber(t, `
+		var x = 99;
+		var result;
+		switch (x) {
+			case 1: result = 10; break;
+			default: result = 0;
+		}
+		result;
+	`, 0)
+}
+
+func TestSwitchFallThrough(t *testing.T) {
+	expectNumber(t, `
+		var x = 1;
+		var result = 0;
+		switch (x) {
+			case 1: result = result + 1;
+			case 2: result = result + 2; break;
+			case 3: result = result + 3;
+		}
+		result;
+	`, 3)
+}
+
+// --- Try/Catch/Finally ---
+
+func TestTryCatch(t *testing.T) {
+	expectNumber(t, `
+		var result;
+		try {
+			throw 42;
+		} catch (e) {
+			result = e;
+		}
+		result;
+	`, 42)
+}
+
+func TestTryFinally(t *testing.T) {
+	expectNumber(t, `
+		var x = 0;
+		try {
+			x = 1;
+		} finally {
+			x = x + 10;
+		}
+		x;
+	`, 11)
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	expectNumber(t, `
+		var result = 0;
+		try {
+			throw "error";
+		} catch (e) {
+			result = 1;
+		} finally {
+			result = result + 10;
+		}
+		result;
+	`, 11)
+}
+
+func TestThrowString(t *testing.T) {
+	err := evalExpectError(t, `throw "my error"`)
+	if !strings.Contains(err.Error(), "my error") {
+		t.Fatalf("expected 'my error', got: %v", err)
+	}
+}
+
+func TestNestedTryCatch(t *testing.T) {
+	expectNumber(t, `
+		var result = 0;
+		try {
+			try {
+				throw 1;
+			} catch (e) {
+				result = e;
+				throw 2;
+			}
+		} catch (e) {
+			result = result + e;
+		}
+		result;
+	`, 3)
+}
+
+// --- Typeof ---
+
+func TestTypeofOperator(t *testing.T) {
+	expectString(t, `typeof 42`, "number")
+	expectString(t, `typeof "str"`, "string")
+	expectString(t, `typeof true`, "boolean")
+	expectString(t, `typeof undefined`, "undefined")
+	expectString(t, `typeof null`, "object")
+	expectString(t, `typeof {}`, "object")
+	expectString(t, `typeof []`, "object")
+	expectString(t, `typeof function(){}`, "function")
+}
+
+// --- Delete ---
+
+func TestDelete(t *testing.T) {
+	expectUndefined(t, `
+		var obj = { x: 1, y: 2 };
+		delete obj.x;
+		obj.x;
+	`)
+}
+
+// --- Void ---
+
+func TestVoid(t *testing.T) {
+	expectUndefined(t, `void 0`)
+	expectUndefined(t, `void "hello"`)
+}
+
+// --- In operator ---
+
+func TestInOperator(t *testing.T) {
+	expectBool(t, `
+		var obj = { x: 1, y: 2 };
+		"x" in obj;
+	`, true)
+
+	expectBool(t, `
+		var obj = { x: 1 };
+		"z" in obj;
+	`, false)
+}
+
+// --- Instanceof ---
+
+func TestInstanceof(t *testing.T) {
+	expectBool(t, `
+		function Foo() {}
+		var f = new Foo();
+		f instanceof Foo;
+	`, true)
+}
+
+// --- Bitwise operators ---
+
+func TestBitwiseOps(t *testing.T) {
+	expectNumber(t, "5 & 3", 1)
+	expectNumber(t, "5 | 3", 7)
+	expectNumber(t, "5 ^ 3", 6)
+	expectNumber(t, "~5", -6)
+	expectNumber(t, "1 << 3", 8)
+	expectNumber(t, "8 >> 1", 4)
+}
+
+// --- Update expressions ---
+
+func TestUpdateExpressions(t *testing.T) {
+	expectNumber(t, `
+		var x = 5;
+		x++;
+		x;
+	`, 6)
+	expectNumber(t, `
+		var x = 5;
+		x--;
+		x;
+	`, 4)
+	expectNumber(t, `
+		var x = 5;
+		++x;
+	`, 6)
+	expectNumber(t, `
+		var x = 5;
+		--x;
+	`, 4)
+}
+
+func TestPostfixReturnValue(t *testing.T) {
+	expectNumber(t, `
+		var x = 5;
+		x++;
+	`, 5)
+}
+
+// --- Compound assignment ---
+
+func TestCompoundAssignment(t *testing.T) {
+	expectNumber(t, "var x = 10; x += 5; x", 15)
+	expectNumber(t, "var x = 10; x -= 3; x", 7)
+	expectNumber(t, "var x = 4; x *= 3; x", 12)
+	expectNumber(t, "var x = 10; x /= 2; x", 5)
+	expectNumber(t, "var x = 10; x %= 3; x", 1)
+}
+
+func TestParenthesizedAssignmentTarget(t *testing.T) {
+	expectNumber(t, "var a = 0; (a) = 1; a", 1)
+	expectNumber(t, "var a = 1; ((a)) += 2; a", 3)
+	expectNumber(t, "var o = {}; (o.p) = 2; (o['q']) = 3; o.p + o.q", 5)
+	expectNumber(t, "var a = 1; (a)++; ++(a); a", 3)
+	expectNumber(t, "var x; (x = 5); x", 5)
+	expectNumber(t, "var x, y; x = (y = 3, 4); x * 10 + y", 43)
+	expectNumber(t, "var a, b; ({ a, b } = { a: 3, b: 4 }); a * b", 12)
+	expectNumber(t, "var a; [(a)] = [7]; a", 7)
+}
+
+// --- Sequence expression ---
+
+func TestSequenceExpression(t *testing.T) {
+	expectNumber(t, "(1, 2, 3)", 3)
+}
+
+// --- Template literals ---
+
+func TestTemplateLiteral(t *testing.T) {
+	expectString(t, "`hello world`", "hello world")
+	expectString(t, "var x = 42; `the answer is ${x}`", "the answer is 42")
+	expectString(t, "var a = 1; var b = 2; `${a} + ${b} = ${a + b}`", "1 + 2 = 3")
+}
+
+// --- For-of ---
+
+func TestForOf(t *testing.T) {
+	expectNumber(t, `
+		var arr = [10, 20, 30];
+		var sum = 0;
+		for (var x of arr) {
+			sum = sum + x;
+		}
+		sum;
+	`, 60)
+}
+
+func TestForOfString(t *testing.T) {
+	expectNumber(t, `
+		var count = 0;
+		for (var ch of "hello") {
+			count = count + 1;
+		}
+		count;
+	`, 5)
+}
+
+// --- For-in ---
+
+func TestForIn(t *testing.T) {
+	expectBool(t, `
+		var obj = { a: 1, b: 2, c: 3 };
+		var keys = [];
+		for (var k in obj) {
+			keys.push(k);
+		}
+		keys.includes("a") && keys.includes("b") && keys.includes("c");
+	`, true)
+}
+
+func TestForInOrder(t *testing.T) {
+	expectString(t, `
+		function Base() {}
+		Base.prototype.inherited = 1;
+		Base.prototype.b = 2;
+		var obj = new Base();
+		obj.b = 1; obj.a = 2; obj[2] = 3; obj[1] = 4;
+		var keys = [];
+		for (var k in obj) keys.push(k);
+		keys.join();
+	`, "1,2,b,a,inherited")
+	// Integer keys come first in ascending order, then the other strings,
+	// "-1" and "01" among them, in insertion order.
+	expectString(t, `
+		var obj = { b: 1, 2: 1, a: 1, 1: 1, "-1": 1, "01": 1 };
+		var keys = [];
+		for (var k in obj) keys.push(k);
+		[keys.join(), Object.keys(obj).join(), Reflect.ownKeys(obj).join(), JSON.stringify(obj)].join(" ");
+	`, `1,2,b,a,-1,01 1,2,b,a,-1,01 1,2,b,a,-1,01 {"1":1,"2":1,"b":1,"a":1,"-1":1,"01":1}`)
+	expectString(t, `
+		class A { constructor() { this.x = 1; } m() {} get g() { return 1; } }
+		class B extends A { n() {} }
+		var keys = [];
+		for (var k in new B()) keys.push(k);
+		keys.join();
+	`, "x")
+}
+
+func TestForInOfAssignmentTargets(t *testing.T) {
+	expectString(t, `
+		var k = "none";
+		for (k in { a: 1, b: 2 }) {}
+		k;
+	`, "b")
+	expectNumber(t, `
+		var o = {};
+		for (o.last of [1, 2, 3]) {}
+		o.last;
+	`, 3)
+	expectNumber(t, `
+		var arr = [];
+		for (arr[arr.length] of [4, 5]) {}
+		arr[0] + arr[1];
+	`, 9)
+	expectNumber(t, `
+		var a, b, sum = 0;
+		for ([a, b] of [[1, 2], [3, 4]]) { sum = sum + a * b; }
+		sum + a + b;
+	`, 21)
+	expectNumber(t, `
+		var o = {};
+		for ({ x: o.y = 5, z } of [{ z: 1 }]) {}
+		var z;
+		o.y + z;
+	`, 6)
+	expectString(t, `
+		var msg;
+		try { for (null.x of [1]) {} } catch (e) { msg = e.message; }
+		msg;
+	`, "Cannot set properties of null (setting 'x')")
+	expectBool(t, `
+		const c = 1;
+		var threw = false;
+		try { for (c of [2]) {} } catch (e) { threw = true; }
+		threw && c === 1;
+	`, true)
+	expectBool(t, `
+		var a, threw = false;
+		try { for ({ a } of [null]) {} } catch (e) { threw = e.name === "TypeError"; }
+		threw;
+	`, true)
+}
+
+func TestEvalSyntaxError(t *testing.T) {
+	expectString(t, `
+		var r;
+		try { eval("var x = ;"); } catch (e) { r = e.name + ": " + e.message; }
+		r;
+	`, `SyntaxError: unexpected token ; (";") (1:9)`)
+	expectString(t, `
+		var r;
+		try { Function("a", "return ("); } catch (e) { r = e.name; }
+		r;
+	`, "SyntaxError")
+	// Positions in Function source are in the body or parameters as written.
+	expectString(t, `
+		var r;
+		try { Function("x", "var ok = 1;\n  return 1 +;"); } catch (e) { r = e.message; }
+		r;
+	`, `unexpected token ; (";") (2:13)`)
+	expectBool(t, `
+		var r;
+		try { Function("a", "b c", "return a"); } catch (e) { r = e.message; }
+		r.indexOf('got IDENTIFIER ("c") (1:5)') !== -1;
+	`, true)
+	msg := evalExpect(t, `
+		var msg;
+		try { eval("(1 +"); } catch (e) { msg = e.message; }
+		msg;
+	`).ToString()
+	if strings.Contains(msg, "\n") || strings.Contains(msg, "parse error") {
+		t.Errorf("eval syntax error message %q", msg)
+	}
+	expectString(t, `
+		function f() {
+			var SyntaxError = "shadowed";
+			try { eval("}"); } catch (e) { return e.name; }
+		}
+		f();
+	`, "SyntaxError")
+	for _, src := range []string{`/(/`, `/a/gg`} {
+		msg := evalExpect(t, `
+			var msg;
+			try { `+src+`; } catch (e) { msg = e.name + ": " + e.message; }
+			msg;
+		`).ToString()
+		if !strings.HasPrefix(msg, "SyntaxError: ") || strings.Contains(msg, "SyntaxError: SyntaxError") {
+			t.Errorf("%s: regular expression syntax error %q", src, msg)
+		}
+	}
+}
+
+func TestAutomaticSemicolonInsertion(t *testing.T) {
+	expectUndefined(t, "function f(x) { return\n x; }\nf(1);")
+	expectString(t, "var a = 1, b = 1\na\n++b\na + \",\" + b;", "1,2")
+	// No semicolon is inserted before a line that starts with (.
+	expectString(t, "var i = 0\nvar f = function () { return i }\n(i++)\ntypeof f;", "number")
+	expectString(t, `
+		var r;
+		try { eval("throw\\n1"); } catch (e) { r = e.name; }
+		r;
+	`, "SyntaxError")
+	for _, src := range []string{"var f = a\n=> a;", "var f = (a, b)\n=> a;", "var f = async a\n=> a;"} {
+		if _, err := New().Eval(src); err == nil || !strings.Contains(err.Error(), "=>") {
+			t.Errorf("%q: expected a syntax error at =>, got %v", src, err)
+		}
+	}
+	expectNumber(t, "var f = a =>\n a + 1;\nf(1);", 2)
+}
+
+// --- Destructuring ---
+
+func TestArrayDestructuring(t *testing.T) {
+	expectNumber(t, `
+		var [a, b, c] = [1, 2, 3];
+		a + b + c;
+	`, 6)
+}
+
+func TestObjectDestructuring(t *testing.T) {
+	expectNumber(t, `
+		var { x, y } = { x: 10, y: 20 };
+		x + y;
+	`, 30)
+}
+
+func TestDestructuringDefaults(t *testing.T) {
+	expectNumber(t, `
+		var [a, b = 5] = [1];
+		a + b;
+	`, 6)
+}
+
+func TestDestructuringRest(t *testing.T) {
+	expectNumber(t, `
+		var [first, ...rest] = [1, 2, 3, 4];
+		rest.length;
+	`, 3)
+}
+
+func TestObjectRestAndSpreadOrder(t *testing.T) {
+	expectString(t, `
+		var src = { b: 1, a: 2, 1: 3 };
+		var { a, ...rest } = src;
+		var keys = [];
+		for (var k in rest) keys.push(k);
+		for (var k in { ...src, c: 5 }) keys.push(k);
+		keys.join();
+	`, "1,b,1,b,a,c")
+	expectString(t, `
+		var a, rest, keys = [];
+		({ a, ...rest } = { z: 1, a: 2, y: 3 });
+		for (var k in rest) keys.push(k);
+		keys.join();
+	`, "z,y")
+	expectNumber(t, `
+		class A { m() {} }
+		var copy = { ...{ get x() { return 7; } }, ...new A() };
+		var n = 0;
+		for (var k in copy) n++;
+		copy.x * 10 + n;
+	`, 71)
+}
+
+// --- Classes ---
+
+func TestClassBasic(t *testing.T) {
+	expectNumber(t, `
+		class Point {
+			constructor(x, y) {
+				this.x = x;
+				this.y = y;
+			}
+			sum() {
+				return this.x + this.y;
+			}
+		}
+		var p = new Point(3, 4);
+		p.sum();
+	`, 7)
+}
+
+func TestClassInheritance(t *testing.T) {
+	expectString(t, `
+		class Animal {
+			constructor(name) {
+				this.name = name;
+			}
+			speak() {
+				return this.name + " makes a sound";
+			}
+		}
+		class Dog extends Animal {
+			constructor(name) {
+				super(name);
+			}
+			speak() {
+				return this.name + " barks";
+			}
+		}
+		var d = new Dog("Rex");
+		d.speak();
+	`, "Rex barks")
+}
+
+func TestClassBasePrototypes(t *testing.T) {
+	// A base class inherits from Function.prototype and its instances
+	// from Object.prototype, which super looks up.
+	expectString(t, `
+		class A {
+			toString() { return "A:" + super.toString(); }
+			has(k) { return super.hasOwnProperty.call(this, k); }
+			static describe() { return typeof super.call; }
+		}
+		var a = new A();
+		a.x = 1;
+		[String(a), a.has("x"), a.valueOf() === a, A.describe()].join();
+	`, "A:[object Object],true,true,function")
+	expectString(t, `
+		class Base {}
+		class Null extends null {}
+		[new Base().toString(), Object.getPrototypeOf(Base) === Function.prototype,
+		 Object.getPrototypeOf(Base.prototype) === Object.prototype,
+		 Object.getPrototypeOf(Null) === Function.prototype, Object.getPrototypeOf(Null.prototype)].join();
+	`, "[object Object],true,true,true,")
+}
+
+func TestClassStaticMethods(t *testing.T) {
+	expectNumber(t, `
+		class MathHelper {
+			static add(a, b) {
+				return a + b;
+			}
+		}
+		MathHelper.add(3, 4);
+	`, 7)
+}
+
+func TestClassGetterSetter(t *testing.T) {
+	expectNumber(t, `
+		class Circle {
+			constructor(r) {
+				this._r = r;
+			}
+			get radius() {
+				return this._r;
+			}
+			set radius(val) {
+				this._r = val;
+			}
+		}
+		var c = new Circle(5);
+		c.radius = 10;
+		c.radius;
+	`, 10)
+}
+
+func TestClassInstanceof(t *testing.T) {
+	expectBool(t, `
+		class Foo {}
+		class Bar extends Foo {}
+		var b = new Bar();
+		b instanceof Foo;
+	`, true)
+}
+
+// --- New operator ---
+
+func TestNewOperator(t *testing.T) {
+	expectNumber(t, `
+		function Point(x, y) {
+			this.x = x;
+			this.y = y;
+		}
+		var p = new Point(3, 4);
+		p.x + p.y;
+	`, 7)
+}
+
+func TestNewTarget(t *testing.T) {
+	expectBool(t, `function F() { return new.target === F; } F();`, false)
+	expectBool(t, `function F() { this.ok = new.target === F; } new F().ok;`, true)
+	expectUndefined(t, `function F() { return new.target; } F();`)
+	// Arrow functions see the new.target of the enclosing function.
+	expectBool(t, `function F() { this.ok = (() => new.target)() === F; } new F().ok;`, true)
+	// A base constructor sees the derived class it was invoked for.
+	expectBool(t, `
+		class A { constructor() { this.nt = new.target; } }
+		class B extends A {}
+		class C extends A { constructor() { super(); } }
+		new A().nt === A && new B().nt === B && new C().nt === C;
+	`, true)
+	err := evalExpectError(t, `new.target;`)
+	if !strings.Contains(err.Error(), "new.target expression is not allowed here") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCallAndConstructDistinction(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"class called", `class A {} A();`, "Class constructor A cannot be invoked without 'new'"},
+		{"arrow", `var f = () => 1; new f();`, "f is not a constructor"},
+		{"method", `var o = { m() {} }; new o.m();`, "m is not a constructor"},
+		{"class method", `class A { m() {} } new (new A().m)();`, "m is not a constructor"},
+		{"async function", `async function f() {} new f();`, "f is not a constructor"},
+		{"generator", `function* g() {} new g();`, "g is not a constructor"},
+		{"extends non-constructor", `var o = { m() {} }; class A extends o.m {}`, "Class extends value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evalExpectError(t, tt.src)
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+	expectUndefined(t, `var o = { m() {} }; o.m.prototype;`)
+	expectString(t, `class A { static s() { return "s"; } } A.s();`, "s")
+}
+
+func TestClassFields(t *testing.T) {
+	expectNumber(t, `class A { x = 1; y = this.x + 1; } new A().y;`, 2)
+	expectUndefined(t, `class A { x; } new A().x;`)
+	expectBool(t, `class A { x = 1; } "x" in new A();`, true)
+	// Computed keys are evaluated once, when the class is defined.
+	expectString(t, `var n = 0; class A { ["k" + n++] = n; } new A(); var a = new A(); n + ":" + a.k0;`, "1:1")
+	// Static fields see the class as this and by name.
+	expectNumber(t, `class S { static a = 1; static b = S.a + 1; static c = this.b + 1; } S.c;`, 3)
+	// Arrow functions in initializers keep the instance as this.
+	expectNumber(t, `class F { v = 5; get = () => this.v; } var g = new F().get; g();`, 5)
+	// Base class fields exist before the constructor body runs; derived
+	// class fields are added when super returns.
+	expectString(t, `
+		class A { a = "a"; constructor() { this.seen = this.a + this.b; } }
+		class B extends A { b = "b"; constructor() { super(); this.after = this.b; } }
+		var o = new B();
+		o.seen + ":" + o.after;
+	`, "aundefined:b")
+	expectString(t, `class A { x = "field"; } class B extends A {} new B().x;`, "field")
+}
+
+func TestClassStaticBlocks(t *testing.T) {
+	// Static blocks run with the static fields, in order, with the class
+	// as this and their own var scope.
+	expectString(t, `
+		var log = [];
+		class A {
+			static x = 1;
+			static { log.push(this.x, this === A); this.y = this.x + 1; var v = 1; }
+			static z = A.y * 10;
+			static #secret = 42;
+			static { log.push(A.z, A.#secret, typeof v); }
+			static secret() { return A.#secret; }
+		}
+		class B extends A { static { log.push(super.secret()); } }
+		log.join();
+	`, "1,true,20,42,undefined,42")
+	expectString(t, `
+		var msg;
+		try { class C { static { throw "boom"; } } } catch (e) { msg = e; }
+		msg;
+	`, "boom")
+	expectUndefined(t, `class D { static { this.nt = new.target; } } D.nt;`)
+}
+
+func TestPrivateMembers(t *testing.T) {
+	prelude := `
+		class Counter {
+			#count = 0;
+			static #instances = 0;
+			constructor() { Counter.#instances++; }
+			get #doubled() { return this.#count * 2; }
+			set #doubled(v) { this.#count = v / 2; }
+			#bump() { return ++this.#count; }
+			inc() { this.#bump(); this.#count += 1; return this.#count; }
+			get doubled() { return this.#doubled; }
+			set doubled(v) { this.#doubled = v; }
+			static instances() { return Counter.#instances; }
+			static isCounter(o) { return #count in o; }
+			static count(o) { return o.#count; }
+		}
+		var c = new Counter();
+	`
+	expectNumber(t, prelude+`c.inc();`, 2)
+	expectNumber(t, prelude+`c.inc(); c.doubled;`, 4)
+	expectNumber(t, prelude+`c.doubled = 10; c.inc();`, 7)
+	expectNumber(t, prelude+`new Counter(); Counter.instances();`, 2)
+	expectBool(t, prelude+`Counter.isCounter(c) && !Counter.isCounter({});`, true)
+	expectUndefined(t, prelude+`c["#count"];`)
+	expectBool(t, prelude+`class Sub extends Counter {} Counter.isCounter(new Sub());`, true)
+
+	errors := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"foreign object", prelude + `Counter.count({});`, "Cannot read private member #count from an object whose class did not declare it"},
+		{"in on primitive", prelude + `Counter.isCounter(1);`, "Cannot use 'in' operator"},
+		{"assign method", `class M { #m() {} t() { this.#m = 1; } } new M().t();`, "Private method #m is not writable"},
+		{"getter only", `class G { get #g() { return 1; } t() { this.#g = 1; } } new G().t();`, "'#g' was defined without a setter"},
+		{"undeclared", `class U { t() { return this.#nope; } } new U().t();`, "Private field '#nope' must be declared in an enclosing class"},
+		// Each evaluation of a class creates new private names.
+		{"separate evaluations", `
+			function make() { return class { #x = 1; static get(o) { return o.#x; } }; }
+			var A = make(), B = make();
+			A.get(new B());
+		`, "Cannot read private member #x"},
+		{"initialized twice", `
+			class Base { constructor(o) { return o; } }
+			class Stamp extends Base { #tag = 1; }
+			var o = {};
+			new Stamp(o);
+			new Stamp(o);
+		`, "Cannot initialize #tag twice on the same object"},
+	}
+	for _, tt := range errors {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evalExpectError(t, tt.src)
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubclassingBuiltins(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var log = [];
+		class E extends Error { constructor(m) { super(m); this.name = "E"; } }
+		var e = new E("boom");
+		log.push(e.message, e instanceof E, e instanceof Error, String(e));
+		class M extends Map { first() { return this.keys().next().value; } }
+		var m = new M([["k", 1]]);
+		log.push(m instanceof M, m.get("k"), m.first());
+		class L extends Array {}
+		var l = new L();
+		l.push(1, 2);
+		log.push(l instanceof L, Array.isArray(l), l.length);
+		function F(a, b) { this.sum = a + b; this.nt = new.target === F; }
+		var G = F.bind(null, 1);
+		var g = new G(2);
+		log.push(g instanceof F, g.sum, g.nt);
+		var r = Reflect.construct(F, [1, 1], L);
+		log.push(r instanceof L, r.sum, r.nt);
+		class K { constructor() { this.nt = new.target; } }
+		class D extends K {}
+		log.push(Reflect.construct(K, [], L).nt === L, Reflect.construct(D, [], L).nt === L);
+		for (var call of ["Map()", "Set()", "WeakMap()", "Promise(function () {})", "new Symbol()"]) {
+			try { eval(call); log.push("no error"); } catch (err) { log.push(err.name); }
+		}
+		log.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "boom,true,true,E: boom,true,1,k,true,true,2,true,3,true,true,2,false,true,true,TypeError,TypeError,TypeError,TypeError,TypeError"
+	if val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+}
+
+func TestSparseArrays(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var out = [];
+		var a = [1, 2, 3, 4];
+		a.length = 1;
+		out.push(a.length, a[1], 1 in a);
+		var b = [1, , 3];
+		out.push(1 in b, b.length, b.indexOf(undefined), b.includes(undefined), b.map(function (x) { return x * 2; }).join("-"));
+		var c = [];
+		c[4294967294] = "last";
+		out.push(c.length, Object.keys(c).join(), c[4294967294]);
+		c.length = 0;
+		out.push(c.length, c[4294967294]);
+		try { a.length = -1; } catch (e) { out.push(e.name); }
+		var n = 0;
+		[0, , , 3].forEach(function () { n++; });
+		out.push(n, [...[, 1]].length, JSON.stringify([, 1]));
+		out.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1,,false,false,3,-1,true,2--6,4294967295,4294967294,last,0,,RangeError,2,2,[null,1]"
+	if val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.EvalFile("main.js", `function inner() { return new Error("boom"); }
+var outer = () => inner();
+var stacks = [outer().stack];
+class K { m() { null.x; } }
+try { new K().m(); } catch (e) { stacks.push(e.stack); }
+stacks.join("\n--\n");`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Error: boom
+    at inner (main.js:1:27)
+    at outer (main.js:2:19)
+    at main.js:3:15
+--
+TypeError: Cannot read properties of null (reading 'x')
+    at m (main.js:4:17)
+    at main.js:5:7`
+	if val.ToString() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", val.ToString(), want)
+	}
+
+	// An uncaught exception reports where it was thrown.
+	_, err = interp.EvalFile("main.js", "function f() {\n  throw new TypeError('bad');\n}\nf();")
+	if err == nil || err.Error() != "TypeError: bad at main.js:2:3" {
+		t.Errorf("uncaught error: got %v", err)
+	}
+	_, err = interp.Eval("try { throw 1; } catch (e) {}\nvar e = new Error('again');\n\nthrow e;")
+	if err == nil || err.Error() != "Error: again at <anonymous>:4:1" {
+		t.Errorf("rethrown error: got %v", err)
+	}
+}
+
+func TestFunctionNameInference(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var f = function () {};
+		let g = () => 1;
+		var o = { h() {}, get p() { return 1; }, k: function () {}, [Symbol.iterator]() {} };
+		class A { m() {} static s() {} }
+		var C = class {};
+		var n;
+		n = () => 0;
+		[f.name, g.name, o.h.name, Object.getOwnPropertyDescriptor(o, "p").get.name, o.k.name,
+			o[Symbol.iterator].name, A.name, new A().m.name, A.s.name, C.name, n.name].join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "f,g,h,get p,k,[Symbol.iterator],A,m,s,C,n"; val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+	// A function that has a name keeps it.
+	expectString(t, `var f = function named() {}; f.name;`, "named")
+}
+
+// --- Native functions ---
+
+func TestGlobalsPersistAcrossRuns(t *testing.T) {
+	interp := New()
+	if _, err := interp.Eval(`var a = 1; let b = 2; const c = 3; function f() { return a + b + c; } class K {}`); err != nil {
+		t.Fatal(err)
+	}
+	program, err := Compile(`b += 1; f() + ":" + typeof K;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"7:function", "8:function"} {
+		val, err := interp.Run(program)
+		if err != nil || val.ToString() != want {
+			t.Errorf("Run: got %v, %v, want %s", val, err, want)
+		}
+	}
+	if _, err := interp.Eval(`let b = 0;`); err == nil || !strings.Contains(err.Error(), "Identifier 'b' has already been declared") {
+		t.Errorf("redeclaring a global let: unexpected error %v", err)
+	}
+	if _, err := Compile(`var = 1;`); err == nil {
+		t.Error("Compile should report parse errors")
+	}
+}
+
+func TestRunProgramOnManyInterpreters(t *testing.T) {
+	program, err := Compile(`function scale(x) { let y = x * factor; return () => y + offset; } scale(2)();`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []Mode{TreeWalk, Compiled} {
+		for _, c := range []struct {
+			globals string
+			want    float64
+		}{
+			{`var factor = 10, offset = 1;`, 21},
+			{`let factor = 3; const offset = 0.5;`, 6.5},
+		} {
+			interp := New()
+			interp.SetMode(mode)
+			if _, err := interp.Eval(c.globals); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2; i++ {
+				val, err := interp.Run(program)
+				if err != nil || val.Number != c.want {
+					t.Errorf("mode %v, %s: got %v, %v, want %v", mode, c.globals, val, err, c.want)
+				}
+			}
+		}
+	}
+}
+
+func TestGlobalObject(t *testing.T) {
+	interp := newTestInterp(t)
+	src := `
+		var log = [];
+		log.push(globalThis === this, typeof globalThis.Array, globalThis.globalThis === globalThis);
+		globalThis.fromProp = 1;
+		log.push(fromProp);
+		function leak() { implicit = 2; }
+		leak();
+		log.push(globalThis.implicit, delete implicit, typeof implicit);
+		var kept = 3;
+		log.push(delete kept, delete globalThis.kept, kept);
+		let hidden = 4;
+		log.push("hidden" in globalThis);
+		Object.defineProperty(globalThis, "acc", { get() { return "got"; }, configurable: true });
+		log.push(acc);
+		fromProp = 5;
+		log.push(globalThis.fromProp, delete fromProp, typeof fromProp);
+		log.join();`
+	val, err := interp.Eval(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "true,function,true,1,2,true,undefined,false,false,3,false,got,5,true,undefined"
+	if val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+	if _, err := interp.Eval(`undeclared`); err == nil || !strings.Contains(err.Error(), "ReferenceError") {
+		t.Errorf("reading an undeclared name: unexpected error %v", err)
+	}
+
+	// Feature detection sees the builtins and the script's declarations.
+	val, err = interp.Eval(`
+		function declared() {}
+		[typeof globalThis.Promise, "Map" in globalThis, globalThis.hasOwnProperty("JSON"),
+			typeof globalThis.declared, globalThis.kept, Object.keys(globalThis).includes("Array"),
+			Object.keys(globalThis).includes("declared")].join();`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "function,true,true,function,3,false,true"; val.ToString() != want {
+		t.Errorf("feature detection: got %q, want %q", val.ToString(), want)
+	}
+}
+
+func TestRegisterNative(t *testing.T) {
+	interp := New()
+	interp.RegisterNative("add", func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		if len(args) < 2 {
+			return runtime.NewNumber(0), nil
+		}
+		return runtime.NewNumber(args[0].ToNumber() + args[1].ToNumber()), nil
+	})
+	val, err := interp.Eval("add(3, 4)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.Number != 7 {
+		t.Fatalf("expected 7, got %v", val.Number)
+	}
+}
+
+func TestNewRealm(t *testing.T) {
+	interp := New()
+	builtins.RegisterAll(interp.Realm(), interp.GlobalEnv(), interp.GlobalObject().Object)
+	realm := interp.NewRealm()
+	builtins.RegisterAll(realm.Realm(), realm.GlobalEnv(), realm.GlobalObject().Object)
+	interp.RegisterNative("host", func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		return runtime.NewString("host"), nil
+	})
+	if _, err := interp.Eval(`Array.prototype.sum = function () { return this.reduce((a, b) => a + b, 0); }; var mine = [1, 2];`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := realm.Eval(`var theirs = [3, 4]; function isArray(a) { return a instanceof Array; }`); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := realm.Eval(`[host(), typeof [].sum, typeof mine, Array.isArray(theirs)].join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "host,undefined,undefined,true"; val.ToString() != want {
+		t.Errorf("new realm: got %q, want %q", val.ToString(), want)
+	}
+
+	// An object keeps the prototypes of the realm it was created in, and a
+	// function runs with the built-ins of its own realm.
+	theirs, _ := realm.GlobalEnv().Get("theirs")
+	isArray, _ := realm.GlobalEnv().Get("isArray")
+	interp.GlobalEnv().Declare("theirs", "var", theirs)
+	interp.GlobalEnv().Declare("isArray", "var", isArray)
+	val, err = interp.Eval(`[typeof theirs.sum, isArray(mine), isArray([]), mine.sum(), [5].sum()].join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "undefined,false,false,3,5"; val.ToString() != want {
+		t.Errorf("across realms: got %q, want %q", val.ToString(), want)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	type point struct {
+		X, Y float64
+		Tag  string `js:"tag"`
+	}
+	interp := newTestInterp(t)
+	interp.RegisterFunc("scale", func(p point, k float64) point {
+		return point{X: p.X * k, Y: p.Y * k, Tag: p.Tag + "!"}
+	})
+	interp.RegisterFunc("parse", func(s string) (int, error) {
+		if s == "" {
+			return 0, errors.New("RangeError: empty input")
+		}
+		return len(s), nil
+	})
+	interp.RegisterFunc("join", func(sep string, parts ...string) string {
+		return strings.Join(parts, sep)
+	})
+	interp.RegisterFunc("apply", func(f func(int) int, xs []int) []int {
+		for i, x := range xs {
+			xs[i] = f(x)
+		}
+		return xs
+	})
+	val, err := interp.Eval(`
+		var p = scale({ X: 1, Y: 2, tag: "a" }, 3);
+		var msg, typeMsg;
+		try { parse(""); } catch (e) { msg = e.name + ": " + e.message; }
+		try { parse(1); } catch (e) { typeMsg = e.name; }
+		[p.X, p.Y, p.tag, parse("abc"), msg, typeMsg, join("-", "a", "b", "c"), join("+"), apply(function (x) { return x * x; }, [1, 2, 3]).join(" ")].join(",");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "3,6,a!,3,RangeError: empty input,TypeError,a-b-c,,1 4 9"; val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+	if err := interp.RegisterFunc("bad", 42); err == nil {
+		t.Error("RegisterFunc should reject a non-function")
+	}
+}
+
+type testStore struct {
+	Name  string `js:"name"`
+	items map[string]int
+}
+
+func (s *testStore) Put(key string, n int) { s.items[key] = n }
+
+func (s *testStore) Get(key string) (int, error) {
+	n, ok := s.items[key]
+	if !ok {
+		return 0, errors.New("RangeError: no item " + key)
+	}
+	return n, nil
+}
+
+func (s *testStore) Sum(keys ...string) int {
+	total := 0
+	for _, k := range keys {
+		total += s.items[k]
+	}
+	return total
+}
+
+func TestSetGlobal(t *testing.T) {
+	interp := newTestInterp(t)
+	store := &testStore{Name: "main", items: map[string]int{}}
+	if err := interp.SetGlobal("store", store); err != nil {
+		t.Fatal(err)
+	}
+	interp.SetGlobal("limit", 3)
+	val, err := interp.Eval(`
+		store.Put("a", 1);
+		store.Put("b", limit);
+		var msg;
+		try { store.Get("c"); } catch (e) { msg = e.name + ": " + e.message; }
+		store.name = "renamed";
+		[store.Get("b"), store.Sum("a", "b"), store.Sum(), msg, JSON.stringify(store), typeof store.items].join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `3,4,0,RangeError: no item c,{"name":"renamed"},undefined`; val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+	if store.Name != "renamed" || store.items["a"] != 1 {
+		t.Errorf("the Go struct was not updated: %+v", store)
+	}
+}
+
+func TestRegisterNativeObject(t *testing.T) {
+	interp := newTestInterp(t)
+	var logged []string
+	interp.RegisterNativeObject("host", map[string]runtime.CallableFunc{
+		"readConfig": func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			return runtime.NewString("config:" + args[0].ToString()), nil
+		},
+		"log": func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			logged = append(logged, args[0].ToString())
+			return runtime.Undefined, nil
+		},
+	}, NativeMethodOption{Method: "readConfig", Length: 1, ReadOnly: true, Enumerable: true})
+	val, err := interp.Eval(`
+		host.log(host.readConfig("app"));
+		host.readConfig = null;
+		var keys = [];
+		for (var k in host) keys.push(k);
+		[typeof readConfig, host.readConfig.name, host.readConfig.length, host.log.length, keys.join("|")].join(",");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "undefined,readConfig,1,0,readConfig"; val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+	if len(logged) != 1 || logged[0] != "config:app" {
+		t.Errorf("unexpected log %v", logged)
+	}
+}
+
+// --- String methods ---
+
+func TestStringMethods(t *testing.T) {
+	expectString(t, `"hello".toUpperCase()`, "HELLO")
+	expectString(t, `"HELLO".toLowerCase()`, "hello")
+	expectNumber(t, `"hello".indexOf("ll")`, 2)
+	expectString(t, `"hello".slice(1, 3)`, "el")
+	expectString(t, `"  hello  ".trim()`, "hello")
+	expectBool(t, `"hello world".includes("world")`, true)
+	expectBool(t, `"hello".startsWith("hel")`, true)
+	expectBool(t, `"hello".endsWith("llo")`, true)
+	expectString(t, `"ha".repeat(3)`, "hahaha")
+	expectString(t, `"hello world".replace("world", "earth")`, "hello earth")
+	expectNumber(t, `"hello".length`, 5)
+	expectNumber(t, `"a,b,c".split(",").length`, 3)
+	expectString(t, `"hello".charAt(0)`, "h")
+	expectString(t, `"hello".charAt(4)`, "o")
+}
+
+func TestStringPrototypeMonkeyPatch(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var out = [];
+		String.prototype.shout = function () { return this.toUpperCase() + "!"; };
+		out.push("hi".shout());
+		var charAt = String.prototype.charAt;
+		String.prototype.charAt = function (i) { return "<" + charAt.call(this, i) + ">"; };
+		out.push("abc".charAt(1), "abc"[1], "abc".length);
+		String.prototype.charAt = charAt;
+		out.push("abc".charAt(1), "x".hasOwnProperty("length"), "x".hasOwnProperty("shout"));
+		out.join();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HI!,<b>,b,3,b,true,false"
+	if val.ToString() != want {
+		t.Errorf("got %q, want %q", val.ToString(), want)
+	}
+}
+
+func TestStringCodeUnits(t *testing.T) {
+	expectNumber(t, `"héllo".length`, 5)
+	expectString(t, `"héllo"[1]`, "é")
+	expectNumber(t, `"a😀b".length`, 4)
+	expectString(t, `"a😀b"[3]`, "b")
+	expectString(t, `"a😀b".slice(3)`, "b")
+	expectNumber(t, `"a😀b".indexOf("b")`, 3)
+	expectNumber(t, `[..."a😀b"].length`, 3)
+	expectNumber(t, `var n = 0; for (var c of "😀\uD800") n++; n`, 2)
+}
+
+func TestStringHalvesJoin(t *testing.T) {
+	// Strings of the same code units are equal, however they were built.
+	expectBool(t, `"\ud83d" + "\ude00" === "😀"`, true)
+	expectBool(t, `var s = "\ud83d"; s += "\ude00"; s === "😀"`, true)
+	expectBool(t, `"😀".slice(0, 1) + "😀".slice(1) === "😀"`, true)
+	expectNumber(t, `[..."😀".slice(0, 1) + "😀".slice(1)].length`, 1)
+	expectBool(t, `"😀".split("").join("") === "😀"`, true)
+	expectBool(t, `["\ud83d", "\ude00"].join("") === "😀"`, true)
+	expectBool(t, `var hi = "\ud83d", lo = "\ude00"; `+"`${hi}${lo}`"+` === "😀"`, true)
+	expectBool(t, `"\ud83d".concat("\ude00") === "😀"`, true)
+	expectBool(t, `String.fromCharCode(0xd83d) + String.fromCharCode(0xde00) === "😀"`, true)
+	expectBool(t, `var m = new Map([["😀", 1]]); m.has("\ud83d" + "\ude00")`, true)
+	expectNumber(t, `var o = {"😀": 1}; o["😀".slice(0, 1) + "😀".slice(1)]`, 1)
+
+	// A low surrogate followed by a high one is no pair.
+	expectNumber(t, `("\ude00" + "\ud83d").length`, 2)
+	expectNumber(t, `[..."\ude00" + "\ud83d"].length`, 2)
+}
+
+// --- This binding ---
+
+func TestThisInMethod(t *testing.T) {
+	expectNumber(t, `
+		var obj = {
+			value: 42,
+			getValue: function() { return this.value; }
+		};
+		obj.getValue();
+	`, 42)
+}
+
+// --- Higher-order functions ---
+
+func TestHigherOrderFunctions(t *testing.T) {
+	expectNumber(t, `
+		function apply(fn, x) {
+			return fn(x);
+		}
+		function double(n) { return n * 2; }
+		apply(double, 5);
+	`, 10)
+}
+
+// --- IIFE ---
+
+func TestIIFE(t *testing.T) {
+	expectNumber(t, `
+		var result = (function() { return 42; })();
+		result;
+	`, 42)
+}
+
+// --- Complex scenarios ---
+
+func TestFibonacciWithClosure(t *testing.T) {
+	expectNumber(t, `
+		function memoize(fn) {
+			var cache = {};
+			return function(n) {
+				var key = "" + n;
+				if (cache[key] !== undefined) return cache[key];
+				var result = fn(n);
+				cache[key] = result;
+				return result;
+			};
+		}
+		var fib = memoize(function(n) {
+			if (n <= 1) return n;
+			return fib(n - 1) + fib(n - 2);
+		});
+		fib(10);
+	`, 55)
+}
+
+func TestArraySortWithComparator(t *testing.T) {
+	expectString(t, `
+		var arr = [3, 1, 4, 1, 5, 9];
+		arr.sort(function(a, b) { return a - b; });
+		arr.join(",");
+	`, "1,1,3,4,5,9")
+}
+
+func TestNestedFunctions(t *testing.T) {
+	expectNumber(t, `
+		function outer(x) {
+			function inner(y) {
+				return x + y;
+			}
+			return inner(10);
+		}
+		outer(5);
+	`, 15)
+}
+
+func TestComplexControlFlow(t *testing.T) {
+	expectNumber(t, `
+		function calculate(n) {
+			var result = 0;
+			for (var i = 0; i < n; i++) {
+				if (i % 3 === 0) continue;
+				if (i > 7) break;
+				result = result + i;
+			}
+			return result;
+		}
+		calculate(10);
+	`, 1+2+4+5+7)
+}
+
+func TestComplexObject(t *testing.T) {
+	expectNumber(t, `
+		var person = {
+			name: "Alice",
+			age: 30,
+			greet: function() {
+				return this.age;
+			}
+		};
+		person.greet();
+	`, 30)
+}
+
+func TestArrayEvery(t *testing.T) {
+	expectBool(t, `
+		var arr = [2, 4, 6];
+		arr.every(function(x) { return x % 2 === 0; });
+	`, true)
+}
+
+func TestArraySome(t *testing.T) {
+	expectBool(t, `
+		var arr = [1, 3, 5, 6];
+		arr.some(function(x) { return x % 2 === 0; });
+	`, true)
+}
+
+func TestArrayFind(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3, 4];
+		arr.find(function(x) { return x > 2; });
+	`, 3)
+}
+
+func TestArrayFindIndex(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3, 4];
+		arr.findIndex(function(x) { return x > 2; });
+	`, 2)
+}
+
+func TestArrayConcat(t *testing.T) {
+	expectNumber(t, `
+		var a = [1, 2];
+		var b = a.concat([3, 4], [5]);
+		b.length;
+	`, 5)
+}
+
+func TestArraySlice(t *testing.T) {
+	expectString(t, `
+		var arr = [1, 2, 3, 4, 5];
+		arr.slice(1, 3).join(",");
+	`, "2,3")
+}
+
+func TestArraySplice(t *testing.T) {
+	expectString(t, `
+		var arr = [1, 2, 3, 4, 5];
+		arr.splice(1, 2);
+		arr.join(",");
+	`, "1,4,5")
+}
+
+func TestArrayReverse(t *testing.T) {
+	expectString(t, `
+		var arr = [1, 2, 3];
+		arr.reverse();
+		arr.join(",");
+	`, "3,2,1")
+}
+
+// --- Edge cases ---
+
+func TestDivisionByZero(t *testing.T) {
+	val := evalExpect(t, "1 / 0")
+	if !math.IsInf(val.Number, 1) {
+		t.Fatalf("expected Infinity, got %v", val.Number)
+	}
+}
+
+func TestNaNComparison(t *testing.T) {
+	expectBool(t, "NaN === NaN", false)
+	expectBool(t, "NaN !== NaN", true)
+}
+
+func TestEmptyReturn(t *testing.T) {
+	expectUndefined(t, `
+		function f() { return; }
+		f();
+	`)
+}
+
+func TestUndefinedAccess(t *testing.T) {
+	expectUndefined(t, `
+		var obj = {};
+		obj.nonexistent;
+	`)
+}
+
+func TestMultipleArgs(t *testing.T) {
+	expectNumber(t, `
+		function sum(a, b, c, d, e) {
+			return a + b + c + d + e;
+		}
+		sum(1, 2, 3, 4, 5);
+	`, 15)
+}
+
+// --- Labeled statements ---
+
+func TestLabeledBreak(t *testing.T) {
+	expectNumber(t, `
+		var result = 0;
+		outer: for (var i = 0; i < 3; i++) {
+			for (var j = 0; j < 3; j++) {
+				if (i === 1 && j === 1) break outer;
+				result = result + 1;
+			}
+		}
+		result;
+	`, 4)
+}
+
+func TestLabeledContinue(t *testing.T) {
+	expectString(t, `
+		var out = "";
+		outer: for (var i = 0; i < 3; i++) {
+			for (var j = 0; j < 3; j++) {
+				if (j === 1) continue outer;
+				out += i + "" + j + " ";
+			}
+		}
+		out;
+	`, "00 10 20 ")
+	// Either label of a loop with two continues it.
+	expectString(t, `
+		var out = "";
+		a: b: for (var i = 0; i < 3; i++) {
+			for (;;) {
+				if (i === 1) continue a;
+				out += i;
+				continue b;
+			}
+		}
+		out;
+	`, "02")
+	expectString(t, `
+		var out = "", k = 0;
+		outer: while (k < 3) {
+			k++;
+			var m = 0;
+			do {
+				m++;
+				if (m === 2) continue outer;
+				out += k + "" + m + " ";
+			} while (m < 5);
+		}
+		out;
+	`, "11 21 31 ")
+	expectString(t, `
+		var out = "";
+		x: for (var p in { a: 1, b: 2 }) {
+			for (var q of [1, 2, 3]) {
+				switch (q) { case 2: continue x; }
+				out += p + q + " ";
+			}
+		}
+		out;
+	`, "a1 b1 ")
+	// A label on a block does not attach to a loop inside it.
+	expectNumber(t, `
+		var n = 0;
+		blk: { inner: for (;;) { n++; if (n > 2) break blk; continue inner; } n = 100; }
+		n;
+	`, 3)
+	expectString(t, `
+		var out = "";
+		o: for (var i = 0; i < 2; i++) {
+			try { for (;;) continue o; } finally { out += "f" + i; }
+		}
+		out;
+	`, "f0f1")
+}
+
+// --- Spread in function call ---
+
+func TestSpreadInCall(t *testing.T) {
+	expectNumber(t, `
+		function add(a, b, c) { return a + b + c; }
+		var args = [1, 2, 3];
+		add(...args);
+	`, 6)
+}
+
+// --- Array assignment ---
+
+func TestArrayAssignment(t *testing.T) {
+	expectNumber(t, `
+		var arr = [1, 2, 3];
+		arr[1] = 20;
+		arr[1];
+	`, 20)
+}
+
+// --- Conditional chaining of method calls ---
+
+func TestMethodChaining(t *testing.T) {
+	expectString(t, `
+		var arr = [3, 1, 2];
+		arr.sort(function(a, b) { return a - b; }).join("-");
+	`, "1-2-3")
+}
+
+// --- Complex class ---
+
+func TestComplexClassHierarchy(t *testing.T) {
+	expectString(t, `
+		class Shape {
+			constructor(type) {
+				this.type = type;
+			}
+			describe() {
+				return "I am a " + this.type;
+			}
+		}
+		class Circle extends Shape {
+			constructor(radius) {
+				super("circle");
+				this.radius = radius;
+			}
+			area() {
+				return 3.14159 * this.radius * this.radius;
+			}
+		}
+		var c = new Circle(5);
+		c.describe();
+	`, "I am a circle")
+}
+
+// --- FizzBuzz ---
+
+func TestFizzBuzz(t *testing.T) {
+	expectString(t, `
+		function fizzbuzz(n) {
+			if (n % 15 === 0) return "FizzBuzz";
+			if (n % 3 === 0) return "Fizz";
+			if (n % 5 === 0) return "Buzz";
+			return "" + n;
+		}
+		fizzbuzz(15);
+	`, "FizzBuzz")
+}
+
+// --- Error propagation across function calls ---
+
+func TestErrorPropagation(t *testing.T) {
+	expectNumber(t, `
+		function thrower() {
+			throw 42;
+		}
+		var result;
+		try {
+			thrower();
+		} catch (e) {
+			result = e;
+		}
+		result;
+	`, 42)
+}
+
+// --- ReferenceError suggestions ---
+
+func TestReferenceErrorSuggestions(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`var counter = 1; function f() { let total = 2; return totl; } f();`, "totl is not defined. Did you mean 'total'?"},
+		{`var counter = 1; function f() { return countr; } f();`, "countr is not defined. Did you mean 'counter'?"},
+		{`var alpha = 1, beta = 2; betta;`, "betta is not defined. Did you mean 'beta'?"},
+		{`var y = 1; x;`, "x is not defined"},
+		{`var something = 1; nothingAtAll;`, "nothingAtAll is not defined"},
+		{`let later = 1; { latr; }`, "latr is not defined. Did you mean 'later'?"},
+		{`undefinedFn();`, "undefinedFn is not defined"},
+		{`undefnied;`, "undefnied is not defined. Did you mean 'undefined'?"},
+		{`Infinty;`, "Infinty is not defined. Did you mean 'Infinity'?"},
+		{`NaNs;`, "NaNs is not defined"},
+	}
+	for _, tt := range tests {
+		expectString(t, "try { "+tt.src+" } catch (e) { e.message; }", tt.want)
+	}
+	// Uninitialized bindings keep their own message.
+	expectString(t, `try { value; let value = 1; } catch (e) { e.message; }`, "Cannot access 'value' before initialization")
+}
+
+func TestClosestName(t *testing.T) {
+	candidates := []string{"Infinity", "Math", "NaN", "console", "counter", "this", "undefined", "value"}
+	tests := map[string]string{
+		"Mth":    "Math",
+		"consle": "console",
+		"conter": "counter",
+		"thsi":   "", // reserved words are never suggested
+		"valeu":  "value",
+		"v":      "",
+		"zzz":    "",
+		// Value names are offered only for near misses.
+		"undefind":    "undefined",
+		"undefinedFn": "",
+		"undefinedX":  "",
+		"undfnd":      "",
+		"Nan":         "NaN",
+		"Infinite":    "Infinity",
+	}
+	for name, want := range tests {
+		if got := closestName(name, candidates); got != want {
+			t.Errorf("closestName(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if d := editDistance("kitten", "sitting"); d != 3 {
+		t.Errorf("editDistance(kitten, sitting) = %d, want 3", d)
+	}
+}
+
+func TestWithStatement(t *testing.T) {
+	expectNumber(t, `var o = {a: 1, b: 2}; var r; with (o) { r = a + b; } r;`, 3)
+	// Names that are not properties resolve in the outer scope.
+	expectNumber(t, `var c = 10, o = {a: 1}; var r; with (o) { r = a + c; } r;`, 11)
+	// Assignments write through to the object, including via var.
+	expectString(t, `var o = {a: 1}; with (o) { a = 5; var a = a + 1; } o.a + "," + typeof a;`, "6,undefined")
+	expectNumber(t, `var x = 1, o = {}; with (o) { x = 2; } x;`, 2)
+	// Inherited properties and accessors are visible.
+	expectNumber(t, `function P() {} P.prototype.a = 7; var o = new P(); var r; with (o) { r = a; } r;`, 7)
+	expectString(t, `var log = []; var o = { get a() { log.push("get"); return 1; }, set a(v) { log.push("set " + v); } };
+		with (o) { a = a + 1; } log.join();`, "get,set 2")
+	// Properties added later are seen by closures created inside.
+	expectNumber(t, `var o = {}; var f; var n = 1; with (o) { f = function() { return n; }; } o.n = 2; f();`, 2)
+	// Functions found on the object are called with it as this.
+	expectNumber(t, `var o = {v: 4, get: function() { return this.v; }}; var r; with (o) { r = get(); } r;`, 4)
+	// Function declarations in the body see the object.
+	expectNumber(t, `var o = {k: 3}; with (o) { function g() { return k; } } g();`, 3)
+	expectNumber(t, `var r; with ("abc") { r = length; } r;`, 3)
+	expectString(t, `try { with (null) {} } catch (e) { e.name; }`, "TypeError")
+	expectString(t, `var o = { get a() { throw "boom"; } }; try { with (o) { a; } } catch (e) { e; }`, "boom")
+	// delete removes a property of the object, not the outer binding.
+	expectString(t, `var o = {a: 1}, a = 2, r; with (o) { r = delete a; } r + "," + ("a" in o) + "," + a;`,
+		"true,false,2")
+	expectString(t, `var o = {}; Object.defineProperty(o, "a", {value: 1}); var r; with (o) { r = delete a; } r + "," + o.a;`,
+		"false,1")
+}
+
+func TestInheritedAccessors(t *testing.T) {
+	// Accessors found on the prototype chain run with the original object
+	// as this, for reads, writes, compound assignments and updates.
+	prelude := `
+		class Base {
+			get x() { return this.tag; }
+			set x(v) { this.seen = v; }
+			get n() { return this._n || 1; }
+			set n(v) { this._n = v * 10; }
+		}
+		class Child extends Base { constructor() { super(); this.tag = "child"; } }
+		var c = new Child();
+	`
+	expectString(t, prelude+`c.x;`, "child")
+	expectString(t, prelude+`c["x"];`, "child")
+	expectNumber(t, prelude+`c.x = 5; c.seen;`, 5)
+	expectNumber(t, prelude+`c.n += 1; c._n;`, 20)
+	expectNumber(t, prelude+`c.n++; c._n;`, 20)
+	expectString(t, prelude+`var { x } = c; x;`, "child")
+	expectString(t, prelude+`c?.x;`, "child")
+	// A getter without a setter ignores assignments.
+	expectNumber(t, `class G { get z() { return 1; } } var g = new G(); g.z = 2; g.z;`, 1)
+	expectUndefined(t, `var o = { set y(v) {} }; o.y;`)
+}
+
+func TestSuperProperty(t *testing.T) {
+	prelude := `
+		class A {
+			get v() { return "A" + this.k; }
+			set v(x) { this.w = x; }
+			m() { return "am" + this.k; }
+		}
+		class B extends A {
+			constructor() { super(); this.k = 1; this.fromCtor = super.m(); }
+			get v() { return "B" + super.v; }
+			set v(x) { super.v = x + 1; }
+			m() { return super.m() + "!"; }
+			arrow() { return (() => super["m"]())(); }
+			plain() { super.own = 3; return this.own; }
+		}
+		var b = new B();
+	`
+	expectString(t, prelude+`b.v;`, "BA1")
+	expectNumber(t, prelude+`b.v = 1; b.w;`, 2)
+	expectString(t, prelude+`b.m();`, "am1!")
+	expectString(t, prelude+`b.fromCtor;`, "am1")
+	expectString(t, prelude+`b.arrow();`, "am1")
+	expectNumber(t, prelude+`b.plain();`, 3)
+	// The home object of an object literal method is the literal.
+	expectUndefined(t, `var o = { m() { return super.m; } }; o.m();`)
+	expectString(t, `
+		var base = { hi() { return "base " + this.name; } };
+		var o = { __proto__: base, name: "o", hi() { return "o>" + super.hi(); } };
+		var other = { name: "other", hi: o.hi };
+		o.hi() + "," + other.hi();
+	`, "o>base o,o>base other")
+
+	// Static methods and fields look up super.name on the parent class,
+	// which the subclass also inherits its static members from.
+	expectString(t, `
+		class P { static s() { return "P" + this.n; } static t() { return "t"; } }
+		class Q extends P { static n = 1; static s() { return "Q" + super.s(); } static u = super.t(); }
+		Q.s() + Q.u + Q.t();
+	`, "QP1tt")
+	expectString(t, prelude+`class C extends A { k = 2; f = () => super.m(); } new C().f();`, "am2")
+
+	// The home object belongs to the method, so it stays with a method
+	// copied elsewhere, and generator and async methods have one too.
+	expectString(t, prelude+`var o = { __proto__: { m() { return "o"; } }, m: B.prototype.m, k: 5 }; o.m();`, "am5!")
+	expectString(t, prelude+`class G extends A { *g() { yield super.m(); } } new G().g().next().value;`, "amundefined")
+	// A function nested in a method is not a method: super is an error
+	// there, not the super of the enclosing method.
+	expectString(t, prelude+`
+		class D extends A {
+			m() { return (function () { try { return super.m(); } catch (e) { return e.name; } })(); }
+		}
+		new D().m();
+	`, "SyntaxError")
+}
+
+func TestDeleteRespectsConfigurable(t *testing.T) {
+	expectBool(t, `var o = { a: 1 }; delete o.a && !("a" in o);`, true)
+	expectBool(t, `var o = {}; delete o.missing;`, true)
+	// The prototype property of a class is non-configurable.
+	expectBool(t, `class C {} !delete C.prototype;`, true)
+	expectString(t, `var a = [1, 2, 3]; delete a[1]; a.length + ":" + a[1];`, "3:undefined")
+}
+
+// --- Nullish assignment ---
+
+func TestNullishAssignment(t *testing.T) {
+	expectNumber(t, `
+		var x = null;
+		x ??= 42;
+		x;
+	`, 42)
+	expectNumber(t, `
+		var x = 10;
+		x ??= 42;
+		x;
+	`, 10)
+}
+
+// --- Logical assignment ---
+
+func TestLogicalAssignment(t *testing.T) {
+	expectNumber(t, `
+		var x = 0;
+		x ||= 42;
+		x;
+	`, 42)
+	expectNumber(t, `
+		var x = 10;
+		x &&= 42;
+		x;
+	`, 42)
+}
+
+// --- Evaluation order ---
+
+// orderPrelude defines helpers that record every evaluation in log: k and v
+// log keys and values, and counter(name, value) returns an object whose
+// accessor property name logs each get and set.
+const orderPrelude = `
+	var log = [];
+	function k(n) { log.push("key " + n); return n; }
+	function v(n) { log.push("val " + n); return n; }
+	function counter(name, value) {
+		return {
+			get [name]() { log.push("get " + name); return value; },
+			set [name](x) { log.push("set " + name + "=" + x); value = x; }
+		};
+	}
+`
+
+// TestEvaluationOrder checks operand evaluation order and side-effect counts
+// against V8: short-circuiting operators evaluate only the taken branch, and
+// assignment targets are evaluated once, before the right-hand side.
+func TestEvaluationOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"ternary", `var o = counter("x", 1); o.x ? v("a") : v("b");`, "get x,val a"},
+		{"ternary false", `var o = counter("x", 0); o.x ? v("a") : v("b");`, "get x,val b"},
+		{"and", `var o = counter("x", 0); o.x && v("r");`, "get x"},
+		{"or", `var o = counter("x", 1); o.x || v("r");`, "get x"},
+		{"nullish", `var o = counter("x", 0); o.x ?? v("r");`, "get x"},
+		{"nullish taken", `var o = counter("x", null); o.x ?? v("r");`, "get x,val r"},
+		{"optional member", `var n = null; n?.[k("a")].b.c;`, ""},
+		{"optional call", `var n = null; n?.f(v(1));`, ""},
+		{"optional getter", `var o = counter("q", null); o.q?.[k("z")];`, "get q"},
+		{"assign", `var o = {}; (log.push("obj"), o)[k("p")] = v(1);`, "obj,key p,val 1"},
+		{"compound", `var o = counter("x", 1); o[k("x")] += v(2);`, "key x,get x,val 2,set x=3"},
+		{"exponent", `var o = counter("x", 2); o.x **= v(3);`, "get x,val 3,set x=8"},
+		{"postfix", `var o = counter("x", 1); o[k("x")]++;`, "key x,get x,set x=2"},
+		{"prefix", `var o = counter("x", 1); --o[k("x")];`, "key x,get x,set x=0"},
+		{"or assign", `var o = counter("x", 1); o[k("x")] ||= v(5);`, "key x,get x"},
+		{"or assign taken", `var o = counter("x", 0); o[k("x")] ||= v(5);`, "key x,get x,val 5,set x=5"},
+		{"and assign", `var o = counter("x", 0); o[k("x")] &&= v(5);`, "key x,get x"},
+		{"and assign taken", `var o = counter("x", 1); o[k("x")] &&= v(5);`, "key x,get x,val 5,set x=5"},
+		{"nullish assign", `var o = counter("x", 0); o.x ??= v(5);`, "get x"},
+		{"nullish assign taken", `var o = counter("x", undefined); o.x ??= v(5);`, "get x,val 5,set x=5"},
+		{"call", `function f(a, b) {} (log.push("callee"), f)(v(1), v(2));`, "callee,val 1,val 2"},
+		{"method getter", `var o = counter("m", function () {}); o[k("m")]();`, "key m,get m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectString(t, orderPrelude+tt.src+"\nlog.join();", tt.want)
+		})
+	}
+}
+
+func TestEvaluationOrderValues(t *testing.T) {
+	// The target is read before the right-hand side runs.
+	expectNumber(t, "var z = 1; z += (z = 10, 2); z", 3)
+	expectNumber(t, "var a = [1, 2], i = 0; a[i++] += 10; a[0] * 10 + i", 111)
+	expectString(t, `var o = { n: 0 }; var r = (o.n ||= "set"); r + "," + o.n`, "set,set")
+	expectNumber(t, `var o = { n: 3 }; o.n &&= 4; o.n`, 4)
+}
+
+func TestEvaluationOrderThrows(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"taken branch", `true ? (function () { throw "then"; })() : v("else");`, "then|"},
+		{"skipped branch", `false ? (function () { throw "then"; })() : v("else"); "none";`, "none|val else"},
+		{"and rhs", `true && (function () { throw "rhs"; })();`, "rhs|"},
+		{"or skips rhs", `1 || (function () { throw "rhs"; })(); "none";`, "none|"},
+		{"getter", `var o = { get x() { throw "get"; } }; o.x;`, "get|"},
+		{"setter", `var o = { set x(_) { throw "set"; } }; o.x = v(1);`, "set|val 1"},
+		{"compound getter", `var o = { get x() { throw "get"; }, set x(_) { log.push("set"); } }; o.x += v(1);`, "get|"},
+		{"key", `var o = {}; o[(function () { throw "key"; })()] = v(1);`, "key|"},
+		{"null base", `var n = null; try { n[k("p")] = v(1); } catch (e) { throw e.name; }`, "TypeError|key p,val 1"},
+		{"null read", `var n = null; try { n[k("p")]; } catch (e) { throw e.name; }`, "TypeError|key p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := orderPrelude + "var result; try {" + tt.src + "\nresult = \"none\"; } catch (e) { result = e; }\n" +
+				"(result === \"none\" ? \"none\" : result) + \"|\" + log.join();"
+			expectString(t, src, tt.want)
+		})
+	}
+}
+
+// --- Block-scoped function declarations (Annex B) ---
+
+func TestBlockFunctionHoisting(t *testing.T) {
+	// Block function hoists name as undefined, assigns value when block executes
+	expectString(t, `
+		(function() {
+			var before = typeof f;
+			{ function f() { return 42; } }
+			var after = typeof f;
+			return before + " / " + after;
+		})();
+	`, "undefined / function")
+
+	// Block function value accessible after block
+	expectNumber(t, `
+		(function() {
+			{ function f() { return 42; } }
+			return f();
+		})();
+	`, 42)
+
+	// Multiple blocks with same function name
+	expectString(t, `
+		(function() {
+			{ function f() { return 1; } }
+			var r1 = f();
+			{ function f() { return 2; } }
+			var r2 = f();
+			return r1 + " / " + r2;
+		})();
+	`, "1 / 2")
+
+	// Only executed branch propagates
+	expectString(t, `
+		(function() {
+			if (true) { function f() { return 'yes'; } }
+			else { function f() { return 'no'; } }
+			return f();
+		})();
+	`, "yes")
+}
+
+func TestBlockFunctionMutable(t *testing.T) {
+	// Block function binding is mutable (not const)
+	expectString(t, `
+		(function() {
+			{ function f() { return 1; } f = 'reassigned'; }
+			return "ok";
+		})();
+	`, "ok")
+
+	// Function declaration name is mutable inside function body
+	expectNumber(t, `
+		(function() {
+			function f() { f = 123; return f; }
+			return f();
+		})();
+	`, 123)
+}
+
+func TestBlockFunctionVarCoexistence(t *testing.T) {
+	// var and block function with same name should not conflict
+	expectString(t, `
+		(function() {
+			var f;
+			{ function f() { return 1; } }
+			return typeof f;
+		})();
+	`, "function")
+
+	// var with value, block function overwrites after block
+	expectString(t, `
+		(function() {
+			var f = 1;
+			{ function f() { return 2; } }
+			return typeof f;
+		})();
+	`, "function")
+}
+
+func TestBlockFunctionLexicalConflict(t *testing.T) {
+	// let in function scope prevents Annex B hoisting
+	expectNumber(t, `
+		(function() {
+			let f = 123;
+			{ function f() {} }
+			return f;
+		})();
+	`, 123)
+}
+
+func TestSwitchFunctionDeclaration(t *testing.T) {
+	// Function in switch case hoists to function scope
+	expectString(t, `
+		(function() {
+			switch (1) { default: function f() { return 'decl'; } }
+			return typeof f;
+		})();
+	`, "function")
+
+	// Function in switch, body can assign to name
+	expectNumber(t, `
+		(function() {
+			switch (1) { default: function f() { f = 123; return f; } }
+			return f();
+		})();
+	`, 123)
+}
+
+func TestNamedFunctionExpressionImmutable(t *testing.T) {
+	// Named function expression has immutable self-reference
+	expectString(t, `
+		var g = function f() {
+			try { f = 123; } catch(e) {}
+			return typeof f;
+		};
+		g();
+	`, "function")
+}
+
+func TestBlockScopeElision(t *testing.T) {
+	expectNumber(t, `
+		var fs = [];
+		for (var i = 0; i < 3; i++) { let j = i; fs.push(function() { return j; }); }
+		var first = fs[0], last = fs[2];
+		first() + last();
+	`, 2)
+	expectNumber(t, "{ var q = 1; { q = q + 1; } } q", 2)
+	expectNumber(t, `
+		function f() { { function g() { return 7; } } return g(); }
+		f();
+	`, 7)
+	expectBool(t, `
+		var outer = "outer";
+		{ class outer2 {} }
+		typeof outer2 === "undefined";
+	`, true)
+}
+
+func TestAsyncAwait(t *testing.T) {
+	// Promises come from the builtins, so this test registers them.
+	interp := newTestInterp(t)
+	_, err := interp.Eval(`
+		var log = [];
+		async function f(x) {
+			log.push("start");
+			var y = await x;
+			log.push("got " + y);
+			return y * 2;
+		}
+		f(21).then(function (v) { log.push("done " + v); });
+		var caught = async () => {
+			try { await Promise.reject(new Error("boom")); } catch (e) { return e.message; }
+		};
+		caught().then(function (v) { log.push("caught " + v); });
+		async function fails() { throw new TypeError("bad"); }
+		fails().catch(function (e) { log.push(e.name); });
+		async function sum(n) {
+			var s = 0;
+			for (let i = 0; i < n; i++) { s += await i; }
+			return s;
+		}
+		sum(5).then(function (v) { log.push("sum " + v); });
+		log.push("sync");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	val, err := interp.Eval(`log.join(",")`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	want := "start,sync,got 21,TypeError,done 42,caught boom,sum 10"
+	if val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+
+	val, err = interp.Eval(`
+		var r;
+		async function g() {}
+		try { new g(); } catch (e) { r = e.name; }
+		try { eval("(function () { return await 1; })"); } catch (e) { r += "," + e.name; }
+		r;
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	if val.ToString() != "TypeError,SyntaxError" {
+		t.Errorf("expected TypeError,SyntaxError, got %q", val.ToString())
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	expectString(t, `
+		function* count(n) {
+			for (let i = 0; i < n; i++) {
+				var got = yield i;
+				if (got) { log.push("got " + got); }
+			}
+			return "end";
+		}
+		var log = [];
+		var g = count(2);
+		var a = g.next(), b = g.next("x"), c = g.next(), d = g.next();
+		log.push(a.value, a.done, b.value, c.value, c.done, d.value === undefined, d.done);
+		log.join(",");
+	`, "got x,0,false,1,end,true,true,true")
+
+	// for-of pulls lazily and closes the generator on break.
+	expectString(t, `
+		var log = [];
+		function* naturals() {
+			var i = 0;
+			try { while (true) { yield i++; } } finally { log.push("closed"); }
+		}
+		for (var n of naturals()) {
+			if (n > 2) { break; }
+			log.push(n);
+		}
+		log.join(",");
+	`, "0,1,2,closed")
+
+	expectString(t, `
+		function* inner() { var x = yield 1; yield x * 2; return "r"; }
+		function* outer() { var r = yield* inner(); yield r; yield* [7, 8]; }
+		var o = outer();
+		var out = [o.next().value, o.next(5).value];
+		for (var v of o) { out.push(v); }
+		out.join(",");
+	`, "1,10,r,7,8")
+
+	expectString(t, `
+		function* gen() { try { yield 1; yield 2; } finally { yield "cleanup"; } }
+		var g = gen();
+		g.next();
+		var r = g.return(9), s = g.next();
+		var t = gen();
+		t.next();
+		var caught, tv = t.throw("boom").value;
+		try { t.next(); } catch (e) { caught = e; }
+		[r.value, r.done, s.value, s.done, tv, caught].join(",");
+	`, "cleanup,false,9,true,cleanup,boom")
+
+	expectString(t, `
+		var obj = { *pair() { yield "a"; yield "b"; } };
+		function* self() { it.next(); }
+		var it = self(), msg;
+		try { it.next(); } catch (e) { msg = e.message; }
+		var notCtor;
+		try { new obj.pair(); } catch (e) { notCtor = e.name; }
+		[...obj.pair()].join("") + "," + msg + "," + notCtor;
+	`, "ab,Generator is already running,TypeError")
+
+	// Outside generators, yield is an ordinary name.
+	expectNumber(t, `var yield = 2; yield;`, 2)
+	expectNumber(t, `
+		function f(yield) { return yield * 3; }
+		function* g() { yield f(2); }
+		g().next().value;
+	`, 6)
+}
+
+func TestDestructuringIterables(t *testing.T) {
+	// Map and Set come from the builtins, so this test registers them.
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var m = new Map([["a", 1], ["b", { n: 2 }]]);
+		var out = [];
+		for (const [k, v] of m) out.push(k + ":" + (typeof v === "object" ? v.n : v));
+		for (var [k, v] of Object.entries({ x: 3 })) out.push(k + v);
+		var target = {};
+		for ([target.k, target.v] of m.entries()) out.push(target.k);
+		var [[k0, v0], ...rest] = m;
+		out.push(k0 + v0, rest.length);
+		var [first] = new Set(["s", "t"]);
+		out.push(first);
+
+		var closed = false;
+		function* gen() { try { yield 1; yield 2; yield 3; } finally { closed = true; } }
+		var [one] = gen();
+		out.push(one, closed);
+
+		try { var [bad] = {}; } catch (e) { out.push(e.name); }
+		try { var [u] = undefined; } catch (e) { out.push(e.name); }
+		out.join(",");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	want := "a:1,b:2,x3,a,b,a1,1,s,1,true,TypeError,TypeError"
+	if val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+}
+
+func TestCommonJS(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.js": `
+			var lib = require("./lib");
+			var a = require("./a");
+			var again = require("./lib/index.js");
+			var missing;
+			try { require("./nope"); } catch (e) { missing = e.message.indexOf("Cannot find module './nope'") === 0; }
+			module.exports = [lib.add(2, 3), require("./data.json").items.length, a.fromB, a.early,
+				lib === again, this === exports, __filename === require.resolve("./main"), missing].join(",");
+		`,
+		"lib/index.js": `exports.add = function (x, y) { return x + y; };`,
+		"data.json":    `{"items": [1, 2]}`,
+		"a.js":         `exports.early = "e"; var b = require("./b"); exports.fromB = b.sawA;`,
+		"b.js":         `exports.sawA = require("./a.js").early; return; exports.never = true;`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	interp := newTestInterp(t)
+	val, err := interp.EvalCommonJS(filepath.Join(dir, "main.js"))
+	if err != nil {
+		t.Fatalf("EvalCommonJS error: %v", err)
+	}
+	want := "5,2,e,e,true,true,true,true"
+	if val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+	if v, err := interp.Eval(`typeof require + typeof module`); err != nil || v.ToString() != "undefinedundefined" {
+		t.Errorf("expected module variables to stay out of the global scope, got %v, %v", v, err)
+	}
+}
+
+func TestEvalFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scripts/count.js": {Data: []byte(`var n = (typeof n === "number" ? n : 0) + 1; n;`)},
+		"lib/main.js":      {Data: []byte(`import { two } from "./util/two.js"; export default two * 21;`)},
+		"lib/util/two.js":  {Data: []byte(`export const two = 2;`)},
+	}
+	interp := New()
+	for _, want := range []float64{1, 2} {
+		val, err := interp.EvalFS(fsys, "/scripts/count.js")
+		if err != nil {
+			t.Fatalf("EvalFS error: %v", err)
+		}
+		if val.Number != want {
+			t.Errorf("expected %v, got %v", want, val.Number)
+		}
+		// The parsed program is cached, so later edits are not seen.
+		fsys["scripts/count.js"] = &fstest.MapFile{Data: []byte(`"changed"`)}
+	}
+	ns, err := interp.EvalModule("lib/main.js")
+	if err != nil {
+		t.Fatalf("EvalModule error: %v", err)
+	}
+	if got := ns.Object.Get("default"); got.Number != 42 {
+		t.Errorf("expected 42 from the seeded resolver, got %v", got)
+	}
+	if _, err := interp.EvalFS(fsys, "missing.js"); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestIterationProtocol(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var log = [];
+		function range(n) {
+		  return { [Symbol.iterator]() { var i = 0; return {
+		    next() { log.push("next"); return i < n ? { value: i++, done: false } : { value: undefined, done: true }; },
+		    return() { log.push("return"); return {}; } }; } };
+		}
+		var out = [];
+		for (var x of range(5)) { if (x == 2) break; out.push(x); }
+		try { for (var y of range(3)) throw new Error("x"); } catch (e) { out.push(e.message); }
+		out.push([...range(3)].join(""), Math.max(...range(4)));
+		var [a, b] = range(10); out.push(a + b);
+		out.push(Array.from(range(3), function (v) { return v * this.k; }, { k: 10 }).join("|"));
+		out.push(new Map([[1, "a"]].values()).get(1), new Set(range(3)).size, Array.from("héllo").length);
+		out.push(Array.from({ length: 2, 0: "p", 1: "q" }).join(""));
+		var it = [7, 8].values(); out.push(it[Symbol.iterator]() === it, [...it].join(""));
+		var arr = [1, 2, 3];
+		arr[Symbol.iterator] = function* () { yield "custom"; };
+		out.push([...arr].join(""), Array.from(arr).join(""));
+		for (var c of arr) out.push(c);
+		var saved = String.prototype[Symbol.iterator];
+		String.prototype[Symbol.iterator] = function* () { yield "S"; };
+		out.push([..."abc"].join(""));
+		String.prototype[Symbol.iterator] = saved;
+		out.push([..."abc"].join(""));
+		try { Array.from(range(3), function () { throw new Error("m"); }); } catch (e) { out.push(e.message); }
+		try { [...{}]; } catch (e) { out.push(e.name); }
+		try { new Map([1]); } catch (e) { out.push(e.name); }
+		var gen = (function* () { yield 1; })();
+		gen.next = function () { return { value: "patched", done: false }; };
+		var [p] = gen;
+		var values = [7].values();
+		values.next = function () { return { done: true }; };
+		out.push(p, [...values].length);
+		out.join(",") + ";" + log.join(" ");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	want := "0,1,x,012,3,1,0|10|20,a,3,5,pq,true,78,custom,custom,custom,S,abc,m,TypeError,TypeError,patched,0;" +
+		"next next next return next return next next next next next next next next next next next return " +
+		"next next next next next next next next next return"
+	if val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+}
+
+func TestObjectCoercion(t *testing.T) {
+	// Array.prototype.toString and Date come from the builtins.
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var money = { valueOf: function () { return 5; }, toString: function () { return "$5"; } };
+		var tp = { [Symbol.toPrimitive]: function (hint) { return hint === "number" ? 1 : hint; } };
+		var n = { valueOf: function () { return 2; } };
+		var out = [+money, money + 1, money * 2, 10 > money, money == 5, "" + money, ` + "`${money}`" + `,
+			[1, 2] == "1,2", [1, 2] + "", +tp, tp + "", ` + "`${tp}`" + `,
+			typeof (new Date(0) + 1), n++, n, -money];
+		var bad = { valueOf: function () { throw new RangeError("no"); } };
+		try { bad < 1; } catch (e) { out.push(e.name); }
+		try { bad == 1; } catch (e) { out.push(e.name); }
+		try { Object.create(null) + ""; } catch (e) { out.push(e.name); }
+		out.join(",");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	want := "5,6,10,true,true,5,$5,true,1,2,1,default,string,string,2,3,-5,RangeError,RangeError,TypeError"
+	if val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+}
+
+func TestPropertyKeyCoercion(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var key = { toString: function () { return "k"; } };
+		var sym = Symbol("s");
+		var boxed = { [Symbol.toPrimitive]: function () { return sym; } };
+		var o = {};
+		o[key] = 1;
+		o[boxed] = 2;
+		var { [key]: a } = o;
+		var b;
+		({ [key]: b } = o);
+		var out = [o.k, a, b, o[sym], key in o, Object.keys({ [key]: 0 })[0], [key, key].join("-"),
+			Object.getOwnPropertyDescriptor(o, key).value, Reflect.get(o, key)];
+		try { o[{ toString: function () { throw new RangeError("key"); } }]; } catch (e) { out.push(e.name); }
+		try { ` + "`${sym}`" + `; } catch (e) { out.push(e.name); }
+		out.join(",");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	want := "1,1,1,2,true,k,k-k,1,1,RangeError,TypeError"
+	if val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+}
+
+func TestRunLoop(t *testing.T) {
+	interp := newTestInterp(t)
+	_, err := interp.Eval(`
+		var log = [];
+		setTimeout(function (a, b) { log.push("late:" + a + b); }, 100, "x", "y");
+		setTimeout(function () { log.push("soon"); }, 0);
+		Promise.resolve().then(function () { log.push("micro"); });
+		var n = 0;
+		var iv = setInterval(function () { log.push("tick" + n); if (++n === 3) clearInterval(iv); }, 1);
+		clearTimeout(setTimeout(function () { log.push("cleared"); }, 1));
+		(async function () {
+			await new Promise(function (resolve) { setTimeout(resolve, 120); });
+			log.push("awaited");
+		})();
+		log.push("sync");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	if err := interp.RunLoop(context.Background()); err != nil {
+		t.Fatalf("RunLoop error: %v", err)
+	}
+	val, _ := interp.Eval(`log.join(" ")`)
+	if want := "sync micro soon tick0 tick1 tick2 late:xy awaited"; val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+
+	// A callback that throws ends the loop with the exception.
+	interp.Eval(`setTimeout(function () { throw new RangeError("boom"); }, 0);`)
+	if err := interp.RunLoop(nil); err == nil || !strings.Contains(err.Error(), "RangeError: boom") {
+		t.Errorf("expected the RangeError, got %v", err)
+	}
+
+	// An interval that is never cleared runs until the context is done.
+	interp.Eval(`var forever = setInterval(function () {}, 1);`)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := interp.RunLoop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to stop the loop, got %v", err)
+	}
+	interp.Eval(`clearInterval(forever);`)
+	if err := interp.RunLoop(nil); err != nil {
+		t.Errorf("RunLoop error: %v", err)
+	}
+}
+
+func TestMicrotasks(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var log = [];
+		queueMicrotask(function () { log.push("a"); queueMicrotask(function () { log.push("c"); }); });
+		Promise.resolve().then(function () { log.push("b"); });
+		log.push("sync");
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, _ = interp.Eval(`log.join(" ")`)
+	if want := "sync a b c"; val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+
+	// A microtask that throws is uncaught; the script's result is lost.
+	_, err = interp.Eval(`queueMicrotask(function () { throw new TypeError("micro"); }); 1;`)
+	if err == nil || !strings.Contains(err.Error(), "TypeError: micro") {
+		t.Errorf("expected the microtask's TypeError, got %v", err)
+	}
+
+	ran := false
+	interp.EnqueueJob(func() error { ran = true; return nil })
+	if interp.PendingJobs() != 1 || interp.DrainJobs() != nil || !ran || interp.PendingJobs() != 0 {
+		t.Error("DrainJobs runs a job queued by the host")
+	}
+}
+
+func TestModules(t *testing.T) {
+	files := map[string]string{
+		"main.js": `
+			import def, { count, inc, a as aa } from "./counter.js";
+			import * as ns from "./counter.js";
+			import { ping } from "./a.js";
+			export const log = [];
+			log.push(count); inc();
+			log.push(count, ns.count, def(), aa, Object.keys(ns).join("|"), ping(3));
+			try { count = 1; } catch (e) { log.push(e.name); }
+			export default log.join(",");
+		`,
+		"counter.js": `
+			export let count = 0;
+			export function inc() { count++; }
+			export default function () { return "d"; }
+			export * from "./other.js";
+		`,
+		"other.js": `export const a = "A"; export default "hidden";`,
+		"a.js":     `import { pong } from "./b.js"; export function ping(n) { return n ? pong(n - 1) : "ping"; }`,
+		"b.js":     `import { ping } from "./a.js"; export function pong(n) { return n ? ping(n - 1) : "pong"; }`,
+		"bad.js":   `import { missing } from "./other.js";`,
+	}
+	interp := newTestInterp(t)
+	loads := 0
+	interp.SetModuleResolver(func(specifier, referrer string) (string, string, error) {
+		name := strings.TrimPrefix(specifier, "./")
+		src, ok := files[name]
+		if !ok {
+			return "", "", fmt.Errorf("cannot find module %q", specifier)
+		}
+		loads++
+		return name, src, nil
+	})
+	ns, err := interp.EvalModule("main.js")
+	if err != nil {
+		t.Fatalf("EvalModule error: %v", err)
+	}
+	want := "0,1,1,d,A,a|count|default|inc,pong,TypeError"
+	if got := ns.Object.Get("default").ToString(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	again, err := interp.EvalModule("main.js")
+	if err != nil || again.Object != ns.Object {
+		t.Errorf("expected the cached namespace, got %v, %v", again, err)
+	}
+	if _, err := interp.EvalModule("bad.js"); err == nil || !strings.Contains(err.Error(), "does not provide an export named 'missing'") {
+		t.Errorf("expected a missing export error, got %v", err)
+	}
+	if _, err := New().Eval(`import x from "y";`); err == nil {
+		t.Errorf("expected import in a script to fail")
+	}
+}
+
+func TestDefaultExportNames(t *testing.T) {
+	files := map[string]string{
+		"main.js": `
+			import { early } from "./cycle.js";
+			import fn from "./fn.js";
+			import gen from "./gen.js";
+			import cls from "./class.js";
+			import arrow from "./arrow.js";
+			import named from "./named.js";
+			export default function () { return "hoisted"; }
+			export const names = [early, fn.name, gen.name, cls.name, arrow.name, named.name].join();
+		`,
+		"cycle.js": `import main from "./main.js"; export const early = main();`,
+		"fn.js":    `export default function () {}`,
+		"gen.js":   `export default async function* () {}`,
+		"class.js": `export default class {}`,
+		"arrow.js": `export default () => {};`,
+		"named.js": `export default function f() {}`,
+	}
+	interp := newTestInterp(t)
+	interp.SetModuleResolver(func(specifier, referrer string) (string, string, error) {
+		name := strings.TrimPrefix(specifier, "./")
+		src, ok := files[name]
+		if !ok {
+			return "", "", fmt.Errorf("cannot find module %q", specifier)
+		}
+		return name, src, nil
+	})
+	ns, err := interp.EvalModule("main.js")
+	if err != nil {
+		t.Fatalf("EvalModule error: %v", err)
+	}
+	want := "hoisted,default,default,default,default,f"
+	if got := ns.Object.Get("names").ToString(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAsyncGeneratorsAndForAwait(t *testing.T) {
+	interp := newTestInterp(t)
+	_, err := interp.Eval(`
+		var log = [];
+		async function* gen() {
+			var x = yield 1;
+			log.push("got " + x);
+			try {
+				yield Promise.resolve(2);
+			} finally {
+				log.push("finally");
+			}
+		}
+		var custom = {
+			[Symbol.asyncIterator]() {
+				var i = 0;
+				return {
+					next() { return Promise.resolve({ value: i, done: i++ >= 3 }); },
+					return() { log.push("closed"); return Promise.resolve({ done: true }); }
+				};
+			}
+		};
+		(async function () {
+			for await (var v of gen()) log.push(v);
+			for await (var v of [Promise.resolve("a"), "b"]) log.push(v);
+			for await (var v of custom) { log.push("c" + v); if (v === 1) break; }
+			var it = gen();
+			var results = [it.next(), it.next("X"), it.return("early"), it.next()];
+			for (var r of await Promise.all(results)) log.push(r.value + ":" + r.done);
+			try {
+				for await (var v of [Promise.reject(new Error("rejected"))]) {}
+			} catch (e) {
+				log.push(e.message);
+			}
+		})();
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	result, err := interp.Eval(`log.join()`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	want := "1,got undefined,2,finally,a,b,c0,c1,closed,got X,finally,1:false,2:false,early:true,undefined:true,rejected"
+	if got := result.ToString(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	err = evalExpectError(t, `function f() { for await (var x of []) {} } f();`)
+	if !strings.Contains(err.Error(), "only valid in async functions") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// --- Proxy and Reflect ---
+
+func TestElementsThroughRuntimeOperations(t *testing.T) {
+	expectNumber(t, `Reflect.get([1], 0)`, 1)
+	expectBool(t, `Reflect.has([1], 0)`, true)
+	expectBool(t, `Reflect.has([1], 1)`, false)
+	expectNumber(t, `Object.create([7, 8])[1]`, 8)
+	expectString(t, `({__proto__: ["z"]})[0]`, "z")
+	expectBool(t, `1 in Object.create([7, 8])`, true)
+	expectNumber(t, `var a = [1, 2, 3]; Reflect.set(a, 1, 5); a[1] + a.length`, 8)
+	expectNumber(t, `var a = [1, 2, 3]; Reflect.set(a, "length", 1); a.length`, 1)
+	expectBool(t, `Reflect.set(Object.freeze([1]), 0, 2)`, false)
+
+	expectNumber(t, `new Proxy([1, 2, 3], {})[0]`, 1)
+	expectString(t, `[...new Proxy([1, 2, 3], {})].join()`, "1,2,3")
+	expectString(t, `Array.prototype.map.call(new Proxy([1, 2], {}), x => x * 2).join()`, "2,4")
+	expectString(t, `Array.prototype.join.call(new Proxy([1, 2], {}), "-")`, "1-2")
+	expectNumber(t, `var n = 0; for (var x of new Proxy([1, 2, 3], {})) n += x; n`, 6)
+	expectString(t, `var a = [1, 2]; var p = new Proxy(a, {}); p[1] = 5; p[2] = 6; a.join()`, "1,5,6")
+}
+
+func TestReflectDescriptors(t *testing.T) {
+	expectBool(t, `var o = {}; Reflect.defineProperty(o, "x", {value: 1}) && o.x === 1`, true)
+	expectBool(t, `var o = Object.freeze({x: 1}); Reflect.defineProperty(o, "x", {value: 2})`, false)
+	expectBool(t, `Reflect.getOwnPropertyDescriptor({x: 1}, "x").writable`, true)
+	expectNumber(t, `Reflect.getOwnPropertyDescriptor([5], 0).value`, 5)
+	expectUndefined(t, `Reflect.getOwnPropertyDescriptor({}, "x")`)
+	expectBool(t, `Reflect.isExtensible({})`, true)
+	expectBool(t, `Reflect.isExtensible(Object.preventExtensions({}))`, false)
+	expectBool(t, `var o = {}; Reflect.preventExtensions(o) && !Object.isExtensible(o)`, true)
+	expectBool(t, `try { Reflect.isExtensible(1); false } catch (e) { e instanceof TypeError }`, true)
+}
+
+func TestProxyDescriptorTraps(t *testing.T) {
+	// Object.keys asks getOwnPropertyDescriptor which keys are enumerable.
+	expectString(t, `
+		var p = new Proxy({a: 1, b: 2}, {
+			getOwnPropertyDescriptor(t, k) {
+				return k === "a" ? undefined : Reflect.getOwnPropertyDescriptor(t, k);
+			},
+		});
+		Object.keys(p).join()
+	`, "b")
+	expectString(t, `
+		var p = new Proxy({a: 1}, {get: (t, k) => t[k] * 10});
+		Object.values(p).join() + ";" + Object.entries(p).join()
+	`, "10;a,10")
+	expectString(t, `
+		var log = [];
+		var p = new Proxy({}, {defineProperty(t, k, d) { log.push(k, d.value); return Reflect.defineProperty(t, k, d); }});
+		Object.defineProperty(p, "x", {value: 1});
+		log.join()
+	`, "x,1")
+	expectBool(t, `
+		var p = new Proxy({}, {defineProperty: () => false});
+		try { Object.defineProperty(p, "x", {value: 1}); false } catch (e) { e instanceof TypeError }
+	`, true)
+	expectBool(t, `Reflect.defineProperty(new Proxy({}, {defineProperty: () => false}), "x", {value: 1})`, false)
+	expectBool(t, `Reflect.isExtensible(new Proxy({}, {isExtensible: () => true}))`, true)
+}
+
+// Object spread, object rest, for-in and JSON.stringify ask a proxy's
+// getOwnPropertyDescriptor trap which of its keys are enumerable.
+func TestProxyEnumeration(t *testing.T) {
+	const hideA = `
+		var log = [];
+		var p = new Proxy({a: 1, b: 2}, {
+			ownKeys(t) { log.push("ownKeys"); return Reflect.ownKeys(t); },
+			getOwnPropertyDescriptor(t, k) {
+				log.push("gopd " + k);
+				return k === "a" ? {value: 1, enumerable: false, configurable: true, writable: true} : Reflect.getOwnPropertyDescriptor(t, k);
+			},
+			get(t, k) { log.push("get " + String(k)); return t[k]; },
+		});
+	`
+	expectString(t, hideA+`JSON.stringify({...p}) + ";" + log.join()`, `{"b":2};ownKeys,gopd a,gopd b,get b`)
+	expectString(t, hideA+`var {b, ...rest} = p; JSON.stringify(rest)`, `{}`)
+	expectString(t, hideA+`var keys = []; for (var k in p) keys.push(k); keys.join() + ";" + log.join()`, "b;ownKeys,gopd a,gopd b")
+	expectString(t, hideA+`JSON.stringify(p) + ";" + log.join()`, `{"b":2};get toJSON,ownKeys,gopd a,gopd b,get b`)
+	expectString(t, `JSON.stringify({list: new Proxy([1, [2]], {})})`, `{"list":[1,[2]]}`)
+	expectString(t, `
+		var proto = new Proxy({inherited: 1}, {});
+		var keys = [];
+		for (var k in Object.create(proto, {own: {value: 1, enumerable: true}})) keys.push(k);
+		keys.join()
+	`, "own,inherited")
+	expectString(t, `
+		[new Proxy([], {}), new Proxy(function () {}, {}), new Proxy({}, {})]
+			.map(p => Object.prototype.toString.call(p)).join()
+	`, "[object Array],[object Function],[object Object]")
+	expectString(t, `
+		var p = new Proxy({a: 1}, {getOwnPropertyDescriptor() { throw new Error("trap"); }});
+		var r = [];
+		try { ({...p}); } catch (e) { r.push(e.message); }
+		try { for (var k in p) {} } catch (e) { r.push(e.message); }
+		try { JSON.stringify(p); } catch (e) { r.push(e.message); }
+		r.join()
+	`, "trap,trap,trap")
+	expectBool(t, `
+		var r = Proxy.revocable([], {});
+		r.revoke();
+		try { Object.prototype.toString.call(r.proxy); false } catch (e) { e instanceof TypeError }
+	`, true)
+}
+
+func TestProxyInvariants(t *testing.T) {
+	// Each trap result contradicting a property the target has fixed
+	// throws a TypeError.
+	target := `var t = {}; Object.defineProperty(t, "x", {value: 1}); `
+	tests := []string{
+		target + `new Proxy(t, {get: () => 2}).x`,
+		target + `Reflect.set(new Proxy(t, {set: () => true}), "x", 2)`,
+		target + `"x" in new Proxy(t, {has: () => false})`,
+		target + `delete new Proxy(t, {deleteProperty: () => true}).x`,
+		target + `Object.keys(new Proxy(t, {ownKeys: () => []}))`,
+		target + `Object.getOwnPropertyDescriptor(new Proxy(t, {getOwnPropertyDescriptor: () => undefined}), "x")`,
+		`Object.getOwnPropertyDescriptor(new Proxy({}, {getOwnPropertyDescriptor: () => ({value: 1})}), "x")`,
+		`Object.defineProperty(new Proxy({}, {defineProperty: () => true}), "x", {value: 1, configurable: false})`,
+		`Reflect.isExtensible(new Proxy({}, {isExtensible: () => false}))`,
+		`Object.preventExtensions(new Proxy({}, {preventExtensions: () => true}))`,
+		`Object.getPrototypeOf(new Proxy(Object.preventExtensions({}), {getPrototypeOf: () => Array.prototype}))`,
+		`Object.keys(new Proxy(Object.preventExtensions({a: 1}), {ownKeys: () => ["a", "b"]}))`,
+	}
+	for _, src := range tests {
+		expectBool(t, `try { `+src+`; false } catch (e) { e instanceof TypeError }`, true)
+	}
+	// Results that agree with the target are fine.
+	expectNumber(t, target+`new Proxy(t, {get: () => 1}).x`, 1)
+	expectBool(t, `"y" in new Proxy({y: 1}, {has: () => false})`, false)
+}
+
+func TestOwnSymbolsAndHasOwn(t *testing.T) {
+	expectString(t, `
+		var a = Symbol("a"), b = Symbol("b");
+		var o = {x: 1};
+		o[b] = 1; o[a] = 2;
+		Object.getOwnPropertySymbols(o).map(String).join() + ";" + Object.getOwnPropertyNames(o).join()
+	`, "Symbol(b),Symbol(a);x")
+	expectNumber(t, `Object.getOwnPropertySymbols("str").length`, 0)
+	expectString(t, `
+		var s = Symbol("s");
+		var p = new Proxy({}, {ownKeys: () => ["k", s]});
+		Object.getOwnPropertySymbols(p).map(String).join()
+	`, "Symbol(s)")
+	expectString(t, `
+		var s = Symbol();
+		var o = Object.create({inherited: 1});
+		o.own = 1; o[s] = 1;
+		[Object.hasOwn(o, "own"), Object.hasOwn(o, "inherited"), Object.hasOwn(o, s),
+		 Object.hasOwn("ab", 1), Object.hasOwn("ab", "length"), Object.hasOwn([1], 0)].join()
+	`, "true,false,true,true,true,true")
+	expectString(t, `
+		var log = [];
+		var p = new Proxy({a: 1}, {getOwnPropertyDescriptor(t, k) { log.push(k); return Reflect.getOwnPropertyDescriptor(t, k); }});
+		[Object.hasOwn(p, "a"), Object.hasOwn(p, "b"), log.join()].join()
+	`, "true,false,a,b")
+	expectBool(t, `try { Object.hasOwn(null, "x"); false } catch (e) { e instanceof TypeError }`, true)
+	expectBool(t, `try { Object.getOwnPropertySymbols(undefined); false } catch (e) { e instanceof TypeError }`, true)
+	expectNumber(t, `Object.hasOwn.length * 10 + Object.getOwnPropertySymbols.length`, 21)
+}
+
+// --- structuredClone ---
+
+func TestStructuredCloneWrapperObjects(t *testing.T) {
+	expectBool(t, `var n = new Number(42); var c = structuredClone(n); c !== n && c instanceof Number && c.valueOf() === 42`, true)
+	expectBool(t, `var s = new String("hi"); var c = structuredClone(s); c !== s && c instanceof String && c.valueOf() === "hi" && c.length === 2`, true)
+	expectBool(t, `var b = new Boolean(false); var c = structuredClone(b); c !== b && c instanceof Boolean && c.valueOf() === false`, true)
+	expectString(t, `var o = {n: new Number(1)}; o.m = o.n; var c = structuredClone(o); typeof c.n + (c.n === c.m)`, "objecttrue")
+	expectString(t, `try { structuredClone(Object(Symbol())) } catch (e) { e.name }`, "DataCloneError")
+}
+
+// --- Benchmarks ---
+
+const benchLoopSource = `
+	var sum = 0;
+	for (var i = 0; i < 10000; i++) {
+		if (i % 3 === 0) { continue; }
+		sum = sum + i;
+	}
+	sum;
+`
+
+const benchCallSource = `
+	function fib(n) {
+		if (n < 2) { return n; }
+		return fib(n - 1) + fib(n - 2);
+	}
+	fib(18);
+`
+
+// benchFunctionLoopSource is benchLoopSource in a function, whose body
+// Compiled mode compiles.
+const benchFunctionLoopSource = `
+	function loop() {
+		var sum = 0;
+		for (var i = 0; i < 10000; i++) {
+			if (i % 3 === 0) { continue; }
+			sum = sum + i;
+		}
+		return sum;
+	}
+	loop();
+`
+
+// benchMapFilterSource is top-level code that spends its time in
+// callbacks of array methods.
+const benchMapFilterSource = `
+	var factor = 3, total = 0;
+	var data = [];
+	for (var i = 0; i < 500; i++) { data.push(i); }
+	for (var k = 0; k < 20; k++) {
+		total += data.map(function (x) { return x * factor; })
+			.filter(function (x) { return x % 2 === 0; })
+			.reduce(function (a, b) { return a + b; }, 0);
+		total += data.map(x => x + k).filter(x => x > 250).length;
+	}
+	total;
+`
+
+func benchmarkEval(b *testing.B, source string) { benchmarkEvalMode(b, source, TreeWalk) }
+
+func benchmarkEvalMode(b *testing.B, source string, mode Mode) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		interp := newTestInterp(b)
+		interp.SetMode(mode)
+		b.StartTimer()
+		if _, err := interp.Eval(source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoop(b *testing.B) { benchmarkEval(b, benchLoopSource) }
+
+func BenchmarkCalls(b *testing.B) { benchmarkEval(b, benchCallSource) }
+
+func BenchmarkFunctionLoop(b *testing.B) { benchmarkEval(b, benchFunctionLoopSource) }
+
+func BenchmarkFunctionLoopCompiled(b *testing.B) {
+	benchmarkEvalMode(b, benchFunctionLoopSource, Compiled)
+}
+
+func BenchmarkCallsCompiled(b *testing.B) { benchmarkEvalMode(b, benchCallSource, Compiled) }
+
+func BenchmarkLoopCompiled(b *testing.B) { benchmarkEvalMode(b, benchLoopSource, Compiled) }
+
+func BenchmarkMapFilter(b *testing.B) { benchmarkEval(b, benchMapFilterSource) }
+
+func BenchmarkMapFilterCompiled(b *testing.B) {
+	benchmarkEvalMode(b, benchMapFilterSource, Compiled)
+}
+
+func TestClosureCapture(t *testing.T) {
+	interp := newTestInterp(t)
+	val, err := interp.Eval(`
+		var out = [];
+		function counter() { var n = 0, unused = "x"; var f = () => ++n; n = 10; return f; }
+		var c = counter(); c(); out.push(c());
+		function early() { { function g() { return y; } let y = 5; return g(); } }
+		out.push(early());
+		function recursive(n) { return even(n); function even(m) { return m === 0 || odd(m - 1); } function odd(m) { return m !== 0 && even(m - 1); } }
+		out.push(recursive(5));
+		function defaults(p = () => q, q = 3) { return p(); }
+		out.push(defaults());
+		function nestedEval() { var z = 1; return function () { return eval("z + 1"); }; }
+		out.push(nestedEval()());
+		function evalAfter() { var f = () => late; eval("var late = 6"); return f(); }
+		out.push(evalAfter());
+		function tdz() { try { (() => w)(); } catch (e) { return "tdz"; } let w = 1; }
+		out.push(tdz());
+		function cls() { class C { static make() { return new C(); } who() { return "C"; } } return C.make().who(); }
+		out.push(cls());
+		var obj = { v: "this", m() { return (() => this.v)(); } };
+		out.push(obj.m());
+		var g = "before"; var readGlobal = () => g; g = "after"; out.push(readGlobal());
+		out.join(",");
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	if want := "12,5,false,3,2,6,tdz,C,this,after"; val.ToString() != want {
+		t.Errorf("expected %q, got %q", want, val.ToString())
+	}
+}
+
+func TestClosureCaptureReleasesUnusedBindings(t *testing.T) {
+	interp := New()
+	collected := make(chan string, 2)
+	interp.RegisterNative("track", func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		name := args[0].ToString()
+		goruntime.SetFinalizer(args[1].Object, func(*runtime.Object) { collected <- name })
+		return runtime.Undefined, nil
+	})
+	_, err := interp.Eval(`
+		function makeCounter() {
+			var big = [];
+			for (var i = 0; i < 100000; i++) big[i] = i;
+			track("big", big);
+			var count = 0;
+			return function () { return ++count; };
+		}
+		function makeEvaluator() {
+			var kept = {};
+			track("kept", kept);
+			return function (src) { return eval(src); };
+		}
+		var counter = makeCounter();
+		var evaluate = makeEvaluator();
+		counter();
+	`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+
+	// big shares a scope with the counter's closure but is not referenced
+	// by it, so it is collectible once makeCounter returns.
+	var got string
+	for i := 0; i < 20 && got == ""; i++ {
+		goruntime.GC()
+		select {
+		case got = <-collected:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if got != "big" {
+		t.Fatalf("expected big to be collected, got %q", got)
+	}
+
+	// A closure that calls eval keeps its whole scope chain.
+	val, err := interp.Eval(`counter() + ":" + evaluate("typeof kept")`)
+	if err != nil {
+		t.Fatalf("Eval error: %v", err)
+	}
+	if val.ToString() != "2:object" {
+		t.Errorf("expected %q, got %q", "2:object", val.ToString())
+	}
+}
+
+func TestOptionalChaining(t *testing.T) {
+	expectUndefined(t, `var a = null; a?.b`)
+	expectUndefined(t, `var a; a?.[0]`)
+	expectUndefined(t, `var f = null; f?.()`)
+	expectNumber(t, `var a = {b: {c: 3}}; a?.b.c`, 3)
+	expectNumber(t, `var a = {b: [5]}; a?.["b"]?.[0]`, 5)
+	expectNumber(t, `var o = {n: 2, m: function () { return this.n; }}; o?.m()`, 2)
+	expectNumber(t, `var o = {n: 2, m: function () { return this.n; }}; o.m?.()`, 2)
+
+	// A nullish base skips the rest of the chain, including call arguments
+	// and computed keys.
+	expectUndefined(t, `var a = null; a?.b.c.d`)
+	expectNumber(t, `
+		var n = 0;
+		function sideEffect() { n++; return 1; }
+		var a = null;
+		a?.b(sideEffect());
+		a?.[sideEffect()].c;
+		a?.b.c(sideEffect());
+		n`, 0)
+	expectNumber(t, `
+		var n = 0;
+		var a = {b: function (x) { return x; }};
+		a?.b(++n) + n`, 2)
+
+	// The chain ends at its parentheses; a non-nullish base is not guarded.
+	evalExpectError(t, `var a = null; (a?.b).c`)
+	evalExpectError(t, `var a = {}; a?.b.c`)
+	expectUndefined(t, `var a = {}; a.b?.()()`)
+
+	// A parenthesized chain ending in a member access still calls it as a
+	// method.
+	expectNumber(t, `var o = {n: 2, m: function () { return this.n; }}; (o?.m)()`, 2)
+	expectNumber(t, `var o = {p: {n: 3, m() { return this.n; }}}; (o?.p.m)()`, 3)
+	expectNumber(t, `var o = {n: 4, m() { return this.n; }}; (o?.["m"])()`, 4)
+	evalExpectError(t, `var o = null; (o?.m)()`)
+}
+
+func TestOptionalChainDelete(t *testing.T) {
+	expectBool(t, `var a = null; delete a?.b`, true)
+	expectBool(t, `var a; delete a?.b.c`, true)
+	expectBool(t, `var a = {b: {c: 1}}; delete a?.b.c && !("c" in a.b)`, true)
+	expectBool(t, `var a = {b: 1}; delete a?.b && a.b === undefined`, true)
+	expectNumber(t, `
+		var n = 0;
+		var a = null;
+		delete a?.[n++].c;
+		n`, 0)
+}
+
+func TestEvalTagged(t *testing.T) {
+	interp := New()
+	if _, err := interp.EvalTagged("a", `var n = 0; for (var i = 0; i < 3; i++) { n++; }`); err != nil {
+		t.Fatalf("EvalTagged error: %v", err)
+	}
+	_, err := interp.EvalTagged("b", `throw 1;`)
+	var tagged *TaggedError
+	if !errors.As(err, &tagged) || tagged.Tag != "b" {
+		t.Fatalf("expected a *TaggedError for b, got %v", err)
+	}
+	if v, ok := runtime.ThrownValue(err); !ok || v.Number != 1 {
+		t.Errorf("expected thrown value 1, got %v", v)
+	}
+
+	a, b := interp.TagStats("a"), interp.TagStats("b")
+	if a.Evals != 1 || a.Errors != 0 || a.Statements < 8 {
+		t.Errorf("stats for a: %+v", a)
+	}
+	if b.Evals != 1 || b.Errors != 1 || b.Statements != 1 {
+		t.Errorf("stats for b: %+v", b)
+	}
+	if (interp.TagStats("c") != TagStats{}) {
+		t.Errorf("expected no stats for an unused tag")
+	}
+	if interp.Interrupt("a") {
+		t.Errorf("Interrupt should report that a is not running")
+	}
+
+	// Stack traces show the tag of each frame, and untagged frames none.
+	interp = newTestInterp(t)
+	if _, err := interp.Eval(`function untagged() { return new Error("x").stack; }`); err != nil {
+		t.Fatal(err)
+	}
+	v, err := interp.EvalTagged("c", "function f() {\n  return untagged();\n}\nf();")
+	if want := "Error: x\n    at untagged (<anonymous>:1:30) [c]\n    at f (<anonymous>:2:10) [c]\n    at <anonymous>:4:1 [c]"; err != nil || v.ToString() != want {
+		t.Errorf("expected tagged frames, got %q, %v", v.ToString(), err)
+	}
+	if v, _ := interp.Eval(`untagged()`); strings.Contains(v.ToString(), "[c]") {
+		t.Errorf("expected no tag outside the tagged evaluation, got %q", v.ToString())
+	}
+	_, err = interp.EvalTagged("d", "\n  null.x;")
+	if err == nil || !strings.HasSuffix(err.Error(), "at <anonymous>:2:3 [d]") {
+		t.Errorf("expected the tag in the error location, got %v", err)
+	}
+}
+
+func TestEvalWithOptions(t *testing.T) {
+	interp := New()
+
+	// The step budget stops an endless loop, and catch does not resume it.
+	_, err := interp.EvalWithOptions(`var caught = false; while (true) { try { for (;;) {} } catch (e) { caught = true; } }`, EvalOptions{MaxSteps: 1000})
+	var limit *LimitError
+	if !errors.As(err, &limit) || !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("expected a step limit error, got %v", err)
+	}
+	if caught, _ := interp.GlobalEnv().Get("caught"); caught.Bool {
+		t.Errorf("the step limit should not be caught")
+	}
+	if _, err := interp.EvalWithOptions(`for (var i = 0; i < 10; i++) {}`, EvalOptions{MaxSteps: 1000}); err != nil {
+		t.Errorf("a script within its budget: %v", err)
+	}
+
+	// A context deadline works as a timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = interp.EvalWithOptions(`while (true) {}`, EvalOptions{Context: ctx})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	// Deep recursion throws a catchable RangeError.
+	opts := EvalOptions{MaxCallDepth: 50}
+	v, err := interp.EvalWithOptions(`
+		function down(n) { return n === 0 ? 0 : 1 + down(n - 1); }
+		var shallow = down(49);
+		var msg;
+		try { down(50); } catch (e) { msg = e.name + ": " + e.message; }
+		shallow + " " + msg;
+	`, opts)
+	if err != nil || v.ToString() != "49 RangeError: Maximum call stack size exceeded" {
+		t.Errorf("call depth: got %v, %v", v, err)
+	}
+	if _, err := interp.Eval(`down(100)`); err != nil {
+		t.Errorf("the depth limit should end with the evaluation: %v", err)
+	}
+}
+
+func TestDefaultCallDepth(t *testing.T) {
+	// Without options, runaway recursion throws a RangeError instead of
+	// overflowing the Go stack, through every kind of call.
+	interp := newTestInterp(t)
+	v, err := interp.Eval(`
+		function overflows(f) {
+			try { f(); return "no error"; } catch (e) { return e.name; }
+		}
+		[
+			overflows(function f() { return f(); }),
+			overflows(function f() { return [0].map(f)[0]; }),
+			overflows(function () { class A { constructor() { new A(); } } new A(); }),
+			overflows(function () { var o = { get x() { return this.x; } }; o.x; }),
+			overflows(function f() { [2, 1].sort(function () { f(); return 0; }); }),
+			overflows(function () { var a = []; for (var i = 0; i < 20000; i++) a = [a]; "" + a; }),
+		].join();
+	`)
+	if err != nil || v.ToString() != "RangeError,RangeError,RangeError,RangeError,RangeError,RangeError" {
+		t.Errorf("got %v, %v", v, err)
+	}
+
+	interp.SetMaxCallDepth(10)
+	v, err = interp.Eval(`
+		function down(n) { return n === 0 ? 0 : 1 + down(n - 1); }
+		var shallow = down(9);
+		try { down(10); } catch (e) { shallow += " " + e.name; }
+		shallow;
+	`)
+	if err != nil || v.ToString() != "9 RangeError" {
+		t.Errorf("SetMaxCallDepth(10): got %v, %v", v, err)
+	}
+	if _, err := interp.EvalWithOptions(`down(20)`, EvalOptions{MaxCallDepth: 100}); err == nil {
+		t.Errorf("EvalOptions should not raise the interpreter's limit")
+	}
+}
+
+func TestEvalHeapLimit(t *testing.T) {
+	interp := newTestInterp(t)
+	opts := EvalOptions{MaxHeapBytes: 1 << 20}
+	for _, src := range []string{
+		`var s = "x"; while (true) { s += s; }`,
+		`var a = []; for (var i = 0; ; i += 1000) { a[i] = i; }`,
+		`var a = []; while (true) { a.push(a.length); }`,
+		`var o = {}; for (var i = 0; ; i++) { o["k" + i] = i; }`,
+		`while (true) { ({ a: 1, b: [1, 2, 3] }); }`,
+		"var s = 'x'; while (true) { s = `${s}${s}`; }",
+	} {
+		_, err := interp.EvalWithOptions(src, opts)
+		if err == nil || !strings.HasPrefix(err.Error(), "RangeError: heap limit exceeded at ") {
+			t.Errorf("%s: expected a heap limit error, got %v", src, err)
+		}
+	}
+
+	// The RangeError is catchable; the refused allocation is not counted,
+	// so smaller ones still succeed.
+	v, err := interp.EvalWithOptions(`
+		var msg;
+		try { var big = []; big[1000] = 0; } catch (e) { msg = e.name + ": " + e.message; }
+		var sparse = [];
+		sparse[1e9] = 0;
+		msg + " " + [1, 2, 3].length + " " + sparse.length;
+	`, EvalOptions{MaxHeapBytes: 4096})
+	if err != nil || v.ToString() != "RangeError: heap limit exceeded 3 1000000001" {
+		t.Errorf("caught heap limit: got %v, %v", v, err)
+	}
+	if _, err := interp.Eval(`var big = []; big[100000] = 0;`); err != nil {
+		t.Errorf("the heap limit should end with the evaluation: %v", err)
+	}
+}
+
+// TestEvalHeapLimitBuiltins checks that the built-ins allocating in bulk
+// charge the heap budget: each of these allocates well over a megabyte.
+func TestEvalHeapLimitBuiltins(t *testing.T) {
+	interp := newTestInterp(t)
+	opts := EvalOptions{MaxHeapBytes: 1 << 20}
+	for _, src := range []string{
+		`Array.from({ length: 5e6 })`,
+		`Array.from("x".repeat(600000))`,
+		`var a = new Array(40000).fill(0); Array.of.apply(null, a)`,
+		`var a = new Array(40000).fill(0); a.concat(a)`,
+		`var a = new Array(40000).fill(0); a.slice()`,
+		`var a = new Array(40000).fill(0); a.map(x => x)`,
+		`var a = new Array(40000).fill(0); a.filter(x => true)`,
+		`var a = new Array(40000).fill([0]); a.flat()`,
+		`var a = new Array(40000).fill(0); a.flatMap(x => [x])`,
+		`var a = new Array(40000).fill(0); a.splice(0)`,
+		`new Array(5e7).fill(0)`,
+		`new Array(2e6).join("x")`,
+		`JSON.stringify(new Array(5e6))`,
+		`JSON.stringify("x".repeat(600000))`,
+		`var o = {}; for (var i = 0; i < 10000; i++) o["k" + i] = i; Object.entries(o)`,
+		`"x".repeat(2e6)`,
+		`"x".padStart(2e6)`,
+		`var s = "x".repeat(600000); s.concat(s)`,
+		`"x".repeat(600000).split("")`,
+		`",".repeat(600000).split(",")`,
+		`"x".repeat(600000).split(/(?:)/)`,
+		`"x".repeat(600000).replaceAll("x", "yy")`,
+		`"x".repeat(2000).replace(/x/g, "y".repeat(1000))`,
+	} {
+		_, err := interp.EvalWithOptions(src, opts)
+		if err == nil || !strings.HasPrefix(err.Error(), "RangeError: heap limit exceeded") {
+			t.Errorf("%s: expected a heap limit error, got %v", src, err)
+		}
+	}
+}
+
+func TestInterruptTagged(t *testing.T) {
+	interp := New()
+	started := make(chan struct{})
+	interp.RegisterNative("started", func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		close(started)
+		return runtime.Undefined, nil
+	})
+	go func() {
+		<-started
+		for !interp.Interrupt("tenant") {
+		}
+	}()
+	// Neither catch nor finally keeps an interrupted evaluation going.
+	_, err := interp.EvalTagged("tenant", `
+		started();
+		var caught = false;
+		while (true) {
+			try { for (;;) {} } catch (e) { caught = true; } finally { caught = "finally"; }
+		}`)
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Tag != "tenant" {
+		t.Fatalf("expected an *InterruptedError, got %v", err)
+	}
+	if caught, _ := interp.GlobalEnv().Get("caught"); caught.Type != runtime.TypeBoolean || caught.Bool {
+		t.Errorf("interrupt should not be caught, caught = %v", caught)
+	}
+	if s := interp.TagStats("tenant"); s.Interrupts != 1 || s.Errors != 1 {
+		t.Errorf("stats: %+v", s)
+	}
+	// The interrupt ends with the evaluation.
+	if v, err := interp.EvalTagged("tenant", `1 + 1`); err != nil || v.Number != 2 {
+		t.Errorf("next evaluation: got %v, %v", v, err)
+	}
+}
+
+func TestCompiledMode(t *testing.T) {
+	sources := []string{
+		benchCallSource,
+		benchFunctionLoopSource,
+		`function f(a, b) { let s = ""; for (let i = 0; i < a; i++) { s += b[i % b.length]; } return s; } f(7, "xyz");`,
+		`function f(n) { var i = 0, out = []; do { if (i === 2) { i++; continue; } out.push(i * i); i++; } while (i < n); while (true) { if (out.length > 5) { break; } out.push(-1); } return out.join(); } f(4);`,
+		`function f(o) { o.n++; ++o.n; o["m"] = o.n * 2; o.m -= 1; var k = "n"; o[k] += 10; return [o.n, o.m, o.n-- + --o.n, typeof o, typeof missing, void 0, !o, -o.n, ~o.n].join(); } f({n: 1});`,
+		`function f(x) { return x && x.y || x ?? "none"; } [f(0), f({y: 1}), f(null), f(undefined), f("s")].join();`,
+		`function f(x) { return x > 1 ? x >= 3 ? "big" : "two" : x <= 0 ? "none" : "one"; } [0, 1, 2, 3].map(f).join();`,
+		`function f() { return [1 + "2", "a" < "b", 2 ** 10, 7 % 3, 1 / 0, 5 >>> 1, 3 & 6, 1 == "1", null != undefined, "k" in {k: 1}, [] instanceof Array].join(); } f();`,
+		`function Point(x, y) { this.x = x; this.y = y; } function make() { var p = new Point(1, 2); return p.x + p.y + (p instanceof Point ? 1 : 0); } make();`,
+		`var counter = 0; function bump() { counter++; total = counter * 2; return this === globalThis; } var total; [bump(), bump(), counter, total].join();`,
+		`var fact = function me(n) { return n <= 1 ? 1 : n * me(n - 1); }; fact(10);`,
+		`function f() { var o = {a: 1, "b": [2, 3], c: {d: "e"}}; return JSON.stringify(o) + o.b.length; } f();`,
+		`function f(s) { return s.toUpperCase().split("").reverse().join("-") + s.length + s[1]; } f("abc");`,
+		`function f() { x; let x = 1; } try { f(); } catch (e) { e.name + ": " + e.message; }`,
+		`function f() { return undefinedName; } try { f(); } catch (e) { e.message; }`,
+		`function f(o) { return o.nope(); } try { f({}); } catch (e) { e.message; }`,
+		`function f() { var g = 1; g(); } try { f(); } catch (e) { e.message; }`,
+		`function f(C) { return new C(); } try { f(1); } catch (e) { e.message; }`,
+		`function f(v) { throw v; } try { f("thrown"); } catch (e) { e; }`,
+		`function f(n) { { let n2 = n * 2; { const n3 = n2 + 1; n = n3; } } return n; } f(4);`,
+		`function f(a, b, c) { return [a, b, c].join("/"); } f(1);`,
+		`function f() { return arguments.length; } f(1, 2, 3);`,
+		`function f(n) { var g = function () { return n; }; return g(); } f(5);`,
+		`function counter() { var n = 0; return { inc: () => ++n, get: function () { return n; } }; } var c = counter(); c.inc(); c.inc(); c.get() + c.inc.name + c.get.name;`,
+		`function f() { var fns = []; for (var i = 0; i < 3; i++) { let j = i; const k = j * 2; fns.push(() => j + k); if (i === 1) { break; } } return fns.map(g => g()).join(); } f();`,
+		`function f() { var fns = []; for (let i = 0; i < 3; i++) { fns.push(() => i); } return fns.map(g => g()).join(); } f();`,
+		`function f() { var fns = [], i = 0; while (i < 4) { let v = i++; if (v % 2) { continue; } fns.push(function () { return v; }); } return fns.map(g => g()).join(); } f();`,
+		`function Obj() { this.v = 7; var get = () => this.v; this.get = get; } new Obj().get();`,
+		`function outer(n) { return inner(n); function inner(m) { return m <= 0 ? "done" : inner(m - 1); } } outer(5);`,
+		`function f() { var g = () => late; try { g(); } catch (e) { return e.message; } let late = 1; } f();`,
+		`function f() { const seen = []; let x = 1; const add = v => { x += v; seen.push(x); }; add(2); add(3); return seen.join() + ":" + x; } f();`,
+		`function f() { var me = function self(n) { return n ? self(n - 1) + n : 0; }; return me(4) + me.name; } f();`,
+		`function f() { var g = function () { return typeof g; }; return g(); } f();`,
+		`function f(n) { if (n > 0) { function g() { return 1; } } return typeof g; } f(1);`,
+		`function f() { return [1, 2, 3].map(function (x) { return x * this.k; }, {k: 2}).join(); } f();`,
+		`function Point() { var t = () => new.target === Point; return t(); } new Point();`,
+		`function f() { var a = () => arguments.length; return a(); } f(1, 2);`,
+		`function f() { try { return 1; } finally { } } f();`,
+		`var o = { v: 3, get() { return this.v; } }; function f() { return o.get() + [1, 2].indexOf(2); } f();`,
+		`function f(n) { return n.toFixed(2) + (1.5).toString(); } f(3);`,
+		`function f(a) { let b = 1; { let c = 2; { const d = 3; a += b + c + d; c++; } b = () => a + c; } return b() + a; } f(1);`,
+		`function f() { var g = () => x; try { x; } catch (e) { return e.message + g.length; } let x = 1; } f();`,
+		`function f() { let x = 1; const g = () => x++; { let y = x; { g(); y += x; } return [x, y, () => y].length + x + y; } } f();`,
+		`function f() { var fns = []; for (var i = 0; i < 3; i++) { let j = i; { let k = j * 10; fns.push(() => j + k); } } return fns.map(g => g()).join(); } f();`,
+	}
+	run := func(mode Mode, source string) string {
+		interp := newTestInterp(t)
+		interp.SetMode(mode)
+		val, err := interp.Eval(source)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return val.ToString()
+	}
+	for _, source := range sources {
+		want, got := run(TreeWalk, source), run(Compiled, source)
+		if got != want {
+			t.Errorf("%s\ncompiled: %q\nwalked:   %q", source, got, want)
+		}
+	}
+}
+
+func TestTypeofIdentifier(t *testing.T) {
+	source := `
+		var out = [];
+		function args() { return typeof arguments; }
+		function missing() { return typeof notDeclared; }
+		function local() { try { typeof x; let x = 1; } catch (e) { return e.name; } }
+		out.push(args(), missing(), local());
+		{
+			let early = function () { return typeof later; };
+			try { early(); } catch (e) { out.push(e.name); }
+			let later = 1;
+			out.push(early());
+		}
+		out.join();
+	`
+	for _, mode := range []Mode{TreeWalk, Compiled} {
+		interp := newTestInterp(t)
+		interp.SetMode(mode)
+		val, err := interp.Eval(source)
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		if want := "object,undefined,ReferenceError,ReferenceError,number"; val.ToString() != want {
+			t.Errorf("mode %d: got %q, want %q", mode, val.ToString(), want)
+		}
+	}
+}
+
+func TestStrictDeleteAndTruncation(t *testing.T) {
+	source := `
+		var sealed = Object.seal({ a: 1 });
+		var pinned = [1, 2, 3];
+		Object.defineProperty(pinned, 1, { value: 2, configurable: false });
+		var fixed = Object.defineProperty([1], "length", { writable: false });
+		function attempt(f) { try { return String(f()); } catch (e) { return e.name; } }
+		[
+			attempt(function () { return delete sealed.a; }),
+			attempt(function () { "use strict"; return delete sealed.a; }),
+			attempt(function () { "use strict"; return delete sealed.b; }),
+			attempt(function () { "use strict"; delete new Proxy({}, { deleteProperty() { return false; } }).x; }),
+			attempt(function () { pinned.length = 0; return pinned.length; }),
+			attempt(function () { "use strict"; pinned.length = 0; }),
+			pinned.length,
+			attempt(function () { "use strict"; fixed.length = 0; }),
+			attempt(function () { "use strict"; fixed.length = 1; return fixed.length; }),
+		].join();
+	`
+	want := "false,TypeError,true,TypeError,2,TypeError,2,TypeError,1"
+	for _, mode := range []Mode{TreeWalk, Compiled} {
+		interp := newTestInterp(t)
+		interp.SetMode(mode)
+		val, err := interp.Eval(source)
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		if val.ToString() != want {
+			t.Errorf("mode %d: got %q, want %q", mode, val.ToString(), want)
+		}
+	}
+}
+
+func TestStrictAssignmentToFrozen(t *testing.T) {
+	// Each function reports whether its assignments threw, as the error
+	// name or "ok".
+	source := `
+		var frozen = Object.freeze({ a: 1, nested: [1] });
+		var arr = Object.freeze([1, 2]);
+		var getter = { get x() { return 1; } };
+		function attempt(f) { try { f(); return "ok"; } catch (e) { return e.name; } }
+		function sloppyWrite() { frozen.a = 2; }
+		function sloppyAdd() { frozen.b = 2; }
+		function sloppyElement() { arr[0] = 9; }
+		function strictWrite() { "use strict"; frozen.a = 2; }
+		function strictAdd() { "use strict"; frozen.b = 2; }
+		function strictElement() { "use strict"; arr[0] = 9; }
+		function strictUpdate() { "use strict"; frozen.a++; }
+		function strictGetter() { "use strict"; getter.x = 2; }
+		function strictNested() { "use strict"; return function () { frozen.a = 2; }(); }
+		var strictArrow = function () { "use strict"; return () => { frozen.a = 2; }; }();
+		class C { m() { frozen.a = 2; } }
+		[
+			attempt(sloppyWrite), attempt(sloppyAdd), attempt(sloppyElement),
+			attempt(strictWrite), attempt(strictAdd), attempt(strictElement),
+			attempt(strictUpdate), attempt(strictGetter), attempt(strictNested),
+			attempt(strictArrow), attempt(() => new C().m()),
+			frozen.a, frozen.b, arr[0],
+		].join();
+	`
+	want := "ok,ok,ok,TypeError,TypeError,TypeError,TypeError,TypeError,TypeError,TypeError,TypeError,1,,1"
+	for _, mode := range []Mode{TreeWalk, Compiled} {
+		interp := newTestInterp(t)
+		interp.SetMode(mode)
+		val, err := interp.Eval(source)
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		if val.ToString() != want {
+			t.Errorf("mode %d: got %q, want %q", mode, val.ToString(), want)
+		}
+	}
+
+	// A strict script is strict at the top level, and a sloppy script
+	// evaluated after it is not.
+	interp := newTestInterp(t)
+	if _, err := interp.Eval(`"use strict"; var f = Object.freeze({ a: 1 }); f.a = 2;`); err == nil || !strings.Contains(err.Error(), "TypeError: Cannot assign to read only property 'a'") {
+		t.Errorf("strict script: got %v", err)
+	}
+	expectNumber(t, `var f = Object.freeze({ a: 1 }); f.a = 2; f.a;`, 1)
+	if _, err := interp.Eval(`f.a = 3; f.a;`); err != nil {
+		t.Errorf("sloppy script after a strict one: %v", err)
+	}
+}
+
+func TestCompileFunction(t *testing.T) {
+	interp := New()
+	interp.SetMode(Compiled)
+	if _, err := interp.Eval(`
+		function plain(n) { var s = 0; for (let i = 0; i < n; i++) { s += i; } return s; }
+		function closure(n) { return function () { return n; }; }
+		function usesArguments() { return arguments[0]; }
+		function usesTry() { try { return 1; } finally {} }
+		plain(3); closure(1); usesArguments(); usesTry();
+	`); err != nil {
+		t.Fatal(err)
+	}
+	// plain and closure are compiled, but not usesArguments and usesTry,
+	// nor the function closure returns, which is never called.
+	compiled, walked := 0, 0
+	for _, code := range interp.compiled {
+		if code != nil {
+			compiled++
+		} else {
+			walked++
+		}
+	}
+	if compiled != 2 || walked != 2 {
+		t.Errorf("compiled %d and walked %d functions, want 2 and 2", compiled, walked)
+	}
+}
+
+// TestCompiledEnvironmentSlots checks that compiled code reaches the locals
+// closures capture by number, looking up only the names it does not declare.
+func TestCompiledEnvironmentSlots(t *testing.T) {
+	interp := New()
+	interp.SetMode(Compiled)
+	val, err := interp.Eval(`
+		var outside = 10;
+		function f(a) { let b = 1; { let c = 2; a += b + c + outside; } return () => a + b; }
+		f(1)();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.Number != 15 {
+		t.Errorf("f(1)() = %v, want 15", val.Number)
+	}
+	var names []string
+	for _, code := range interp.compiled {
+		if code == nil || len(code.refs) == 0 {
+			continue
+		}
+		for _, in := range code.code {
+			if in.op == opLoadName || in.op == opStoreName {
+				names = append(names, code.nodes[in.a].(*ast.Identifier).Value)
+			}
+		}
+	}
+	if len(names) != 1 || names[0] != "outside" {
+		t.Errorf("names looked up: %v, want [outside]", names)
+	}
+}
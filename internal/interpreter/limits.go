@@ -0,0 +1,193 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// EvalOptions limits the resources of an evaluation, so that a host can run
+// untrusted scripts without hanging on while (true) {}. A zero field sets
+// no limit.
+type EvalOptions struct {
+	// Context stops the evaluation at its next statement once it is done,
+	// which gives a wall-clock timeout with context.WithTimeout.
+	Context context.Context
+	// MaxSteps is the number of statements the evaluation may execute.
+	MaxSteps int64
+	// MaxCallDepth is the number of nested function calls allowed, if
+	// fewer than SetMaxCallDepth allows. A call beyond it throws a
+	// RangeError, which scripts can catch.
+	MaxCallDepth int
+	// MaxHeapBytes is the approximate number of bytes the evaluation may
+	// allocate for objects, array elements and strings, see
+	// runtime.HeapBudget. An allocation beyond it throws a RangeError.
+	MaxHeapBytes int64
+}
+
+// DefaultMaxCallDepth is the number of nested function calls an interpreter
+// allows unless SetMaxCallDepth says otherwise. A call beyond it throws a
+// RangeError, as an engine's would: runaway recursion must not overflow the
+// Go stack, which crashes the whole process.
+const DefaultMaxCallDepth = 10000
+
+// SetMaxCallDepth sets the number of nested function calls allowed in every
+// evaluation; 0 removes the limit. EvalOptions.MaxCallDepth can only lower
+// it.
+func (interp *Interpreter) SetMaxCallDepth(n int) { interp.maxDepth = n }
+
+// ErrStepLimit is the cause of a LimitError for an evaluation that used up
+// its EvalOptions.MaxSteps.
+var ErrStepLimit = errors.New("step limit exceeded")
+
+// LimitError is the error of an evaluation stopped by its EvalOptions. Err
+// is ErrStepLimit, or the error of the done context, so errors.Is(err,
+// context.DeadlineExceeded) detects a timeout.
+type LimitError struct {
+	Err error
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("evaluation stopped: %v", e.Err)
+}
+
+func (e *LimitError) Unwrap() error { return e.Err }
+
+// limitValue is thrown at the next statement once an evaluation exceeds a
+// limit. Like interruptValue, try/catch does not catch it, and it is thrown
+// again at every statement until the evaluation returns.
+var limitValue = runtime.NewObject(runtime.NewOrdinaryObject(nil))
+
+// limitState is the budget of the evaluation run by EvalWithOptions.
+type limitState struct {
+	done     <-chan struct{}
+	ctx      context.Context
+	maxSteps int64
+	steps    int64
+	maxDepth int
+	err      *LimitError // set once a limit is exceeded
+}
+
+// EvalWithOptions parses and evaluates source like Eval, within the limits
+// of opts. An evaluation that exceeds its step budget or whose context is
+// done returns a *LimitError.
+func (interp *Interpreter) EvalWithOptions(source string, opts EvalOptions) (*runtime.Value, error) {
+	return interp.runLimited(opts, func() (*runtime.Value, error) {
+		return interp.Eval(source)
+	})
+}
+
+// RunWithOptions runs an already parsed program like Run, within the limits
+// of opts; see EvalWithOptions.
+func (interp *Interpreter) RunWithOptions(program *ast.Program, opts EvalOptions) (*runtime.Value, error) {
+	return interp.runLimited(opts, func() (*runtime.Value, error) {
+		return interp.Run(program)
+	})
+}
+
+func (interp *Interpreter) runLimited(opts EvalOptions, run func() (*runtime.Value, error)) (*runtime.Value, error) {
+	l := &limitState{ctx: opts.Context, maxSteps: opts.MaxSteps, maxDepth: opts.MaxCallDepth}
+	if l.ctx != nil {
+		l.done = l.ctx.Done()
+	}
+	if l.maxDepth > 0 {
+		// Only calls count: not the frames below the evaluation, nor the
+		// frame of the script itself.
+		l.maxDepth += 1 + interp.nativeDepth
+		if interp.frame != nil {
+			l.maxDepth += interp.frame.depth
+		}
+	}
+	prev := interp.limits
+	interp.limits = l
+	defer func() { interp.limits = prev }()
+	if opts.MaxHeapBytes > 0 {
+		prevHeap := interp.agent.Heap
+		interp.agent.Heap = &runtime.HeapBudget{Limit: opts.MaxHeapBytes}
+		defer func() { interp.agent.Heap = prevHeap }()
+	}
+
+	val, err := run()
+	if l.err != nil {
+		return nil, l.err
+	}
+	return val, err
+}
+
+// check counts a statement and reports whether the evaluation is over one
+// of its limits.
+func (l *limitState) check() bool {
+	if l.err != nil {
+		return true
+	}
+	l.steps++
+	if l.maxSteps > 0 && l.steps > l.maxSteps {
+		l.err = &LimitError{Err: ErrStepLimit}
+		return true
+	}
+	select {
+	case <-l.done:
+		l.err = &LimitError{Err: l.ctx.Err()}
+		return true
+	default:
+	}
+	return false
+}
+
+// checkCallDepth throws a RangeError when a call would nest deeper than the
+// interpreter's or the evaluation's MaxCallDepth. Conversions run by native
+// code count as calls, see enterNative.
+func (interp *Interpreter) checkCallDepth() error {
+	if interp.frame == nil {
+		return nil
+	}
+	depth := interp.frame.depth + interp.nativeDepth
+	over := interp.maxDepth > 0 && depth > interp.maxDepth
+	if l := interp.limits; l != nil && l.maxDepth > 0 && depth >= l.maxDepth {
+		over = true
+	}
+	if !over {
+		return nil
+	}
+	return &jsError{value: interp.makeErrorObject("RangeError", "Maximum call stack size exceeded", interp.global)}
+}
+
+// enterNative is the agent's EnterNativeHook: it counts a conversion run by
+// native code, such as Array.prototype.join converting an element, as a
+// call, so that native code converting objects whose methods are native in
+// turn cannot recurse past the call depth.
+func (interp *Interpreter) enterNative() (func(), error) {
+	interp.nativeDepth++
+	if err := interp.checkCallDepth(); err != nil {
+		interp.nativeDepth--
+		return nil, err
+	}
+	return func() { interp.nativeDepth-- }, nil
+}
+
+// charge counts an allocation of n bytes against the heap budget of the
+// evaluation, throwing a RangeError when it does not fit.
+func (interp *Interpreter) charge(n int64, env *runtime.Environment) signal {
+	if err := interp.agent.Charge(n); err != nil {
+		return signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+	}
+	return signal{}
+}
+
+// concatStrings evaluates the string concatenation of + and +=.
+func (interp *Interpreter) concatStrings(left, right *runtime.Value, env *runtime.Environment) (*runtime.Value, signal) {
+	l, r := left.ToString(), right.ToString()
+	if sig := interp.charge(int64(len(l)+len(r)), env); sig.typ != sigNone {
+		return nil, sig
+	}
+	return runtime.NewString(l + r), signal{}
+}
+
+// catchable reports whether try/catch may catch the thrown value: values
+// thrown to stop an interrupted evaluation or one over its limits are not.
+func catchable(val *runtime.Value) bool {
+	return val != interruptValue && val != limitValue
+}
@@ -0,0 +1,436 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/parser"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// ModuleResolver locates the module an import specifier refers to. referrer
+// is the name of the importing module, or "" for the entry module. It returns
+// the module's canonical name, which identifies the module in the cache, and
+// its source text.
+type ModuleResolver func(specifier, referrer string) (name, source string, err error)
+
+// defaultExportName is the local binding holding the value of an
+// `export default <expression>` or anonymous function declaration. The
+// function or class it holds is named "default".
+const defaultExportName = "*default*"
+
+type moduleStatus int
+
+const (
+	moduleLinked moduleStatus = iota
+	moduleEvaluating
+	moduleEvaluated
+)
+
+// module is a loaded ES module. Its top-level bindings live in env, which
+// importers link to directly, so exported bindings are live.
+type module struct {
+	name      string
+	program   *ast.Program
+	env       *runtime.Environment
+	body      []ast.Statement        // top-level statements with export wrappers removed
+	requested map[string]*module     // import specifier -> module
+	order     []*module              // requested modules in source order
+	imports   map[string]importEntry // local name -> imported binding
+	exports   map[string]string      // exported name -> local name
+	indirect  map[string]importEntry // exported name -> re-exported binding
+	stars     []*module              // export * from ...
+	namespace *runtime.Object
+	status    moduleStatus
+	err       error
+}
+
+// importEntry names a binding exported by another module. name is "*" for
+// the module namespace object.
+type importEntry struct {
+	from *module
+	name string
+}
+
+// SetModuleResolver sets the resolver used to load imported modules.
+func (interp *Interpreter) SetModuleResolver(resolve ModuleResolver) {
+	interp.resolveModule = resolve
+}
+
+// EvalModule loads the module specifier with the module resolver, evaluates
+// it and the modules it imports, and returns its namespace object. Each
+// module is evaluated at most once per interpreter; loading it again returns
+// the same namespace.
+func (interp *Interpreter) EvalModule(specifier string) (_ *runtime.Value, err error) {
+	if interp.resolveModule == nil {
+		return nil, fmt.Errorf("no module resolver set")
+	}
+	interp.prepareGlobalEnv()
+	defer interp.drainJobs(&err)
+
+	var loaded []*module
+	m, err := interp.loadModule(specifier, "", &loaded)
+	if err != nil {
+		// Forget the partially loaded graph so a later attempt starts over.
+		for _, l := range loaded {
+			delete(interp.modules, l.name)
+		}
+		return nil, err
+	}
+	for _, l := range loaded {
+		interp.instantiateModule(l)
+	}
+	for _, l := range loaded {
+		if err := interp.linkModule(l); err != nil {
+			for _, l := range loaded {
+				delete(interp.modules, l.name)
+			}
+			return nil, err
+		}
+	}
+	if err := interp.evaluateModule(m); err != nil {
+		return nil, err
+	}
+	return runtime.NewObject(interp.moduleNamespace(m)), nil
+}
+
+// loadModule resolves and parses a module and, recursively, every module it
+// requests. Modules are cached before their dependencies load, so import
+// cycles terminate. Newly parsed modules are appended to loaded.
+func (interp *Interpreter) loadModule(specifier, referrer string, loaded *[]*module) (*module, error) {
+	name, source, err := interp.resolveModule(specifier, referrer)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := interp.modules[name]; ok {
+		return m, nil
+	}
+	program, errs := parser.New(source).ParseModule()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: parse errors: %v", name, errs)
+	}
+	m := &module{
+		name:      name,
+		program:   program,
+		requested: make(map[string]*module),
+		imports:   make(map[string]importEntry),
+		exports:   make(map[string]string),
+		indirect:  make(map[string]importEntry),
+	}
+	if interp.modules == nil {
+		interp.modules = make(map[string]*module)
+	}
+	interp.modules[name] = m
+	*loaded = append(*loaded, m)
+
+	request := func(src *ast.StringLiteral) (*module, error) {
+		if dep, ok := m.requested[src.Value]; ok {
+			return dep, nil
+		}
+		dep, err := interp.loadModule(src.Value, name, loaded)
+		if err != nil {
+			return nil, err
+		}
+		m.requested[src.Value] = dep
+		m.order = append(m.order, dep)
+		return dep, nil
+	}
+
+	for _, stmt := range program.Statements {
+		switch s := stmt.(type) {
+		case *ast.ImportDeclaration:
+			dep, err := request(s.Source)
+			if err != nil {
+				return nil, err
+			}
+			for _, spec := range s.Specifiers {
+				m.imports[spec.Local.Value] = importEntry{from: dep, name: spec.Imported}
+			}
+		case *ast.ExportAllDeclaration:
+			dep, err := request(s.Source)
+			if err != nil {
+				return nil, err
+			}
+			if s.Exported != "" {
+				m.indirect[s.Exported] = importEntry{from: dep, name: "*"}
+			} else {
+				m.stars = append(m.stars, dep)
+			}
+		case *ast.ExportNamedDeclaration:
+			if s.Declaration != nil {
+				m.body = append(m.body, s.Declaration)
+				for _, local := range declaredNames(interp, s.Declaration) {
+					m.exports[local] = local
+				}
+				continue
+			}
+			if s.Source != nil {
+				dep, err := request(s.Source)
+				if err != nil {
+					return nil, err
+				}
+				for _, spec := range s.Specifiers {
+					m.indirect[spec.Exported] = importEntry{from: dep, name: spec.Local}
+				}
+				continue
+			}
+			for _, spec := range s.Specifiers {
+				m.exports[spec.Exported] = spec.Local
+			}
+		case *ast.ExportDefaultDeclaration:
+			switch d := s.Declaration.(type) {
+			case *ast.FunctionDeclaration:
+				if d.Name == nil {
+					fn := *d
+					fn.Name = &ast.Identifier{Token: d.Token, Value: defaultExportName}
+					d = &fn
+				}
+				m.body = append(m.body, d)
+				m.exports["default"] = d.Name.Value
+			case *ast.ClassDeclaration:
+				m.body = append(m.body, d)
+				m.exports["default"] = d.Name.Value
+			case ast.Expression:
+				m.body = append(m.body, &ast.VariableDeclaration{
+					SourceSpan: s.SourceSpan,
+					Token:      s.Token,
+					Kind:       "const",
+					Declarations: []*ast.VariableDeclarator{{
+						Name:  &ast.Identifier{Token: s.Token, Value: defaultExportName},
+						Value: d,
+					}},
+				})
+				m.exports["default"] = defaultExportName
+			}
+		default:
+			m.body = append(m.body, stmt)
+		}
+	}
+	return m, nil
+}
+
+// declaredNames returns the names bound by a variable, function or class
+// declaration.
+func declaredNames(interp *Interpreter, stmt ast.Statement) []string {
+	switch d := stmt.(type) {
+	case *ast.VariableDeclaration:
+		var names []string
+		for _, decl := range d.Declarations {
+			names = append(names, interp.extractBindingNames(decl.Name)...)
+		}
+		return names
+	case *ast.FunctionDeclaration:
+		return []string{d.Name.Value}
+	case *ast.ClassDeclaration:
+		if d.Name != nil {
+			return []string{d.Name.Value}
+		}
+	}
+	return nil
+}
+
+// instantiateModule creates the module scope: var and function declarations
+// are hoisted and let, const and class bindings are created uninitialized.
+func (interp *Interpreter) instantiateModule(m *module) {
+	m.env = runtime.NewEnvironment(interp.global, false)
+	m.env.SetStrict(true)
+	// Imports are bound after the hoisted functions are created, so those
+	// closures look module-level names up when they run.
+	m.env.MarkCaptureBoundary()
+	interp.hoist(m.body, m.env)
+	if b, ok := m.env.GetBinding(defaultExportName); ok && b.Kind == "function" {
+		nameMethod(b.Value, "", "default")
+	}
+	for _, stmt := range m.body {
+		switch s := stmt.(type) {
+		case *ast.VariableDeclaration:
+			if s.Kind != "var" {
+				for _, name := range declaredNames(interp, s) {
+					m.env.DeclareUninitialized(name, s.Kind)
+				}
+			}
+		case *ast.ClassDeclaration:
+			for _, name := range declaredNames(interp, s) {
+				m.env.DeclareUninitialized(name, "let")
+			}
+		}
+	}
+}
+
+// linkModule binds each import of m to the exporting module's binding.
+func (interp *Interpreter) linkModule(m *module) error {
+	for local, imp := range m.imports {
+		if imp.name == "*" {
+			m.env.Declare(local, "const", runtime.NewObject(interp.moduleNamespace(imp.from)))
+			continue
+		}
+		b, err := interp.resolveExport(imp.from, imp.name, nil)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("SyntaxError: The requested module '%s' does not provide an export named '%s'", imp.from.name, imp.name)
+		}
+		m.env.Import(local, b)
+	}
+	return nil
+}
+
+// resolveExport finds the binding behind the export name of m, following
+// imports, re-exports and star exports. It returns nil when there is no
+// such export and an error when star exports make the name ambiguous.
+// A namespace re-export (export * as ns) resolves to a fresh binding
+// holding the namespace object.
+func (interp *Interpreter) resolveExport(m *module, name string, visiting map[*module]map[string]bool) (*runtime.Binding, error) {
+	if visiting == nil {
+		visiting = make(map[*module]map[string]bool)
+	}
+	if visiting[m][name] {
+		return nil, nil // circular re-export
+	}
+	if visiting[m] == nil {
+		visiting[m] = make(map[string]bool)
+	}
+	visiting[m][name] = true
+
+	if local, ok := m.exports[name]; ok {
+		if imp, ok := m.imports[local]; ok {
+			return interp.resolveImport(imp, visiting)
+		}
+		b, ok := m.env.GetBinding(local)
+		if !ok {
+			return nil, fmt.Errorf("SyntaxError: Export '%s' is not defined in module '%s'", local, m.name)
+		}
+		return b, nil
+	}
+	if imp, ok := m.indirect[name]; ok {
+		return interp.resolveImport(imp, visiting)
+	}
+	if name == "default" {
+		return nil, nil // star exports never provide a default
+	}
+	var found *runtime.Binding
+	for _, star := range m.stars {
+		b, err := interp.resolveExport(star, name, visiting)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+		if found != nil && found != b {
+			return nil, fmt.Errorf("SyntaxError: The requested module '%s' contains conflicting star exports for name '%s'", m.name, name)
+		}
+		found = b
+	}
+	return found, nil
+}
+
+func (interp *Interpreter) resolveImport(imp importEntry, visiting map[*module]map[string]bool) (*runtime.Binding, error) {
+	if imp.name == "*" {
+		return &runtime.Binding{
+			Value:    runtime.NewObject(interp.moduleNamespace(imp.from)),
+			Kind:     "const",
+			Declared: true,
+		}, nil
+	}
+	b, err := interp.resolveExport(imp.from, imp.name, visiting)
+	if err != nil || b != nil {
+		return b, err
+	}
+	return nil, fmt.Errorf("SyntaxError: The requested module '%s' does not provide an export named '%s'", imp.from.name, imp.name)
+}
+
+// exportedNames lists the names m exports, including those provided by star
+// exports. Names exported ambiguously through several star exports are left
+// out.
+func (interp *Interpreter) exportedNames(m *module, seen map[*module]bool) []string {
+	if seen[m] {
+		return nil
+	}
+	seen[m] = true
+	var names []string
+	for name := range m.exports {
+		names = append(names, name)
+	}
+	for name := range m.indirect {
+		names = append(names, name)
+	}
+	for _, star := range m.stars {
+		for _, name := range interp.exportedNames(star, seen) {
+			if name != "default" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// moduleNamespace returns the namespace object of m: a null-prototype object
+// with a getter per export that reads the live binding.
+func (interp *Interpreter) moduleNamespace(m *module) *runtime.Object {
+	if m.namespace != nil {
+		return m.namespace
+	}
+	ns := runtime.NewOrdinaryObject(nil)
+	ns.Prototype = nil
+	m.namespace = ns
+	names := interp.exportedNames(m, make(map[*module]bool))
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := ns.Properties[name]; ok {
+			continue
+		}
+		b, err := interp.resolveExport(m, name, nil)
+		if err != nil || b == nil {
+			continue
+		}
+		getter := runtime.NewFunctionObject(interp.realm.FunctionPrototype, func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+			for b.Target != nil {
+				b = b.Target
+			}
+			if !b.Declared {
+				return runtime.Undefined, nil
+			}
+			return b.Value, nil
+		})
+		ns.DefineProperty(name, &runtime.Property{
+			Getter:     runtime.NewObject(getter),
+			Enumerable: true,
+			IsAccessor: true,
+		})
+	}
+	ns.DefineProperty("@@toStringTag", &runtime.Property{Value: runtime.NewString("Module")})
+	return ns
+}
+
+// evaluateModule evaluates the modules m requests and then m itself. A module
+// already being evaluated is part of a cycle and is skipped; its bindings are
+// read once it has run. Errors are cached so every importer sees the same
+// failure.
+func (interp *Interpreter) evaluateModule(m *module) error {
+	switch m.status {
+	case moduleEvaluated:
+		return m.err
+	case moduleEvaluating:
+		return nil
+	}
+	m.status = moduleEvaluating
+	for _, dep := range m.order {
+		if err := interp.evaluateModule(dep); err != nil {
+			m.status, m.err = moduleEvaluated, err
+			return err
+		}
+	}
+	leave := interp.enterFrame(nil, m.name)
+	for _, stmt := range m.body {
+		if _, sig := interp.execStatement(stmt, m.env); sig.typ == sigThrow {
+			m.err = interp.uncaught(sig.value)
+			break
+		}
+	}
+	leave()
+	m.status = moduleEvaluated
+	return m.err
+}
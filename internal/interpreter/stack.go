@@ -0,0 +1,151 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// stackTraceLimit is the number of frames an error's stack lists, as with
+// V8's default Error.stackTraceLimit.
+const stackTraceLimit = 10
+
+// anonymousFile names scripts that were not loaded from a file, such as
+// the source passed to Eval.
+const anonymousFile = "<anonymous>"
+
+// callFrame is an entry of the call stack that error stacks and the
+// locations of uncaught exceptions are built from. Script code runs in a
+// frame without a function. pos is the start of the statement or call the
+// frame is evaluating, depth the number of frames up to this one, and tag
+// that of the evaluation the frame was entered in, if any.
+type callFrame struct {
+	fn     *runtime.Object
+	file   string
+	tag    string
+	pos    ast.Position
+	caller *callFrame
+	depth  int
+}
+
+// location formats the frame's position as file:line:column.
+func (f *callFrame) location() string {
+	return fmt.Sprintf("%s:%d:%d", f.file, f.pos.Line, f.pos.Column)
+}
+
+// tagSuffix returns " [tag]" for a frame of a tagged evaluation, to follow
+// its location in stack traces, and "" otherwise.
+func (f *callFrame) tagSuffix() string {
+	if f.tag == "" {
+		return ""
+	}
+	return " [" + f.tag + "]"
+}
+
+// thrownAt records where the exception being propagated was thrown, see
+// noteThrow.
+type thrownAt struct {
+	value    *runtime.Value
+	location string
+}
+
+// enterFrame pushes a frame for a call of fn, or for script code in file
+// when fn is nil, and points the agent's stack and construct hooks at this
+// interpreter and its streams at the interpreter's own. The returned
+// function pops the frame and points the agent back at the caller, which
+// may be the interpreter of another realm.
+func (interp *Interpreter) enterFrame(fn *runtime.Object, file string) func() {
+	caller := interp.frame
+	interp.frame = &callFrame{fn: fn, file: file, caller: caller, depth: 1}
+	if c := interp.tags.current; c != nil {
+		interp.frame.tag = c.tag
+	}
+	if caller != nil {
+		interp.frame.depth = caller.depth + 1
+	}
+	agent := interp.agent
+	capture, construct, native, io := agent.CaptureStack, agent.ConstructHook, agent.EnterNativeHook, agent.IO
+	agent.CaptureStack = interp.stackTrace
+	agent.ConstructHook = interp.construct
+	agent.EnterNativeHook = interp.enterNative
+	agent.IO = interp.streams
+	return func() {
+		interp.frame = caller
+		agent.CaptureStack, agent.ConstructHook, agent.EnterNativeHook, agent.IO = capture, construct, native, io
+	}
+}
+
+// saveState saves the state of the evaluation running when the agent's
+// lock is released, and starts the scripts run meanwhile afresh: outside
+// of its frames, its coroutine and its limits. See runtime.Agent.SaveState.
+func (interp *Interpreter) saveState() func() {
+	frame, co, newTarget, loopLabels := interp.frame, interp.co, interp.newTarget, interp.loopLabels
+	thrown, limits, tag, native := interp.thrown, interp.limits, interp.tags.current, interp.nativeDepth
+	interp.frame, interp.co, interp.newTarget, interp.loopLabels = nil, nil, nil, nil
+	interp.thrown, interp.limits, interp.tags.current, interp.nativeDepth = thrownAt{}, nil, nil, 0
+	return func() {
+		interp.frame, interp.co, interp.newTarget, interp.loopLabels = frame, co, newTarget, loopLabels
+		interp.thrown, interp.limits, interp.tags.current, interp.nativeDepth = thrown, limits, tag, native
+	}
+}
+
+// currentFile returns the file of the code being evaluated.
+func (interp *Interpreter) currentFile() string {
+	if interp.frame == nil {
+		return anonymousFile
+	}
+	return interp.frame.file
+}
+
+// at records that the current frame is evaluating node.
+func (interp *Interpreter) at(node ast.Node) {
+	if f := interp.frame; f != nil {
+		if pos := node.Span().Start; pos.IsValid() {
+			f.pos = pos
+		}
+	}
+}
+
+// stackTrace lists the frames of the call stack, innermost first, in the
+// form V8 uses below the first line of an error's stack. The frames of a
+// tagged evaluation are followed by the tag.
+func (interp *Interpreter) stackTrace() string {
+	var sb strings.Builder
+	n := 0
+	for f := interp.frame; f != nil && n < stackTraceLimit; f = f.caller {
+		if n > 0 {
+			sb.WriteByte('\n')
+		}
+		n++
+		if f.fn == nil {
+			sb.WriteString("    at " + f.location() + f.tagSuffix())
+			continue
+		}
+		name := "<anonymous>"
+		if prop := f.fn.Properties["name"]; prop != nil && prop.Value != nil && prop.Value.Type == runtime.TypeString && prop.Value.Str != "" {
+			name = prop.Value.Str
+		}
+		sb.WriteString("    at " + name + " (" + f.location() + ")" + f.tagSuffix())
+	}
+	return sb.String()
+}
+
+// noteThrow records the current position as where val was thrown, unless
+// val is already on its way out from a deeper frame.
+func (interp *Interpreter) noteThrow(val *runtime.Value) {
+	if interp.thrown.value == val || interp.frame == nil {
+		return
+	}
+	interp.thrown = thrownAt{value: val, location: interp.frame.location() + interp.frame.tagSuffix()}
+}
+
+// uncaught returns the error for an exception that propagated out of a
+// script, carrying the location where it was thrown.
+func (interp *Interpreter) uncaught(val *runtime.Value) error {
+	interp.noteThrow(val)
+	err := &jsError{value: val, location: interp.thrown.location}
+	interp.thrown = thrownAt{}
+	return err
+}
@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+	"github.com/example/jsgo/internal/token"
+)
+
+// maxSuggestDistance is the largest edit distance at which a visible name is
+// offered as a suggestion for an undefined one.
+const maxSuggestDistance = 2
+
+// valueNames are globals that only hold a value. They make poor guesses for
+// names that are longer identifiers of their own, such as undefinedFn, so
+// they are offered only for near misses; see closestName.
+var valueNames = map[string]bool{"undefined": true, "NaN": true, "Infinity": true}
+
+// undefinedReference returns the ReferenceError thrown for an identifier that
+// does not resolve, suggesting the closest name visible from env, or among
+// the locals of compiled code, if there is one within maxSuggestDistance
+// edits.
+func (interp *Interpreter) undefinedReference(name string, env *runtime.Environment, locals ...string) *runtime.Value {
+	msg := name + " is not defined"
+	names := env.Names()
+	if len(locals) > 0 {
+		names = append(slices.Clone(locals), names...)
+		slices.Sort(names)
+	}
+	if match := closestName(name, names); match != "" {
+		msg += ". Did you mean '" + match + "'?"
+	}
+	return interp.makeErrorObject("ReferenceError", msg, env)
+}
+
+// closestName returns the candidate nearest to name by edit distance, or ""
+// if none is close enough. A suggestion must also take fewer edits than the
+// length of name, so that "x" is not matched to every one-letter name. One
+// of the valueNames must be within an edit per four letters of it, and is
+// never offered for a name that starts with it. Candidates are expected in
+// sorted order; ties go to the first.
+func closestName(name string, candidates []string) string {
+	best, bestDist := "", maxSuggestDistance+1
+	for _, c := range candidates {
+		// new.target is bound like a variable but cannot be named as one.
+		if c == name || c == "new.target" || token.IsReservedWord(c) {
+			continue
+		}
+		if d := abs(len(c) - len(name)); d >= bestDist {
+			continue
+		}
+		d := editDistance(name, c)
+		if valueNames[c] && (d > (len(c)+1)/4 || strings.HasPrefix(name, c)) {
+			continue
+		}
+		if d < bestDist && d < len(name) {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b, counted in
+// bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
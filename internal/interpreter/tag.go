@@ -0,0 +1,218 @@
+package interpreter
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// TagStats is the resource usage of the evaluations run under one tag.
+type TagStats struct {
+	Evals      int64         // tagged evaluations started
+	Errors     int64         // evaluations that ended with an error, interrupts included
+	Interrupts int64         // evaluations stopped by Interrupt
+	Statements int64         // statements executed, not counting nested evaluations under another tag
+	Duration   time.Duration // wall time, including nested evaluations
+}
+
+// TaggedError is the error of a tagged evaluation that failed. It wraps the
+// error Run would have returned, so runtime.ThrownValue still applies.
+type TaggedError struct {
+	Tag string
+	Err error
+}
+
+func (e *TaggedError) Error() string { return e.Err.Error() }
+
+func (e *TaggedError) Unwrap() error { return e.Err }
+
+// InterruptedError is the error of a tagged evaluation stopped by Interrupt.
+type InterruptedError struct {
+	Tag string
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("evaluation %q interrupted", e.Tag)
+}
+
+// interruptValue is thrown at the next statement once the running
+// evaluation is interrupted. try/catch does not catch it, and since the
+// interrupt stays in effect until the evaluation returns, code that
+// swallows it (a finally block, a promise reaction) is stopped again at its
+// next statement.
+var interruptValue = runtime.NewObject(runtime.NewOrdinaryObject(nil))
+
+type tagCounters struct {
+	tag                                          string
+	evals, errors, interrupts, statements, nanos atomic.Int64
+}
+
+// tagState tracks the tagged evaluations of an interpreter. Interrupt and
+// TagStats may be called from other goroutines, so everything but current
+// is guarded by mu.
+type tagState struct {
+	mu      sync.Mutex
+	active  []string        // tags of the running evaluations, innermost last
+	killed  map[string]bool // active tags passed to Interrupt
+	stats   map[string]*tagCounters
+	pending atomic.Bool // killed is not empty
+
+	current *tagCounters // counters of the innermost tag, nil when untagged
+}
+
+// EvalTagged parses and evaluates source like Eval, under tag. The tag is
+// an opaque label chosen by the host, typically one per tenant: usage is
+// accounted to it in TagStats, errors are returned as *TaggedError, and
+// Interrupt(tag) stops the evaluation.
+func (interp *Interpreter) EvalTagged(tag, source string) (*runtime.Value, error) {
+	return interp.runTagged(tag, func() (*runtime.Value, error) {
+		return interp.Eval(source)
+	})
+}
+
+// RunTagged runs an already parsed program under tag, like EvalTagged.
+func (interp *Interpreter) RunTagged(tag string, program *ast.Program) (*runtime.Value, error) {
+	return interp.runTagged(tag, func() (*runtime.Value, error) {
+		return interp.Run(program)
+	})
+}
+
+func (interp *Interpreter) runTagged(tag string, run func() (*runtime.Value, error)) (*runtime.Value, error) {
+	counters := interp.tags.enter(tag)
+	prev := interp.tags.current
+	interp.tags.current = counters
+	start := time.Now()
+
+	var interrupted bool
+	val, err := func() (*runtime.Value, error) {
+		// Leave even on a panic, or a stale interrupt would stop every
+		// later evaluation.
+		defer func() {
+			interp.tags.current = prev
+			interrupted = interp.tags.leave(tag)
+		}()
+		return run()
+	}()
+
+	counters.nanos.Add(int64(time.Since(start)))
+	counters.evals.Add(1)
+	if thrown, ok := runtime.ThrownValue(err); ok && thrown == interruptValue {
+		// An enclosing evaluation was interrupted.
+		interrupted = true
+	}
+	if interrupted {
+		counters.interrupts.Add(1)
+		counters.errors.Add(1)
+		return nil, &InterruptedError{Tag: tag}
+	}
+	if err != nil {
+		counters.errors.Add(1)
+		return nil, &TaggedError{Tag: tag, Err: err}
+	}
+	return val, nil
+}
+
+// Interrupt stops the running evaluations tagged tag, including any
+// evaluation nested in them, at their next statement. It reports whether
+// one was running. Unlike the rest of the interpreter it is safe to call
+// from any goroutine.
+func (interp *Interpreter) Interrupt(tag string) bool {
+	t := &interp.tags
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, active := range t.active {
+		if active == tag {
+			if t.killed == nil {
+				t.killed = make(map[string]bool)
+			}
+			t.killed[tag] = true
+			t.pending.Store(true)
+			return true
+		}
+	}
+	return false
+}
+
+// TagStats returns the usage accounted to tag so far. It is safe to call
+// from any goroutine, also while an evaluation is running.
+func (interp *Interpreter) TagStats(tag string) TagStats {
+	t := &interp.tags
+	t.mu.Lock()
+	c := t.stats[tag]
+	t.mu.Unlock()
+	if c == nil {
+		return TagStats{}
+	}
+	return TagStats{
+		Evals:      c.evals.Load(),
+		Errors:     c.errors.Load(),
+		Interrupts: c.interrupts.Load(),
+		Statements: c.statements.Load(),
+		Duration:   time.Duration(c.nanos.Load()),
+	}
+}
+
+// Tags returns the tags that have usage recorded, in no particular order.
+func (interp *Interpreter) Tags() []string {
+	t := &interp.tags
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tags := make([]string, 0, len(t.stats))
+	for tag := range t.stats {
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func (t *tagState) enter(tag string) *tagCounters {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active = append(t.active, tag)
+	if t.stats == nil {
+		t.stats = make(map[string]*tagCounters)
+	}
+	c := t.stats[tag]
+	if c == nil {
+		c = &tagCounters{tag: tag}
+		t.stats[tag] = c
+	}
+	return c
+}
+
+// leave ends the innermost evaluation, tagged tag, and reports whether it
+// was interrupted.
+func (t *tagState) leave(tag string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active = t.active[:len(t.active)-1]
+	interrupted := t.killed[tag]
+	for _, active := range t.active {
+		if active == tag {
+			// An enclosing evaluation with the same tag is stopped too.
+			return interrupted
+		}
+	}
+	delete(t.killed, tag)
+	t.pending.Store(len(t.killed) > 0)
+	return interrupted
+}
+
+// checkpoint runs before each statement: it counts the statement against
+// the current tag and throws interruptValue once the evaluation has been
+// interrupted, or limitValue once it is over the limits of EvalWithOptions.
+func (interp *Interpreter) checkpoint() signal {
+	if c := interp.tags.current; c != nil {
+		c.statements.Add(1)
+	}
+	if interp.tags.pending.Load() {
+		return signal{typ: sigThrow, value: interruptValue}
+	}
+	if l := interp.limits; l != nil && l.check() {
+		return signal{typ: sigThrow, value: limitValue}
+	}
+	return signal{}
+}
@@ -0,0 +1,514 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// bytecode is the compiled body of a function, see compileFunction.
+type bytecode struct {
+	code      []instr
+	consts    []*runtime.Value
+	names     []string   // property keys, operators and names for errors
+	nodes     []ast.Node // nodes the instructions locate errors at or look names up by
+	slots     int        // slots of parameters, variables and the function's own name
+	slotNames []string
+	locals    [][]string // names of the locals in scope where opLoadName looks a name up
+	params    int        // the first params slots hold the arguments
+	vars      []int      // slots of var declarations, undefined on entry
+	self      int        // slot of the name of a named function expression, or -1
+
+	// env is set when the function creates closures. It then runs in an
+	// environment of its own that binds this, new.target and decls, the
+	// locals closures capture, and enters one that binds the captured
+	// let and const declarations of a block for each of blocks.
+	env    bool
+	decls  []envDecl
+	blocks [][]envDecl
+	refs   []envRef
+
+	strict bool // the function is strict mode code
+}
+
+// An envDecl is a local kept in an environment instead of a slot.
+type envDecl struct {
+	name, kind string
+	slot       int
+}
+
+// An envRef locates a captured local from where an instruction uses it:
+// the binding numbered index in the environment depth levels out, see
+// runtime.Environment.Slot.
+type envRef struct {
+	name         string
+	depth, index int
+}
+
+type instr struct {
+	op   opcode
+	a, b int32
+}
+
+type opcode uint8
+
+const (
+	opStatement        opcode = iota // a: node; counts a statement, see checkpoint
+	opConst                          // a: constant
+	opPop                            //
+	opDup                            //
+	opDup2                           // duplicates the top two values
+	opLoad                           // a: slot
+	opStore                          // a: slot; leaves the value on the stack
+	opInit                           // a: slot; initializes it with the popped value
+	opClear                          // a: slot; makes it uninitialized
+	opLoadName                       // a: identifier node looked up in the environment, b: locals
+	opStoreName                      // a: identifier node
+	opLoadEnv                        // a: ref
+	opStoreEnv                       // a: ref; leaves the value on the stack
+	opInitEnv                        // a: ref; initializes it with the popped value
+	opPushScope                      // a: block
+	opPopScope                       //
+	opClosure                        // a: function node
+	opNameFunction                   // a: function node, b: name
+	opTypeofName                     // a: identifier node
+	opWithThis                       // a: identifier node of a callee; pushes its this value
+	opThis                           //
+	opGetProp                        // a: name of the key
+	opToKey                          // converts the top value to a property key
+	opGetElem                        // obj key -> value
+	opSetElem                        // obj key value -> value
+	opUpdateElem                     // obj key -> value; a: +1 or -1, b: 1 for prefix
+	opArray                          // a: element count
+	opObject                         // a: property count
+	opDefine                         // obj value -> obj; a: name of the key
+	opAdd                            //
+	opSub                            //
+	opMul                            //
+	opLess                           //
+	opGreater                        //
+	opLessEq                         //
+	opGreaterEq                      //
+	opStrictEq                       //
+	opStrictNe                       //
+	opBinary                         // a: name of the operator
+	opNeg                            //
+	opToNumber                       //
+	opIncrement                      // a: +1 or -1, added to a number
+	opBitNot                         //
+	opNot                            //
+	opVoid                           //
+	opTypeof                         //
+	opJump                           // a: target
+	opJumpIfFalse                    // a: target; pops the condition
+	opJumpIfTrue                     // a: target; pops the condition
+	opAnd                            // a: target, jumped to keeping a falsy value
+	opOr                             // a: target, jumped to keeping a truthy value
+	opNullish                        // a: target, jumped to keeping a value that is not nullish
+	opGetMethod                      // obj key -> method obj; a: call node
+	opCheckCallable                  // callee this -> callee this; a: name, b: call node
+	opCall                           // callee this args... -> result; a: argument count, b: call node
+	opCheckConstructor               // a: name, b: new node
+	opNew                            // callee args... -> object; a: argument count, b: new node
+	opThrow                          // a: throw statement node
+	opReturn                         //
+	opReturnUndefined                //
+)
+
+// runBytecode runs the compiled body of the function fn.
+func (interp *Interpreter) runBytecode(code *bytecode, fn *runtime.Object, file string, env *runtime.Environment, this *runtime.Value, args []*runtime.Value, newTarget *runtime.Value) (*runtime.Value, error) {
+	if err := interp.checkCallDepth(); err != nil {
+		return nil, err
+	}
+	defer interp.enterFrame(fn, file)()
+	val, sig := interp.execBytecode(code, fn, env, this, args, newTarget)
+	if sig.typ == sigThrow {
+		interp.noteThrow(sig.value)
+		return nil, &jsError{value: sig.value}
+	}
+	return val, nil
+}
+
+// execBytecode is the stack machine. Names the function does not keep in
+// slots are looked up in env, the environment its closure captured, or the
+// function's own environment when it has one.
+func (interp *Interpreter) execBytecode(code *bytecode, fn *runtime.Object, env *runtime.Environment, this *runtime.Value, args []*runtime.Value, newTarget *runtime.Value) (*runtime.Value, signal) {
+	frame := make([]*runtime.Value, code.slots, code.slots+8)
+	slots := frame[:code.slots:code.slots]
+	stack := frame[code.slots:]
+	for i := 0; i < code.params; i++ {
+		if i < len(args) {
+			slots[i] = args[i]
+		} else {
+			slots[i] = runtime.Undefined
+		}
+	}
+	for _, slot := range code.vars {
+		slots[slot] = runtime.Undefined
+	}
+	if code.self >= 0 {
+		slots[code.self] = runtime.NewObject(fn)
+	}
+	if code.env {
+		env = runtime.NewEnvironment(env, false)
+		env.Declare("this", "const", this)
+		env.Declare("new.target", "const", newTarget)
+		for _, d := range code.decls {
+			switch d.kind {
+			case "param":
+				env.Declare(d.name, "var", slots[d.slot])
+			case "self":
+				env.Declare(d.name, "const", slots[d.slot])
+			case "var":
+				env.Declare(d.name, "var", runtime.Undefined)
+			default:
+				env.DeclareUninitialized(d.name, d.kind)
+			}
+			env.Index(d.name)
+		}
+	}
+	throw := func(errorType, message string) signal {
+		return signal{typ: sigThrow, value: interp.makeErrorObject(errorType, message, env)}
+	}
+
+	for pc := 0; pc < len(code.code); pc++ {
+		in := code.code[pc]
+		switch in.op {
+		case opStatement:
+			if sig := interp.checkpoint(); sig.typ != sigNone {
+				return nil, sig
+			}
+			interp.at(code.nodes[in.a])
+		case opConst:
+			stack = append(stack, code.consts[in.a])
+		case opPop:
+			stack = stack[:len(stack)-1]
+		case opDup:
+			stack = append(stack, stack[len(stack)-1])
+		case opDup2:
+			stack = append(stack, stack[len(stack)-2], stack[len(stack)-1])
+		case opLoad:
+			val := slots[in.a]
+			if val == nil {
+				return nil, throw("ReferenceError", fmt.Sprintf("Cannot access '%s' before initialization", code.slotNames[in.a]))
+			}
+			stack = append(stack, val)
+		case opStore:
+			if slots[in.a] == nil {
+				return nil, throw("ReferenceError", fmt.Sprintf("Cannot access '%s' before initialization", code.slotNames[in.a]))
+			}
+			slots[in.a] = stack[len(stack)-1]
+		case opInit:
+			slots[in.a] = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case opClear:
+			slots[in.a] = nil
+		case opLoadName:
+			val, sig := interp.lookupIdentifier(code.nodes[in.a].(*ast.Identifier), env, code.locals[in.b])
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = append(stack, val)
+		case opStoreName:
+			if sig := interp.assignToExpression(code.nodes[in.a].(*ast.Identifier), stack[len(stack)-1], env); sig.typ != sigNone {
+				return nil, sig
+			}
+		case opLoadEnv:
+			ref := code.refs[in.a]
+			b := env.Slot(ref.depth, ref.index)
+			if !b.Declared {
+				return nil, throw("ReferenceError", fmt.Sprintf("Cannot access '%s' before initialization", ref.name))
+			}
+			stack = append(stack, b.Value)
+		case opStoreEnv:
+			ref := code.refs[in.a]
+			b := env.Slot(ref.depth, ref.index)
+			if !b.Declared {
+				return nil, throw("ReferenceError", fmt.Sprintf("Cannot access '%s' before initialization", ref.name))
+			}
+			b.Value = stack[len(stack)-1]
+		case opInitEnv:
+			b := env.Slot(code.refs[in.a].depth, code.refs[in.a].index)
+			b.Value, b.Declared = stack[len(stack)-1], true
+			stack = stack[:len(stack)-1]
+		case opPushScope:
+			env = runtime.NewEnvironment(env, true)
+			for _, d := range code.blocks[in.a] {
+				env.DeclareUninitialized(d.name, d.kind)
+				env.Index(d.name)
+			}
+		case opPopScope:
+			env = env.Outer()
+		case opClosure:
+			var closure *runtime.Value
+			switch n := code.nodes[in.a].(type) {
+			case *ast.FunctionExpression:
+				closure = interp.createFunctionFromExpr(n, env)
+			case *ast.ArrowFunctionExpression:
+				closure = interp.createArrowFunction(n, env)
+			case *ast.FunctionDeclaration:
+				closure = interp.createFunctionFromDecl(n, env)
+			}
+			stack = append(stack, closure)
+		case opNameFunction:
+			nameFunction(code.nodes[in.a].(ast.Expression), stack[len(stack)-1], code.names[in.b])
+		case opTypeofName:
+			val, sig := interp.typeofIdentifier(code.nodes[in.a].(*ast.Identifier), env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = append(stack, val)
+		case opWithThis:
+			thisVal := runtime.Undefined
+			if obj := env.WithBase(code.nodes[in.a].(*ast.Identifier).Value); obj != nil {
+				thisVal = runtime.NewObject(obj)
+			}
+			stack = append(stack, thisVal)
+		case opThis:
+			if this == nil || this == runtime.Undefined {
+				stack = append(stack, interp.globalObject)
+			} else {
+				stack = append(stack, this)
+			}
+		case opGetProp:
+			val, sig := interp.getMember(stack[len(stack)-1], code.names[in.a], env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack[len(stack)-1] = val
+		case opToKey:
+			key, sig := interp.toPropertyKey(stack[len(stack)-1], env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack[len(stack)-1] = runtime.NewString(key)
+		case opGetElem:
+			n := len(stack)
+			val, sig := interp.getMember(stack[n-2], stack[n-1].Str, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = stack[:n-1]
+			stack[n-2] = val
+		case opSetElem:
+			n := len(stack)
+			val := stack[n-1]
+			if sig := interp.setMember(stack[n-3], stack[n-2].Str, val, code.strict, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = stack[:n-2]
+			stack[n-3] = val
+		case opUpdateElem:
+			n := len(stack)
+			obj, key := stack[n-2], stack[n-1].Str
+			old, sig := interp.getMember(obj, key, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			oldNum, sig := interp.toNumber(old, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			newVal := runtime.NewNumber(oldNum + float64(in.a))
+			if sig := interp.setMember(obj, key, newVal, code.strict, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = stack[:n-1]
+			if in.b == 1 {
+				stack[n-2] = newVal
+			} else {
+				stack[n-2] = runtime.NewNumber(oldNum)
+			}
+		case opArray:
+			n := int(in.a)
+			if sig := interp.charge(runtime.ObjectSize+int64(n)*runtime.SlotSize, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			elements := make([]*runtime.Value, n)
+			copy(elements, stack[len(stack)-n:])
+			stack = append(stack[:len(stack)-n], runtime.NewObject(runtime.NewArrayObject(interp.realm.ArrayPrototype, elements)))
+		case opObject:
+			if sig := interp.charge(runtime.ObjectSize+int64(in.a)*runtime.PropertySize, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = append(stack, runtime.NewObject(runtime.NewOrdinaryObject(interp.realm.ObjectPrototype)))
+		case opDefine:
+			n := len(stack)
+			stack[n-2].Object.Set(code.names[in.a], stack[n-1])
+			stack = stack[:n-1]
+		case opAdd, opSub, opMul, opLess, opGreater, opLessEq, opGreaterEq, opStrictEq, opStrictNe:
+			n := len(stack)
+			left, right := stack[n-2], stack[n-1]
+			var val *runtime.Value
+			if left.Type == runtime.TypeNumber && right.Type == runtime.TypeNumber {
+				val = numberOp(in.op, left.Number, right.Number)
+			} else {
+				var sig signal
+				if val, sig = interp.binaryOp(opNames[in.op], left, right, env); sig.typ != sigNone {
+					return nil, sig
+				}
+			}
+			stack = stack[:n-1]
+			stack[n-2] = val
+		case opBinary:
+			n := len(stack)
+			val, sig := interp.binaryOp(code.names[in.a], stack[n-2], stack[n-1], env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = stack[:n-1]
+			stack[n-2] = val
+		case opNeg, opToNumber, opBitNot:
+			top := stack[len(stack)-1]
+			num := top.Number
+			if top.Type != runtime.TypeNumber {
+				var sig signal
+				if num, sig = interp.toNumber(top, env); sig.typ != sigNone {
+					return nil, sig
+				}
+			}
+			switch in.op {
+			case opNeg:
+				num = -num
+			case opBitNot:
+				num = float64(^int32(num))
+			}
+			if in.op != opToNumber || top.Type != runtime.TypeNumber {
+				stack[len(stack)-1] = runtime.NewNumber(num)
+			}
+		case opIncrement:
+			stack[len(stack)-1] = runtime.NewNumber(stack[len(stack)-1].Number + float64(in.a))
+		case opNot:
+			stack[len(stack)-1] = runtime.NewBool(!stack[len(stack)-1].ToBoolean())
+		case opVoid:
+			stack[len(stack)-1] = runtime.Undefined
+		case opTypeof:
+			stack[len(stack)-1] = interp.typeofValue(stack[len(stack)-1])
+		case opJump:
+			pc = int(in.a) - 1
+		case opJumpIfFalse, opJumpIfTrue:
+			cond := stack[len(stack)-1].ToBoolean()
+			stack = stack[:len(stack)-1]
+			if cond == (in.op == opJumpIfTrue) {
+				pc = int(in.a) - 1
+			}
+		case opAnd, opOr, opNullish:
+			top := stack[len(stack)-1]
+			var keep bool
+			switch in.op {
+			case opAnd:
+				keep = !top.ToBoolean()
+			case opOr:
+				keep = top.ToBoolean()
+			default:
+				keep = !isNullish(top)
+			}
+			if keep {
+				pc = int(in.a) - 1
+			} else {
+				stack = stack[:len(stack)-1]
+			}
+		case opGetMethod:
+			n := len(stack)
+			obj, key := stack[n-2], stack[n-1].Str
+			method, sig := interp.getMember(obj, key, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			if !isCallable(method) {
+				if privateName := runtime.PrivateNameForKey(key); privateName != nil {
+					key = privateName.Description
+				}
+				interp.at(code.nodes[in.a])
+				return nil, throw("TypeError", fmt.Sprintf("%s is not a function", key))
+			}
+			stack[n-2], stack[n-1] = method, obj
+		case opCheckCallable:
+			if !isCallable(stack[len(stack)-2]) {
+				interp.at(code.nodes[in.b])
+				return nil, throw("TypeError", fmt.Sprintf("%s is not a function", code.names[in.a]))
+			}
+		case opCall:
+			n := len(stack) - int(in.a)
+			callArgs := make([]*runtime.Value, in.a)
+			copy(callArgs, stack[n:])
+			interp.at(code.nodes[in.b])
+			val, sig := interp.callFunction(stack[n-2], stack[n-1], callArgs, env)
+			if sig.typ != sigNone {
+				return nil, sig
+			}
+			stack = stack[:n-1]
+			stack[n-2] = val
+		case opCheckConstructor:
+			callee := stack[len(stack)-1]
+			if callee.Type != runtime.TypeObject || callee.Object == nil || callee.Object.Constructor == nil {
+				interp.at(code.nodes[in.b])
+				return nil, throw("TypeError", fmt.Sprintf("%s is not a constructor", code.names[in.a]))
+			}
+		case opNew:
+			n := len(stack) - int(in.a)
+			callArgs := make([]*runtime.Value, in.a)
+			copy(callArgs, stack[n:])
+			interp.at(code.nodes[in.b])
+			if sig := interp.charge(runtime.ObjectSize, env); sig.typ != sigNone {
+				return nil, sig
+			}
+			callee := stack[n-1].Object
+			val, err := interp.construct(callee, callArgs, callee)
+			if err != nil {
+				if jsErr, ok := err.(*jsError); ok {
+					return nil, signal{typ: sigThrow, value: jsErr.value}
+				}
+				return nil, signal{typ: sigThrow, value: interp.errorFromGoError(err, env)}
+			}
+			stack = stack[:n]
+			stack[n-1] = val
+		case opThrow:
+			val := stack[len(stack)-1]
+			interp.at(code.nodes[in.a])
+			interp.thrown = thrownAt{}
+			interp.noteThrow(val)
+			return nil, signal{typ: sigThrow, value: val}
+		case opReturn:
+			return stack[len(stack)-1], signal{}
+		case opReturnUndefined:
+			return runtime.Undefined, signal{}
+		}
+	}
+	return runtime.Undefined, signal{}
+}
+
+// opNames are the operators of the instructions with a fast path for
+// numbers, applied by binaryOp to other operands.
+var opNames = map[opcode]string{
+	opAdd: "+", opSub: "-", opMul: "*", opLess: "<", opGreater: ">",
+	opLessEq: "<=", opGreaterEq: ">=", opStrictEq: "===", opStrictNe: "!==",
+}
+
+// numberOp applies the operator of op to two numbers.
+func numberOp(op opcode, left, right float64) *runtime.Value {
+	switch op {
+	case opAdd:
+		return runtime.NewNumber(left + right)
+	case opSub:
+		return runtime.NewNumber(left - right)
+	case opMul:
+		return runtime.NewNumber(left * right)
+	case opLess:
+		return runtime.NewBool(left < right)
+	case opGreater:
+		return runtime.NewBool(left > right)
+	case opLessEq:
+		return runtime.NewBool(left <= right)
+	case opGreaterEq:
+		return runtime.NewBool(left >= right)
+	case opStrictEq:
+		return runtime.NewBool(left == right)
+	}
+	return runtime.NewBool(left != right)
+}
+
+func isCallable(v *runtime.Value) bool {
+	return v != nil && v.Type == runtime.TypeObject && v.Object != nil && v.Object.Callable != nil
+}
@@ -5,7 +5,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
-	"github.com/example/jsgo/token"
+	"github.com/example/jsgo/internal/token"
 )
 
 type Lexer struct {
@@ -163,7 +163,26 @@ func canPrecedeRegex(tt token.TokenType) bool {
 
 func (l *Lexer) NextToken() token.Token {
 	l.skipWhitespaceAndComments()
+	start := l.pos
+	return l.withRange(l.scanToken(), start)
+}
+
+// withRange records the source range of a just-scanned token. The lexer
+// has already advanced past the token, so the current position is its end.
+func (l *Lexer) withRange(tok token.Token, start int) token.Token {
+	tok.Offset = start
+	tok.EndOffset = l.pos
+	if tok.EndOffset > len(l.input) {
+		tok.EndOffset = len(l.input)
+	}
+	tok.EndLine = l.line
+	tok.EndColumn = l.col
+	return tok
+}
 
+// scanToken reads the token starting at the current character. Whitespace
+// and comments must already have been skipped.
+func (l *Lexer) scanToken() token.Token {
 	line := l.line
 	col := l.col
 
@@ -419,6 +438,11 @@ func (l *Lexer) NextToken() token.Token {
 	case isIdentStart(l.ch):
 		return l.readIdentifier(line, col)
 
+	case l.ch == '#' && isIdentStart(l.peekChar()):
+		l.readChar()
+		ident := l.readIdentifier(line, col)
+		return tok(token.PrivateName, "#"+ident.Literal)
+
 	case l.ch == '\\' && l.peekChar() == 'u':
 		return l.readIdentifier(line, col)
 
@@ -437,7 +461,8 @@ func (l *Lexer) NextTokenWithRegex(prevType token.TokenType) token.Token {
 	if l.ch == '/' && l.peekChar() != '/' && l.peekChar() != '*' && canPrecedeRegex(prevType) {
 		line := l.line
 		col := l.col
-		return l.readRegExp(line, col)
+		start := l.pos
+		return l.withRange(l.readRegExp(line, col), start)
 	}
 	return l.NextToken()
 }
@@ -473,8 +498,13 @@ func (l *Lexer) readIdentifier(line, col int) token.Token {
 		literal = l.input[start:l.pos]
 	}
 
-	tt := token.LookupIdentifier(literal)
-	return token.Token{Type: tt, Literal: literal, Line: line, Column: col}
+	// A keyword spelled with escapes is never the keyword itself: it can
+	// only be an identifier, subject to the parser's reserved-word checks.
+	tt := token.Identifier
+	if !hasEscape {
+		tt = token.LookupIdentifier(literal)
+	}
+	return token.Token{Type: tt, Literal: literal, Line: line, Column: col, Escaped: hasEscape}
 }
 
 // writeUTF16CodeUnit writes a UTF-16 code unit (including surrogates) to a string builder.
@@ -718,7 +748,7 @@ func (l *Lexer) readTemplateLiteral(line, col int) token.Token {
 
 	for {
 		if l.ch == 0 {
-			return token.Token{Type: token.Illegal, Literal: "unterminated template literal", Line: line, Column: col}
+			return token.Token{Type: token.Illegal, Literal: "unterminated template literal", Line: line, Column: col, Unterminated: true}
 		}
 		if l.ch == '`' {
 			l.readChar()
@@ -752,7 +782,7 @@ func (l *Lexer) readTemplateContinuation(line, col int) token.Token {
 
 	for {
 		if l.ch == 0 {
-			return token.Token{Type: token.Illegal, Literal: "unterminated template literal", Line: line, Column: col}
+			return token.Token{Type: token.Illegal, Literal: "unterminated template literal", Line: line, Column: col, Unterminated: true}
 		}
 		if l.ch == '`' {
 			l.readChar()
@@ -3,7 +3,7 @@ package lexer
 import (
 	"testing"
 
-	"github.com/example/jsgo/token"
+	"github.com/example/jsgo/internal/token"
 )
 
 func TestSingleCharTokens(t *testing.T) {
@@ -362,6 +362,33 @@ func TestIdentifiers(t *testing.T) {
 	}
 }
 
+func TestEscapedIdentifiers(t *testing.T) {
+	tests := []struct {
+		input   string
+		lit     string
+		escaped bool
+	}{
+		{`\u0061b`, "ab", true},
+		{`a\u{62}c`, "abc", true},
+		{`\u0069f`, "if", true},
+		{`cl\u0061ss`, "class", true},
+		{`abc`, "abc", false},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != token.Identifier {
+			t.Errorf("input=%q: type wrong. expected=Identifier, got=%d", tt.input, tok.Type)
+		}
+		if tok.Literal != tt.lit {
+			t.Errorf("input=%q: literal wrong. expected=%q, got=%q", tt.input, tt.lit, tok.Literal)
+		}
+		if tok.Escaped != tt.escaped {
+			t.Errorf("input=%q: Escaped wrong. expected=%v, got=%v", tt.input, tt.escaped, tok.Escaped)
+		}
+	}
+}
+
 func TestNumberLiterals(t *testing.T) {
 	tests := []struct {
 		input string
@@ -754,6 +781,34 @@ func TestClassDeclaration(t *testing.T) {
 	}
 }
 
+func TestPrivateName(t *testing.T) {
+	input := `this.#count = #count in o`
+	expected := []struct {
+		typ token.TokenType
+		lit string
+	}{
+		{token.This, "this"},
+		{token.Dot, "."},
+		{token.PrivateName, "#count"},
+		{token.Assign, "="},
+		{token.PrivateName, "#count"},
+		{token.In, "in"},
+		{token.Identifier, "o"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, exp := range expected {
+		tok := l.NextToken()
+		if tok.Type != exp.typ {
+			t.Errorf("test[%d]: type wrong. expected=%d, got=%d (lit=%q)", i, exp.typ, tok.Type, tok.Literal)
+		}
+		if tok.Literal != exp.lit {
+			t.Errorf("test[%d]: literal wrong. expected=%q, got=%q", i, exp.lit, tok.Literal)
+		}
+	}
+}
+
 func TestAsyncAwait(t *testing.T) {
 	input := `async function fetchData() { const data = await fetch(); }`
 	expected := []struct {
@@ -1022,8 +1077,8 @@ func TestUnicodeIdentifier(t *testing.T) {
 		input string
 		lit   string
 	}{
-		{`\u0061`, "a"},           // \u0061 = 'a'
-		{`\u{62}`, "b"},          // \u{62} = 'b'
+		{`\u0061`, "a"}, // \u0061 = 'a'
+		{`\u{62}`, "b"}, // \u{62} = 'b'
 	}
 
 	for _, tt := range tests {
@@ -1044,7 +1099,7 @@ line 2
 line 3
 */ b`
 	l := New(input)
-	l.NextToken() // a
+	l.NextToken()        // a
 	tok := l.NextToken() // b
 	if tok.Type != token.Identifier || tok.Literal != "b" {
 		t.Errorf("expected 'b', got %d %q", tok.Type, tok.Literal)
@@ -1235,3 +1290,35 @@ func TestVoidInstanceof(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenRanges(t *testing.T) {
+	input := "let x = /a+/g;\n  `t${y}`"
+	expected := []struct {
+		lit             string
+		offset, end     int
+		endLine, endCol int
+	}{
+		{"let", 0, 3, 1, 4},
+		{"x", 4, 5, 1, 6},
+		{"=", 6, 7, 1, 8},
+		{"/a+/g", 8, 13, 1, 14},
+		{";", 13, 14, 1, 15},
+		{"t", 17, 21, 2, 7},
+		{"y", 21, 22, 2, 8},
+		{"", 22, 24, 2, 10},
+	}
+
+	tokens := Tokenize(input)
+	for i, exp := range expected {
+		tok := tokens[i]
+		if tok.Literal != exp.lit {
+			t.Fatalf("test[%d]: literal wrong. expected=%q, got=%q", i, exp.lit, tok.Literal)
+		}
+		if tok.Offset != exp.offset || tok.EndOffset != exp.end {
+			t.Errorf("test[%d] %q: range wrong. expected=%d-%d, got=%d-%d", i, exp.lit, exp.offset, exp.end, tok.Offset, tok.EndOffset)
+		}
+		if tok.EndLine != exp.endLine || tok.EndColumn != exp.endCol {
+			t.Errorf("test[%d] %q: end wrong. expected=%d:%d, got=%d:%d", i, exp.lit, exp.endLine, exp.endCol, tok.EndLine, tok.EndColumn)
+		}
+	}
+}
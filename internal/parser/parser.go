@@ -1,13 +1,14 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
-	"github.com/example/jsgo/ast"
-	"github.com/example/jsgo/lexer"
-	"github.com/example/jsgo/token"
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/lexer"
+	"github.com/example/jsgo/internal/token"
 )
 
 // Precedence levels for Pratt parsing
@@ -39,15 +40,25 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 	prevType  token.TokenType
-	prevLine  int
 	errors    []error
-	noIn      bool // suppress 'in' as binary operator (for-in disambiguation)
+	noIn      bool         // suppress 'in' as binary operator (for-in disambiguation)
+	prevEnd   ast.Position // end of the most recently consumed token
+
+	// parenthesized records expressions that were written inside a pair of
+	// grouping parentheses, so assignment targets can be validated later.
+	parenthesized map[ast.Expression]bool
+
+	module    bool // parsing a module: import and export are allowed at top level
+	strict    bool // parsing strict mode code
+	generator bool // parsing a generator, where yield is an operator
+	async     bool // parsing an async function, where await is an operator
 }
 
 func New(source string) *Parser {
 	p := &Parser{
-		l:        lexer.New(source),
-		prevType: token.EOF,
+		l:             lexer.New(source),
+		prevType:      token.EOF,
+		parenthesized: make(map[ast.Expression]bool),
 	}
 	p.nextToken()
 	p.nextToken()
@@ -56,18 +67,54 @@ func New(source string) *Parser {
 
 func (p *Parser) ParseProgram() (*ast.Program, []error) {
 	program := &ast.Program{}
+	prologue := true
 	for p.curToken.Type != token.EOF {
+		tok := p.curToken
 		stmt := p.parseStatement()
+		if prologue {
+			prologue = p.directive(tok, stmt)
+		}
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
 	}
+	program.Strict = p.strict
+	program.SetSpan(ast.Position{Offset: 0, Line: 1, Column: 1}, p.startPos())
+	ast.AnalyzeScopes(program)
+	return program, p.errors
+}
+
+// ParseModule parses the source as an ES module, where import and export
+// declarations may appear at the top level.
+func (p *Parser) ParseModule() (*ast.Program, []error) {
+	p.module = true
+	p.strict = true
+	program := &ast.Program{}
+	for p.curToken.Type != token.EOF {
+		var stmt ast.Statement
+		start := p.startPos()
+		switch p.curToken.Type {
+		case token.Import:
+			stmt = p.parseImportDeclaration()
+		case token.Export:
+			stmt = p.parseExportDeclaration()
+		default:
+			stmt = p.parseStatement()
+		}
+		if stmt != nil {
+			p.finish(stmt, start)
+			program.Statements = append(program.Statements, stmt)
+		}
+	}
+	program.Strict = true
+	program.SetSpan(ast.Position{Offset: 0, Line: 1, Column: 1}, p.startPos())
+	ast.AnalyzeScopes(program)
 	return program, p.errors
 }
 
 func (p *Parser) nextToken() {
 	p.prevType = p.curToken.Type
-	p.prevLine = p.curToken.Line
+	p.prevEnd = ast.Position{Offset: p.curToken.EndOffset, Line: p.curToken.EndLine, Column: p.curToken.EndColumn}
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextTokenWithRegex(p.curToken.Type)
 }
@@ -76,6 +123,13 @@ func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Type == t
 }
 
+// curTokenIsContextual reports whether the current token is the contextual
+// keyword name, such as get or static. Like real keywords, contextual
+// keywords lose their meaning when written with escapes.
+func (p *Parser) curTokenIsContextual(name string) bool {
+	return p.curTokenIs(token.Identifier) && !p.curToken.Escaped && p.curToken.Literal == name
+}
+
 func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
@@ -89,16 +143,114 @@ func (p *Parser) expect(t token.TokenType) bool {
 	return false
 }
 
+// ErrUnexpectedEnd is the cause of the SyntaxErrors reported because the
+// input ends too early, inside a block or a template literal, say, where
+// more input could complete it. errors.Is finds it, so that a REPL can read
+// another line.
+var ErrUnexpectedEnd = errors.New("unexpected end of input")
+
+// SyntaxError is a single parse failure at a source position. AtEnd is set
+// when the failure is at the end of the input, see ErrUnexpectedEnd.
+type SyntaxError struct {
+	Message string
+	Line    int
+	Column  int
+	AtEnd   bool
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("parse error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Unwrap returns ErrUnexpectedEnd for an error at the end of the input.
+func (e *SyntaxError) Unwrap() error {
+	if e.AtEnd {
+		return ErrUnexpectedEnd
+	}
+	return nil
+}
+
 func (p *Parser) addError(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	err := fmt.Errorf("parse error at %d:%d: %s", p.curToken.Line, p.curToken.Column, msg)
+	p.addErrorAt(p.curToken, format, args...)
+}
+
+// addErrorAt reports an error at tok, a token parsed earlier.
+func (p *Parser) addErrorAt(tok token.Token, format string, args ...interface{}) {
+	err := &SyntaxError{
+		Message: fmt.Sprintf(format, args...),
+		Line:    tok.Line,
+		Column:  tok.Column,
+		AtEnd:   tok.Type == token.EOF || tok.Unterminated,
+	}
 	p.errors = append(p.errors, err)
 }
 
-// parseStatement dispatches to the appropriate statement parser.
+// startPos returns the position of the current token, which is where the
+// node about to be parsed begins.
+func (p *Parser) startPos() ast.Position {
+	return ast.Position{Offset: p.curToken.Offset, Line: p.curToken.Line, Column: p.curToken.Column}
+}
+
+// finish records the span of n as running from start to the end of the last
+// consumed token. Nodes that already carry a span keep it, so the innermost
+// parse function wins; this keeps e.g. the parentheses around a grouped
+// expression out of the inner expression's span.
+func (p *Parser) finish(n ast.Node, start ast.Position) {
+	if n == nil {
+		return
+	}
+	s, ok := n.(interface{ SetSpan(start, end ast.Position) })
+	if !ok || n.Span().Start.IsValid() {
+		return
+	}
+	end := p.prevEnd
+	if end.Offset < start.Offset {
+		end = start
+	}
+	s.SetSpan(start, end)
+}
+
+// tokenPos returns the start position of tok.
+func tokenPos(tok token.Token) ast.Position {
+	return ast.Position{Offset: tok.Offset, Line: tok.Line, Column: tok.Column}
+}
+
+// finishToken records a span covering exactly tok, for nodes built from a
+// single token.
+func finishToken(n ast.Node, tok token.Token) {
+	if s, ok := n.(interface{ SetSpan(start, end ast.Position) }); ok {
+		s.SetSpan(tokenPos(tok), ast.Position{Offset: tok.EndOffset, Line: tok.EndLine, Column: tok.EndColumn})
+	}
+}
+
+// inheritSpan gives a node synthesized by the parser the span of the source
+// construct it stands for.
+func inheritSpan(n ast.Node, from ast.Node) {
+	if s, ok := n.(interface{ SetSpan(start, end ast.Position) }); ok && from != nil {
+		span := from.Span()
+		s.SetSpan(span.Start, span.End)
+	}
+}
+
+// parseStatement parses a statement and records its source span.
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.startPos()
+	stmt := p.parseStatementInner()
+	if stmt != nil {
+		p.finish(stmt, start)
+	}
+	return stmt
+}
+
+// parseStatementInner dispatches to the appropriate statement parser.
+func (p *Parser) parseStatementInner() ast.Statement {
 	switch p.curToken.Type {
-	case token.Var, token.Let, token.Const:
+	case token.Let:
+		if p.letIsIdentifier() {
+			return p.parseExpressionStatement()
+		}
+		return p.parseVariableDeclaration()
+	case token.Var, token.Const:
 		return p.parseVariableDeclaration()
 	case token.LeftBrace:
 		return p.parseBlockStatement()
@@ -132,8 +284,18 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseEmptyStatement()
 	case token.With:
 		return p.parseWithStatement()
+	case token.Import, token.Export:
+		if p.module {
+			p.addError("%s declarations may only appear at the top level of a module", p.curToken.Literal)
+		} else {
+			p.addError("cannot use %s outside a module", p.curToken.Literal)
+		}
+		if p.curTokenIs(token.Import) {
+			return p.parseImportDeclaration()
+		}
+		return p.parseExportDeclaration()
 	case token.Async:
-		if p.peekTokenIs(token.Function) {
+		if p.peekTokenIs(token.Function) && !p.peekTokenOnNewline() {
 			return p.parseAsyncFunctionDeclaration()
 		}
 		return p.parseExpressionOrLabeledStatement()
@@ -151,12 +313,31 @@ func (p *Parser) parseExpressionOrLabeledStatement() ast.Statement {
 
 // ---------- Statement Parsers ----------
 
+// letIsIdentifier reports whether the let starting a statement is an
+// identifier, as in let = 1, rather than a declaration: it ends the
+// statement or an operator follows it. let [ always starts a declaration.
+func (p *Parser) letIsIdentifier() bool {
+	switch p.peekToken.Type {
+	case token.LeftBracket:
+		return false
+	case token.Semicolon, token.RightBrace, token.EOF:
+		return true
+	}
+	cur := p.curToken
+	p.curToken = p.peekToken
+	defer func() { p.curToken = cur }()
+	return p.infixPrecedence() > 0
+}
+
 func (p *Parser) parseVariableDeclaration() *ast.VariableDeclaration {
 	stmt := &ast.VariableDeclaration{Token: p.curToken, Kind: p.curToken.Literal}
 	p.nextToken() // consume var/let/const
 
 	for {
 		decl := p.parseVariableDeclarator()
+		if stmt.Kind != "var" {
+			p.checkLexicalBinding(decl.Name)
+		}
 		stmt.Declarations = append(stmt.Declarations, decl)
 		if !p.curTokenIs(token.Comma) {
 			break
@@ -170,24 +351,30 @@ func (p *Parser) parseVariableDeclaration() *ast.VariableDeclaration {
 
 func (p *Parser) parseVariableDeclarator() *ast.VariableDeclarator {
 	decl := &ast.VariableDeclarator{Token: p.curToken}
+	start := p.startPos()
 	decl.Name = p.parseBindingPattern()
 
 	if p.curTokenIs(token.Assign) {
 		p.nextToken() // consume =
 		decl.Value = p.parseAssignmentExpression()
 	}
+	p.finish(decl, start)
 	return decl
 }
 
 func (p *Parser) parseBindingPattern() ast.Expression {
+	start := p.startPos()
+	var pat ast.Expression
 	switch p.curToken.Type {
 	case token.LeftBrace:
-		return p.parseObjectPattern()
+		pat = p.parseObjectPattern()
 	case token.LeftBracket:
-		return p.parseArrayPattern()
+		pat = p.parseArrayPattern()
 	default:
-		return p.parseIdentifier()
+		pat = p.parseBindingIdentifier()
 	}
+	p.finish(pat, start)
+	return pat
 }
 
 func (p *Parser) parseObjectPattern() *ast.ObjectPattern {
@@ -196,6 +383,7 @@ func (p *Parser) parseObjectPattern() *ast.ObjectPattern {
 
 	for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
 		if p.curTokenIs(token.Spread) {
+			start := p.startPos()
 			rest := &ast.RestElement{Token: p.curToken}
 			p.nextToken() // consume ...
 			rest.Argument = p.parseBindingPattern()
@@ -204,6 +392,8 @@ func (p *Parser) parseObjectPattern() *ast.ObjectPattern {
 				Key:   rest,
 				Value: rest,
 			}
+			p.finish(rest, start)
+			p.finish(prop, start)
 			pat.Properties = append(pat.Properties, prop)
 		} else {
 			prop := p.parseBindingProperty()
@@ -220,6 +410,8 @@ func (p *Parser) parseObjectPattern() *ast.ObjectPattern {
 
 func (p *Parser) parseBindingProperty() *ast.Property {
 	prop := &ast.Property{Token: p.curToken, Kind: "init"}
+	start := p.startPos()
+	defer p.finish(prop, start)
 
 	if p.curTokenIs(token.LeftBracket) {
 		prop.Computed = true
@@ -238,6 +430,7 @@ func (p *Parser) parseBindingProperty() *ast.Property {
 		p.nextToken() // consume :
 		prop.Value = p.parseBindingElement()
 	} else {
+		p.checkShorthand(prop.Key)
 		prop.Shorthand = true
 		prop.Value = prop.Key
 		if p.curTokenIs(token.Assign) {
@@ -247,17 +440,21 @@ func (p *Parser) parseBindingProperty() *ast.Property {
 				Left:  prop.Key,
 				Right: p.parseAssignmentExpression(),
 			}
+			p.finish(prop.Value, start)
 		}
 	}
 	return prop
 }
 
 func (p *Parser) parseBindingElement() ast.Expression {
+	start := p.startPos()
 	elem := p.parseBindingPattern()
 	if p.curTokenIs(token.Assign) {
 		tok := p.curToken
 		p.nextToken()
-		return &ast.AssignmentPattern{Token: tok, Left: elem, Right: p.parseAssignmentExpression()}
+		pat := &ast.AssignmentPattern{Token: tok, Left: elem, Right: p.parseAssignmentExpression()}
+		p.finish(pat, start)
+		return pat
 	}
 	return elem
 }
@@ -273,9 +470,11 @@ func (p *Parser) parseArrayPattern() *ast.ArrayPattern {
 			continue
 		}
 		if p.curTokenIs(token.Spread) {
+			start := p.startPos()
 			rest := &ast.RestElement{Token: p.curToken}
 			p.nextToken()
 			rest.Argument = p.parseBindingPattern()
+			p.finish(rest, start)
 			pat.Elements = append(pat.Elements, rest)
 			break
 		}
@@ -290,8 +489,92 @@ func (p *Parser) parseArrayPattern() *ast.ArrayPattern {
 	return pat
 }
 
+// toAssignmentTarget reinterprets an already-parsed expression as the target
+// of an assignment or for-in/of head. Array and object literals become
+// destructuring patterns; anything that cannot be assigned to is reported.
+func (p *Parser) toAssignmentTarget(expr ast.Expression, context string) ast.Expression {
+	switch e := expr.(type) {
+	case *ast.Identifier, *ast.MemberExpression, *ast.ArrayPattern, *ast.ObjectPattern:
+		return expr
+	case *ast.ArrayLiteral:
+		if p.parenthesized[expr] {
+			break
+		}
+		pat := &ast.ArrayPattern{Token: e.Token, SourceSpan: e.SourceSpan}
+		for i, elem := range e.Elements {
+			if spread, ok := elem.(*ast.SpreadElement); ok {
+				if i != len(e.Elements)-1 {
+					p.addError("rest element must be last element")
+				}
+				rest := &ast.RestElement{Token: spread.Token, SourceSpan: spread.SourceSpan}
+				rest.Argument = p.toAssignmentTarget(spread.Argument, context)
+				pat.Elements = append(pat.Elements, rest)
+				continue
+			}
+			if elem != nil {
+				elem = p.toAssignmentElement(elem, context)
+			}
+			pat.Elements = append(pat.Elements, elem)
+		}
+		return pat
+	case *ast.ObjectLiteral:
+		if p.parenthesized[expr] {
+			break
+		}
+		pat := &ast.ObjectPattern{Token: e.Token, SourceSpan: e.SourceSpan}
+		for _, prop := range e.Properties {
+			if spread, ok := prop.Value.(*ast.SpreadElement); ok {
+				rest := &ast.RestElement{Token: spread.Token, SourceSpan: spread.SourceSpan}
+				rest.Argument = p.toAssignmentTarget(spread.Argument, context)
+				pat.Properties = append(pat.Properties, &ast.Property{
+					Token: prop.Token, SourceSpan: prop.SourceSpan, Key: rest, Value: rest,
+				})
+				continue
+			}
+			if prop.Kind != "init" || prop.Method {
+				p.addError("invalid destructuring target in %s", context)
+				continue
+			}
+			converted := *prop
+			if !prop.Shorthand {
+				converted.Value = p.toAssignmentElement(prop.Value, context)
+			}
+			pat.Properties = append(pat.Properties, &converted)
+		}
+		return pat
+	}
+	p.addError("invalid left-hand side in %s", context)
+	return expr
+}
+
+// checkSimpleTarget reports an error unless expr is an identifier or member
+// expression, optionally wrapped in parentheses. Compound assignment and
+// update operators accept only these targets.
+func (p *Parser) checkSimpleTarget(expr ast.Expression, context string) {
+	switch expr.(type) {
+	case *ast.Identifier, *ast.MemberExpression:
+		return
+	}
+	p.addError("invalid left-hand side in %s", context)
+}
+
+// toAssignmentElement converts one element of a destructuring target, turning
+// `target = default` into an AssignmentPattern.
+func (p *Parser) toAssignmentElement(expr ast.Expression, context string) ast.Expression {
+	if assign, ok := expr.(*ast.AssignmentExpression); ok && assign.Operator == "=" {
+		return &ast.AssignmentPattern{
+			Token:      assign.Token,
+			SourceSpan: assign.SourceSpan,
+			Left:       p.toAssignmentTarget(assign.Left, context),
+			Right:      assign.Right,
+		}
+	}
+	return p.toAssignmentTarget(expr, context)
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
+	start := p.startPos()
 	p.nextToken() // consume {
 
 	for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
@@ -301,14 +584,84 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 	}
 	p.expect(token.RightBrace)
+	p.finish(block, start)
 	return block
 }
 
+// parseFunctionBody parses the body of a function and reports whether it is
+// strict mode code: the function is nested in strict code or its body
+// begins with a "use strict" directive.
+func (p *Parser) parseFunctionBody() (*ast.BlockStatement, bool) {
+	outer := p.strict
+	defer func() { p.strict = outer }()
+	block := &ast.BlockStatement{Token: p.curToken}
+	start := p.startPos()
+	p.expect(token.LeftBrace)
+
+	prologue := true
+	for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
+		tok := p.curToken
+		stmt := p.parseStatement()
+		if prologue {
+			prologue = p.directive(tok, stmt)
+		}
+		if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+	}
+	p.expect(token.RightBrace)
+	p.finish(block, start)
+	return block, p.strict
+}
+
+// enterFunction starts the parameters and body of a function, where yield
+// is an operator only if the function is a generator and await only if it
+// is async, and returns the function that restores the state of the
+// enclosing code.
+func (p *Parser) enterFunction(generator, async bool) func() {
+	outerGenerator, outerAsync := p.generator, p.async
+	p.generator, p.async = generator, async
+	return func() { p.generator, p.async = outerGenerator, outerAsync }
+}
+
+// parseArrowBody parses the block body of an arrow function, which is
+// never a generator, even within one, and async only if marked so.
+func (p *Parser) parseArrowBody(async bool) (*ast.BlockStatement, bool) {
+	defer p.enterFunction(false, async)()
+	return p.parseFunctionBody()
+}
+
+// parseConciseBody parses the expression body of an arrow function.
+func (p *Parser) parseConciseBody(async bool) ast.Expression {
+	defer p.enterFunction(false, async)()
+	return p.parseAssignmentExpression()
+}
+
+// directive reports whether stmt, which begins with tok, is a directive
+// of a directive prologue: a statement that is only a string literal. A
+// "use strict" directive written without escapes or line continuations
+// makes the code that follows strict.
+func (p *Parser) directive(tok token.Token, stmt ast.Statement) bool {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if !ok || tok.Type != token.String {
+		return false
+	}
+	if _, ok := es.Expression.(*ast.StringLiteral); !ok {
+		return false
+	}
+	if tok.Literal == "use strict" && tok.EndOffset-tok.Offset == len(`"use strict"`) {
+		p.strict = true
+	}
+	return true
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 	p.nextToken() // consume return
 
-	if !p.curTokenIs(token.Semicolon) && !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
+	// return is a restricted production: a value on the next line is a
+	// statement of its own.
+	if !p.curTokenIs(token.Semicolon) && !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) && !p.prevTokenWasNewline() {
 		stmt.Value = p.parseExpression(precComma)
 	}
 	p.consumeSemicolon()
@@ -317,6 +670,7 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 func (p *Parser) parseIfStatement() *ast.IfStatement {
 	stmt := &ast.IfStatement{Token: p.curToken}
+	start := p.startPos()
 	p.nextToken() // consume if
 	p.expect(token.LeftParen)
 	stmt.Condition = p.parseExpression(precComma)
@@ -330,6 +684,7 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 			Token:      p.curToken,
 			Statements: []ast.Statement{inner},
 		}
+		inheritSpan(stmt.Consequence, inner)
 	}
 
 	if p.curTokenIs(token.Else) {
@@ -340,12 +695,15 @@ func (p *Parser) parseIfStatement() *ast.IfStatement {
 			stmt.Alternative = p.parseBlockStatement()
 		} else {
 			inner := p.parseStatement()
-			stmt.Alternative = &ast.BlockStatement{
+			block := &ast.BlockStatement{
 				Token:      p.curToken,
 				Statements: []ast.Statement{inner},
 			}
+			inheritSpan(block, inner)
+			stmt.Alternative = block
 		}
 	}
+	p.finish(stmt, start)
 	return stmt
 }
 
@@ -367,11 +725,27 @@ func (p *Parser) parseDoWhileStatement() *ast.DoWhileStatement {
 	p.expect(token.LeftParen)
 	stmt.Condition = p.parseExpression(precComma)
 	p.expect(token.RightParen)
-	p.consumeSemicolon()
+	// A semicolon is inserted after do-while even on the same line.
+	if p.curTokenIs(token.Semicolon) {
+		p.nextToken()
+	}
 	return stmt
 }
 
 func (p *Parser) parseForStatement() ast.Statement {
+	if p.peekTokenIs(token.Await) {
+		tok := p.curToken
+		p.nextToken() // consume for
+		stmt := p.parseForStatement()
+		forOf, ok := stmt.(*ast.ForOfStatement)
+		if !ok {
+			p.addError("for await is only valid with for-of loops")
+			return stmt
+		}
+		forOf.Token = tok
+		forOf.Await = true
+		return forOf
+	}
 	tok := p.curToken
 	p.nextToken() // consume for
 	p.expect(token.LeftParen)
@@ -394,22 +768,26 @@ func (p *Parser) parseForStatement() ast.Statement {
 	p.noIn = false
 
 	if p.curTokenIs(token.In) {
+		left := p.toAssignmentTarget(expr, "for-in loop")
 		p.nextToken()
 		right := p.parseExpression(precComma)
 		p.expect(token.RightParen)
 		body := p.parseStatement()
-		return &ast.ForInStatement{Token: tok, Left: expr, Right: right, Body: body}
+		return &ast.ForInStatement{Token: tok, Left: left, Right: right, Body: body}
 	}
 	if p.curTokenIs(token.Of) {
+		left := p.toAssignmentTarget(expr, "for-of loop")
 		p.nextToken()
 		right := p.parseAssignmentExpression()
 		p.expect(token.RightParen)
 		body := p.parseStatement()
-		return &ast.ForOfStatement{Token: tok, Left: expr, Right: right, Body: body}
+		return &ast.ForOfStatement{Token: tok, Left: left, Right: right, Body: body}
 	}
 
 	p.expect(token.Semicolon)
-	return p.parseForStandard(tok, &ast.ExpressionStatement{Token: tok, Expression: expr})
+	init := &ast.ExpressionStatement{Token: tok, Expression: expr}
+	inheritSpan(init, expr)
+	return p.parseForStandard(tok, init)
 }
 
 func (p *Parser) parseForWithDeclaration(tok token.Token) ast.Statement {
@@ -419,9 +797,18 @@ func (p *Parser) parseForWithDeclaration(tok token.Token) ast.Statement {
 
 	decl := &ast.VariableDeclaration{Token: declToken, Kind: kind}
 	d := &ast.VariableDeclarator{Token: p.curToken}
+	declStart := tokenPos(declToken)
+	dStart := p.startPos()
 	d.Name = p.parseBindingPattern()
+	if kind != "var" {
+		p.checkLexicalBinding(d.Name)
+	}
 
 	// for-in / for-of
+	if p.curTokenIs(token.In) || p.curTokenIs(token.Of) {
+		p.finish(d, dStart)
+		p.finish(decl, declStart)
+	}
 	if p.curTokenIs(token.In) {
 		decl.Declarations = append(decl.Declarations, d)
 		p.nextToken()
@@ -444,13 +831,18 @@ func (p *Parser) parseForWithDeclaration(tok token.Token) ast.Statement {
 		p.nextToken()
 		d.Value = p.parseAssignmentExpression()
 	}
+	p.finish(d, dStart)
 	decl.Declarations = append(decl.Declarations, d)
 
 	for p.curTokenIs(token.Comma) {
 		p.nextToken()
 		d2 := p.parseVariableDeclarator()
+		if kind != "var" {
+			p.checkLexicalBinding(d2.Name)
+		}
 		decl.Declarations = append(decl.Declarations, d2)
 	}
+	p.finish(decl, declStart)
 
 	p.expect(token.Semicolon)
 	return p.parseForStandard(tok, decl)
@@ -476,8 +868,7 @@ func (p *Parser) parseBreakStatement() *ast.BreakStatement {
 	stmt := &ast.BreakStatement{Token: p.curToken}
 	p.nextToken() // consume break
 	if p.curTokenIs(token.Identifier) && !p.prevTokenWasNewline() {
-		stmt.Label = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		p.nextToken()
+		stmt.Label = p.parseIdentifier()
 	}
 	p.consumeSemicolon()
 	return stmt
@@ -487,8 +878,7 @@ func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
 	stmt := &ast.ContinueStatement{Token: p.curToken}
 	p.nextToken() // consume continue
 	if p.curTokenIs(token.Identifier) && !p.prevTokenWasNewline() {
-		stmt.Label = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		p.nextToken()
+		stmt.Label = p.parseIdentifier()
 	}
 	p.consumeSemicolon()
 	return stmt
@@ -504,6 +894,7 @@ func (p *Parser) parseSwitchStatement() *ast.SwitchStatement {
 
 	for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
 		sc := &ast.SwitchCase{Token: p.curToken}
+		start := p.startPos()
 		if p.curTokenIs(token.Case) {
 			p.nextToken()
 			sc.Test = p.parseExpression(precComma)
@@ -521,6 +912,7 @@ func (p *Parser) parseSwitchStatement() *ast.SwitchStatement {
 				sc.Consequent = append(sc.Consequent, s)
 			}
 		}
+		p.finish(sc, start)
 		stmt.Cases = append(stmt.Cases, sc)
 	}
 	p.expect(token.RightBrace)
@@ -530,6 +922,9 @@ func (p *Parser) parseSwitchStatement() *ast.SwitchStatement {
 func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
 	stmt := &ast.ThrowStatement{Token: p.curToken}
 	p.nextToken() // consume throw
+	if p.prevTokenWasNewline() {
+		p.addError("illegal newline after throw")
+	}
 	stmt.Argument = p.parseExpression(precComma)
 	p.consumeSemicolon()
 	return stmt
@@ -542,6 +937,7 @@ func (p *Parser) parseTryStatement() *ast.TryStatement {
 
 	if p.curTokenIs(token.Catch) {
 		stmt.Handler = &ast.CatchClause{Token: p.curToken}
+		start := p.startPos()
 		p.nextToken() // consume catch
 		if p.curTokenIs(token.LeftParen) {
 			p.nextToken()
@@ -549,6 +945,7 @@ func (p *Parser) parseTryStatement() *ast.TryStatement {
 			p.expect(token.RightParen)
 		}
 		stmt.Handler.Body = p.parseBlockStatement()
+		p.finish(stmt.Handler, start)
 	}
 	if p.curTokenIs(token.Finally) {
 		p.nextToken()
@@ -566,11 +963,11 @@ func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
 		p.nextToken()
 	}
 
-	decl.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	p.nextToken()
+	decl.Name = p.parseBindingIdentifier()
 
+	defer p.enterFunction(decl.Generator, decl.Async)()
 	p.parseFunctionParams(decl)
-	decl.Body = p.parseBlockStatement()
+	decl.Body, decl.Strict = p.parseFunctionBody()
 	return decl
 }
 
@@ -584,11 +981,11 @@ func (p *Parser) parseAsyncFunctionDeclaration() *ast.FunctionDeclaration {
 		p.nextToken()
 	}
 
-	decl.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	p.nextToken()
+	decl.Name = p.parseBindingIdentifier()
 
+	defer p.enterFunction(decl.Generator, decl.Async)()
 	p.parseFunctionParams(decl)
-	decl.Body = p.parseBlockStatement()
+	decl.Body, decl.Strict = p.parseFunctionBody()
 	return decl
 }
 
@@ -601,14 +998,14 @@ type funcParamTarget interface {
 type funcDeclTarget struct{ d *ast.FunctionDeclaration }
 
 func (t funcDeclTarget) setParams(p []ast.Expression)   { t.d.Params = p }
-func (t funcDeclTarget) setDefaults(d []ast.Expression)  { t.d.Defaults = d }
-func (t funcDeclTarget) setRest(r ast.Expression)        { t.d.Rest = r }
+func (t funcDeclTarget) setDefaults(d []ast.Expression) { t.d.Defaults = d }
+func (t funcDeclTarget) setRest(r ast.Expression)       { t.d.Rest = r }
 
 type funcExprTarget struct{ e *ast.FunctionExpression }
 
 func (t funcExprTarget) setParams(p []ast.Expression)   { t.e.Params = p }
-func (t funcExprTarget) setDefaults(d []ast.Expression)  { t.e.Defaults = d }
-func (t funcExprTarget) setRest(r ast.Expression)        { t.e.Rest = r }
+func (t funcExprTarget) setDefaults(d []ast.Expression) { t.e.Defaults = d }
+func (t funcExprTarget) setRest(r ast.Expression)       { t.e.Rest = r }
 
 func (p *Parser) parseFunctionParams(decl *ast.FunctionDeclaration) {
 	target := funcDeclTarget{decl}
@@ -625,8 +1022,9 @@ func (p *Parser) parseFunctionParamsGeneric(target funcParamTarget) {
 		if p.curTokenIs(token.Spread) {
 			restTok := p.curToken
 			p.nextToken()
-			rest := p.parseBindingPattern()
-			target.setRest(&ast.RestElement{Token: restTok, Argument: rest})
+			rest := &ast.RestElement{Token: restTok, Argument: p.parseBindingPattern()}
+			p.finish(rest, tokenPos(restTok))
+			target.setRest(rest)
 			if p.curTokenIs(token.Comma) {
 				p.nextToken()
 			}
@@ -661,9 +1059,13 @@ func (p *Parser) parseClassDeclaration() *ast.ClassDeclaration {
 	decl := &ast.ClassDeclaration{Token: p.curToken}
 	p.nextToken() // consume class
 
-	if p.curTokenIs(token.Identifier) {
-		decl.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		p.nextToken()
+	// All parts of a class are strict mode code.
+	outer := p.strict
+	p.strict = true
+	defer func() { p.strict = outer }()
+
+	if !p.curTokenIs(token.Extends) && !p.curTokenIs(token.LeftBrace) {
+		decl.Name = p.parseBindingIdentifier()
 	}
 
 	if p.curTokenIs(token.Extends) {
@@ -677,6 +1079,8 @@ func (p *Parser) parseClassDeclaration() *ast.ClassDeclaration {
 
 func (p *Parser) parseClassBody() *ast.ClassBody {
 	body := &ast.ClassBody{Token: p.curToken}
+	start := p.startPos()
+	defer p.finish(body, start)
 	p.expect(token.LeftBrace)
 
 	for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
@@ -684,23 +1088,36 @@ func (p *Parser) parseClassBody() *ast.ClassBody {
 			p.nextToken()
 			continue
 		}
-		method := p.parseMethodDefinition()
+		start := p.startPos()
+		method, field := p.parseClassElement()
+		if field != nil {
+			p.finish(field, start)
+			body.Fields = append(body.Fields, field)
+			continue
+		}
+		p.finish(method, start)
 		body.Methods = append(body.Methods, method)
 	}
 	p.expect(token.RightBrace)
 	return body
 }
 
-func (p *Parser) parseMethodDefinition() *ast.MethodDefinition {
+// parseClassElement parses a method, accessor or field of a class body. It
+// returns either the method or the field.
+func (p *Parser) parseClassElement() (*ast.MethodDefinition, *ast.FieldDefinition) {
 	md := &ast.MethodDefinition{Token: p.curToken, Kind: "method"}
 
-	if p.curTokenIs(token.Identifier) && p.curToken.Literal == "static" {
+	if p.curTokenIsContextual("static") && p.peekTokenIs(token.LeftBrace) {
+		p.nextToken()
+		return nil, &ast.FieldDefinition{Token: md.Token, Static: true, Block: p.parseBlockStatement()}
+	}
+	if p.curTokenIsContextual("static") && !p.peekTokenIs(token.LeftParen) && !p.peekIsFieldEnd() {
 		md.Static = true
 		p.nextToken()
 	}
 
-	if p.curTokenIs(token.Identifier) && (p.curToken.Literal == "get" || p.curToken.Literal == "set") {
-		if !p.peekTokenIs(token.LeftParen) {
+	if p.curTokenIsContextual("get") || p.curTokenIsContextual("set") {
+		if !p.peekTokenIs(token.LeftParen) && !p.peekIsFieldEnd() {
 			md.Kind = p.curToken.Literal
 			p.nextToken()
 		}
@@ -709,13 +1126,12 @@ func (p *Parser) parseMethodDefinition() *ast.MethodDefinition {
 	if p.curTokenIs(token.Asterisk) {
 		p.nextToken()
 		md.Key = p.parseMethodKey(md)
-		fe := p.parseMethodFunctionExpression()
-		fe.Generator = true
+		fe := p.parseMethodFunctionExpression(true, false)
 		md.Value = fe
-		return md
+		return md, nil
 	}
 
-	if (p.curTokenIs(token.Async) || (p.curTokenIs(token.Identifier) && p.curToken.Literal == "async")) && !p.peekTokenIs(token.LeftParen) {
+	if p.curTokenIs(token.Async) && !p.peekTokenIs(token.LeftParen) && !p.peekIsFieldEnd() {
 		p.nextToken()
 		isGen := false
 		if p.curTokenIs(token.Asterisk) {
@@ -723,21 +1139,42 @@ func (p *Parser) parseMethodDefinition() *ast.MethodDefinition {
 			p.nextToken()
 		}
 		md.Key = p.parseMethodKey(md)
-		fe := p.parseMethodFunctionExpression()
-		fe.Async = true
-		fe.Generator = isGen
+		fe := p.parseMethodFunctionExpression(isGen, true)
 		md.Value = fe
-		return md
+		return md, nil
 	}
 
 	md.Key = p.parseMethodKey(md)
 
+	if md.Kind == "method" && !p.curTokenIs(token.LeftParen) {
+		field := &ast.FieldDefinition{Token: md.Token, Key: md.Key, Static: md.Static, Computed: md.Computed}
+		if ident, ok := md.Key.(*ast.Identifier); ok && ident.Value == "constructor" && !md.Computed {
+			p.addError("classes may not have a field named 'constructor'")
+		}
+		if p.curTokenIs(token.Assign) {
+			p.nextToken()
+			field.Value = p.parseAssignmentExpression()
+		}
+		// A field ends with a semicolon, inserted before a new line or }.
+		if !p.curTokenIs(token.Semicolon) && !p.curTokenIs(token.RightBrace) && !p.prevTokenWasNewline() {
+			p.addError("unexpected token %s (%q) after class field", tokenName(p.curToken.Type), p.curToken.Literal)
+		}
+		p.consumeSemicolon()
+		return nil, field
+	}
+
 	if ident, ok := md.Key.(*ast.Identifier); ok && ident.Value == "constructor" && md.Kind == "method" {
 		md.Kind = "constructor"
 	}
 
-	md.Value = p.parseMethodFunctionExpression()
-	return md
+	md.Value = p.parseMethodFunctionExpression(false, false)
+	return md, nil
+}
+
+// peekIsFieldEnd reports whether the next token ends a field declaration,
+// making a contextual word such as static or get the field's name.
+func (p *Parser) peekIsFieldEnd() bool {
+	return p.peekTokenIs(token.Assign) || p.peekTokenIs(token.Semicolon) || p.peekTokenIs(token.RightBrace)
 }
 
 func (p *Parser) parseMethodKey(md *ast.MethodDefinition) ast.Expression {
@@ -748,21 +1185,26 @@ func (p *Parser) parseMethodKey(md *ast.MethodDefinition) ast.Expression {
 		p.expect(token.RightBracket)
 		return key
 	}
+	if p.curTokenIs(token.PrivateName) {
+		return p.parsePrivateIdentifier()
+	}
 	return p.parsePropertyName()
 }
 
-func (p *Parser) parseMethodFunctionExpression() *ast.FunctionExpression {
-	fe := &ast.FunctionExpression{Token: p.curToken}
+func (p *Parser) parseMethodFunctionExpression(generator, async bool) *ast.FunctionExpression {
+	fe := &ast.FunctionExpression{Token: p.curToken, Generator: generator, Async: async}
+	start := p.startPos()
+	defer p.enterFunction(generator, async)()
 	target := funcExprTarget{fe}
 	p.parseFunctionParamsGeneric(target)
-	fe.Body = p.parseBlockStatement()
+	fe.Body, fe.Strict = p.parseFunctionBody()
+	p.finish(fe, start)
 	return fe
 }
 
 func (p *Parser) parseLabeledStatement() *ast.LabeledStatement {
 	stmt := &ast.LabeledStatement{Token: p.curToken}
-	stmt.Label = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	p.nextToken() // consume identifier
+	stmt.Label = p.parseIdentifier()
 	p.nextToken() // consume colon
 	stmt.Body = p.parseStatement()
 	return stmt
@@ -791,6 +1233,256 @@ func (p *Parser) parseWithStatement() *ast.WithStatement {
 	return stmt
 }
 
+// ---------- Module Declarations ----------
+
+func (p *Parser) parseImportDeclaration() *ast.ImportDeclaration {
+	decl := &ast.ImportDeclaration{Token: p.curToken}
+	p.nextToken() // consume import
+
+	if p.curTokenIs(token.String) {
+		decl.Source = p.parseStringLiteral()
+		p.consumeSemicolon()
+		return decl
+	}
+
+	if !p.curTokenIs(token.LeftBrace) && !p.curTokenIs(token.Asterisk) {
+		// import name [, ...] from
+		decl.Specifiers = append(decl.Specifiers, p.parseImportSpecifierAs("default"))
+		if !p.curTokenIs(token.Comma) {
+			p.parseModuleSource(&decl.Source)
+			return decl
+		}
+		p.nextToken() // consume ,
+	}
+
+	if p.curTokenIs(token.Asterisk) {
+		p.nextToken() // consume *
+		p.expect(token.As)
+		decl.Specifiers = append(decl.Specifiers, p.parseImportSpecifierAs("*"))
+	} else if p.curTokenIs(token.LeftBrace) {
+		p.nextToken() // consume {
+		for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
+			start := p.startPos()
+			spec := &ast.ImportSpecifier{Token: p.curToken}
+			spec.Imported = p.parseModuleExportName()
+			if p.curTokenIs(token.As) {
+				p.nextToken() // consume as
+				spec.Local = p.parseBindingIdentifier()
+			} else {
+				spec.Local = &ast.Identifier{Token: spec.Token, Value: spec.Imported}
+				if spec.Token.Type != token.Identifier {
+					p.addError("unexpected %s in import specifier", tokenName(spec.Token.Type))
+				}
+			}
+			p.finish(spec, start)
+			decl.Specifiers = append(decl.Specifiers, spec)
+			if !p.curTokenIs(token.Comma) {
+				break
+			}
+			p.nextToken() // consume ,
+		}
+		p.expect(token.RightBrace)
+	} else {
+		p.addError("unexpected %s in import declaration", tokenName(p.curToken.Type))
+	}
+	p.parseModuleSource(&decl.Source)
+	return decl
+}
+
+// parseImportSpecifierAs parses a local binding name for a default or
+// namespace import.
+func (p *Parser) parseImportSpecifierAs(imported string) *ast.ImportSpecifier {
+	start := p.startPos()
+	spec := &ast.ImportSpecifier{Token: p.curToken, Imported: imported}
+	spec.Local = p.parseBindingIdentifier()
+	p.finish(spec, start)
+	return spec
+}
+
+// parseModuleSource parses the `from "source"` clause that ends an import or
+// re-export.
+func (p *Parser) parseModuleSource(dst **ast.StringLiteral) {
+	p.expect(token.From)
+	if !p.curTokenIs(token.String) {
+		p.addError("expected module specifier string, got %s", tokenName(p.curToken.Type))
+		return
+	}
+	*dst = p.parseStringLiteral()
+	p.consumeSemicolon()
+}
+
+// parseModuleExportName parses the name of an export, which may be any
+// identifier name (including reserved words) or a string literal.
+func (p *Parser) parseModuleExportName() string {
+	if p.curTokenIs(token.String) {
+		return p.parseStringLiteral().Value
+	}
+	if ident, ok := p.parsePropertyName().(*ast.Identifier); ok {
+		return ident.Value
+	}
+	p.addError("invalid export name")
+	return ""
+}
+
+// parseBindingIdentifier parses the name a declaration binds. Reserved
+// words are never allowed; let and yield only in sloppy code, yield not in
+// a generator either, and await neither in a module nor an async function.
+func (p *Parser) parseBindingIdentifier() *ast.Identifier {
+	switch p.curToken.Type {
+	case token.Identifier, token.From, token.As, token.Of, token.Async, token.Undefined:
+	case token.Let, token.Yield, token.Await:
+		p.checkContextualName(p.curToken)
+	default:
+		if token.Keywords[p.curToken.Literal] == p.curToken.Type {
+			p.addError("unexpected reserved word %q; expected identifier", p.curToken.Literal)
+		} else {
+			p.addError("expected identifier, got %s", tokenName(p.curToken.Type))
+		}
+	}
+	return p.parseIdentifier()
+}
+
+// checkContextualName reports let, yield or await used as a name where it
+// is reserved: let and yield in strict mode code, yield also in a generator
+// and await in a module or an async function.
+func (p *Parser) checkContextualName(tok token.Token) {
+	switch {
+	case tok.Literal == "await":
+		p.checkAwaitName(tok)
+	case p.strict:
+		p.addError("unexpected strict mode reserved word %q", tok.Literal)
+	case p.generator && tok.Literal == "yield":
+		p.addError("yield cannot be a name in a generator")
+	}
+}
+
+// checkLexicalBinding reports let among the names a let or const
+// declaration binds by pat, which is an error even in sloppy code, where
+// let is otherwise a name.
+func (p *Parser) checkLexicalBinding(pat ast.Expression) {
+	switch pat := pat.(type) {
+	case *ast.Identifier:
+		if pat.Value == "let" {
+			p.addErrorAt(pat.Token, "let is disallowed as a lexically bound name")
+		}
+	case *ast.ObjectPattern:
+		for _, prop := range pat.Properties {
+			p.checkLexicalBinding(prop.Value)
+		}
+	case *ast.ArrayPattern:
+		for _, elem := range pat.Elements {
+			p.checkLexicalBinding(elem)
+		}
+	case *ast.AssignmentPattern:
+		p.checkLexicalBinding(pat.Left)
+	case *ast.RestElement:
+		p.checkLexicalBinding(pat.Argument)
+	}
+}
+
+// checkAwaitName reports await used as a name in a module or an async
+// function, where it is an operator.
+func (p *Parser) checkAwaitName(tok token.Token) {
+	switch {
+	case p.module:
+		p.addError("unexpected reserved word %q in module code", tok.Literal)
+	case p.async:
+		p.addError("unexpected reserved word %q in an async function", tok.Literal)
+	}
+}
+
+func (p *Parser) parseExportDeclaration() ast.Statement {
+	tok := p.curToken
+	p.nextToken() // consume export
+
+	switch p.curToken.Type {
+	case token.Default:
+		return p.parseExportDefault(tok)
+	case token.Asterisk:
+		decl := &ast.ExportAllDeclaration{Token: tok}
+		p.nextToken() // consume *
+		if p.curTokenIs(token.As) {
+			p.nextToken() // consume as
+			decl.Exported = p.parseModuleExportName()
+		}
+		p.parseModuleSource(&decl.Source)
+		return decl
+	case token.LeftBrace:
+		decl := &ast.ExportNamedDeclaration{Token: tok}
+		p.nextToken() // consume {
+		for !p.curTokenIs(token.RightBrace) && !p.curTokenIs(token.EOF) {
+			start := p.startPos()
+			spec := &ast.ExportSpecifier{Token: p.curToken}
+			spec.Local = p.parseModuleExportName()
+			spec.Exported = spec.Local
+			if p.curTokenIs(token.As) {
+				p.nextToken() // consume as
+				spec.Exported = p.parseModuleExportName()
+			}
+			p.finish(spec, start)
+			decl.Specifiers = append(decl.Specifiers, spec)
+			if !p.curTokenIs(token.Comma) {
+				break
+			}
+			p.nextToken() // consume ,
+		}
+		p.expect(token.RightBrace)
+		if p.curTokenIs(token.From) {
+			p.parseModuleSource(&decl.Source)
+		} else {
+			p.consumeSemicolon()
+		}
+		return decl
+	case token.Var, token.Let, token.Const, token.Function, token.Class, token.Async:
+		decl := &ast.ExportNamedDeclaration{Token: tok}
+		if p.curTokenIs(token.Async) && !p.peekTokenIs(token.Function) {
+			p.addError("unexpected %s after export", tokenName(p.curToken.Type))
+		}
+		decl.Declaration = p.parseStatement()
+		return decl
+	}
+	p.addError("unexpected %s after export", tokenName(p.curToken.Type))
+	p.nextToken()
+	return nil
+}
+
+// parseExportDefault parses `export default`. Named function and class
+// declarations stay declarations, so functions are hoisted; anything else is
+// an expression.
+func (p *Parser) parseExportDefault(tok token.Token) *ast.ExportDefaultDeclaration {
+	decl := &ast.ExportDefaultDeclaration{Token: tok}
+	p.nextToken() // consume default
+
+	start := p.startPos()
+	if p.curTokenIs(token.Class) && p.peekTokenIs(token.Identifier) {
+		cd := p.parseClassDeclaration()
+		p.finish(cd, start)
+		decl.Declaration = cd
+		return decl
+	}
+	// A function, named or not, is a hoisted declaration and ends the
+	// statement; the module gives an anonymous one its binding.
+	var fn *ast.FunctionExpression
+	switch {
+	case p.curTokenIs(token.Function):
+		fn = p.parseFunctionExpression(false)
+	case p.curTokenIs(token.Async) && p.peekTokenIs(token.Function) && !p.peekTokenOnNewline():
+		fn = p.parseAsyncFunctionExpression()
+	}
+	if fn != nil {
+		p.finish(fn, start)
+		decl.Declaration = &ast.FunctionDeclaration{
+			SourceSpan: fn.SourceSpan, Token: fn.Token, Name: fn.Name,
+			Params: fn.Params, Body: fn.Body, Generator: fn.Generator, Async: fn.Async,
+			Defaults: fn.Defaults, Rest: fn.Rest, Strict: fn.Strict,
+		}
+		return decl
+	}
+	decl.Declaration = p.parseAssignmentExpression()
+	p.consumeSemicolon()
+	return decl
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(0)
@@ -801,13 +1493,16 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 // ---------- Expression Parsing (Pratt) ----------
 
 func (p *Parser) parseExpression(minPrec int) ast.Expression {
+	start := p.startPos()
 	left := p.parsePrefixExpression()
+	p.finish(left, start)
 	for {
 		prec := p.infixPrecedence()
 		if prec <= minPrec {
 			break
 		}
 		left = p.parseInfixExpression(left, prec)
+		p.finish(left, start)
 	}
 	return left
 }
@@ -831,19 +1526,25 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	case token.Undefined:
 		return p.parseUndefinedLiteral()
 	case token.LeftParen:
-		return p.parseParenthesizedOrArrow()
+		return p.parseParenthesizedOrArrow(false)
 	case token.LeftBracket:
 		return p.parseArrayLiteral()
 	case token.LeftBrace:
 		return p.parseObjectLiteral()
 	case token.Function:
-		return p.parseFunctionExpression()
+		return p.parseFunctionExpression(false)
 	case token.Class:
 		return p.parseClassExpression()
 	case token.This:
 		return p.parseThisExpression()
 	case token.Super:
 		return p.parseSuperExpression()
+	case token.PrivateName:
+		// A private name on its own is only valid as #x in obj.
+		if !p.peekTokenIs(token.In) {
+			p.addError("unexpected private name %s", p.curToken.Literal)
+		}
+		return p.parsePrivateIdentifier()
 	case token.New:
 		return p.parseNewExpression()
 	case token.Not, token.BitwiseNot, token.Typeof, token.Void, token.Delete:
@@ -855,9 +1556,21 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	case token.Spread:
 		return p.parseSpreadElement()
 	case token.Yield:
-		return p.parseYieldExpression()
+		// Outside generators, yield is an ordinary identifier.
+		if p.generator {
+			return p.parseYieldExpression()
+		}
+		p.checkContextualName(p.curToken)
+		return p.parseIdentifier()
 	case token.Await:
-		return p.parseAwaitExpression()
+		// Outside async functions and modules, await is an identifier.
+		if p.async || p.module {
+			return p.parseAwaitExpression()
+		}
+		return p.parseIdentifier()
+	case token.Let:
+		p.checkContextualName(p.curToken)
+		return p.parseIdentifier()
 	case token.Async:
 		return p.parseAsyncExpressionPrefix()
 	case token.NoSubstitutionTemplate:
@@ -882,16 +1595,16 @@ func (p *Parser) parseIdentifierOrArrow() ast.Expression {
 }
 
 func (p *Parser) parseSingleParamArrow() ast.Expression {
-	paramTok := p.curToken
-	param := &ast.Identifier{Token: paramTok, Value: paramTok.Literal}
-	p.nextToken() // consume identifier
+	param := p.parseIdentifier()
 	arrowTok := p.curToken
+	p.checkArrowLine()
 	p.nextToken() // consume =>
 	arrow := &ast.ArrowFunctionExpression{Token: arrowTok, Params: []ast.Expression{param}}
 	if p.curTokenIs(token.LeftBrace) {
-		arrow.Body = p.parseBlockStatement()
+		arrow.Body, arrow.Strict = p.parseArrowBody(false)
 	} else {
-		arrow.Body = p.parseAssignmentExpression()
+		arrow.Body = p.parseConciseBody(false)
+		arrow.Strict = p.strict
 	}
 	return arrow
 }
@@ -899,6 +1612,14 @@ func (p *Parser) parseSingleParamArrow() ast.Expression {
 func (p *Parser) parseAsyncExpressionPrefix() ast.Expression {
 	asyncTok := p.curToken
 
+	// async followed by a line break is an identifier, not a modifier.
+	if p.peekTokenOnNewline() && !p.peekTokenIs(token.LeftParen) {
+		ident := &ast.Identifier{Token: asyncTok, Value: asyncTok.Literal}
+		finishToken(ident, asyncTok)
+		p.nextToken()
+		return ident
+	}
+
 	if p.peekTokenIs(token.Function) {
 		return p.parseAsyncFunctionExpression()
 	}
@@ -910,10 +1631,9 @@ func (p *Parser) parseAsyncExpressionPrefix() ast.Expression {
 	if p.peekTokenIs(token.Identifier) {
 		p.nextToken()
 		if p.peekTokenIs(token.Arrow) {
-			paramTok := p.curToken
-			param := &ast.Identifier{Token: paramTok, Value: paramTok.Literal}
-			p.nextToken() // consume identifier
+			param := p.parseIdentifier()
 			arrowTok := p.curToken
+			p.checkArrowLine()
 			p.nextToken() // consume =>
 			arrow := &ast.ArrowFunctionExpression{
 				Token:  arrowTok,
@@ -921,13 +1641,16 @@ func (p *Parser) parseAsyncExpressionPrefix() ast.Expression {
 				Async:  true,
 			}
 			if p.curTokenIs(token.LeftBrace) {
-				arrow.Body = p.parseBlockStatement()
+				arrow.Body, arrow.Strict = p.parseArrowBody(true)
 			} else {
-				arrow.Body = p.parseAssignmentExpression()
+				arrow.Body = p.parseConciseBody(true)
+				arrow.Strict = p.strict
 			}
 			return arrow
 		}
-		return &ast.Identifier{Token: asyncTok, Value: asyncTok.Literal}
+		ident := &ast.Identifier{Token: asyncTok, Value: asyncTok.Literal}
+		finishToken(ident, asyncTok)
+		return ident
 	}
 
 	return p.parseIdentifier()
@@ -938,7 +1661,7 @@ func (p *Parser) parseAsyncArrowOrCall() ast.Expression {
 	p.nextToken() // consume async, now on (
 
 	// Parse the parenthesized content
-	result := p.parseParenthesizedOrArrow()
+	result := p.parseParenthesizedOrArrow(true)
 
 	// If it was parsed as an arrow, mark it async
 	if arrow, ok := result.(*ast.ArrowFunctionExpression); ok {
@@ -948,6 +1671,7 @@ func (p *Parser) parseAsyncArrowOrCall() ast.Expression {
 
 	// Otherwise it was a group expression, treat "async" as an identifier being called
 	callee := &ast.Identifier{Token: asyncTok, Value: "async"}
+	finishToken(callee, asyncTok)
 	// The result was already parsed as a group, but we need to convert it to a call
 	// Since parseParenthesizedOrArrow consumed the parens, we wrap it as a call
 	var args []ast.Expression
@@ -957,22 +1681,67 @@ func (p *Parser) parseAsyncArrowOrCall() ast.Expression {
 		args = []ast.Expression{result}
 	}
 	call := &ast.CallExpression{Token: asyncTok, Callee: callee, Arguments: args}
+	p.finish(call, tokenPos(asyncTok))
 	return p.parsePostfixOps(call)
 }
 
 func (p *Parser) parseAsyncFunctionExpression() *ast.FunctionExpression {
 	p.nextToken() // consume async
-	fe := p.parseFunctionExpression()
-	fe.Async = true
-	return fe
+	return p.parseFunctionExpression(true)
 }
 
 func (p *Parser) parseIdentifier() *ast.Identifier {
+	p.checkIdentifier(p.curToken)
 	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	start := p.startPos()
 	p.nextToken()
+	p.finish(ident, start)
 	return ident
 }
 
+// checkIdentifier reports an identifier token that spells a reserved word.
+// Only escaped identifiers and enum, which is not a keyword token, can do
+// so in scripts, since the lexer turns the plain spelling of the others into
+// a keyword token; strict code also reserves let,
+// static, yield and the like, modules and async functions reserve await and
+// generators yield.
+func (p *Parser) checkIdentifier(tok token.Token) {
+	if tok.Type != token.Identifier {
+		return
+	}
+	switch {
+	case token.IsReservedWord(tok.Literal) && tok.Escaped:
+		p.addError("keyword %q must not contain escape sequences", tok.Literal)
+	case token.IsReservedWord(tok.Literal):
+		p.addError("unexpected reserved word %q", tok.Literal)
+	case tok.Literal == "await":
+		p.checkAwaitName(tok)
+	case p.strict && token.IsStrictReservedWord(tok.Literal):
+		p.addError("unexpected strict mode reserved word %q", tok.Literal)
+	case p.generator && tok.Literal == "yield":
+		p.addError("yield cannot be a name in a generator")
+	}
+}
+
+// checkShorthand validates the key of a shorthand property such as {x} or
+// {x = 1}, which is also an identifier reference or binding.
+func (p *Parser) checkShorthand(key ast.Expression) {
+	ident, ok := key.(*ast.Identifier)
+	if !ok {
+		p.addError("invalid shorthand property")
+		return
+	}
+	switch ident.Token.Type {
+	case token.Identifier:
+		p.checkIdentifier(ident.Token)
+	case token.Let, token.Yield, token.Await:
+		p.checkContextualName(ident.Token)
+	case token.From, token.As, token.Of, token.Async, token.Undefined:
+	default:
+		p.addError("unexpected keyword %q in shorthand property", ident.Value)
+	}
+}
+
 func (p *Parser) parseNumberLiteral() *ast.NumberLiteral {
 	lit := &ast.NumberLiteral{Token: p.curToken}
 	val, err := parseJSNumber(p.curToken.Literal)
@@ -1012,6 +1781,7 @@ func parseJSNumber(s string) (float64, error) {
 
 func (p *Parser) parseStringLiteral() *ast.StringLiteral {
 	lit := &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	finishToken(lit, p.curToken)
 	p.nextToken()
 	return lit
 }
@@ -1046,7 +1816,9 @@ func (p *Parser) parseSuperExpression() *ast.SuperExpression {
 	return expr
 }
 
-func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
+// parseParenthesizedOrArrow parses a parenthesized expression or the arrow
+// function it turns out to start; async marks the arrow of async (...) =>.
+func (p *Parser) parseParenthesizedOrArrow(async bool) ast.Expression {
 	// Use a fresh lexer-based parser to speculatively test for arrow params.
 	// We create an entirely new parser from the same source position if needed.
 	// Simpler approach: parse as group expression, then if we see => after ),
@@ -1058,12 +1830,14 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 		p.nextToken()
 		if p.curTokenIs(token.Arrow) {
 			arrowTok := p.curToken
+			p.checkArrowLine()
 			p.nextToken()
 			arrow := &ast.ArrowFunctionExpression{Token: arrowTok}
 			if p.curTokenIs(token.LeftBrace) {
-				arrow.Body = p.parseBlockStatement()
+				arrow.Body, arrow.Strict = p.parseArrowBody(async)
 			} else {
-				arrow.Body = p.parseAssignmentExpression()
+				arrow.Body = p.parseConciseBody(async)
+				arrow.Strict = p.strict
 			}
 			return arrow
 		}
@@ -1073,6 +1847,7 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 
 	// Try to parse content - if it looks like params and we see =>, it's an arrow
 	var items []ast.Expression
+	var exprs []ast.Expression // items as written, for the non-arrow case
 	var defaults []ast.Expression
 	var rest ast.Expression
 	hasDefaults := false
@@ -1084,6 +1859,7 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 			p.nextToken()
 			arg := p.parseBindingPattern()
 			rest = &ast.RestElement{Token: restTok, Argument: arg}
+			p.finish(rest, tokenPos(restTok))
 			if p.curTokenIs(token.Comma) {
 				p.nextToken()
 			}
@@ -1092,6 +1868,7 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 
 		item := p.parseAssignmentExpression()
 		items = append(items, item)
+		exprs = append(exprs, item)
 		defaults = append(defaults, nil)
 
 		// Check if this was param = default
@@ -1115,6 +1892,7 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 
 	if canBeArrow && p.curTokenIs(token.Arrow) {
 		arrowTok := p.curToken
+		p.checkArrowLine()
 		p.nextToken() // consume =>
 		arrow := &ast.ArrowFunctionExpression{Token: arrowTok, Params: items}
 		if hasDefaults {
@@ -1124,9 +1902,10 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 			arrow.Rest = rest
 		}
 		if p.curTokenIs(token.LeftBrace) {
-			arrow.Body = p.parseBlockStatement()
+			arrow.Body, arrow.Strict = p.parseArrowBody(async)
 		} else {
-			arrow.Body = p.parseAssignmentExpression()
+			arrow.Body = p.parseConciseBody(async)
+			arrow.Strict = p.strict
 		}
 		return arrow
 	}
@@ -1137,15 +1916,18 @@ func (p *Parser) parseParenthesizedOrArrow() ast.Expression {
 		p.addError("empty parenthesized expression")
 		return &ast.Identifier{Token: openTok}
 	}
-	if len(items) == 1 && rest == nil {
-		return items[0]
+	if rest != nil {
+		p.addError("unexpected rest element in parenthesized expression")
+	}
+	if len(exprs) == 1 {
+		p.parenthesized[exprs[0]] = true
+		return exprs[0]
 	}
 	// Multiple items = sequence expression
-	seq := &ast.SequenceExpression{Token: openTok, Expressions: items}
+	seq := &ast.SequenceExpression{Token: openTok, Expressions: exprs}
 	return seq
 }
 
-
 func (p *Parser) parseGroupExpression() ast.Expression {
 	p.nextToken() // consume (
 
@@ -1153,12 +1935,14 @@ func (p *Parser) parseGroupExpression() ast.Expression {
 		p.nextToken()
 		if p.curTokenIs(token.Arrow) {
 			arrowTok := p.curToken
+			p.checkArrowLine()
 			p.nextToken()
 			arrow := &ast.ArrowFunctionExpression{Token: arrowTok}
 			if p.curTokenIs(token.LeftBrace) {
-				arrow.Body = p.parseBlockStatement()
+				arrow.Body, arrow.Strict = p.parseArrowBody(false)
 			} else {
-				arrow.Body = p.parseAssignmentExpression()
+				arrow.Body = p.parseConciseBody(false)
+				arrow.Strict = p.strict
 			}
 			return arrow
 		}
@@ -1169,6 +1953,7 @@ func (p *Parser) parseGroupExpression() ast.Expression {
 	expr := p.parseExpression(0)
 
 	p.expect(token.RightParen)
+	p.parenthesized[expr] = true
 	return expr
 }
 
@@ -1183,10 +1968,7 @@ func (p *Parser) parseArrayLiteral() *ast.ArrayLiteral {
 			continue
 		}
 		if p.curTokenIs(token.Spread) {
-			spread := &ast.SpreadElement{Token: p.curToken}
-			p.nextToken()
-			spread.Argument = p.parseAssignmentExpression()
-			arr.Elements = append(arr.Elements, spread)
+			arr.Elements = append(arr.Elements, p.parseSpreadElement())
 		} else {
 			arr.Elements = append(arr.Elements, p.parseAssignmentExpression())
 		}
@@ -1217,17 +1999,19 @@ func (p *Parser) parseObjectLiteral() *ast.ObjectLiteral {
 
 func (p *Parser) parseObjectProperty() *ast.Property {
 	prop := &ast.Property{Token: p.curToken, Kind: "init"}
+	start := p.startPos()
+	defer p.finish(prop, start)
 
 	// Spread property
 	if p.curTokenIs(token.Spread) {
-		spread := &ast.SpreadElement{Token: p.curToken}
-		p.nextToken()
-		spread.Argument = p.parseAssignmentExpression()
-		return &ast.Property{Token: spread.Token, Key: spread, Value: spread, Kind: "init"}
+		spread := p.parseSpreadElement()
+		prop.Key = spread
+		prop.Value = spread
+		return prop
 	}
 
 	// getter/setter
-	if p.curTokenIs(token.Identifier) && (p.curToken.Literal == "get" || p.curToken.Literal == "set") {
+	if p.curTokenIsContextual("get") || p.curTokenIsContextual("set") {
 		kindTok := p.curToken
 		kind := kindTok.Literal
 		if p.peekTokenIs(token.LeftParen) || p.peekTokenIs(token.Colon) || p.peekTokenIs(token.Comma) || p.peekTokenIs(token.RightBrace) || p.peekTokenIs(token.Assign) {
@@ -1236,10 +2020,7 @@ func (p *Parser) parseObjectProperty() *ast.Property {
 		p.nextToken()
 		prop.Kind = kind
 		prop.Key = p.parseObjectPropertyKey(prop)
-		fe := &ast.FunctionExpression{Token: p.curToken}
-		target := funcExprTarget{fe}
-		p.parseFunctionParamsGeneric(target)
-		fe.Body = p.parseBlockStatement()
+		fe := p.parseMethodFunctionExpression(false, false)
 		prop.Value = fe
 		prop.Method = true
 		return prop
@@ -1247,7 +2028,7 @@ func (p *Parser) parseObjectProperty() *ast.Property {
 
 normalProperty:
 	// async method
-	if (p.curTokenIs(token.Async) || (p.curTokenIs(token.Identifier) && p.curToken.Literal == "async")) && !p.peekTokenIs(token.Colon) && !p.peekTokenIs(token.Comma) && !p.peekTokenIs(token.RightBrace) && !p.peekTokenIs(token.LeftParen) && !p.peekTokenIs(token.Assign) {
+	if p.curTokenIs(token.Async) && !p.peekTokenIs(token.Colon) && !p.peekTokenIs(token.Comma) && !p.peekTokenIs(token.RightBrace) && !p.peekTokenIs(token.LeftParen) && !p.peekTokenIs(token.Assign) {
 		p.nextToken()
 		isGen := false
 		if p.curTokenIs(token.Asterisk) {
@@ -1255,12 +2036,7 @@ normalProperty:
 			p.nextToken()
 		}
 		prop.Key = p.parseObjectPropertyKey(prop)
-		fe := &ast.FunctionExpression{Token: p.curToken}
-		target := funcExprTarget{fe}
-		p.parseFunctionParamsGeneric(target)
-		fe.Body = p.parseBlockStatement()
-		fe.Async = true
-		fe.Generator = isGen
+		fe := p.parseMethodFunctionExpression(isGen, true)
 		prop.Value = fe
 		prop.Method = true
 		return prop
@@ -1270,11 +2046,7 @@ normalProperty:
 	if p.curTokenIs(token.Asterisk) {
 		p.nextToken()
 		prop.Key = p.parseObjectPropertyKey(prop)
-		fe := &ast.FunctionExpression{Token: p.curToken}
-		target := funcExprTarget{fe}
-		p.parseFunctionParamsGeneric(target)
-		fe.Body = p.parseBlockStatement()
-		fe.Generator = true
+		fe := p.parseMethodFunctionExpression(true, false)
 		prop.Value = fe
 		prop.Method = true
 		return prop
@@ -1284,11 +2056,7 @@ normalProperty:
 
 	// Method shorthand: key(...)
 	if p.curTokenIs(token.LeftParen) {
-		fe := &ast.FunctionExpression{Token: p.curToken}
-		target := funcExprTarget{fe}
-		p.parseFunctionParamsGeneric(target)
-		fe.Body = p.parseBlockStatement()
-		prop.Value = fe
+		prop.Value = p.parseMethodFunctionExpression(false, false)
 		prop.Method = true
 		return prop
 	}
@@ -1301,6 +2069,7 @@ normalProperty:
 	}
 
 	// Shorthand property {x} or {x = default}
+	p.checkShorthand(prop.Key)
 	prop.Shorthand = true
 	prop.Value = prop.Key
 	if p.curTokenIs(token.Assign) {
@@ -1310,6 +2079,7 @@ normalProperty:
 			Left:  prop.Key,
 			Right: p.parseAssignmentExpression(),
 		}
+		p.finish(prop.Value, start)
 	}
 	return prop
 }
@@ -1325,7 +2095,31 @@ func (p *Parser) parseObjectPropertyKey(prop *ast.Property) ast.Expression {
 	return p.parsePropertyName()
 }
 
+// parsePrivateIdentifier parses a private name such as #count.
+func (p *Parser) parsePrivateIdentifier() *ast.PrivateIdentifier {
+	start := p.startPos()
+	ident := &ast.PrivateIdentifier{Token: p.curToken, Name: p.curToken.Literal}
+	p.nextToken()
+	p.finish(ident, start)
+	return ident
+}
+
+// parseMemberName parses the name after a dot, which may be a private name.
+func (p *Parser) parseMemberName() ast.Expression {
+	if p.curTokenIs(token.PrivateName) {
+		return p.parsePrivateIdentifier()
+	}
+	return p.parsePropertyName()
+}
+
 func (p *Parser) parsePropertyName() ast.Expression {
+	start := p.startPos()
+	name := p.parsePropertyNameInner()
+	p.finish(name, start)
+	return name
+}
+
+func (p *Parser) parsePropertyNameInner() ast.Expression {
 	switch p.curToken.Type {
 	case token.Identifier, token.Var, token.Let, token.Const, token.Function, token.Return,
 		token.If, token.Else, token.While, token.For, token.Do, token.Break, token.Continue,
@@ -1349,8 +2143,8 @@ func (p *Parser) parsePropertyName() ast.Expression {
 	}
 }
 
-func (p *Parser) parseFunctionExpression() *ast.FunctionExpression {
-	fe := &ast.FunctionExpression{Token: p.curToken}
+func (p *Parser) parseFunctionExpression(async bool) *ast.FunctionExpression {
+	fe := &ast.FunctionExpression{Token: p.curToken, Async: async}
 	p.nextToken() // consume function
 
 	if p.curTokenIs(token.Asterisk) {
@@ -1358,14 +2152,15 @@ func (p *Parser) parseFunctionExpression() *ast.FunctionExpression {
 		p.nextToken()
 	}
 
-	if p.curTokenIs(token.Identifier) {
-		fe.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		p.nextToken()
+	// The name of a generator or async expression is bound inside it.
+	defer p.enterFunction(fe.Generator, async)()
+	if !p.curTokenIs(token.LeftParen) {
+		fe.Name = p.parseBindingIdentifier()
 	}
 
 	target := funcExprTarget{fe}
 	p.parseFunctionParamsGeneric(target)
-	fe.Body = p.parseBlockStatement()
+	fe.Body, fe.Strict = p.parseFunctionBody()
 	return fe
 }
 
@@ -1373,9 +2168,12 @@ func (p *Parser) parseClassExpression() *ast.ClassExpression {
 	expr := &ast.ClassExpression{Token: p.curToken}
 	p.nextToken() // consume class
 
-	if p.curTokenIs(token.Identifier) && !p.curTokenIs(token.Extends) {
-		expr.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		p.nextToken()
+	outer := p.strict
+	p.strict = true
+	defer func() { p.strict = outer }()
+
+	if !p.curTokenIs(token.Extends) && !p.curTokenIs(token.LeftBrace) {
+		expr.Name = p.parseBindingIdentifier()
 	}
 
 	if p.curTokenIs(token.Extends) {
@@ -1391,17 +2189,31 @@ func (p *Parser) parseNewExpression() ast.Expression {
 	tok := p.curToken
 	p.nextToken() // consume new
 
+	if p.curTokenIs(token.Dot) {
+		start := tokenPos(tok)
+		p.nextToken()
+		if !p.curTokenIs(token.Identifier) || p.curToken.Literal != "target" {
+			p.addError("unexpected token %s (%q) after new.", tokenName(p.curToken.Type), p.curToken.Literal)
+		}
+		expr := &ast.MetaProperty{Token: tok, Meta: "new", Property: p.curToken.Literal}
+		p.nextToken()
+		p.finish(expr, start)
+		return expr
+	}
+
 	callee := p.parseLeftHandSideExpression()
 
 	if p.curTokenIs(token.LeftParen) {
 		args := p.parseArguments()
 		expr := &ast.NewExpression{Token: tok, Callee: callee, Arguments: args}
+		p.finish(expr, tokenPos(tok))
 		return p.parsePostfixOps(expr)
 	}
 	return &ast.NewExpression{Token: tok, Callee: callee}
 }
 
 func (p *Parser) parseLeftHandSideExpression() ast.Expression {
+	start := p.startPos()
 	var left ast.Expression
 	switch p.curToken.Type {
 	case token.Identifier:
@@ -1420,7 +2232,7 @@ func (p *Parser) parseLeftHandSideExpression() ast.Expression {
 		if p.curTokenIs(token.Dot) {
 			tok := p.curToken
 			p.nextToken()
-			prop := p.parsePropertyName()
+			prop := p.parseMemberName()
 			left = &ast.MemberExpression{Token: tok, Object: left, Property: prop}
 		} else if p.curTokenIs(token.LeftBracket) {
 			tok := p.curToken
@@ -1431,7 +2243,9 @@ func (p *Parser) parseLeftHandSideExpression() ast.Expression {
 		} else {
 			break
 		}
+		p.finish(left, start)
 	}
+	p.finish(left, start)
 	return left
 }
 
@@ -1440,6 +2254,11 @@ func (p *Parser) parseUnaryExpression() ast.Expression {
 	op := tok.Literal
 	p.nextToken()
 	operand := p.parseExpression(precUnary)
+	if member, ok := operand.(*ast.MemberExpression); ok && tok.Type == token.Delete {
+		if _, private := member.Property.(*ast.PrivateIdentifier); private {
+			p.addError("private fields can not be deleted")
+		}
+	}
 	return &ast.UnaryExpression{Token: tok, Operator: op, Operand: operand, Prefix: true}
 }
 
@@ -1448,13 +2267,16 @@ func (p *Parser) parsePrefixUpdateExpression() ast.Expression {
 	op := tok.Literal
 	p.nextToken()
 	operand := p.parseExpression(precUnary)
+	p.checkSimpleTarget(operand, "prefix operation")
 	return &ast.UpdateExpression{Token: tok, Operator: op, Operand: operand, Prefix: true}
 }
 
 func (p *Parser) parseSpreadElement() *ast.SpreadElement {
 	spread := &ast.SpreadElement{Token: p.curToken}
+	start := p.startPos()
 	p.nextToken() // consume ...
 	spread.Argument = p.parseAssignmentExpression()
+	p.finish(spread, start)
 	return spread
 }
 
@@ -1462,6 +2284,10 @@ func (p *Parser) parseYieldExpression() *ast.YieldExpression {
 	expr := &ast.YieldExpression{Token: p.curToken}
 	p.nextToken() // consume yield
 
+	// Like return, yield takes no operand from the next line.
+	if p.prevTokenWasNewline() {
+		return expr
+	}
 	if p.curTokenIs(token.Asterisk) {
 		expr.Delegate = true
 		p.nextToken()
@@ -1484,42 +2310,28 @@ func (p *Parser) parseAwaitExpression() *ast.AwaitExpression {
 
 func (p *Parser) parseNoSubstitutionTemplate() *ast.TemplateLiteralExpr {
 	tmpl := &ast.TemplateLiteralExpr{Token: p.curToken}
-	tmpl.Quasis = append(tmpl.Quasis, &ast.TemplateElement{
-		Token: p.curToken,
-		Value: p.curToken.Literal,
-		Tail:  true,
-	})
-	p.nextToken()
+	start := p.startPos()
+	p.appendTemplateElement(tmpl, true)
+	p.finish(tmpl, start)
 	return tmpl
 }
 
 func (p *Parser) parseTemplateLiteral() *ast.TemplateLiteralExpr {
 	tmpl := &ast.TemplateLiteralExpr{Token: p.curToken}
-	tmpl.Quasis = append(tmpl.Quasis, &ast.TemplateElement{
-		Token: p.curToken,
-		Value: p.curToken.Literal,
-	})
-	p.nextToken() // move past TemplateHead
+	start := p.startPos()
+	defer p.finish(tmpl, start)
+	p.appendTemplateElement(tmpl, false) // moves past TemplateHead
 
 	for {
 		expr := p.parseExpression(precComma)
 		tmpl.Expressions = append(tmpl.Expressions, expr)
 
 		if p.curTokenIs(token.TemplateTail) {
-			tmpl.Quasis = append(tmpl.Quasis, &ast.TemplateElement{
-				Token: p.curToken,
-				Value: p.curToken.Literal,
-				Tail:  true,
-			})
-			p.nextToken()
+			p.appendTemplateElement(tmpl, true)
 			break
 		}
 		if p.curTokenIs(token.TemplateMiddle) {
-			tmpl.Quasis = append(tmpl.Quasis, &ast.TemplateElement{
-				Token: p.curToken,
-				Value: p.curToken.Literal,
-			})
-			p.nextToken()
+			p.appendTemplateElement(tmpl, false)
 			continue
 		}
 		p.addError("expected template middle or tail, got %s", tokenName(p.curToken.Type))
@@ -1528,6 +2340,19 @@ func (p *Parser) parseTemplateLiteral() *ast.TemplateLiteralExpr {
 	return tmpl
 }
 
+// appendTemplateElement adds the current template token as a quasi of tmpl
+// and advances past it.
+func (p *Parser) appendTemplateElement(tmpl *ast.TemplateLiteralExpr, tail bool) {
+	elem := &ast.TemplateElement{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+		Tail:  tail,
+	}
+	finishToken(elem, p.curToken)
+	tmpl.Quasis = append(tmpl.Quasis, elem)
+	p.nextToken()
+}
+
 func (p *Parser) parseRegExpLiteral() ast.Expression {
 	raw := p.curToken.Literal // e.g. "/pattern/flags"
 	// Find last '/' which separates pattern from flags
@@ -1584,6 +2409,11 @@ func (p *Parser) infixPrecedence() int {
 	case token.Exponent:
 		return precExponent
 	case token.Increment, token.Decrement:
+		// A postfix operator must be on the operand's line; a ++ or -- on
+		// the next line starts a new statement.
+		if p.prevTokenWasNewline() {
+			return 0
+		}
 		return precPostfix
 	case token.LeftParen:
 		return precCall
@@ -1608,7 +2438,7 @@ func (p *Parser) parseInfixExpression(left ast.Expression, prec int) ast.Express
 		return p.parseAssignmentInfix(left)
 	case token.QuestionMark:
 		return p.parseConditionalExpression(left)
-	case token.Or, token.And:
+	case token.Or, token.And, token.NullishCoalesce:
 		return p.parseLogicalInfix(left)
 	case token.Exponent:
 		return p.parseExponentInfix(left)
@@ -1640,6 +2470,11 @@ func (p *Parser) parseSequenceExpression(left ast.Expression) ast.Expression {
 
 func (p *Parser) parseAssignmentInfix(left ast.Expression) ast.Expression {
 	tok := p.curToken
+	if tok.Type == token.Assign {
+		left = p.toAssignmentTarget(left, "assignment")
+	} else {
+		p.checkSimpleTarget(left, "assignment")
+	}
 	p.nextToken()
 	right := p.parseAssignmentExpression()
 	return &ast.AssignmentExpression{Token: tok, Operator: tok.Literal, Left: left, Right: right}
@@ -1681,7 +2516,9 @@ func (p *Parser) parseExponentInfix(left ast.Expression) ast.Expression {
 func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 	tok := p.curToken
 	args := p.parseArguments()
-	return p.parsePostfixOps(&ast.CallExpression{Token: tok, Callee: left, Arguments: args})
+	call := &ast.CallExpression{Token: tok, Callee: left, Arguments: args}
+	p.finish(call, left.Span().Start)
+	return p.parsePostfixOps(call)
 }
 
 func (p *Parser) parseArguments() []ast.Expression {
@@ -1706,8 +2543,9 @@ func (p *Parser) parseArguments() []ast.Expression {
 func (p *Parser) parseDotMember(left ast.Expression) ast.Expression {
 	tok := p.curToken
 	p.nextToken() // consume .
-	prop := p.parsePropertyName()
+	prop := p.parseMemberName()
 	result := &ast.MemberExpression{Token: tok, Object: left, Property: prop}
+	p.finish(result, left.Span().Start)
 	return p.parsePostfixOps(result)
 }
 
@@ -1717,31 +2555,19 @@ func (p *Parser) parseBracketMember(left ast.Expression) ast.Expression {
 	prop := p.parseExpression(precComma)
 	p.expect(token.RightBracket)
 	result := &ast.MemberExpression{Token: tok, Object: left, Property: prop, Computed: true}
+	p.finish(result, left.Span().Start)
 	return p.parsePostfixOps(result)
 }
 
+// parseOptionalChain parses a chain starting at ?. after left. The chain
+// handling, including the ChainExpression wrapper, lives in parsePostfixOps.
 func (p *Parser) parseOptionalChain(left ast.Expression) ast.Expression {
-	tok := p.curToken
-	p.nextToken() // consume ?.
-
-	if p.curTokenIs(token.LeftParen) {
-		args := p.parseArguments()
-		return p.parsePostfixOps(&ast.CallExpression{Token: tok, Callee: left, Arguments: args})
-	}
-
-	if p.curTokenIs(token.LeftBracket) {
-		p.nextToken()
-		prop := p.parseExpression(precComma)
-		p.expect(token.RightBracket)
-		return p.parsePostfixOps(&ast.MemberExpression{Token: tok, Object: left, Property: prop, Computed: true})
-	}
-
-	prop := p.parsePropertyName()
-	return p.parsePostfixOps(&ast.MemberExpression{Token: tok, Object: left, Property: prop})
+	return p.parsePostfixOps(left)
 }
 
 func (p *Parser) parsePostfixUpdate(left ast.Expression) ast.Expression {
 	tok := p.curToken
+	p.checkSimpleTarget(left, "postfix operation")
 	p.nextToken()
 	return &ast.UpdateExpression{Token: tok, Operator: tok.Literal, Operand: left, Prefix: false}
 }
@@ -1754,16 +2580,24 @@ func (p *Parser) parseTaggedTemplate(tag ast.Expression) ast.Expression {
 	} else {
 		quasi = p.parseTemplateLiteral()
 	}
-	return &ast.TaggedTemplateExpression{Token: tok, Tag: tag, Quasi: quasi}
+	expr := &ast.TaggedTemplateExpression{Token: tok, Tag: tag, Quasi: quasi}
+	p.finish(expr, tag.Span().Start)
+	return expr
 }
 
+// parsePostfixOps parses the member accesses, calls and tagged templates
+// following expr. If any of them is an optional link, the resulting chain is
+// wrapped in a ChainExpression.
 func (p *Parser) parsePostfixOps(expr ast.Expression) ast.Expression {
+	start := expr.Span().Start
+	var chain *ast.ChainExpression
 	for {
+		p.finish(expr, start)
 		switch p.curToken.Type {
 		case token.Dot:
 			tok := p.curToken
 			p.nextToken()
-			prop := p.parsePropertyName()
+			prop := p.parseMemberName()
 			expr = &ast.MemberExpression{Token: tok, Object: expr, Property: prop}
 		case token.LeftBracket:
 			tok := p.curToken
@@ -1777,151 +2611,188 @@ func (p *Parser) parsePostfixOps(expr ast.Expression) ast.Expression {
 			expr = &ast.CallExpression{Token: tok, Callee: expr, Arguments: args}
 		case token.OptionalChain:
 			tok := p.curToken
+			if chain == nil {
+				chain = &ast.ChainExpression{Token: tok}
+			}
 			p.nextToken()
 			if p.curTokenIs(token.LeftParen) {
 				args := p.parseArguments()
-				expr = &ast.CallExpression{Token: tok, Callee: expr, Arguments: args}
+				expr = &ast.CallExpression{Token: tok, Callee: expr, Arguments: args, Optional: true}
 			} else if p.curTokenIs(token.LeftBracket) {
 				p.nextToken()
 				prop := p.parseExpression(precComma)
 				p.expect(token.RightBracket)
-				expr = &ast.MemberExpression{Token: tok, Object: expr, Property: prop, Computed: true}
+				expr = &ast.MemberExpression{Token: tok, Object: expr, Property: prop, Computed: true, Optional: true}
 			} else {
-				prop := p.parsePropertyName()
-				expr = &ast.MemberExpression{Token: tok, Object: expr, Property: prop}
+				prop := p.parseMemberName()
+				expr = &ast.MemberExpression{Token: tok, Object: expr, Property: prop, Optional: true}
 			}
 		case token.TemplateHead, token.NoSubstitutionTemplate:
+			if chain != nil {
+				p.addError("invalid tagged template on optional chain")
+			}
 			expr = p.parseTaggedTemplate(expr)
 		default:
-			return expr
+			if chain == nil {
+				return expr
+			}
+			chain.Expression = expr
+			p.finish(chain, start)
+			return chain
 		}
 	}
 }
 
 // ---------- Helpers ----------
 
+// consumeSemicolon ends a statement. A missing semicolon is inserted before
+// a }, at the end of the input and before a token on a new line, as
+// automatic semicolon insertion does; anywhere else it is an error.
 func (p *Parser) consumeSemicolon() {
 	if p.curTokenIs(token.Semicolon) {
 		p.nextToken()
+		return
 	}
+	if p.curTokenIs(token.RightBrace) || p.curTokenIs(token.EOF) || p.prevTokenWasNewline() {
+		return
+	}
+	p.addError("unexpected token %s (%q); missing semicolon", tokenName(p.curToken.Type), p.curToken.Literal)
 }
 
+// checkArrowLine reports a line terminator before the current => token,
+// which the grammar of arrow functions does not allow.
+func (p *Parser) checkArrowLine() {
+	if p.prevTokenWasNewline() {
+		p.addError("unexpected line terminator before =>")
+	}
+}
+
+// prevTokenWasNewline reports whether a line terminator separates the
+// current token from the previous one. A multi-line comment or template
+// literal in between counts as one.
 func (p *Parser) prevTokenWasNewline() bool {
-	return p.curToken.Line > p.prevLine
+	return p.curToken.Line > p.prevEnd.Line
+}
+
+// peekTokenOnNewline reports whether a line terminator separates the next
+// token from the current one.
+func (p *Parser) peekTokenOnNewline() bool {
+	return p.peekToken.Line > p.curToken.EndLine
 }
 
 func tokenName(t token.TokenType) string {
 	names := map[token.TokenType]string{
 		token.EOF:                      "EOF",
 		token.Illegal:                  "ILLEGAL",
-		token.Identifier:              "IDENTIFIER",
-		token.Number:                  "NUMBER",
-		token.String:                  "STRING",
-		token.Plus:                    "+",
-		token.Minus:                   "-",
-		token.Asterisk:                "*",
-		token.Slash:                   "/",
-		token.Percent:                 "%",
-		token.Exponent:                "**",
-		token.Assign:                  "=",
-		token.PlusAssign:              "+=",
-		token.MinusAssign:             "-=",
-		token.AsteriskAssign:          "*=",
-		token.SlashAssign:             "/=",
-		token.PercentAssign:           "%=",
-		token.ExponentAssign:          "**=",
-		token.AmpersandAssign:         "&=",
-		token.PipeAssign:              "|=",
-		token.CaretAssign:             "^=",
-		token.LeftShiftAssign:         "<<=",
-		token.RightShiftAssign:        ">>=",
+		token.Identifier:               "IDENTIFIER",
+		token.Number:                   "NUMBER",
+		token.String:                   "STRING",
+		token.PrivateName:              "PRIVATE NAME",
+		token.Plus:                     "+",
+		token.Minus:                    "-",
+		token.Asterisk:                 "*",
+		token.Slash:                    "/",
+		token.Percent:                  "%",
+		token.Exponent:                 "**",
+		token.Assign:                   "=",
+		token.PlusAssign:               "+=",
+		token.MinusAssign:              "-=",
+		token.AsteriskAssign:           "*=",
+		token.SlashAssign:              "/=",
+		token.PercentAssign:            "%=",
+		token.ExponentAssign:           "**=",
+		token.AmpersandAssign:          "&=",
+		token.PipeAssign:               "|=",
+		token.CaretAssign:              "^=",
+		token.LeftShiftAssign:          "<<=",
+		token.RightShiftAssign:         ">>=",
 		token.UnsignedRightShiftAssign: ">>>=",
-		token.NullishAssign:           "??=",
-		token.AndAssign:               "&&=",
-		token.OrAssign:                "||=",
-		token.Equal:                   "==",
-		token.NotEqual:                "!=",
-		token.StrictEqual:             "===",
-		token.StrictNotEqual:          "!==",
-		token.LessThan:                "<",
-		token.GreaterThan:             ">",
-		token.LessThanOrEqual:         "<=",
-		token.GreaterThanOrEqual:      ">=",
-		token.And:                     "&&",
-		token.Or:                      "||",
-		token.Not:                     "!",
-		token.BitwiseAnd:              "&",
-		token.BitwiseOr:               "|",
-		token.BitwiseXor:              "^",
-		token.BitwiseNot:              "~",
-		token.LeftShift:               "<<",
-		token.RightShift:              ">>",
-		token.UnsignedRightShift:      ">>>",
-		token.Increment:               "++",
-		token.Decrement:               "--",
-		token.LeftParen:               "(",
-		token.RightParen:              ")",
-		token.LeftBrace:               "{",
-		token.RightBrace:              "}",
-		token.LeftBracket:             "[",
-		token.RightBracket:            "]",
-		token.Semicolon:               ";",
-		token.Colon:                   ":",
-		token.Comma:                   ",",
-		token.Dot:                     ".",
-		token.Spread:                  "...",
-		token.Arrow:                   "=>",
-		token.QuestionMark:            "?",
-		token.OptionalChain:           "?.",
-		token.NullishCoalesce:         "??",
-		token.Var:                     "var",
-		token.Let:                     "let",
-		token.Const:                   "const",
-		token.Function:                "function",
-		token.Return:                  "return",
-		token.If:                      "if",
-		token.Else:                    "else",
-		token.While:                   "while",
-		token.For:                     "for",
-		token.Do:                      "do",
-		token.Break:                   "break",
-		token.Continue:                "continue",
-		token.Switch:                  "switch",
-		token.Case:                    "case",
-		token.Default:                 "default",
-		token.Throw:                   "throw",
-		token.Try:                     "try",
-		token.Catch:                   "catch",
-		token.Finally:                 "finally",
-		token.New:                     "new",
-		token.Delete:                  "delete",
-		token.Typeof:                  "typeof",
-		token.Void:                    "void",
-		token.In:                      "in",
-		token.Instanceof:              "instanceof",
-		token.This:                    "this",
-		token.Class:                   "class",
-		token.Extends:                 "extends",
-		token.Super:                   "super",
-		token.Import:                  "import",
+		token.NullishAssign:            "??=",
+		token.AndAssign:                "&&=",
+		token.OrAssign:                 "||=",
+		token.Equal:                    "==",
+		token.NotEqual:                 "!=",
+		token.StrictEqual:              "===",
+		token.StrictNotEqual:           "!==",
+		token.LessThan:                 "<",
+		token.GreaterThan:              ">",
+		token.LessThanOrEqual:          "<=",
+		token.GreaterThanOrEqual:       ">=",
+		token.And:                      "&&",
+		token.Or:                       "||",
+		token.Not:                      "!",
+		token.BitwiseAnd:               "&",
+		token.BitwiseOr:                "|",
+		token.BitwiseXor:               "^",
+		token.BitwiseNot:               "~",
+		token.LeftShift:                "<<",
+		token.RightShift:               ">>",
+		token.UnsignedRightShift:       ">>>",
+		token.Increment:                "++",
+		token.Decrement:                "--",
+		token.LeftParen:                "(",
+		token.RightParen:               ")",
+		token.LeftBrace:                "{",
+		token.RightBrace:               "}",
+		token.LeftBracket:              "[",
+		token.RightBracket:             "]",
+		token.Semicolon:                ";",
+		token.Colon:                    ":",
+		token.Comma:                    ",",
+		token.Dot:                      ".",
+		token.Spread:                   "...",
+		token.Arrow:                    "=>",
+		token.QuestionMark:             "?",
+		token.OptionalChain:            "?.",
+		token.NullishCoalesce:          "??",
+		token.Var:                      "var",
+		token.Let:                      "let",
+		token.Const:                    "const",
+		token.Function:                 "function",
+		token.Return:                   "return",
+		token.If:                       "if",
+		token.Else:                     "else",
+		token.While:                    "while",
+		token.For:                      "for",
+		token.Do:                       "do",
+		token.Break:                    "break",
+		token.Continue:                 "continue",
+		token.Switch:                   "switch",
+		token.Case:                     "case",
+		token.Default:                  "default",
+		token.Throw:                    "throw",
+		token.Try:                      "try",
+		token.Catch:                    "catch",
+		token.Finally:                  "finally",
+		token.New:                      "new",
+		token.Delete:                   "delete",
+		token.Typeof:                   "typeof",
+		token.Void:                     "void",
+		token.In:                       "in",
+		token.Instanceof:               "instanceof",
+		token.This:                     "this",
+		token.Class:                    "class",
+		token.Extends:                  "extends",
+		token.Super:                    "super",
+		token.Import:                   "import",
 		token.Export:                   "export",
-		token.From:                    "from",
-		token.As:                      "as",
-		token.Of:                      "of",
-		token.Yield:                   "yield",
-		token.Async:                   "async",
-		token.Await:                   "await",
-		token.True:                    "true",
-		token.False:                   "false",
-		token.Null:                    "null",
-		token.Undefined:               "undefined",
-		token.Debugger:                "debugger",
-		token.With:                    "with",
-		token.TemplateHead:            "TEMPLATE_HEAD",
-		token.TemplateMiddle:          "TEMPLATE_MIDDLE",
-		token.TemplateTail:            "TEMPLATE_TAIL",
-		token.NoSubstitutionTemplate:  "TEMPLATE",
-		token.RegExp:                  "REGEXP",
+		token.From:                     "from",
+		token.As:                       "as",
+		token.Of:                       "of",
+		token.Yield:                    "yield",
+		token.Async:                    "async",
+		token.Await:                    "await",
+		token.True:                     "true",
+		token.False:                    "false",
+		token.Null:                     "null",
+		token.Undefined:                "undefined",
+		token.Debugger:                 "debugger",
+		token.With:                     "with",
+		token.TemplateHead:             "TEMPLATE_HEAD",
+		token.TemplateMiddle:           "TEMPLATE_MIDDLE",
+		token.TemplateTail:             "TEMPLATE_TAIL",
+		token.NoSubstitutionTemplate:   "TEMPLATE",
+		token.RegExp:                   "REGEXP",
 	}
 	if name, ok := names[t]; ok {
 		return name
@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
-	"github.com/example/jsgo/ast"
+	"github.com/example/jsgo/internal/ast"
 )
 
 func parse(t *testing.T, input string) *ast.Program {
@@ -525,6 +529,25 @@ func TestNewWithoutArgs(t *testing.T) {
 	}
 }
 
+func TestNewTarget(t *testing.T) {
+	prog := parse(t, `new.target.name;`)
+	stmt := prog.Statements[0].(*ast.ExpressionStatement)
+	me, ok := stmt.Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("expected MemberExpression, got %T", stmt.Expression)
+	}
+	mp, ok := me.Object.(*ast.MetaProperty)
+	if !ok {
+		t.Fatalf("expected MetaProperty, got %T", me.Object)
+	}
+	if mp.Meta != "new" || mp.Property != "target" {
+		t.Errorf("expected new.target, got %s.%s", mp.Meta, mp.Property)
+	}
+	if _, errs := New(`new.foo;`).ParseProgram(); len(errs) == 0 {
+		t.Error("expected an error for new.foo")
+	}
+}
+
 // ---------- If Statement ----------
 
 func TestIfStatement(t *testing.T) {
@@ -634,6 +657,21 @@ func TestForOfWithDestructuring(t *testing.T) {
 	}
 }
 
+func TestForAwaitStatement(t *testing.T) {
+	prog := parse(t, `async function f() { for await (const x of xs) {} }`)
+	fn := prog.Statements[0].(*ast.FunctionDeclaration)
+	stmt, ok := fn.Body.Statements[0].(*ast.ForOfStatement)
+	if !ok {
+		t.Fatalf("expected ForOfStatement, got %T", fn.Body.Statements[0])
+	}
+	if !stmt.Await {
+		t.Error("expected await")
+	}
+	if _, errs := New(`for await (k in obj) {}`).ParseProgram(); len(errs) == 0 {
+		t.Error("expected an error for for await ... in")
+	}
+}
+
 // ---------- Break / Continue ----------
 
 func TestBreakStatement(t *testing.T) {
@@ -751,6 +789,67 @@ func TestReturnVoid(t *testing.T) {
 	}
 }
 
+// ---------- Automatic Semicolon Insertion ----------
+
+func TestASI(t *testing.T) {
+	prog := parse(t, "a = 1\nb = 2\n{ c }\nlet d = [1]\n[0]")
+	expectStmtCount(t, prog, 4)
+	// A line starting with [ continues the previous statement.
+	decl := prog.Statements[3].(*ast.VariableDeclaration)
+	if _, ok := decl.Declarations[0].Value.(*ast.MemberExpression); !ok {
+		t.Errorf("expected [1][0] to be a member expression, got %T", decl.Declarations[0].Value)
+	}
+
+	prog = parse(t, "function f() { return\n42 }")
+	body := prog.Statements[0].(*ast.FunctionDeclaration).Body
+	if len(body.Statements) != 2 || body.Statements[0].(*ast.ReturnStatement).Value != nil {
+		t.Errorf("return followed by a newline should return undefined")
+	}
+
+	prog = parse(t, "a\n++b")
+	expectStmtCount(t, prog, 2)
+	update := prog.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.UpdateExpression)
+	if !update.Prefix || update.Operand.(*ast.Identifier).Value != "b" {
+		t.Errorf("++ on a new line should apply to b as a prefix")
+	}
+
+	prog = parse(t, "outer: for (;;) { break\nouter }")
+	loop := prog.Statements[0].(*ast.LabeledStatement).Body.(*ast.ForStatement)
+	if brk := loop.Body.(*ast.BlockStatement).Statements[0].(*ast.BreakStatement); brk.Label != nil {
+		t.Errorf("break followed by a newline should not take a label")
+	}
+
+	prog = parse(t, "function* g() { yield\n1 }")
+	yield := prog.Statements[0].(*ast.FunctionDeclaration).Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.YieldExpression)
+	if yield.Argument != nil {
+		t.Errorf("yield followed by a newline should have no argument")
+	}
+
+	prog = parse(t, "async\nfunction h() {}")
+	expectStmtCount(t, prog, 2)
+
+	// A semicolon is inserted after do-while and before }, even on one line.
+	parse(t, "do x(); while (y) z()")
+	parse(t, "if (a) { b }")
+
+	// The line break inside the template literal does not separate it from b.
+	for _, src := range []string{"a = 1 b = 2", "a = `x\n` b", "throw\nnew Error()", "if (a) b else c"} {
+		if _, errs := parseWithErrors(src); len(errs) == 0 {
+			t.Errorf("%q: expected a syntax error", src)
+		}
+	}
+
+	// No line terminator may come before the => of an arrow function,
+	// though one may follow it.
+	parse(t, "f = a =>\n1")
+	parse(t, "f = (a, b) =>\n{}")
+	for _, src := range []string{"a\n=> 1", "(a)\n=> 1", "()\n=> 1", "(a, b = 1)\n=> 1", "async a\n=> 1", "async (a)\n=> 1", "f = x\n=> x"} {
+		if _, errs := parseWithErrors(src); len(errs) == 0 {
+			t.Errorf("%q: expected a syntax error", src)
+		}
+	}
+}
+
 // ---------- Function Declaration ----------
 
 func TestFunctionDeclaration(t *testing.T) {
@@ -795,6 +894,44 @@ func TestAsyncGeneratorFunction(t *testing.T) {
 	}
 }
 
+func TestStrictFunctions(t *testing.T) {
+	tests := []struct {
+		src    string
+		strict bool
+	}{
+		{`function f() {}`, false},
+		{`function f() { "use strict"; }`, true},
+		{`function f() { 'use strict'; return 1; }`, true},
+		{`function f() { "a"; "use strict"; }`, true},
+		{`function f() { f(); "use strict"; }`, false},
+		{`function f() { "use\x20strict"; }`, false},
+		{`function f() { "use strict".length; }`, false},
+		{`"use strict"; function f() {}`, true},
+		{`function g() { "use strict"; } function f() {}`, false},
+	}
+	for _, tt := range tests {
+		prog := parse(t, tt.src)
+		fn := prog.Statements[len(prog.Statements)-1].(*ast.FunctionDeclaration)
+		if fn.Strict != tt.strict {
+			t.Errorf("%s: expected strict %v, got %v", tt.src, tt.strict, fn.Strict)
+		}
+	}
+
+	prog := parse(t, `class C { m() {} }`)
+	method := prog.Statements[0].(*ast.ClassDeclaration).Body.Methods[0]
+	if !method.Value.Strict {
+		t.Error("expected a class method to be strict")
+	}
+
+	prog, errs := New(`function f() {}`).ParseModule()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if !prog.Statements[0].(*ast.FunctionDeclaration).Strict {
+		t.Error("expected a function in a module to be strict")
+	}
+}
+
 func TestFunctionDefaultParams(t *testing.T) {
 	prog := parse(t, `function foo(a, b = 1, c = 2) {}`)
 	fn := prog.Statements[0].(*ast.FunctionDeclaration)
@@ -899,6 +1036,53 @@ func TestClassComputedMethod(t *testing.T) {
 	}
 }
 
+func TestClassFields(t *testing.T) {
+	input := `class Foo {
+		x = 1;
+		static count
+		#secret = "s"
+		get = 2;
+		[key] = 3;
+		#peek() { return this.#secret; }
+		has(o) { return #secret in o; }
+	}`
+	prog := parse(t, input)
+	cls := prog.Statements[0].(*ast.ClassDeclaration)
+	if len(cls.Body.Fields) != 5 || len(cls.Body.Methods) != 2 {
+		t.Fatalf("expected 5 fields and 2 methods, got %d and %d", len(cls.Body.Fields), len(cls.Body.Methods))
+	}
+	fields := cls.Body.Fields
+	if fields[0].Key.(*ast.Identifier).Value != "x" || fields[0].Value == nil {
+		t.Error("expected field x with an initializer")
+	}
+	if !fields[1].Static || fields[1].Value != nil {
+		t.Error("expected static field count without an initializer")
+	}
+	if key, ok := fields[2].Key.(*ast.PrivateIdentifier); !ok || key.Name != "#secret" {
+		t.Errorf("expected private field #secret, got %T", fields[2].Key)
+	}
+	if fields[3].Key.(*ast.Identifier).Value != "get" {
+		t.Error("expected a field named get")
+	}
+	if !fields[4].Computed {
+		t.Error("expected computed field")
+	}
+	if _, ok := cls.Body.Methods[0].Key.(*ast.PrivateIdentifier); !ok {
+		t.Errorf("expected private method, got %T", cls.Body.Methods[0].Key)
+	}
+
+	for _, src := range []string{
+		`class A { x = 1 y = 2 }`,
+		`class A { constructor = 1 }`,
+		`class A { #x; m() { delete this.#x; } }`,
+		`class A { #x; m() { return #x; } }`,
+	} {
+		if _, errs := New(src).ParseProgram(); len(errs) == 0 {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+}
+
 // ---------- Arrow Functions ----------
 
 func TestArrowFunctionExpression(t *testing.T) {
@@ -1157,8 +1341,8 @@ func TestSuperExpression(t *testing.T) {
 // ---------- Yield ----------
 
 func TestYieldExpression(t *testing.T) {
-	prog := parse(t, `yield 1;`)
-	stmt := prog.Statements[0].(*ast.ExpressionStatement)
+	prog := parse(t, `function* g() { yield 1; }`)
+	stmt := prog.Statements[0].(*ast.FunctionDeclaration).Body.Statements[0].(*ast.ExpressionStatement)
 	yld, ok := stmt.Expression.(*ast.YieldExpression)
 	if !ok {
 		t.Fatalf("expected YieldExpression, got %T", stmt.Expression)
@@ -1169,19 +1353,56 @@ func TestYieldExpression(t *testing.T) {
 }
 
 func TestYieldDelegateExpression(t *testing.T) {
-	prog := parse(t, `yield* gen();`)
-	stmt := prog.Statements[0].(*ast.ExpressionStatement)
+	prog := parse(t, `function* g() { yield* gen(); }`)
+	stmt := prog.Statements[0].(*ast.FunctionDeclaration).Body.Statements[0].(*ast.ExpressionStatement)
 	yld := stmt.Expression.(*ast.YieldExpression)
 	if !yld.Delegate {
 		t.Error("expected delegate")
 	}
 }
 
+func TestYieldIdentifier(t *testing.T) {
+	// Outside generators and strict code, yield is an ordinary name, also
+	// in a function nested in a generator and in an arrow function.
+	for _, src := range []string{
+		`var yield = 2; yield;`,
+		`function f(yield) { return yield + 1; }`,
+		`var o = { yield }; o.yield;`,
+		`function* g() { function h() { var yield = 1; return yield; } }`,
+		`function* g() { var f = () => yield; }`,
+		`function* yield() {}`,
+	} {
+		parse(t, src)
+	}
+	prog := parse(t, `var yield = 2; yield * 3;`)
+	if _, ok := prog.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.BinaryExpression); !ok {
+		t.Errorf("expected yield * 3 to be a multiplication, got %T", prog.Statements[1].(*ast.ExpressionStatement).Expression)
+	}
+
+	for _, src := range []string{
+		`function* g() { var yield = 1; }`,
+		`function* g(yield) {}`,
+		`function* g() { var o = { yield }; }`,
+		`function* g() { function yield() {} }`,
+		`var g = function* yield() {};`,
+		`function* g() { var yi\u0065ld; }`,
+		`"use strict"; var yield;`,
+		`"use strict"; yield;`,
+		`function f() { "use strict"; var yi\u0065ld; }`,
+		`class C { m() { var yield; } }`,
+	} {
+		if _, errs := parseWithErrors(src); len(errs) == 0 {
+			t.Errorf("%q: expected a syntax error", src)
+		}
+	}
+}
+
 // ---------- Await ----------
 
 func TestAwaitExpression(t *testing.T) {
-	prog := parse(t, `await promise;`)
-	stmt := prog.Statements[0].(*ast.ExpressionStatement)
+	prog := parse(t, `async function f() { await promise; }`)
+	fn := prog.Statements[0].(*ast.FunctionDeclaration)
+	stmt := fn.Body.Statements[0].(*ast.ExpressionStatement)
 	aw, ok := stmt.Expression.(*ast.AwaitExpression)
 	if !ok {
 		t.Fatalf("expected AwaitExpression, got %T", stmt.Expression)
@@ -1192,6 +1413,82 @@ func TestAwaitExpression(t *testing.T) {
 	}
 }
 
+func TestLetAndAwaitNames(t *testing.T) {
+	// In sloppy scripts let and await are identifiers wherever they may be
+	// bound, and await is an operator only in async functions.
+	for _, src := range []string{
+		`var let = 1; console.log(let); let = 2; let++;`,
+		`var await = 3; console.log(await); ({await});`,
+		`function await() {} async function f() {} var g = async function () {};`,
+		`async function f() { function g() { var await; } }`,
+		`async function f() { return await 1; } var g = async x => await x; var h = async (x) => { await x; };`,
+		`var o = { async m() { await 1; } }; class C { async *m() { await 1; } }`,
+		"let\nx = 1;",
+	} {
+		if _, errs := parseWithErrors(src); len(errs) > 0 {
+			t.Errorf("%q: unexpected errors %v", src, errs)
+		}
+	}
+	prog := parse(t, `await(1);`)
+	if _, ok := prog.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression); !ok {
+		t.Errorf("expected await to be called as a function outside async functions")
+	}
+	for _, src := range []string{
+		`async function f() { var await = 1; }`,
+		`async function f() { let [await] = []; }`,
+		`async function f() { function await() {} }`,
+		`async function f() { ({await}); }`,
+		`async function f() { aw\u0061it: ; }`,
+		`(async function await() {});`,
+		`async () => { var await; };`,
+		`var o = { async m() { var await; } };`,
+		`function f() { await 1; }`,
+		`"use strict"; let;`,
+		`"use strict"; console.log(let);`,
+	} {
+		if _, errs := parseWithErrors(src); len(errs) == 0 {
+			t.Errorf("%q: expected a syntax error", src)
+		}
+	}
+	if _, errs := New(`var await;`).ParseModule(); len(errs) == 0 {
+		t.Errorf("expected await to be reserved in a module")
+	}
+}
+
+func TestLetAsLexicalName(t *testing.T) {
+	// let may name a var but never a let or const binding.
+	for _, src := range []string{
+		`let let = 1;`,
+		`const let = 1;`,
+		`let [a, let] = [];`,
+		`const {x: let} = {};`,
+		`let {let} = {};`,
+		`let {...let} = {};`,
+		`let [b = 1, ...let] = [];`,
+		`for (let let of []) ;`,
+		`for (const let in {}) ;`,
+		`for (let a = 1, let = 2; ;) ;`,
+	} {
+		_, errs := parseWithErrors(src)
+		if len(errs) == 0 {
+			t.Errorf("%q: expected a syntax error", src)
+			continue
+		}
+		if msg := errs[0].(*SyntaxError).Message; msg != "let is disallowed as a lexically bound name" {
+			t.Errorf("%q: unexpected error %q", src, msg)
+		}
+	}
+	for _, src := range []string{
+		`var let = 1;`,
+		`for (var let in {}) ;`,
+		`let x = { let: 1 }, {let: y} = x;`,
+	} {
+		if _, errs := parseWithErrors(src); len(errs) > 0 {
+			t.Errorf("%q: unexpected errors %v", src, errs)
+		}
+	}
+}
+
 // ---------- Labeled Statement ----------
 
 func TestLabeledStatement(t *testing.T) {
@@ -1281,6 +1578,25 @@ func TestGeneratorExpression(t *testing.T) {
 
 // ---------- Class Expression ----------
 
+func TestClassStaticBlock(t *testing.T) {
+	prog := parse(t, `class A { static x = 1; static { var y = A.x; } static; static() {} }`)
+	cls := prog.Statements[0].(*ast.ClassDeclaration)
+	fields := cls.Body.Fields
+	if len(fields) != 3 || len(cls.Body.Methods) != 1 {
+		t.Fatalf("expected 3 fields and 1 method, got %d and %d", len(fields), len(cls.Body.Methods))
+	}
+	block := fields[1]
+	if !block.Static || block.Key != nil || block.Block == nil || len(block.Block.Statements) != 1 {
+		t.Errorf("expected a static block with one statement, got %+v", block)
+	}
+	if fields[2].Key.(*ast.Identifier).Value != "static" || fields[2].Static {
+		t.Error("expected a field named static")
+	}
+	if got := fields[1].Span(); got.Start.Column != 25 || got.End.Column != 48 {
+		t.Errorf("static block span: got %v", got)
+	}
+}
+
 func TestClassExpression(t *testing.T) {
 	prog := parse(t, `const C = class { constructor() {} };`)
 	decl := prog.Statements[0].(*ast.VariableDeclaration)
@@ -1304,6 +1620,124 @@ func TestNamedClassExpression(t *testing.T) {
 
 // ---------- Sequence Expression ----------
 
+func TestParenthesizedExpression(t *testing.T) {
+	prog := parse(t, `(x = 1);`)
+	stmt := prog.Statements[0].(*ast.ExpressionStatement)
+	if _, ok := stmt.Expression.(*ast.AssignmentExpression); !ok {
+		t.Fatalf("expected AssignmentExpression, got %T", stmt.Expression)
+	}
+
+	prog = parse(t, `(a, b = 2);`)
+	stmt = prog.Statements[0].(*ast.ExpressionStatement)
+	seq, ok := stmt.Expression.(*ast.SequenceExpression)
+	if !ok {
+		t.Fatalf("expected SequenceExpression, got %T", stmt.Expression)
+	}
+	if _, ok := seq.Expressions[1].(*ast.AssignmentExpression); !ok {
+		t.Errorf("expected AssignmentExpression, got %T", seq.Expressions[1])
+	}
+}
+
+func TestInvalidAssignmentTargets(t *testing.T) {
+	valid := []string{`(a) = 1;`, `((a)) += 2;`, `(a.b) = 1;`, `[(a)] = x;`, `({ a: (b) } = x);`, `(a)++;`}
+	for _, input := range valid {
+		if _, errs := parseWithErrors(input); len(errs) > 0 {
+			t.Errorf("unexpected errors for %q: %v", input, errs)
+		}
+	}
+	invalid := []string{`(a, b) = 1;`, `({ a }) = x;`, `([a]) = x;`, `[a] += x;`, `1 = 2;`, `a + b = c;`, `1++;`, `--f();`, `(...a);`}
+	for _, input := range invalid {
+		if _, errs := parseWithErrors(input); len(errs) == 0 {
+			t.Errorf("expected parse error for %q", input)
+		}
+	}
+}
+
+func TestEscapedKeywords(t *testing.T) {
+	valid := []string{
+		`var \u0061b = 1; ab;`,
+		`var l\u0065t = 1;`,
+		`var \u0061sync = 1;`,
+		`o.\u0069f; o = { \u0069f: 1, cl\u0061ss() {} };`,
+		`var o = { g\u0065t: 1, s\u0065t };`,
+	}
+	for _, input := range valid {
+		if _, errs := parseWithErrors(input); len(errs) > 0 {
+			t.Errorf("unexpected errors for %q: %v", input, errs)
+		}
+	}
+	invalid := []string{
+		`var \u0069f = 1;`,
+		`\u0069f (x) {}`,
+		`var cl\u0061ss;`,
+		`n\u0075ll;`,
+		`x = n\u0065w Foo();`,
+		`({ t\u0068is });`,
+		`var { \u{69}f } = o;`,
+		`({ \u0069f });`,
+		`var \u0065num;`,
+		`label: br\u0065ak label;`,
+		`({ g\u0065t x() {} });`,
+		`class C { st\u0061tic m() {} }`,
+	}
+	for _, input := range invalid {
+		if _, errs := parseWithErrors(input); len(errs) == 0 {
+			t.Errorf("expected parse error for %q", input)
+		}
+	}
+
+	// Modules are strict code, which reserves more names.
+	for _, input := range []string{`var l\u0065t;`, `var static;`, `var implements;`, `var aw\u0061it;`} {
+		if _, errs := New(input).ParseModule(); len(errs) == 0 {
+			t.Errorf("expected parse error for %q in a module", input)
+		}
+	}
+}
+
+func TestReservedBindingNames(t *testing.T) {
+	for _, input := range []string{
+		`var if = 1;`,
+		`let if = 1;`,
+		`var enum = 1;`,
+		`enum = 1;`,
+		`function if() {}`,
+		`function enum() {}`,
+		`function f(if) {}`,
+		`function f(a, ...new) {}`,
+		`try {} catch (if) {}`,
+		`var [if] = [];`,
+		`var { a: if } = {};`,
+		`var { enum } = {};`,
+		`class if {}`,
+		`(class enum {});`,
+		`"use strict"; var let;`,
+		`function f() { "use strict"; var static; }`,
+		`class C { m() { var static; } }`,
+		`class let {}`,
+	} {
+		if _, errs := parseWithErrors(input); len(errs) == 0 {
+			t.Errorf("expected parse error for %q", input)
+		}
+	}
+	for _, input := range []string{`var let, static, of, async, from, as;`, `var undefined;`, `class C {} var D = class {};`} {
+		if _, errs := parseWithErrors(input); len(errs) > 0 {
+			t.Errorf("unexpected errors for %q: %v", input, errs)
+		}
+	}
+	// Only keywords are reported as reserved words.
+	for input, want := range map[string]string{
+		`var if = 1;`:         `unexpected reserved word "if"; expected identifier`,
+		`function class() {}`: `unexpected reserved word "class"; expected identifier`,
+		`let 5 = 1;`:          `expected identifier, got NUMBER`,
+		`var (x);`:            `expected identifier, got (`,
+	} {
+		_, errs := parseWithErrors(input)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), want) {
+			t.Errorf("%q: expected %q first, got %v", input, want, errs)
+		}
+	}
+}
+
 func TestSequenceExpression(t *testing.T) {
 	prog := parse(t, `a, b, c;`)
 	stmt := prog.Statements[0].(*ast.ExpressionStatement)
@@ -1412,6 +1846,52 @@ func TestParseErrors(t *testing.T) {
 	}
 }
 
+func TestParseErrorPosition(t *testing.T) {
+	_, errs := parseWithErrors("var a = 1;\nvar b = ;")
+	if len(errs) == 0 {
+		t.Fatal("expected parse errors")
+	}
+	var serr *SyntaxError
+	if !errors.As(errs[0], &serr) {
+		t.Fatalf("expected *SyntaxError, got %T", errs[0])
+	}
+	if serr.Line != 2 || serr.Column != 9 {
+		t.Errorf("expected position 2:9, got %d:%d", serr.Line, serr.Column)
+	}
+	if serr.Error() != "parse error at 2:9: "+serr.Message {
+		t.Errorf("unexpected error text %q", serr.Error())
+	}
+}
+
+func TestParseErrorUnexpectedEnd(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"function f() {", true},
+		{"var x =", true},
+		{"`abc", true},
+		{"`a ${b}\nc", true},
+		{"var 1 = 2;", false},
+		{"}", false},
+		{"'abc", false},
+	}
+	for _, tt := range tests {
+		_, errs := parseWithErrors(tt.src)
+		if len(errs) == 0 {
+			t.Errorf("%q: expected parse errors", tt.src)
+			continue
+		}
+		got := false
+		for _, err := range errs {
+			got = got || errors.Is(err, ErrUnexpectedEnd)
+		}
+		if got != tt.want {
+			t.Errorf("%q: errors.Is(err, ErrUnexpectedEnd) = %v, want %v (%v)", tt.src, got, tt.want, errs)
+		}
+	}
+}
+
 // ---------- Complex Programs ----------
 
 func TestComplexProgram(t *testing.T) {
@@ -1532,7 +2012,10 @@ func TestForEmptyParts(t *testing.T) {
 func TestNullishCoalescing(t *testing.T) {
 	prog := parse(t, `a ?? b;`)
 	stmt := prog.Statements[0].(*ast.ExpressionStatement)
-	bin := stmt.Expression.(*ast.BinaryExpression)
+	bin, ok := stmt.Expression.(*ast.LogicalExpression)
+	if !ok {
+		t.Fatalf("expected LogicalExpression, got %T", stmt.Expression)
+	}
 	if bin.Operator != "??" {
 		t.Errorf("expected ??, got %s", bin.Operator)
 	}
@@ -1541,9 +2024,16 @@ func TestNullishCoalescing(t *testing.T) {
 func TestOptionalChaining(t *testing.T) {
 	prog := parse(t, `a?.b;`)
 	stmt := prog.Statements[0].(*ast.ExpressionStatement)
-	mem, ok := stmt.Expression.(*ast.MemberExpression)
+	chain, ok := stmt.Expression.(*ast.ChainExpression)
 	if !ok {
-		t.Fatalf("expected MemberExpression, got %T", stmt.Expression)
+		t.Fatalf("expected ChainExpression, got %T", stmt.Expression)
+	}
+	mem, ok := chain.Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("expected MemberExpression, got %T", chain.Expression)
+	}
+	if !mem.Optional {
+		t.Error("expected an optional member")
 	}
 	obj := mem.Object.(*ast.Identifier)
 	if obj.Value != "a" {
@@ -1551,6 +2041,48 @@ func TestOptionalChaining(t *testing.T) {
 	}
 }
 
+func TestOptionalChainShape(t *testing.T) {
+	// The whole chain after the first ?. sits under one ChainExpression.
+	prog := parse(t, `a.b?.c.d(x);`)
+	chain, ok := prog.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ChainExpression)
+	if !ok {
+		t.Fatalf("expected ChainExpression, got %T", prog.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	call := chain.Expression.(*ast.CallExpression)
+	if call.Optional {
+		t.Error("call should not be optional")
+	}
+	d := call.Callee.(*ast.MemberExpression)
+	c := d.Object.(*ast.MemberExpression)
+	b := c.Object.(*ast.MemberExpression)
+	if d.Optional || !c.Optional || b.Optional {
+		t.Errorf("optional links: got .d=%v ?.c=%v .b=%v", d.Optional, c.Optional, b.Optional)
+	}
+
+	// Parentheses end the chain.
+	prog = parse(t, `(a?.b).c;`)
+	mem, ok := prog.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("expected MemberExpression, got %T", prog.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	if _, ok := mem.Object.(*ast.ChainExpression); !ok {
+		t.Errorf("expected ChainExpression object, got %T", mem.Object)
+	}
+
+	prog = parse(t, `f?.(1)?.[k];`)
+	chain = prog.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ChainExpression)
+	index := chain.Expression.(*ast.MemberExpression)
+	if !index.Optional || !index.Computed || !index.Object.(*ast.CallExpression).Optional {
+		t.Error("expected optional computed member of an optional call")
+	}
+
+	for _, input := range []string{"a?.b = 1;", "a?.b++;", "a?.b`t`;", "[a?.b] = [1];"} {
+		if _, errs := parseWithErrors(input); len(errs) == 0 {
+			t.Errorf("%s: expected a syntax error", input)
+		}
+	}
+}
+
 func TestMultipleTemplateLiteralExpressions(t *testing.T) {
 	prog := parse(t, "`${a} ${b}`;")
 	stmt := prog.Statements[0].(*ast.ExpressionStatement)
@@ -1578,6 +2110,43 @@ func TestForInExpressionLeft(t *testing.T) {
 	}
 }
 
+func TestForOfPatternLeft(t *testing.T) {
+	prog := parse(t, `for ([a, b = 1, ...rest] of arr) {}`)
+	stmt := prog.Statements[0].(*ast.ForOfStatement)
+	pat, ok := stmt.Left.(*ast.ArrayPattern)
+	if !ok {
+		t.Fatalf("expected ArrayPattern, got %T", stmt.Left)
+	}
+	if len(pat.Elements) != 3 {
+		t.Fatalf("expected 3 elements, got %d", len(pat.Elements))
+	}
+	if _, ok := pat.Elements[1].(*ast.AssignmentPattern); !ok {
+		t.Errorf("expected AssignmentPattern, got %T", pat.Elements[1])
+	}
+	if _, ok := pat.Elements[2].(*ast.RestElement); !ok {
+		t.Errorf("expected RestElement, got %T", pat.Elements[2])
+	}
+
+	prog = parse(t, `for ({x: o.y, z} in obj) {}`)
+	in := prog.Statements[0].(*ast.ForInStatement)
+	obj, ok := in.Left.(*ast.ObjectPattern)
+	if !ok {
+		t.Fatalf("expected ObjectPattern, got %T", in.Left)
+	}
+	if _, ok := obj.Properties[0].Value.(*ast.MemberExpression); !ok {
+		t.Errorf("expected MemberExpression target, got %T", obj.Properties[0].Value)
+	}
+}
+
+func TestInvalidForOfLeft(t *testing.T) {
+	for _, input := range []string{`for (1 of arr) {}`, `for (f() in obj) {}`, `for ([a + b] of arr) {}`} {
+		_, errs := parseWithErrors(input)
+		if len(errs) == 0 {
+			t.Errorf("expected parse error for %q", input)
+		}
+	}
+}
+
 func TestObjectAsyncMethod(t *testing.T) {
 	prog := parse(t, `({ async foo() {} });`)
 	stmt := prog.Statements[0].(*ast.ExpressionStatement)
@@ -1611,3 +2180,291 @@ func TestClassGeneratorMethod(t *testing.T) {
 		t.Error("expected generator method")
 	}
 }
+
+// ---------- Source spans ----------
+
+// collectNodes walks every AST node reachable from n via exported fields.
+func collectNodes(n ast.Node, out *[]ast.Node) {
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if v.IsNil() {
+				return
+			}
+			if node, ok := v.Interface().(ast.Node); ok && v.Kind() == reflect.Ptr {
+				*out = append(*out, node)
+			}
+			walk(v.Elem())
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).IsExported() {
+					walk(v.Field(i))
+				}
+			}
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		}
+	}
+	walk(reflect.ValueOf(n))
+}
+
+func TestEveryNodeHasSpan(t *testing.T) {
+	src := `var a = 1, [b, , ...c] = d, {e, f: g = 2, ...h} = i;
+let j = (k) => k * 2; const l = async x => { await x; };
+function* m(n = 1, ...o) { yield n; yield* o; }
+async function p() {}
+class Q extends R { constructor() { super(); } static s() {} get t() { return 1; } set t(v) {} [u]() {} static { s; } }
+if (a) { b; } else if (c) d; else {}
+for (let i = 0; i < 10; i++) continue;
+for (var k in obj) break;
+for (const [x, y] of pairs) {}
+while (a) a--;
+do { a++; } while (a < 5);
+outer: for (;;) { break outer; }
+switch (a) { case 1: b; break; default: c; }
+try { throw new Error("x"); } catch ({message}) {} finally {}
+try {} catch {}
+with (obj) { prop; }
+debugger;
+;
+x = a ? b : c, y += 1;
+z = a && b || c ?? d;
+w = typeof a + -b + !c + ~d + void 0 + delete e.f;
+v = a.b.c[d](e, ...f)?.g;
+u = ` + "`" + `head ${a} mid ${b} tail` + "`" + `;
+s = tag` + "`" + `t${1}` + "`" + `;
+r = /ab+c/gi;
+q = {a, b: 1, [c]: 2, d() {}, get e() { return 1; }, set e(v) {}, ...f};
+o = [1, , 3, ...p];
+n = function named() { return this; };
+l = class {};
+h = 0x1F + 1e3 + "str" + true + null + undefined;
+class S { #x = 1; static y; #m() { return #x in this; } get #g() { return this.#x; } }
+async function* ag() { for await (const v of src) yield v; }
+g ??= a?.b?.[c]?.(d); g ||= 1; g &&= 2;
+label: for (const e of f) { continue label; }
+`
+	prog := parse(t, src)
+	var nodes []ast.Node
+	collectNodes(prog, &nodes)
+	for _, n := range nodes {
+		span := n.Span()
+		if !span.Start.IsValid() || !span.End.IsValid() {
+			t.Errorf("%T (%q) has no span", n, n.TokenLiteral())
+			continue
+		}
+		if span.End.Offset < span.Start.Offset {
+			t.Errorf("%T (%q) has inverted span %v-%v", n, n.TokenLiteral(), span.Start, span.End)
+		}
+	}
+}
+
+func TestSpanText(t *testing.T) {
+	src := "let x = (a + b) * c;\nfoo.bar(1, 2);"
+	prog := parse(t, src)
+	decl := prog.Statements[0].(*ast.VariableDeclaration)
+	if got := decl.Span().Text(src); got != "let x = (a + b) * c;" {
+		t.Errorf("declaration span = %q", got)
+	}
+	bin := decl.Declarations[0].Value.(*ast.BinaryExpression)
+	if got := bin.Span().Text(src); got != "(a + b) * c" {
+		t.Errorf("binary span = %q", got)
+	}
+	if got := bin.Left.Span().Text(src); got != "a + b" {
+		t.Errorf("grouped span = %q", got)
+	}
+	stmt := prog.Statements[1].(*ast.ExpressionStatement)
+	call := stmt.Expression.(*ast.CallExpression)
+	if got := call.Span().Text(src); got != "foo.bar(1, 2)" {
+		t.Errorf("call span = %q", got)
+	}
+	if got := call.Callee.Span().Text(src); got != "foo.bar" {
+		t.Errorf("callee span = %q", got)
+	}
+	start := call.Span().Start
+	if start.Line != 2 || start.Column != 1 || start.Offset != 21 {
+		t.Errorf("call start = %+v", start)
+	}
+	if prog.Span().Text(src) != src {
+		t.Errorf("program span does not cover the whole source")
+	}
+}
+
+// estreeBody converts prog to ESTree, drops the positions and returns the
+// JSON of its body.
+func estreeBody(t *testing.T, prog *ast.Program, src string) string {
+	t.Helper()
+	data, err := json.Marshal(ast.ESTree(prog, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree interface{}
+	json.Unmarshal(data, &tree)
+	var strip func(v interface{}) interface{}
+	strip = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for _, k := range []string{"start", "end", "range", "loc"} {
+				delete(v, k)
+			}
+			for k, child := range v {
+				v[k] = strip(child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				v[i] = strip(child)
+			}
+		}
+		return v
+	}
+	body, _ := json.Marshal(strip(tree).(map[string]interface{})["body"])
+	return string(body)
+}
+
+func TestESTree(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{
+			"const {a, ...r} = e;",
+			`[{"declarations":[{"id":{"properties":[{"computed":false,"key":{"name":"a","type":"Identifier"},"kind":"init","method":false,"shorthand":true,"type":"Property","value":{"name":"a","type":"Identifier"}},{"argument":{"name":"r","type":"Identifier"},"type":"RestElement"}],"type":"ObjectPattern"},"init":{"name":"e","type":"Identifier"},"type":"VariableDeclarator"}],"kind":"const","type":"VariableDeclaration"}]`,
+		},
+		{
+			"(x = 1, ...z) => x ?? z;",
+			`[{"expression":{"async":false,"body":{"left":{"name":"x","type":"Identifier"},"operator":"??","right":{"name":"z","type":"Identifier"},"type":"LogicalExpression"},"expression":true,"generator":false,"id":null,"params":[{"left":{"name":"x","type":"Identifier"},"right":{"raw":"1","type":"Literal","value":1},"type":"AssignmentPattern"},{"argument":{"name":"z","type":"Identifier"},"type":"RestElement"}],"type":"ArrowFunctionExpression"},"type":"ExpressionStatement"}]`,
+		},
+		{
+			"if (a) b(); else c?.d;",
+			`[{"alternate":{"expression":{"expression":{"computed":false,"object":{"name":"c","type":"Identifier"},"optional":true,"property":{"name":"d","type":"Identifier"},"type":"MemberExpression"},"type":"ChainExpression"},"type":"ExpressionStatement"},"consequent":{"expression":{"arguments":[],"callee":{"name":"b","type":"Identifier"},"optional":false,"type":"CallExpression"},"type":"ExpressionStatement"},"test":{"name":"a","type":"Identifier"},"type":"IfStatement"}]`,
+		},
+		{
+			"class A { m() {} #x = 1; }",
+			`[{"body":{"body":[{"computed":false,"key":{"name":"m","type":"Identifier"},"kind":"method","static":false,"type":"MethodDefinition","value":{"async":false,"body":{"body":[],"type":"BlockStatement"},"expression":false,"generator":false,"id":null,"params":[],"type":"FunctionExpression"}},{"computed":false,"key":{"name":"x","type":"PrivateIdentifier"},"static":false,"type":"PropertyDefinition","value":{"raw":"1","type":"Literal","value":1}}],"type":"ClassBody"},"id":{"name":"A","type":"Identifier"},"superClass":null,"type":"ClassDeclaration"}]`,
+		},
+		{
+			"class A { static { a; } }",
+			`[{"body":{"body":[{"body":[{"expression":{"name":"a","type":"Identifier"},"type":"ExpressionStatement"}],"type":"StaticBlock"}],"type":"ClassBody"},"id":{"name":"A","type":"Identifier"},"superClass":null,"type":"ClassDeclaration"}]`,
+		},
+		{
+			"`a${b}`;",
+			`[{"expression":{"expressions":[{"name":"b","type":"Identifier"}],"quasis":[{"tail":false,"type":"TemplateElement","value":{"cooked":"a","raw":"a"}},{"tail":true,"type":"TemplateElement","value":{"cooked":"","raw":""}}],"type":"TemplateLiteral"},"type":"ExpressionStatement"}]`,
+		},
+	}
+	for _, tt := range tests {
+		if got := estreeBody(t, parse(t, tt.src), tt.src); got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.src, got, tt.want)
+		}
+	}
+
+	src := "import d, { a as b } from \"m\";\nexport { b as c };\nexport default function () {}"
+	prog, errs := New(src).ParseModule()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	want := `[{"source":{"raw":"\"m\"","type":"Literal","value":"m"},"specifiers":[{"local":{"name":"d","type":"Identifier"},"type":"ImportDefaultSpecifier"},{"imported":{"name":"a","type":"Identifier"},"local":{"name":"b","type":"Identifier"},"type":"ImportSpecifier"}],"type":"ImportDeclaration"},{"declaration":null,"source":null,"specifiers":[{"exported":{"name":"c","type":"Identifier"},"local":{"name":"b","type":"Identifier"},"type":"ExportSpecifier"}],"type":"ExportNamedDeclaration"},{"declaration":{"async":false,"body":{"body":[],"type":"BlockStatement"},"expression":false,"generator":false,"id":null,"params":[],"type":"FunctionDeclaration"},"type":"ExportDefaultDeclaration"}]`
+	if got := estreeBody(t, prog, src); got != want {
+		t.Errorf("module:\ngot  %s\nwant %s", got, want)
+	}
+	if ast.ESTree(prog, src)["sourceType"] != "module" {
+		t.Errorf("sourceType should be module")
+	}
+
+	// Positions follow acorn: 0-based columns, and names kept as strings
+	// get the position of their text.
+	spec := ast.ESTree(prog, src)["body"].([]interface{})[1].(map[string]interface{})["specifiers"].([]interface{})[0].(map[string]interface{})
+	exported := spec["exported"].(map[string]interface{})
+	loc := exported["loc"].(map[string]interface{})["start"].(map[string]int)
+	if exported["start"] != 45 || exported["end"] != 46 || loc["line"] != 2 || loc["column"] != 14 {
+		t.Errorf("exported name at %v-%v %v", exported["start"], exported["end"], loc)
+	}
+}
+
+// ---------- Modules ----------
+
+func TestModuleDeclarations(t *testing.T) {
+	prog, errs := New(`
+		import def, { a, b as c, default as d } from "./m.js";
+		import * as ns from "./n.js";
+		export const x = 1, y = 2;
+		export { x as default2, y };
+		export * as all from "./n.js";
+		export default function f() {}
+	`).ParseModule()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	expectStmtCount(t, prog, 6)
+
+	imp := prog.Statements[0].(*ast.ImportDeclaration)
+	if imp.Source.Value != "./m.js" || len(imp.Specifiers) != 4 {
+		t.Fatalf("unexpected import %+v", imp)
+	}
+	var got [][2]string
+	for _, spec := range imp.Specifiers {
+		got = append(got, [2]string{spec.Imported, spec.Local.Value})
+	}
+	want := [][2]string{{"default", "def"}, {"a", "a"}, {"b", "c"}, {"default", "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("specifiers = %v, want %v", got, want)
+	}
+	if ns := prog.Statements[1].(*ast.ImportDeclaration); ns.Specifiers[0].Imported != "*" || ns.Specifiers[0].Local.Value != "ns" {
+		t.Errorf("unexpected namespace import %+v", ns.Specifiers[0])
+	}
+	if decl, ok := prog.Statements[2].(*ast.ExportNamedDeclaration); !ok || decl.Declaration == nil {
+		t.Errorf("expected exported declaration, got %T", prog.Statements[2])
+	}
+	list := prog.Statements[3].(*ast.ExportNamedDeclaration)
+	if len(list.Specifiers) != 2 || list.Specifiers[0].Local != "x" || list.Specifiers[0].Exported != "default2" {
+		t.Errorf("unexpected export list %+v", list.Specifiers)
+	}
+	if all := prog.Statements[4].(*ast.ExportAllDeclaration); all.Exported != "all" || all.Source.Value != "./n.js" {
+		t.Errorf("unexpected export all %+v", all)
+	}
+	if def := prog.Statements[5].(*ast.ExportDefaultDeclaration); def.Declaration.(*ast.FunctionDeclaration).Name.Value != "f" {
+		t.Errorf("expected default function declaration")
+	}
+
+	if _, errs := parseWithErrors(`export const x = 1;`); len(errs) == 0 {
+		t.Errorf("expected export in a script to be an error")
+	}
+	if _, errs := New(`if (x) { import y from "y"; }`).ParseModule(); len(errs) == 0 {
+		t.Errorf("expected nested import to be an error")
+	}
+}
+
+func TestScopeAnalysis(t *testing.T) {
+	prog := parse(t, `
+		function outer(a) {
+			var local = 1;
+			return () => inner(a, this) + (function () { return eval("local"); })();
+		}
+		var arrow = x => x.y;
+	`)
+	outer := prog.Statements[0].(*ast.FunctionDeclaration)
+	if outer.Scope == nil {
+		t.Fatalf("expected outer to be analyzed")
+	}
+	want := []string{"a", "eval", "inner", "local", "outer", "this"}
+	if !reflect.DeepEqual(outer.Scope.Free, want) {
+		t.Errorf("outer free names = %v, want %v", outer.Scope.Free, want)
+	}
+	if outer.Scope.DirectEval || !outer.Scope.Dynamic {
+		t.Errorf("outer: eval is nested, got DirectEval=%v Dynamic=%v", outer.Scope.DirectEval, outer.Scope.Dynamic)
+	}
+	ret := outer.Body.Statements[1].(*ast.ReturnStatement).Value.(*ast.ArrowFunctionExpression)
+	if !ret.Scope.Dynamic || ret.Scope.DirectEval {
+		t.Errorf("arrow containing the eval: got DirectEval=%v Dynamic=%v", ret.Scope.DirectEval, ret.Scope.Dynamic)
+	}
+
+	arrow := prog.Statements[1].(*ast.VariableDeclaration).Declarations[0].Value.(*ast.ArrowFunctionExpression)
+	if want := []string{"x", "y"}; !reflect.DeepEqual(arrow.Scope.Free, want) {
+		t.Errorf("arrow free names = %v, want %v", arrow.Scope.Free, want)
+	}
+	if prog.Scope == nil || prog.Scope.DirectEval || !prog.Scope.Dynamic {
+		t.Errorf("unexpected program scope %+v", prog.Scope)
+	}
+}
@@ -0,0 +1,85 @@
+package runtime
+
+import "sync/atomic"
+
+// An Agent runs the scripts of one or more realms, one at a time, as a
+// JavaScript agent does: the realms share its event queue, its execution
+// lock and the state of the script that is running, which the fields below
+// hold. Agents share nothing with each other, so the scripts of different
+// agents may run in parallel, on different goroutines.
+type Agent struct {
+	// Events are the microtasks and timers of the agent's scripts.
+	Events *EventQueue
+	// IO are the streams of the running script, set by the interpreter
+	// running it. Builtins do their I/O through it.
+	IO *Streams
+	// Heap is the budget of the evaluation being run, set by the
+	// interpreter. When it is nil, allocations are not limited.
+	Heap *HeapBudget
+	// CaptureStack is set by the interpreter running a script. It returns
+	// the frames of the script's call stack, innermost first, one
+	// "    at ..." line each.
+	CaptureStack func() string
+	// ConstructHook is set by the interpreter running a script to its
+	// [[Construct]], which passes new.target on to interpreted
+	// constructors.
+	ConstructHook func(callee *Object, args []*Value, newTarget *Object) (*Value, error)
+	// EnterNativeHook is set by the interpreter running a script. It
+	// counts a conversion started by native code against the script's call
+	// depth and returns the function that ends it, or a RangeError once
+	// the limit is reached.
+	EnterNativeHook func() (func(), error)
+
+	sem    chan struct{}
+	held   atomic.Bool
+	states []func() func()
+}
+
+// NewAgent returns an agent with an empty event queue, the standard
+// streams of the process and its execution lock free.
+func NewAgent() *Agent {
+	return &Agent{
+		Events: NewEventQueue(),
+		IO:     StandardStreams(),
+		sem:    make(chan struct{}, 1),
+	}
+}
+
+// ErrorStack returns the stack property of an error created now: header,
+// such as "TypeError: x is not a function", followed by the call stack.
+func (a *Agent) ErrorStack(header string) string {
+	if a.CaptureStack == nil {
+		return header
+	}
+	if frames := a.CaptureStack(); frames != "" {
+		return header + "\n" + frames
+	}
+	return header
+}
+
+// ToPrimitive is the ToPrimitive abstract operation run by native code.
+// The toString, valueOf or Symbol.toPrimitive method it calls may be native
+// too and convert again, as Array.prototype.join does for its elements, so
+// the conversion counts as a call: a chain of them too deep for the script's
+// call depth throws a RangeError instead of overflowing the Go stack.
+func (a *Agent) ToPrimitive(v *Value, hint string) (*Value, error) {
+	if v == nil || v.Type != TypeObject || a.EnterNativeHook == nil {
+		return ToPrimitive(v, hint)
+	}
+	leave, err := a.EnterNativeHook()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	return ToPrimitive(v, hint)
+}
+
+// ToString is the ToString abstract operation run by native code, with
+// its conversion counted as a call like that of ToPrimitive.
+func (a *Agent) ToString(v *Value) (string, error) {
+	prim, err := a.ToPrimitive(v, "string")
+	if err != nil {
+		return "", err
+	}
+	return ToString(prim)
+}
@@ -0,0 +1,73 @@
+package runtime
+
+import "strconv"
+
+// NewArgumentsObject creates the arguments object of a call: an ordinary
+// object, not an array, holding the arguments as indexed properties with a
+// non-enumerable length, callee and Symbol.iterator. A nil callee makes the
+// arguments object of a strict function, whose callee throws when read or
+// written.
+func (r *Realm) NewArgumentsObject(args []*Value, callee *Value) *Object {
+	obj := NewOrdinaryObject(r.ObjectPrototype)
+	obj.OType = ObjTypeArguments
+	for i, a := range args {
+		obj.putProperty(strconv.Itoa(i), &Property{Value: a, Writable: true, Enumerable: true, Configurable: true})
+	}
+	obj.putProperty("length", &Property{Value: NewNumber(float64(len(args))), Writable: true, Configurable: true})
+	if callee == nil {
+		thrower := NewObject(r.ThrowTypeError())
+		obj.putProperty("callee", &Property{IsAccessor: true, Getter: thrower, Setter: thrower})
+	} else {
+		obj.putProperty("callee", &Property{Value: callee, Writable: true, Configurable: true})
+	}
+	if SymbolIterator != nil && r.ArrayIteratorMethod != nil {
+		obj.putProperty(SymbolIterator.Key(), &Property{Value: NewObject(r.ArrayIteratorMethod), Writable: true, Configurable: true})
+	}
+	return obj
+}
+
+// MapArguments maps the elements of the arguments object o to the
+// parameters of a sloppy-mode function with a simple parameter list, whose
+// bindings are in env: reading or assigning arguments[i] reads or assigns
+// params[i], and the other way round. Only elements for arguments that
+// were passed are mapped, and a repeated name maps its last position.
+func (o *Object) MapArguments(env *Environment, params []string) {
+	mapped := make(map[string]*Binding)
+	seen := make(map[string]bool)
+	for i := len(params) - 1; i >= 0; i-- {
+		name := params[i]
+		key := strconv.Itoa(i)
+		if seen[name] || o.Properties[key] == nil {
+			continue
+		}
+		seen[name] = true
+		if b, ok := env.store[name]; ok {
+			mapped[key] = b
+		}
+	}
+	if len(mapped) == 0 {
+		return
+	}
+	if o.Internal == nil {
+		o.Internal = make(map[string]interface{})
+	}
+	o.Internal["mappedArguments"] = mapped
+}
+
+// mappedArgument returns the parameter binding an element of an arguments
+// object is mapped to, or nil.
+func (o *Object) mappedArgument(name string) *Binding {
+	if o.OType != ObjTypeArguments {
+		return nil
+	}
+	mapped, _ := o.Internal["mappedArguments"].(map[string]*Binding)
+	return mapped[name]
+}
+
+// unmapArgument ends the mapping of an arguments object element, leaving
+// the element as an ordinary property.
+func (o *Object) unmapArgument(name string) {
+	if mapped, ok := o.Internal["mappedArguments"].(map[string]*Binding); ok {
+		delete(mapped, name)
+	}
+}
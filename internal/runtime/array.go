@@ -0,0 +1,282 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// MaxArrayGap is how far past the end of ArrayData an element may be
+// written and still be stored there, the indices skipped becoming holes
+// (nil). An element further out, as in arr[1e9] = 1, is kept as an
+// ordinary index property instead, so that a large index or length costs
+// no memory for the holes below it.
+const MaxArrayGap = 1024
+
+// MaxArrayLength is the largest length an array can have, 2^32 - 1.
+const MaxArrayLength = 1<<32 - 1
+
+// ArrayIndex returns the value of key if it is a canonical array index: a
+// decimal integer below MaxArrayLength without leading zeros.
+func ArrayIndex(key string) (int, bool) {
+	n, ok := arrayIndex(key)
+	return int(n), ok
+}
+
+// ToArrayLength converts v, the new length of an array, to an int. It
+// throws a RangeError unless v is an integer from 0 to MaxArrayLength.
+func ToArrayLength(v *Value) (int, error) {
+	prim, err := ToPrimitive(v, "number")
+	if err != nil {
+		return 0, err
+	}
+	if prim.Type == TypeSymbol {
+		return 0, fmt.Errorf("TypeError: Cannot convert a Symbol value to a number")
+	}
+	n := prim.ToNumber()
+	if n < 0 || n > MaxArrayLength || n != math.Trunc(n) {
+		return 0, fmt.Errorf("RangeError: Invalid array length")
+	}
+	return int(n), nil
+}
+
+// ArrayLength returns the length of the array o. Its elements are stored
+// in ArrayData, and beyond it as index properties, with the length
+// property recording a length past the last of them.
+func (o *Object) ArrayLength() int {
+	n := len(o.ArrayData)
+	if prop := o.Properties["length"]; prop != nil && prop.Value != nil && prop.Value.Type == TypeNumber && int(prop.Value.Number) > n {
+		return int(prop.Value.Number)
+	}
+	return n
+}
+
+// setLengthProperty records n as the length of the array o. A read-only
+// length is left alone; CanSetElement keeps it from being outgrown.
+func (o *Object) setLengthProperty(n int) {
+	prop := o.Properties["length"]
+	if prop == nil {
+		o.putProperty("length", &Property{Value: NewNumber(float64(n)), Writable: true})
+		return
+	}
+	if prop.Writable && !prop.IsAccessor {
+		prop.Value = NewNumber(float64(n))
+	}
+}
+
+// IsSparse reports whether some elements of the array o lie beyond
+// ArrayData, or its length does.
+func (o *Object) IsSparse() bool {
+	return o.ArrayLength() > len(o.ArrayData)
+}
+
+// ArrayElement returns element i of the array o, and false if i is a hole
+// or past the end. Elements beyond ArrayData are read as properties, so a
+// getter defined on an index is called.
+func (o *Object) ArrayElement(i int) (*Value, bool) {
+	if i < 0 {
+		return nil, false
+	}
+	if i < len(o.ArrayData) {
+		if v := o.ArrayData[i]; v != nil {
+			return v, true
+		}
+	}
+	key := strconv.Itoa(i)
+	if _, ok := o.Properties[key]; !ok {
+		return nil, false
+	}
+	return o.Get(key), true
+}
+
+// element returns the element of o named key if ArrayData holds it, and
+// nil otherwise.
+func (o *Object) element(key string) *Value {
+	if len(o.ArrayData) == 0 || key == "" || key[0] < '0' || key[0] > '9' {
+		return nil
+	}
+	if n, ok := arrayIndex(key); ok && int(n) < len(o.ArrayData) {
+		return o.ArrayData[n]
+	}
+	return nil
+}
+
+// trySetElement assigns val to the element or the length named key of the
+// array o. handled reports whether key names one, leaving elements with
+// attributes of their own to be set as properties, and ok whether the
+// assignment took effect.
+func (o *Object) trySetElement(key string, val *Value) (handled, ok bool, err error) {
+	if o.OType != ObjTypeArray {
+		return false, false, nil
+	}
+	if key == "length" {
+		n, err := ToArrayLength(val)
+		if err != nil {
+			return true, false, err
+		}
+		return true, o.SetArrayLength(n), nil
+	}
+	i, isIndex := ArrayIndex(key)
+	if !isIndex || o.Properties[key] != nil {
+		return false, false, nil
+	}
+	if !o.CanSetElement(i) {
+		return true, false, nil
+	}
+	o.SetArrayElement(i, val)
+	return true, true, nil
+}
+
+// SetArrayElement stores v as element i of the array o, extending its
+// length past i if needed. Within MaxArrayGap of the end of ArrayData the
+// element goes into ArrayData, leaving holes before it; further out it
+// becomes an index property. Callers check CanSetElement first.
+func (o *Object) SetArrayElement(i int, v *Value) {
+	n := len(o.ArrayData)
+	switch {
+	case i < n:
+		o.ArrayData[i] = v
+	case i <= n+MaxArrayGap:
+		for len(o.ArrayData) < i {
+			o.ArrayData = append(o.ArrayData, nil)
+		}
+		o.ArrayData = append(o.ArrayData, v)
+		o.absorbIndexProperties(n)
+	default:
+		o.putProperty(strconv.Itoa(i), &Property{Value: v, Writable: true, Enumerable: true, Configurable: true})
+	}
+	if i >= o.ArrayLength() {
+		o.setLengthProperty(i + 1)
+	}
+}
+
+// absorbIndexProperties moves the plain data elements that ArrayData has
+// grown over, from index from on, out of Properties and into ArrayData.
+// Only arrays with properties besides length can have any.
+func (o *Object) absorbIndexProperties(from int) {
+	if len(o.Properties) <= 1 {
+		return
+	}
+	for i := from; i < len(o.ArrayData); i++ {
+		key := strconv.Itoa(i)
+		prop, ok := o.Properties[key]
+		if !ok || prop.IsAccessor || !prop.Writable || !prop.Enumerable || !prop.Configurable {
+			continue
+		}
+		if o.ArrayData[i] == nil {
+			o.ArrayData[i] = prop.Value
+		}
+		delete(o.Properties, key)
+	}
+}
+
+// SetArrayLength sets the length of the array o to n, deleting the
+// elements from n on, and reports whether it could. Growing within
+// MaxArrayGap adds holes to ArrayData; growing further only records the
+// length. Like a non-configurable element, a sealed array keeps its
+// elements, and the length stops just past the last one kept.
+func (o *Object) SetArrayLength(n int) bool {
+	if prop := o.Properties["length"]; prop != nil && (!prop.Writable || prop.IsAccessor) {
+		return n == o.ArrayLength()
+	}
+	ok := true
+	indices := o.indexProperties(n)
+	for j := len(indices) - 1; j >= 0; j-- {
+		key := strconv.Itoa(indices[j])
+		if !o.Properties[key].Configurable {
+			n, ok = indices[j]+1, false
+			break
+		}
+		delete(o.Properties, key)
+	}
+	if n < len(o.ArrayData) {
+		if o.sealedElements {
+			n, ok = len(o.ArrayData), false
+		} else {
+			clear(o.ArrayData[n:])
+			o.ArrayData = o.ArrayData[:n]
+		}
+	}
+	if old := len(o.ArrayData); n > old && n <= old+MaxArrayGap {
+		for len(o.ArrayData) < n {
+			o.ArrayData = append(o.ArrayData, nil)
+		}
+		o.absorbIndexProperties(old)
+	}
+	if prop := o.Properties["length"]; prop != nil {
+		prop.Value = NewNumber(float64(n))
+	} else {
+		o.setLengthProperty(n)
+	}
+	return ok
+}
+
+// Densify moves the elements of the array o kept as index properties into
+// ArrayData, which grows to the length of o with holes where it has no
+// element. Elements with attributes of their own, such as accessors, stay
+// properties and leave a hole in ArrayData.
+func (o *Object) Densify() {
+	old := len(o.ArrayData)
+	if n := o.ArrayLength(); n > old {
+		o.ArrayData = append(o.ArrayData, make([]*Value, n-old)...)
+		o.absorbIndexProperties(old)
+	}
+}
+
+// indexProperties returns the indices, from index from on, of the elements
+// of o kept as properties, in ascending order.
+func (o *Object) indexProperties(from int) []int {
+	if len(o.Properties) <= 1 {
+		return nil
+	}
+	var indices []int
+	for key := range o.Properties {
+		if i, ok := ArrayIndex(key); ok && i >= from {
+			indices = append(indices, i)
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// ElementIndices returns the indices of the elements of the array o, holes
+// left out, in ascending order.
+func (o *Object) ElementIndices() []int {
+	var indices []int
+	for i, v := range o.ArrayData {
+		if v != nil {
+			indices = append(indices, i)
+		}
+	}
+	// Elements with attributes of their own are properties even within
+	// ArrayData, where they leave a hole.
+	props := o.indexProperties(0)
+	if len(props) > 0 && props[0] < len(o.ArrayData) {
+		indices = append(indices, props...)
+		sort.Ints(indices)
+		return indices
+	}
+	return append(indices, props...)
+}
+
+// ArrayValues returns the values of the array o from index 0 up to its
+// length, as iterating over it yields them: holes read as undefined, or as
+// what the prototype chain has at that index. An array without holes
+// returns its ArrayData.
+func (o *Object) ArrayValues() []*Value {
+	length := o.ArrayLength()
+	if length == len(o.ArrayData) && !slices.Contains(o.ArrayData, nil) {
+		return o.ArrayData
+	}
+	values := make([]*Value, length)
+	for i := range values {
+		if v, ok := o.ArrayElement(i); ok {
+			values[i] = v
+		} else {
+			values[i] = o.Get(strconv.Itoa(i))
+		}
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+package runtime
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSparseArray(t *testing.T) {
+	arr := NewArrayObject(nil, []*Value{NewNumber(1), NewNumber(2)})
+	arr.SetArrayElement(5, NewNumber(6))
+	if arr.ArrayLength() != 6 || len(arr.ArrayData) != 6 || arr.ArrayData[3] != nil {
+		t.Errorf("a nearby element extends ArrayData with holes: %v", arr.ArrayData)
+	}
+	if _, ok := arr.ArrayElement(3); ok {
+		t.Error("a hole is not an element")
+	}
+
+	arr.SetArrayElement(1_000_000, NewNumber(7))
+	if arr.ArrayLength() != 1_000_001 || len(arr.ArrayData) != 6 {
+		t.Errorf("a distant element is kept as a property, got length %d with %d slots", arr.ArrayLength(), len(arr.ArrayData))
+	}
+	if v, ok := arr.ArrayElement(1_000_000); !ok || v.Number != 7 {
+		t.Error("a distant element reads back")
+	}
+	if got := arr.ElementIndices(); !slices.Equal(got, []int{0, 1, 5, 1_000_000}) {
+		t.Errorf("ElementIndices = %v", got)
+	}
+
+	if !arr.SetArrayLength(2) || arr.ArrayLength() != 2 || len(arr.ArrayData) != 2 || arr.HasOwnProperty("1000000") {
+		t.Error("shortening the length deletes the elements past it")
+	}
+	if !arr.SetArrayLength(10) || len(arr.ArrayData) != 10 || arr.ArrayData[9] != nil {
+		t.Error("lengthening the length adds holes")
+	}
+	if got := arr.ArrayValues(); len(got) != 10 || got[9] != nil && got[9].Type != TypeUndefined {
+		t.Errorf("ArrayValues reads holes as undefined, got %v", got)
+	}
+}
+
+func TestArrayLengthStopsAtNonConfigurable(t *testing.T) {
+	arr := NewArrayObject(nil, []*Value{NewNumber(1), nil, NewNumber(3)})
+	arr.DefineProperty("1", &Property{Value: NewNumber(2), Writable: true, Enumerable: true})
+	if arr.SetArrayLength(0) {
+		t.Error("a non-configurable element cannot be deleted")
+	}
+	if arr.ArrayLength() != 2 || arr.ArrayData[0] == nil {
+		t.Errorf("the length stops past the kept element, got %d", arr.ArrayLength())
+	}
+}
+
+func TestToArrayLength(t *testing.T) {
+	if n, err := ToArrayLength(NewString("3")); err != nil || n != 3 {
+		t.Errorf("ToArrayLength(\"3\") = %d, %v", n, err)
+	}
+	for _, v := range []*Value{NewNumber(-1), NewNumber(1.5), NewNumber(MaxArrayLength + 1)} {
+		if _, err := ToArrayLength(v); err == nil {
+			t.Errorf("ToArrayLength(%v) should throw", v.Number)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package runtime
+
+import "fmt"
+
+// Cloner copies values for DeepClone. It remembers the objects it has
+// copied, so that an object reached twice is copied once and cycles in the
+// original are cycles in the copy.
+type Cloner struct {
+	realm  *Realm
+	copies map[*Object]*Object
+}
+
+// DeepClone returns a copy of v made by the structured clone algorithm, as
+// structuredClone does: primitives are returned as they are, arrays and
+// plain objects are copied with their own enumerable string-keyed
+// properties, getters being read, Number, String and Boolean objects are
+// copied as new wrappers of the same primitive, and Maps, Sets, Dates,
+// RegExps and errors are copied with their contents. Objects of other classes are copied as
+// plain objects without their prototype. Functions, symbols, promises,
+// proxies and weak collections cannot be cloned and fail with a
+// DataCloneError. The copy shares nothing with v, so it may be handed to
+// another interpreter or kept as a snapshot. The copies are objects of r.
+func (r *Realm) DeepClone(v *Value) (*Value, error) {
+	c := &Cloner{realm: r, copies: make(map[*Object]*Object)}
+	return c.Clone(v)
+}
+
+// Clone copies v, reusing the copy of an object already cloned.
+func (c *Cloner) Clone(v *Value) (*Value, error) {
+	if v == nil {
+		return Undefined, nil
+	}
+	switch v.Type {
+	case TypeSymbol:
+		return nil, dataCloneError(v.ToString())
+	case TypeObject:
+		if v.Object == nil {
+			return v, nil
+		}
+		obj, err := c.cloneObject(v.Object)
+		if err != nil {
+			return nil, err
+		}
+		return NewObject(obj), nil
+	}
+	return v, nil
+}
+
+// Remember records dst as the copy of src.
+func (c *Cloner) Remember(src, dst *Object) {
+	c.copies[src] = dst
+}
+
+func (c *Cloner) cloneObject(src *Object) (*Object, error) {
+	if dst, ok := c.copies[src]; ok {
+		return dst, nil
+	}
+	switch {
+	case src.Callable != nil:
+		return nil, dataCloneError("function")
+	case src.OType == ObjTypeProxy:
+		return nil, dataCloneError("#<Proxy>")
+	case src.OType == ObjTypePromise, src.OType == ObjTypeWeakMap, src.OType == ObjTypeWeakSet,
+		src.OType == ObjTypeGenerator, src.OType == ObjTypeIterator:
+		return nil, dataCloneError("#<Object>")
+	}
+	if err := c.realm.Agent.Charge(ObjectSize); err != nil {
+		return nil, err
+	}
+	if prim, ok := PrimitiveData(src); ok {
+		if prim.Type == TypeSymbol {
+			return nil, dataCloneError("#<Symbol>")
+		}
+		dst, err := c.realm.ToObject(prim)
+		if err != nil {
+			return nil, err
+		}
+		c.Remember(src, dst)
+		return dst, nil
+	}
+	if clone := c.realm.CloneBuiltin; clone != nil {
+		if dst, ok, err := clone(c, src); ok || err != nil {
+			return dst, err
+		}
+	}
+
+	var dst *Object
+	if src.OType == ObjTypeArray {
+		dst = NewArrayObject(c.realm.ArrayPrototype, nil)
+		dst.SetArrayLength(src.ArrayLength())
+	} else {
+		dst = NewOrdinaryObject(c.realm.ObjectPrototype)
+	}
+	c.Remember(src, dst)
+	for key, prop := range OwnPropertyIterator(src, EnumerableStringKeys) {
+		if dst.OType == ObjTypeArray && key == "length" {
+			continue
+		}
+		val := prop.Value
+		if prop.IsAccessor {
+			var err error
+			if val, err = src.GetErr(key); err != nil {
+				return nil, err
+			}
+		}
+		copied, err := c.Clone(val)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.realm.Agent.Charge(PropertySize); err != nil {
+			return nil, err
+		}
+		if n, ok := ArrayIndex(key); ok && dst.OType == ObjTypeArray {
+			dst.SetArrayElement(n, copied)
+		} else {
+			dst.Set(key, copied)
+		}
+	}
+	return dst, nil
+}
+
+// dataCloneError is the error for a value that cannot be cloned, which
+// structuredClone throws as a DataCloneError.
+func dataCloneError(what string) error {
+	return fmt.Errorf("DataCloneError: %s could not be cloned", what)
+}
@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeepClone(t *testing.T) {
+	realm := NewRealm(NewAgent())
+	inner := NewOrdinaryObject(nil)
+	inner.Set("n", NewNumber(1))
+	arr := NewArrayObject(nil, []*Value{NewObject(inner), nil, NewString("x")})
+	arr.SetArrayElement(100_000, NewObject(inner))
+	obj := NewOrdinaryObject(nil)
+	obj.Set("arr", NewObject(arr))
+	obj.Set("self", NewObject(obj))
+	obj.DefineProperty("hidden", &Property{Value: NewNumber(1)})
+
+	v, err := realm.DeepClone(NewObject(obj))
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := v.Object
+	if copied == obj || copied.Get("self").Object != copied {
+		t.Error("a cycle in the original is a cycle in the copy")
+	}
+	if copied.HasOwnProperty("hidden") {
+		t.Error("a non-enumerable property is not cloned")
+	}
+	carr := copied.Get("arr").Object
+	if carr == arr || carr.ArrayLength() != 100_001 {
+		t.Fatalf("the array is copied with its length, got %d", carr.ArrayLength())
+	}
+	if _, ok := carr.ArrayElement(1); ok {
+		t.Error("a hole stays a hole")
+	}
+	first, _ := carr.ArrayElement(0)
+	last, _ := carr.ArrayElement(100_000)
+	if first.Object == inner || first.Object != last.Object {
+		t.Error("an object reached twice is copied once")
+	}
+
+	for _, prim := range []*Value{NewNumber(42), NewString("hi"), NewBool(false)} {
+		wrapper, _ := realm.ToObject(prim)
+		wrapper.Set("extra", NewNumber(1))
+		v, err := realm.DeepClone(NewObject(wrapper))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := PrimitiveData(v.Object)
+		if v.Object == wrapper || !ok || !StrictEquals(got, prim) {
+			t.Errorf("a %s object is cloned as a new wrapper of %s, got %v", prim.Type, prim.ToString(), got)
+		}
+		if v.Object.Prototype != wrapper.Prototype || v.Object.HasOwnProperty("extra") {
+			t.Errorf("a %s object keeps its prototype but not its properties", prim.Type)
+		}
+	}
+
+	sym := &Value{Type: TypeSymbol, Symbol: &Symbol{Description: "s"}}
+	fn := NewObject(NewFunctionObject(nil, func(this *Value, args []*Value) (*Value, error) { return Undefined, nil }))
+	for _, bad := range []*Value{sym, fn} {
+		if _, err := realm.DeepClone(bad); err == nil || !strings.HasPrefix(err.Error(), "DataCloneError: ") {
+			t.Errorf("cloning %s: expected a DataCloneError, got %v", bad.Type, err)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Coercion matrix. Objects are converted to a primitive with ToPrimitive
+// first (hint "number" for ToNumber, "string" for ToString), which calls
+// obj[Symbol.toPrimitive](hint), or else valueOf and toString in hint order.
+//
+//	value      | ToBoolean      | ToNumber                 | ToString
+//	-----------+----------------+--------------------------+------------------------
+//	undefined  | false          | NaN                      | "undefined"
+//	null       | false          | 0                        | "null"
+//	true/false | itself         | 1 / 0                    | "true" / "false"
+//	number     | false for 0,   | itself                   | "NaN", "Infinity",
+//	           | -0 and NaN     |                          | shortest round-trip
+//	string     | false for ""   | trimmed numeric literal, | itself
+//	           |                | decimal or 0x/0o/0b;     |
+//	           |                | 0 for "", NaN otherwise  |
+//	symbol     | true           | NaN (TypeError in JS)    | "Symbol(desc)"
+//	object     | always true    | ToNumber(ToPrimitive)    | "[object Object]"; see
+//	           |                | NaN if it throws         | Value.ToString
+//
+// ToNumber and ToString on Value never fail; code that must report errors
+// thrown by user conversions calls ToPrimitive directly. LooseEquals (==)
+// converts an object compared with a primitive using hint "default", which
+// ordinary objects treat as "number" and Date objects, through their
+// Symbol.toPrimitive method, as "string". The relational operators convert
+// both operands with hint "number" and compare two strings by UTF-16 code
+// units; see IsLessThan.
+
+// SymbolToPrimitive is set by the builtins package to the well-known
+// Symbol.toPrimitive, so conversions can find user-defined hooks.
+var SymbolToPrimitive *Symbol
+
+// ToPrimitive implements the ECMAScript ToPrimitive abstract operation. hint
+// is "default", "number" or "string". Primitives are returned unchanged.
+func ToPrimitive(v *Value, hint string) (*Value, error) {
+	if v == nil {
+		return Undefined, nil
+	}
+	if v.Type != TypeObject || v.Object == nil {
+		return v, nil
+	}
+	if SymbolToPrimitive != nil {
+		if exotic := v.Object.GetSymbol(SymbolToPrimitive); exotic != nil && exotic.Type != TypeUndefined && exotic.Type != TypeNull {
+			if exotic.Type != TypeObject || exotic.Object == nil || exotic.Object.Callable == nil {
+				return nil, fmt.Errorf("TypeError: Symbol.toPrimitive is not a function")
+			}
+			result, err := exotic.Object.Callable(v, []*Value{NewString(hint)})
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				return Undefined, nil
+			}
+			if result.Type == TypeObject {
+				return nil, fmt.Errorf("TypeError: Cannot convert object to primitive value")
+			}
+			return result, nil
+		}
+	}
+	if hint == "default" {
+		hint = "number"
+	}
+	return OrdinaryToPrimitive(v, hint)
+}
+
+// OrdinaryToPrimitive converts an object by calling valueOf and toString,
+// toString first when hint is "string". It ignores Symbol.toPrimitive, so
+// implementations of that hook can fall back on it.
+func OrdinaryToPrimitive(v *Value, hint string) (*Value, error) {
+	methods := [2]string{"valueOf", "toString"}
+	if hint == "string" {
+		methods = [2]string{"toString", "valueOf"}
+	}
+	for _, name := range methods {
+		fn := v.Object.Get(name)
+		if fn == nil || fn.Type != TypeObject || fn.Object == nil || fn.Object.Callable == nil {
+			continue
+		}
+		result, err := fn.Object.Callable(v, nil)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return Undefined, nil
+		}
+		if result.Type != TypeObject {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("TypeError: Cannot convert object to primitive value")
+}
+
+// ToString implements the ECMAScript ToString abstract operation, which
+// converts objects through ToPrimitive with hint "string" and, unlike the
+// Value.ToString method, rejects symbols.
+func ToString(v *Value) (string, error) {
+	prim, err := ToPrimitive(v, "string")
+	if err != nil {
+		return "", err
+	}
+	if prim.Type == TypeSymbol {
+		return "", fmt.Errorf("TypeError: Cannot convert a Symbol value to a string")
+	}
+	return prim.ToString(), nil
+}
+
+// ToPropertyKey implements the ECMAScript ToPropertyKey abstract operation:
+// objects are converted through ToPrimitive with hint "string", so their
+// toString or Symbol.toPrimitive decides the key.
+func ToPropertyKey(v *Value) (string, error) {
+	prim, err := ToPrimitive(v, "string")
+	if err != nil {
+		return "", err
+	}
+	return prim.ToPropertyKey(), nil
+}
+
+// ToNumber implements the ECMAScript ToNumber abstract operation.
+func (v *Value) ToNumber() float64 {
+	switch v.Type {
+	case TypeUndefined:
+		return math.NaN()
+	case TypeNull:
+		return 0
+	case TypeBoolean:
+		if v.Bool {
+			return 1
+		}
+		return 0
+	case TypeNumber:
+		return v.Number
+	case TypeString:
+		return StringToNumber(v.Str)
+	case TypeObject:
+		prim, err := ToPrimitive(v, "number")
+		if err != nil {
+			return math.NaN()
+		}
+		return prim.ToNumber()
+	default:
+		return math.NaN()
+	}
+}
+
+// StringToNumber implements the ECMAScript StringToNumber abstract
+// operation: once surrounding white space is trimmed, the whole string must
+// be a decimal literal, Infinity, or a 0x, 0o or 0b integer, or the result
+// is NaN. An empty string is 0.
+func StringToNumber(str string) float64 {
+	s := strings.TrimFunc(str, IsStrWhiteSpace)
+	if s == "" {
+		return 0
+	}
+	if len(s) > 2 && s[0] == '0' {
+		radix := 0
+		switch s[1] {
+		case 'x', 'X':
+			radix = 16
+		case 'o', 'O':
+			radix = 8
+		case 'b', 'B':
+			radix = 2
+		}
+		if radix != 0 {
+			// SetString would also accept a sign.
+			if s[2] == '+' || s[2] == '-' {
+				return math.NaN()
+			}
+			n, ok := new(big.Int).SetString(s[2:], radix)
+			if !ok {
+				return math.NaN()
+			}
+			f, _ := new(big.Float).SetInt(n).Float64()
+			return f
+		}
+	}
+	f, n := ParseDecimalPrefix(s)
+	if n != len(s) {
+		return math.NaN()
+	}
+	return f
+}
+
+// ParseDecimalPrefix reads the longest prefix of s that is a
+// StrDecimalLiteral, an optionally signed Infinity or decimal number, and
+// returns its value and length. The length is 0 if s starts with none.
+func ParseDecimalPrefix(s string) (float64, int) {
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	if strings.HasPrefix(s[i:], "Infinity") {
+		if s[0] == '-' {
+			return math.Inf(-1), i + len("Infinity")
+		}
+		return math.Inf(1), i + len("Infinity")
+	}
+	digits := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return math.NaN(), 0
+	}
+	end := i
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			end = i
+		}
+	}
+	// Out-of-range exponents give ±Inf or ±0 along with an error.
+	f, _ := strconv.ParseFloat(s[:end], 64)
+	return f, end
+}
+
+// IsStrWhiteSpace reports whether r is a StrWhiteSpaceChar: white space or
+// a line terminator. Unlike unicode.IsSpace it includes U+FEFF and excludes
+// U+0085.
+func IsStrWhiteSpace(r rune) bool {
+	return r == '\uFEFF' || (unicode.IsSpace(r) && r != '\u0085')
+}
+
+// SameValue implements the SameValue comparison of Object.is: NaN is the
+// same as itself, and +0 and -0 differ.
+func SameValue(a, b *Value) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case TypeUndefined, TypeNull:
+		return true
+	case TypeNumber:
+		if math.IsNaN(a.Number) && math.IsNaN(b.Number) {
+			return true
+		}
+		if a.Number == 0 && b.Number == 0 {
+			return (1/a.Number > 0) == (1/b.Number > 0)
+		}
+		return a.Number == b.Number
+	case TypeString:
+		return a.Str == b.Str
+	case TypeBoolean:
+		return a.Bool == b.Bool
+	case TypeObject:
+		return a.Object == b.Object
+	case TypeSymbol:
+		return a.Symbol == b.Symbol
+	}
+	return false
+}
+
+// StrictEquals implements === comparison.
+func StrictEquals(a, b *Value) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case TypeUndefined, TypeNull:
+		return true
+	case TypeBoolean:
+		return a.Bool == b.Bool
+	case TypeNumber:
+		if math.IsNaN(a.Number) || math.IsNaN(b.Number) {
+			return false
+		}
+		return a.Number == b.Number
+	case TypeString:
+		return a.Str == b.Str
+	case TypeObject:
+		return a.Object == b.Object
+	case TypeSymbol:
+		return a.Symbol == b.Symbol
+	default:
+		return false
+	}
+}
+
+// AbstractEquals implements == comparison, treating an error thrown while
+// converting an object operand as inequality. See LooseEquals.
+func AbstractEquals(a, b *Value) bool {
+	eq, _ := LooseEquals(a, b)
+	return eq
+}
+
+// LooseEquals implements the ECMAScript IsLooselyEqual (==) operation. An
+// object compared with a primitive is converted with ToPrimitive, so errors
+// thrown by its conversion methods are returned.
+func LooseEquals(a, b *Value) (bool, error) {
+	if a.Type == b.Type {
+		return StrictEquals(a, b), nil
+	}
+	if (a.Type == TypeNull && b.Type == TypeUndefined) ||
+		(a.Type == TypeUndefined && b.Type == TypeNull) {
+		return true, nil
+	}
+	if a.Type == TypeNumber && b.Type == TypeString {
+		return LooseEquals(a, NewNumber(b.ToNumber()))
+	}
+	if a.Type == TypeString && b.Type == TypeNumber {
+		return LooseEquals(NewNumber(a.ToNumber()), b)
+	}
+	if a.Type == TypeBoolean {
+		return LooseEquals(NewNumber(a.ToNumber()), b)
+	}
+	if b.Type == TypeBoolean {
+		return LooseEquals(a, NewNumber(b.ToNumber()))
+	}
+	if b.Type == TypeObject && isComparablePrimitive(a) {
+		prim, err := ToPrimitive(b, "default")
+		if err != nil {
+			return false, err
+		}
+		return LooseEquals(a, prim)
+	}
+	if a.Type == TypeObject && isComparablePrimitive(b) {
+		prim, err := ToPrimitive(a, "default")
+		if err != nil {
+			return false, err
+		}
+		return LooseEquals(prim, b)
+	}
+	return false, nil
+}
+
+func isComparablePrimitive(v *Value) bool {
+	return v.Type == TypeNumber || v.Type == TypeString || v.Type == TypeSymbol
+}
+
+// IsLessThan implements the ECMAScript IsLessThan abstract operation. It
+// converts x and y with ToPrimitive, hint "number", x first if leftFirst
+// is set, then compares two strings by UTF-16 code units and anything else
+// as numbers. The result is Undefined when either number is NaN.
+func IsLessThan(x, y *Value, leftFirst bool) (*Value, error) {
+	var px, py *Value
+	var err error
+	if leftFirst {
+		if px, err = ToPrimitive(x, "number"); err == nil {
+			py, err = ToPrimitive(y, "number")
+		}
+	} else {
+		if py, err = ToPrimitive(y, "number"); err == nil {
+			px, err = ToPrimitive(x, "number")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if px.Type == TypeString && py.Type == TypeString {
+		return NewBool(CompareUTF16(px.Str, py.Str) < 0), nil
+	}
+	if px.Type == TypeSymbol || py.Type == TypeSymbol {
+		return nil, fmt.Errorf("TypeError: Cannot convert a Symbol value to a number")
+	}
+	nx, ny := px.ToNumber(), py.ToNumber()
+	if math.IsNaN(nx) || math.IsNaN(ny) {
+		return Undefined, nil
+	}
+	return NewBool(nx < ny), nil
+}
+
+// Compare applies the relational operator op, one of <, >, <= and >=, as
+// the spec defines it in terms of IsLessThan: a > b is b < a, and a <= b
+// is !(b < a), except that every comparison with NaN is false.
+func Compare(op string, a, b *Value) (bool, error) {
+	var r *Value
+	var err error
+	switch op {
+	case "<", ">=":
+		r, err = IsLessThan(a, b, true)
+	case ">", "<=":
+		r, err = IsLessThan(b, a, false)
+	default:
+		return false, fmt.Errorf("runtime.Compare: unknown operator %q", op)
+	}
+	if err != nil || r.Type == TypeUndefined {
+		return false, err
+	}
+	if op == "<" || op == ">" {
+		return r.Bool, nil
+	}
+	return !r.Bool, nil
+}
+
+// ToIntegerOrInfinity truncates n toward zero, mapping NaN to 0 and leaving
+// the infinities intact.
+func ToIntegerOrInfinity(n float64) float64 {
+	if math.IsNaN(n) {
+		return 0
+	}
+	if n == 0 || math.IsInf(n, 0) {
+		return n
+	}
+	return math.Trunc(n)
+}
+
+// ArrayIndexOf implements the search of Array.prototype.indexOf. from is the
+// already-converted fromIndex; negative values count back from the end of
+// the array. Holes never match.
+func ArrayIndexOf(data []*Value, search *Value, from float64) int {
+	n := float64(len(data))
+	if n == 0 || from >= n {
+		return -1
+	}
+	if from < 0 {
+		from = n + from
+		if from < 0 {
+			from = 0
+		}
+	}
+	for i := int(from); i < len(data); i++ {
+		if data[i] != nil && StrictEquals(data[i], search) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ArrayLastIndexOf implements the search of Array.prototype.lastIndexOf. from
+// is the already-converted fromIndex, which callers default to len(data)-1
+// when the argument is omitted.
+func ArrayLastIndexOf(data []*Value, search *Value, from float64) int {
+	n := float64(len(data))
+	if n == 0 {
+		return -1
+	}
+	if from < 0 {
+		from = n + from
+		if from < 0 {
+			return -1
+		}
+	} else if from > n-1 {
+		from = n - 1
+	}
+	for i := int(from); i >= 0; i-- {
+		if data[i] != nil && StrictEquals(data[i], search) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewArrayObject creates an array object from values.
+func NewArrayObject(proto *Object, elements []*Value) *Object {
+	obj := &Object{
+		OType:      ObjTypeArray,
+		Properties: make(map[string]*Property),
+		Prototype:  proto,
+		ArrayData:  elements,
+	}
+	obj.Set("length", NewNumber(float64(len(elements))))
+	return obj
+}
+
+// NewFunctionObject creates a function object.
+func NewFunctionObject(proto *Object, callable CallableFunc) *Object {
+	return &Object{
+		OType:      ObjTypeFunction,
+		Properties: make(map[string]*Property),
+		Prototype:  proto,
+		Callable:   callable,
+	}
+}
+
+// NewErrorObject creates an error object with a message.
+func NewErrorObject(proto *Object, message string) *Object {
+	obj := &Object{
+		OType:      ObjTypeError,
+		Properties: make(map[string]*Property),
+		Prototype:  proto,
+	}
+	obj.Set("message", NewString(message))
+	obj.Set("name", NewString("Error"))
+	return obj
+}
@@ -0,0 +1,330 @@
+package runtime
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// method returns a function object whose call returns result, or err.
+func method(result *Value, err error) *Value {
+	return NewObject(NewFunctionObject(nil, func(this *Value, args []*Value) (*Value, error) {
+		return result, err
+	}))
+}
+
+func objectWith(props map[string]*Value) *Value {
+	obj := NewOrdinaryObject(nil)
+	for k, v := range props {
+		obj.Set(k, v)
+	}
+	return NewObject(obj)
+}
+
+// TestCoercionMatrix checks the value type × target type table documented
+// in coercion.go.
+func TestCoercionMatrix(t *testing.T) {
+	sym := &Value{Type: TypeSymbol, Symbol: &Symbol{Description: "s"}}
+	valueOf := objectWith(map[string]*Value{"valueOf": method(NewNumber(7), nil)})
+	both := objectWith(map[string]*Value{
+		"valueOf":  method(NewNumber(7), nil),
+		"toString": method(NewString("str"), nil),
+	})
+	throws := objectWith(map[string]*Value{"valueOf": method(nil, errors.New("TypeError: boom"))})
+	bare := NewObject(NewOrdinaryObject(nil))
+
+	tests := []struct {
+		name   string
+		val    *Value
+		bool   bool
+		number float64 // NaN compares as NaN
+		str    string
+	}{
+		{"undefined", Undefined, false, math.NaN(), "undefined"},
+		{"null", Null, false, 0, "null"},
+		{"true", True, true, 1, "true"},
+		{"false", False, false, 0, "false"},
+		{"zero", NewNumber(0), false, 0, "0"},
+		{"negative zero", NewNumber(math.Copysign(0, -1)), false, math.Copysign(0, -1), "0"},
+		{"NaN", NaN, false, math.NaN(), "NaN"},
+		{"Infinity", NewNumber(math.Inf(1)), true, math.Inf(1), "Infinity"},
+		{"number", NewNumber(1.5), true, 1.5, "1.5"},
+		{"empty string", NewString(""), false, 0, ""},
+		{"blank string", NewString("  \t"), true, 0, "  \t"},
+		{"numeric string", NewString(" 42 "), true, 42, " 42 "},
+		{"non-numeric string", NewString("4x"), true, math.NaN(), "4x"},
+		{"string Infinity", NewString("-Infinity"), true, math.Inf(-1), "-Infinity"},
+		{"hex string", NewString("0x10"), true, 16, "0x10"},
+		{"upper-case hex string", NewString(" 0XfF\n"), true, 255, " 0XfF\n"},
+		{"binary string", NewString("0b101"), true, 5, "0b101"},
+		{"octal string", NewString("0o17"), true, 15, "0o17"},
+		{"large hex string", NewString("0x20000000000001"), true, 9007199254740992, "0x20000000000001"},
+		{"signed hex string", NewString("-0x10"), true, math.NaN(), "-0x10"},
+		{"bad binary digit", NewString("0b102"), true, math.NaN(), "0b102"},
+		{"empty hex string", NewString("0x"), true, math.NaN(), "0x"},
+		{"exponent", NewString("1.5e3"), true, 1500, "1.5e3"},
+		{"leading dot", NewString(".5"), true, 0.5, ".5"},
+		{"trailing dot", NewString("5."), true, 5, "5."},
+		{"overflow", NewString("1e400"), true, math.Inf(1), "1e400"},
+		{"go-only syntax", NewString("1_000"), true, math.NaN(), "1_000"},
+		{"lower-case infinity", NewString("infinity"), true, math.NaN(), "infinity"},
+		{"byte order mark", NewString("\uFEFF7"), true, 7, "\uFEFF7"},
+		{"symbol", sym, true, math.NaN(), "Symbol(s)"},
+		{"object with valueOf", valueOf, true, 7, "[object Object]"},
+		{"object with valueOf and toString", both, true, 7, "[object Object]"},
+		{"object whose valueOf throws", throws, true, math.NaN(), "[object Object]"},
+		{"object without conversions", bare, true, math.NaN(), "[object Object]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.val.ToBoolean(); got != tt.bool {
+				t.Errorf("ToBoolean = %v, want %v", got, tt.bool)
+			}
+			got := tt.val.ToNumber()
+			if math.IsNaN(tt.number) {
+				if !math.IsNaN(got) {
+					t.Errorf("ToNumber = %v, want NaN", got)
+				}
+			} else if got != tt.number || math.Signbit(got) != math.Signbit(tt.number) {
+				t.Errorf("ToNumber = %v, want %v", got, tt.number)
+			}
+			if got := tt.val.ToString(); got != tt.str {
+				t.Errorf("ToString = %q, want %q", got, tt.str)
+			}
+		})
+	}
+}
+
+func TestNumberToString(t *testing.T) {
+	tests := []struct {
+		n    float64
+		want string
+	}{
+		{1234567, "1234567"},
+		{123456789012, "123456789012"},
+		{1e21, "1e+21"},
+		{1.5e300, "1.5e+300"},
+		{123e-20, "1.23e-18"},
+		{0.000001, "0.000001"},
+		{1e-7, "1e-7"},
+		{-0.5, "-0.5"},
+		{9007199254740993, "9007199254740992"},
+		{5e-324, "5e-324"},
+		{math.Copysign(0, -1), "0"},
+		{math.Inf(-1), "-Infinity"},
+		{math.Inf(1), "Infinity"},
+		{math.NaN(), "NaN"},
+		{100, "100"},
+		{0.30000000000000004, "0.30000000000000004"},
+		{1.5e-7, "1.5e-7"},
+		{-1e-7, "-1e-7"},
+		{1e20, "100000000000000000000"},
+		{123456789012345680000, "123456789012345680000"},
+		{1.5e21, "1.5e+21"},
+		{-1e21, "-1e+21"},
+		{1.7976931348623157e308, "1.7976931348623157e+308"},
+		{2.2250738585072014e-308, "2.2250738585072014e-308"},
+		{1 << 53, "9007199254740992"},
+		{123.456, "123.456"},
+		{0.1, "0.1"},
+	}
+	for _, tt := range tests {
+		if got := NumberToString(tt.n); got != tt.want {
+			t.Errorf("NumberToString(%v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestToPrimitive(t *testing.T) {
+	both := objectWith(map[string]*Value{
+		"valueOf":  method(NewNumber(7), nil),
+		"toString": method(NewString("str"), nil),
+	})
+	objValueOf := objectWith(map[string]*Value{
+		"valueOf":  method(NewObject(NewOrdinaryObject(nil)), nil),
+		"toString": method(NewString("fallback"), nil),
+	})
+
+	saved := SymbolToPrimitive
+	SymbolToPrimitive = &Symbol{Description: "Symbol.toPrimitive"}
+	defer func() { SymbolToPrimitive = saved }()
+	exotic := NewOrdinaryObject(nil)
+	exotic.Set(SymbolToPrimitive.Key(), NewObject(NewFunctionObject(nil, func(this *Value, args []*Value) (*Value, error) {
+		return NewString("hint:" + args[0].Str), nil
+	})))
+	badExotic := NewOrdinaryObject(nil)
+	badExotic.Set(SymbolToPrimitive.Key(), method(NewObject(NewOrdinaryObject(nil)), nil))
+
+	tests := []struct {
+		name    string
+		val     *Value
+		hint    string
+		want    string
+		wantErr bool
+	}{
+		{"primitive unchanged", NewNumber(3), "string", "3", false},
+		{"default prefers valueOf", both, "default", "7", false},
+		{"number prefers valueOf", both, "number", "7", false},
+		{"string prefers toString", both, "string", "str", false},
+		{"object from valueOf is skipped", objValueOf, "number", "fallback", false},
+		{"Symbol.toPrimitive gets the hint", NewObject(exotic), "default", "hint:default", false},
+		{"Symbol.toPrimitive returning an object", NewObject(badExotic), "number", "", true},
+		{"no conversion methods", NewObject(NewOrdinaryObject(nil)), "default", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToPrimitive(tt.val, tt.hint)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ToString() != tt.want {
+				t.Errorf("ToPrimitive = %q, want %q", got.ToString(), tt.want)
+			}
+		})
+	}
+}
+
+func TestToPropertyKey(t *testing.T) {
+	obj := objectWith(map[string]*Value{"toString": method(NewString("key"), nil)})
+	if got, err := ToPropertyKey(obj); err != nil || got != "key" {
+		t.Errorf("ToPropertyKey(object) = %q, %v; want \"key\"", got, err)
+	}
+	if got, err := ToPropertyKey(NewNumber(1.5)); err != nil || got != "1.5" {
+		t.Errorf("ToPropertyKey(1.5) = %q, %v; want \"1.5\"", got, err)
+	}
+	sym := &Symbol{Description: "s"}
+	if got, err := ToPropertyKey(&Value{Type: TypeSymbol, Symbol: sym}); err != nil || got != sym.Key() {
+		t.Errorf("ToPropertyKey(symbol) = %q, %v; want %q", got, err, sym.Key())
+	}
+	if _, err := ToString(&Value{Type: TypeSymbol, Symbol: sym}); err == nil {
+		t.Error("ToString(symbol) should throw a TypeError")
+	}
+}
+
+func TestLooseEquals(t *testing.T) {
+	arr := NewObject(NewArrayObject(nil, []*Value{NewNumber(1), NewNumber(2)}))
+	arr.Object.Set("toString", method(NewString("1,2"), nil))
+	seven := objectWith(map[string]*Value{"valueOf": method(NewNumber(7), nil)})
+	throws := objectWith(map[string]*Value{"valueOf": method(nil, errors.New("TypeError: boom"))})
+	sym := &Value{Type: TypeSymbol, Symbol: &Symbol{Description: "s"}}
+
+	tests := []struct {
+		name    string
+		a, b    *Value
+		want    bool
+		wantErr bool
+	}{
+		{"null and undefined", Null, Undefined, true, false},
+		{"null and zero", Null, NewNumber(0), false, false},
+		{"number and string", NewNumber(1), NewString("1"), true, false},
+		{"boolean and number", True, NewNumber(1), true, false},
+		{"array and string", arr, NewString("1,2"), true, false},
+		{"object and number", seven, NewNumber(7), true, false},
+		{"number and object", NewNumber(7), seven, true, false},
+		{"boolean and object", False, seven, false, false},
+		{"object identity", seven, seven, true, false},
+		{"distinct objects", seven, objectWith(nil), false, false},
+		{"object and null", seven, Null, false, false},
+		{"throwing conversion", throws, NewNumber(1), false, true},
+		{"NaN", NaN, NaN, false, false},
+		{"negative zero", NewNumber(math.Copysign(0, -1)), Zero, true, false},
+		{"undefined and false", Undefined, False, false, false},
+		{"symbol identity", sym, sym, true, false},
+		{"distinct symbols", sym, &Value{Type: TypeSymbol, Symbol: &Symbol{Description: "s"}}, false, false},
+		{"symbol and string", sym, NewString("Symbol(s)"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LooseEquals(tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LooseEquals = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	sym := &Value{Type: TypeSymbol, Symbol: &Symbol{Description: "s"}}
+	seven := objectWith(map[string]*Value{"valueOf": method(NewNumber(7), nil)})
+	str := objectWith(map[string]*Value{"toString": method(NewString("b"), nil)})
+	throws := objectWith(map[string]*Value{"valueOf": method(nil, errors.New("TypeError: boom"))})
+	negZero := NewNumber(math.Copysign(0, -1))
+
+	type results struct{ lt, gt, le, ge bool }
+	tests := []struct {
+		name    string
+		a, b    *Value
+		want    results
+		wantErr bool
+	}{
+		{"numbers", NewNumber(1), NewNumber(2), results{true, false, true, false}, false},
+		{"equal numbers", NewNumber(2), NewNumber(2), results{false, false, true, true}, false},
+		{"NaN", NaN, NewNumber(1), results{}, false},
+		{"NaN and NaN", NaN, NaN, results{}, false},
+		{"negative zero", negZero, Zero, results{false, false, true, true}, false},
+		{"infinities", NegInf, PosInf, results{true, false, true, false}, false},
+		{"strings", NewString("10"), NewString("9"), results{true, false, true, false}, false},
+		{"string prefix", NewString("a"), NewString("ab"), results{true, false, true, false}, false},
+		{"string and number", NewString("10"), NewNumber(9), results{false, true, false, true}, false},
+		{"non-numeric string", NewString("x"), NewNumber(1), results{}, false},
+		{"empty string", NewString(""), Zero, results{false, false, true, true}, false},
+		{"null and zero", Null, Zero, results{false, false, true, true}, false},
+		{"undefined and zero", Undefined, Zero, results{}, false},
+		{"null and undefined", Null, Undefined, results{}, false},
+		{"booleans", False, True, results{true, false, true, false}, false},
+		{"object with valueOf", seven, NewNumber(8), results{true, false, true, false}, false},
+		{"object with toString", str, NewString("a"), results{false, true, false, true}, false},
+		{"symbol", sym, NewNumber(1), results{}, true},
+		{"throwing conversion", NewNumber(1), throws, results{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got results
+			for _, c := range []struct {
+				op  string
+				res *bool
+			}{{"<", &got.lt}, {">", &got.gt}, {"<=", &got.le}, {">=", &got.ge}} {
+				r, err := Compare(c.op, tt.a, tt.b)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("%s: error = %v, wantErr %v", c.op, err, tt.wantErr)
+				}
+				*c.res = r
+			}
+			if got != tt.want {
+				t.Errorf("<, >, <=, >= = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompareOrder checks that the left operand is converted first even
+// when the operator swaps the operands of IsLessThan.
+func TestCompareOrder(t *testing.T) {
+	var order []string
+	obj := func(name string) *Value {
+		return objectWith(map[string]*Value{"valueOf": NewObject(NewFunctionObject(nil, func(this *Value, args []*Value) (*Value, error) {
+			order = append(order, name)
+			return Zero, nil
+		}))})
+	}
+	a, b := obj("a"), obj("b")
+	for _, op := range []string{"<", ">", "<=", ">="} {
+		order = nil
+		if _, err := Compare(op, a, b); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(order, ","); got != "a,b" {
+			t.Errorf("%s converted %s", op, got)
+		}
+	}
+}
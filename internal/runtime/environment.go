@@ -0,0 +1,563 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Environment represents a lexical scope.
+type Environment struct {
+	store       map[string]*Binding
+	outer       *Environment
+	isBlock     bool            // true for block scopes (let/const), false for function scopes
+	annexBNames map[string]bool // names hoisted by Annex B (block-level function decls)
+	globalObj   *Object         // if set, var/function bindings are mirrored as properties
+	boundary    bool            // Capture stops here; see MarkCaptureBoundary
+	withObj     *Object         // if set, an object environment record; see NewObjectEnvironment
+	slots       []*Binding      // bindings by number, see Index
+	strict      bool            // the function or script of this scope is strict mode code
+}
+
+type Binding struct {
+	Value    *Value
+	Mutable  bool     // false for const
+	Kind     string   // "var", "let", "const", "function", "import"
+	Declared bool     // false until initialized (for TDZ)
+	Target   *Binding // for imports: the exporting module's binding
+}
+
+func NewEnvironment(outer *Environment, isBlock bool) *Environment {
+	return &Environment{
+		store:   make(map[string]*Binding),
+		outer:   outer,
+		isBlock: isBlock,
+	}
+}
+
+// NewObjectEnvironment creates the object environment record of a with
+// statement. A name that is a property of obj, and is not blocked by its
+// @@unscopables, resolves to that property; any other name is looked up in
+// outer. The record is a capture boundary, since obj can gain properties
+// after closures are created in it.
+func NewObjectEnvironment(obj *Object, outer *Environment) *Environment {
+	env := NewEnvironment(outer, true)
+	env.withObj = obj
+	env.boundary = true
+	return env
+}
+
+// withHas reports whether the object record e resolves name.
+func (e *Environment) withHas(name string) (bool, error) {
+	if !e.withObj.HasProperty(name) {
+		return false, nil
+	}
+	if SymbolUnscopables == nil {
+		return true, nil
+	}
+	unscopables, err := e.withObj.GetErr(SymbolUnscopables.Key())
+	if err != nil {
+		return false, err
+	}
+	if unscopables == nil || unscopables.Type != TypeObject || unscopables.Object == nil {
+		return true, nil
+	}
+	blocked, err := unscopables.Object.GetErr(name)
+	if err != nil {
+		return false, err
+	}
+	return blocked == nil || !blocked.ToBoolean(), nil
+}
+
+// WithBase returns the object of the with statement that name resolves
+// through from e, or nil if name resolves to a declarative binding or does
+// not resolve. Calls use it as the this value of an unqualified callee.
+func (e *Environment) WithBase(name string) *Object {
+	for cur := e; cur != nil; cur = cur.outer {
+		if _, ok := cur.store[name]; ok {
+			return nil
+		}
+		if cur.withObj != nil {
+			if has, err := cur.withHas(name); err == nil && has {
+				return cur.withObj
+			}
+		}
+	}
+	return nil
+}
+
+// SetGlobalObject links this environment to a global object so that
+// var/function bindings are mirrored as own properties of the object.
+// Linking it again to the same object does nothing.
+func (e *Environment) SetGlobalObject(obj *Object) {
+	if e.globalObj == obj {
+		return
+	}
+	e.globalObj = obj
+	// Set up bidirectional link
+	if obj.Internal == nil {
+		obj.Internal = make(map[string]interface{})
+	}
+	obj.Internal["globalEnv"] = e
+	// Mirror existing bindings (these are builtins since this runs before user code)
+	for name, binding := range e.store {
+		if binding.Kind == "var" || binding.Kind == "function" {
+			// Builtin bindings are non-enumerable per spec
+			obj.putProperty(name, &Property{
+				Value:        binding.Value,
+				Writable:     true,
+				Enumerable:   false,
+				Configurable: true,
+			})
+		}
+	}
+}
+
+// linkedGlobalEnv returns the environment o is the global object of, if any.
+func (o *Object) linkedGlobalEnv() *Environment {
+	if o.Internal == nil {
+		return nil
+	}
+	env, _ := o.Internal["globalEnv"].(*Environment)
+	return env
+}
+
+// syncGlobal keeps the var or function binding of name, which mirrors the
+// global object's property, in step with the property after it is
+// redefined as prop or, for a nil prop, deleted. A binding whose property
+// is gone or became an accessor is dropped, so that lookups reach the
+// global object.
+func (e *Environment) syncGlobal(name string, prop *Property) {
+	b, ok := e.store[name]
+	if !ok || (b.Kind != "var" && b.Kind != "function") {
+		return
+	}
+	switch {
+	case prop == nil || prop.IsAccessor:
+		delete(e.store, name)
+	case prop.HasValue:
+		b.Value = prop.Value
+	}
+}
+
+// GlobalObject returns the global object if set.
+func (e *Environment) GlobalObject() *Object {
+	return e.globalObj
+}
+
+// Index numbers the binding of name, which must be declared in e, with the
+// next free number, so that Slot finds it without a name lookup. Code
+// whose scopes are resolved ahead of time, as compiled functions are,
+// numbers the bindings of the environments it creates this way.
+func (e *Environment) Index(name string) {
+	e.slots = append(e.slots, e.store[name])
+}
+
+// Slot returns the binding numbered index by Index in the environment depth
+// levels out from e.
+func (e *Environment) Slot(depth, index int) *Binding {
+	for ; depth > 0; depth-- {
+		e = e.outer
+	}
+	return e.slots[index]
+}
+
+// GetBinding returns the binding for a name in the current scope only.
+func (e *Environment) GetBinding(name string) (*Binding, bool) {
+	b, ok := e.store[name]
+	return b, ok
+}
+
+// Declare declares a variable in the current scope.
+func (e *Environment) Declare(name string, kind string, value *Value) error {
+	if kind == "let" || kind == "const" {
+		if existing, exists := e.store[name]; exists {
+			// At global scope, let/const can shadow var bindings (they live in
+			// separate environment records per spec). Only reject if existing
+			// is also a lexical (let/const) binding.
+			if existing.Declared && (existing.Kind == "let" || existing.Kind == "const") {
+				return fmt.Errorf("SyntaxError: Identifier '%s' has already been declared", name)
+			}
+		}
+	}
+	if existing, exists := e.store[name]; exists && !existing.Declared {
+		// Initialize a binding created by DeclareUninitialized in place so
+		// that modules importing it observe the value.
+		existing.Value = value
+		existing.Mutable = kind != "const"
+		existing.Kind = kind
+		existing.Declared = true
+		return nil
+	}
+	e.store[name] = &Binding{
+		Value:    value,
+		Mutable:  kind != "const",
+		Kind:     kind,
+		Declared: true,
+	}
+	// Mirror var/function bindings to global object
+	if e.globalObj != nil && (kind == "var" || kind == "function") {
+		if existing, ok := e.globalObj.Properties[name]; ok {
+			// Only update value; preserve existing configurability
+			existing.Value = value
+		} else {
+			// Declared globals cannot be deleted.
+			e.globalObj.putProperty(name, &Property{
+				Value:      value,
+				Writable:   true,
+				Enumerable: true,
+			})
+		}
+	}
+	return nil
+}
+
+// DeclareUninitialized creates a let, const or class binding that throws a
+// ReferenceError until Declare initializes it. Modules create their lexical
+// bindings this way before linking so imports can refer to them.
+func (e *Environment) DeclareUninitialized(name string, kind string) {
+	if _, exists := e.store[name]; exists {
+		return
+	}
+	e.store[name] = &Binding{
+		Value:   Undefined,
+		Mutable: kind != "const",
+		Kind:    kind,
+	}
+}
+
+// Import creates an immutable binding that reads through to target, the
+// binding exported by another module.
+func (e *Environment) Import(name string, target *Binding) {
+	e.store[name] = &Binding{
+		Kind:     "import",
+		Declared: true,
+		Target:   target,
+	}
+}
+
+// Get retrieves a variable value, walking up the scope chain.
+func (e *Environment) Get(name string) (*Value, error) {
+	if e.withObj != nil {
+		has, err := e.withHas(name)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			return e.withObj.GetErr(name)
+		}
+	}
+	if binding, ok := e.store[name]; ok {
+		for binding.Target != nil {
+			binding = binding.Target
+		}
+		if !binding.Declared {
+			return nil, fmt.Errorf("ReferenceError: Cannot access '%s' before initialization", name)
+		}
+		return binding.Value, nil
+	}
+	if e.outer != nil {
+		return e.outer.Get(name)
+	}
+	// At global scope, fall back to the global object (object environment
+	// record), whose properties include inherited ones and accessors.
+	if e.globalObj != nil && e.globalObj.HasProperty(name) {
+		return e.globalObj.GetErr(name)
+	}
+	return nil, fmt.Errorf("ReferenceError: %s is not defined", name)
+}
+
+// Set updates a variable value in the scope where it was declared.
+func (e *Environment) Set(name string, value *Value) error {
+	if e.withObj != nil {
+		has, err := e.withHas(name)
+		if err != nil {
+			return err
+		}
+		if has {
+			return e.withObj.SetErr(name, value)
+		}
+	}
+	if binding, ok := e.store[name]; ok {
+		if !binding.Mutable {
+			return fmt.Errorf("TypeError: Assignment to constant variable '%s'", name)
+		}
+		binding.Value = value
+		// Mirror to global object
+		if e.globalObj != nil && (binding.Kind == "var" || binding.Kind == "function") {
+			if prop, ok := e.globalObj.Properties[name]; ok {
+				prop.Value = value
+			}
+		}
+		return nil
+	}
+	if e.outer != nil {
+		return e.outer.Set(name, value)
+	}
+	if e.globalObj != nil && e.globalObj.HasProperty(name) {
+		return e.globalObj.SetErr(name, value)
+	}
+	return fmt.Errorf("ReferenceError: %s is not defined", name)
+}
+
+// SetImplicitGlobal creates the global that assigning to an undeclared
+// name creates outside strict mode: a property of the global object, or a
+// var of the outermost scope when it has no global object.
+func (e *Environment) SetImplicitGlobal(name string, value *Value) error {
+	global := e
+	for global.outer != nil {
+		global = global.outer
+	}
+	if global.globalObj != nil {
+		return global.globalObj.SetErr(name, value)
+	}
+	global.SetInCurrentScope(name, value)
+	return nil
+}
+
+// Delete implements the delete operator applied to name. A name that
+// resolves to a property, of a with statement's object or of the global
+// object, deletes it. A declared binding cannot be deleted, except for a
+// global var whose property is configurable, such as a built-in. A name
+// that does not resolve is trivially deleted.
+func (e *Environment) Delete(name string) (bool, error) {
+	for cur := e; cur != nil; cur = cur.outer {
+		if cur.withObj != nil {
+			has, err := cur.withHas(name)
+			if err != nil {
+				return false, err
+			}
+			if has {
+				return cur.withObj.DeleteErr(name)
+			}
+		}
+		if b, ok := cur.store[name]; ok {
+			if cur.globalObj == nil || (b.Kind != "var" && b.Kind != "function") {
+				return false, nil
+			}
+			return cur.globalObj.DeleteErr(name)
+		}
+		if cur.outer == nil && cur.globalObj != nil && cur.globalObj.HasOwnProperty(name) {
+			return cur.globalObj.DeleteErr(name)
+		}
+	}
+	return true, nil
+}
+
+// SetInCurrentScope sets/creates a variable in the current scope (for var
+// hoisting). At global scope, a name that is already a property of the
+// global object, such as one an assignment created, is assigned to it.
+func (e *Environment) SetInCurrentScope(name string, value *Value) {
+	if _, ok := e.store[name]; !ok && e.globalObj != nil && e.globalObj.HasOwnProperty(name) {
+		e.globalObj.Set(name, value)
+		return
+	}
+	if binding, ok := e.store[name]; ok {
+		binding.Value = value
+		// Mirror to global object
+		if e.globalObj != nil {
+			if prop, ok := e.globalObj.Properties[name]; ok {
+				prop.Value = value
+			}
+		}
+		return
+	}
+	e.store[name] = &Binding{
+		Value:    value,
+		Mutable:  true,
+		Kind:     "var",
+		Declared: true,
+	}
+	// Mirror to global object
+	if e.globalObj != nil {
+		e.globalObj.putProperty(name, &Property{
+			Value:      value,
+			Writable:   true,
+			Enumerable: true,
+		})
+	}
+}
+
+// DeclareVar declares a var binding only if the name doesn't already exist in this scope.
+// Used for Annex B block-level function hoisting: the name is hoisted as undefined
+// but must not overwrite existing bindings (var, let, const, or function).
+// Tracks the name as an Annex B hoisted name for runtime propagation checks.
+func (e *Environment) DeclareVar(name string) {
+	e.DeclareVarEx(name, true)
+}
+
+func (e *Environment) DeclareVarEx(name string, configurable bool) {
+	if e.annexBNames == nil {
+		e.annexBNames = make(map[string]bool)
+	}
+	e.annexBNames[name] = true
+	if _, exists := e.store[name]; exists {
+		return
+	}
+	e.store[name] = &Binding{
+		Value:    Undefined,
+		Mutable:  true,
+		Kind:     "var",
+		Declared: true,
+	}
+	// Mirror to global object
+	if e.globalObj != nil {
+		if _, exists := e.globalObj.Properties[name]; !exists {
+			e.globalObj.putProperty(name, &Property{
+				Value:        Undefined,
+				Writable:     true,
+				Enumerable:   true,
+				Configurable: configurable,
+			})
+		}
+	}
+}
+
+// IsAnnexBHoisted returns true if the given name was Annex B hoisted in this scope.
+func (e *Environment) IsAnnexBHoisted(name string) bool {
+	return e.annexBNames != nil && e.annexBNames[name]
+}
+
+// GetFunctionScope walks up to find the nearest function scope (or global).
+func (e *Environment) GetFunctionScope() *Environment {
+	if !e.isBlock {
+		return e
+	}
+	if e.outer != nil {
+		return e.outer.GetFunctionScope()
+	}
+	return e
+}
+
+// HasVarBinding returns true if the given name has a var or function binding in this scope.
+// Used by Annex B to check whether propagating a block function to function scope is safe.
+func (e *Environment) HasVarBinding(name string) bool {
+	if binding, ok := e.store[name]; ok {
+		return binding.Kind == "var" || binding.Kind == "function"
+	}
+	return false
+}
+
+// IsBlock returns true if this is a block scope (not a function/program scope).
+func (e *Environment) IsBlock() bool {
+	return e.isBlock
+}
+
+// HasLexicalInEnclosingBlocks checks whether any block scope between this scope's
+// parent and the target scope (exclusive) has a lexical binding (let/const/function)
+// for the given name. Used to determine if Annex B propagation should be suppressed.
+func (e *Environment) HasLexicalInEnclosingBlocks(name string, target *Environment) bool {
+	for cur := e.outer; cur != nil && cur != target; cur = cur.outer {
+		if !cur.isBlock {
+			break
+		}
+		if binding, ok := cur.store[name]; ok {
+			if binding.Kind == "let" || binding.Kind == "const" || binding.Kind == "function" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ForEachBinding calls fn for each binding in the current scope.
+func (e *Environment) ForEachBinding(fn func(name string, kind string)) {
+	for name, binding := range e.store {
+		fn(name, binding.Kind)
+	}
+}
+
+// Names returns the names visible from e: the bindings of e and every
+// enclosing scope, and the string-keyed properties of with objects and of
+// the global object.
+// The result is sorted and has no duplicates.
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for cur := e; cur != nil; cur = cur.outer {
+		for name := range cur.store {
+			add(name)
+		}
+		for o := cur.withObj; o != nil; o = o.Prototype {
+			for name := range o.Properties {
+				if !IsSymbolKey(name) {
+					add(name)
+				}
+			}
+		}
+		if cur.globalObj != nil {
+			for name := range cur.globalObj.Properties {
+				if !IsSymbolKey(name) {
+					add(name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasBinding returns true if this scope has a binding for the given name.
+func (e *Environment) HasBinding(name string) bool {
+	_, ok := e.store[name]
+	return ok
+}
+
+// SetStrict records whether the code of the function or script this
+// scope, which is not a block scope, belongs to is strict mode code.
+func (e *Environment) SetStrict(strict bool) {
+	e.strict = strict
+}
+
+// IsStrict reports whether code running in e is strict mode code, as
+// recorded by SetStrict on the nearest enclosing function or script scope.
+func (e *Environment) IsStrict() bool {
+	for e.isBlock && e.outer != nil {
+		e = e.outer
+	}
+	return e.strict
+}
+
+// MarkCaptureBoundary makes Capture stop at this scope: closures created
+// below it look its names up when they run instead of capturing bindings.
+// It is for scopes that gain bindings after closures are created in them,
+// through direct eval or module linking.
+func (e *Environment) MarkCaptureBoundary() {
+	e.boundary = true
+}
+
+// Capture returns a compact environment for a closure created in e that can
+// only refer to names. It holds e's bindings for those names, shared rather
+// than copied so assignments stay visible both ways, and its outer scope is
+// the nearest capture boundary or the global scope. Every other binding in between is left
+// out, so the closure does not keep it alive. Bindings for names must already
+// exist when Capture is called; those created later are not seen.
+func (e *Environment) Capture(names []string) *Environment {
+	barrier := e
+	for !barrier.boundary && barrier.outer != nil {
+		barrier = barrier.outer
+	}
+	if barrier == e {
+		return e
+	}
+	flat := NewEnvironment(barrier, false)
+	for _, name := range names {
+		for cur := e; cur != barrier; cur = cur.outer {
+			if b, ok := cur.store[name]; ok {
+				flat.store[name] = b
+				break
+			}
+		}
+	}
+	return flat
+}
+
+// Outer returns the parent environment.
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
@@ -0,0 +1,81 @@
+package runtime
+
+import "testing"
+
+func TestObjectEnvironment(t *testing.T) {
+	saved := SymbolUnscopables
+	SymbolUnscopables = &Symbol{Description: "Symbol.unscopables"}
+	defer func() { SymbolUnscopables = saved }()
+
+	outer := NewEnvironment(nil, false)
+	outer.Declare("a", "var", NewString("outer a"))
+	outer.Declare("b", "var", NewString("outer b"))
+
+	obj := NewOrdinaryObject(nil)
+	obj.Set("a", NewString("obj a"))
+	obj.Set("b", NewString("obj b"))
+	blocked := NewOrdinaryObject(nil)
+	blocked.Set("b", True)
+	obj.Set(SymbolUnscopables.Key(), NewObject(blocked))
+
+	env := NewObjectEnvironment(obj, NewEnvironment(outer, true))
+	if v, _ := env.Get("a"); v.Str != "obj a" {
+		t.Errorf("Get(a) = %q, want the object's property", v.Str)
+	}
+	if v, _ := env.Get("b"); v.Str != "outer b" {
+		t.Errorf("Get(b) = %q, want the outer binding: b is unscopable", v.Str)
+	}
+	if env.WithBase("a") != obj || env.WithBase("b") != nil {
+		t.Error("WithBase should report the object only for a")
+	}
+
+	if err := env.Set("a", NewString("set a")); err != nil {
+		t.Fatal(err)
+	}
+	if v := obj.Get("a"); v.Str != "set a" {
+		t.Errorf("Set(a) should write the object's property, got %q", v.Str)
+	}
+	if v, _ := outer.Get("a"); v.Str != "outer a" {
+		t.Errorf("Set(a) should leave the outer binding alone, got %q", v.Str)
+	}
+
+	// A declaration nested inside the with body shadows the object.
+	inner := NewEnvironment(env, true)
+	inner.Declare("a", "let", NewString("inner a"))
+	if inner.WithBase("a") != nil {
+		t.Error("WithBase should be nil for a name bound by an inner declaration")
+	}
+}
+
+func TestEnvironmentSlots(t *testing.T) {
+	fn := NewEnvironment(nil, false)
+	fn.Declare("a", "var", NewNumber(1))
+	fn.DeclareUninitialized("b", "let")
+	fn.Index("b")
+	fn.Index("a")
+	block := NewEnvironment(fn, true)
+	block.Declare("c", "const", NewNumber(3))
+	block.Index("c")
+
+	if b := block.Slot(0, 0); b.Value.Number != 3 {
+		t.Errorf("Slot(0, 0) = %v, want c", b.Value)
+	}
+	if b := block.Slot(1, 1); b.Value.Number != 1 {
+		t.Errorf("Slot(1, 1) = %v, want a", b.Value)
+	}
+	// Slots are the bindings themselves, shared with lookups by name.
+	b := block.Slot(1, 0)
+	if b.Declared {
+		t.Error("b should be uninitialized")
+	}
+	if err := fn.Declare("b", "let", NewNumber(2)); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Declared || b.Value.Number != 2 {
+		t.Errorf("Slot(1, 0) after initializing b = %v", b.Value)
+	}
+	b.Value = NewNumber(5)
+	if v, _ := block.Get("b"); v.Number != 5 {
+		t.Errorf("Get(b) = %v after a write through the slot", v)
+	}
+}
@@ -0,0 +1,48 @@
+package runtime
+
+import "fmt"
+
+// Approximate sizes, in bytes, that allocations are charged to a heap
+// budget. They are in the order of what the Go structures cost and only
+// need to make the budget proportional to what a script allocates.
+const (
+	ObjectSize   = 128 // an object with its property map
+	PropertySize = 64  // a property added to an object
+	SlotSize     = 16  // an array element
+)
+
+// HeapBudget caps the memory a script may allocate, so that an untrusted
+// script cannot exhaust the memory of the host. Allocations are counted
+// as they happen and the garbage collector gives nothing back: the budget
+// limits the total a script allocates, not what it holds live.
+type HeapBudget struct {
+	Limit int64 // the number of bytes the script may allocate
+	used  int64
+}
+
+// Used returns the number of bytes charged to b so far.
+func (b *HeapBudget) Used() int64 {
+	return b.used
+}
+
+// Charge counts an allocation of n bytes against the heap budget of a. An
+// allocation that does not fit is refused with a RangeError and is not
+// counted, so a script that catches the error can still make smaller
+// allocations.
+func (a *Agent) Charge(n int64) error {
+	b := a.Heap
+	if b == nil || n <= 0 {
+		return nil
+	}
+	if n > b.Limit-b.used {
+		return fmt.Errorf("RangeError: heap limit exceeded")
+	}
+	b.used += n
+	return nil
+}
+
+// ChargeSlots counts an allocation of n array elements against the heap
+// budget of a.
+func (a *Agent) ChargeSlots(n int) error {
+	return a.Charge(int64(n) * SlotSize)
+}
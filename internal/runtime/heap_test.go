@@ -0,0 +1,24 @@
+package runtime
+
+import "testing"
+
+func TestCharge(t *testing.T) {
+	a := NewAgent()
+	if err := a.Charge(1 << 40); err != nil {
+		t.Fatalf("without a budget: %v", err)
+	}
+
+	a.Heap = &HeapBudget{Limit: 100}
+	if err := a.ChargeSlots(4); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Charge(50); err == nil || err.Error() != "RangeError: heap limit exceeded" {
+		t.Errorf("over the budget: unexpected error %v", err)
+	}
+	if a.Heap.Used() != 4*SlotSize {
+		t.Errorf("a refused allocation should not count: used %d", a.Heap.Used())
+	}
+	if err := a.Charge(100 - 4*SlotSize); err != nil {
+		t.Errorf("filling the budget exactly: %v", err)
+	}
+}
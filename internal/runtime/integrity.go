@@ -0,0 +1,164 @@
+package runtime
+
+// PreventExtensions makes o non-extensible: assignments no longer add
+// properties to it. There is no way back.
+func (o *Object) PreventExtensions() {
+	o.PreventExtensionsErr()
+}
+
+// PreventExtensionsErr is PreventExtensions, but reports whether o became
+// non-extensible: a proxy asks its preventExtensions trap, which may
+// refuse, and reports an error the trap throws.
+func (o *Object) PreventExtensionsErr() (bool, error) {
+	if o.OType == ObjTypeProxy {
+		return o.proxyPreventExtensions()
+	}
+	o.nonExtensible = true
+	return true, nil
+}
+
+// IsExtensible reports whether new properties can be added to o.
+func (o *Object) IsExtensible() bool {
+	ok, _ := o.IsExtensibleErr()
+	return ok
+}
+
+// IsExtensibleErr is IsExtensible, but reports an error thrown by a
+// proxy's isExtensible trap instead of dropping it.
+func (o *Object) IsExtensibleErr() (bool, error) {
+	if o.OType == ObjTypeProxy {
+		return o.proxyIsExtensible()
+	}
+	return !o.nonExtensible, nil
+}
+
+// SetPrototypeOf makes proto, which may be nil, the prototype of o, and
+// reports whether it did: the prototype of a non-extensible object cannot
+// change, and o cannot become its own ancestor. A proxy in the chain ends
+// the search for o, as it does in the spec.
+func (o *Object) SetPrototypeOf(proto *Object) bool {
+	if proto == o.Prototype {
+		return true
+	}
+	if o.nonExtensible {
+		return false
+	}
+	for p := proto; p != nil && p.OType != ObjTypeProxy; p = p.Prototype {
+		if p == o {
+			return false
+		}
+	}
+	o.Prototype = proto
+	return true
+}
+
+// Seal makes o non-extensible and all of its own properties, including
+// array elements, non-configurable.
+func (o *Object) Seal() {
+	o.nonExtensible = true
+	o.sealedElements = true
+	for _, p := range o.Properties {
+		p.Configurable = false
+	}
+}
+
+// Freeze seals o and also makes its own data properties and array elements
+// read-only.
+func (o *Object) Freeze() {
+	o.Seal()
+	o.frozenElements = true
+	for _, p := range o.Properties {
+		if !p.IsAccessor {
+			p.Writable = false
+		}
+	}
+}
+
+// IsSealed reports whether o is non-extensible and has no configurable own
+// properties.
+func (o *Object) IsSealed() bool {
+	return o.testIntegrity(false)
+}
+
+// IsFrozen reports whether o is sealed and has no writable own data
+// properties.
+func (o *Object) IsFrozen() bool {
+	return o.testIntegrity(true)
+}
+
+func (o *Object) testIntegrity(frozen bool) bool {
+	if !o.nonExtensible {
+		return false
+	}
+	if len(o.ArrayData) > 0 && (!o.sealedElements || (frozen && !o.frozenElements)) {
+		return false
+	}
+	for _, p := range o.Properties {
+		if p.Configurable || (frozen && !p.IsAccessor && p.Writable) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanSetElement reports whether an assignment to array element i of o
+// takes effect: frozen elements are read-only, and a non-extensible array,
+// or one whose length is read-only, cannot grow.
+func (o *Object) CanSetElement(i int) bool {
+	if i < len(o.ArrayData) && o.ArrayData[i] != nil {
+		return !o.frozenElements
+	}
+	if o.nonExtensible {
+		return false
+	}
+	if i >= o.ArrayLength() {
+		if prop := o.Properties["length"]; prop != nil && !prop.Writable {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete removes the own property name of o and reports whether it is gone.
+// Non-configurable properties and the elements of a sealed array are kept.
+// A deleted array element leaves a hole.
+func (o *Object) Delete(name string) bool {
+	ok, _ := o.DeleteErr(name)
+	return ok
+}
+
+// DeleteErr is Delete, but reports an error thrown by a proxy's
+// deleteProperty trap instead of dropping it.
+func (o *Object) DeleteErr(name string) (bool, error) {
+	if o.OType == ObjTypeProxy {
+		return o.proxyDelete(name)
+	}
+	if len(o.ArrayData) > 0 {
+		if n, ok := arrayIndex(name); ok && int(n) < len(o.ArrayData) && o.ArrayData[n] != nil {
+			if o.sealedElements {
+				return false, nil
+			}
+			o.ArrayData[n] = nil
+			return true, nil
+		}
+	}
+	prop, ok := o.Properties[name]
+	if !ok {
+		return true, nil
+	}
+	if !prop.Configurable {
+		return false, nil
+	}
+	o.unmapArgument(name)
+	delete(o.Properties, name)
+	if env := o.linkedGlobalEnv(); env != nil {
+		env.syncGlobal(name, nil)
+	}
+	return true, nil
+}
+
+// elementAttributes returns the attributes OwnProperty reports for the
+// array elements of o.
+func (o *Object) elementAttributes(v *Value) *Property {
+	return &Property{Value: v, Writable: !o.frozenElements, Enumerable: true, Configurable: !o.sealedElements}
+}
@@ -0,0 +1,92 @@
+package runtime
+
+import "testing"
+
+func TestIntegrityLevels(t *testing.T) {
+	obj := NewOrdinaryObject(nil)
+	obj.Set("a", NewNumber(1))
+	obj.PreventExtensions()
+	obj.Set("b", NewNumber(2))
+	if obj.HasOwnProperty("b") {
+		t.Error("a non-extensible object gained a property")
+	}
+	if obj.IsSealed() {
+		t.Error("a non-extensible object with a configurable property is not sealed")
+	}
+	if !obj.Delete("a") || obj.HasOwnProperty("a") {
+		t.Error("configurable properties of a non-extensible object can be deleted")
+	}
+	if !obj.IsFrozen() {
+		t.Error("an empty non-extensible object is frozen")
+	}
+
+	sealed := NewOrdinaryObject(nil)
+	sealed.Set("a", NewNumber(1))
+	sealed.Seal()
+	sealed.Set("a", NewNumber(2))
+	if sealed.Delete("a") || sealed.Get("a").Number != 2 {
+		t.Error("a sealed object keeps its properties but they stay writable")
+	}
+	if !sealed.IsSealed() || sealed.IsFrozen() {
+		t.Error("IsSealed/IsFrozen of a sealed object")
+	}
+
+	frozen := NewOrdinaryObject(nil)
+	frozen.Set("a", NewNumber(1))
+	frozen.Freeze()
+	frozen.Set("a", NewNumber(2))
+	if frozen.Get("a").Number != 1 || !frozen.IsFrozen() {
+		t.Error("a frozen object's properties are read-only")
+	}
+	// Assigning to an object inheriting a frozen property is blocked too.
+	child := NewOrdinaryObject(frozen)
+	child.Set("a", NewNumber(3))
+	if child.HasOwnProperty("a") {
+		t.Error("an inherited read-only property blocks assignment")
+	}
+}
+
+func TestArrayIntegrity(t *testing.T) {
+	arr := NewArrayObject(nil, []*Value{NewNumber(1), NewNumber(2)})
+	if !arr.CanSetElement(0) || !arr.CanSetElement(5) {
+		t.Fatal("elements of an ordinary array are writable")
+	}
+	if !arr.Delete("0") || arr.ArrayData[0] != nil {
+		t.Error("deleting an element leaves a hole")
+	}
+
+	arr.Seal()
+	if arr.CanSetElement(2) || !arr.CanSetElement(1) || arr.Delete("1") {
+		t.Error("a sealed array cannot grow or lose elements but stays writable")
+	}
+	if p := OwnProperty(arr, "1"); p.Configurable || !p.Writable {
+		t.Errorf("element of a sealed array: %+v", p)
+	}
+
+	arr.Freeze()
+	if arr.CanSetElement(1) || !arr.IsFrozen() {
+		t.Error("elements of a frozen array are read-only")
+	}
+	if p := OwnProperty(arr, "1"); p.Writable {
+		t.Errorf("element of a frozen array: %+v", p)
+	}
+}
+
+func TestSetPrototypeOf(t *testing.T) {
+	a := NewOrdinaryObject(nil)
+	b := NewOrdinaryObject(a)
+	c := NewOrdinaryObject(b)
+	if a.SetPrototypeOf(c) || a.SetPrototypeOf(a) || a.Prototype != nil {
+		t.Error("an object cannot become its own ancestor")
+	}
+	if !c.SetPrototypeOf(a) || c.Prototype != a {
+		t.Error("SetPrototypeOf(a) should succeed")
+	}
+	c.PreventExtensions()
+	if c.SetPrototypeOf(nil) || c.Prototype != a {
+		t.Error("the prototype of a non-extensible object cannot change")
+	}
+	if !c.SetPrototypeOf(a) {
+		t.Error("setting the same prototype again should succeed")
+	}
+}
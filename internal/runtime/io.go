@@ -0,0 +1,19 @@
+package runtime
+
+import (
+	"io"
+	"os"
+)
+
+// Streams are the standard streams of a script: console writes to Stdout
+// and Stderr, and builtins that read input read Stdin.
+type Streams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// StandardStreams returns the standard streams of the process.
+func StandardStreams() *Streams {
+	return &Streams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+}
@@ -0,0 +1,83 @@
+package runtime
+
+import "errors"
+
+// Job is a unit of work on the microtask queue, such as a promise reaction
+// or a queueMicrotask callback. The error of a job is an exception it did
+// not catch.
+type Job func() error
+
+// EventQueue is the microtask queue and the timers of one event loop.
+// Each agent has its own, so that the jobs and timers a script schedules
+// run in the loop of its agent and no other.
+type EventQueue struct {
+	jobs     []Job
+	timers   map[int]*timer
+	timerID  int
+	timerSeq int
+}
+
+// NewEventQueue returns an empty event queue.
+func NewEventQueue() *EventQueue {
+	return &EventQueue{timers: make(map[int]*timer)}
+}
+
+// EnqueueJob appends job to the microtask queue of q.
+func (q *EventQueue) EnqueueJob(job Job) {
+	q.jobs = append(q.jobs, job)
+}
+
+// RunJobs drains the microtask queue, including any jobs enqueued by the
+// jobs it runs. Hosts call it once the current script has finished. A job
+// that fails stops the drain and RunJobs returns its error; the jobs after
+// it stay queued for the next drain.
+func (q *EventQueue) RunJobs() error {
+	for len(q.jobs) > 0 {
+		job := q.jobs[0]
+		q.jobs[0] = nil
+		q.jobs = q.jobs[1:]
+		if err := job(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PendingJobs reports how many jobs are waiting on the microtask queue of
+// q.
+func (q *EventQueue) PendingJobs() int {
+	return len(q.jobs)
+}
+
+// Exception is a Go error carrying a thrown JS value. Native functions return
+// one to throw an arbitrary value rather than a TypeError-style message.
+type Exception struct {
+	Value *Value
+}
+
+// Throw returns an error that throws v when it reaches JS code.
+func Throw(v *Value) error {
+	return &Exception{Value: v}
+}
+
+func (e *Exception) Error() string {
+	if e.Value == nil {
+		return "undefined"
+	}
+	return e.Value.ToString()
+}
+
+// ThrownValue returns the JS value carried by the exception.
+func (e *Exception) ThrownValue() *Value {
+	return e.Value
+}
+
+// ThrownValue extracts the JS value carried by err, if err (or an error it
+// wraps) was produced by a JS throw.
+func ThrownValue(err error) (*Value, bool) {
+	var t interface{ ThrownValue() *Value }
+	if errors.As(err, &t) {
+		return t.ThrownValue(), true
+	}
+	return nil, false
+}
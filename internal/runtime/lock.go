@@ -0,0 +1,72 @@
+package runtime
+
+import "context"
+
+// The scripts of an agent run one at a time. A host that uses an agent
+// from several goroutines holds its execution lock around every use of its
+// interpreters and of the values they made, as the jsgo package does.
+// Different agents have different locks, and their scripts run in
+// parallel.
+
+// Lock acquires the execution lock of a, waiting until no other goroutine
+// holds it. It is not reentrant: code running under the lock only calls
+// back into a locking API from within Unlocked.
+func (a *Agent) Lock() {
+	a.sem <- struct{}{}
+	a.held.Store(true)
+}
+
+// LockContext is Lock, except that it gives up waiting once ctx is done
+// and returns ctx.Err().
+func (a *Agent) LockContext(ctx context.Context) error {
+	select {
+	case a.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	a.held.Store(true)
+	return nil
+}
+
+// Unlock releases the execution lock of a.
+func (a *Agent) Unlock() {
+	a.held.Store(false)
+	<-a.sem
+}
+
+// SaveState registers state of the running script that Unlocked must keep
+// for it: save is called before the lock is released, and resets the state
+// for the scripts that may run meanwhile, and the function it returns is
+// called once the lock is taken again. Interpreters register their frames
+// and the limits of the evaluation they run.
+func (a *Agent) SaveState(save func() (restore func())) {
+	a.states = append(a.states, save)
+}
+
+// Unlocked calls fn, a call out of a script into host code, with the
+// execution lock released, so that fn may run scripts of its own and other
+// goroutines may run theirs on a meanwhile. The state of the running
+// script is saved before and restored after, see SaveState, so that the
+// scripts run meanwhile neither see nor change it: they are not charged to
+// its Heap, for one. Without the lock held, fn is simply called.
+func (a *Agent) Unlocked(fn func()) {
+	if !a.held.Load() {
+		fn()
+		return
+	}
+	io, heap, capture, construct := a.IO, a.Heap, a.CaptureStack, a.ConstructHook
+	restores := make([]func(), len(a.states))
+	for i, save := range a.states {
+		restores[i] = save()
+	}
+	a.Heap = nil
+	a.Unlock()
+	defer func() {
+		a.Lock()
+		a.IO, a.Heap, a.CaptureStack, a.ConstructHook = io, heap, capture, construct
+		for _, restore := range restores {
+			restore()
+		}
+	}()
+	fn()
+}
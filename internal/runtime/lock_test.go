@@ -0,0 +1,62 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUnlocked(t *testing.T) {
+	a := NewAgent()
+	ran := false
+	a.Unlocked(func() { ran = true })
+	if !ran {
+		t.Fatal("Unlocked without the lock held calls fn")
+	}
+
+	var out bytes.Buffer
+	state := "outer"
+	a.SaveState(func() func() {
+		saved := state
+		return func() { state = saved }
+	})
+	a.Lock()
+	a.IO = &Streams{Stdout: &out}
+	budget := &HeapBudget{Limit: 100}
+	a.Heap = budget
+	a.Unlocked(func() {
+		// Another goroutine's script may run here and change the state.
+		a.Lock()
+		if a.Heap != nil {
+			t.Error("the scripts run meanwhile are not charged to the heap budget")
+		}
+		a.IO = StandardStreams()
+		state = "inner"
+		a.Unlock()
+	})
+	if a.IO.Stdout != &out || a.Heap != budget || state != "outer" {
+		t.Error("Unlocked restores the state of the running script")
+	}
+	a.Unlock()
+}
+
+func TestLockContext(t *testing.T) {
+	a := NewAgent()
+	a.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.LockContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("LockContext while the lock is held: got %v", err)
+	}
+	other := NewAgent()
+	if err := other.LockContext(context.Background()); err != nil {
+		t.Errorf("the lock of another agent is free: got %v", err)
+	}
+	other.Unlock()
+	a.Unlock()
+	if err := a.LockContext(context.Background()); err != nil {
+		t.Fatalf("LockContext on a free lock: %v", err)
+	}
+	a.Unlock()
+}
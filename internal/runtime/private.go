@@ -0,0 +1,106 @@
+package runtime
+
+import "fmt"
+
+// NewPrivateName creates the private name of a class element such as #x.
+// It is a symbol that is never handed to scripts: every evaluation of a
+// class body creates its own names, reachable only through the class's
+// scope, so code outside the class cannot name its private elements.
+func NewPrivateName(desc string) *Value {
+	return &Value{Type: TypeSymbol, Symbol: &Symbol{Description: desc, private: true}}
+}
+
+// IsPrivate reports whether s is a private name.
+func (s *Symbol) IsPrivate() bool {
+	return s.private
+}
+
+// PrivateNameForKey returns the private name whose Key is key, or nil if
+// key does not name one.
+func PrivateNameForKey(key string) *Symbol {
+	if !IsSymbolKey(key) {
+		return nil
+	}
+	sym, ok := symbolsByKey.Load(key)
+	if !ok || !sym.(*Symbol).private {
+		return nil
+	}
+	return sym.(*Symbol)
+}
+
+// privateElements returns the private fields, methods and accessors of o,
+// which are kept apart from its properties: they are not inherited and no
+// reflection or proxy trap sees them.
+func (o *Object) privateElements() map[*Symbol]*Property {
+	elements, _ := o.Internal["privateElements"].(map[*Symbol]*Property)
+	return elements
+}
+
+// HasPrivate reports whether o has the private element name, as #x in o
+// checks.
+func (o *Object) HasPrivate(name *Symbol) bool {
+	return o.privateElements()[name] != nil
+}
+
+// AddPrivate adds the private element name to o when a class initializes
+// an instance. Adding the same name twice is a TypeError, which happens
+// when a constructor returns an object that was already initialized.
+func (o *Object) AddPrivate(name *Symbol, prop *Property) error {
+	elements := o.privateElements()
+	if elements == nil {
+		if o.Internal == nil {
+			o.Internal = make(map[string]interface{})
+		}
+		elements = make(map[*Symbol]*Property)
+		o.Internal["privateElements"] = elements
+	}
+	if elements[name] != nil {
+		return fmt.Errorf("TypeError: Cannot initialize %s twice on the same object", name.Description)
+	}
+	elements[name] = prop
+	return nil
+}
+
+// GetPrivate reads the private element name of o, calling its getter with o
+// as this for an accessor.
+func (o *Object) GetPrivate(name *Symbol) (*Value, error) {
+	prop := o.privateElements()[name]
+	if prop == nil {
+		return nil, fmt.Errorf("TypeError: Cannot read private member %s from an object whose class did not declare it", name.Description)
+	}
+	if !prop.IsAccessor {
+		return prop.Value, nil
+	}
+	if prop.Getter == nil || prop.Getter.Type != TypeObject || prop.Getter.Object == nil || prop.Getter.Object.Callable == nil {
+		return nil, fmt.Errorf("TypeError: '%s' was defined without a getter", name.Description)
+	}
+	val, err := prop.Getter.Object.Callable(NewObject(o), nil)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		return Undefined, nil
+	}
+	return val, nil
+}
+
+// SetPrivate assigns the private element name of o, calling its setter
+// with o as this for an accessor. Private methods cannot be assigned.
+func (o *Object) SetPrivate(name *Symbol, val *Value) error {
+	prop := o.privateElements()[name]
+	if prop == nil {
+		return fmt.Errorf("TypeError: Cannot write private member %s to an object whose class did not declare it", name.Description)
+	}
+	if !prop.IsAccessor {
+		if !prop.Writable {
+			return fmt.Errorf("TypeError: Private method %s is not writable", name.Description)
+		}
+		prop.Value = val
+		return nil
+	}
+	if prop.Setter == nil || prop.Setter.Type != TypeObject || prop.Setter.Object == nil || prop.Setter.Object.Callable == nil {
+		return fmt.Errorf("TypeError: '%s' was defined without a setter", name.Description)
+	}
+	_, err := prop.Setter.Object.Callable(NewObject(o), []*Value{val})
+	return err
+}
@@ -0,0 +1,47 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrivateElements(t *testing.T) {
+	name := NewPrivateName("#x").Symbol
+	obj := NewOrdinaryObject(nil)
+	if obj.HasPrivate(name) {
+		t.Fatal("new object should not have #x")
+	}
+	if _, err := obj.GetPrivate(name); err == nil || !strings.Contains(err.Error(), "did not declare it") {
+		t.Errorf("GetPrivate before AddPrivate: unexpected error %v", err)
+	}
+	if err := obj.AddPrivate(name, &Property{Value: NewNumber(1), Writable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := obj.AddPrivate(name, &Property{Value: NewNumber(2)}); err == nil {
+		t.Error("adding #x twice should fail")
+	}
+	if err := obj.SetPrivate(name, NewNumber(3)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := obj.GetPrivate(name); v.Number != 3 {
+		t.Errorf("GetPrivate: got %v, want 3", v.Number)
+	}
+
+	// Private elements are not properties, and their names only resolve
+	// back from keys for private names.
+	if len(OwnKeys(obj)) != 0 || obj.HasProperty(name.Key()) {
+		t.Error("private elements should not be visible as properties")
+	}
+	if PrivateNameForKey(name.Key()) != name {
+		t.Error("PrivateNameForKey should find #x")
+	}
+	if PrivateNameForKey((&Symbol{Description: "#x"}).Key()) != nil || PrivateNameForKey("#x") != nil {
+		t.Error("PrivateNameForKey should ignore symbols and strings")
+	}
+
+	method := NewPrivateName("#m").Symbol
+	obj.AddPrivate(method, &Property{Value: NewNumber(0)})
+	if err := obj.SetPrivate(method, NewNumber(1)); err == nil || !strings.Contains(err.Error(), "not writable") {
+		t.Errorf("SetPrivate on a method: unexpected error %v", err)
+	}
+}
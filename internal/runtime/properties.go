@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// propertySeq numbers property insertions so that own keys can be listed in
+// creation order even though Properties is an unordered map.
+var propertySeq atomic.Uint64
+
+// putProperty stores prop under name. A key that is already present keeps
+// its position in the key order; a new key goes after all existing ones.
+func (o *Object) putProperty(name string, prop *Property) {
+	if old, ok := o.Properties[name]; ok {
+		prop.seq = old.seq
+	} else {
+		prop.seq = propertySeq.Add(1)
+	}
+	o.Properties[name] = prop
+}
+
+// IsSymbolKey reports whether a property key names a symbol. Symbol keys
+// are stored under "@@" names (see Symbol.Key).
+func IsSymbolKey(key string) bool {
+	return strings.HasPrefix(key, "@@")
+}
+
+// PropertyFilter selects the properties visited by OwnPropertyIterator.
+type PropertyFilter func(key string, prop *Property) bool
+
+// EnumerableKeys selects enumerable properties, including symbol keys.
+func EnumerableKeys(key string, prop *Property) bool {
+	return prop.Enumerable
+}
+
+// EnumerableStringKeys selects enumerable string-keyed properties: the keys
+// seen by Object.keys, object spread, JSON.stringify and for-in.
+func EnumerableStringKeys(key string, prop *Property) bool {
+	return prop.Enumerable && !IsSymbolKey(key)
+}
+
+// StringKeys selects string-keyed properties regardless of enumerability.
+func StringKeys(key string, prop *Property) bool {
+	return !IsSymbolKey(key)
+}
+
+// arrayIndex returns the value of key if it is a canonical array index.
+func arrayIndex(key string) (uint32, bool) {
+	if key == "" || len(key) > 10 || (len(key) > 1 && key[0] == '0') {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(key, 10, 32)
+	if err != nil || n == 1<<32-1 {
+		return 0, false
+	}
+	return uint32(n), true
+}
+
+// OwnKeys returns the own property keys of obj in [[OwnPropertyKeys]]
+// order: array indices ascending, then string keys in insertion order, then
+// symbol keys in insertion order. A proxy lists the keys its ownKeys trap
+// returns.
+func OwnKeys(obj *Object) []string {
+	keys, _ := OwnKeysErr(obj)
+	return keys
+}
+
+// OwnKeysErr is OwnKeys, but reports an error thrown by a proxy's ownKeys
+// trap instead of dropping it.
+func OwnKeysErr(obj *Object) ([]string, error) {
+	if obj.OType == ObjTypeProxy {
+		return obj.proxyOwnKeys()
+	}
+	type indexKey struct {
+		key string
+		n   uint32
+	}
+	var indices []indexKey
+	for i, v := range obj.ArrayData {
+		if v != nil {
+			indices = append(indices, indexKey{strconv.Itoa(i), uint32(i)})
+		}
+	}
+	var strs, syms []string
+	for k := range obj.Properties {
+		if n, ok := arrayIndex(k); ok {
+			if int(n) < len(obj.ArrayData) && obj.ArrayData[n] != nil {
+				continue
+			}
+			indices = append(indices, indexKey{k, n})
+		} else if IsSymbolKey(k) {
+			syms = append(syms, k)
+		} else {
+			strs = append(strs, k)
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].n < indices[j].n })
+	byInsertion := func(keys []string) {
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := obj.Properties[keys[i]], obj.Properties[keys[j]]
+			if a.seq != b.seq {
+				return a.seq < b.seq
+			}
+			return keys[i] < keys[j]
+		})
+	}
+	byInsertion(strs)
+	byInsertion(syms)
+
+	keys := make([]string, 0, len(indices)+len(strs)+len(syms))
+	for _, ik := range indices {
+		keys = append(keys, ik.key)
+	}
+	keys = append(keys, strs...)
+	return append(keys, syms...), nil
+}
+
+// OwnProperty returns the own property of obj named key, or nil. Array
+// elements are reported as enumerable data properties, writable and
+// configurable unless the array is frozen or sealed, and an array's length
+// as non-enumerable and non-configurable. A proxy reports the property of
+// its target.
+func OwnProperty(obj *Object, key string) *Property {
+	if obj.OType == ObjTypeProxy {
+		if target := obj.ProxyTarget(); target != nil {
+			return OwnProperty(target, key)
+		}
+		return nil
+	}
+	if obj.OType == ObjTypeArray || len(obj.ArrayData) > 0 {
+		if n, ok := arrayIndex(key); ok && int(n) < len(obj.ArrayData) && obj.ArrayData[n] != nil {
+			return obj.elementAttributes(obj.ArrayData[n])
+		}
+	}
+	prop, ok := obj.Properties[key]
+	if !ok {
+		return nil
+	}
+	if b := obj.mappedArgument(key); b != nil {
+		element := *prop
+		element.Value = b.Value
+		return &element
+	}
+	if obj.OType == ObjTypeArray && key == "length" && (prop.Enumerable || prop.Configurable) {
+		length := *prop
+		length.Enumerable = false
+		length.Configurable = false
+		return &length
+	}
+	return prop
+}
+
+// OwnPropertyIterator yields the own properties of obj selected by filter
+// (all of them when filter is nil) in OwnKeys order. The keys are taken up
+// front; each property is looked up again when its turn comes, so one that
+// is deleted during iteration is skipped and one that is changed is seen
+// in its new state.
+func OwnPropertyIterator(obj *Object, filter PropertyFilter) iter.Seq2[string, *Property] {
+	return func(yield func(string, *Property) bool) {
+		for _, key := range OwnKeys(obj) {
+			prop := OwnProperty(obj, key)
+			if prop == nil || (filter != nil && !filter(key, prop)) {
+				continue
+			}
+			if !yield(key, prop) {
+				return
+			}
+		}
+	}
+}
+
+// GetValue returns the value of prop as read through receiver, calling the
+// getter of an accessor property.
+func (p *Property) GetValue(receiver *Object) *Value {
+	if !p.IsAccessor {
+		if p.Value == nil {
+			return Undefined
+		}
+		return p.Value
+	}
+	if p.Getter == nil || p.Getter.Object == nil || p.Getter.Object.Callable == nil {
+		return Undefined
+	}
+	val, _ := p.Getter.Object.Callable(NewObject(receiver), nil)
+	return val
+}
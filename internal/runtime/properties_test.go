@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOwnKeysOrder(t *testing.T) {
+	obj := NewOrdinaryObject(nil)
+	for _, k := range []string{"b", "@@sym(s)@1", "2", "a", "01", "4294967295", "1"} {
+		obj.Set(k, NewNumber(1))
+	}
+	want := []string{"1", "2", "b", "a", "01", "4294967295", "@@sym(s)@1"}
+	if got := OwnKeys(obj); !slices.Equal(got, want) {
+		t.Errorf("OwnKeys: got %v, want %v", got, want)
+	}
+
+	// Deleting and re-adding a key moves it to the end.
+	delete(obj.Properties, "b")
+	obj.Set("b", NewNumber(2))
+	obj.DefineProperty("a", &Property{Value: NewNumber(3)})
+	want = []string{"1", "2", "a", "01", "4294967295", "b", "@@sym(s)@1"}
+	if got := OwnKeys(obj); !slices.Equal(got, want) {
+		t.Errorf("OwnKeys after redefinition: got %v, want %v", got, want)
+	}
+}
+
+func TestOwnPropertyIterator(t *testing.T) {
+	arr := NewArrayObject(nil, []*Value{NewString("x"), NewString("y")})
+	arr.Set("extra", NewNumber(1))
+	arr.DefineProperty("hidden", &Property{Value: NewNumber(2)})
+
+	var keys []string
+	for k, prop := range OwnPropertyIterator(arr, EnumerableStringKeys) {
+		keys = append(keys, k)
+		if k == "1" && prop.Value.Str != "y" {
+			t.Errorf("element 1: got %v, want y", prop.Value)
+		}
+	}
+	if want := []string{"0", "1", "extra"}; !slices.Equal(keys, want) {
+		t.Errorf("enumerable keys: got %v, want %v", keys, want)
+	}
+
+	keys = nil
+	for k := range OwnPropertyIterator(arr, nil) {
+		keys = append(keys, k)
+	}
+	if want := []string{"0", "1", "length", "extra", "hidden"}; !slices.Equal(keys, want) {
+		t.Errorf("all keys: got %v, want %v", keys, want)
+	}
+
+	// Properties deleted mid-iteration are skipped.
+	obj := NewOrdinaryObject(nil)
+	obj.Set("a", NewNumber(1))
+	obj.Set("b", NewNumber(2))
+	keys = nil
+	for k := range OwnPropertyIterator(obj, EnumerableKeys) {
+		keys = append(keys, k)
+		delete(obj.Properties, "b")
+	}
+	if want := []string{"a"}; !slices.Equal(keys, want) {
+		t.Errorf("keys with deletion: got %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,295 @@
+package runtime
+
+import (
+	"fmt"
+	"sync"
+)
+
+// symbolsByKey maps the property keys handed out by Symbol.Key back to
+// their symbols, so that a key can be passed to JavaScript code as a value.
+var symbolsByKey sync.Map
+
+// KeyToValue returns a property key as a JavaScript value: the symbol for
+// a symbol key, and a string otherwise.
+func KeyToValue(key string) *Value {
+	if IsSymbolKey(key) {
+		if sym, ok := symbolsByKey.Load(key); ok {
+			return &Value{Type: TypeSymbol, Symbol: sym.(*Symbol)}
+		}
+	}
+	return NewString(key)
+}
+
+// ProxyTarget returns the target of a proxy object, or nil if o is not a
+// proxy.
+func (o *Object) ProxyTarget() *Object {
+	if o.OType != ObjTypeProxy {
+		return nil
+	}
+	target, _ := o.Internal["target"].(*Object)
+	return target
+}
+
+// IsArray implements the IsArray abstract operation: a proxy is an array
+// when its target is one, and asking a revoked proxy throws.
+func IsArray(v *Value) (bool, error) {
+	if v.Type != TypeObject || v.Object == nil {
+		return false, nil
+	}
+	o := v.Object
+	for o.OType == ObjTypeProxy {
+		if o = o.ProxyTarget(); o == nil {
+			return false, fmt.Errorf("TypeError: Cannot perform 'IsArray' on a proxy that has been revoked")
+		}
+	}
+	return o.OType == ObjTypeArray, nil
+}
+
+// GetPrototypeOfErr returns the prototype of o, or nil if it has none. A
+// proxy asks its getPrototypeOf trap, which must return an object or null,
+// or else its target.
+func (o *Object) GetPrototypeOfErr() (*Object, error) {
+	if o.OType != ObjTypeProxy {
+		return o.Prototype, nil
+	}
+	target, trap, handler, err := o.ProxyTrap("getPrototypeOf")
+	if err != nil {
+		return nil, err
+	}
+	if trap == nil {
+		return target.GetPrototypeOfErr()
+	}
+	result, err := trap(handler, []*Value{NewObject(target)})
+	if err != nil {
+		return nil, err
+	}
+	var proto *Object
+	switch {
+	case result != nil && result.Type == TypeNull:
+	case result != nil && result.Type == TypeObject && result.Object != nil:
+		proto = result.Object
+	default:
+		return nil, fmt.Errorf("TypeError: proxy trap 'getPrototypeOf' returned neither object nor null")
+	}
+	// A non-extensible target fixes its prototype.
+	if !target.IsExtensible() && proto != target.Prototype {
+		return nil, fmt.Errorf("TypeError: 'getPrototypeOf' on proxy: proxy target is non-extensible but the trap did not return its actual prototype")
+	}
+	return proto, nil
+}
+
+// ProxyTrap looks up the trap called name on the handler of the proxy o.
+// It returns the target, and a nil trap when the handler does not define
+// one, in which case the operation is forwarded to the target.
+func (o *Object) ProxyTrap(name string) (target *Object, trap CallableFunc, handler *Value, err error) {
+	target, _ = o.Internal["target"].(*Object)
+	h, _ := o.Internal["handler"].(*Object)
+	if target == nil || h == nil {
+		return nil, nil, nil, fmt.Errorf("TypeError: Cannot perform '%s' on a proxy that has been revoked", name)
+	}
+	method, err := h.GetErr(name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if method == nil || method.Type == TypeUndefined || method.Type == TypeNull {
+		return target, nil, nil, nil
+	}
+	if method.Type != TypeObject || method.Object == nil || method.Object.Callable == nil {
+		return nil, nil, nil, fmt.Errorf("TypeError: proxy trap '%s' is not a function", name)
+	}
+	return target, method.Object.Callable, NewObject(h), nil
+}
+
+func (o *Object) proxyGet(name string, receiver *Value) (*Value, error) {
+	target, trap, handler, err := o.ProxyTrap("get")
+	if err != nil {
+		return nil, err
+	}
+	if trap == nil {
+		return target.get(name, receiver)
+	}
+	val, err := trap(handler, []*Value{NewObject(target), KeyToValue(name), receiver})
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		val = Undefined
+	}
+	// The trap cannot misreport a property the target has fixed.
+	if prop := OwnProperty(target, name); prop != nil && !prop.Configurable {
+		if !prop.IsAccessor && !prop.Writable && !SameValue(val, prop.Value) {
+			return nil, fmt.Errorf("TypeError: 'get' on proxy: property '%s' is a read-only and non-configurable data property on the proxy target but the proxy did not return its actual value", KeyToValue(name).ToString())
+		}
+		if prop.IsAccessor && prop.Getter == nil && val.Type != TypeUndefined {
+			return nil, fmt.Errorf("TypeError: 'get' on proxy: property '%s' is a non-configurable accessor property on the proxy target and does not have a getter function, but the trap did not return 'undefined'", KeyToValue(name).ToString())
+		}
+	}
+	return val, nil
+}
+
+func (o *Object) proxySet(name string, val *Value, receiver *Value) (bool, error) {
+	target, trap, handler, err := o.ProxyTrap("set")
+	if err != nil {
+		return false, err
+	}
+	if trap == nil {
+		return target.TrySetWithReceiver(name, val, receiver)
+	}
+	result, err := trap(handler, []*Value{NewObject(target), KeyToValue(name), val, receiver})
+	if err != nil || result == nil || !result.ToBoolean() {
+		return false, err
+	}
+	if prop := OwnProperty(target, name); prop != nil && !prop.Configurable {
+		if !prop.IsAccessor && !prop.Writable && !SameValue(val, prop.Value) {
+			return false, fmt.Errorf("TypeError: 'set' on proxy: trap returned truish for property '%s' which exists in the proxy target as a non-configurable and non-writable data property with a different value", KeyToValue(name).ToString())
+		}
+		if prop.IsAccessor && prop.Setter == nil {
+			return false, fmt.Errorf("TypeError: 'set' on proxy: trap returned truish for property '%s' which exists in the proxy target as a non-configurable and writable accessor property without a setter", KeyToValue(name).ToString())
+		}
+	}
+	return true, nil
+}
+
+func (o *Object) proxyHas(name string) (bool, error) {
+	target, trap, handler, err := o.ProxyTrap("has")
+	if err != nil {
+		return false, err
+	}
+	if trap == nil {
+		return target.HasPropertyErr(name)
+	}
+	result, err := trap(handler, []*Value{NewObject(target), KeyToValue(name)})
+	if err != nil {
+		return false, err
+	}
+	if result != nil && result.ToBoolean() {
+		return true, nil
+	}
+	// A property the target has fixed cannot be hidden.
+	if prop := OwnProperty(target, name); prop != nil && (!prop.Configurable || !target.IsExtensible()) {
+		return false, fmt.Errorf("TypeError: 'has' on proxy: trap returned falsish for property '%s' which exists in the proxy target as non-configurable or in a non-extensible target", KeyToValue(name).ToString())
+	}
+	return false, nil
+}
+
+func (o *Object) proxyDelete(name string) (bool, error) {
+	target, trap, handler, err := o.ProxyTrap("deleteProperty")
+	if err != nil {
+		return false, err
+	}
+	if trap == nil {
+		return target.DeleteErr(name)
+	}
+	result, err := trap(handler, []*Value{NewObject(target), KeyToValue(name)})
+	if err != nil || result == nil || !result.ToBoolean() {
+		return false, err
+	}
+	if prop := OwnProperty(target, name); prop != nil && (!prop.Configurable || !target.IsExtensible()) {
+		return false, fmt.Errorf("TypeError: 'deleteProperty' on proxy: trap returned truish for property '%s' which is non-configurable in the proxy target or the target is non-extensible", KeyToValue(name).ToString())
+	}
+	return true, nil
+}
+
+// proxyIsExtensible calls the isExtensible trap, whose result must be
+// that of the target.
+func (o *Object) proxyIsExtensible() (bool, error) {
+	target, trap, handler, err := o.ProxyTrap("isExtensible")
+	if err != nil {
+		return false, err
+	}
+	extensible, err := target.IsExtensibleErr()
+	if err != nil || trap == nil {
+		return extensible, err
+	}
+	result, err := trap(handler, []*Value{NewObject(target)})
+	if err != nil {
+		return false, err
+	}
+	if (result != nil && result.ToBoolean()) != extensible {
+		return false, fmt.Errorf("TypeError: 'isExtensible' on proxy: trap result does not reflect extensibility of proxy target (which is '%t')", extensible)
+	}
+	return extensible, nil
+}
+
+// proxyPreventExtensions calls the preventExtensions trap, which may only
+// report success once the target is non-extensible.
+func (o *Object) proxyPreventExtensions() (bool, error) {
+	target, trap, handler, err := o.ProxyTrap("preventExtensions")
+	if err != nil {
+		return false, err
+	}
+	if trap == nil {
+		return target.PreventExtensionsErr()
+	}
+	result, err := trap(handler, []*Value{NewObject(target)})
+	if err != nil || result == nil || !result.ToBoolean() {
+		return false, err
+	}
+	if extensible, err := target.IsExtensibleErr(); err != nil || extensible {
+		if err == nil {
+			err = fmt.Errorf("TypeError: 'preventExtensions' on proxy: trap returned truish but the proxy target is extensible")
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// proxyOwnKeys calls the ownKeys trap, which must return an array-like of
+// strings and symbols without duplicates.
+func (o *Object) proxyOwnKeys() ([]string, error) {
+	target, trap, handler, err := o.ProxyTrap("ownKeys")
+	if err != nil {
+		return nil, err
+	}
+	if trap == nil {
+		return OwnKeysErr(target)
+	}
+	result, err := trap(handler, []*Value{NewObject(target)})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || result.Type != TypeObject || result.Object == nil {
+		return nil, fmt.Errorf("TypeError: proxy trap 'ownKeys' returned a non-object")
+	}
+	list := result.Object
+	n := int(list.Get("length").ToNumber())
+	keys := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		var v *Value
+		if i < len(list.ArrayData) && list.ArrayData[i] != nil {
+			v = list.ArrayData[i]
+		} else {
+			v = list.Get(fmt.Sprint(i))
+		}
+		if v.Type != TypeString && v.Type != TypeSymbol {
+			return nil, fmt.Errorf("TypeError: %s is not a valid property name", v.ToString())
+		}
+		key := v.ToPropertyKey()
+		if seen[key] {
+			return nil, fmt.Errorf("TypeError: proxy trap 'ownKeys' returned duplicate entries")
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	// The keys must include every property the target has fixed, and
+	// those of a non-extensible target exactly.
+	targetKeys, err := OwnKeysErr(target)
+	if err != nil {
+		return nil, err
+	}
+	extensible := target.IsExtensible()
+	for _, key := range targetKeys {
+		if seen[key] {
+			delete(seen, key)
+			continue
+		}
+		if prop := OwnProperty(target, key); !extensible || (prop != nil && !prop.Configurable) {
+			return nil, fmt.Errorf("TypeError: 'ownKeys' on proxy: trap result did not include '%s'", KeyToValue(key).ToString())
+		}
+	}
+	if !extensible && len(seen) > 0 {
+		return nil, fmt.Errorf("TypeError: 'ownKeys' on proxy: trap returned extra keys but proxy target is non-extensible")
+	}
+	return keys, nil
+}
@@ -0,0 +1,103 @@
+package runtime
+
+import "errors"
+
+// A Realm is a set of intrinsics: the built-in constructors and prototypes
+// that scripts of one global scope share, such as Object.prototype and
+// Array.prototype. The builtins package creates them; the fields here are
+// the ones the runtime and the interpreter need. A realm belongs to an
+// agent, which runs its scripts. Well-known symbols and the Symbol.for
+// registry are not part of a realm but shared by all.
+type Realm struct {
+	Agent *Agent
+
+	ObjectPrototype   *Object
+	FunctionPrototype *Object
+	ArrayPrototype    *Object
+	StringPrototype   *Object
+	NumberPrototype   *Object
+	BooleanPrototype  *Object
+	SymbolPrototype   *Object
+
+	// ArrayIteratorMethod and StringIteratorMethod are the built-in
+	// Array.prototype[Symbol.iterator] and
+	// String.prototype[Symbol.iterator]. See HasDefaultIterator.
+	ArrayIteratorMethod, StringIteratorMethod *Object
+
+	// NewPromise creates the promise returned by an async function. It
+	// returns a pending promise together with the functions that resolve
+	// and reject it.
+	NewPromise func() (promise *Value, resolve, reject func(*Value))
+	// AwaitValue implements await: v is converted to a promise and
+	// onFulfilled or onRejected runs from a job once that promise settles.
+	AwaitValue func(v *Value, onFulfilled, onRejected func(*Value))
+	// CloneBuiltin copies the built-in objects whose state lives in
+	// internal slots: Maps, Sets, Dates, RegExps and errors. It reports
+	// false for an object that is none of them. It must Remember the copy
+	// before cloning the values the object holds.
+	CloneBuiltin func(c *Cloner, obj *Object) (*Object, bool, error)
+	// GetOwnProperty implements [[GetOwnProperty]]: a proxy asks its
+	// getOwnPropertyDescriptor trap, other objects answer with
+	// OwnProperty.
+	GetOwnProperty func(obj *Object, key string) (*Property, error)
+
+	thrower *Object
+}
+
+// NewRealm returns a realm of agent without intrinsics, for the builtins
+// package to fill in. Objects made before it does have no prototype.
+func NewRealm(agent *Agent) *Realm {
+	return &Realm{Agent: agent}
+}
+
+// PrimitivePrototype returns the prototype that property lookups on the
+// primitive v consult, as if on its wrapper object: String.prototype for a
+// string and so on. It returns nil for objects, undefined and null.
+func (r *Realm) PrimitivePrototype(v *Value) *Object {
+	switch v.Type {
+	case TypeString:
+		return r.StringPrototype
+	case TypeNumber:
+		return r.NumberPrototype
+	case TypeBoolean:
+		return r.BooleanPrototype
+	case TypeSymbol:
+		return r.SymbolPrototype
+	}
+	return nil
+}
+
+// ThrowTypeError returns the %ThrowTypeError% function of r, which throws
+// a TypeError whenever it is called.
+func (r *Realm) ThrowTypeError() *Object {
+	if r.thrower == nil {
+		r.thrower = NewFunctionObject(r.FunctionPrototype, func(this *Value, args []*Value) (*Value, error) {
+			return nil, errors.New("TypeError: 'caller', 'callee', and 'arguments' properties may not be accessed on strict mode functions or the arguments objects for calls to them")
+		})
+		r.thrower.nonExtensible = true
+	}
+	return r.thrower
+}
+
+// Construct invokes callee as a constructor with newTarget as new.target,
+// as Reflect.construct does: the instance inherits from
+// newTarget.prototype, and the constructor sees newTarget as new.target.
+// Without an interpreter only the prototype follows newTarget.
+func (r *Realm) Construct(callee *Object, args []*Value, newTarget *Object) (*Value, error) {
+	if r.Agent.ConstructHook != nil {
+		return r.Agent.ConstructHook(callee, args, newTarget)
+	}
+	proto := r.ObjectPrototype
+	if p := newTarget.Get("prototype"); p.Type == TypeObject && p.Object != nil {
+		proto = p.Object
+	}
+	instance := NewObject(NewOrdinaryObject(proto))
+	result, err := callee.Constructor(instance, args)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && result.Type == TypeObject {
+		return result, nil
+	}
+	return instance, nil
+}
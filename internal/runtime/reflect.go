@@ -0,0 +1,444 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var (
+	valuePtrType = reflect.TypeOf((*Value)(nil))
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// GoConverter converts between Go and JavaScript values by reflection, for
+// hosts that pass structs, maps, slices and functions to scripts instead of
+// building objects by hand. The zero GoConverter uses the default rules;
+// the hooks let an embedding API add its own wrapper types.
+type GoConverter struct {
+	// Realm is the realm of the objects the converter makes, whose agent's
+	// lock Go functions called from scripts run without. Without one,
+	// objects have no prototype and no lock is taken.
+	Realm *Realm
+	// FromGo, when set, converts a Go value before the default rules
+	// apply; it reports false to leave the value to them.
+	FromGo func(rv reflect.Value) (*Value, bool, error)
+	// ToGo, when set, stores v into dst before the default rules apply;
+	// it reports false to leave the destination to them.
+	ToGo func(v *Value, dst reflect.Value) (bool, error)
+	// GoError, when set, translates the errors that Go functions return to
+	// scripts, and CallError those of script functions called from Go.
+	GoError   func(error) error
+	CallError func(error) error
+}
+
+// intrinsics returns the realm of the objects c makes.
+func (c *GoConverter) intrinsics() *Realm {
+	if c.Realm == nil {
+		return &Realm{}
+	}
+	return c.Realm
+}
+
+// unlocked calls fn without the execution lock of c's agent, see
+// Agent.Unlocked.
+func (c *GoConverter) unlocked(fn func()) {
+	if c.Realm == nil {
+		fn()
+		return
+	}
+	c.Realm.Agent.Unlocked(fn)
+}
+
+// FieldName returns the property name of a struct field: the name given by
+// its js tag, as in `js:"name"`, or the field name itself. Unexported
+// fields and fields tagged `js:"-"` have no property.
+func FieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	tag := f.Tag.Get("js")
+	if tag == "-" {
+		return "", false
+	}
+	if tag != "" {
+		return tag, true
+	}
+	return f.Name, true
+}
+
+// ToValue converts x to a JavaScript value: nil, nil pointers, maps and
+// slices to null, booleans, numbers and strings to their primitives,
+// slices and arrays to new arrays, maps with string keys and structs to
+// new objects, and functions to functions, see WrapFunc. A *Value is
+// returned as is.
+func (c *GoConverter) ToValue(x interface{}) (*Value, error) {
+	return c.fromGo(reflect.ValueOf(x))
+}
+
+func (c *GoConverter) fromGo(rv reflect.Value) (*Value, error) {
+	if c.FromGo != nil && rv.IsValid() {
+		if v, ok, err := c.FromGo(rv); ok || err != nil {
+			return v, err
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return Null, nil
+	case reflect.Bool:
+		return NewBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewNumber(float64(rv.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewNumber(float64(rv.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return NewNumber(rv.Float()), nil
+	case reflect.String:
+		return NewString(rv.String()), nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return Null, nil
+		}
+		if rv.Type() == valuePtrType {
+			return rv.Interface().(*Value), nil
+		}
+		return c.fromGo(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return Null, nil
+		}
+		elems := make([]*Value, rv.Len())
+		for i := range elems {
+			val, err := c.fromGo(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = val
+		}
+		return NewObject(NewArrayObject(c.intrinsics().ArrayPrototype, elems)), nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return Null, nil
+		}
+		obj := NewOrdinaryObject(c.intrinsics().ObjectPrototype)
+		iter := rv.MapRange()
+		for iter.Next() {
+			val, err := c.fromGo(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(iter.Key().String(), val)
+		}
+		return NewObject(obj), nil
+	case reflect.Struct:
+		obj := NewOrdinaryObject(c.intrinsics().ObjectPrototype)
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := FieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			val, err := c.fromGo(rv.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(name, val)
+		}
+		return NewObject(obj), nil
+	case reflect.Func:
+		if rv.IsNil() {
+			return Null, nil
+		}
+		return NewObject(NewFunctionObject(c.intrinsics().FunctionPrototype, c.wrapFunc(rv))), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to a JavaScript value", rv.Type())
+}
+
+// WrapFunc adapts the Go function fn, of any signature, to a native
+// function. Arguments are converted to the parameter types with ExportTo,
+// missing ones from undefined, and a conversion failure throws a
+// TypeError. A last result of type error throws when it is not nil; the
+// other result, if any, is converted with ToValue, and several results make
+// an array.
+func (c *GoConverter) WrapFunc(fn interface{}) (CallableFunc, error) {
+	rv := reflect.ValueOf(fn)
+	if rv.Kind() != reflect.Func || rv.IsNil() {
+		return nil, fmt.Errorf("%T is not a function", fn)
+	}
+	return c.wrapFunc(rv), nil
+}
+
+func (c *GoConverter) wrapFunc(fn reflect.Value) CallableFunc {
+	t := fn.Type()
+	return func(this *Value, args []*Value) (*Value, error) {
+		n := t.NumIn()
+		if t.IsVariadic() && len(args) > n {
+			n = len(args)
+		}
+		in := make([]reflect.Value, n)
+		for i := range in {
+			pt := t.In(min(i, t.NumIn()-1))
+			if t.IsVariadic() && i >= t.NumIn()-1 {
+				pt = pt.Elem()
+			}
+			arg := Undefined
+			if i < len(args) {
+				arg = args[i]
+			}
+			in[i] = reflect.New(pt).Elem()
+			if err := c.ExportTo(arg, in[i]); err != nil {
+				return nil, fmt.Errorf("TypeError: argument %d: %v", i+1, err)
+			}
+		}
+		if t.IsVariadic() && len(in) < t.NumIn() {
+			in = in[:t.NumIn()-1]
+		}
+		var out []reflect.Value
+		c.unlocked(func() { out = fn.Call(in) })
+		if len(out) > 0 && t.Out(len(out)-1) == errorType {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				if c.GoError != nil {
+					err = c.GoError(err)
+				}
+				return nil, err
+			}
+			out = out[:len(out)-1]
+		}
+		switch len(out) {
+		case 0:
+			return Undefined, nil
+		case 1:
+			return c.fromGo(out[0])
+		}
+		elems := make([]*Value, len(out))
+		for i, r := range out {
+			val, err := c.fromGo(r)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = val
+		}
+		return NewObject(NewArrayObject(c.intrinsics().ArrayPrototype, elems)), nil
+	}
+}
+
+// Export converts v to a plain Go value: nil for undefined and null, bool,
+// float64, string, []interface{} for arrays and map[string]interface{} for
+// other objects (own enumerable properties only). Functions and symbols are
+// returned as the *Value itself.
+func (c *GoConverter) Export(v *Value) interface{} {
+	var out interface{}
+	if err := c.ExportTo(v, reflect.ValueOf(&out).Elem()); err != nil {
+		return v
+	}
+	return out
+}
+
+// ExportTo stores v into dst, which must be settable, following the type
+// of dst: numbers go into the numeric kinds, strings and booleans into
+// theirs, arrays into slices and arrays, objects into maps with string keys
+// and into structs (matching properties to fields as ToValue names them),
+// and functions into Go functions that call v. Pointers are allocated as
+// needed and an interface{} receives the value Export returns. Undefined
+// and null set the zero value.
+func (c *GoConverter) ExportTo(v *Value, dst reflect.Value) error {
+	if v == nil {
+		v = Undefined
+	}
+	if c.ToGo != nil {
+		if ok, err := c.ToGo(v, dst); ok || err != nil {
+			return err
+		}
+	}
+	t := dst.Type()
+	if t == valuePtrType {
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+	if v.Type == TypeUndefined || v.Type == TypeNull {
+		dst.Set(reflect.Zero(t))
+		return nil
+	}
+	mismatch := fmt.Errorf("cannot export %s to %s", v.Type, t)
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return mismatch
+		}
+		exported, err := c.exportPlain(v)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(&exported).Elem())
+	case reflect.Bool:
+		if v.Type != TypeBoolean {
+			return mismatch
+		}
+		dst.SetBool(v.Bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type != TypeNumber || v.Number != math.Trunc(v.Number) || dst.OverflowInt(int64(v.Number)) {
+			return mismatch
+		}
+		dst.SetInt(int64(v.Number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Type != TypeNumber || v.Number < 0 || v.Number != math.Trunc(v.Number) || dst.OverflowUint(uint64(v.Number)) {
+			return mismatch
+		}
+		dst.SetUint(uint64(v.Number))
+	case reflect.Float32, reflect.Float64:
+		if v.Type != TypeNumber {
+			return mismatch
+		}
+		dst.SetFloat(v.Number)
+	case reflect.String:
+		if v.Type != TypeString {
+			return mismatch
+		}
+		dst.SetString(v.Str)
+	case reflect.Ptr:
+		p := reflect.New(t.Elem())
+		if err := c.ExportTo(v, p.Elem()); err != nil {
+			return err
+		}
+		dst.Set(p)
+	case reflect.Slice, reflect.Array:
+		if v.Type != TypeObject || v.Object == nil || v.Object.OType != ObjTypeArray {
+			return mismatch
+		}
+		elems := v.Object.ArrayValues()
+		if t.Kind() == reflect.Array {
+			if len(elems) != t.Len() {
+				return fmt.Errorf("cannot export an array of length %d to %s", len(elems), t)
+			}
+		} else {
+			dst.Set(reflect.MakeSlice(t, len(elems), len(elems)))
+		}
+		for i, elem := range elems {
+			if err := c.ExportTo(elem, dst.Index(i)); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if v.Type != TypeObject || v.Object == nil || t.Key().Kind() != reflect.String {
+			return mismatch
+		}
+		m := reflect.MakeMap(t)
+		for _, key := range OwnKeys(v.Object) {
+			prop := v.Object.Properties[key]
+			if prop == nil || !prop.Enumerable || IsSymbolKey(key) {
+				continue
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := c.ExportTo(v.Object.Get(key), elem); err != nil {
+				return fmt.Errorf("property %q: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		if v.Type != TypeObject || v.Object == nil {
+			return mismatch
+		}
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := FieldName(t.Field(i))
+			if !ok || !v.Object.HasProperty(name) {
+				continue
+			}
+			if err := c.ExportTo(v.Object.Get(name), dst.Field(i)); err != nil {
+				return fmt.Errorf("field %s: %w", t.Field(i).Name, err)
+			}
+		}
+	case reflect.Func:
+		if v.Type != TypeObject || v.Object == nil || v.Object.Callable == nil {
+			return mismatch
+		}
+		dst.Set(reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+			return c.callFromGo(v, t, in)
+		}))
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+// exportPlain is Export for the values of an interface{}.
+func (c *GoConverter) exportPlain(v *Value) (interface{}, error) {
+	switch v.Type {
+	case TypeBoolean:
+		return v.Bool, nil
+	case TypeNumber:
+		return v.Number, nil
+	case TypeString:
+		return v.Str, nil
+	case TypeObject:
+		if v.Object == nil || v.Object.Callable != nil {
+			break
+		}
+		if v.Object.OType == ObjTypeArray {
+			var out []interface{}
+			err := c.ExportTo(v, reflect.ValueOf(&out).Elem())
+			return out, err
+		}
+		var out map[string]interface{}
+		err := c.ExportTo(v, reflect.ValueOf(&out).Elem())
+		return out, err
+	}
+	return v, nil
+}
+
+// callFromGo calls the script function v for a Go function of type t made
+// by ExportTo. A function whose last result is an error reports exceptions
+// and conversion failures there; any other function panics with them.
+func (c *GoConverter) callFromGo(v *Value, t reflect.Type, in []reflect.Value) []reflect.Value {
+	// Host code calls the function, so the execution lock is not held.
+	if c.Realm != nil {
+		c.Realm.Agent.Lock()
+		defer c.Realm.Agent.Unlock()
+	}
+	out := make([]reflect.Value, t.NumOut())
+	for i := range out {
+		out[i] = reflect.New(t.Out(i)).Elem()
+	}
+	fail := func(err error) []reflect.Value {
+		if len(out) == 0 || t.Out(len(out)-1) != errorType {
+			panic(err)
+		}
+		out[len(out)-1] = reflect.ValueOf(&err).Elem()
+		return out
+	}
+
+	var args []*Value
+	for i, arg := range in {
+		if t.IsVariadic() && i == len(in)-1 {
+			for j := 0; j < arg.Len(); j++ {
+				val, err := c.fromGo(arg.Index(j))
+				if err != nil {
+					return fail(err)
+				}
+				args = append(args, val)
+			}
+			break
+		}
+		val, err := c.fromGo(arg)
+		if err != nil {
+			return fail(err)
+		}
+		args = append(args, val)
+	}
+	result, err := v.Object.Callable(Undefined, args)
+	if err != nil {
+		if c.CallError != nil {
+			err = c.CallError(err)
+		}
+		return fail(err)
+	}
+	if len(out) > 0 && t.Out(0) != errorType {
+		if err := c.ExportTo(result, out[0]); err != nil {
+			return fail(err)
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoConverter(t *testing.T) {
+	type inner struct {
+		N int `js:"n"`
+	}
+	type outer struct {
+		Name   string            `js:"name"`
+		Inner  *inner            `js:"inner"`
+		List   []inner           `js:"list"`
+		Extra  map[string]uint8  `js:"extra"`
+		Hidden string            `js:"-"`
+		Any    interface{}       `js:"any"`
+		Raw    *Value            `js:"raw"`
+		Opt    map[string]string `js:"opt"`
+		secret int
+	}
+	var c GoConverter
+	in := outer{Name: "a", Inner: &inner{N: 1}, List: []inner{{2}, {3}}, Extra: map[string]uint8{"k": 4}, Hidden: "h", Any: []interface{}{true, "s"}, Raw: NewString("r"), secret: 5}
+	v, err := c.ToValue(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := v.Object
+	if obj.HasProperty("Hidden") || obj.HasProperty("secret") || obj.Get("opt") != Null {
+		t.Error("hidden and unexported fields should be skipped and nil maps be null")
+	}
+	if got := obj.Get("inner").Object.Get("n").Number; got != 1 {
+		t.Errorf("inner.n = %v, want 1", got)
+	}
+
+	var out outer
+	if err := c.ExportTo(v, reflect.ValueOf(&out).Elem()); err != nil {
+		t.Fatal(err)
+	}
+	in.Hidden, in.secret = "", 0
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip: got %+v, want %+v", out, in)
+	}
+
+	// Export into a type that does not fit fails with the path to the value.
+	var bad struct {
+		List []struct {
+			N string `js:"n"`
+		} `js:"list"`
+	}
+	err = c.ExportTo(v, reflect.ValueOf(&bad).Elem())
+	if err == nil || err.Error() != "field List: index 0: field N: cannot export number to string" {
+		t.Errorf("unexpected error %v", err)
+	}
+	var n uint
+	if err := c.ExportTo(NewNumber(1.5), reflect.ValueOf(&n).Elem()); err == nil {
+		t.Error("1.5 should not export to an integer")
+	}
+
+	if got := c.Export(NewObject(NewArrayObject(nil, []*Value{NewNumber(1), nil}))); !reflect.DeepEqual(got, []interface{}{1.0, nil}) {
+		t.Errorf("Export = %#v", got)
+	}
+	if _, err := c.ToValue(make(chan int)); err == nil {
+		t.Error("channels should not convert")
+	}
+}
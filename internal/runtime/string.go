@@ -0,0 +1,54 @@
+package runtime
+
+import "strconv"
+
+// StringOwnProperty returns the own property key of the string s: its
+// length, or the code unit at an index below it. Every other property of a
+// string is looked up on String.prototype.
+func StringOwnProperty(s, key string) (*Value, bool) {
+	if key == "length" {
+		return NewNumber(float64(UTF16Length(s))), true
+	}
+	i, ok := ArrayIndex(key)
+	if !ok {
+		return nil, false
+	}
+	cu, ok := CodeUnitAt(s, i)
+	if !ok {
+		return nil, false
+	}
+	return NewString(FromCodePoint(rune(cu))), true
+}
+
+// NewStringObject returns a String object wrapping s, as new String(s) and
+// Object(s) create, with the String.prototype of r as its prototype.
+func (r *Realm) NewStringObject(s string) *Object {
+	obj := NewOrdinaryObject(r.StringPrototype)
+	SetStringData(obj, s)
+	return obj
+}
+
+// SetStringData makes obj a String object wrapping s: s is kept in its
+// [[StringData]] slot, and its code units and length become read-only own
+// properties.
+func SetStringData(obj *Object, s string) {
+	if obj.Internal == nil {
+		obj.Internal = make(map[string]interface{})
+	}
+	obj.Internal["StringData"] = s
+	units := UTF16(s)
+	for i, cu := range units {
+		obj.putProperty(strconv.Itoa(i), &Property{Value: NewString(FromCodePoint(rune(cu))), Enumerable: true})
+	}
+	obj.putProperty("length", &Property{Value: NewNumber(float64(len(units)))})
+}
+
+// StringData returns the string wrapped by the String object obj, and
+// false if obj is not one.
+func StringData(obj *Object) (string, bool) {
+	if obj == nil || obj.Internal == nil {
+		return "", false
+	}
+	s, ok := obj.Internal["StringData"].(string)
+	return s, ok
+}
@@ -0,0 +1,29 @@
+package runtime
+
+import "testing"
+
+func TestStringOwnProperty(t *testing.T) {
+	s := "a😀"
+	if v, ok := StringOwnProperty(s, "length"); !ok || v.Number != 3 {
+		t.Errorf("length = %v, %v", v, ok)
+	}
+	if v, ok := StringOwnProperty(s, "2"); !ok || v.Str != "\xed\xb8\x80" {
+		t.Errorf("index 2 = %q, %v, want the low surrogate", v.Str, ok)
+	}
+	for _, key := range []string{"3", "01", "toString"} {
+		if _, ok := StringOwnProperty(s, key); ok {
+			t.Errorf("%q is not an own property", key)
+		}
+	}
+
+	obj := NewRealm(NewAgent()).NewStringObject(s)
+	if data, ok := StringData(obj); !ok || data != s {
+		t.Errorf("StringData = %q, %v", data, ok)
+	}
+	if obj.Get("0").Str != "a" || obj.Get("length").Number != 3 {
+		t.Error("a String object has its code units and length as own properties")
+	}
+	if _, ok := StringData(NewOrdinaryObject(nil)); ok {
+		t.Error("an ordinary object has no StringData")
+	}
+}
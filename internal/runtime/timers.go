@@ -0,0 +1,84 @@
+package runtime
+
+import "time"
+
+// timer is a callback waiting on the timer queue, scheduled by setTimeout
+// or setInterval.
+type timer struct {
+	id       int
+	seq      int // order of scheduling, which breaks ties between due timers
+	when     time.Time
+	interval time.Duration
+	repeat   bool
+	fn       func() error
+}
+
+// AddTimer schedules fn to run once delay has elapsed, and every delay
+// after that if repeat is set, until ClearTimer is called with the id it
+// returns. Ids are positive. The event loop runs the timers; see NextTimer.
+func (q *EventQueue) AddTimer(delay time.Duration, repeat bool, fn func() error) int {
+	if delay < 0 {
+		delay = 0
+	}
+	q.timerID++
+	t := &timer{id: q.timerID, interval: delay, repeat: repeat, fn: fn}
+	q.schedule(t)
+	q.timers[t.id] = t
+	return t.id
+}
+
+func (q *EventQueue) schedule(t *timer) {
+	q.timerSeq++
+	t.seq = q.timerSeq
+	t.when = time.Now().Add(t.interval)
+}
+
+// ClearTimer cancels the timer id. Unknown ids are ignored.
+func (q *EventQueue) ClearTimer(id int) {
+	delete(q.timers, id)
+}
+
+// PendingTimers reports how many timers are scheduled.
+func (q *EventQueue) PendingTimers() int {
+	return len(q.timers)
+}
+
+// nextTimer returns the timer due first, or nil if there are none.
+func (q *EventQueue) nextTimer() *timer {
+	var next *timer
+	for _, t := range q.timers {
+		if next == nil || t.when.Before(next.when) || t.when.Equal(next.when) && t.seq < next.seq {
+			next = t
+		}
+	}
+	return next
+}
+
+// NextTimer returns when the earliest timer is due, and false if no timer
+// is scheduled.
+func (q *EventQueue) NextTimer() (time.Time, bool) {
+	if t := q.nextTimer(); t != nil {
+		return t.when, true
+	}
+	return time.Time{}, false
+}
+
+// RunTimer runs the earliest timer if it is due, followed by the microtask
+// queue, and reports whether it ran one. An interval is scheduled again
+// before its callback runs, so the callback may clear it. The error is
+// that of the callback, or else of a job it queued.
+func (q *EventQueue) RunTimer() (bool, error) {
+	t := q.nextTimer()
+	if t == nil || t.when.After(time.Now()) {
+		return false, nil
+	}
+	if t.repeat {
+		q.schedule(t)
+	} else {
+		delete(q.timers, t.id)
+	}
+	if err := t.fn(); err != nil {
+		return true, err
+	}
+	return true, q.RunJobs()
+}
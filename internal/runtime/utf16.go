@@ -0,0 +1,361 @@
+package runtime
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// JavaScript strings are sequences of UTF-16 code units. Value.Str holds
+// them as WTF-8: UTF-8, except that a lone surrogate, which UTF-8 cannot
+// encode, is written as the three-byte sequence its code point would have.
+// A surrogate pair is always a four-byte character: NewString joins a lone
+// high surrogate followed by a lone low one, as concatenation can leave
+// them, so that equal code units make equal strings. The functions here
+// index such strings by code unit, the unit of length, charAt and slice.
+
+// UTF16Length returns the number of UTF-16 code units in s.
+func UTF16Length(s string) int {
+	if isASCII(s) {
+		return len(s)
+	}
+	n := 0
+	for i := 0; i < len(s); {
+		r, size := decodeWTF8(s[i:])
+		i += size
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return n
+}
+
+// UTF16 returns the UTF-16 code units of s.
+func UTF16(s string) []uint16 {
+	units := make([]uint16, 0, len(s))
+	for i := 0; i < len(s); {
+		r, size := decodeWTF8(s[i:])
+		i += size
+		if r >= 0x10000 {
+			r -= 0x10000
+			units = append(units, uint16(0xD800+r>>10), uint16(0xDC00+r&0x3FF))
+		} else {
+			units = append(units, uint16(r))
+		}
+	}
+	return units
+}
+
+// CompareUTF16 compares a and b by their UTF-16 code units, the order of
+// the relational operators, returning -1, 0 or +1. It differs from
+// strings.Compare only for characters above U+FFFF, which sort below
+// U+E000 through U+FFFF as their surrogates do.
+func CompareUTF16(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[i]) {
+		i-- // back to the start of the character the strings differ in
+	}
+	if i == len(a) || i == len(b) {
+		return compareInts(len(a)-i, len(b)-i)
+	}
+	ra, _ := decodeWTF8(a[i:])
+	rb, _ := decodeWTF8(b[i:])
+	if ua, ub := firstUnit(ra), firstUnit(rb); ua != ub {
+		return compareInts(int(ua), int(ub))
+	}
+	// The same high surrogate: the low ones are in code point order.
+	return compareInts(int(ra), int(rb))
+}
+
+// firstUnit returns the first UTF-16 code unit of r.
+func firstUnit(r rune) rune {
+	if r >= 0x10000 {
+		return 0xD800 + (r-0x10000)>>10
+	}
+	return r
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// FromUTF16 builds a string from UTF-16 code units, joining surrogate
+// pairs into one character and keeping lone surrogates.
+func FromUTF16(units []uint16) string {
+	var sb strings.Builder
+	sb.Grow(len(units))
+	for i := 0; i < len(units); i++ {
+		cu := units[i]
+		if cu >= 0xD800 && cu <= 0xDBFF && i+1 < len(units) && units[i+1] >= 0xDC00 && units[i+1] <= 0xDFFF {
+			sb.WriteRune(rune(cu-0xD800)<<10 + rune(units[i+1]-0xDC00) + 0x10000)
+			i++
+			continue
+		}
+		writeCodePoint(&sb, rune(cu))
+	}
+	return sb.String()
+}
+
+// joinSurrogates returns s with each lone high surrogate that is followed
+// by a lone low surrogate joined with it into one four-byte character.
+func joinSurrogates(s string) string {
+	i := strings.IndexByte(s, 0xED)
+	if i < 0 {
+		return s
+	}
+	var sb strings.Builder
+	last := 0
+	for ; i+5 < len(s); i++ {
+		if s[i] != 0xED || s[i+1] < 0xA0 || s[i+1] > 0xAF || s[i+3] != 0xED || s[i+4] < 0xB0 || s[i+4] > 0xBF {
+			continue
+		}
+		hi, _ := LoneSurrogate(s, i)
+		lo, _ := LoneSurrogate(s, i+3)
+		if sb.Len() == 0 {
+			sb.Grow(len(s) - 2)
+		}
+		sb.WriteString(s[last:i])
+		sb.WriteRune((hi-0xD800)<<10 + (lo - 0xDC00) + 0x10000)
+		i += 5
+		last = i + 1
+	}
+	if last == 0 {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// FromCodePoint returns the string of the code point r, which may be a
+// lone surrogate.
+func FromCodePoint(r rune) string {
+	var sb strings.Builder
+	writeCodePoint(&sb, r)
+	return sb.String()
+}
+
+// CodeUnitAt returns the code unit at index i of s, and false if i is out
+// of range.
+func CodeUnitAt(s string, i int) (uint16, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	if isASCII(s) {
+		if i >= len(s) {
+			return 0, false
+		}
+		return uint16(s[i]), true
+	}
+	units := UTF16(s)
+	if i >= len(units) {
+		return 0, false
+	}
+	return units[i], true
+}
+
+// CodePointAt returns the code point that starts at code unit i of s: a
+// surrogate pair read as one code point, or else the code unit itself. It
+// returns false if i is out of range.
+func CodePointAt(s string, i int) (rune, bool) {
+	units := UTF16(s)
+	if i < 0 || i >= len(units) {
+		return 0, false
+	}
+	cu := units[i]
+	if cu >= 0xD800 && cu <= 0xDBFF && i+1 < len(units) && units[i+1] >= 0xDC00 && units[i+1] <= 0xDFFF {
+		return rune(cu-0xD800)<<10 + rune(units[i+1]-0xDC00) + 0x10000, true
+	}
+	return rune(cu), true
+}
+
+// UTF16Slice returns the code units of s from start up to end, which must
+// satisfy 0 <= start <= end <= UTF16Length(s). Cutting a surrogate pair
+// leaves a lone surrogate.
+func UTF16Slice(s string, start, end int) string {
+	if isASCII(s) {
+		return s[start:end]
+	}
+	return FromUTF16(UTF16(s)[start:end])
+}
+
+// UTF16Index returns the code unit index of the first occurrence of sub
+// in s at or after code unit from, or -1.
+func UTF16Index(s, sub string, from int) int {
+	if isASCII(s) {
+		if from > len(s) {
+			return -1
+		}
+		if i := strings.Index(s[from:], sub); i >= 0 {
+			return from + i
+		}
+		return -1
+	}
+	units, subUnits := UTF16(s), UTF16(sub)
+	for i := from; i+len(subUnits) <= len(units); i++ {
+		if unitsHavePrefix(units[i:], subUnits) {
+			return i
+		}
+	}
+	return -1
+}
+
+// UTF16LastIndex returns the code unit index of the last occurrence of sub
+// in s that starts at or before code unit from, or -1.
+func UTF16LastIndex(s, sub string, from int) int {
+	units, subUnits := UTF16(s), UTF16(sub)
+	if from > len(units)-len(subUnits) {
+		from = len(units) - len(subUnits)
+	}
+	for i := from; i >= 0; i-- {
+		if unitsHavePrefix(units[i:], subUnits) {
+			return i
+		}
+	}
+	return -1
+}
+
+// CodePoints splits s into its code points, each a string of one or two
+// code units; a lone surrogate is a code point of its own. This is how
+// strings are iterated.
+func CodePoints(s string) []string {
+	points := make([]string, 0, len(s))
+	for i := 0; i < len(s); {
+		_, size := decodeWTF8(s[i:])
+		points = append(points, s[i:i+size])
+		i += size
+	}
+	return points
+}
+
+// IsWellFormed reports whether s has no lone surrogates, so that it
+// converts to UTF-8 without loss.
+func IsWellFormed(s string) bool {
+	if utf8.ValidString(s) {
+		return true
+	}
+	for i := 0; i < len(s); {
+		r, size := decodeWTF8(s[i:])
+		if r >= 0xD800 && r <= 0xDFFF {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// LoneSurrogate returns the lone surrogate whose WTF-8 encoding starts at
+// byte i of s, and false if none does.
+func LoneSurrogate(s string, i int) (rune, bool) {
+	if i+2 >= len(s) || s[i] != 0xED || s[i+1] < 0xA0 || s[i+1] > 0xBF {
+		return 0, false
+	}
+	return 0xD000 | rune(s[i+1]&0x3F)<<6 | rune(s[i+2]&0x3F), true
+}
+
+// ToWellFormed returns s with each lone surrogate replaced by U+FFFD.
+func ToWellFormed(s string) string {
+	if IsWellFormed(s) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := decodeWTF8(s[i:])
+		if r >= 0xD800 && r <= 0xDFFF {
+			sb.WriteRune(utf8.RuneError)
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// MapWellFormed applies f to each run of s without lone surrogates and
+// keeps the surrogates between them, which Go's string functions would
+// replace with one U+FFFD for each of their bytes.
+func MapWellFormed(s string, f func(string) string) string {
+	if IsWellFormed(s) {
+		return f(s)
+	}
+	var sb strings.Builder
+	start := 0
+	for i := 0; i < len(s); {
+		if _, ok := LoneSurrogate(s, i); ok {
+			sb.WriteString(f(s[start:i]))
+			sb.WriteString(s[i : i+3])
+			i += 3
+			start = i
+			continue
+		}
+		i++
+	}
+	sb.WriteString(f(s[start:]))
+	return sb.String()
+}
+
+func unitsHavePrefix(units, prefix []uint16) bool {
+	if len(units) < len(prefix) {
+		return false
+	}
+	for i, cu := range prefix {
+		if units[i] != cu {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCodePoint writes r to sb in WTF-8. utf8 would replace a surrogate
+// with U+FFFD.
+func writeCodePoint(sb *strings.Builder, r rune) {
+	if r >= 0xD800 && r <= 0xDFFF {
+		sb.WriteByte(byte(0xE0 | r>>12))
+		sb.WriteByte(byte(0x80 | (r>>6)&0x3F))
+		sb.WriteByte(byte(0x80 | r&0x3F))
+		return
+	}
+	sb.WriteRune(r)
+}
+
+// decodeWTF8 decodes the character at the start of s. Invalid bytes
+// decode one at a time, as the code unit of their own value.
+func decodeWTF8(s string) (rune, int) {
+	b := s[0]
+	size := 4
+	switch {
+	case b < 0xC0:
+		size = 1
+	case b < 0xE0:
+		size = 2
+	case b < 0xF0:
+		size = 3
+	}
+	if size == 1 || size > len(s) {
+		return rune(b), 1
+	}
+	switch size {
+	case 2:
+		return rune(b&0x1F)<<6 | rune(s[1]&0x3F), 2
+	case 3:
+		return rune(b&0x0F)<<12 | rune(s[1]&0x3F)<<6 | rune(s[2]&0x3F), 3
+	}
+	return rune(b&0x07)<<18 | rune(s[1]&0x3F)<<12 | rune(s[2]&0x3F)<<6 | rune(s[3]&0x3F), 4
+}
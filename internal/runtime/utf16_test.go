@@ -0,0 +1,122 @@
+package runtime
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUTF16(t *testing.T) {
+	s := "hé😀"
+	if n := UTF16Length(s); n != 4 {
+		t.Errorf("UTF16Length(%q) = %d, want 4", s, n)
+	}
+	units := UTF16(s)
+	if !slices.Equal(units, []uint16{'h', 0xE9, 0xD83D, 0xDE00}) {
+		t.Errorf("UTF16(%q) = %x", s, units)
+	}
+	if FromUTF16(units) != s {
+		t.Error("FromUTF16 joins a surrogate pair into one character")
+	}
+
+	// Cutting a pair leaves lone surrogates, which survive a round trip.
+	hi, lo := UTF16Slice(s, 2, 3), UTF16Slice(s, 3, 4)
+	if hi != "\xed\xa0\xbd" || UTF16Length(hi) != 1 {
+		t.Errorf("the high half is a lone surrogate, got %q", hi)
+	}
+	if cu, ok := CodeUnitAt(lo, 0); !ok || cu != 0xDE00 {
+		t.Errorf("CodeUnitAt(lo, 0) = %x", cu)
+	}
+	if cp, _ := CodePointAt(s, 2); cp != 0x1F600 {
+		t.Errorf("CodePointAt(s, 2) = %x", cp)
+	}
+	if cp, _ := CodePointAt(s, 3); cp != 0xDE00 {
+		t.Errorf("CodePointAt(s, 3) = %x", cp)
+	}
+	if _, ok := CodeUnitAt(s, 4); ok {
+		t.Error("CodeUnitAt past the end")
+	}
+
+	if i := UTF16Index(s, "😀", 0); i != 2 {
+		t.Errorf("UTF16Index = %d", i)
+	}
+	if i := UTF16LastIndex("aéa", "a", 1); i != 0 {
+		t.Errorf("UTF16LastIndex = %d", i)
+	}
+	if got := CodePoints(s + hi); !slices.Equal(got, []string{"h", "é", "😀", hi}) {
+		t.Errorf("CodePoints = %q", got)
+	}
+}
+
+func TestJoinSurrogates(t *testing.T) {
+	hi, lo := "\xed\xa0\xbd", "\xed\xb8\x80"
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"abc", "abc"},
+		{hi + lo, "😀"},
+		{"a" + hi + lo + "b" + hi + lo, "a😀b😀"},
+		{hi + hi + lo, hi + "😀"},
+		{lo + hi, lo + hi},
+		{hi, hi},
+		{"한", "한"}, // also starts with 0xED
+	}
+	for _, tt := range tests {
+		if got := NewString(tt.in).Str; got != tt.want {
+			t.Errorf("NewString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMapWellFormed(t *testing.T) {
+	hi := "\xed\xa0\xbd"
+	tests := []struct{ in, want string }{
+		{"abc", "ABC"},
+		{hi, hi},
+		{"a" + hi + "b" + hi, "A" + hi + "B" + hi},
+	}
+	for _, tt := range tests {
+		if got := MapWellFormed(tt.in, strings.ToUpper); got != tt.want {
+			t.Errorf("MapWellFormed(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompareUTF16(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"a", "a", 0},
+		{"", "a", -1},
+		{"ab", "a", 1},
+		{"a", "b", -1},
+		{"Z", "a", -1},
+		{"é", "z", 1},
+		{"😀", "ﬁ", -1}, // U+D83D U+DE00 sorts before U+FB01
+		{"😀", "😁", -1}, // the same high surrogate
+		{"😀", "\xed\xa0\xbd", 1},
+		{"x😀y", "x😀z", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareUTF16(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareUTF16(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWellFormed(t *testing.T) {
+	lone := FromUTF16([]uint16{'a', 0xD83D, 'b', 0xDE00})
+	if !IsWellFormed("a😀\xff") || IsWellFormed(lone) {
+		t.Error("only lone surrogates make a string ill-formed")
+	}
+	if got := ToWellFormed(lone); got != "a�b�" {
+		t.Errorf("ToWellFormed = %q", got)
+	}
+	if r, ok := LoneSurrogate(lone, 1); !ok || r != 0xD83D {
+		t.Errorf("LoneSurrogate(1) = %x, %v", r, ok)
+	}
+	if _, ok := LoneSurrogate("😀", 0); ok {
+		t.Error("a surrogate pair is not a lone surrogate")
+	}
+}
@@ -0,0 +1,612 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ValueType represents the type of a JavaScript value.
+type ValueType int
+
+const (
+	TypeUndefined ValueType = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeObject
+	TypeSymbol
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "object" // typeof null === "object" in JS
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeObject:
+		return "object"
+	case TypeSymbol:
+		return "symbol"
+	default:
+		return "unknown"
+	}
+}
+
+// Value represents a JavaScript value.
+type Value struct {
+	Type   ValueType
+	Bool   bool
+	Number float64
+	Str    string
+	Object *Object
+	Symbol *Symbol
+}
+
+var (
+	Undefined = &Value{Type: TypeUndefined}
+	Null      = &Value{Type: TypeNull}
+	True      = &Value{Type: TypeBoolean, Bool: true}
+	False     = &Value{Type: TypeBoolean, Bool: false}
+	NaN       = &Value{Type: TypeNumber, Number: math_NaN()}
+	PosInf    = &Value{Type: TypeNumber, Number: math_Inf(1)}
+	NegInf    = &Value{Type: TypeNumber, Number: math_Inf(-1)}
+	Zero      = &Value{Type: TypeNumber, Number: 0}
+)
+
+// smallInts holds shared Values for the integers most programs use as loop
+// counters and indices. Values are never mutated, so sharing is safe.
+var smallInts = func() [1024 + 128]Value {
+	var vals [1024 + 128]Value
+	for i := range vals {
+		vals[i] = Value{Type: TypeNumber, Number: float64(i - 128)}
+	}
+	return vals
+}()
+
+func NewNumber(n float64) *Value {
+	if i := int(n); float64(i) == n && i >= -128 && i < 1024 && (i != 0 || !math.Signbit(n)) {
+		return &smallInts[i+128]
+	}
+	return &Value{Type: TypeNumber, Number: n}
+}
+
+func NewString(s string) *Value {
+	return &Value{Type: TypeString, Str: joinSurrogates(s)}
+}
+
+func NewBool(b bool) *Value {
+	if b {
+		return True
+	}
+	return False
+}
+
+func NewObject(obj *Object) *Value {
+	return &Value{Type: TypeObject, Object: obj}
+}
+
+// ToBoolean implements the ECMAScript ToBoolean abstract operation.
+func (v *Value) ToBoolean() bool {
+	switch v.Type {
+	case TypeUndefined, TypeNull:
+		return false
+	case TypeBoolean:
+		return v.Bool
+	case TypeNumber:
+		return v.Number != 0 && !isNaN(v.Number)
+	case TypeString:
+		return len(v.Str) > 0
+	case TypeObject, TypeSymbol:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToString implements the ECMAScript ToString abstract operation.
+func (v *Value) ToString() string {
+	switch v.Type {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		if v.Bool {
+			return "true"
+		}
+		return "false"
+	case TypeNumber:
+		return NumberToString(v.Number)
+	case TypeString:
+		return v.Str
+	case TypeSymbol:
+		if v.Symbol != nil {
+			return "Symbol(" + v.Symbol.Description + ")"
+		}
+		return "Symbol()"
+	case TypeObject:
+		if v.Object != nil && v.Object.OType == ObjTypeError {
+			name := v.Object.Get("name")
+			msg := v.Object.Get("message")
+			nameStr := "Error"
+			if name != nil && name.Type == TypeString && name.Str != "" {
+				nameStr = name.Str
+			}
+			msgStr := ""
+			if msg != nil && msg.Type == TypeString {
+				msgStr = msg.Str
+			}
+			if msgStr == "" {
+				return nameStr
+			}
+			return nameStr + ": " + msgStr
+		}
+		return "[object Object]"
+	default:
+		return "undefined"
+	}
+}
+
+// NumberToString implements Number::toString(n) for radix 10: the shortest
+// digits that round-trip, written out in full for exponents up to 21 and
+// down to -6, and in exponential notation such as "1e+21" beyond.
+func NumberToString(n float64) string {
+	switch {
+	case isNaN(n):
+		return "NaN"
+	case n == 0:
+		return "0"
+	case n < 0:
+		return "-" + NumberToString(-n)
+	case isInf(n, 1):
+		return "Infinity"
+	}
+	// FormatFloat gives "d.ddde±XX"; n = 0.digits × 10^point.
+	s := strconv.FormatFloat(n, 'e', -1, 64)
+	mant, exp, _ := strings.Cut(s, "e")
+	digits := strings.Replace(mant, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	point, k := e+1, len(digits)
+	switch {
+	case k <= point && point <= 21:
+		return digits + strings.Repeat("0", point-k)
+	case 0 < point && point <= 21:
+		return digits[:point] + "." + digits[point:]
+	case -6 < point && point <= 0:
+		return "0." + strings.Repeat("0", -point) + digits
+	}
+	if k > 1 {
+		digits = digits[:1] + "." + digits[1:]
+	}
+	if point-1 < 0 {
+		return digits + "e-" + strconv.Itoa(1-point)
+	}
+	return digits + "e+" + strconv.Itoa(point-1)
+}
+
+// ToPropertyKey returns the string key for use as an object property key.
+// For Symbols, it returns a unique internal string representation.
+func (v *Value) ToPropertyKey() string {
+	if v.Type == TypeSymbol && v.Symbol != nil {
+		return v.Symbol.Key()
+	}
+	return v.ToString()
+}
+
+// Key returns a unique string key for this symbol, used as a property key.
+func (s *Symbol) Key() string {
+	if s.key == "" {
+		s.key = fmt.Sprintf("@@sym(%s)@%p", s.Description, s)
+		symbolsByKey.Store(s.key, s)
+	}
+	return s.key
+}
+
+// SymbolIterator is set by the builtins package to the well-known
+// Symbol.iterator, so that the interpreter can look up iterator methods.
+var SymbolIterator *Symbol
+
+// SymbolAsyncIterator is set by the builtins package to the well-known
+// Symbol.asyncIterator, which for await loops look up.
+var SymbolAsyncIterator *Symbol
+
+// SymbolUnscopables is set by the builtins package to the well-known
+// Symbol.unscopables, which object environment records consult.
+var SymbolUnscopables *Symbol
+
+// HasDefaultIterator reports whether v is an array or string whose
+// Symbol.iterator is still the built-in one of r, so iterating it may read
+// the elements directly instead of calling next(). Without builtins every
+// array and string counts as default.
+func (r *Realm) HasDefaultIterator(v *Value) bool {
+	switch {
+	case v == nil:
+		return false
+	case v.Type == TypeString:
+		if SymbolIterator == nil || r.StringPrototype == nil {
+			return true
+		}
+		method := r.StringPrototype.GetSymbol(SymbolIterator)
+		return method != nil && method.Type == TypeObject && method.Object == r.StringIteratorMethod
+	case v.Type == TypeObject && v.Object != nil && v.Object.OType == ObjTypeArray:
+		if SymbolIterator == nil {
+			return true
+		}
+		method := v.Object.GetSymbol(SymbolIterator)
+		return method != nil && method.Type == TypeObject && method.Object == r.ArrayIteratorMethod
+	}
+	return false
+}
+
+// GetSymbol retrieves a symbol-keyed property.
+func (o *Object) GetSymbol(sym *Symbol) *Value {
+	if sym == nil {
+		return nil
+	}
+	return o.Get(sym.Key())
+}
+
+// ObjectType describes the kind of object.
+type ObjectType int
+
+const (
+	ObjTypeOrdinary ObjectType = iota
+	ObjTypeArray
+	ObjTypeFunction
+	ObjTypeRegExp
+	ObjTypeDate
+	ObjTypeError
+	ObjTypeMap
+	ObjTypeSet
+	ObjTypeWeakMap
+	ObjTypeWeakSet
+	ObjTypePromise
+	ObjTypeIterator
+	ObjTypeGenerator
+	ObjTypeProxy
+	ObjTypeArguments
+)
+
+// Object represents a JavaScript object.
+type Object struct {
+	OType       ObjectType
+	Properties  map[string]*Property
+	Prototype   *Object
+	Callable    CallableFunc
+	Constructor CallableFunc
+	Internal    map[string]interface{} // internal slots
+
+	// Array-specific
+	ArrayData []*Value
+
+	// For iterables
+	IteratorNext func() (*Value, bool)
+
+	// Integrity state; see integrity.go.
+	nonExtensible  bool
+	sealedElements bool
+	frozenElements bool
+}
+
+// Property represents a property descriptor.
+type Property struct {
+	Value        *Value
+	Getter       *Value // for accessor properties
+	Setter       *Value // for accessor properties
+	Writable     bool
+	Enumerable   bool
+	Configurable bool
+	IsAccessor   bool
+	// Has* fields track which attributes were explicitly specified in a descriptor.
+	// Used by defineProperty merging logic.
+	HasValue        bool
+	HasWritable     bool
+	HasEnumerable   bool
+	HasConfigurable bool
+	HasGet          bool
+	HasSet          bool
+
+	// seq records when the key was first added to its object; see OwnKeys.
+	seq uint64
+}
+
+// CallableFunc is the Go function signature for JS callable objects.
+type CallableFunc func(this *Value, args []*Value) (*Value, error)
+
+// Symbol represents an ES6 Symbol.
+type Symbol struct {
+	Description string
+	id          uint64
+	key         string // cached Key
+	private     bool   // a private name, see NewPrivateName
+}
+
+// NewOrdinaryObject creates a plain object.
+func NewOrdinaryObject(proto *Object) *Object {
+	return &Object{
+		OType:      ObjTypeOrdinary,
+		Properties: make(map[string]*Property),
+		Prototype:  proto,
+	}
+}
+
+// Get retrieves a property, walking the prototype chain. Inherited getters
+// are called with o as this.
+func (o *Object) Get(name string) *Value {
+	val, err := o.get(name, nil)
+	if err != nil {
+		return Undefined
+	}
+	return val
+}
+
+// GetErr is Get, but reports an error thrown by a getter instead of
+// dropping it.
+func (o *Object) GetErr(name string) (*Value, error) {
+	return o.get(name, nil)
+}
+
+// GetWithReceiver looks name up on o and its prototype chain, but calls a
+// getter with receiver as this. It implements property reads on primitives,
+// super.name and Reflect.get.
+func (o *Object) GetWithReceiver(name string, receiver *Value) (*Value, error) {
+	return o.get(name, receiver)
+}
+
+// get implements [[Get]]. A nil receiver stands for o itself.
+func (o *Object) get(name string, receiver *Value) (*Value, error) {
+	for p := o; p != nil; p = p.Prototype {
+		if p.OType == ObjTypeProxy {
+			if receiver == nil {
+				receiver = NewObject(o)
+			}
+			return p.proxyGet(name, receiver)
+		}
+		if v := p.element(name); v != nil {
+			return v, nil
+		}
+		prop, ok := p.Properties[name]
+		if !ok {
+			continue
+		}
+		if !prop.IsAccessor {
+			if b := p.mappedArgument(name); b != nil {
+				return b.Value, nil
+			}
+			if prop.Value == nil {
+				return Undefined, nil
+			}
+			return prop.Value, nil
+		}
+		if prop.Getter == nil || prop.Getter.Type != TypeObject || prop.Getter.Object == nil || prop.Getter.Object.Callable == nil {
+			return Undefined, nil
+		}
+		if receiver == nil {
+			receiver = NewObject(o)
+		}
+		val, err := prop.Getter.Object.Callable(receiver, nil)
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			return Undefined, nil
+		}
+		return val, nil
+	}
+	return Undefined, nil
+}
+
+// Set sets a property value.
+func (o *Object) Set(name string, val *Value) {
+	o.SetErr(name, val)
+}
+
+// SetErr is Set, but reports an error thrown by a setter instead of
+// dropping it. Inherited setters are called with o as this, and an
+// inherited read-only property blocks the assignment.
+func (o *Object) SetErr(name string, val *Value) error {
+	_, err := o.TrySet(name, val)
+	return err
+}
+
+// TrySet is SetErr, but also reports whether the assignment took effect. It
+// does not when the property is read-only or an accessor without a setter,
+// when it would add a property to a non-extensible object, or when a
+// proxy's set trap returns false; strict mode code throws in those cases.
+func (o *Object) TrySet(name string, val *Value) (bool, error) {
+	if o.OType == ObjTypeProxy {
+		return o.proxySet(name, val, NewObject(o))
+	}
+	if handled, ok, err := o.trySetElement(name, val); handled {
+		return ok, err
+	}
+	if prop, ok := o.Properties[name]; ok {
+		if prop.IsAccessor {
+			return prop.callSetter(NewObject(o), val)
+		}
+		if !prop.Writable {
+			return false, nil
+		}
+		prop.Value = val
+		if b := o.mappedArgument(name); b != nil {
+			b.Value = val
+		}
+		// Mirror to global env
+		if env := o.linkedGlobalEnv(); env != nil {
+			if binding, exists := env.GetBinding(name); exists {
+				binding.Value = val
+			}
+		}
+		return true, nil
+	}
+	if done, ok, err := o.Prototype.setInherited(name, val, NewObject(o)); done {
+		return ok, err
+	}
+	if o.nonExtensible {
+		return false, nil
+	}
+	o.putProperty(name, &Property{
+		Value:        val,
+		Writable:     true,
+		Enumerable:   true,
+		Configurable: true,
+	})
+	return true, nil
+}
+
+// SetWithReceiver looks name up on o and its prototype chain the way SetErr
+// does, but calls a setter with receiver as this, and creates or updates a
+// data property on receiver rather than on o. It implements super.name = v
+// and Reflect.set.
+func (o *Object) SetWithReceiver(name string, val *Value, receiver *Value) error {
+	_, err := o.TrySetWithReceiver(name, val, receiver)
+	return err
+}
+
+// TrySetWithReceiver is SetWithReceiver, but also reports whether the
+// assignment took effect, as TrySet does.
+func (o *Object) TrySetWithReceiver(name string, val *Value, receiver *Value) (bool, error) {
+	if done, ok, err := o.setInherited(name, val, receiver); done {
+		return ok, err
+	}
+	if receiver == nil || receiver.Type != TypeObject || receiver.Object == nil {
+		return false, nil
+	}
+	target := receiver.Object
+	for target.OType == ObjTypeProxy {
+		if target = target.ProxyTarget(); target == nil {
+			return false, fmt.Errorf("TypeError: Cannot perform 'set' on a proxy that has been revoked")
+		}
+	}
+	if handled, ok, err := target.trySetElement(name, val); handled {
+		return ok, err
+	}
+	if prop, ok := target.Properties[name]; ok {
+		if prop.IsAccessor || !prop.Writable {
+			return false, nil
+		}
+		return target.TrySet(name, val)
+	}
+	if target.nonExtensible {
+		return false, nil
+	}
+	target.DefineProperty(name, &Property{
+		Value:        val,
+		Writable:     true,
+		Enumerable:   true,
+		Configurable: true,
+		HasValue:     true,
+	})
+	return true, nil
+}
+
+// setInherited finds name on o or its prototype chain. An accessor
+// property's setter is called with receiver, and a read-only data property
+// blocks the assignment; in both cases done is true, and ok reports whether
+// the assignment took effect. When done is false the caller stores the
+// value as a data property of the receiver.
+func (o *Object) setInherited(name string, val *Value, receiver *Value) (done, ok bool, err error) {
+	for p := o; p != nil; p = p.Prototype {
+		if p.OType == ObjTypeProxy {
+			ok, err := p.proxySet(name, val, receiver)
+			return true, ok, err
+		}
+		if p.element(name) != nil {
+			// A frozen element blocks the assignment like a read-only
+			// property; otherwise the receiver gets the value.
+			return p.frozenElements, false, nil
+		}
+		prop, found := p.Properties[name]
+		if !found {
+			continue
+		}
+		if prop.IsAccessor {
+			ok, err := prop.callSetter(receiver, val)
+			return true, ok, err
+		}
+		if !prop.Writable {
+			return true, false, nil
+		}
+		return false, false, nil
+	}
+	return false, false, nil
+}
+
+// callSetter calls the setter of an accessor property with receiver as
+// this. An accessor without a setter ignores the assignment and reports
+// false.
+func (p *Property) callSetter(receiver *Value, val *Value) (bool, error) {
+	if p.Setter == nil || p.Setter.Type != TypeObject || p.Setter.Object == nil || p.Setter.Object.Callable == nil {
+		return false, nil
+	}
+	_, err := p.Setter.Object.Callable(receiver, []*Value{val})
+	return err == nil, err
+}
+
+// DefineProperty defines a property with full descriptor control.
+func (o *Object) DefineProperty(name string, prop *Property) {
+	if b := o.mappedArgument(name); b != nil {
+		// Defining a mapped element of an arguments object updates the
+		// parameter, and making it an accessor or read-only unmaps it.
+		if prop.IsAccessor {
+			o.unmapArgument(name)
+		} else {
+			if prop.HasValue {
+				b.Value = prop.Value
+			} else {
+				prop.Value = b.Value
+			}
+			if !prop.Writable {
+				o.unmapArgument(name)
+			}
+		}
+	}
+	o.putProperty(name, prop)
+	// If this object is a global object linked to an environment, mirror to env
+	if env := o.linkedGlobalEnv(); env != nil {
+		env.syncGlobal(name, prop)
+	}
+}
+
+// HasProperty checks own and prototype chain.
+func (o *Object) HasProperty(name string) bool {
+	has, _ := o.HasPropertyErr(name)
+	return has
+}
+
+// HasPropertyErr is HasProperty, but reports an error thrown by a proxy's
+// has trap instead of dropping it.
+func (o *Object) HasPropertyErr(name string) (bool, error) {
+	for p := o; p != nil; p = p.Prototype {
+		if p.OType == ObjTypeProxy {
+			return p.proxyHas(name)
+		}
+		if p.element(name) != nil {
+			return true, nil
+		}
+		if _, ok := p.Properties[name]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasOwnProperty checks only own properties, array elements included, see
+// OwnProperty.
+func (o *Object) HasOwnProperty(name string) bool {
+	return OwnProperty(o, name) != nil
+}
+
+func math_NaN() float64              { return math.NaN() }
+func math_Inf(sign int) float64      { return math.Inf(sign) }
+func isNaN(f float64) bool           { return math.IsNaN(f) }
+func isInf(f float64, sign int) bool { return math.IsInf(f, sign) }
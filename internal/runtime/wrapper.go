@@ -0,0 +1,71 @@
+package runtime
+
+import "fmt"
+
+// The internal slots that hold the primitive value of a wrapper object, as
+// new Number(1) or Object(true) create. String objects keep theirs in
+// "StringData", see SetStringData.
+const (
+	numberData  = "NumberData"
+	booleanData = "BooleanData"
+	symbolData  = "SymbolData"
+)
+
+// SetPrimitiveData makes obj a wrapper object for the number, boolean or
+// symbol v by storing v in the matching internal slot; new Number and new
+// Boolean use it on the object new created. A string makes obj a String
+// object, see SetStringData.
+func SetPrimitiveData(obj *Object, v *Value) {
+	if obj.Internal == nil {
+		obj.Internal = make(map[string]interface{})
+	}
+	switch v.Type {
+	case TypeNumber:
+		obj.Internal[numberData] = v.Number
+	case TypeBoolean:
+		obj.Internal[booleanData] = v.Bool
+	case TypeSymbol:
+		obj.Internal[symbolData] = v.Symbol
+	case TypeString:
+		SetStringData(obj, v.Str)
+	}
+}
+
+// PrimitiveData returns the primitive value wrapped by obj, and false if
+// obj is not a wrapper object.
+func PrimitiveData(obj *Object) (*Value, bool) {
+	if obj == nil || obj.Internal == nil {
+		return nil, false
+	}
+	if n, ok := obj.Internal[numberData].(float64); ok {
+		return NewNumber(n), true
+	}
+	if b, ok := obj.Internal[booleanData].(bool); ok {
+		return NewBool(b), true
+	}
+	if sym, ok := obj.Internal[symbolData].(*Symbol); ok {
+		return &Value{Type: TypeSymbol, Symbol: sym}, true
+	}
+	if s, ok := StringData(obj); ok {
+		return NewString(s), true
+	}
+	return nil, false
+}
+
+// ToObject converts v to an object as the ToObject operation does: an
+// object is returned as is, and a primitive is wrapped in a new wrapper
+// object whose prototype is that of its type in r. Undefined and null throw
+// a TypeError.
+func (r *Realm) ToObject(v *Value) (*Object, error) {
+	switch v.Type {
+	case TypeObject:
+		return v.Object, nil
+	case TypeUndefined, TypeNull:
+		return nil, fmt.Errorf("TypeError: Cannot convert undefined or null to object")
+	case TypeString:
+		return r.NewStringObject(v.Str), nil
+	}
+	obj := NewOrdinaryObject(r.PrimitivePrototype(v))
+	SetPrimitiveData(obj, v)
+	return obj, nil
+}
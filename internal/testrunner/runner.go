@@ -7,9 +7,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/example/jsgo/builtins"
-	"github.com/example/jsgo/interpreter"
-	"github.com/example/jsgo/runtime"
+	"github.com/example/jsgo/internal/builtins"
+	"github.com/example/jsgo/internal/interpreter"
+	"github.com/example/jsgo/internal/runtime"
 )
 
 type Result int
@@ -166,7 +166,7 @@ func runSingleTest(path, rel, baseHarness, harnessDir string) TestResult {
 	// Run with timeout
 	start := time.Now()
 	interp := interpreter.New()
-	builtins.RegisterAll(interp.GlobalEnv(), nil)
+	builtins.RegisterAll(interp.Realm(), interp.GlobalEnv(), nil)
 	registerTestNatives(interp)
 
 	resultCh := make(chan evalResult, 1)
@@ -433,37 +433,36 @@ func parseInlineList(s string) []string {
 
 func isUnsupportedFeature(feat string) bool {
 	unsupported := map[string]bool{
-		"SharedArrayBuffer":      true,
-		"Atomics":                true,
-		"WeakRef":                true,
-		"FinalizationRegistry":   true,
-		"import.meta":            true,
-		"dynamic-import":         true,
-		"top-level-await":        true,
-		"regexp-lookbehind":      true,
-		"regexp-named-groups":    true,
+		"SharedArrayBuffer":               true,
+		"Atomics":                         true,
+		"WeakRef":                         true,
+		"FinalizationRegistry":            true,
+		"import.meta":                     true,
+		"dynamic-import":                  true,
+		"top-level-await":                 true,
+		"regexp-lookbehind":               true,
+		"regexp-named-groups":             true,
 		"regexp-unicode-property-escapes": true,
-		"Intl":                   true,
-		"Temporal":               true,
-		"decorators":             true,
-		"import-assertions":      true,
-		"json-modules":           true,
-		"IsHTMLDDA":              true,
-		"Reflect.construct":      true,
-		"arrow-function":         false, // we support this
-		"generators":             true,
-		"async-functions":        true,
-		"async-iteration":        true,
-		"String.prototype.at":    true,
-		"Array.prototype.at":     true,
-		"Object.hasOwn":          true,
-		"array-grouping":         true,
-		"change-array-by-copy":   true,
-		"resizable-arraybuffer":  true,
-		"ArrayBuffer":            true,
-		"TypedArray":             true,
-		"DataView":               true,
-		"legacy-regexp":          true,
+		"Intl":                            true,
+		"Temporal":                        true,
+		"decorators":                      true,
+		"import-assertions":               true,
+		"json-modules":                    true,
+		"IsHTMLDDA":                       true,
+		"Reflect.construct":               true,
+		"arrow-function":                  false, // we support this
+		"generators":                      true,
+		"async-functions":                 true,
+		"async-iteration":                 true,
+		"String.prototype.at":             true,
+		"Array.prototype.at":              true,
+		"array-grouping":                  true,
+		"change-array-by-copy":            true,
+		"resizable-arraybuffer":           true,
+		"ArrayBuffer":                     true,
+		"TypedArray":                      true,
+		"DataView":                        true,
+		"legacy-regexp":                   true,
 	}
 	return unsupported[feat]
 }
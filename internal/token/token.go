@@ -0,0 +1,361 @@
+package token
+
+import "fmt"
+
+type TokenType int
+
+const (
+	// Literals
+	Illegal TokenType = iota
+	EOF
+	Identifier
+	Number
+	String
+	TemplateLiteral
+	RegExp
+	PrivateName // #name in a class body
+
+	// Operators
+	Plus
+	Minus
+	Asterisk
+	Slash
+	Percent
+	Exponent // **
+	Assign
+	PlusAssign
+	MinusAssign
+	AsteriskAssign
+	SlashAssign
+	PercentAssign
+	ExponentAssign
+	AmpersandAssign
+	PipeAssign
+	CaretAssign
+	LeftShiftAssign
+	RightShiftAssign
+	UnsignedRightShiftAssign
+	NullishAssign // ??=
+	AndAssign     // &&=
+	OrAssign      // ||=
+	Equal
+	NotEqual
+	StrictEqual
+	StrictNotEqual
+	LessThan
+	GreaterThan
+	LessThanOrEqual
+	GreaterThanOrEqual
+	And
+	Or
+	Not
+	BitwiseAnd
+	BitwiseOr
+	BitwiseXor
+	BitwiseNot
+	LeftShift
+	RightShift
+	UnsignedRightShift
+	Increment
+	Decrement
+
+	// Delimiters
+	LeftParen
+	RightParen
+	LeftBrace
+	RightBrace
+	LeftBracket
+	RightBracket
+	Semicolon
+	Colon
+	Comma
+	Dot
+	Spread // ...
+	Arrow  // =>
+	QuestionMark
+	OptionalChain   // ?.
+	NullishCoalesce // ??
+
+	// Keywords
+	Var
+	Let
+	Const
+	Function
+	Return
+	If
+	Else
+	While
+	For
+	Do
+	Break
+	Continue
+	Switch
+	Case
+	Default
+	Throw
+	Try
+	Catch
+	Finally
+	New
+	Delete
+	Typeof
+	Void
+	In
+	Instanceof
+	This
+	Class
+	Extends
+	Super
+	Import
+	Export
+	From
+	As
+	Of
+	Yield
+	Async
+	Await
+	True
+	False
+	Null
+	Undefined
+	Debugger
+	With
+
+	// Template literal parts
+	TemplateHead
+	TemplateMiddle
+	TemplateTail
+	NoSubstitutionTemplate
+)
+
+var tokenNames = [...]string{
+	Illegal:                  "Illegal",
+	EOF:                      "EOF",
+	Identifier:               "Identifier",
+	Number:                   "Number",
+	String:                   "String",
+	TemplateLiteral:          "TemplateLiteral",
+	RegExp:                   "RegExp",
+	PrivateName:              "PrivateName",
+	Plus:                     "Plus",
+	Minus:                    "Minus",
+	Asterisk:                 "Asterisk",
+	Slash:                    "Slash",
+	Percent:                  "Percent",
+	Exponent:                 "Exponent",
+	Assign:                   "Assign",
+	PlusAssign:               "PlusAssign",
+	MinusAssign:              "MinusAssign",
+	AsteriskAssign:           "AsteriskAssign",
+	SlashAssign:              "SlashAssign",
+	PercentAssign:            "PercentAssign",
+	ExponentAssign:           "ExponentAssign",
+	AmpersandAssign:          "AmpersandAssign",
+	PipeAssign:               "PipeAssign",
+	CaretAssign:              "CaretAssign",
+	LeftShiftAssign:          "LeftShiftAssign",
+	RightShiftAssign:         "RightShiftAssign",
+	UnsignedRightShiftAssign: "UnsignedRightShiftAssign",
+	NullishAssign:            "NullishAssign",
+	AndAssign:                "AndAssign",
+	OrAssign:                 "OrAssign",
+	Equal:                    "Equal",
+	NotEqual:                 "NotEqual",
+	StrictEqual:              "StrictEqual",
+	StrictNotEqual:           "StrictNotEqual",
+	LessThan:                 "LessThan",
+	GreaterThan:              "GreaterThan",
+	LessThanOrEqual:          "LessThanOrEqual",
+	GreaterThanOrEqual:       "GreaterThanOrEqual",
+	And:                      "And",
+	Or:                       "Or",
+	Not:                      "Not",
+	BitwiseAnd:               "BitwiseAnd",
+	BitwiseOr:                "BitwiseOr",
+	BitwiseXor:               "BitwiseXor",
+	BitwiseNot:               "BitwiseNot",
+	LeftShift:                "LeftShift",
+	RightShift:               "RightShift",
+	UnsignedRightShift:       "UnsignedRightShift",
+	Increment:                "Increment",
+	Decrement:                "Decrement",
+	LeftParen:                "LeftParen",
+	RightParen:               "RightParen",
+	LeftBrace:                "LeftBrace",
+	RightBrace:               "RightBrace",
+	LeftBracket:              "LeftBracket",
+	RightBracket:             "RightBracket",
+	Semicolon:                "Semicolon",
+	Colon:                    "Colon",
+	Comma:                    "Comma",
+	Dot:                      "Dot",
+	Spread:                   "Spread",
+	Arrow:                    "Arrow",
+	QuestionMark:             "QuestionMark",
+	OptionalChain:            "OptionalChain",
+	NullishCoalesce:          "NullishCoalesce",
+	Var:                      "Var",
+	Let:                      "Let",
+	Const:                    "Const",
+	Function:                 "Function",
+	Return:                   "Return",
+	If:                       "If",
+	Else:                     "Else",
+	While:                    "While",
+	For:                      "For",
+	Do:                       "Do",
+	Break:                    "Break",
+	Continue:                 "Continue",
+	Switch:                   "Switch",
+	Case:                     "Case",
+	Default:                  "Default",
+	Throw:                    "Throw",
+	Try:                      "Try",
+	Catch:                    "Catch",
+	Finally:                  "Finally",
+	New:                      "New",
+	Delete:                   "Delete",
+	Typeof:                   "Typeof",
+	Void:                     "Void",
+	In:                       "In",
+	Instanceof:               "Instanceof",
+	This:                     "This",
+	Class:                    "Class",
+	Extends:                  "Extends",
+	Super:                    "Super",
+	Import:                   "Import",
+	Export:                   "Export",
+	From:                     "From",
+	As:                       "As",
+	Of:                       "Of",
+	Yield:                    "Yield",
+	Async:                    "Async",
+	Await:                    "Await",
+	True:                     "True",
+	False:                    "False",
+	Null:                     "Null",
+	Undefined:                "Undefined",
+	Debugger:                 "Debugger",
+	With:                     "With",
+	TemplateHead:             "TemplateHead",
+	TemplateMiddle:           "TemplateMiddle",
+	TemplateTail:             "TemplateTail",
+	NoSubstitutionTemplate:   "NoSubstitutionTemplate",
+}
+
+// String returns the name of the token type, as used in the const block.
+func (t TokenType) String() string {
+	if t >= 0 && int(t) < len(tokenNames) {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
+type Token struct {
+	Type    TokenType
+	Literal string
+	Line    int
+	Column  int
+
+	// Source range covered by the token. Offset and EndOffset are byte
+	// offsets into the input; EndLine/EndColumn locate the position just
+	// past the token's last character.
+	Offset    int
+	EndOffset int
+	EndLine   int
+	EndColumn int
+
+	// Escaped is set on identifiers written with \u escape sequences. An
+	// escaped keyword is lexed as an Identifier so the parser can reject it
+	// where a keyword is required and check it where a name is.
+	Escaped bool
+	// Unterminated is set on the Illegal token of a template literal that
+	// the input ends inside, which more input could still complete.
+	Unterminated bool
+}
+
+var Keywords = map[string]TokenType{
+	"var":        Var,
+	"let":        Let,
+	"const":      Const,
+	"function":   Function,
+	"return":     Return,
+	"if":         If,
+	"else":       Else,
+	"while":      While,
+	"for":        For,
+	"do":         Do,
+	"break":      Break,
+	"continue":   Continue,
+	"switch":     Switch,
+	"case":       Case,
+	"default":    Default,
+	"throw":      Throw,
+	"try":        Try,
+	"catch":      Catch,
+	"finally":    Finally,
+	"new":        New,
+	"delete":     Delete,
+	"typeof":     Typeof,
+	"void":       Void,
+	"in":         In,
+	"instanceof": Instanceof,
+	"this":       This,
+	"class":      Class,
+	"extends":    Extends,
+	"super":      Super,
+	"import":     Import,
+	"export":     Export,
+	"from":       From,
+	"as":         As,
+	"of":         Of,
+	"yield":      Yield,
+	"async":      Async,
+	"await":      Await,
+	"true":       True,
+	"false":      False,
+	"null":       Null,
+	"undefined":  Undefined,
+	"debugger":   Debugger,
+	"with":       With,
+}
+
+func LookupIdentifier(ident string) TokenType {
+	if tok, ok := Keywords[ident]; ok {
+		return tok
+	}
+	return Identifier
+}
+
+// reservedWords are the ReservedWords of the grammar: they can never be used
+// as identifiers, whether or not they are written with escapes.
+var reservedWords = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true,
+	"delete": true, "do": true, "else": true, "enum": true, "export": true,
+	"extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "new": true, "null": true, "return": true,
+	"super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true,
+	"with": true, "yield": true,
+}
+
+// strictReservedWords are additionally reserved in strict mode code.
+var strictReservedWords = map[string]bool{
+	"implements": true, "interface": true, "let": true, "package": true,
+	"private": true, "protected": true, "public": true, "static": true,
+}
+
+// IsReservedWord reports whether name can never be an identifier in sloppy
+// script code. await and yield are excluded: scripts may use them as names
+// outside async functions and generators.
+func IsReservedWord(name string) bool {
+	return reservedWords[name] && name != "await" && name != "yield"
+}
+
+// IsStrictReservedWord reports whether name can never be an identifier in
+// strict mode code, which includes every module.
+func IsStrictReservedWord(name string) bool {
+	return reservedWords[name] || strictReservedWords[name]
+}
@@ -0,0 +1,554 @@
+// Package jsgo embeds a JavaScript interpreter in Go programs.
+//
+// This package is the supported API of the module and follows semantic
+// versioning: within a major version, exported identifiers are not removed
+// and their behaviour only changes to fix bugs. The packages under internal/
+// (lexer, parser, interpreter, runtime, builtins) are implementation details
+// and may change in any release.
+//
+// A Runtime is a single JavaScript realm with its own global scope:
+//
+//	rt := jsgo.New()
+//	rt.Set("greeting", "hello")
+//	v, err := rt.RunString(`greeting + ", world"`)
+//
+// Each Runtime has its own built-in objects, so a script changing
+// Array.prototype in one does not affect the others; see NewRealm. Each
+// Runtime made by New is also an agent of its own, with its own microtask
+// queue and timers, which RunLoop and DrainJobs run, and its own lock. A
+// Runtime and its values may be used from any goroutine: every method that
+// runs script code or touches script values holds the lock of the
+// Runtime's agent, which Go functions called from scripts and jobs queued
+// by the host run without, so they may call back into any Runtime. The
+// scripts of one agent run one at a time, while those of different agents
+// run in parallel.
+package jsgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/example/jsgo/internal/ast"
+	"github.com/example/jsgo/internal/bind"
+	"github.com/example/jsgo/internal/builtins"
+	"github.com/example/jsgo/internal/interpreter"
+	"github.com/example/jsgo/internal/parser"
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// Version is the semantic version of the public API.
+const Version = "1.0.0"
+
+// Runtime is a JavaScript realm: a global scope with the standard built-in
+// objects installed.
+type Runtime struct {
+	interp *interpreter.Interpreter
+	realm  *builtins.Realm
+	conv   *runtime.GoConverter // converts for ToValue and ExportTo
+	binder *bind.Binder         // binds structs for Bind
+}
+
+// New creates a Runtime with the standard built-ins registered, for a new
+// agent.
+func New() *Runtime {
+	return newRuntime(interpreter.New())
+}
+
+// newRuntime installs the built-ins in the realm of interp and returns the
+// Runtime running it.
+func newRuntime(interp *interpreter.Interpreter) *Runtime {
+	r := &Runtime{interp: interp}
+	defer r.lock()()
+	r.realm = builtins.RegisterAll(interp.Realm(), interp.GlobalEnv(), interp.GlobalObject().Object)
+	r.conv = r.newConverter()
+	r.binder = &bind.Binder{Conv: r.conv}
+	return r
+}
+
+// NewRealm creates a Runtime for a new realm, with a global scope and
+// built-in objects of its own, for running a script isolated from r's:
+// neither the globals set on r nor changes r's scripts make to the
+// built-ins are visible in it. It shares r's module loader and starts with
+// r's standard streams. The new Runtime belongs to r's agent: it shares
+// r's microtask queue, timers and lock, and values may pass between the
+// two.
+func (r *Runtime) NewRealm() *Runtime {
+	unlock := r.lock()
+	interp := r.interp.NewRealm()
+	unlock()
+	return newRuntime(interp)
+}
+
+// EnableTemporal adds the global Temporal namespace: PlainDate,
+// PlainDateTime, Duration and Now in the ISO 8601 calendar. It is opt-in
+// because only a subset of the Temporal API is implemented.
+func (r *Runtime) EnableTemporal() {
+	defer r.lock()()
+	r.realm.RegisterTemporal(r.interp.GlobalEnv())
+}
+
+// SeedRandom makes Math.random return the same sequence on every run with
+// the same seed, for reproducible tests and simulations. Without it,
+// Math.random uses Go's process-wide source.
+func (r *Runtime) SeedRandom(seed int64) {
+	defer r.lock()()
+	r.realm.SeedMathRandom(seed)
+}
+
+// SetMaxCallDepth sets the number of nested function calls scripts may
+// make, interpreter.DefaultMaxCallDepth by default. Deeper calls throw a
+// RangeError in the script. 0 removes the limit, and with it the protection
+// against runaway recursion overflowing the Go stack.
+func (r *Runtime) SetMaxCallDepth(n int) {
+	defer r.lock()()
+	r.interp.SetMaxCallDepth(n)
+}
+
+// Mode selects how a Runtime runs the bodies of functions.
+type Mode int
+
+const (
+	// TreeWalk, the mode of a new Runtime, evaluates every function by
+	// walking its syntax tree. It is the reference for the semantics of
+	// both modes.
+	TreeWalk Mode = iota + 1
+	// Compiled compiles the body of a plain function to bytecode the first
+	// time the function is called and runs the bytecode on a stack machine
+	// from then on. Functions the compiler does not support, such as async
+	// functions and generators, are walked as in TreeWalk.
+	Compiled
+)
+
+// SetMode selects how r runs functions from now on, TreeWalk by default.
+// The top level of scripts and modules is always walked.
+func (r *Runtime) SetMode(mode Mode) {
+	defer r.lock()()
+	r.interp.SetMode(mode.internal())
+}
+
+// internal returns the interpreter's mode for m.
+func (m Mode) internal() interpreter.Mode {
+	if m == Compiled {
+		return interpreter.Compiled
+	}
+	return interpreter.TreeWalk
+}
+
+// SetStdout makes console.log and console.info in scripts write to w
+// instead of os.Stdout, for hosts that capture script output.
+func (r *Runtime) SetStdout(w io.Writer) {
+	defer r.lock()()
+	r.interp.SetStdout(w)
+}
+
+// SetStderr makes console.error and console.warn write to w instead of
+// os.Stderr.
+func (r *Runtime) SetStderr(w io.Writer) {
+	defer r.lock()()
+	r.interp.SetStderr(w)
+}
+
+// SetStdin sets the reader that built-ins reading input read from, instead
+// of os.Stdin.
+func (r *Runtime) SetStdin(rd io.Reader) {
+	defer r.lock()()
+	r.interp.SetStdin(rd)
+}
+
+// RunString compiles and runs source as a global script and returns the
+// value of its last expression statement.
+func (r *Runtime) RunString(source string) (Value, error) {
+	prog, err := Compile(source)
+	if err != nil {
+		return Undefined(), err
+	}
+	return r.RunProgram(prog)
+}
+
+// RunProgram runs a compiled program as a global script. Pending promise
+// reactions are run before it returns. An uncaught throw is reported as an
+// *Exception.
+func (r *Runtime) RunProgram(prog *Program) (Value, error) {
+	defer r.lock()()
+	val, err := r.interp.Run(prog.program)
+	if err != nil {
+		return Undefined(), r.wrapError(err)
+	}
+	return r.value(val), nil
+}
+
+// RunFS runs the script at name in fsys, which may be an embed.FS, as a
+// global script, like RunProgram. Parsed scripts are cached by name for
+// the lifetime of r, so running the same file again skips the parse; fsys
+// is expected not to change. If no module resolver is set, RunFS also
+// sets FSResolver(fsys), so that RunModule loads modules from the same
+// file system.
+func (r *Runtime) RunFS(fsys fs.FS, name string) (Value, error) {
+	defer r.lock()()
+	val, err := r.interp.EvalFS(fsys, name)
+	if err != nil {
+		return Undefined(), r.wrapError(err)
+	}
+	return r.value(val), nil
+}
+
+// RunStringTagged is RunString under tag, an opaque label the host picks,
+// typically one per tenant. Usage is accounted to the tag in Stats, an
+// uncaught throw is an *Exception whose Tag is tag, and Interrupt(tag)
+// stops the evaluation. Source that does not compile is reported as by
+// RunString and not accounted.
+func (r *Runtime) RunStringTagged(tag, source string) (Value, error) {
+	prog, err := Compile(source)
+	if err != nil {
+		return Undefined(), err
+	}
+	return r.RunProgramTagged(tag, prog)
+}
+
+// RunProgramTagged is RunProgram under tag; see RunStringTagged.
+func (r *Runtime) RunProgramTagged(tag string, prog *Program) (Value, error) {
+	defer r.lock()()
+	val, err := r.interp.RunTagged(tag, prog.program)
+	if err != nil {
+		return Undefined(), r.wrapError(err)
+	}
+	return r.value(val), nil
+}
+
+// RunOptions limits the resources of a run, so that untrusted scripts
+// cannot hang the host. A zero field sets no limit.
+type RunOptions struct {
+	// Context stops the run at its next statement once it is done; use
+	// context.WithTimeout for a wall-clock limit.
+	Context context.Context
+	// MaxSteps is the number of statements the run may execute.
+	MaxSteps int64
+	// MaxCallDepth is the number of nested function calls allowed, if
+	// fewer than the Runtime's, see SetMaxCallDepth. Deeper calls throw a
+	// RangeError in the script.
+	MaxCallDepth int
+	// MaxHeapBytes is the approximate number of bytes the run may allocate
+	// for objects, array elements and strings. Allocating beyond it throws
+	// a RangeError ("heap limit exceeded") in the script.
+	MaxHeapBytes int64
+	// Mode is how the run runs functions, if not the Runtime's mode; see
+	// SetMode.
+	Mode Mode
+}
+
+// RunStringWithOptions is RunString within the limits of opts. A run that
+// uses up its steps or whose context is done returns a *LimitError.
+func (r *Runtime) RunStringWithOptions(source string, opts RunOptions) (Value, error) {
+	prog, err := Compile(source)
+	if err != nil {
+		return Undefined(), err
+	}
+	return r.RunProgramWithOptions(prog, opts)
+}
+
+// RunProgramWithOptions is RunProgram within the limits of opts; see
+// RunStringWithOptions.
+func (r *Runtime) RunProgramWithOptions(prog *Program, opts RunOptions) (Value, error) {
+	if opts.Context != nil {
+		// A run waiting for another to finish stops waiting once its
+		// context is done.
+		agent := r.interp.Agent()
+		if err := agent.LockContext(opts.Context); err != nil {
+			return Undefined(), &LimitError{Err: err}
+		}
+		defer agent.Unlock()
+	} else {
+		defer r.lock()()
+	}
+	if opts.Mode != 0 {
+		prev := r.interp.Mode()
+		r.interp.SetMode(opts.Mode.internal())
+		defer r.interp.SetMode(prev)
+	}
+	val, err := r.interp.RunWithOptions(prog.program, interpreter.EvalOptions{
+		Context:      opts.Context,
+		MaxSteps:     opts.MaxSteps,
+		MaxCallDepth: opts.MaxCallDepth,
+		MaxHeapBytes: opts.MaxHeapBytes,
+	})
+	if err != nil {
+		return Undefined(), r.wrapError(err)
+	}
+	return r.value(val), nil
+}
+
+// ModuleResolver locates the module an import specifier refers to.
+// referrer is the name of the importing module, or "" for the module
+// passed to RunModule. It returns the module's canonical name, which
+// identifies the module in the cache, and its source text.
+type ModuleResolver func(specifier, referrer string) (name, source string, err error)
+
+// FSResolver returns a ModuleResolver that loads modules from fsys, which
+// may be an embed.FS. Specifiers starting with "./" or "../" are resolved
+// against the directory of the importing module, and "/"-rooted ones
+// against the root of fsys, as is the module passed to RunModule. Module
+// names are the cleaned paths within fsys.
+func FSResolver(fsys fs.FS) ModuleResolver {
+	return ModuleResolver(interpreter.FSResolver(fsys))
+}
+
+// SetModuleResolver sets the resolver that RunModule and the import
+// declarations of modules load modules with.
+func (r *Runtime) SetModuleResolver(resolve ModuleResolver) {
+	defer r.lock()()
+	r.interp.SetModuleResolver(interpreter.ModuleResolver(resolve))
+}
+
+// RunModule loads the module specifier with the module resolver, runs it
+// and the modules it imports, and returns its namespace object. Each
+// module runs at most once per Runtime; loading it again returns the same
+// namespace.
+func (r *Runtime) RunModule(specifier string) (Value, error) {
+	defer r.lock()()
+	val, err := r.interp.EvalModule(specifier)
+	if err != nil {
+		return Undefined(), r.wrapError(err)
+	}
+	return r.value(val), nil
+}
+
+// RunLoop runs the timers r's scripts set with setTimeout and setInterval,
+// waiting for each to come due, until none are left or ctx is done, in
+// which case it returns ctx.Err(). A callback that throws stops the loop
+// with an *Exception.
+func (r *Runtime) RunLoop(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	agent := r.interp.Agent()
+	if err := agent.LockContext(ctx); err != nil {
+		return err
+	}
+	defer agent.Unlock()
+	return r.wrapError(r.interp.RunLoop(ctx))
+}
+
+// EnqueueJob appends job to the microtask queue, where promise reactions
+// and queueMicrotask callbacks wait. The queue is drained after each run
+// and timer callback, or by DrainJobs, so a host with its own event loop
+// can interleave its work with the script's. An error job returns is
+// reported by the drain as an uncaught exception. job runs without the
+// lock, like a Func, so it may call back into any Runtime.
+func (r *Runtime) EnqueueJob(job func() error) {
+	defer r.lock()()
+	r.interp.EnqueueJob(func() (err error) {
+		r.interp.Agent().Unlocked(func() { err = job() })
+		return throwException(err)
+	})
+}
+
+// DrainJobs runs the microtask queue until it is empty. A job that throws
+// stops it with an *Exception, leaving the jobs after it queued.
+func (r *Runtime) DrainJobs() error {
+	defer r.lock()()
+	return r.wrapError(r.interp.DrainJobs())
+}
+
+// Interrupt stops the running evaluations tagged tag at their next
+// statement; they return an *InterruptedError. It reports whether one was
+// running. Interrupt, unlike the other methods, does not wait for the
+// running script, so it may stop one running on another goroutine.
+func (r *Runtime) Interrupt(tag string) bool {
+	return r.interp.Interrupt(tag)
+}
+
+// Stats is the resource usage accounted to one tag.
+type Stats struct {
+	Runs       int64         // tagged runs started
+	Errors     int64         // runs that ended with an error, interrupts included
+	Interrupts int64         // runs stopped by Interrupt
+	Statements int64         // statements executed, not counting runs nested under another tag
+	Duration   time.Duration // wall time, including nested runs
+}
+
+// Stats returns the usage accounted to tag so far. It may be called from
+// any goroutine, also while the tag is running.
+func (r *Runtime) Stats(tag string) Stats {
+	s := r.interp.TagStats(tag)
+	return Stats{
+		Runs:       s.Evals,
+		Errors:     s.Errors,
+		Interrupts: s.Interrupts,
+		Statements: s.Statements,
+		Duration:   s.Duration,
+	}
+}
+
+// Tags returns every tag with usage recorded, sorted.
+func (r *Runtime) Tags() []string {
+	tags := r.interp.Tags()
+	sort.Strings(tags)
+	return tags
+}
+
+// Set binds a global variable to x, converted with ToValue in r's realm.
+func (r *Runtime) Set(name string, x interface{}) error {
+	defer r.lock()()
+	val, err := r.toValue(x)
+	if err != nil {
+		return err
+	}
+	env := r.interp.GlobalEnv()
+	if _, ok := env.GetBinding(name); ok {
+		return r.wrapError(env.Set(name, val.raw()))
+	}
+	return env.Declare(name, "var", val.raw())
+}
+
+// MethodOption configures one method of an object set with SetObject.
+// Methods without an option get length 0 and are writable, configurable
+// and non-enumerable, like built-in methods.
+type MethodOption struct {
+	Method     string // name of the method the option applies to
+	Length     int    // value of the function's length property
+	ReadOnly   bool   // make the method non-writable and non-configurable
+	Enumerable bool   // list the method in for-in and Object.keys
+}
+
+// SetObject binds the global variable name to an object whose methods are
+// the Go functions of methods, so that a host API is reachable as
+// host.readConfig() rather than as separate globals. If the global already
+// holds an object, the methods are added to it. The object is set up
+// before the next script runs.
+func (r *Runtime) SetObject(name string, methods map[string]Func, options ...MethodOption) {
+	defer r.lock()()
+	natives := make(map[string]runtime.CallableFunc, len(methods))
+	for method, fn := range methods {
+		natives[method] = r.wrapFunc(fn)
+	}
+	opts := make([]interpreter.NativeMethodOption, len(options))
+	for i, o := range options {
+		opts[i] = interpreter.NativeMethodOption(o)
+	}
+	r.interp.RegisterNativeObject(name, natives, opts...)
+}
+
+// Get returns the value of a global variable, or undefined if it does not
+// exist.
+func (r *Runtime) Get(name string) Value {
+	defer r.lock()()
+	val, err := r.interp.GlobalEnv().Get(name)
+	if err != nil || val == nil {
+		return Undefined()
+	}
+	return r.value(val)
+}
+
+// Program is a parsed script. It is immutable and may be run any number of
+// times, on any Runtime.
+type Program struct {
+	program *ast.Program
+}
+
+// Compile parses source as a script. A parse failure is reported as a
+// *SyntaxError describing the first error found.
+func Compile(source string) (*Program, error) {
+	program, errs := parser.New(source).ParseProgram()
+	if len(errs) > 0 {
+		var perr *parser.SyntaxError
+		if errors.As(errs[0], &perr) {
+			return nil, &SyntaxError{Message: perr.Message, Line: perr.Line, Column: perr.Column}
+		}
+		return nil, &SyntaxError{Message: errs[0].Error()}
+	}
+	return &Program{program: program}, nil
+}
+
+// SyntaxError reports source that could not be parsed.
+type SyntaxError struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("SyntaxError: %s (%d:%d)", e.Message, e.Line, e.Column)
+}
+
+// Exception is a JavaScript value thrown and not caught by script code.
+type Exception struct {
+	value Value
+	msg   string
+	tag   string
+}
+
+func (e *Exception) Error() string {
+	return e.msg
+}
+
+// Value returns the thrown value.
+func (e *Exception) Value() Value {
+	return e.value
+}
+
+// Tag returns the tag of the run that threw, or "" for an untagged run.
+func (e *Exception) Tag() string {
+	return e.tag
+}
+
+// InterruptedError is returned by a tagged run stopped by Interrupt.
+type InterruptedError struct {
+	Tag string
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("run %q interrupted", e.Tag)
+}
+
+// ErrStepLimit is the cause of a LimitError for a run that used up its
+// RunOptions.MaxSteps.
+var ErrStepLimit = interpreter.ErrStepLimit
+
+// LimitError is returned by a run stopped by its RunOptions. Err is
+// ErrStepLimit or the error of the done context, such as
+// context.DeadlineExceeded; errors.Is sees through to it.
+type LimitError struct {
+	Err error
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("run stopped: %v", e.Err)
+}
+
+func (e *LimitError) Unwrap() error { return e.Err }
+
+// Throw returns an error that, when returned from a Func, throws v in the
+// calling script.
+func Throw(v Value) error {
+	return &Exception{value: v, msg: v.String()}
+}
+
+// wrapError converts an error from the interpreter into an *Exception when it
+// carries a thrown value, a Value of r.
+func (r *Runtime) wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var interrupted *interpreter.InterruptedError
+	if errors.As(err, &interrupted) {
+		return &InterruptedError{Tag: interrupted.Tag}
+	}
+	var limit *interpreter.LimitError
+	if errors.As(err, &limit) {
+		return &LimitError{Err: limit.Err}
+	}
+	tag := ""
+	var tagged *interpreter.TaggedError
+	if errors.As(err, &tagged) {
+		tag, err = tagged.Tag, tagged.Err
+	}
+	if val, ok := runtime.ThrownValue(err); ok {
+		return &Exception{value: r.value(val), msg: err.Error(), tag: tag}
+	}
+	return err
+}
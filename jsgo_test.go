@@ -0,0 +1,769 @@
+package jsgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/example/jsgo/internal/interpreter"
+)
+
+func TestRunString(t *testing.T) {
+	rt := New()
+	v, err := rt.RunString(`var x = 20; x + 22`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if v.Type() != TypeNumber || v.Float() != 42 {
+		t.Errorf("expected 42, got %v", v)
+	}
+	if got := rt.Get("x").Float(); got != 20 {
+		t.Errorf("expected global x = 20, got %v", got)
+	}
+	if !rt.Get("missing").IsUndefined() {
+		t.Errorf("expected undefined for a missing global")
+	}
+}
+
+func TestSetAndExport(t *testing.T) {
+	rt := New()
+	if err := rt.Set("config", map[string]interface{}{
+		"name":  "jsgo",
+		"sizes": []interface{}{1, 2.5},
+		"debug": true,
+	}); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	v, err := rt.RunString(`({ name: config.name.toUpperCase(), total: config.sizes[0] + config.sizes[1], debug: !config.debug, list: [null, "a"] })`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":  "JSGO",
+		"total": 3.5,
+		"debug": false,
+		"list":  []interface{}{nil, "a"},
+	}
+	if got := v.Export(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Export() = %#v, want %#v", got, want)
+	}
+	if _, err := rt.ToValue(make(chan int)); err == nil {
+		t.Errorf("expected an error converting a channel")
+	}
+}
+
+func TestClone(t *testing.T) {
+	rt := New()
+	v, err := rt.RunString(`var snap = { list: [1, { n: 2 }], when: new Date(0) }; snap.self = snap; snap`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := v.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.RunString(`snap.list[1].n = 3`); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Get("list").Get("1").Get("n").Float(); n != 2 {
+		t.Errorf("the clone is unaffected by changes to the original, got n = %v", n)
+	}
+	if c.Get("self").Get("when").Get("getTime").IsUndefined() {
+		t.Error("a cycle is cloned to the copy and a Date stays a Date")
+	}
+
+	other := New()
+	if err := other.Set("snap", c); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := other.RunString(`snap.self === snap && snap.list.length`); err != nil || got.Float() != 2 {
+		t.Errorf("the clone can be used by another runtime: %v, %v", got, err)
+	}
+
+	fn, _ := rt.RunString(`(function () {})`)
+	if _, err := fn.Clone(); err == nil {
+		t.Error("a function cannot be cloned")
+	}
+}
+
+func TestBridging(t *testing.T) {
+	type Item struct {
+		Name  string `js:"name"`
+		Price float64
+		Tags  []string `js:"tags"`
+		note  string
+		Skip  int `js:"-"`
+	}
+	type Order struct {
+		ID    int               `js:"id"`
+		Items []*Item           `js:"items"`
+		Meta  map[string]string `js:"meta"`
+	}
+	rt := New()
+	rt.Set("order", Order{ID: 7, Items: []*Item{{Name: "pen", Price: 1.5, Tags: []string{"a"}, note: "x", Skip: 1}}, Meta: map[string]string{"k": "v"}})
+	rt.Set("discount", func(price float64, pct int) (float64, error) {
+		if pct < 0 || pct > 100 {
+			return 0, errors.New("RangeError: bad percentage")
+		}
+		return price * float64(100-pct) / 100, nil
+	})
+	rt.Set("sum", func(xs ...int) int {
+		total := 0
+		for _, x := range xs {
+			total += x
+		}
+		return total
+	})
+
+	v, err := rt.RunString(`
+		var it = order.items[0];
+		var err;
+		try { discount(1, 200); } catch (e) { err = e.name + ": " + e.message; }
+		({ id: order.id + 1, items: [{ name: it.name + "s", Price: discount(it.Price, 50), tags: it.tags.concat("b"), note: typeof it.note, Skip: typeof it.Skip }],
+		   meta: { k: order.meta.k, err: err, sum: String(sum(1, 2, 3)) } })`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	var got Order
+	if err := v.ExportTo(&got); err != nil {
+		t.Fatalf("ExportTo error: %v", err)
+	}
+	want := Order{ID: 8, Items: []*Item{{Name: "pens", Price: 0.75, Tags: []string{"a", "b"}}}, Meta: map[string]string{
+		"k": "v", "err": "RangeError: bad percentage", "sum": "6",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExportTo = %+v, want %+v", got, want)
+	}
+
+	if _, err := rt.RunString(`discount("1", 5)`); err == nil {
+		t.Errorf("expected a TypeError for a string argument")
+	}
+	var n int
+	if err := v.Get("id").ExportTo(&n); err != nil || n != 8 {
+		t.Errorf("ExportTo int: %v, %v", n, err)
+	}
+	if err := v.Get("meta").ExportTo(&n); err == nil {
+		t.Errorf("expected an error exporting an object to int")
+	}
+
+	// JavaScript functions export to Go functions.
+	fnVal, _ := rt.RunString(`(function (a, b) { if (b === 0) throw new Error("div by zero"); return a / b; })`)
+	var div func(float64, float64) (float64, error)
+	if err := fnVal.ExportTo(&div); err != nil {
+		t.Fatal(err)
+	}
+	if q, err := div(6, 3); err != nil || q != 2 {
+		t.Errorf("div(6, 3) = %v, %v", q, err)
+	}
+	var ex *Exception
+	if _, err := div(1, 0); !errors.As(err, &ex) {
+		t.Errorf("div(1, 0): expected an *Exception, got %v", err)
+	}
+}
+
+type account struct {
+	Owner   string `js:"owner"`
+	balance int
+}
+
+func (a *account) Deposit(n int) int { a.balance += n; return a.balance }
+
+func (a *account) Withdraw(n int) error {
+	if n > a.balance {
+		return errors.New("RangeError: insufficient funds")
+	}
+	a.balance -= n
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	rt := New()
+	acct := &account{Owner: "ann"}
+	v, err := rt.Bind(acct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt.Set("acct", v)
+	rt.Set("check", func(err Value) bool { return err.Get("name").String() == "RangeError" })
+	res, err := rt.RunString(`
+		acct.Deposit(10);
+		var ok;
+		try { acct.Withdraw(50); } catch (e) { ok = check(e); }
+		acct.owner = "bob";
+		acct.Withdraw(4);
+		[acct.Deposit(0), ok, acct.owner].join();`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != "6,true,bob" || acct.Owner != "bob" || acct.balance != 6 {
+		t.Errorf("got %q, account %+v", res.String(), acct)
+	}
+	if _, err := rt.Bind("text"); err != nil {
+		t.Errorf("Bind of a string: %v", err)
+	}
+}
+
+func TestFuncAndCall(t *testing.T) {
+	rt := New()
+	rt.Set("add", Func(func(this Value, args []Value) (Value, error) {
+		if len(args) != 2 {
+			return Undefined(), errors.New("TypeError: add expects 2 arguments")
+		}
+		return rt.ToValue(args[0].Float() + args[1].Float())
+	}))
+	rt.Set("fail", Func(func(this Value, args []Value) (Value, error) {
+		return Undefined(), Throw(args[0])
+	}))
+	v, err := rt.RunString(`
+		var r = [add(1, 2)];
+		try { add(1); } catch (e) { r.push(e instanceof TypeError); }
+		try { fail("custom"); } catch (e) { r.push(e); }
+		r.join(",");
+	`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if v.String() != "3,true,custom" {
+		t.Errorf("expected 3,true,custom, got %q", v.String())
+	}
+
+	fn, err := rt.RunString(`(function (a, b) { return this.base + a * b; })`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	this, _ := rt.ToValue(map[string]interface{}{"base": 1})
+	a, _ := rt.ToValue(3)
+	b, _ := rt.ToValue(4)
+	res, err := fn.Call(this, a, b)
+	if err != nil || res.Float() != 13 {
+		t.Errorf("Call() = %v, %v; want 13", res, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	rt := New()
+	_, err := rt.RunString(`throw new RangeError("too far")`)
+	var ex *Exception
+	if !errors.As(err, &ex) {
+		t.Fatalf("expected *Exception, got %T: %v", err, err)
+	}
+	if ex.Value().Get("message").String() != "too far" || ex.Error() != "RangeError: too far at <anonymous>:1:1" {
+		t.Errorf("unexpected exception %q", ex.Error())
+	}
+
+	_, err = Compile("let x = (1;")
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected *SyntaxError, got %T: %v", err, err)
+	}
+	if serr.Line != 1 {
+		t.Errorf("expected line 1, got %d", serr.Line)
+	}
+}
+
+func TestCompileOnce(t *testing.T) {
+	prog, err := Compile(`typeof counter === "number" ? ++counter : (counter = 1)`)
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	rt1, rt2 := New(), New()
+	for i, want := range []float64{1, 2, 3} {
+		v, err := rt1.RunProgram(prog)
+		if err != nil || v.Float() != want {
+			t.Errorf("run %d: got %v, %v; want %v", i, v, err, want)
+		}
+	}
+	if v, _ := rt2.RunProgram(prog); v.Float() != 1 {
+		t.Errorf("expected a fresh counter in the second runtime, got %v", v)
+	}
+}
+
+func TestEnableTemporal(t *testing.T) {
+	rt := New()
+	if v, _ := rt.RunString(`typeof Temporal`); v.String() != "undefined" {
+		t.Fatalf("expected no Temporal by default, got %v", v)
+	}
+	rt.EnableTemporal()
+	v, err := rt.RunString(`
+		var start = Temporal.PlainDate.from("2024-01-31");
+		var due = start.add({ months: 1, days: 3 });
+		[due.toString(), start.until(due).toString(), due.dayOfWeek].join(" ")
+	`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if want := "2024-03-03 P32D 7"; v.String() != want {
+		t.Errorf("expected %q, got %q", want, v.String())
+	}
+}
+
+func TestSeedRandom(t *testing.T) {
+	run := func() string {
+		rt := New()
+		rt.SeedRandom(42)
+		v, err := rt.RunString(`[Math.random(), Math.random(), Math.random()].join(",")`)
+		if err != nil {
+			t.Fatalf("RunString error: %v", err)
+		}
+		return v.String()
+	}
+	if a, b := run(), run(); a != b {
+		t.Errorf("expected the same sequence for the same seed, got %q and %q", a, b)
+	}
+
+	// Seeding does not depend on the script leaving the global Math alone.
+	rt := New()
+	if _, err := rt.RunString(`var real = Math; Math = {};`); err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	rt.SeedRandom(42)
+	v, err := rt.RunString(`[real.random(), real.random(), real.random()].join(",")`)
+	if err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if want := run(); v.String() != want {
+		t.Errorf("expected %q after replacing Math, got %q", want, v.String())
+	}
+}
+
+func TestStreams(t *testing.T) {
+	// Each runtime writes to its own streams.
+	var out1, out2, errOut bytes.Buffer
+	rt1, rt2 := New(), New()
+	rt1.SetStdout(&out1)
+	rt1.SetStderr(&errOut)
+	rt2.SetStdout(&out2)
+	if _, err := rt1.RunString(`console.log("one", 1); console.error("oops")`); err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if _, err := rt2.RunString(`console.log({ two: 2 })`); err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if _, err := rt1.RunString(`Promise.resolve().then(() => console.info("later"))`); err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	if got := out1.String(); got != "one 1\nlater\n" {
+		t.Errorf("stdout of the first runtime: got %q", got)
+	}
+	if got := errOut.String(); got != "oops\n" {
+		t.Errorf("stderr of the first runtime: got %q", got)
+	}
+	if got := out2.String(); got != "{ two: 2 }\n" {
+		t.Errorf("stdout of the second runtime: got %q", got)
+	}
+}
+
+func TestRunLoop(t *testing.T) {
+	var out bytes.Buffer
+	rt := New()
+	rt.SetStdout(&out)
+	if _, err := rt.RunString(`
+		setTimeout(() => console.log("timeout"), 5);
+		setTimeout(() => { throw new Error("late"); }, 10);
+		console.log("script");
+	`); err != nil {
+		t.Fatalf("RunString error: %v", err)
+	}
+	err := rt.RunLoop(context.Background())
+	var exc *Exception
+	if !errors.As(err, &exc) || exc.Value().Get("message").String() != "late" {
+		t.Errorf("expected the thrown Error, got %v", err)
+	}
+	if got := out.String(); got != "script\ntimeout\n" {
+		t.Errorf("stdout: got %q", got)
+	}
+}
+
+func TestConsoleLogFormatting(t *testing.T) {
+	var out bytes.Buffer
+	rt := New()
+	rt.SetStdout(&out)
+	if _, err := rt.RunString(`
+		class K {}
+		class D extends K {}
+		console.log(K, D, class {});
+		console.log("%d %i", 42.5, 42.5);
+	`); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "[class K] [class D extends K] [class (anonymous)]\n42.5 42\n"; got != want {
+		t.Errorf("stdout: got %q, want %q", got, want)
+	}
+}
+
+func TestJobQueue(t *testing.T) {
+	var out bytes.Buffer
+	rt := New()
+	rt.SetStdout(&out)
+	if _, err := rt.RunString(`queueMicrotask(() => console.log("microtask")); console.log("script")`); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "script\nmicrotask\n" {
+		t.Errorf("stdout: got %q", got)
+	}
+
+	out.Reset()
+	rt.EnqueueJob(func() error { out.WriteString("first\n"); return nil })
+	rt.EnqueueJob(func() error { return errors.New("TypeError: host job failed") })
+	rt.EnqueueJob(func() error { out.WriteString("last\n"); return nil })
+	err := rt.DrainJobs()
+	var exc *Exception
+	if !errors.As(err, &exc) || exc.Value().Get("name").String() != "TypeError" {
+		t.Errorf("expected the failing job's TypeError, got %v", err)
+	}
+	if got := out.String(); got != "first\n" {
+		t.Errorf("stdout: got %q", got)
+	}
+	if err := rt.DrainJobs(); err != nil || out.String() != "first\nlast\n" {
+		t.Errorf("the jobs after a failure stay queued: %v, %q", err, out.String())
+	}
+}
+
+func TestRunWithOptions(t *testing.T) {
+	rt := New()
+	_, err := rt.RunStringWithOptions(`for (;;) {}`, RunOptions{MaxSteps: 100})
+	var limit *LimitError
+	if !errors.As(err, &limit) || !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("expected a step *LimitError, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := rt.RunStringWithOptions(`while (true) {}`, RunOptions{Context: ctx}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	_, err = rt.RunStringWithOptions(`function f() { return f(); } f();`, RunOptions{MaxCallDepth: 100})
+	var ex *Exception
+	if !errors.As(err, &ex) || ex.Value().Get("name").String() != "RangeError" {
+		t.Errorf("expected an uncaught RangeError, got %v", err)
+	}
+
+	_, err = rt.RunStringWithOptions(`var s = "x"; for (;;) { s += s; }`, RunOptions{MaxHeapBytes: 1 << 20})
+	if !errors.As(err, &ex) || ex.Value().Get("message").String() != "heap limit exceeded" {
+		t.Errorf("expected a heap limit RangeError, got %v", err)
+	}
+}
+
+func TestCyclicAndDeepArrays(t *testing.T) {
+	// Converting an array that contains itself, or arrays nested deeper
+	// than the call depth, runs natively: neither may crash the process.
+	rt := New()
+	v, err := rt.RunString(`var a = []; a[0] = a; a[1] = [1, a]; String(a) + "|" + a.join("-")`)
+	if err != nil || v.String() != ",1,|-1," {
+		t.Errorf("cyclic array: got %v, %v", v, err)
+	}
+	_, err = rt.RunString(`var b = []; for (var i = 0; i < 100000; i++) b = [b]; String(b)`)
+	var ex *Exception
+	if !errors.As(err, &ex) || ex.Value().Get("name").String() != "RangeError" {
+		t.Errorf("deep array: expected an uncaught RangeError, got %v", err)
+	}
+}
+
+func TestTaggedRuns(t *testing.T) {
+	rt := New()
+	if _, err := rt.RunStringTagged("tenant-a", `var total = 0; [1, 2, 3].forEach(function (n) { total += n; });`); err != nil {
+		t.Fatalf("RunStringTagged error: %v", err)
+	}
+	_, err := rt.RunStringTagged("tenant-b", `throw new TypeError("bad input")`)
+	var ex *Exception
+	if !errors.As(err, &ex) || ex.Tag() != "tenant-b" || ex.Error() != "TypeError: bad input at <anonymous>:1:1 [tenant-b]" {
+		t.Fatalf("expected a tagged *Exception, got %v", err)
+	}
+	stack, err := rt.RunStringTagged("tenant-b", "function f() {\n  return new Error('x').stack;\n}\nf();")
+	if want := "Error: x\n    at f (<anonymous>:2:10) [tenant-b]\n    at <anonymous>:4:1 [tenant-b]"; err != nil || stack.String() != want {
+		t.Errorf("expected the tag in the stack trace, got %q, %v", stack, err)
+	}
+	if _, err := rt.RunString(`throw 1`); !errors.As(err, &ex) || ex.Tag() != "" {
+		t.Errorf("expected an untagged *Exception, got %v", err)
+	}
+
+	rt.Set("check", Func(func(this Value, args []Value) (Value, error) {
+		if !rt.Interrupt("tenant-a") {
+			t.Errorf("Interrupt should report tenant-a as running")
+		}
+		return Undefined(), nil
+	}))
+	_, err = rt.RunStringTagged("tenant-a", `check(); for (;;) {}`)
+	var ierr *InterruptedError
+	if !errors.As(err, &ierr) || ierr.Tag != "tenant-a" {
+		t.Fatalf("expected an *InterruptedError, got %v", err)
+	}
+
+	a := rt.Stats("tenant-a")
+	if a.Runs != 2 || a.Errors != 1 || a.Interrupts != 1 || a.Statements == 0 {
+		t.Errorf("stats for tenant-a: %+v", a)
+	}
+	if got := rt.Tags(); !reflect.DeepEqual(got, []string{"tenant-a", "tenant-b"}) {
+		t.Errorf("Tags() = %v", got)
+	}
+}
+
+func TestNewRealm(t *testing.T) {
+	rt := New()
+	if err := rt.Set("double", func(n float64) float64 { return 2 * n }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.RunString(`
+		Array.prototype.first = function () { return this[0]; };
+		String.prototype.shout = function () { return this.toUpperCase(); };
+		var secret = 1;
+	`); err != nil {
+		t.Fatal(err)
+	}
+	realm := rt.NewRealm()
+	got, err := realm.RunString(`[typeof [].first, typeof "".shout, typeof secret, [1, 2].map(x => x * 2).join()].join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "undefined,undefined,undefined,2,4"; got.String() != want {
+		t.Errorf("new realm sees %q, want %q", got.String(), want)
+	}
+	if _, err := realm.RunString(`Object.prototype.polluted = true`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Running in one realm leaves the other's built-ins intact.
+	got, err = rt.RunString(`[[5].first(), "a".shout(), ({}).polluted, [..."ab"].length].join()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "5,A,,2"; got.String() != want {
+		t.Errorf("original realm sees %q, want %q", got.String(), want)
+	}
+
+	got, err = realm.RunString(`typeof double + "," + (Symbol.iterator in []) + "," + ({}).polluted`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "undefined,true,true"; got.String() != want {
+		t.Errorf("realm globals: got %q, want %q", got.String(), want)
+	}
+}
+
+func TestConcurrentRuns(t *testing.T) {
+	shared := New()
+	if _, err := shared.RunString(`var hits = 0`); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rt := New()
+			// A Go function called from a script may run another script.
+			if err := rt.Set("bump", func() (Value, error) {
+				return shared.RunString(`++hits`)
+			}); err != nil {
+				t.Error(err)
+				return
+			}
+			for j := 0; j < 20; j++ {
+				v, err := rt.RunString(`[1, 2, 3].map(x => x * 2).join() + "/" + typeof bump()`)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := v.String(); got != "2,4,6/number" {
+					t.Errorf("goroutine %d: got %q", i, got)
+				}
+				if _, err := shared.Get("hits").Clone(); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := shared.Get("hits").Float(); n != 160 {
+		t.Errorf("hits = %v, want 160", n)
+	}
+}
+
+func TestEventQueuePerRuntime(t *testing.T) {
+	var outA, outB bytes.Buffer
+	a, b := New(), New()
+	a.SetStdout(&outA)
+	b.SetStdout(&outB)
+	if _, err := a.RunString(`setTimeout(() => console.log("timer of a"), 1)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RunLoop(context.Background()); err != nil || outB.Len() != 0 {
+		t.Errorf("b.RunLoop ran a's timer: %v, %q", err, outB.String())
+	}
+
+	// A job queued by the host may run scripts, on its own runtime too.
+	a.EnqueueJob(func() error {
+		_, err := a.RunString(`console.log("job of a")`)
+		return err
+	})
+	if err := b.DrainJobs(); err != nil || outA.Len() != 0 {
+		t.Errorf("b.DrainJobs ran a's job: %v, %q", err, outA.String())
+	}
+	if err := a.RunLoop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := outA.String(); got != "job of a\ntimer of a\n" {
+		t.Errorf("stdout of a: got %q", got)
+	}
+}
+
+func TestRunWaitsWithContext(t *testing.T) {
+	busy := New()
+	waiting, parallel := busy.NewRealm(), New()
+	started := make(chan struct{})
+	busy.Set("started", func() { close(started) })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := busy.RunStringWithOptions(`started(); for (;;) {}`, RunOptions{Context: ctx})
+		done <- err
+	}()
+	<-started
+
+	// Another agent runs while busy does.
+	if v, err := parallel.RunString(`6 * 7`); err != nil || v.Float() != 42 {
+		t.Errorf("run of another agent: got %v, %v", v, err)
+	}
+
+	// A realm of busy's agent waits for it, until the context of its run
+	// ends.
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer waitCancel()
+	var limit *LimitError
+	if _, err := waiting.RunStringWithOptions(`1`, RunOptions{Context: waitCtx}); !errors.As(err, &limit) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout while waiting, got %v", err)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("the busy run: got %v", err)
+	}
+}
+
+func TestRunModule(t *testing.T) {
+	rt := New()
+	if _, err := rt.RunModule("main.js"); err == nil {
+		t.Error("RunModule without a resolver: expected an error")
+	}
+
+	fsys := fstest.MapFS{
+		"main.js":     {Data: []byte(`import { double } from "./lib/math.js"; export const answer = double(21);`)},
+		"lib/math.js": {Data: []byte(`export function double(x) { return 2 * x; }`)},
+	}
+	rt.SetModuleResolver(FSResolver(fsys))
+	ns, err := rt.RunModule("main.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ns.Get("answer").Float(); got != 42 {
+		t.Errorf("answer = %v, want 42", got)
+	}
+
+	var loaded []string
+	rt = New()
+	rt.SetModuleResolver(func(specifier, referrer string) (string, string, error) {
+		loaded = append(loaded, specifier)
+		if specifier == "dep" {
+			return "dep", `export default "from dep";`, nil
+		}
+		return specifier, `import dep from "dep"; throw new Error(dep);`, nil
+	})
+	_, err = rt.RunModule("entry")
+	var exc *Exception
+	if !errors.As(err, &exc) || exc.Value().Get("message").String() != "from dep" {
+		t.Errorf("a throwing module: got %v", err)
+	}
+	if !reflect.DeepEqual(loaded, []string{"entry", "dep"}) {
+		t.Errorf("resolver calls = %q", loaded)
+	}
+}
+
+func TestRunFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scripts/init.js": {Data: []byte(`var runs = (typeof runs === "number" ? runs : 0) + 1; runs`)},
+		"scripts/lib.js":  {Data: []byte(`export const name = "lib";`)},
+	}
+	rt := New()
+	for want := 1.0; want <= 2; want++ {
+		v, err := rt.RunFS(fsys, "/scripts/init.js")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Float() != want {
+			t.Errorf("run %v returned %v", want, v)
+		}
+	}
+	if _, err := rt.RunFS(fsys, "missing.js"); err == nil {
+		t.Error("RunFS of a missing file: expected an error")
+	}
+
+	// The file system becomes the root of module resolution.
+	ns, err := rt.RunModule("scripts/lib.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ns.Get("name").String(); got != "lib" {
+		t.Errorf("module export = %q", got)
+	}
+}
+
+func TestSetObject(t *testing.T) {
+	rt := New()
+	var logged []string
+	rt.SetObject("host", map[string]Func{
+		"log": func(this Value, args []Value) (Value, error) {
+			logged = append(logged, args[0].String())
+			return Undefined(), nil
+		},
+		"version": func(this Value, args []Value) (Value, error) {
+			return rt.ToValue("1.2")
+		},
+	}, MethodOption{Method: "log", Length: 1, ReadOnly: true})
+	v, err := rt.RunString(`
+		host.log("hi");
+		host.log = null;
+		[host.version(), host.log.length, Object.keys(host).length].join()
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.String(); got != "1.2,1,0" {
+		t.Errorf("got %q", got)
+	}
+	if !reflect.DeepEqual(logged, []string{"hi"}) {
+		t.Errorf("logged %q", logged)
+	}
+}
+
+func TestSetMode(t *testing.T) {
+	const src = `function fib(n) { return n < 2 ? n : fib(n - 1) + fib(n - 2); } fib(15)`
+	for _, mode := range []Mode{TreeWalk, Compiled} {
+		rt := New()
+		rt.SetMode(mode)
+		if got := rt.interp.Mode(); got != mode.internal() {
+			t.Errorf("SetMode(%v): interpreter mode %v", mode, got)
+		}
+		v, err := rt.RunString(src)
+		if err != nil || v.Float() != 610 {
+			t.Errorf("fib(15) in mode %v: got %v, %v", mode, v, err)
+		}
+	}
+	// RunOptions.Mode applies to one run.
+	rt := New()
+	var during interpreter.Mode
+	rt.Set("probe", func() { during = rt.interp.Mode() })
+	if _, err := rt.RunStringWithOptions(`probe()`, RunOptions{Mode: Compiled}); err != nil {
+		t.Fatal(err)
+	}
+	if during != interpreter.Compiled || rt.interp.Mode() != interpreter.TreeWalk {
+		t.Errorf("RunOptions.Mode: mode %v during the run, %v after", during, rt.interp.Mode())
+	}
+	if New().interp.Mode() != interpreter.TreeWalk {
+		t.Error("a new Runtime should walk functions")
+	}
+}
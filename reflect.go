@@ -0,0 +1,118 @@
+package jsgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+var (
+	valueType = reflect.TypeOf(Value{})
+	funcType  = reflect.TypeOf(Func(nil))
+)
+
+// newConverter returns the converter of r's ToValue and ExportTo. On top of
+// the rules of the runtime, it passes Value through, turns Func into
+// functions without reflection, exports into interface{} with Export and
+// reports the exceptions of script functions called from Go as *Exception.
+// The objects it makes belong to r's realm.
+func (r *Runtime) newConverter() *runtime.GoConverter {
+	return &runtime.GoConverter{
+		Realm: r.realm.Realm,
+		FromGo: func(rv reflect.Value) (*runtime.Value, bool, error) {
+			switch {
+			case rv.Type() == valueType:
+				return rv.Interface().(Value).raw(), true, nil
+			case rv.Kind() == reflect.Func && rv.Type().ConvertibleTo(funcType) && !rv.IsNil():
+				fn := rv.Convert(funcType).Interface().(Func)
+				return runtime.NewObject(runtime.NewFunctionObject(r.realm.FunctionPrototype, r.wrapFunc(fn))), true, nil
+			}
+			return nil, false, nil
+		},
+		ToGo: func(v *runtime.Value, dst reflect.Value) (bool, error) {
+			switch {
+			case dst.Type() == valueType:
+				dst.Set(reflect.ValueOf(r.value(v)))
+				return true, nil
+			case dst.Kind() == reflect.Interface && dst.Type().NumMethod() == 0:
+				exported := r.value(v).export()
+				dst.Set(reflect.ValueOf(&exported).Elem())
+				return true, nil
+			}
+			return false, nil
+		},
+		GoError:   throwException,
+		CallError: r.wrapError,
+	}
+}
+
+// Bind converts x like ToValue, except that a struct or a pointer to one
+// becomes a live host object instead of a copy: each exported field is a
+// property whose reads and writes go to the Go field, and each exported
+// method, including those with pointer receivers, is a function that calls
+// it. Arguments and results convert as for functions passed to ToValue, a
+// variadic method takes any number of arguments, and a non-nil error
+// result throws. Structs reached through a bound object are bound too. Use
+// it with Set to give scripts a service or a handle:
+//
+//	db, err := rt.Bind(&DB{})
+//	err = rt.Set("db", db) // db.Query("...") calls (*DB).Query
+func (r *Runtime) Bind(x interface{}) (Value, error) {
+	defer r.lock()()
+	v, err := r.binder.ToValue(x)
+	if err != nil {
+		return Undefined(), fmt.Errorf("jsgo: %w", err)
+	}
+	return r.value(v), nil
+}
+
+// ExportTo converts v into the Go value target points to, following the
+// type of target: numbers go into the numeric kinds, strings and booleans
+// into theirs, arrays into slices and arrays, objects into maps with string
+// keys and into structs (matching properties to fields as ToValue names
+// them), and functions into Go functions that call v and return an
+// *Exception for an uncaught throw. Pointers are allocated as needed, an
+// interface{} receives Export, and a Value receives v itself. Undefined and
+// null set the zero value.
+func (v Value) ExportTo(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("jsgo: ExportTo needs a non-nil pointer, not %T", target)
+	}
+	if v.rt == nil {
+		// Undefined and null, which belong to no Runtime, export to the
+		// zero value of the target.
+		if rv.Elem().Type() == valueType {
+			rv.Elem().Set(reflect.ValueOf(v))
+		} else {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+		}
+		return nil
+	}
+	defer v.lock()()
+	if err := v.rt.conv.ExportTo(v.raw(), rv.Elem()); err != nil {
+		return fmt.Errorf("jsgo: %w", err)
+	}
+	return nil
+}
+
+// lock holds the execution lock of r's agent until the returned function
+// is called. Every use of r and of its values takes it, so that they may
+// be used from any goroutine; see runtime.Agent.Lock.
+func (r *Runtime) lock() func() {
+	agent := r.interp.Agent()
+	agent.Lock()
+	return agent.Unlock
+}
+
+// throwException makes an *Exception returned by Go code throw its value
+// again, instead of a new Error with its message.
+func throwException(err error) error {
+	var ex *Exception
+	if errors.As(err, &ex) {
+		return runtime.Throw(ex.value.raw())
+	}
+	return err
+}
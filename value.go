@@ -0,0 +1,309 @@
+package jsgo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/example/jsgo/internal/runtime"
+)
+
+// Type is the type of a JavaScript value.
+type Type int
+
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeObject
+	TypeFunction
+	TypeSymbol
+)
+
+// Value is a JavaScript value. The zero Value is undefined. A Value made
+// by a Runtime belongs to it: its methods hold the Runtime's lock while
+// they use it, and it may be passed to the Runtime and to the others of
+// its agent, those made with NewRealm. Pass a Clone to other Runtimes.
+type Value struct {
+	v  *runtime.Value
+	rt *Runtime // the Runtime v belongs to, nil for Undefined and Null
+}
+
+// Func is a Go function callable from JavaScript. Returning an error throws
+// in the calling script: errors from Throw throw their value, any other error
+// becomes an Error whose message is the error text. Prefixing the text with
+// an error name, as in "TypeError: bad input", selects that error type.
+type Func func(this Value, args []Value) (Value, error)
+
+// Undefined returns the undefined value.
+func Undefined() Value { return Value{v: runtime.Undefined} }
+
+// Null returns the null value.
+func Null() Value { return Value{v: runtime.Null} }
+
+// ToValue converts a Go value to a JavaScript value of r's realm. Supported
+// types are nil, bool, the integer and float types, string, Value, Func,
+// pointers, slices and arrays, maps with string keys, structs and
+// functions. Slices, maps and structs are converted recursively into new
+// arrays and objects; a struct gets a property for each exported field,
+// named by its js tag as in `js:"name"` or else by the field, and fields
+// tagged `js:"-"` are skipped. Other functions become JavaScript functions
+// whose arguments are converted with ExportTo, and a non-nil error result
+// throws.
+func (r *Runtime) ToValue(x interface{}) (Value, error) {
+	defer r.lock()()
+	return r.toValue(x)
+}
+
+func (r *Runtime) toValue(x interface{}) (Value, error) {
+	v, err := r.conv.ToValue(x)
+	if err != nil {
+		return Undefined(), fmt.Errorf("jsgo: %w", err)
+	}
+	return r.value(v), nil
+}
+
+// value returns v as a Value of r.
+func (r *Runtime) value(v *runtime.Value) Value {
+	return Value{v: v, rt: r}
+}
+
+// wrapFunc makes fn callable by the scripts of r, with the lock of r's
+// agent released.
+func (r *Runtime) wrapFunc(fn Func) runtime.CallableFunc {
+	return func(this *runtime.Value, args []*runtime.Value) (*runtime.Value, error) {
+		jsArgs := make([]Value, len(args))
+		for i, a := range args {
+			jsArgs[i] = r.value(a)
+		}
+		var result Value
+		var err error
+		r.interp.Agent().Unlocked(func() { result, err = fn(r.value(this), jsArgs) })
+		if err != nil {
+			return nil, throwException(err)
+		}
+		return result.raw(), nil
+	}
+}
+
+// lock holds the lock of v's Runtime, if it has one, until the returned
+// function is called.
+func (v Value) lock() func() {
+	if v.rt == nil {
+		return func() {}
+	}
+	return v.rt.lock()
+}
+
+func (v Value) raw() *runtime.Value {
+	if v.v == nil {
+		return runtime.Undefined
+	}
+	return v.v
+}
+
+// Type reports the type of v. Callable objects report TypeFunction.
+func (v Value) Type() Type {
+	raw := v.raw()
+	switch raw.Type {
+	case runtime.TypeNull:
+		return TypeNull
+	case runtime.TypeBoolean:
+		return TypeBoolean
+	case runtime.TypeNumber:
+		return TypeNumber
+	case runtime.TypeString:
+		return TypeString
+	case runtime.TypeSymbol:
+		return TypeSymbol
+	case runtime.TypeObject:
+		if raw.Object != nil && raw.Object.Callable != nil {
+			return TypeFunction
+		}
+		return TypeObject
+	}
+	return TypeUndefined
+}
+
+// IsUndefined reports whether v is undefined.
+func (v Value) IsUndefined() bool { return v.raw().Type == runtime.TypeUndefined }
+
+// IsNull reports whether v is null.
+func (v Value) IsNull() bool { return v.raw().Type == runtime.TypeNull }
+
+// String converts v to a string as JavaScript's String(v) would for
+// primitives. Objects use their default string conversion. The string is
+// UTF-8, except that a lone surrogate, which UTF-8 cannot encode, keeps
+// the three bytes its code point would take, so that it survives a round
+// trip through Go.
+func (v Value) String() string {
+	if raw := v.raw(); raw.Type == runtime.TypeString {
+		return raw.Str
+	}
+	defer v.lock()()
+	return v.raw().ToString()
+}
+
+// Float converts v to a number.
+func (v Value) Float() float64 {
+	if raw := v.raw(); raw.Type == runtime.TypeNumber {
+		return raw.Number
+	}
+	defer v.lock()()
+	return v.raw().ToNumber()
+}
+
+// Bool converts v to a boolean using JavaScript truthiness.
+func (v Value) Bool() bool {
+	return v.raw().ToBoolean()
+}
+
+// Get returns the property key of an object, or undefined when v is not an
+// object or has no such property.
+func (v Value) Get(key string) Value {
+	defer v.lock()()
+	return v.get(key)
+}
+
+func (v Value) get(key string) Value {
+	raw := v.raw()
+	if raw.Type != runtime.TypeObject || raw.Object == nil {
+		return Undefined()
+	}
+	if raw.Object.OType == runtime.ObjTypeArray {
+		if key == "length" {
+			return v.rt.value(runtime.NewNumber(float64(raw.Object.ArrayLength())))
+		}
+		if i, ok := runtime.ArrayIndex(key); ok {
+			if elem, ok := raw.Object.ArrayElement(i); ok {
+				return v.rt.value(elem)
+			}
+		}
+	}
+	val := raw.Object.Get(key)
+	if val == nil {
+		return Undefined()
+	}
+	return v.rt.value(val)
+}
+
+// Set assigns the property key of an object to x, converted with ToValue.
+func (v Value) Set(key string, x interface{}) error {
+	raw := v.raw()
+	if raw.Type != runtime.TypeObject || raw.Object == nil {
+		return fmt.Errorf("jsgo: cannot set property %q on %s", key, raw.Type)
+	}
+	defer v.lock()()
+	val, err := v.rt.toValue(x)
+	if err != nil {
+		return err
+	}
+	obj := raw.Object
+	if obj.OType == runtime.ObjTypeArray {
+		if key == "length" {
+			n, err := runtime.ToArrayLength(val.raw())
+			if err != nil {
+				return err
+			}
+			obj.SetArrayLength(n)
+			return nil
+		}
+		if i, ok := runtime.ArrayIndex(key); ok && obj.Properties[key] == nil {
+			if obj.CanSetElement(i) {
+				obj.SetArrayElement(i, val.raw())
+			}
+			return nil
+		}
+	}
+	obj.Set(key, val.raw())
+	return nil
+}
+
+// Call calls v as a function with the given this value and arguments. An
+// uncaught throw is reported as an *Exception.
+func (v Value) Call(this Value, args ...Value) (Value, error) {
+	raw := v.raw()
+	if raw.Type != runtime.TypeObject || raw.Object == nil || raw.Object.Callable == nil {
+		return Undefined(), fmt.Errorf("jsgo: value is not a function")
+	}
+	rawArgs := make([]*runtime.Value, len(args))
+	for i, a := range args {
+		rawArgs[i] = a.raw()
+	}
+	defer v.lock()()
+	result, err := raw.Object.Callable(this.raw(), rawArgs)
+	if err != nil {
+		return Undefined(), v.rt.wrapError(err)
+	}
+	if result == nil {
+		return Undefined(), nil
+	}
+	return v.rt.value(result), nil
+}
+
+// Clone returns a deep copy of v made by the structured clone algorithm,
+// as structuredClone(v) would. The copy shares no objects with v, so it can
+// be kept as a snapshot or passed to another Runtime. Functions and symbols
+// cannot be cloned.
+func (v Value) Clone() (Value, error) {
+	if v.rt == nil {
+		// Undefined and null are their own copies.
+		return v, nil
+	}
+	defer v.lock()()
+	val, err := v.rt.realm.DeepClone(v.raw())
+	if err != nil {
+		return Undefined(), err
+	}
+	return v.rt.value(val), nil
+}
+
+// Export converts v to a plain Go value: nil for undefined and null, bool,
+// float64, string, []interface{} for arrays and map[string]interface{} for
+// other objects (own enumerable properties only). Functions and symbols are
+// returned as the Value itself.
+func (v Value) Export() interface{} {
+	defer v.lock()()
+	return v.export()
+}
+
+func (v Value) export() interface{} {
+	raw := v.raw()
+	switch raw.Type {
+	case runtime.TypeUndefined, runtime.TypeNull:
+		return nil
+	case runtime.TypeBoolean:
+		return raw.Bool
+	case runtime.TypeNumber:
+		return raw.Number
+	case runtime.TypeString:
+		return raw.Str
+	case runtime.TypeObject:
+		obj := raw.Object
+		if obj == nil || obj.Callable != nil {
+			return v
+		}
+		if obj.OType == runtime.ObjTypeArray {
+			elems := obj.ArrayValues()
+			out := make([]interface{}, len(elems))
+			for i, elem := range elems {
+				out[i] = v.rt.value(elem).export()
+			}
+			return out
+		}
+		out := make(map[string]interface{})
+		for key, prop := range obj.Properties {
+			if !prop.Enumerable || strings.HasPrefix(key, "@@") {
+				continue
+			}
+			val := prop.Value
+			if prop.IsAccessor {
+				val = v.get(key).raw()
+			}
+			out[key] = v.rt.value(val).export()
+		}
+		return out
+	}
+	return v
+}